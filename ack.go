@@ -0,0 +1,411 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ackCommand exports the violations of a report to CSV and imports the acknowledgments
+// annotated in it back into the state store.
+const ackCommand = "ack"
+
+// Statuses of an acknowledgment. Acknowledged violations are listed separately from the
+// violations to act on, and suppressed violations are left out of the report.
+const (
+	ackStatusAcknowledged = "acknowledged"
+	ackStatusSuppressed   = "suppressed"
+)
+
+// stateKeyAcknowledgments is the key of the acknowledgments in the state store.
+const stateKeyAcknowledgments = "acknowledgments"
+
+var (
+	errAckArguments          = errors.New("usage: ack export [REPORT] | ack import FILE")
+	errNoStateStore          = errors.New("ASSET_WATCHER_STATE_STORE is not set")
+	errInvalidAcknowledgment = errors.New("invalid acknowledgment")
+)
+
+// ackCSVHeader is the header of the exported CSV. Imports only require the rule, resource,
+// and status columns, in any order, so spreadsheets can rearrange and add columns.
+var ackCSVHeader = []string{
+	"rule", "severity", "resource", "name", "project", "ip_address", "message",
+	"status", "reason", "owner", "expires",
+}
+
+// Acknowledgment records the triage of the violation of a rule by a resource.
+type Acknowledgment struct {
+	Rule       string    `json:"rule"`
+	Resource   string    `json:"resource"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+	Owner      string    `json:"owner,omitempty"`
+	Expires    time.Time `json:"expires,omitzero"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+// key returns the key matching the acknowledgment with its violation.
+func (a Acknowledgment) key() string {
+	return a.Rule + "|" + a.Resource
+}
+
+// activeAt reports whether the acknowledgment has not expired at the time.
+func (a Acknowledgment) activeAt(t time.Time) bool {
+	return a.Expires.IsZero() || t.Before(a.Expires)
+}
+
+// violationKey returns the key of the acknowledgments of the violation.
+func violationKey(violation RuleViolation) string {
+	return violation.Rule + "|" + assetKey(violation.Asset)
+}
+
+// runAckCommand runs ack export, which writes the violations of a JSON report, or of the
+// latest stored run, as CSV along with their current acknowledgments, and ack import, which
+// merges the acknowledgments of the CSV, or of stdin for -, into the state store.
+func runAckCommand(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg *Config,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+) error {
+	if len(args) == 0 {
+		return errAckArguments
+	}
+
+	switch {
+	case args[0] == "export" && len(args) <= 2:
+		state := newStateStore(ctx, logger, cfg)
+		defer closeStore(ctx, logger, state)
+
+		report, err := loadAckReport(ctx, cfg, args[1:])
+		if err != nil {
+			return err
+		}
+
+		return exportAcknowledgments(ctx, state, report, stdout)
+	case args[0] == "import" && len(args) == 2:
+		if cfg.StateStore == "" {
+			return errNoStateStore
+		}
+
+		r := stdin
+
+		if args[1] != "-" {
+			f, err := os.Open(args[1]) //nolint:gosec // The path is provided by the operator.
+			if err != nil {
+				return fmt.Errorf("failed to open acknowledgments: %w", err)
+			}
+			defer f.Close()
+
+			r = f
+		}
+
+		state := newStateStore(ctx, logger, cfg)
+		defer closeStore(ctx, logger, state)
+
+		return importAcknowledgments(ctx, state, r, stdout, time.Now().UTC())
+	default:
+		return errAckArguments
+	}
+}
+
+// loadAckReport reads the JSON report of the argument, or the latest stored run.
+func loadAckReport(ctx context.Context, cfg *Config, args []string) (*Report, error) {
+	if len(args) == 0 {
+		if cfg.HistoryDir == "" {
+			return nil, errNoHistory
+		}
+
+		return NewFileRunStore(cfg.HistoryDir).AsOf(ctx, time.Now())
+	}
+
+	f, err := os.Open(args[0]) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer f.Close()
+
+	return ReadReport(f)
+}
+
+// exportAcknowledgments writes the violations and the acknowledged violations of the report
+// as CSV, with the status, reason, owner, and expiry of their current acknowledgments.
+func exportAcknowledgments(ctx context.Context, state StateStore, report *Report, w io.Writer) error {
+	acks := map[string]Acknowledgment{}
+
+	if state != nil {
+		stored, err := loadAcknowledgments(ctx, state)
+		if err != nil {
+			return err
+		}
+
+		for _, ack := range stored {
+			acks[ack.key()] = ack
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(ackCSVHeader)
+
+	for _, violation := range slices.Concat(report.Violations, report.Acknowledged) {
+		ack := acks[violationKey(violation)]
+
+		expires := ""
+		if !ack.Expires.IsZero() {
+			expires = ack.Expires.Format(time.RFC3339)
+		}
+
+		_ = cw.Write([]string{
+			violation.Rule, violation.Severity, assetKey(violation.Asset), violation.Asset.Name,
+			violation.Asset.Project, violation.Asset.IPAddress, violation.Message,
+			ack.Status, ack.Reason, ack.Owner, expires,
+		})
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write acknowledgments: %w", err)
+	}
+
+	return nil
+}
+
+// importAcknowledgments merges the acknowledgments of the CSV into the state store. Rows
+// with an empty status remove the acknowledgment of their violation.
+func importAcknowledgments(ctx context.Context, state StateStore, r io.Reader, w io.Writer, now time.Time) error {
+	imported, err := readAcknowledgmentsCSV(r, now)
+	if err != nil {
+		return err
+	}
+
+	merged, updated, removed, err := updateAcknowledgments(ctx, state, imported)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "Imported %d acknowledgments, removed %d, %d in total\n", updated, removed, len(merged))
+
+	return nil
+}
+
+// readAcknowledgmentsCSV parses a CSV exported by ack export and annotated by teams. The
+// columns are matched by their header, case-insensitively, and unknown columns are ignored.
+// The expiry is either a date, expiring at the end of the day in UTC, or an RFC 3339 time.
+func readAcknowledgmentsCSV(r io.Reader, now time.Time) ([]Acknowledgment, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read acknowledgments header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")] = i
+	}
+
+	for _, required := range []string{"rule", "resource", "status"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", errInvalidAcknowledgment, required)
+		}
+	}
+
+	acks := []Acknowledgment{}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read acknowledgments: %w", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+
+			return ""
+		}
+
+		ack := Acknowledgment{
+			Rule:       field("rule"),
+			Resource:   field("resource"),
+			Status:     strings.ToLower(field("status")),
+			Reason:     field("reason"),
+			Owner:      field("owner"),
+			ImportedAt: now,
+		}
+
+		if ack.Rule == "" || ack.Resource == "" {
+			return nil, fmt.Errorf("%w: line %d: rule and resource are required", errInvalidAcknowledgment, line)
+		}
+
+		if ack.Status != "" && ack.Status != ackStatusAcknowledged && ack.Status != ackStatusSuppressed {
+			return nil, fmt.Errorf("%w: line %d: status %q, expected %s, %s, or empty",
+				errInvalidAcknowledgment, line, ack.Status, ackStatusAcknowledged, ackStatusSuppressed)
+		}
+
+		if expires := field("expires"); expires != "" {
+			if ack.Expires, err = parseAckExpiry(expires); err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", errInvalidAcknowledgment, line, err)
+			}
+		}
+
+		acks = append(acks, ack)
+	}
+
+	return acks, nil
+}
+
+// parseAckExpiry parses a date, expiring at the end of the day in UTC, or an RFC 3339 time.
+func parseAckExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expires %s, expected YYYY-MM-DD or RFC 3339: %w", strconv.Quote(s), err)
+	}
+
+	return t, nil
+}
+
+// mergeAcknowledgments applies the imported acknowledgments on top of the stored ones and
+// returns the result sorted by rule and resource, with the number of updated and removed ones.
+func mergeAcknowledgments(stored, imported []Acknowledgment) ([]Acknowledgment, int, int) {
+	byKey := make(map[string]Acknowledgment, len(stored))
+	for _, ack := range stored {
+		byKey[ack.key()] = ack
+	}
+
+	updated, removed := 0, 0
+
+	for _, ack := range imported {
+		if ack.Status == "" {
+			if _, ok := byKey[ack.key()]; ok {
+				delete(byKey, ack.key())
+
+				removed++
+			}
+
+			continue
+		}
+
+		byKey[ack.key()] = ack
+		updated++
+	}
+
+	merged := make([]Acknowledgment, 0, len(byKey))
+	for _, ack := range byKey {
+		merged = append(merged, ack)
+	}
+
+	slices.SortFunc(merged, func(a, b Acknowledgment) int {
+		return cmp.Or(cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Resource, b.Resource))
+	})
+
+	return merged, updated, removed
+}
+
+// loadAcknowledgments reads the acknowledgments of the state store.
+func loadAcknowledgments(ctx context.Context, state StateStore) ([]Acknowledgment, error) {
+	value, err := state.Get(ctx, stateKeyAcknowledgments)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeAcknowledgments(value)
+}
+
+// decodeAcknowledgments decodes the stored acknowledgments, if any.
+func decodeAcknowledgments(value []byte) ([]Acknowledgment, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var acks []Acknowledgment
+	if err := json.Unmarshal(value, &acks); err != nil {
+		return nil, fmt.Errorf("failed to decode acknowledgments: %w", err)
+	}
+
+	return acks, nil
+}
+
+// updateAcknowledgments merges the imported acknowledgments into the state store atomically,
+// so that concurrent imports and Slack actions do not overwrite each other, and returns the
+// merged acknowledgments with the number of updated and removed ones.
+func updateAcknowledgments(
+	ctx context.Context, state StateStore, imported []Acknowledgment,
+) ([]Acknowledgment, int, int, error) {
+	var (
+		merged           []Acknowledgment
+		updated, removed int
+	)
+
+	err := updateState(ctx, state, stateKeyAcknowledgments, func(value []byte) ([]byte, error) {
+		stored, err := decodeAcknowledgments(value)
+		if err != nil {
+			return nil, err
+		}
+
+		merged, updated, removed = mergeAcknowledgments(stored, imported)
+
+		value, err = json.Marshal(merged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode acknowledgments: %w", err)
+		}
+
+		return value, nil
+	})
+
+	return merged, updated, removed, err
+}
+
+// applyAcknowledgments moves the acknowledged violations of the report to its acknowledged
+// violations and drops the suppressed ones, counting them in the summary. Expired
+// acknowledgments are ignored, so their violations are reported again.
+func applyAcknowledgments(report *Report, acks []Acknowledgment, now time.Time) {
+	byKey := make(map[string]Acknowledgment, len(acks))
+	for _, ack := range acks {
+		if ack.activeAt(now) {
+			byKey[ack.key()] = ack
+		}
+	}
+
+	violations := []RuleViolation{}
+
+	for _, violation := range report.Violations {
+		ack, ok := byKey[violationKey(violation)]
+
+		switch {
+		case !ok:
+			violations = append(violations, violation)
+		case ack.Status == ackStatusSuppressed:
+			report.Summary.Suppressed++
+		default:
+			violation.Acknowledgment = &ack
+			report.Acknowledged = append(report.Acknowledged, violation)
+		}
+	}
+
+	report.Violations = violations
+}
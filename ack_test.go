@@ -0,0 +1,179 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadAcknowledgmentsCSV(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		csv     string
+		want    []Acknowledgment
+		wantErr bool
+	}{
+		{
+			name: "reordered columns with extra columns",
+			csv: "Status,Team Notes,Resource,Rule,Owner,Expires\n" +
+				"Acknowledged,NAT of the old VPN,//a1,orphaned-external-address,net-team,2024-06-30\n" +
+				",,//a2,orphaned-external-address,,\n",
+			want: []Acknowledgment{
+				{
+					Rule: ruleOrphanedExternalAddress, Resource: "//a1", Status: ackStatusAcknowledged, Owner: "net-team",
+					Expires: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), ImportedAt: now,
+				},
+				{Rule: ruleOrphanedExternalAddress, Resource: "//a2", ImportedAt: now},
+			},
+		},
+		{name: "missing status column", csv: "rule,resource\nr,//a1\n", wantErr: true},
+		{name: "unknown status", csv: "rule,resource,status\nr,//a1,ignored\n", wantErr: true},
+		{name: "missing resource", csv: "rule,resource,status\nr,,suppressed\n", wantErr: true},
+		{name: "invalid expiry", csv: "rule,resource,status,expires\nr,//a1,suppressed,next week\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAcknowledgmentsCSV(strings.NewReader(tt.csv), now)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidAcknowledgment) {
+					t.Fatalf("readAcknowledgmentsCSV() error = %v, want %v", err, errInvalidAcknowledgment)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("readAcknowledgmentsCSV() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readAcknowledgmentsCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeAcknowledgments(t *testing.T) {
+	stored := []Acknowledgment{
+		{Rule: "r", Resource: "//b", Status: ackStatusAcknowledged},
+		{Rule: "r", Resource: "//c", Status: ackStatusSuppressed},
+	}
+	imported := []Acknowledgment{
+		{Rule: "r", Resource: "//a", Status: ackStatusSuppressed},
+		{Rule: "r", Resource: "//b", Status: ackStatusSuppressed, Reason: "decommissioned"},
+		{Rule: "r", Resource: "//c"},
+		{Rule: "r", Resource: "//d"},
+	}
+
+	merged, updated, removed := mergeAcknowledgments(stored, imported)
+
+	want := []Acknowledgment{
+		{Rule: "r", Resource: "//a", Status: ackStatusSuppressed},
+		{Rule: "r", Resource: "//b", Status: ackStatusSuppressed, Reason: "decommissioned"},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeAcknowledgments() = %+v, want %+v", merged, want)
+	}
+
+	if updated != 2 || removed != 1 {
+		t.Errorf("mergeAcknowledgments() updated %d and removed %d, want 2 and 1", updated, removed)
+	}
+}
+
+func TestApplyAcknowledgments(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	violation := func(resource string) RuleViolation {
+		return RuleViolation{Rule: ruleOrphanedExternalAddress, Asset: ProcessedAsset{ResourceName: resource}}
+	}
+
+	report := &Report{Violations: []RuleViolation{violation("//a"), violation("//b"), violation("//c"), violation("//d")}}
+	acks := []Acknowledgment{
+		{Rule: ruleOrphanedExternalAddress, Resource: "//a", Status: ackStatusAcknowledged},
+		{Rule: ruleOrphanedExternalAddress, Resource: "//b", Status: ackStatusSuppressed},
+		{Rule: ruleOrphanedExternalAddress, Resource: "//c", Status: ackStatusSuppressed, Expires: now},
+		{Rule: ruleInstanceExternalIP, Resource: "//d", Status: ackStatusSuppressed},
+	}
+
+	applyAcknowledgments(report, acks, now)
+
+	resources := func(violations []RuleViolation) []string {
+		names := []string{}
+		for _, v := range violations {
+			names = append(names, v.Asset.ResourceName)
+		}
+
+		return names
+	}
+
+	if got, want := resources(report.Violations), []string{"//c", "//d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+
+	if got, want := resources(report.Acknowledged), []string{"//a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("acknowledged = %v, want %v", got, want)
+	}
+
+	if report.Acknowledged[0].Acknowledgment == nil {
+		t.Error("acknowledged violation has no acknowledgment")
+	}
+
+	if report.Summary.Suppressed != 1 {
+		t.Errorf("suppressed = %d, want 1", report.Summary.Suppressed)
+	}
+}
+
+func TestAcknowledgments_ExportImportRoundTrip(t *testing.T) {
+	ctx := t.Context()
+	state := NewFileStateStore(t.TempDir())
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	report := &Report{Violations: []RuleViolation{{
+		Rule:     ruleOrphanedExternalAddress,
+		Severity: severityMedium,
+		Message:  "External address 1.1.1.1 is reserved but not used by any resource",
+		Asset:    ProcessedAsset{Name: "a1", Project: "proj-A", IPAddress: "1.1.1.1", ResourceName: "//a1"},
+	}}}
+
+	var exported bytes.Buffer
+	if err := exportAcknowledgments(ctx, state, report, &exported); err != nil {
+		t.Fatalf("exportAcknowledgments() error = %v", err)
+	}
+
+	// Triage the finding in the spreadsheet.
+	annotated := strings.Replace(exported.String(), ",,,,\n", ",suppressed,Reserved for the DR site,net-team,\n", 1)
+
+	var out bytes.Buffer
+	if err := importAcknowledgments(ctx, state, strings.NewReader(annotated), &out, now); err != nil {
+		t.Fatalf("importAcknowledgments() error = %v", err)
+	}
+
+	acks, err := loadAcknowledgments(ctx, state)
+	if err != nil {
+		t.Fatalf("loadAcknowledgments() error = %v", err)
+	}
+
+	want := []Acknowledgment{{
+		Rule: ruleOrphanedExternalAddress, Resource: "//a1", Status: ackStatusSuppressed,
+		Reason: "Reserved for the DR site", Owner: "net-team", ImportedAt: now,
+	}}
+	if !reflect.DeepEqual(acks, want) {
+		t.Errorf("stored acknowledgments = %+v, want %+v", acks, want)
+	}
+
+	exported.Reset()
+
+	if err := exportAcknowledgments(ctx, state, report, &exported); err != nil {
+		t.Fatalf("exportAcknowledgments() error = %v", err)
+	}
+
+	if !strings.Contains(exported.String(), ",suppressed,Reserved for the DR site,net-team,") {
+		t.Errorf("export does not include the acknowledgment:\n%s", exported.String())
+	}
+}
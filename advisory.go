@@ -0,0 +1,307 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"google.golang.org/api/advisorynotifications/v1"
+	"google.golang.org/api/option"
+)
+
+// advisoriesCommand sends the new Advisory Notifications of the organization through the
+// notifiers.
+const advisoriesCommand = "advisories"
+
+const (
+	// stateKeyAdvisories is the key of the advisories already notified in the state store.
+	stateKeyAdvisories = "advisory-notifications"
+
+	// advisoryRetention is how long a notified advisory is remembered. Advisory Notifications
+	// are kept for a year, so an advisory is never notified twice.
+	advisoryRetention = 400 * hoursPerDay * time.Hour
+
+	advisoryPageSize = 50
+)
+
+var errAdvisoriesIncomplete = errors.New("some advisories could not be sent")
+
+// Advisory is an Advisory Notification of the organization, such as a security bulletin or a
+// notice about the handling of customer data, with its body converted to Markdown.
+// https://cloud.google.com/advisory-notifications/docs/overview
+type Advisory struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// AdvisoryClient lists the Advisory Notifications of an organization.
+type AdvisoryClient struct {
+	notifications *advisorynotifications.OrganizationsLocationsNotificationsService
+}
+
+// NewAdvisoryClient creates a new Advisory Notifications API client.
+func NewAdvisoryClient(ctx context.Context, opts ...option.ClientOption) (*AdvisoryClient, error) {
+	s, err := advisorynotifications.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Advisory Notifications client: %w", err)
+	}
+
+	return &AdvisoryClient{notifications: s.Organizations.Locations.Notifications}, nil
+}
+
+// List returns the advisories of the organization, oldest first, with the body of their
+// latest message.
+func (c *AdvisoryClient) List(ctx context.Context, orgID string) ([]Advisory, error) {
+	advisories := []Advisory{}
+	parent := "organizations/" + orgID + "/locations/global"
+
+	err := c.notifications.List(parent).View("FULL").PageSize(advisoryPageSize).Pages(ctx,
+		func(resp *advisorynotifications.GoogleCloudAdvisorynotificationsV1ListNotificationsResponse) error {
+			for _, n := range resp.Notifications {
+				advisories = append(advisories, newAdvisory(n))
+			}
+
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Advisory Notifications of %s: %w", parent, err)
+	}
+
+	// The API lists the newest advisories first.
+	slices.Reverse(advisories)
+
+	return advisories, nil
+}
+
+// newAdvisory converts a notification of the API, whose texts are HTML.
+func newAdvisory(n *advisorynotifications.GoogleCloudAdvisorynotificationsV1Notification) Advisory {
+	advisory := Advisory{Name: n.Name, Type: n.NotificationType}
+
+	if n.Subject != nil {
+		advisory.Subject = convertHTMLToMarkdown(advisoryText(n.Subject.Text))
+	}
+
+	// Follow-up messages are appended to the notification, so the latest one is current.
+	if len(n.Messages) > 0 {
+		if message := n.Messages[len(n.Messages)-1]; message.Body != nil {
+			advisory.Body = convertHTMLToMarkdown(advisoryText(message.Body.Text))
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339, n.CreateTime); err == nil {
+		advisory.CreateTime = t
+	}
+
+	return advisory
+}
+
+// advisoryText returns the localized text, or the English text if it is not localized.
+func advisoryText(text *advisorynotifications.GoogleCloudAdvisorynotificationsV1Text) string {
+	switch {
+	case text == nil:
+		return ""
+	case text.LocalizedText != "":
+		return text.LocalizedText
+	default:
+		return text.EnText
+	}
+}
+
+// newAdvisoryNotification returns the notification of the advisory of the severity, with its
+// Markdown body.
+func newAdvisoryNotification(runID string, advisory Advisory, severity string) Notification {
+	summary := fmt.Sprintf("%s %s published on %s", severity, advisory.Type, advisory.CreateTime.Format(time.DateOnly))
+
+	return Notification{
+		RunID:   runID,
+		Title:   "[" + severity + "] Google Cloud advisory: " + advisory.Subject,
+		Summary: summary,
+		Body:    "**" + advisory.Subject + "**\n\n" + summary + "\n\n" + advisory.Body,
+	}
+}
+
+// runAdvisoriesCommand lists the Advisory Notifications of the organization and sends the ones
+// not notified yet through the notifiers, remembering them in the state store. On the first
+// run, the existing advisories are only remembered, so that the history is not sent. With
+// --dry-run, the new advisories are written to stdout instead. The advisories are filtered and
+// routed by the advisory policy of the configuration.
+func runAdvisoriesCommand(ctx context.Context, logger *slog.Logger, cfg *Config, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet(advisoriesCommand, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dryRun := flags.Bool("dry-run", false, "write the new advisories to stdout instead of sending them")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if cfg.StateStore == "" {
+		return errNoStateStore
+	}
+
+	state := newStateStore(ctx, logger, cfg)
+	defer closeStore(ctx, logger, state)
+
+	return runAdvisories(ctx, logger, cfg, state, *dryRun, stdout)
+}
+
+// runAdvisories runs the advisory pipeline: it sends the new advisories of the organization to
+// the targets of the advisory policy. It runs both as the advisories command and, with
+// ASSET_WATCHER_ADVISORIES, after the scan of a run, sharing its logger, state store, and
+// notifiers.
+func runAdvisories(
+	ctx context.Context, logger *slog.Logger, cfg *Config, state StateStore, dryRun bool, stdout io.Writer,
+) error {
+	// The policy is validated by GetConfig.
+	policy, _ := parseAdvisoryPolicy(cfg)
+
+	targets := advisoryTargets(logger, cfg, newHTTPClient(cfg), policy)
+	if len(targets) == 0 && !dryRun {
+		return errNoNotifiers
+	}
+
+	client, err := NewAdvisoryClient(ctx, clientOptionsFor(ctx, logger, cfg, credentialsAdvisories)...)
+	if err != nil {
+		return err
+	}
+
+	advisories, err := client.List(ctx, cfg.OrgID)
+	if err != nil {
+		return err
+	}
+
+	return notifyAdvisories(ctx, logger, state, targets, policy, advisories, dryRun, stdout)
+}
+
+// notifyAdvisories sends the advisories not in the state store to the targets. An advisory is
+// remembered once every target has sent it, so a failed advisory is sent again by the next run.
+// The advisories of the types filtered out are remembered without being sent, so that adding
+// their type later does not send the past ones.
+func notifyAdvisories(
+	ctx context.Context,
+	logger *slog.Logger,
+	state StateStore,
+	targets []advisoryTarget,
+	policy *advisoryPolicy,
+	advisories []Advisory,
+	dryRun bool,
+	stdout io.Writer,
+) error {
+	notified, err := loadNotifiedAdvisories(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	baseline := notified == nil
+
+	if baseline {
+		notified = map[string]time.Time{}
+	}
+
+	sent := 0
+	errs := []error{}
+
+	for _, advisory := range advisories {
+		if _, ok := notified[advisory.Name]; ok {
+			continue
+		}
+
+		switch {
+		case !policy.allows(advisory):
+		case dryRun:
+			_, _ = fmt.Fprintf(stdout, "%s\t%s\t%s\t%s\n", advisory.CreateTime.Format(time.DateOnly),
+				policy.severity(advisory), advisory.Type, advisory.Subject)
+
+			continue
+		case !baseline:
+			if err := sendAdvisory(ctx, targets, policy, advisory); err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			sent++
+		}
+
+		notified[advisory.Name] = now
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if baseline {
+		logger.InfoContext(ctx, "Recorded the existing advisories without notifying them",
+			slog.Int("advisories", len(notified)))
+	} else {
+		logger.InfoContext(ctx, "Sent advisories", slog.Int("sent", sent), slog.Int("failed", len(errs)))
+	}
+
+	maps.DeleteFunc(notified, func(_ string, t time.Time) bool {
+		return now.Sub(t) > advisoryRetention
+	})
+
+	if err := saveNotifiedAdvisories(ctx, state, notified); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", errAdvisoriesIncomplete, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// sendAdvisory sends the advisory to the targets receiving its type.
+func sendAdvisory(ctx context.Context, targets []advisoryTarget, policy *advisoryPolicy, advisory Advisory) error {
+	notification := newAdvisoryNotification(runIDFromContext(ctx), advisory, policy.severity(advisory))
+	errs := []error{}
+
+	for _, target := range targets {
+		if !policy.receives(target.name, advisory) {
+			continue
+		}
+
+		if err := target.notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", advisory.Name, target.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// loadNotifiedAdvisories reads the names of the notified advisories and when they were
+// notified from the state store, or nil if none were ever recorded.
+func loadNotifiedAdvisories(ctx context.Context, state StateStore) (map[string]time.Time, error) {
+	value, err := state.Get(ctx, stateKeyAdvisories)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	notified := map[string]time.Time{}
+	if err := json.Unmarshal(value, &notified); err != nil {
+		return nil, fmt.Errorf("failed to decode notified advisories: %w", err)
+	}
+
+	return notified, nil
+}
+
+// saveNotifiedAdvisories writes the notified advisories to the state store.
+func saveNotifiedAdvisories(ctx context.Context, state StateStore, notified map[string]time.Time) error {
+	value, err := json.Marshal(notified)
+	if err != nil {
+		return fmt.Errorf("failed to encode notified advisories: %w", err)
+	}
+
+	return state.Put(ctx, stateKeyAdvisories, value)
+}
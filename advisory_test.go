@@ -0,0 +1,180 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestAdvisoryClient_List(t *testing.T) {
+	var paths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?view="+r.URL.Query().Get("view"))
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"notifications":[{"name":"organizations/123/locations/global/notifications/new",` +
+				`"notificationType":"NOTIFICATION_TYPE_SECURITY_PRIVACY_ADVISORY","createTime":"2026-03-02T10:00:00Z",` +
+				`"subject":{"text":{"enText":"Security <b>bulletin</b>"}},"messages":[` +
+				`{"body":{"text":{"enText":"<p>First</p>"}}},` +
+				`{"body":{"text":{"enText":"<p>See <a href=\"https://cloud.google.com/x\">the bulletin</a>.</p>"}}}]}],` +
+				`"nextPageToken":"next"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"notifications":[{"name":"organizations/123/locations/global/notifications/old",` +
+			`"subject":{"text":{"enText":"Old","localizedText":"Ancien"}}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAdvisoryClient(t.Context(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewAdvisoryClient failed: %v", err)
+	}
+
+	advisories, err := client.List(t.Context(), "123")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(paths) != 2 || paths[0] != "/v1/organizations/123/locations/global/notifications?view=FULL" {
+		t.Errorf("unexpected requests %v", paths)
+	}
+
+	if len(advisories) != 2 || advisories[0].Subject != "Ancien" {
+		t.Fatalf("expected the advisories oldest first, got %+v", advisories)
+	}
+
+	latest := advisories[1]
+	if latest.Subject != "Security **bulletin**" || latest.Body != "See [the bulletin](https://cloud.google.com/x)." ||
+		!latest.CreateTime.Equal(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected advisory %+v", latest)
+	}
+}
+
+func TestNotifyAdvisories(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+	state := &memoryStateStore{values: map[string][]byte{}}
+	notifier := &fakeNotifier{}
+
+	policy := &advisoryPolicy{}
+	targets := []advisoryTarget{{name: "fake", notifier: notifier}}
+	existing := []Advisory{{Name: "a1", Subject: "Existing"}}
+
+	// The first run only records the existing advisories.
+	if err := notifyAdvisories(ctx, logger, state, targets, policy, existing, false, nil); err != nil {
+		t.Fatalf("notifyAdvisories failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 0 {
+		t.Fatalf("expected no notifications on the first run, got %d", len(notifier.notifications))
+	}
+
+	advisories := append(existing, Advisory{Name: "a2", Type: "NOTIFICATION_TYPE_SECURITY_PRIVACY_ADVISORY", Subject: "New", Body: "Details"})
+
+	var out bytes.Buffer
+	if err := notifyAdvisories(ctx, logger, state, targets, policy, advisories, true, &out); err != nil {
+		t.Fatalf("notifyAdvisories failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 0 || !strings.Contains(out.String(), "New") || strings.Contains(out.String(), "Existing") {
+		t.Fatalf("expected the new advisory on stdout only, got %q", out.String())
+	}
+
+	flaky := &flakyNotifier{failures: 1}
+	withFlaky := append(targets, advisoryTarget{name: "flaky", notifier: flaky})
+	if err := notifyAdvisories(ctx, logger, state, withFlaky, policy, advisories, false, nil); !errors.Is(err, errNotifierUnavailable) {
+		t.Fatalf("expected the error of the notifier, got %v", err)
+	}
+
+	if err := notifyAdvisories(ctx, logger, state, withFlaky, policy, advisories, false, nil); err != nil {
+		t.Fatalf("notifyAdvisories failed: %v", err)
+	}
+
+	if err := notifyAdvisories(ctx, logger, state, withFlaky, policy, advisories, false, nil); err != nil {
+		t.Fatalf("notifyAdvisories failed: %v", err)
+	}
+
+	// The failed advisory is sent again by the next run, then no longer.
+	if len(notifier.notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifier.notifications))
+	}
+
+	// Advisories of the types filtered out are remembered without being sent.
+	filtered := &advisoryPolicy{types: []string{advisoryTypes["threat-horizons"]}}
+	advisories = append(advisories, Advisory{Name: "a3", Type: advisoryTypes["security-msa"]})
+
+	for range 2 {
+		if err := notifyAdvisories(ctx, logger, state, targets, filtered, advisories, false, nil); err != nil {
+			t.Fatalf("notifyAdvisories failed: %v", err)
+		}
+	}
+
+	if len(notifier.notifications) != 2 {
+		t.Fatalf("expected the filtered advisory not to be sent, got %d notifications", len(notifier.notifications))
+	}
+
+	if n := notifier.notifications[0]; n.Title != "[MEDIUM] Google Cloud advisory: New" || !strings.Contains(n.Body, "Details") {
+		t.Errorf("unexpected notification %+v", n)
+	}
+}
+
+func TestRunAdvisories(t *testing.T) {
+	advisories := `{"name":"organizations/123/locations/global/notifications/a1","subject":{"text":{"enText":"Old"}}}`
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"notifications":[` + advisories + `]}`))
+	}))
+	defer api.Close()
+
+	var received []string
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload.Title)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	state := &memoryStateStore{values: map[string][]byte{}}
+	ctx := withSelftestServices(t.Context(), &selftestServices{
+		clientOptions: []option.ClientOption{option.WithEndpoint(api.URL), option.WithoutAuthentication()},
+		stateStore:    state,
+	})
+
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.StateStore = "memory"
+	cfg.WebhookURL = webhook.URL
+	logger := slog.New(slog.DiscardHandler)
+
+	if err := runAdvisories(ctx, logger, &cfg, state, false, io.Discard); err != nil {
+		t.Fatalf("runAdvisories failed: %v", err)
+	}
+
+	advisories = `{"name":"organizations/123/locations/global/notifications/a2","subject":{"text":{"enText":"New"}}},` +
+		advisories
+
+	if err := runAdvisories(ctx, logger, &cfg, state, false, io.Discard); err != nil {
+		t.Fatalf("runAdvisories failed: %v", err)
+	}
+
+	if len(received) != 1 || received[0] != "[MEDIUM] Google Cloud advisory: New" {
+		t.Errorf("expected only the new advisory to be sent, got %v", received)
+	}
+}
@@ -0,0 +1,165 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// advisoryTypes maps the names of the advisory types of the configuration to the notification
+// types of the Advisory Notifications API.
+var advisoryTypes = map[string]string{
+	"security-privacy":  "NOTIFICATION_TYPE_SECURITY_PRIVACY_ADVISORY",
+	"sensitive-actions": "NOTIFICATION_TYPE_SENSITIVE_ACTIONS",
+	"security-msa":      "NOTIFICATION_TYPE_SECURITY_MSA",
+	"threat-horizons":   "NOTIFICATION_TYPE_THREAT_HORIZONS",
+}
+
+var errInvalidAdvisoryPolicy = errors.New("invalid advisory filter")
+
+// advisoryPolicy selects the advisories to send by type, and assigns their severity and the
+// notifiers they are sent to.
+type advisoryPolicy struct {
+	// types are the notification types to send, or empty for all of them.
+	types []string
+
+	// severities are the severities of the notification types; other types are MEDIUM.
+	severities map[string]string
+
+	// routes are the notification types routed to every target. A target with routes only
+	// receives the advisories of its types, and targets without routes receive all of them.
+	routes map[string][]string
+}
+
+// parseAdvisoryPolicy parses ASSET_WATCHER_ADVISORY_TYPES, a comma-separated list of types,
+// ASSET_WATCHER_ADVISORY_SEVERITIES, a list of type=severity pairs, and
+// ASSET_WATCHER_ADVISORY_ROUTES, a list of type=target pairs.
+func parseAdvisoryPolicy(cfg *Config) (*advisoryPolicy, error) {
+	policy := &advisoryPolicy{severities: map[string]string{}, routes: map[string][]string{}}
+
+	for _, name := range splitString(cfg.AdvisoryTypes, ",") {
+		notificationType, err := advisoryType(name)
+		if err != nil {
+			return nil, err
+		}
+
+		policy.types = append(policy.types, notificationType)
+	}
+
+	for _, pair := range splitString(cfg.AdvisorySeverities, ",") {
+		name, severity, err := cutAdvisoryPair(pair, "type=severity")
+		if err != nil {
+			return nil, err
+		}
+
+		severity = strings.ToUpper(severity)
+		if severity != severityHigh && severity != severityMedium {
+			return nil, fmt.Errorf("%w: unknown severity %s, expected %s or %s", errInvalidAdvisoryPolicy,
+				strconv.Quote(severity), severityHigh, severityMedium)
+		}
+
+		policy.severities[name] = severity
+	}
+
+	for _, pair := range splitString(cfg.AdvisoryRoutes, ",") {
+		name, target, err := cutAdvisoryPair(pair, "type=target")
+		if err != nil {
+			return nil, err
+		}
+
+		notifier, channel, hasChannel := strings.Cut(target, ":")
+		if !slices.Contains(routableNotifiers, notifier) || notifier == "pagerduty" {
+			return nil, fmt.Errorf("%w: unknown notifier %s, expected one of slack, teams, webhook, or email",
+				errInvalidAdvisoryPolicy, strconv.Quote(notifier))
+		}
+
+		if hasChannel && (notifier != "slack" || !slackChannelPattern.MatchString(channel)) {
+			return nil, fmt.Errorf("%w: invalid target %s, only slack targets take a channel ID or #channel name",
+				errInvalidAdvisoryPolicy, strconv.Quote(target))
+		}
+
+		if !notifierConfigured(cfg, target) {
+			return nil, fmt.Errorf("%w: the %s notifier of target %s is not configured", errInvalidAdvisoryPolicy,
+				notifier, strconv.Quote(target))
+		}
+
+		policy.routes[target] = append(policy.routes[target], name)
+	}
+
+	return policy, nil
+}
+
+// cutAdvisoryPair splits a type=value pair into the notification type and the value.
+func cutAdvisoryPair(pair, format string) (string, string, error) {
+	name, value, ok := strings.Cut(pair, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+	if !ok || name == "" || value == "" {
+		return "", "", fmt.Errorf("%w: %s, expected %s", errInvalidAdvisoryPolicy, strconv.Quote(pair), format)
+	}
+
+	notificationType, err := advisoryType(name)
+
+	return notificationType, value, err
+}
+
+// advisoryType returns the notification type of the name of the configuration.
+func advisoryType(name string) (string, error) {
+	notificationType, ok := advisoryTypes[strings.TrimSpace(name)]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown advisory type %s, expected one of %s", errInvalidAdvisoryPolicy,
+			strconv.Quote(name), strings.Join(slices.Sorted(maps.Keys(advisoryTypes)), ", "))
+	}
+
+	return notificationType, nil
+}
+
+// allows reports whether advisories of the type are sent.
+func (p *advisoryPolicy) allows(advisory Advisory) bool {
+	return len(p.types) == 0 || slices.Contains(p.types, advisory.Type)
+}
+
+// severity returns the severity of the advisory.
+func (p *advisoryPolicy) severity(advisory Advisory) string {
+	if severity, ok := p.severities[advisory.Type]; ok {
+		return severity
+	}
+
+	return severityMedium
+}
+
+// receives reports whether the target receives the advisory.
+func (p *advisoryPolicy) receives(target string, advisory Advisory) bool {
+	types, ok := p.routes[target]
+
+	return !ok || slices.Contains(types, advisory.Type)
+}
+
+// advisoryTarget is a notifier receiving advisories: a configured notifier, or the target of
+// an advisory route, such as slack:CHANNEL.
+type advisoryTarget struct {
+	name     string
+	notifier Notifier
+}
+
+// advisoryTargets returns the configured notifiers and the targets of the advisory routes.
+func advisoryTargets(logger *slog.Logger, cfg *Config, client *http.Client, policy *advisoryPolicy) []advisoryTarget {
+	targets := []advisoryTarget{}
+
+	for _, notifier := range newNotifiers(logger, cfg) {
+		targets = append(targets, advisoryTarget{name: notifier.Name(), notifier: notifier})
+	}
+
+	for _, target := range slices.Sorted(maps.Keys(policy.routes)) {
+		if !slices.ContainsFunc(targets, func(t advisoryTarget) bool { return t.name == target }) {
+			targets = append(targets, advisoryTarget{name: target, notifier: newNotifier(logger, cfg, client, target)})
+		}
+	}
+
+	return targets
+}
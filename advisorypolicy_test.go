@@ -0,0 +1,71 @@
+package assetwatcher
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestParseAdvisoryPolicy(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.SlackToken = "xoxb-token"
+	cfg.WebhookURL = "https://example.com/hook"
+	cfg.AdvisoryTypes = "security-privacy, threat-horizons"
+	cfg.AdvisorySeverities = "threat-horizons=high"
+	cfg.AdvisoryRoutes = "threat-horizons=slack:#security,security-privacy=webhook"
+
+	policy, err := parseAdvisoryPolicy(&cfg)
+	if err != nil {
+		t.Fatalf("parseAdvisoryPolicy failed: %v", err)
+	}
+
+	threat := Advisory{Type: advisoryTypes["threat-horizons"]}
+	privacy := Advisory{Type: advisoryTypes["security-privacy"]}
+	msa := Advisory{Type: advisoryTypes["security-msa"]}
+
+	if !policy.allows(threat) || !policy.allows(privacy) || policy.allows(msa) {
+		t.Error("expected only the configured types to be allowed")
+	}
+
+	if policy.severity(threat) != severityHigh || policy.severity(privacy) != severityMedium {
+		t.Errorf("unexpected severities %s and %s", policy.severity(threat), policy.severity(privacy))
+	}
+
+	if !policy.receives("slack:#security", threat) || policy.receives("slack:#security", privacy) ||
+		!policy.receives("webhook", privacy) || !policy.receives("slack", privacy) {
+		t.Error("unexpected routes")
+	}
+
+	targets := advisoryTargets(slog.New(slog.DiscardHandler), &cfg, nil, policy)
+
+	names := []string{}
+	for _, target := range targets {
+		names = append(names, target.name)
+	}
+
+	if len(names) != 3 || names[0] != "slack" || names[1] != "webhook" || names[2] != "slack:#security" {
+		t.Errorf("unexpected targets %v", names)
+	}
+}
+
+func TestParseAdvisoryPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "unknown type", cfg: Config{AdvisoryTypes: "incident"}},
+		{name: "unknown severity", cfg: Config{AdvisorySeverities: "security-msa=LOW"}},
+		{name: "malformed pair", cfg: Config{AdvisorySeverities: "security-msa"}},
+		{name: "pagerduty", cfg: Config{AdvisoryRoutes: "security-msa=pagerduty", PagerDutyRoutingKey: "key"}},
+		{name: "unconfigured notifier", cfg: Config{AdvisoryRoutes: "security-msa=teams"}},
+		{name: "channel of another notifier", cfg: Config{AdvisoryRoutes: "security-msa=webhook:#x", WebhookURL: "https://x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseAdvisoryPolicy(&tt.cfg); !errors.Is(err, errInvalidAdvisoryPolicy) {
+				t.Errorf("expected an invalid advisory filter, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package assetwatcher
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"time"
+)
+
+// attestCommand exports a signed attestation of the ownership of an IP address.
+const attestCommand = "attest"
+
+// Formats of the attestation.
+const (
+	attestationFormatJSON = "json"
+	attestationFormatPDF  = "pdf"
+)
+
+var (
+	errMissingAttestationIP     = errors.New("--ip must be a valid IP address")
+	errInvalidAttestationFormat = errors.New("--format must be json or pdf")
+	errNoSigningKey             = errors.New("ASSET_WATCHER_SIGNING_KEY must be set to sign attestations")
+)
+
+// Attestation states which assets owned an IP address according to the stored runs.
+type Attestation struct {
+	IPAddress   string            `json:"ipAddress"`
+	OrgID       string            `json:"orgId"`
+	Ownership   []OwnershipPeriod `json:"ownership"`
+	Runs        int               `json:"runs"`
+	FirstRunAt  time.Time         `json:"firstRunAt,omitzero"`
+	LastRunAt   time.Time         `json:"lastRunAt,omitzero"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+}
+
+// OwnershipPeriod is a period during which the IP address was allocated to the same asset.
+// From and To are the start times of the first and the last run that found the allocation.
+type OwnershipPeriod struct {
+	Asset        string    `json:"asset"`
+	ResourceName string    `json:"resourceName,omitempty"`
+	AssetType    string    `json:"assetType,omitempty"`
+	Project      string    `json:"project"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+}
+
+// SignedAttestation is an attestation signed with the report-signing key. The signature covers
+// the exact bytes of the base64 encoded payload, which is the JSON encoding of the attestation.
+type SignedAttestation struct {
+	Attestation Attestation `json:"attestation"`
+	Payload     string      `json:"payload"`
+	Algorithm   string      `json:"algorithm"`
+	PublicKey   string      `json:"publicKey"`
+	Signature   string      `json:"signature"`
+}
+
+// runAttestCommand writes a signed attestation of the ownership of an IP address, built from
+// the stored runs, for example to respond to abuse complaints about the address space.
+func runAttestCommand(ctx context.Context, cfg *Config, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(attestCommand, flag.ContinueOnError)
+	flags.SetOutput(w)
+	ip := flags.String("ip", "", "IP address to attest the ownership of")
+	format := flags.String("format", attestationFormatJSON, "format of the attestation, json or pdf")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	addr, err := netip.ParseAddr(*ip)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errMissingAttestationIP, err)
+	}
+
+	if *format != attestationFormatJSON && *format != attestationFormatPDF {
+		return errInvalidAttestationFormat
+	}
+
+	if cfg.HistoryDir == "" {
+		return errNoHistory
+	}
+
+	if cfg.SigningKey == "" {
+		return errNoSigningKey
+	}
+
+	key, err := loadSigningKey(cfg.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	reports, err := NewFileRunStore(cfg.HistoryDir).List(ctx)
+	if err != nil {
+		return err
+	}
+
+	signed, err := signAttestation(newAttestation(cfg.OrgID, addr, reports, time.Now()), key)
+	if err != nil {
+		return err
+	}
+
+	if *format == attestationFormatPDF {
+		return writeTextPDF(w, attestationLines(signed))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(signed); err != nil {
+		return fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	return nil
+}
+
+// newAttestation lists the periods during which the address was allocated to the same asset,
+// in the order of the runs. The reports must be ordered by the start time of the run.
+func newAttestation(orgID string, addr netip.Addr, reports []*Report, now time.Time) Attestation {
+	attestation := Attestation{
+		IPAddress:   addr.String(),
+		OrgID:       orgID,
+		Ownership:   []OwnershipPeriod{},
+		Runs:        len(reports),
+		GeneratedAt: now.UTC(),
+	}
+
+	if len(reports) > 0 {
+		attestation.FirstRunAt = reports[0].Metadata.StartedAt
+		attestation.LastRunAt = reports[len(reports)-1].Metadata.StartedAt
+	}
+
+	// open holds the index of the period of every asset found in the previous run.
+	open := map[string]int{}
+
+	for _, report := range reports {
+		found := map[string]int{}
+
+		for _, asset := range report.Assets {
+			if !ownsAddress(asset, addr) {
+				continue
+			}
+
+			key := assetKey(asset)
+			if i, ok := open[key]; ok {
+				attestation.Ownership[i].To = report.Metadata.StartedAt
+				found[key] = i
+
+				continue
+			}
+
+			found[key] = len(attestation.Ownership)
+			attestation.Ownership = append(attestation.Ownership, OwnershipPeriod{
+				Asset:        asset.Name,
+				ResourceName: asset.ResourceName,
+				AssetType:    asset.AssetType,
+				Project:      asset.Project,
+				From:         report.Metadata.StartedAt,
+				To:           report.Metadata.StartedAt,
+			})
+		}
+
+		open = found
+	}
+
+	return attestation
+}
+
+func ownsAddress(asset ProcessedAsset, addr netip.Addr) bool {
+	for _, address := range ownedAddresses(asset) {
+		if a, err := netip.ParseAddr(address); err == nil && a.Unmap() == addr.Unmap() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signAttestation signs the JSON encoding of the attestation.
+func signAttestation(attestation Attestation, key ed25519.PrivateKey) (SignedAttestation, error) {
+	payload, err := json.Marshal(attestation)
+	if err != nil {
+		return SignedAttestation{}, fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	return SignedAttestation{
+		Attestation: attestation,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Algorithm:   "Ed25519",
+		PublicKey:   base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}, nil
+}
+
+// attestationLines renders the attestation as the text of the PDF document.
+func attestationLines(signed SignedAttestation) []string {
+	a := signed.Attestation
+	lines := []string{
+		"IP ADDRESS OWNERSHIP ATTESTATION",
+		"",
+		"IP address:    " + a.IPAddress,
+		"Organization:  " + a.OrgID,
+		"Generated at:  " + a.GeneratedAt.Format(time.RFC3339),
+		fmt.Sprintf("Runs examined: %d", a.Runs),
+	}
+
+	if a.Runs > 0 {
+		lines = append(lines, "History:       "+a.FirstRunAt.Format(time.RFC3339)+" to "+a.LastRunAt.Format(time.RFC3339))
+	}
+
+	lines = append(lines, "", "OWNERSHIP", "")
+
+	if len(a.Ownership) == 0 {
+		lines = append(lines, "The address was not allocated to any asset in the examined runs.")
+	}
+
+	for _, period := range a.Ownership {
+		lines = append(lines,
+			"Asset:    "+period.Asset,
+			"Project:  "+period.Project,
+			"Resource: "+period.ResourceName,
+			"Period:   "+period.From.Format(time.RFC3339)+" to "+period.To.Format(time.RFC3339),
+			"",
+		)
+	}
+
+	return append(lines,
+		"SIGNATURE",
+		"",
+		"The "+signed.Algorithm+" signature covers the base64 decoded payload.",
+		"",
+		"Public key: "+signed.PublicKey,
+		"Signature:  "+signed.Signature,
+		"Payload:    "+signed.Payload,
+	)
+}
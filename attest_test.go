@@ -0,0 +1,144 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestSigningKey writes a new Ed25519 signing key to a temporary file.
+func writeTestSigningKey(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "signing-key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestNewAttestation(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	vm := ProcessedAsset{
+		Name: "vm", ResourceName: "//compute.googleapis.com/projects/p1/zones/z/instances/vm", Project: "p1",
+		Attributes: map[string]string{"externalIPs": "203.0.113.1"},
+	}
+	nat := ProcessedAsset{Name: "nat", ResourceName: "//compute.googleapis.com/projects/p2/regions/r/addresses/nat", Project: "p2", IPAddress: "203.0.113.1"}
+	other := ProcessedAsset{Name: "other", Project: "p3", IPAddress: "203.0.113.2"}
+
+	reports := []*Report{
+		{Metadata: RunMetadata{StartedAt: day(1)}, Assets: []ProcessedAsset{vm, other}},
+		{Metadata: RunMetadata{StartedAt: day(2)}, Assets: []ProcessedAsset{vm}},
+		{Metadata: RunMetadata{StartedAt: day(3)}, Assets: []ProcessedAsset{nat}},
+		{Metadata: RunMetadata{StartedAt: day(4)}, Assets: []ProcessedAsset{vm}},
+	}
+
+	got := newAttestation("123", netip.MustParseAddr("203.0.113.1"), reports, day(5))
+
+	want := []OwnershipPeriod{
+		{Asset: "vm", ResourceName: vm.ResourceName, Project: "p1", From: day(1), To: day(2)},
+		{Asset: "nat", ResourceName: nat.ResourceName, Project: "p2", From: day(3), To: day(3)},
+		{Asset: "vm", ResourceName: vm.ResourceName, Project: "p1", From: day(4), To: day(4)},
+	}
+	if !reflect.DeepEqual(got.Ownership, want) {
+		t.Errorf("newAttestation() ownership = %+v, want %+v", got.Ownership, want)
+	}
+
+	if got.Runs != 4 || !got.FirstRunAt.Equal(day(1)) || !got.LastRunAt.Equal(day(4)) || got.OrgID != "123" {
+		t.Errorf("unexpected attestation %+v", got)
+	}
+}
+
+func TestRunAttestCommand(t *testing.T) {
+	cfg := &Config{OrgID: "123", HistoryDir: t.TempDir(), SigningKey: writeTestSigningKey(t)}
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run1", StartedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		Assets:   []ProcessedAsset{{Name: "nat", Project: "p", IPAddress: "203.0.113.1"}},
+	}
+
+	if err := NewFileRunStore(cfg.HistoryDir).Save(t.Context(), report); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runAttestCommand(t.Context(), cfg, []string{"--ip", "203.0.113.1"}, &out); err != nil {
+		t.Fatalf("runAttestCommand failed: %v", err)
+	}
+
+	var signed SignedAttestation
+	if err := json.Unmarshal(out.Bytes(), &signed); err != nil {
+		t.Fatalf("failed to decode the attestation: %v", err)
+	}
+
+	payload, _ := base64.StdEncoding.DecodeString(signed.Payload)
+	publicKey, _ := base64.StdEncoding.DecodeString(signed.PublicKey)
+	signature, _ := base64.StdEncoding.DecodeString(signed.Signature)
+
+	if !ed25519.Verify(publicKey, payload, signature) {
+		t.Error("the signature does not verify")
+	}
+
+	var attestation Attestation
+	if err := json.Unmarshal(payload, &attestation); err != nil || len(attestation.Ownership) != 1 ||
+		attestation.Ownership[0].Asset != "nat" {
+		t.Errorf("unexpected payload %s: %v", payload, err)
+	}
+
+	out.Reset()
+
+	if err := runAttestCommand(t.Context(), cfg, []string{"--ip", "203.0.113.1", "--format", "pdf"}, &out); err != nil {
+		t.Fatalf("runAttestCommand failed: %v", err)
+	}
+
+	if pdf := out.String(); !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") ||
+		!strings.Contains(pdf, "(IP address:    203.0.113.1)") {
+		t.Errorf("unexpected PDF:\n%s", pdf)
+	}
+
+	err := runAttestCommand(t.Context(), &Config{HistoryDir: cfg.HistoryDir}, []string{"--ip", "203.0.113.1"}, &out)
+	if !errors.Is(err, errNoSigningKey) {
+		t.Errorf("expected errNoSigningKey, got %v", err)
+	}
+
+	if err := runAttestCommand(t.Context(), cfg, []string{"--ip", "nat"}, &out); !errors.Is(err, errMissingAttestationIP) {
+		t.Errorf("expected errMissingAttestationIP, got %v", err)
+	}
+}
+
+func TestWriteTextPDF_Pages(t *testing.T) {
+	lines := make([]string, pdfLinesPerPage)
+	lines[0] = "(" + strings.Repeat("x", pdfLineLength) + ")"
+
+	var out bytes.Buffer
+	if err := writeTextPDF(&out, lines); err != nil {
+		t.Fatalf("writeTextPDF failed: %v", err)
+	}
+
+	// The first line is wrapped, so the text flows over 2 pages.
+	if pdf := out.String(); !strings.Contains(pdf, "/Count 2") || !strings.Contains(pdf, `(\(`) {
+		t.Errorf("unexpected PDF:\n%s", pdf)
+	}
+}
@@ -0,0 +1,318 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// Events recorded in the audit log.
+const (
+	auditEventAdded        = "added"
+	auditEventRemoved      = "removed"
+	auditEventIPReassigned = "ip-reassigned"
+	auditEventStateChanged = "state-changed"
+	auditEventChanged      = "changed"
+)
+
+const (
+	auditLogDateLayout = "2006-01-02"
+	auditLogExtension  = ".jsonl"
+)
+
+var errInvalidAuditLogPath = errors.New("invalid audit log path, expected a directory or gs://BUCKET/PREFIX")
+
+// AuditEntry is a single change of an asset recorded in the audit log.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	RunID     string    `json:"runId"`
+	Event     string    `json:"event"`
+	Asset     string    `json:"asset"`
+	AssetType string    `json:"assetType,omitempty"`
+	Name      string    `json:"name"`
+	Project   string    `json:"project"`
+	IPAddress string    `json:"ipAddress,omitempty"`
+	Changes   []string  `json:"changes,omitempty"`
+}
+
+// AuditLog is an interface for an append-only log of the changes detected by the runs.
+type AuditLog interface {
+	// Append adds the entries of a run to the log.
+	Append(ctx context.Context, entries []AuditEntry) error
+	// Prune deletes the entries of the days before the time and returns the number of deleted files.
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+// newAuditEntries returns an entry of every change of the report. Changes of the IP
+// address or the status of an asset are recorded as the more specific ip-reassigned
+// and state-changed events.
+func newAuditEntries(report *Report) []AuditEntry {
+	entries := make([]AuditEntry, 0, len(report.Diffs))
+
+	for _, diff := range report.Diffs {
+		entry := AuditEntry{
+			Time:      report.Metadata.StartedAt.UTC(),
+			RunID:     report.Metadata.RunID,
+			Event:     auditEvent(diff),
+			Asset:     assetKey(diff.Asset),
+			AssetType: diff.Asset.AssetType,
+			Name:      diff.Asset.Name,
+			Project:   diff.Asset.Project,
+			IPAddress: diff.Asset.IPAddress,
+			Changes:   diff.Changes,
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func auditEvent(diff AssetDiff) string {
+	switch {
+	case diff.Type == DiffAdded:
+		return auditEventAdded
+	case diff.Type == DiffRemoved:
+		return auditEventRemoved
+	case diff.Previous != nil && diff.Previous.IPAddress != diff.Asset.IPAddress:
+		return auditEventIPReassigned
+	case diff.Previous != nil && diff.Previous.Status != diff.Asset.Status:
+		return auditEventStateChanged
+	default:
+		return auditEventChanged
+	}
+}
+
+// encodeAuditEntries encodes the entries as JSON lines.
+func encodeAuditEntries(entries []AuditEntry) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// expiredAuditDay reports whether all entries of the day are before the time.
+func expiredAuditDay(day string, before time.Time) bool {
+	t, err := time.Parse(auditLogDateLayout, day)
+	if err != nil {
+		return false
+	}
+
+	return !t.AddDate(0, 0, 1).After(before)
+}
+
+// FileAuditLog appends the entries to a JSON lines file per day in a local directory.
+type FileAuditLog struct {
+	dir string
+}
+
+// NewFileAuditLog creates a new audit log in the directory.
+func NewFileAuditLog(dir string) *FileAuditLog {
+	return &FileAuditLog{dir: dir}
+}
+
+// Append adds the entries to the file of the day of the run.
+func (l *FileAuditLog) Append(_ context.Context, entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	data, err := encodeAuditEntries(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(l.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	name := filepath.Join(l.dir, entries[0].Time.Format(auditLogDateLayout)+auditLogExtension)
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the files of the days before the time.
+func (l *FileAuditLog) Prune(_ context.Context, before time.Time) (int, error) {
+	files, err := os.ReadDir(l.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit log directory: %w", err)
+	}
+
+	deleted := 0
+
+	for _, file := range files {
+		day, ok := strings.CutSuffix(file.Name(), auditLogExtension)
+		if file.IsDir() || !ok || !expiredAuditDay(day, before) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(l.dir, file.Name())); err != nil {
+			return deleted, fmt.Errorf("failed to delete audit log file: %w", err)
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// GCSAuditLog writes the entries of every run to a JSON lines object under a Cloud
+// Storage prefix, as PREFIX/DAY/RUN_ID.jsonl, since objects cannot be appended to.
+type GCSAuditLog struct {
+	service *storage.Service
+	bucket  string
+	prefix  string
+}
+
+// NewGCSAuditLog creates a new audit log of the gs://BUCKET/PREFIX path.
+func NewGCSAuditLog(ctx context.Context, path string, opts ...option.ClientOption) (*GCSAuditLog, error) {
+	bucket, prefix, err := parseAuditLogPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := storage.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+
+	return &GCSAuditLog{service: s, bucket: bucket, prefix: prefix}, nil
+}
+
+// parseAuditLogPath splits a gs://BUCKET/PREFIX path into the bucket and the prefix.
+func parseAuditLogPath(gcsPath string) (string, string, error) {
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(gcsPath, gcsScheme), "/")
+	prefix = strings.Trim(prefix, "/")
+
+	if bucket == "" || prefix == "" {
+		return "", "", fmt.Errorf("%w: %q", errInvalidAuditLogPath, gcsPath)
+	}
+
+	return bucket, prefix, nil
+}
+
+// Append uploads the entries as a new object.
+func (l *GCSAuditLog) Append(ctx context.Context, entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	data, err := encodeAuditEntries(entries)
+	if err != nil {
+		return err
+	}
+
+	name := path.Join(l.prefix, entries[0].Time.Format(auditLogDateLayout), entries[0].RunID+auditLogExtension)
+
+	_, err = l.service.Objects.Insert(l.bucket, &storage.Object{Name: name, ContentType: "application/jsonl"}).
+		Media(bytes.NewReader(data)).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to upload audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Prune deletes the objects of the days before the time.
+func (l *GCSAuditLog) Prune(ctx context.Context, before time.Time) (int, error) {
+	expired := []string{}
+
+	err := l.service.Objects.List(l.bucket).Prefix(l.prefix+"/").Fields("items(name)", "nextPageToken").
+		Pages(ctx, func(objects *storage.Objects) error {
+			for _, object := range objects.Items {
+				day, _, _ := strings.Cut(strings.TrimPrefix(object.Name, l.prefix+"/"), "/")
+				if expiredAuditDay(day, before) {
+					expired = append(expired, object.Name)
+				}
+			}
+
+			return nil
+		})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list audit log objects: %w", err)
+	}
+
+	for i, name := range expired {
+		if err := l.service.Objects.Delete(l.bucket, name).Context(ctx).Do(); err != nil {
+			return i, fmt.Errorf("failed to delete audit log object: %w", err)
+		}
+	}
+
+	return len(expired), nil
+}
+
+// auditLogSink appends the changes of every report to the audit log and prunes the
+// entries older than the retention period, if set.
+type auditLogSink struct {
+	log           AuditLog
+	retentionDays int
+	logger        *slog.Logger
+}
+
+// Name returns the name of the sink.
+func (s auditLogSink) Name() string {
+	return "audit-log"
+}
+
+// Publish appends the changes of the report to the audit log.
+func (s auditLogSink) Publish(ctx context.Context, report *Report) error {
+	entries := newAuditEntries(report)
+	if err := s.log.Append(ctx, entries); err != nil {
+		return err
+	}
+
+	s.logger.DebugContext(ctx, "Appended changes to the audit log", slog.Int("number_of_entries", len(entries)))
+
+	if s.retentionDays == 0 {
+		return nil
+	}
+
+	deleted, err := s.log.Prune(ctx, report.Metadata.StartedAt.AddDate(0, 0, -s.retentionDays))
+	if err != nil {
+		return err
+	}
+
+	s.logger.DebugContext(ctx, "Pruned the audit log", slog.Int("number_of_files", deleted))
+
+	return nil
+}
+
+// Close is a no-op, as the audit logs do not hold any resources.
+func (s auditLogSink) Close() error {
+	return nil
+}
@@ -0,0 +1,185 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestNewAuditEntries(t *testing.T) {
+	startedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run1", StartedAt: startedAt},
+		Diffs: diffAssets(
+			[]ProcessedAsset{
+				{ResourceName: "r/a", Name: "a", IPAddress: "203.0.113.1", Status: "IN_USE"},
+				{ResourceName: "r/b", Name: "b", IPAddress: "203.0.113.2", Status: "RESERVED"},
+				{ResourceName: "r/c", Name: "c", IPAddress: "203.0.113.3", Status: "RESERVED"},
+				{ResourceName: "r/d", Name: "d", IPAddress: "203.0.113.4"},
+			},
+			[]ProcessedAsset{
+				{ResourceName: "r/a", Name: "a", IPAddress: "203.0.113.9", Status: "IN_USE"},
+				{ResourceName: "r/b", Name: "b", IPAddress: "203.0.113.2", Status: "IN_USE"},
+				{ResourceName: "r/c", Name: "c", IPAddress: "203.0.113.3", Status: "RESERVED", Labels: map[string]string{"env": "prod"}},
+				{ResourceName: "r/e", Name: "e", IPAddress: "203.0.113.5"},
+			},
+		),
+	}
+
+	events := map[string]string{}
+	for _, entry := range newAuditEntries(report) {
+		if entry.RunID != "run1" || !entry.Time.Equal(startedAt) {
+			t.Errorf("unexpected entry metadata %+v", entry)
+		}
+
+		events[entry.Asset] = entry.Event
+	}
+
+	want := map[string]string{
+		"r/a": auditEventIPReassigned,
+		"r/b": auditEventStateChanged,
+		"r/c": auditEventChanged,
+		"r/d": auditEventRemoved,
+		"r/e": auditEventAdded,
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	ctx := t.Context()
+	dir := filepath.Join(t.TempDir(), "audit")
+	auditLog := NewFileAuditLog(dir)
+
+	day1 := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	for _, entry := range []AuditEntry{
+		{Time: day1, RunID: "run1", Event: auditEventAdded, Asset: "a"},
+		{Time: day1, RunID: "run2", Event: auditEventRemoved, Asset: "a"},
+		{Time: day2, RunID: "run3", Event: auditEventAdded, Asset: "b"},
+	} {
+		if err := auditLog.Append(ctx, []AuditEntry{entry}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "2025-03-01.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected 2 entries on the first day, got %d", lines)
+	}
+
+	// The second day is not pruned, as it has entries after the cutoff.
+	deleted, err := auditLog.Prune(ctx, day2)
+	if err != nil || deleted != 1 {
+		t.Fatalf("Prune() = %d, %v, want 1", deleted, err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "2025-03-02.jsonl" {
+		t.Errorf("unexpected files after pruning %v", files)
+	}
+
+	deleted, err = NewFileAuditLog(filepath.Join(dir, "missing")).Prune(ctx, day2)
+	if err != nil || deleted != 0 {
+		t.Errorf("Prune() of a missing directory = %d, %v", deleted, err)
+	}
+}
+
+func TestGCSAuditLog(t *testing.T) {
+	var uploaded, deleted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/audit/o":
+			// The multipart upload contains the object metadata followed by the media.
+			body, _ := io.ReadAll(r.Body)
+			var object struct {
+				Name string `json:"name"`
+			}
+			for part := range strings.SplitSeq(string(body), "\r\n") {
+				if json.Unmarshal([]byte(part), &object) == nil && object.Name != "" {
+					uploaded = append(uploaded, object.Name)
+
+					break
+				}
+			}
+
+			_, _ = w.Write([]byte(`{"name": "changes/2025-03-02/run3.jsonl"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/audit/o":
+			if prefix := r.URL.Query().Get("prefix"); prefix != "changes/" {
+				t.Errorf("unexpected prefix %q", prefix)
+			}
+
+			_, _ = w.Write([]byte(`{"items": [
+				{"name": "changes/2025-02-28/run1.jsonl"},
+				{"name": "changes/2025-03-01/run2.jsonl"},
+				{"name": "changes/2025-03-02/run3.jsonl"},
+				{"name": "changes/README"}
+			]}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/storage/v1/b/audit/o/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	auditLog, err := NewGCSAuditLog(ctx, "gs://audit/changes/",
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewGCSAuditLog failed: %v", err)
+	}
+
+	sink := auditLogSink{log: auditLog, retentionDays: 1, logger: slog.New(slog.DiscardHandler)}
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run3", StartedAt: time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC)},
+		Diffs:    []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "b"}}},
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if want := []string{"changes/2025-03-02/run3.jsonl"}; !reflect.DeepEqual(uploaded, want) {
+		t.Errorf("uploaded = %v, want %v", uploaded, want)
+	}
+
+	if want := []string{"changes/2025-02-28/run1.jsonl"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted = %v, want %v", deleted, want)
+	}
+}
+
+func TestParseAuditLogPath(t *testing.T) {
+	bucket, prefix, err := parseAuditLogPath("gs://audit/changes/")
+	if err != nil || bucket != "audit" || prefix != "changes" {
+		t.Errorf("parseAuditLogPath() = %q, %q, %v", bucket, prefix, err)
+	}
+
+	for _, path := range []string{"gs://audit", "gs:///changes", "gs://audit/"} {
+		if _, _, err := parseAuditLogPath(path); err == nil {
+			t.Errorf("expected an error for %q", path)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package assetwatcher
+
+import (
+	"fmt"
+	"os"
+)
+
+// BaselineSummary represents the comparison of the discovered assets with the baseline.
+type BaselineSummary struct {
+	BaselineAssets    int `json:"baselineAssets"`
+	NewAssets         int `json:"newAssets"`
+	DisappearedAssets int `json:"disappearedAssets"`
+}
+
+// LoadBaseline reads the known and accepted assets from a JSON report of a previous run.
+// An empty path results in no baseline.
+func LoadBaseline(path string) ([]ProcessedAsset, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open baseline file: %w", err)
+	}
+	defer f.Close()
+
+	report, err := ReadReport(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file %s: %w", path, err)
+	}
+
+	return report.Assets, nil
+}
+
+// applyBaseline returns the assets that are not in the baseline, along with the diffs listing
+// them as added and the baseline assets that disappeared as removed.
+func applyBaseline(assets, baseline []ProcessedAsset) ([]ProcessedAsset, []AssetDiff, BaselineSummary) {
+	known := make(map[string]bool, len(baseline))
+	for _, asset := range baseline {
+		known[assetKey(asset)] = true
+	}
+
+	discovered := make(map[string]bool, len(assets))
+	newAssets := []ProcessedAsset{}
+	diffs := []AssetDiff{}
+
+	for _, asset := range assets {
+		key := assetKey(asset)
+		discovered[key] = true
+
+		if !known[key] {
+			newAssets = append(newAssets, asset)
+			diffs = append(diffs, AssetDiff{Type: DiffAdded, Asset: asset})
+		}
+	}
+
+	disappeared := 0
+
+	for _, asset := range baseline {
+		if !discovered[assetKey(asset)] {
+			diffs = append(diffs, AssetDiff{Type: DiffRemoved, Asset: asset})
+			disappeared++
+		}
+	}
+
+	return newAssets, diffs, BaselineSummary{
+		BaselineAssets:    len(baseline),
+		NewAssets:         len(newAssets),
+		DisappearedAssets: disappeared,
+	}
+}
+
+// assetKey identifies an asset across runs by its full resource name, or by its type,
+// project, name, and address for assets without one.
+func assetKey(asset ProcessedAsset) string {
+	if asset.ResourceName != "" {
+		return asset.ResourceName
+	}
+
+	return asset.AssetType + "/" + asset.Project + "/" + asset.Name + "/" + asset.IPAddress
+}
@@ -0,0 +1,63 @@
+package assetwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	content := `{"metadata": {"runId": "abc"}, "assets": [{"name": "a1", "ipAddress": "203.0.113.1"}]}`
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline failed: %v", err)
+	}
+
+	if len(got) != 1 || got[0].Name != "a1" {
+		t.Errorf("unexpected baseline %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadBaseline(path); err == nil {
+		t.Error("expected an error for an invalid baseline")
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	baseline := []ProcessedAsset{
+		{Name: "kept", ResourceName: "//compute.googleapis.com/projects/p/regions/r/addresses/kept", IPAddress: "203.0.113.1"},
+		{Name: "gone", ResourceName: "//compute.googleapis.com/projects/p/regions/r/addresses/gone", IPAddress: "203.0.113.2"},
+		{Name: "legacy", Project: "p", IPAddress: "203.0.113.3"},
+	}
+	assets := []ProcessedAsset{
+		{Name: "kept", ResourceName: "//compute.googleapis.com/projects/p/regions/r/addresses/kept", IPAddress: "203.0.113.1"},
+		{Name: "legacy", Project: "p", IPAddress: "203.0.113.3"},
+		{Name: "new", ResourceName: "//compute.googleapis.com/projects/p/regions/r/addresses/new", IPAddress: "203.0.113.4"},
+	}
+
+	got, diffs, summary := applyBaseline(assets, baseline)
+
+	if len(got) != 1 || got[0].Name != "new" {
+		t.Errorf("expected only the new asset, got %+v", got)
+	}
+
+	wantDiffs := []AssetDiff{{Type: DiffAdded, Asset: assets[2]}, {Type: DiffRemoved, Asset: baseline[1]}}
+	if !reflect.DeepEqual(diffs, wantDiffs) {
+		t.Errorf("applyBaseline() diffs = %+v, want %+v", diffs, wantDiffs)
+	}
+
+	want := BaselineSummary{BaselineAssets: 3, NewAssets: 1, DisappearedAssets: 1}
+	if summary != want {
+		t.Errorf("applyBaseline() summary = %+v, want %+v", summary, want)
+	}
+}
@@ -0,0 +1,141 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+const bigQueryPollInterval = 2 * time.Second
+
+var errInvalidTableID = errors.New("invalid BigQuery table ID")
+
+// bigQueryTable is a fully qualified BigQuery table reference.
+type bigQueryTable struct {
+	project string
+	dataset string
+	table   string
+}
+
+// parseBigQueryTable parses a table ID in the project.dataset.table format.
+func parseBigQueryTable(s string) (bigQueryTable, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return bigQueryTable{}, fmt.Errorf("%w: %q, expected project.dataset.table", errInvalidTableID, s)
+	}
+
+	return bigQueryTable{project: parts[0], dataset: parts[1], table: parts[2]}, nil
+}
+
+// String returns the table reference quoted for Standard SQL.
+func (t bigQueryTable) String() string {
+	return fmt.Sprintf("`%s.%s.%s`", t.project, t.dataset, t.table)
+}
+
+// stringArrayParameter returns a named ARRAY<STRING> query parameter.
+func stringArrayParameter(name string, values []string) *bigquery.QueryParameter {
+	arrayValues := make([]*bigquery.QueryParameterValue, 0, len(values))
+	for _, v := range values {
+		arrayValues = append(arrayValues, &bigquery.QueryParameterValue{Value: v})
+	}
+
+	return &bigquery.QueryParameter{
+		Name:           name,
+		ParameterType:  &bigquery.QueryParameterType{Type: "ARRAY", ArrayType: &bigquery.QueryParameterType{Type: "STRING"}},
+		ParameterValue: &bigquery.QueryParameterValue{ArrayValues: arrayValues},
+	}
+}
+
+// timestampParameter returns a named TIMESTAMP query parameter.
+func timestampParameter(name string, t time.Time) *bigquery.QueryParameter {
+	return &bigquery.QueryParameter{
+		Name:           name,
+		ParameterType:  &bigquery.QueryParameterType{Type: "TIMESTAMP"},
+		ParameterValue: &bigquery.QueryParameterValue{Value: t.UTC().Format("2006-01-02 15:04:05.999999-07:00")},
+	}
+}
+
+// runQuery runs a Standard SQL query and returns all result rows as strings.
+// NULL cells are returned as empty strings.
+func runQuery(
+	ctx context.Context,
+	service *bigquery.Service,
+	project, query string,
+	params ...*bigquery.QueryParameter,
+) ([][]string, error) {
+	resp, err := service.Jobs.Query(project, &bigquery.QueryRequest{
+		Query:           query,
+		UseLegacySql:    new(bool),
+		ParameterMode:   "NAMED",
+		QueryParameters: params,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+
+	rows := convertRows(resp.Rows)
+	if resp.JobComplete && resp.PageToken == "" {
+		return rows, nil
+	}
+
+	jobID := resp.JobReference.JobId
+	location := resp.JobReference.Location
+	pageToken := resp.PageToken
+
+	if !resp.JobComplete {
+		rows = nil
+	}
+
+	for {
+		call := service.Jobs.GetQueryResults(project, jobID).Location(location).Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		results, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get query results: %w", err)
+		}
+
+		if !results.JobComplete {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("failed to get query results: %w", ctx.Err())
+			case <-time.After(bigQueryPollInterval):
+			}
+
+			continue
+		}
+
+		rows = append(rows, convertRows(results.Rows)...)
+
+		if results.PageToken == "" {
+			return rows, nil
+		}
+
+		pageToken = results.PageToken
+	}
+}
+
+func convertRows(tableRows []*bigquery.TableRow) [][]string {
+	rows := make([][]string, 0, len(tableRows))
+
+	for _, tableRow := range tableRows {
+		row := make([]string, 0, len(tableRow.F))
+		for _, cell := range tableRow.F {
+			if v, ok := cell.V.(string); ok {
+				row = append(row, v)
+			} else {
+				row = append(row, "")
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
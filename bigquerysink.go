@@ -0,0 +1,255 @@
+package assetwatcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// bigQueryInsertBatchSize is the number of rows of a streaming insert request, within the
+// recommended maximum of 500 rows.
+// https://cloud.google.com/bigquery/quotas#streaming_inserts
+const bigQueryInsertBatchSize = 500
+
+var errBigQueryInsert = errors.New("failed to insert rows to BigQuery")
+
+// bigQueryAssetSchema is the schema of the table of ASSET_WATCHER_BIGQUERY_TABLE. Every
+// row is an asset of a scan, identified by run_id and scan_time.
+var bigQueryAssetSchema = &bigquery.TableSchema{
+	Fields: []*bigquery.TableFieldSchema{
+		{Name: "run_id", Type: "STRING", Mode: "REQUIRED"},
+		{Name: "scan_time", Type: "TIMESTAMP", Mode: "REQUIRED"},
+		{Name: "name", Type: "STRING"},
+		{Name: "project", Type: "STRING"},
+		{Name: "location", Type: "STRING"},
+		{Name: "status", Type: "STRING"},
+		{Name: "ip_address", Type: "STRING"},
+		{Name: "created_at", Type: "STRING"},
+		{Name: "resource_name", Type: "STRING"},
+		{Name: "asset_type", Type: "STRING"},
+		{Name: "address_type", Type: "STRING"},
+		{Name: "estimated_monthly_cost", Type: "FLOAT"},
+		{Name: "labels", Type: "RECORD", Mode: "REPEATED", Fields: bigQueryKeyValueFields()},
+		{Name: "attributes", Type: "RECORD", Mode: "REPEATED", Fields: bigQueryKeyValueFields()},
+		{Name: "disposition", Type: "STRING"},
+		{Name: "category", Type: "STRING"},
+		{Name: "compliance", Type: "STRING"},
+		{Name: "country", Type: "STRING"},
+		{Name: "region", Type: "STRING"},
+		{Name: "byoip", Type: "BOOLEAN"},
+		{Name: "attachments", Type: "STRING", Mode: "REPEATED"},
+		{Name: "exposed_ports", Type: "STRING", Mode: "REPEATED"},
+		{Name: "blocklists", Type: "STRING", Mode: "REPEATED"},
+		{Name: "partner_peers", Type: "STRING", Mode: "REPEATED"},
+	},
+}
+
+func bigQueryKeyValueFields() []*bigquery.TableFieldSchema {
+	return []*bigquery.TableFieldSchema{
+		{Name: "key", Type: "STRING", Mode: "REQUIRED"},
+		{Name: "value", Type: "STRING"},
+	}
+}
+
+// BigQuerySink streams the assets of every scan into a BigQuery table, so that the
+// historical inventory can be queried and visualized, for example in Looker Studio.
+type BigQuerySink struct {
+	service *bigquery.Service
+	table   bigQueryTable
+	logger  *slog.Logger
+}
+
+// NewBigQuerySink creates a new BigQuery sink for the configured table.
+func NewBigQuerySink(ctx context.Context, logger *slog.Logger, cfg *Config, opts ...option.ClientOption) (*BigQuerySink, error) {
+	table, err := parseBigQueryTable(cfg.BigQueryTable)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := bigquery.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
+	}
+
+	return &BigQuerySink{
+		service: s,
+		table:   table,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// Name returns the name of the sink.
+func (s *BigQuerySink) Name() string {
+	return "bigquery"
+}
+
+// Publish creates the table if it does not exist and streams the assets of the report into it.
+func (s *BigQuerySink) Publish(ctx context.Context, report *Report) error {
+	if err := s.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	rows := newBigQueryRows(report)
+
+	for start := 0; start < len(rows); start += bigQueryInsertBatchSize {
+		end := min(start+bigQueryInsertBatchSize, len(rows))
+
+		if err := s.insert(ctx, rows[start:end]); err != nil {
+			return err
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Inserted assets to BigQuery",
+		slog.String("table", s.table.String()),
+		slog.Int("number_of_rows", len(rows)),
+	)
+
+	return nil
+}
+
+// Close is a no-op, as the BigQuery client does not hold any resources.
+func (s *BigQuerySink) Close() error {
+	return nil
+}
+
+// ensureTable creates the table, partitioned by day of scan_time, if it does not exist.
+// The schema of an existing table is left as is.
+func (s *BigQuerySink) ensureTable(ctx context.Context) error {
+	_, err := s.service.Tables.Get(s.table.project, s.table.dataset, s.table.table).Context(ctx).Do()
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+		return fmt.Errorf("failed to get BigQuery table %s: %w", s.table, err)
+	}
+
+	_, err = s.service.Tables.Insert(s.table.project, s.table.dataset, &bigquery.Table{
+		TableReference: &bigquery.TableReference{
+			ProjectId: s.table.project,
+			DatasetId: s.table.dataset,
+			TableId:   s.table.table,
+		},
+		Description:      "IP address inventory collected by asset-watcher",
+		Schema:           bigQueryAssetSchema,
+		TimePartitioning: &bigquery.TimePartitioning{Type: "DAY", Field: "scan_time"},
+	}).Context(ctx).Do()
+
+	// Another run may have created the table in the meantime.
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to create BigQuery table %s: %w", s.table, err)
+	}
+
+	s.logger.InfoContext(ctx, "Created BigQuery table", slog.String("table", s.table.String()))
+
+	return nil
+}
+
+func (s *BigQuerySink) insert(ctx context.Context, rows []*bigquery.TableDataInsertAllRequestRows) error {
+	resp, err := s.service.Tabledata.InsertAll(s.table.project, s.table.dataset, s.table.table,
+		&bigquery.TableDataInsertAllRequest{Rows: rows}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errBigQueryInsert, err)
+	}
+
+	if len(resp.InsertErrors) > 0 {
+		first := resp.InsertErrors[0]
+
+		var reason string
+		if len(first.Errors) > 0 {
+			reason = first.Errors[0].Message
+		}
+
+		return fmt.Errorf("%w: %d rows rejected, row %d: %s", errBigQueryInsert, len(resp.InsertErrors), first.Index, reason)
+	}
+
+	return nil
+}
+
+// newBigQueryRows converts the assets of the report to rows of the table. The insert ID
+// of a row is derived from the run and the asset, so that retried inserts are deduplicated.
+func newBigQueryRows(report *Report) []*bigquery.TableDataInsertAllRequestRows {
+	scanTime := report.Metadata.StartedAt.UTC().Format(time.RFC3339Nano)
+	rows := make([]*bigquery.TableDataInsertAllRequestRows, 0, len(report.Assets))
+
+	for _, asset := range report.Assets {
+		id := asset.ResourceName
+		if id == "" {
+			id = asset.Project + "/" + asset.Location + "/" + asset.Name
+		}
+
+		insertID := sha256.Sum256([]byte(report.Metadata.RunID + ":" + id))
+
+		rows = append(rows, &bigquery.TableDataInsertAllRequestRows{
+			InsertId: hex.EncodeToString(insertID[:]),
+			Json:     newBigQueryRow(report.Metadata.RunID, scanTime, asset),
+		})
+	}
+
+	return rows
+}
+
+func newBigQueryRow(runID, scanTime string, asset ProcessedAsset) map[string]bigquery.JsonValue {
+	return map[string]bigquery.JsonValue{
+		"run_id":                 runID,
+		"scan_time":              scanTime,
+		"name":                   asset.Name,
+		"project":                asset.Project,
+		"location":               asset.Location,
+		"status":                 asset.Status,
+		"ip_address":             asset.IPAddress,
+		"created_at":             asset.CreatedAt,
+		"resource_name":          asset.ResourceName,
+		"asset_type":             asset.AssetType,
+		"address_type":           asset.AddressType,
+		"estimated_monthly_cost": asset.EstimatedMonthlyCost,
+		"labels":                 bigQueryKeyValues(asset.Labels),
+		"attributes":             bigQueryKeyValues(asset.Attributes),
+		"disposition":            asset.Disposition,
+		"category":               asset.Category,
+		"compliance":             asset.Compliance,
+		"country":                asset.Country,
+		"region":                 asset.Region,
+		"byoip":                  asset.BYOIP,
+		"attachments":            bigQueryRepeated(asset.Attachments),
+		"exposed_ports":          bigQueryRepeated(asset.ExposedPorts),
+		"blocklists":             bigQueryRepeated(asset.Blocklists),
+		"partner_peers":          bigQueryRepeated(asset.PartnerPeers),
+	}
+}
+
+// bigQueryKeyValues converts a map to repeated key and value records, sorted by key.
+func bigQueryKeyValues(m map[string]string) []map[string]string {
+	records := make([]map[string]string, 0, len(m))
+
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		records = append(records, map[string]string{"key": key, "value": m[key]})
+	}
+
+	return records
+}
+
+// bigQueryRepeated returns an empty slice for nil, as BigQuery rejects null repeated fields.
+func bigQueryRepeated(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
@@ -0,0 +1,168 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestNewBigQueryRows(t *testing.T) {
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run-1", StartedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Assets: []ProcessedAsset{
+			{
+				Name:         "nat-1",
+				Project:      "proj-A",
+				IPAddress:    "203.0.113.1",
+				ResourceName: "//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/nat-1",
+				Labels:       map[string]string{"team": "net", "env": "prod"},
+			},
+			{Name: "nat-2", Project: "proj-A", Location: "us-central1"},
+		},
+	}
+
+	rows := newBigQueryRows(report)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	row := rows[0].Json
+	if row["run_id"] != "run-1" || row["scan_time"] != "2025-01-02T03:04:05Z" || row["ip_address"] != "203.0.113.1" {
+		t.Errorf("unexpected row: %v", row)
+	}
+
+	labels, _ := json.Marshal(row["labels"])
+	if string(labels) != `[{"key":"env","value":"prod"},{"key":"team","value":"net"}]` {
+		t.Errorf("unexpected labels: %s", labels)
+	}
+
+	if ports, ok := row["exposed_ports"].([]string); !ok || ports == nil {
+		t.Errorf("expected an empty repeated field, got %#v", row["exposed_ports"])
+	}
+
+	if rows[0].InsertId == rows[1].InsertId {
+		t.Error("expected distinct insert IDs")
+	}
+
+	if again := newBigQueryRows(report); again[0].InsertId != rows[0].InsertId {
+		t.Error("expected insert IDs to be stable across retries")
+	}
+}
+
+func TestBigQuerySink_Publish(t *testing.T) {
+	var (
+		created  bool
+		inserted int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/tables/assets"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "Not found: Table proj:inventory.assets"}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/datasets/inventory/tables"):
+			var table struct {
+				Schema struct {
+					Fields []struct {
+						Name string `json:"name"`
+					} `json:"fields"`
+				} `json:"schema"`
+				TimePartitioning struct {
+					Field string `json:"field"`
+				} `json:"timePartitioning"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&table)
+
+			created = true
+
+			if len(table.Schema.Fields) < 2 || table.Schema.Fields[0].Name != "run_id" || table.Schema.Fields[1].Name != "scan_time" {
+				t.Errorf("unexpected schema: %+v", table.Schema)
+			}
+
+			if table.TimePartitioning.Field != "scan_time" {
+				t.Errorf("expected the table to be partitioned by scan_time, got %q", table.TimePartitioning.Field)
+			}
+
+			_, _ = w.Write([]byte(`{}`))
+		case strings.HasSuffix(r.URL.Path, "/tables/assets/insertAll"):
+			var request struct {
+				Rows []json.RawMessage `json:"rows"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			inserted += len(request.Rows)
+
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	sink, err := NewBigQuerySink(ctx, slog.New(slog.DiscardHandler), &Config{BigQueryTable: "proj.inventory.assets"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewBigQuerySink failed: %v", err)
+	}
+
+	assets := make([]ProcessedAsset, bigQueryInsertBatchSize+1)
+	for i := range assets {
+		assets[i] = ProcessedAsset{Name: "addr", Project: "proj-A", Location: string(rune('a' + i%26))}
+	}
+
+	if err := sink.Publish(ctx, &Report{Metadata: RunMetadata{RunID: "run-1"}, Assets: assets}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if !created {
+		t.Error("expected the table to be created")
+	}
+
+	if inserted != len(assets) {
+		t.Errorf("expected %d inserted rows, got %d", len(assets), inserted)
+	}
+}
+
+func TestBigQuerySink_PublishInsertErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/insertAll") {
+			_, _ = w.Write([]byte(`{"insertErrors": [{"index": 0, "errors": [{"reason": "invalid", "message": "no such field: foo"}]}]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	sink, err := NewBigQuerySink(ctx, slog.New(slog.DiscardHandler), &Config{BigQueryTable: "proj.inventory.assets"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewBigQuerySink failed: %v", err)
+	}
+
+	err = sink.Publish(ctx, &Report{Assets: []ProcessedAsset{{Name: "nat-1"}}})
+	if err == nil || !strings.Contains(err.Error(), "no such field: foo") {
+		t.Errorf("expected the insert error, got %v", err)
+	}
+}
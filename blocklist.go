@@ -0,0 +1,219 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	abuseIPDBEndpoint        = "https://api.abuseipdb.com/api/v2/check"
+	abuseIPDBMaxAgeInDays    = 90
+	defaultAbuseIPDBMinScore = 50
+)
+
+var (
+	errDNSBLUnavailable     = errors.New("DNSBL refused the query")
+	errAbuseIPDBUnavailable = errors.New("AbuseIPDB check failed")
+)
+
+// BlocklistChecker is an interface for checking whether an address is listed on a blocklist.
+type BlocklistChecker interface {
+	Name() string
+	Listed(ctx context.Context, addr netip.Addr) (bool, error)
+}
+
+// DNSBLChecker checks addresses against a DNS-based blocklist, such as zen.spamhaus.org.
+type DNSBLChecker struct {
+	zone       string
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewDNSBLChecker creates a new checker of the DNSBL zone using the system resolver.
+func NewDNSBLChecker(zone string) *DNSBLChecker {
+	return &DNSBLChecker{zone: strings.TrimSuffix(zone, "."), lookupHost: net.DefaultResolver.LookupHost}
+}
+
+// Name returns the zone of the blocklist.
+func (c *DNSBLChecker) Name() string {
+	return c.zone
+}
+
+// Listed reports whether the blocklist has an entry for the address. Listed addresses resolve
+// to 127.0.0.0/8, while responses in 127.255.255.0/24 signal refused queries, for example
+// of public resolvers, and are reported as errors.
+func (c *DNSBLChecker) Listed(ctx context.Context, addr netip.Addr) (bool, error) {
+	results, err := c.lookupHost(ctx, dnsblName(addr)+"."+c.zone)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to query %s: %w", c.zone, err)
+	}
+
+	listed := false
+
+	for _, result := range results {
+		a, err := netip.ParseAddr(result)
+		if err != nil || !netip.MustParsePrefix("127.0.0.0/8").Contains(a) {
+			continue
+		}
+
+		if netip.MustParsePrefix("127.255.255.0/24").Contains(a) {
+			return false, fmt.Errorf("%w: %s returned %s", errDNSBLUnavailable, c.zone, result)
+		}
+
+		listed = true
+	}
+
+	return listed, nil
+}
+
+// dnsblName returns the reversed octets of an IPv4 address, or the reversed nibbles
+// of an IPv6 address, as queried in DNSBL zones.
+func dnsblName(addr netip.Addr) string {
+	addr = addr.Unmap()
+	labels := []string{}
+
+	if addr.Is4() {
+		for _, b := range addr.As4() {
+			labels = append(labels, strconv.Itoa(int(b)))
+		}
+	} else {
+		for _, b := range addr.As16() {
+			labels = append(labels, strconv.FormatUint(uint64(b>>4), 16), strconv.FormatUint(uint64(b&0x0f), 16))
+		}
+	}
+
+	slices.Reverse(labels)
+
+	return strings.Join(labels, ".")
+}
+
+// abuseIPDBResponse is the response of the AbuseIPDB check endpoint.
+// https://docs.abuseipdb.com/#check-endpoint
+type abuseIPDBResponse struct {
+	Data struct {
+		AbuseConfidenceScore int `json:"abuseConfidenceScore"`
+	} `json:"data"`
+}
+
+// AbuseIPDBChecker checks addresses against the reports of AbuseIPDB.
+type AbuseIPDBChecker struct {
+	client   *http.Client
+	endpoint string
+	key      string
+	minScore int
+}
+
+// NewAbuseIPDBChecker creates a new AbuseIPDB checker listing the addresses with an abuse
+// confidence score of at least the configured minimum.
+func NewAbuseIPDBChecker(cfg *Config, client *http.Client) *AbuseIPDBChecker {
+	return &AbuseIPDBChecker{
+		client:   client,
+		endpoint: abuseIPDBEndpoint,
+		key:      cfg.AbuseIPDBKey,
+		minScore: cfg.AbuseIPDBMinScore,
+	}
+}
+
+// Name returns the name of the blocklist.
+func (c *AbuseIPDBChecker) Name() string {
+	return "abuseipdb"
+}
+
+// Listed reports whether the abuse confidence score of the address reaches the minimum score.
+func (c *AbuseIPDBChecker) Listed(ctx context.Context, addr netip.Addr) (bool, error) {
+	query := url.Values{"ipAddress": {addr.String()}, "maxAgeInDays": {strconv.Itoa(abuseIPDBMaxAgeInDays)}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Key", c.key)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%w: %s", errAbuseIPDBUnavailable, resp.Status)
+	}
+
+	result := abuseIPDBResponse{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Data.AbuseConfidenceScore >= c.minScore, nil
+}
+
+// newBlocklistCheckers creates the checkers of the configured blocklists.
+func newBlocklistCheckers(cfg *Config) []BlocklistChecker {
+	checkers := []BlocklistChecker{}
+
+	for _, zone := range splitString(cfg.DNSBLZones, ",") {
+		checkers = append(checkers, NewDNSBLChecker(zone))
+	}
+
+	if cfg.AbuseIPDBKey != "" {
+		checkers = append(checkers, NewAbuseIPDBChecker(cfg, newHTTPClient(cfg)))
+	}
+
+	return checkers
+}
+
+// annotateBlocklists sets the blocklists listing any public address of each asset.
+// Failed checks are logged and the address is considered not listed by that blocklist.
+func annotateBlocklists(
+	ctx context.Context,
+	logger *slog.Logger,
+	checkers []BlocklistChecker,
+	assets []ProcessedAsset,
+) []ProcessedAsset {
+	for i, asset := range assets {
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || !isPublicAddress(asset, addr) {
+				continue
+			}
+
+			for _, checker := range checkers {
+				listed, err := checker.Listed(ctx, addr)
+				if err != nil {
+					logger.WarnContext(ctx, "failed to check a blocklist",
+						slog.String("blocklist", checker.Name()), slog.String("address", address), slog.Any("error", err))
+
+					continue
+				}
+
+				if listed && !slices.Contains(assets[i].Blocklists, checker.Name()) {
+					assets[i].Blocklists = append(assets[i].Blocklists, checker.Name())
+				}
+			}
+		}
+	}
+
+	return assets
+}
@@ -0,0 +1,139 @@
+package assetwatcher
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+// fakeBlocklistChecker is a mock implementation of the BlocklistChecker.
+type fakeBlocklistChecker struct {
+	name   string
+	listed map[string]bool
+}
+
+func (f *fakeBlocklistChecker) Name() string {
+	return f.name
+}
+
+func (f *fakeBlocklistChecker) Listed(_ context.Context, addr netip.Addr) (bool, error) {
+	return f.listed[addr.String()], nil
+}
+
+func TestDNSBLName(t *testing.T) {
+	tests := map[string]string{
+		"203.0.113.7": "7.113.0.203",
+		"2001:db8::1": "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2",
+	}
+
+	for ip, want := range tests {
+		if got := dnsblName(netip.MustParseAddr(ip)); got != want {
+			t.Errorf("dnsblName(%s) = %s, want %s", ip, got, want)
+		}
+	}
+}
+
+func TestDNSBLChecker_Listed(t *testing.T) {
+	responses := map[string][]string{
+		"7.113.0.203.zen.spamhaus.org": {"127.0.0.2", "127.0.0.4"},
+		"8.113.0.203.zen.spamhaus.org": {"127.255.255.254"},
+	}
+
+	checker := NewDNSBLChecker("zen.spamhaus.org.")
+	checker.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if results, ok := responses[host]; ok {
+			return results, nil
+		}
+
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	tests := []struct {
+		ip      string
+		want    bool
+		wantErr bool
+	}{
+		{ip: "203.0.113.7", want: true},
+		{ip: "203.0.113.9", want: false},
+		{ip: "203.0.113.8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, err := checker.Listed(t.Context(), netip.MustParseAddr(tt.ip))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Listed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Listed() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbuseIPDBChecker_Listed(t *testing.T) {
+	var key, ipAddress string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("Key")
+		ipAddress = r.URL.Query().Get("ipAddress")
+
+		_, _ = w.Write([]byte(`{"data": {"ipAddress": "203.0.113.7", "abuseConfidenceScore": 80}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		minScore int
+		want     bool
+	}{
+		{minScore: 50, want: true},
+		{minScore: 90, want: false},
+	}
+
+	for _, tt := range tests {
+		checker := NewAbuseIPDBChecker(&Config{AbuseIPDBKey: "secret", AbuseIPDBMinScore: tt.minScore}, server.Client())
+		checker.endpoint = server.URL
+
+		got, err := checker.Listed(t.Context(), netip.MustParseAddr("203.0.113.7"))
+		if err != nil {
+			t.Fatalf("Listed failed: %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("Listed() with minimum score %d = %t, want %t", tt.minScore, got, tt.want)
+		}
+	}
+
+	if key != "secret" || ipAddress != "203.0.113.7" {
+		t.Errorf("unexpected request with key %q for %q", key, ipAddress)
+	}
+}
+
+func TestAnnotateBlocklists(t *testing.T) {
+	checkers := []BlocklistChecker{
+		&fakeBlocklistChecker{name: "zen.spamhaus.org", listed: map[string]bool{"203.0.113.1": true, "203.0.113.3": true}},
+		&fakeBlocklistChecker{name: "abuseipdb", listed: map[string]bool{"203.0.113.1": true, "10.0.0.1": true}},
+	}
+
+	assets := []ProcessedAsset{
+		{Name: "nat", IPAddress: "203.0.113.1"},
+		{Name: "clean", IPAddress: "203.0.113.2"},
+		{Name: "vm", IPAddress: "N/A", Attributes: map[string]string{"externalIPs": "203.0.113.3"}},
+		{Name: "internal", AddressType: "INTERNAL", IPAddress: "10.0.0.1"},
+	}
+
+	got := annotateBlocklists(t.Context(), slog.New(slog.DiscardHandler), checkers, assets)
+
+	want := [][]string{{"zen.spamhaus.org", "abuseipdb"}, nil, {"zen.spamhaus.org"}, nil}
+	for i := range want {
+		if !reflect.DeepEqual(got[i].Blocklists, want[i]) {
+			t.Errorf("%s: expected blocklists %v, got %v", got[i].Name, want[i], got[i].Blocklists)
+		}
+	}
+}
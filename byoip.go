@@ -0,0 +1,27 @@
+package assetwatcher
+
+import (
+	"net/netip"
+)
+
+// isBYOIP reports whether any public address of the asset is within the bring-your-own-IP
+// ranges. Both IPv4 and IPv6 ranges are supported, so dual-stack assets are matched by
+// either of their addresses.
+func isBYOIP(asset ProcessedAsset, ranges []netip.Prefix) bool {
+	if len(ranges) == 0 {
+		return false
+	}
+
+	for _, address := range ownedAddresses(asset) {
+		addr, err := netip.ParseAddr(address)
+		if err != nil || !isPublicAddress(asset, addr) {
+			continue
+		}
+
+		if withinRanges(ranges, addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
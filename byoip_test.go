@@ -0,0 +1,43 @@
+package assetwatcher
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIsBYOIP(t *testing.T) {
+	ranges := []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24"), netip.MustParsePrefix("2001:db8::/32")}
+
+	tests := []struct {
+		name   string
+		asset  ProcessedAsset
+		ranges []netip.Prefix
+		want   bool
+	}{
+		{name: "IPv4 address in range", asset: ProcessedAsset{IPAddress: "203.0.113.10"}, ranges: ranges, want: true},
+		{name: "IPv6 address in range", asset: ProcessedAsset{IPAddress: "2001:db8::1"}, ranges: ranges, want: true},
+		{name: "Google-owned address", asset: ProcessedAsset{IPAddress: "198.51.100.10"}, ranges: ranges, want: false},
+		{
+			name:   "dual-stack instance with external address in range",
+			asset:  ProcessedAsset{IPAddress: "10.0.0.2", Attributes: map[string]string{"externalIPs": "198.51.100.1,2001:db8::2"}},
+			ranges: ranges,
+			want:   true,
+		},
+		{
+			name:   "internal address",
+			asset:  ProcessedAsset{IPAddress: "203.0.113.10", AddressType: addressTypeInternal},
+			ranges: ranges,
+			want:   false,
+		},
+		{name: "invalid address", asset: ProcessedAsset{IPAddress: "N/A"}, ranges: ranges, want: false},
+		{name: "no ranges", asset: ProcessedAsset{IPAddress: "203.0.113.10"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBYOIP(tt.asset, tt.ranges); got != tt.want {
+				t.Errorf("isBYOIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
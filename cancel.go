@@ -0,0 +1,62 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// exitCodeCanceled is the exit code of a run canceled by SIGINT, SIGTERM, or the timeout, as
+// the shells report the processes interrupted by SIGINT.
+const exitCodeCanceled = 130
+
+var errRunTimeout = errors.New("run timed out")
+
+// withCancellation returns the root context of the command, canceled on SIGINT or SIGTERM or,
+// with ASSET_WATCHER_TIMEOUT, once the timeout has elapsed, so that the run stops at the next
+// API call instead of being killed mid-iteration. Once canceled, a second signal kills the
+// process as usual.
+func withCancellation(ctx context.Context, cfg *Config) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+
+	// The timeout is validated by GetConfig.
+	timeout, _ := parseAge(cfg.Timeout)
+	if timeout == 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", errRunTimeout, timeout))
+
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// cancelScan ends a scan canceled while the assets were fetched. With
+// ASSET_WATCHER_PARTIAL_OUTPUT, the report of the assets fetched so far is written to the
+// output, marked as partial; it is never published to the sinks, whose snapshots and state
+// would record the missing assets as removed.
+func cancelScan(ctx context.Context, logger *slog.Logger, cfg *Config, startedAt time.Time, assets []ProcessedAsset) {
+	logger.WarnContext(ctx, "The scan was canceled",
+		slog.Any("cause", context.Cause(ctx)),
+		slog.Int("fetched_assets", len(assets)),
+	)
+
+	if cfg.PartialOutput {
+		report := NewReport(ctx, cfg, startedAt, assets)
+		report.Metadata.Partial = true
+
+		setStage(ctx, stageOutput)
+		writeOutput(ctx, logger, cfg, func(w io.Writer) { outputReport(ctx, logger, w, report, cfg) })
+	}
+
+	exit(ctx, exitCodeCanceled)
+}
@@ -0,0 +1,148 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+)
+
+func TestWithCancellation_Timeout(t *testing.T) {
+	ctx, cancel := withCancellation(t.Context(), &Config{Timeout: "10ms"})
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context was not canceled after the timeout")
+	}
+
+	if err := context.Cause(ctx); !errors.Is(err, errRunTimeout) {
+		t.Errorf("context.Cause() = %v, want errRunTimeout", err)
+	}
+}
+
+func TestWithCancellation_NoTimeout(t *testing.T) {
+	ctx, cancel := withCancellation(t.Context(), &Config{})
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("the context should have no deadline without a timeout")
+	}
+
+	cancel()
+
+	if ctx.Err() == nil {
+		t.Error("the context should be canceled by the cancel function")
+	}
+}
+
+func TestExit_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(withEmbeddedRun(t.Context()))
+	cancel()
+
+	for code, want := range map[int]int{1: exitCodeCanceled, exitCodeViolations: exitCodeViolations} {
+		if got := embeddedExitCode(t, func() { exit(ctx, code) }); got != want {
+			t.Errorf("exit(%d) of a canceled run exited with %d, want %d", code, got, want)
+		}
+	}
+}
+
+func TestNewRunResult_Canceled(t *testing.T) {
+	ctx := withRunProgress(t.Context())
+	setStage(ctx, stageFetch)
+
+	result := newRunResult(ctx, &runOutcome{}, exitCodeCanceled, time.Now())
+
+	if result.Status != resultStatusCanceled || result.ErrorClass != stageFetch+"_canceled" {
+		t.Errorf("newRunResult() = %q, %q, want canceled", result.Status, result.ErrorClass)
+	}
+}
+
+func TestCancelScan_PartialOutput(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.OutputPath = filepath.Join(t.TempDir(), "report.json")
+	cfg.OutputFormat = outputFormatJSON
+	cfg.PartialOutput = true
+
+	ctx, cancel := context.WithCancelCause(withEmbeddedRun(t.Context()))
+	defer cancel(nil)
+
+	logger := slog.New(slog.DiscardHandler)
+	assets := &cancelingAssetIterator{
+		mockAssetIterator: mockAssetIterator{assets: []*assetpb.ResourceSearchResult{
+			createTestAsset("nat-1", "prod", "IN_USE", "203.0.113.10", time.Now()),
+			createTestAsset("nat-2", "prod", "IN_USE", "203.0.113.11", time.Now()),
+		}},
+		ctx:    ctx,
+		cancel: func() { cancel(errRunTimeout) },
+		after:  1,
+	}
+
+	processedAssets, err := NewAssetProcessor(ctx, logger, &cfg).ProcessAssets(ctx, assets)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ProcessAssets() error = %v, want context.Canceled", err)
+	}
+
+	if got := embeddedExitCode(t, func() { cancelScan(ctx, logger, &cfg, time.Now(), processedAssets) }); got != exitCodeCanceled {
+		t.Fatalf("cancelScan() exited with %d, want %d", got, exitCodeCanceled)
+	}
+
+	data, err := os.ReadFile(cfg.OutputPath)
+	if err != nil {
+		t.Fatalf("failed to read the output: %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to decode the report %q: %v", data, err)
+	}
+
+	if !report.Metadata.Partial || len(report.Assets) != 1 || report.Assets[0].Name != "nat-1" {
+		t.Errorf("unexpected report %+v, want the partial report of the fetched asset", report)
+	}
+}
+
+// cancelingAssetIterator cancels the context once the given number of assets has been read, and
+// then fails as the iterators of the API do.
+type cancelingAssetIterator struct {
+	mockAssetIterator
+
+	ctx    context.Context //nolint:containedctx // Checked by Next as the iterators of the API do.
+	cancel func()
+	after  int
+}
+
+func (it *cancelingAssetIterator) Next() (*assetpb.ResourceSearchResult, error) {
+	if it.index == it.after {
+		it.cancel()
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return it.mockAssetIterator.Next()
+}
+
+func embeddedExitCode(t *testing.T, f func()) (code int) {
+	t.Helper()
+
+	defer func() {
+		exited, ok := recover().(embeddedExit)
+		if !ok {
+			t.Fatal("expected an embedded exit")
+		}
+
+		code = exited.code
+	}()
+
+	f()
+
+	return -1
+}
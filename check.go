@@ -0,0 +1,174 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// checkCommand compares the inventory with a committed baseline, for "IP inventory as code"
+// checks in CI pipelines.
+const checkCommand = "check"
+
+var (
+	errCheckArguments = errors.New("usage: check [--update] [--format table|json] [BASELINE]")
+	errNoBaseline     = errors.New("no baseline given and ASSET_WATCHER_BASELINE_FILE is not set")
+	errInventoryDrift = errors.New("inventory drifted from the baseline")
+)
+
+// runCheckCommand scans the inventory and compares it with the baseline, a JSON report given
+// as argument or by ASSET_WATCHER_BASELINE_FILE. It writes the drift and returns
+// errInventoryDrift if any asset was added, removed, or changed. With --update, the baseline
+// is replaced with the current inventory instead, to be committed along with the accepted changes.
+func runCheckCommand(ctx context.Context, logger *slog.Logger, cfg *Config, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(checkCommand, flag.ContinueOnError)
+	flags.SetOutput(w)
+	update := flags.Bool("update", false, "replace the baseline with the current inventory")
+	format := flags.String("format", "table", "output format, table or json")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if flags.NArg() > 1 {
+		return errCheckArguments
+	}
+
+	path := cfg.BaselineFile
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	if path == "" {
+		return errNoBaseline
+	}
+
+	var baseline []ProcessedAsset
+
+	if !*update {
+		var err error
+
+		baseline, err = LoadBaseline(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The baseline would otherwise filter the inventory to compare with it.
+	scanCfg := *cfg
+	scanCfg.BaselineFile = ""
+	stores := newRunStores(ctx, logger, &scanCfg)
+	defer closeRunStores(ctx, logger, stores)
+
+	report := runScan(ctx, logger, &scanCfg, stores, time.Now())
+
+	if *update {
+		if err := writeBaseline(path, report); err != nil {
+			return err
+		}
+
+		logger.InfoContext(ctx, "Updated the baseline", slog.String("path", path), slog.Int("assets", len(report.Assets)))
+
+		return nil
+	}
+
+	return checkDrift(w, *format, path, baseline, report)
+}
+
+// checkDrift writes the changes of the inventory of the report since the baseline, and
+// returns errInventoryDrift if there are any.
+func checkDrift(w io.Writer, format, source string, baseline []ProcessedAsset, report *Report) error {
+	diff := SnapshotDiff{
+		Old: SnapshotInfo{Source: source, Assets: len(baseline)},
+		New: SnapshotInfo{
+			Source: "inventory", RunID: report.Metadata.RunID, TakenAt: report.Metadata.StartedAt, Assets: len(report.Assets),
+		},
+		Diffs: diffAssets(baseline, report.Assets),
+	}
+
+	if strings.ToLower(format) == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(diff); err != nil {
+			return fmt.Errorf("failed to encode drift: %w", err)
+		}
+	} else if err := writeDrift(w, source, diff.Diffs); err != nil {
+		return err
+	}
+
+	if len(diff.Diffs) > 0 {
+		return fmt.Errorf("%w: %d changes", errInventoryDrift, len(diff.Diffs))
+	}
+
+	return nil
+}
+
+// writeDrift writes the changes as a readable diff: added assets are prefixed with +, removed
+// assets with -, and changed assets with ~, followed by their changes.
+func writeDrift(w io.Writer, source string, diffs []AssetDiff) error {
+	counts := map[DiffType]int{}
+	for _, diff := range diffs {
+		counts[diff.Type]++
+	}
+
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintf(w, "No drift from %s\n", source)
+		if err != nil {
+			return fmt.Errorf("failed to write drift: %w", err)
+		}
+
+		return nil
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Inventory drift from %s: %d added, %d removed, %d changed\n\n",
+		source, counts[DiffAdded], counts[DiffRemoved], counts[DiffChanged])
+
+	markers := map[DiffType]string{DiffAdded: "+", DiffRemoved: "-", DiffChanged: "~"}
+
+	for _, diff := range diffs {
+		asset := diff.Asset
+		fmt.Fprintf(&b, "%s %s/%s (%s, %s)\n", markers[diff.Type], asset.Project, asset.Name,
+			orNone(asset.IPAddress), orNone(asset.Location))
+
+		for _, change := range diff.Changes {
+			fmt.Fprintf(&b, "    %s\n", change)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write drift: %w", err)
+	}
+
+	return nil
+}
+
+// writeBaseline writes the assets of the report as a baseline readable by LoadBaseline.
+func writeBaseline(path string, report *Report) error {
+	f, err := os.Create(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return fmt.Errorf("failed to create baseline file: %w", err)
+	}
+
+	baseline := &Report{Metadata: report.Metadata, Assets: report.Assets, Summary: report.Summary}
+	if err := baseline.WriteJSON(f); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckDrift(t *testing.T) {
+	baseline := []ProcessedAsset{
+		{ResourceName: "//a", Name: "nat-1", Project: "prod-network", Status: "RESERVED", IPAddress: "1.1.1.1", Location: "us-central1"},
+		{ResourceName: "//b", Name: "lb-1", Project: "prod-web", Status: "IN_USE", IPAddress: "2.2.2.2", Location: "global"},
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := checkDrift(&buf, "table", "baseline.json", baseline, &Report{Assets: baseline}); err != nil {
+			t.Fatalf("checkDrift() error = %v", err)
+		}
+
+		if got, want := buf.String(), "No drift from baseline.json\n"; got != want {
+			t.Errorf("checkDrift() = %q, want %q", got, want)
+		}
+	})
+
+	current := []ProcessedAsset{
+		{ResourceName: "//a", Name: "nat-1", Project: "prod-network", Status: "IN_USE", IPAddress: "1.1.1.1", Location: "us-central1"},
+		{ResourceName: "//c", Name: "nat-2", Project: "prod-network", Status: "IN_USE", IPAddress: "3.3.3.3", Location: "us-central1"},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := checkDrift(&buf, "table", "baseline.json", baseline, &Report{Assets: current})
+		if !errors.Is(err, errInventoryDrift) {
+			t.Fatalf("checkDrift() error = %v, want %v", err, errInventoryDrift)
+		}
+
+		want := "Inventory drift from baseline.json: 1 added, 1 removed, 1 changed\n\n" +
+			"~ prod-network/nat-1 (1.1.1.1, us-central1)\n" +
+			"    status: RESERVED -> IN_USE\n" +
+			"+ prod-network/nat-2 (3.3.3.3, us-central1)\n" +
+			"- prod-web/lb-1 (2.2.2.2, global)\n"
+		if got := buf.String(); got != want {
+			t.Errorf("checkDrift() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := checkDrift(&buf, "json", "baseline.json", baseline, &Report{Assets: current})
+		if !errors.Is(err, errInventoryDrift) {
+			t.Fatalf("checkDrift() error = %v, want %v", err, errInventoryDrift)
+		}
+
+		var diff SnapshotDiff
+		if err := json.Unmarshal(buf.Bytes(), &diff); err != nil {
+			t.Fatalf("failed to decode drift: %v", err)
+		}
+
+		if diff.Old.Source != "baseline.json" || len(diff.Diffs) != 3 {
+			t.Errorf("drift = %+v, want 3 changes from baseline.json", diff)
+		}
+	})
+}
+
+func TestWriteBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	report := &Report{
+		Metadata:   RunMetadata{RunID: "run-1"},
+		Assets:     []ProcessedAsset{{ResourceName: "//a", Name: "nat-1"}},
+		Violations: []RuleViolation{{Rule: "orphaned-address"}},
+	}
+
+	if err := writeBaseline(path, report); err != nil {
+		t.Fatalf("writeBaseline() error = %v", err)
+	}
+
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+
+	if len(baseline) != 1 || baseline[0].Name != "nat-1" {
+		t.Errorf("LoadBaseline() = %+v, want the assets of the report", baseline)
+	}
+}
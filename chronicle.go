@@ -0,0 +1,241 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// Chronicle ingestion API.
+// https://cloud.google.com/chronicle/docs/reference/ingestion-api
+const (
+	chronicleScope         = "https://www.googleapis.com/auth/malachite-ingestion"
+	chronicleDefaultRegion = "us"
+	chronicleBatchSize     = 500
+	udmProductName         = "asset-watcher"
+)
+
+// UDM event types of the asset diffs.
+// https://cloud.google.com/chronicle/docs/reference/udm-field-list#metadataevent_type
+var udmEventTypes = map[DiffType]string{
+	DiffAdded:   "RESOURCE_CREATION",
+	DiffRemoved: "RESOURCE_DELETION",
+	DiffChanged: "RESOURCE_WRITTEN",
+}
+
+var (
+	chronicleRegionPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+	errInvalidRegion       = errors.New("invalid Chronicle region")
+	errIngestionFailed     = errors.New("failed to ingest events to Chronicle")
+)
+
+// udmEvent is a Unified Data Model event.
+// https://cloud.google.com/chronicle/docs/unified-data-model/udm-usage
+type udmEvent struct {
+	Metadata udmMetadata `json:"metadata"`
+	Target   udmNoun     `json:"target"`
+}
+
+type udmMetadata struct {
+	EventTimestamp   string `json:"event_timestamp"`
+	EventType        string `json:"event_type"`
+	ProductName      string `json:"product_name"`
+	VendorName       string `json:"vendor_name"`
+	ProductVersion   string `json:"product_version,omitempty"`
+	ProductEventType string `json:"product_event_type"`
+	ProductLogID     string `json:"product_log_id,omitempty"`
+	Description      string `json:"description,omitempty"`
+}
+
+type udmNoun struct {
+	IP       []string     `json:"ip,omitempty"`
+	Resource udmResource  `json:"resource"`
+	Cloud    udmCloud     `json:"cloud"`
+	Location *udmLocation `json:"location,omitempty"`
+	Labels   []udmLabel   `json:"labels,omitempty"`
+}
+
+type udmResource struct {
+	Name            string `json:"name"`
+	ProductObjectID string `json:"product_object_id,omitempty"`
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+}
+
+type udmCloud struct {
+	Environment string      `json:"environment"`
+	Project     udmResource `json:"project"`
+}
+
+type udmLocation struct {
+	Name string `json:"name"`
+}
+
+type udmLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// chronicleEndpoint returns the regional endpoint of the Chronicle ingestion API.
+func chronicleEndpoint(region string) (string, error) {
+	if region == "" || region == chronicleDefaultRegion {
+		return "https://malachiteingestion-pa.googleapis.com", nil
+	}
+
+	if !chronicleRegionPattern.MatchString(region) {
+		return "", fmt.Errorf("%w: %q", errInvalidRegion, region)
+	}
+
+	return "https://" + region + "-malachiteingestion-pa.googleapis.com", nil
+}
+
+// ChronicleSink exports asset diffs as UDM events to Chronicle.
+type ChronicleSink struct {
+	client     *http.Client
+	endpoint   string
+	customerID string
+	logger     *slog.Logger
+}
+
+// NewChronicleSink creates a new Chronicle sink for the configured customer and region.
+func NewChronicleSink(ctx context.Context, logger *slog.Logger, cfg *Config, opts ...option.ClientOption) (*ChronicleSink, error) {
+	endpoint, err := chronicleEndpoint(cfg.ChronicleRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]option.ClientOption{option.WithScopes(chronicleScope)}, opts...)
+
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Chronicle client: %w", err)
+	}
+
+	return &ChronicleSink{
+		client:     client,
+		endpoint:   endpoint,
+		customerID: cfg.ChronicleCustomerID,
+		logger:     logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// Name returns the name of the sink.
+func (s *ChronicleSink) Name() string {
+	return "chronicle"
+}
+
+// Publish sends the diffs of the report as UDM events in batches.
+func (s *ChronicleSink) Publish(ctx context.Context, report *Report) error {
+	events := newUDMEvents(report)
+
+	for start := 0; start < len(events); start += chronicleBatchSize {
+		end := min(start+chronicleBatchSize, len(events))
+
+		if err := s.send(ctx, events[start:end]); err != nil {
+			return err
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Exported UDM events", slog.Int("number_of_events", len(events)))
+
+	return nil
+}
+
+// Close is a no-op, as the HTTP client does not hold any resources.
+func (s *ChronicleSink) Close() error {
+	return nil
+}
+
+func (s *ChronicleSink) send(ctx context.Context, events []udmEvent) error {
+	body, err := json.Marshal(map[string]any{
+		"customer_id": s.customerID,
+		"events":      events,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal UDM events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/v2/udmevents:batchCreate", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Chronicle request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send UDM events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s: %s", errIngestionFailed, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// newUDMEvents converts the diffs of the report to UDM events.
+func newUDMEvents(report *Report) []udmEvent {
+	events := make([]udmEvent, 0, len(report.Diffs))
+
+	for _, diff := range report.Diffs {
+		events = append(events, newUDMEvent(diff, report.Metadata))
+	}
+
+	return events
+}
+
+func newUDMEvent(diff AssetDiff, metadata RunMetadata) udmEvent {
+	asset := diff.Asset
+
+	event := udmEvent{
+		Metadata: udmMetadata{
+			EventTimestamp:   metadata.FinishedAt.Format(time.RFC3339),
+			EventType:        udmEventTypes[diff.Type],
+			ProductName:      udmProductName,
+			VendorName:       udmProductName,
+			ProductVersion:   metadata.Version,
+			ProductEventType: string(diff.Type),
+			ProductLogID:     metadata.RunID,
+			Description:      fmt.Sprintf("%s %s %s", asset.AssetType, asset.Name, diff.Type),
+		},
+		Target: udmNoun{
+			Resource: udmResource{
+				Name:            asset.ResourceName,
+				ProductObjectID: asset.Name,
+				ResourceSubtype: asset.AssetType,
+			},
+			Cloud: udmCloud{
+				Environment: "GOOGLE_CLOUD_PLATFORM",
+				Project:     udmResource{Name: asset.Project},
+			},
+		},
+	}
+
+	if asset.IPAddress != "" && asset.IPAddress != "N/A" {
+		event.Target.IP = []string{asset.IPAddress}
+	}
+
+	if asset.Location != "" {
+		event.Target.Location = &udmLocation{Name: asset.Location}
+	}
+
+	event.Target.Labels = append(event.Target.Labels, udmLabel{Key: "status", Value: asset.Status})
+	for _, change := range diff.Changes {
+		event.Target.Labels = append(event.Target.Labels, udmLabel{Key: "change", Value: change})
+	}
+
+	return event
+}
@@ -0,0 +1,141 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestChronicleEndpoint(t *testing.T) {
+	tests := []struct {
+		region  string
+		want    string
+		wantErr bool
+	}{
+		{region: "", want: "https://malachiteingestion-pa.googleapis.com"},
+		{region: "us", want: "https://malachiteingestion-pa.googleapis.com"},
+		{region: "europe", want: "https://europe-malachiteingestion-pa.googleapis.com"},
+		{region: "evil.example.com/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			got, err := chronicleEndpoint(tt.region)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("chronicleEndpoint(%q) error = %v, wantErr %v", tt.region, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("chronicleEndpoint(%q) = %q, want %q", tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUDMEvent(t *testing.T) {
+	metadata := RunMetadata{RunID: "run-1", Version: "1.2.3", FinishedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	diff := AssetDiff{
+		Type: DiffAdded,
+		Asset: ProcessedAsset{
+			Name:         "nat-1",
+			Project:      "proj-A",
+			Location:     "us-central1",
+			IPAddress:    "203.0.113.1",
+			Status:       "IN_USE",
+			AssetType:    addressAssetType,
+			ResourceName: "//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/nat-1",
+		},
+	}
+
+	got := newUDMEvent(diff, metadata)
+
+	if got.Metadata.EventType != "RESOURCE_CREATION" || got.Metadata.ProductEventType != "added" ||
+		got.Metadata.EventTimestamp != "2025-01-02T03:04:05Z" || got.Metadata.ProductLogID != "run-1" {
+		t.Errorf("unexpected metadata: %+v", got.Metadata)
+	}
+
+	if len(got.Target.IP) != 1 || got.Target.IP[0] != "203.0.113.1" {
+		t.Errorf("unexpected target IPs: %v", got.Target.IP)
+	}
+
+	if got.Target.Resource.Name != diff.Asset.ResourceName || got.Target.Cloud.Project.Name != "proj-A" {
+		t.Errorf("unexpected target: %+v", got.Target)
+	}
+}
+
+func TestChronicleSink_Publish(t *testing.T) {
+	var batches [][]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/udmevents:batchCreate" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+
+		var req struct {
+			CustomerID string `json:"customer_id"`
+			Events     []any  `json:"events"`
+		}
+		_ = json.Unmarshal(body, &req)
+
+		if req.CustomerID != "customer-1" {
+			t.Errorf("unexpected customer ID %q", req.CustomerID)
+		}
+
+		batches = append(batches, req.Events)
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	sink, err := NewChronicleSink(ctx, logger, &Config{ChronicleCustomerID: "customer-1"}, option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewChronicleSink failed: %v", err)
+	}
+
+	sink.endpoint = server.URL
+
+	report := &Report{Diffs: make([]AssetDiff, chronicleBatchSize+1)}
+	for i := range report.Diffs {
+		report.Diffs[i] = AssetDiff{Type: DiffRemoved, Asset: ProcessedAsset{Name: "a"}}
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[0]) != chronicleBatchSize || len(batches[1]) != 1 {
+		t.Errorf("expected 2 batches of %d and 1 events, got %d batches", chronicleBatchSize, len(batches))
+	}
+}
+
+func TestChronicleSink_PublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	sink, err := NewChronicleSink(ctx, slog.New(slog.DiscardHandler), &Config{}, option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewChronicleSink failed: %v", err)
+	}
+
+	sink.endpoint = server.URL
+
+	err = sink.Publish(ctx, &Report{Diffs: []AssetDiff{{Type: DiffAdded}}})
+	if err == nil {
+		t.Error("expected an error for a failed ingestion")
+	}
+}
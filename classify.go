@@ -0,0 +1,263 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in categories of the addresses. Classification files can define any category.
+const (
+	categoryIngressLB = "ingress-lb"
+	categoryNAT       = "nat"
+	categoryBastion   = "bastion"
+	categoryUnknown   = "unknown"
+)
+
+// classificationBuiltin selects the built-in classification rules.
+const classificationBuiltin = "builtin"
+
+var (
+	errInvalidClassification = errors.New("invalid classification rule")
+	errInvalidCategoryRoute  = errors.New("invalid category route")
+
+	// routableNotifiers are the names of the notifiers that categories can be routed to.
+	routableNotifiers = []string{"slack", "teams", "webhook", "email", "pagerduty"}
+)
+
+// ClassificationRule assigns its category to the assets matching all its conditions.
+// Empty conditions match any asset.
+type ClassificationRule struct {
+	Category    string            `yaml:"category"`
+	Name        string            `yaml:"name"`
+	AssetTypes  []string          `yaml:"assetTypes"`
+	Attachments []string          `yaml:"attachments"`
+	NetworkTags []string          `yaml:"networkTags"`
+	Labels      map[string]string `yaml:"labels"`
+	Attributes  map[string]string `yaml:"attributes"`
+}
+
+// Classifier assigns a category to every asset with the first matching rule, or the
+// default category if no rule matches.
+type Classifier struct {
+	Default string               `yaml:"default"`
+	Rules   []ClassificationRule `yaml:"rules"`
+
+	compiled []compiledClassificationRule
+}
+
+type compiledClassificationRule struct {
+	ClassificationRule
+
+	name       *regexp.Regexp
+	attributes map[string]*regexp.Regexp
+}
+
+// builtinClassificationRules classify addresses by what they are attached to and by
+// conventional bastion names and network tags.
+var builtinClassificationRules = []ClassificationRule{
+	{Category: categoryBastion, Name: `(?i)(bastion|jump)`},
+	{Category: categoryBastion, NetworkTags: []string{"bastion"}},
+	{Category: categoryIngressLB, Attachments: []string{"forwardingRules", "globalForwardingRules"}},
+	{
+		Category:   categoryIngressLB,
+		AssetTypes: []string{forwardingRuleAssetType},
+		Attributes: map[string]string{"loadBalancingScheme": "^EXTERNAL"},
+	},
+	{Category: categoryNAT, Attachments: []string{"routers"}},
+	{Category: categoryNAT, Attributes: map[string]string{"purpose": "^NAT_AUTO$"}},
+}
+
+// LoadClassifier returns the classifier of the YAML classification file, or of the
+// built-in rules for "builtin". An empty source results in a nil classifier.
+func LoadClassifier(source string) (*Classifier, error) {
+	switch source {
+	case "":
+		return nil, nil //nolint:nilnil // Classification is not enabled.
+	case classificationBuiltin:
+		classifier := &Classifier{Rules: builtinClassificationRules}
+
+		return classifier, classifier.compile()
+	}
+
+	f, err := os.Open(source) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open classification file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	classifier := &Classifier{}
+	if err := decoder.Decode(classifier); err != nil {
+		return nil, fmt.Errorf("failed to parse classification file %s: %w", source, err)
+	}
+
+	if err := classifier.compile(); err != nil {
+		return nil, fmt.Errorf("invalid classification file %s: %w", source, err)
+	}
+
+	return classifier, nil
+}
+
+func (c *Classifier) compile() error {
+	if c.Default == "" {
+		c.Default = categoryUnknown
+	}
+
+	c.compiled = make([]compiledClassificationRule, 0, len(c.Rules))
+
+	for i, rule := range c.Rules {
+		if rule.Category == "" {
+			return fmt.Errorf("%w: rule #%d has no category", errInvalidClassification, i+1)
+		}
+
+		compiled := compiledClassificationRule{ClassificationRule: rule, attributes: map[string]*regexp.Regexp{}}
+
+		var err error
+
+		if rule.Name != "" {
+			if compiled.name, err = regexp.Compile(rule.Name); err != nil {
+				return fmt.Errorf("%w: rule #%d: invalid name regex: %w", errInvalidClassification, i+1, err)
+			}
+		}
+
+		for key, pattern := range rule.Attributes {
+			if compiled.attributes[key], err = regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("%w: rule #%d: invalid regex of attribute %s: %w", errInvalidClassification, i+1, key, err)
+			}
+		}
+
+		c.compiled = append(c.compiled, compiled)
+	}
+
+	return nil
+}
+
+// classify returns the category of the asset.
+func (c *Classifier) classify(asset ProcessedAsset) string {
+	for _, rule := range c.compiled {
+		if rule.matches(asset) {
+			return rule.Category
+		}
+	}
+
+	return c.Default
+}
+
+func (r compiledClassificationRule) matches(asset ProcessedAsset) bool {
+	if r.name != nil && !r.name.MatchString(asset.Name) {
+		return false
+	}
+
+	// Reports of versions that only supported addresses have no asset type.
+	if len(r.AssetTypes) > 0 && !slices.Contains(r.AssetTypes, cmp.Or(asset.AssetType, addressAssetType)) {
+		return false
+	}
+
+	if len(r.Attachments) > 0 && !containsAny(asset.Attachments, r.Attachments) {
+		return false
+	}
+
+	if len(r.NetworkTags) > 0 && !containsAny(splitString(asset.Attributes["networkTags"], ","), r.NetworkTags) {
+		return false
+	}
+
+	if !matchesAllLabels(asset.Labels, r.Labels) {
+		return false
+	}
+
+	for key, pattern := range r.attributes {
+		if !pattern.MatchString(asset.Attributes[key]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsAny(values, candidates []string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return slices.Contains(candidates, v) })
+}
+
+// classifyAssets sets the category of every asset.
+func classifyAssets(classifier *Classifier, assets []ProcessedAsset) []ProcessedAsset {
+	for i := range assets {
+		assets[i].Category = classifier.classify(assets[i])
+	}
+
+	return assets
+}
+
+// summarizeCategories counts the assets per category.
+func summarizeCategories(assets []ProcessedAsset) map[string]int {
+	categories := make(map[string]int)
+	for _, asset := range assets {
+		categories[asset.Category]++
+	}
+
+	return categories
+}
+
+// reportForCategories returns a copy of the report limited to the violations and changes
+// of the assets of the categories.
+func reportForCategories(report *Report, categories []string) *Report {
+	routed := *report
+
+	routed.Violations = slices.DeleteFunc(slices.Clone(report.Violations), func(v RuleViolation) bool {
+		return !slices.Contains(categories, v.Asset.Category)
+	})
+	routed.Diffs = slices.DeleteFunc(slices.Clone(report.Diffs), func(d AssetDiff) bool {
+		return !slices.Contains(categories, d.Asset.Category)
+	})
+
+	return &routed
+}
+
+// attachmentKinds returns the collections of the resources using an address, such as
+// forwardingRules, instances, or routers, from their URLs.
+func attachmentKinds(users []string) []string {
+	kinds := map[string]bool{}
+
+	for _, user := range users {
+		// The collection precedes the name of the resource.
+		if i := strings.LastIndex(user, "/"); i > 0 {
+			kinds[lastPathSegment(user[:i])] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(kinds))
+}
+
+// parseCategoryRoutes parses a comma-separated list of category=notifier pairs into the
+// categories routed to every notifier.
+func parseCategoryRoutes(s string) (map[string][]string, error) {
+	routes := map[string][]string{}
+
+	for _, pair := range splitString(s, ",") {
+		category, notifier, ok := strings.Cut(pair, "=")
+		category, notifier = strings.TrimSpace(category), strings.TrimSpace(notifier)
+
+		if !ok || category == "" || notifier == "" {
+			return nil, fmt.Errorf("%w: %s, expected category=notifier", errInvalidCategoryRoute, strconv.Quote(pair))
+		}
+
+		if !slices.Contains(routableNotifiers, notifier) {
+			return nil, fmt.Errorf("%w: unknown notifier %s, expected one of %s", errInvalidCategoryRoute,
+				strconv.Quote(notifier), strings.Join(routableNotifiers, ", "))
+		}
+
+		routes[notifier] = append(routes[notifier], category)
+	}
+
+	return routes, nil
+}
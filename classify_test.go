@@ -0,0 +1,184 @@
+package assetwatcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassifier_Builtin(t *testing.T) {
+	classifier, err := LoadClassifier(classificationBuiltin)
+	if err != nil {
+		t.Fatalf("LoadClassifier() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		asset ProcessedAsset
+		want  string
+	}{
+		{name: "load balancer address", asset: ProcessedAsset{Name: "web", Attachments: []string{"forwardingRules"}}, want: categoryIngressLB},
+		{
+			name: "external forwarding rule",
+			asset: ProcessedAsset{
+				Name: "web-fr", AssetType: forwardingRuleAssetType,
+				Attributes: map[string]string{"loadBalancingScheme": "EXTERNAL_MANAGED"},
+			},
+			want: categoryIngressLB,
+		},
+		{
+			name: "internal forwarding rule",
+			asset: ProcessedAsset{
+				Name: "ilb-fr", AssetType: forwardingRuleAssetType,
+				Attributes: map[string]string{"loadBalancingScheme": "INTERNAL"},
+			},
+			want: categoryUnknown,
+		},
+		{name: "manual NAT address", asset: ProcessedAsset{Name: "nat-ip-1", Attachments: []string{"routers"}}, want: categoryNAT},
+		{name: "auto NAT address", asset: ProcessedAsset{Name: "nat-auto", Attributes: map[string]string{"purpose": "NAT_AUTO"}}, want: categoryNAT},
+		{name: "bastion by name", asset: ProcessedAsset{Name: "prod-Bastion-ip", Attachments: []string{"instances"}}, want: categoryBastion},
+		{
+			name: "bastion by network tag",
+			asset: ProcessedAsset{
+				Name: "vm-1", AssetType: instanceAssetType,
+				Attributes: map[string]string{"networkTags": "ssh,bastion"},
+			},
+			want: categoryBastion,
+		},
+		{name: "unattached address", asset: ProcessedAsset{Name: "spare"}, want: categoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.classify(tt.asset); got != tt.want {
+				t.Errorf("classify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadClassifier_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "classification.yaml")
+	rules := `default: other
+rules:
+  - category: partner-egress
+    labels:
+      team: partners
+    attributes:
+      networkTier: ^PREMIUM$
+  - category: ingress-lb
+    attachments: [forwardingRules]
+`
+
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatalf("failed to write classification file: %v", err)
+	}
+
+	classifier, err := LoadClassifier(path)
+	if err != nil {
+		t.Fatalf("LoadClassifier() error = %v", err)
+	}
+
+	assets := classifyAssets(classifier, []ProcessedAsset{
+		{Name: "a1", Labels: map[string]string{"team": "partners"}, Attributes: map[string]string{"networkTier": "PREMIUM"}},
+		{Name: "a2", Labels: map[string]string{"team": "partners"}, Attributes: map[string]string{"networkTier": "STANDARD"}},
+		{Name: "a3", Attachments: []string{"forwardingRules"}},
+	})
+
+	want := map[string]int{"partner-egress": 1, "other": 1, categoryIngressLB: 1}
+	if got := summarizeCategories(assets); !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeCategories() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadClassifier_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "missing category", rules: "rules:\n  - name: nat\n"},
+		{name: "invalid name regex", rules: "rules:\n  - category: nat\n    name: \"(\"\n"},
+		{name: "unknown field", rules: "rules:\n  - category: nat\n    purpose: NAT_AUTO\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "classification.yaml")
+			if err := os.WriteFile(path, []byte(tt.rules), 0o600); err != nil {
+				t.Fatalf("failed to write classification file: %v", err)
+			}
+
+			if _, err := LoadClassifier(path); err == nil {
+				t.Error("LoadClassifier() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestAttachmentKinds(t *testing.T) {
+	users := []string{
+		"https://www.googleapis.com/compute/v1/projects/p/regions/r/forwardingRules/fr-1",
+		"https://www.googleapis.com/compute/v1/projects/p/global/forwardingRules/fr-2",
+		"https://www.googleapis.com/compute/v1/projects/p/zones/z/instances/vm-1",
+		"",
+	}
+
+	want := []string{"forwardingRules", "instances"}
+	if got := attachmentKinds(users); !reflect.DeepEqual(got, want) {
+		t.Errorf("attachmentKinds() = %v, want %v", got, want)
+	}
+}
+
+func TestParseCategoryRoutes(t *testing.T) {
+	routes, err := parseCategoryRoutes("nat=slack, bastion=slack,ingress-lb=webhook")
+	if err != nil {
+		t.Fatalf("parseCategoryRoutes() error = %v", err)
+	}
+
+	want := map[string][]string{"slack": {"nat", "bastion"}, "webhook": {"ingress-lb"}}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("parseCategoryRoutes() = %v, want %v", routes, want)
+	}
+
+	for _, invalid := range []string{"nat", "=slack", "nat=pager"} {
+		if _, err := parseCategoryRoutes(invalid); !errors.Is(err, errInvalidCategoryRoute) {
+			t.Errorf("parseCategoryRoutes(%q) error = %v, want %v", invalid, err, errInvalidCategoryRoute)
+		}
+	}
+}
+
+func TestNotifierSink_PublishRoutedCategories(t *testing.T) {
+	ctx := t.Context()
+	notifier := &fakeNotifier{}
+	sink := notifierSink{notifier: notifier, categories: []string{categoryNAT}}
+
+	report := &Report{
+		Violations: []RuleViolation{{Severity: severityHigh, Message: "exposed", Asset: ProcessedAsset{Project: "p", Category: categoryBastion}}},
+		Diffs:      []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "lb", Category: categoryIngressLB}}},
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 0 {
+		t.Fatalf("expected no notification without findings in the routed categories, got %+v", notifier.notifications)
+	}
+
+	report.Diffs = append(report.Diffs, AssetDiff{Type: DiffRemoved, Asset: ProcessedAsset{Name: "nat-1", IPAddress: "203.0.113.1", Project: "p", Category: categoryNAT}})
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	want := []string{"removed: nat-1 203.0.113.1 (p)"}
+	if len(notifier.notifications) != 1 || !reflect.DeepEqual(notifier.notifications[0].Items, want) {
+		t.Errorf("notifications = %+v, want one with items %v", notifier.notifications, want)
+	}
+
+	if len(report.Violations) != 1 || len(report.Diffs) != 2 {
+		t.Error("routing modified the report")
+	}
+}
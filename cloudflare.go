@@ -0,0 +1,128 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	cloudflareEndpoint = "https://api.cloudflare.com/client/v4"
+	cloudflarePageSize = 100
+)
+
+var errCloudflareRequestFailed = errors.New("request to Cloudflare failed")
+
+// cloudflareRecords is a page of the List DNS Records response.
+// https://developers.cloudflare.com/api/resources/dns/subresources/records/methods/list/
+type cloudflareRecords struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result []struct {
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Content string `json:"content"`
+	} `json:"result"`
+	ResultInfo struct {
+		Page       int `json:"page"`
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
+}
+
+// CloudflareProvider lists the address records of Cloudflare zones.
+type CloudflareProvider struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	zones    []string
+	logger   *slog.Logger
+}
+
+// NewCloudflareProvider creates a new Cloudflare provider for the configured zones,
+// authenticated with an API token with the Zone DNS Read permission.
+func NewCloudflareProvider(logger *slog.Logger, cfg *Config, client *http.Client) *CloudflareProvider {
+	return &CloudflareProvider{
+		client:   client,
+		endpoint: cloudflareEndpoint,
+		token:    cfg.CloudflareToken,
+		zones:    splitString(cfg.CloudflareZones, ","),
+		logger:   logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the provider.
+func (p *CloudflareProvider) Name() string {
+	return "cloudflare"
+}
+
+// ListRecords lists the A and AAAA records of all zones.
+func (p *CloudflareProvider) ListRecords(ctx context.Context) ([]DNSRecord, error) {
+	records := []DNSRecord{}
+
+	for _, zone := range p.zones {
+		for page := 1; ; page++ {
+			resp, err := p.listRecords(ctx, zone, page)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list records of zone %s: %w", zone, err)
+			}
+
+			for _, record := range resp.Result {
+				if record.Type != "A" && record.Type != "AAAA" {
+					continue
+				}
+
+				records = append(records, DNSRecord{
+					Name: record.Name, Type: record.Type, Address: record.Content, Zone: p.Name() + ":" + zone,
+				})
+			}
+
+			if page >= resp.ResultInfo.TotalPages {
+				break
+			}
+		}
+	}
+
+	p.logger.DebugContext(ctx, "Listed Cloudflare records", slog.Int("number_of_records", len(records)))
+
+	return records, nil
+}
+
+func (p *CloudflareProvider) listRecords(ctx context.Context, zone string, page int) (*cloudflareRecords, error) {
+	query := url.Values{"page": {strconv.Itoa(page)}, "per_page": {strconv.Itoa(cloudflarePageSize)}}
+	endpoint := p.endpoint + "/zones/" + url.PathEscape(zone) + "/dns_records?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	records := &cloudflareRecords{}
+	if err := json.Unmarshal(body, records); err != nil || resp.StatusCode != http.StatusOK || !records.Success {
+		return nil, fmt.Errorf("%w: %s: %s", errCloudflareRequestFailed, resp.Status,
+			bytes.TrimSpace(body[:min(len(body), maxErrorBodyBytes)]))
+	}
+
+	return records, nil
+}
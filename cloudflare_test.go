@@ -0,0 +1,73 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCloudflareProvider_ListRecords(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"success": true, "result": [
+			{"name": "www.example.com", "type": "A", "content": "203.0.113.1"},
+			{"name": "example.com", "type": "CNAME", "content": "www.example.com"}
+		], "result_info": {"page": 1, "total_pages": 2}}`,
+		"2": `{"success": true, "result": [
+			{"name": "v6.example.com", "type": "AAAA", "content": "2001:db8::1"}
+		], "result_info": {"page": 2, "total_pages": 2}}`,
+	}
+
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+
+		if r.URL.Path != "/zones/zone-1/dns_records" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("page")]))
+	}))
+	defer server.Close()
+
+	cfg := &Config{CloudflareZones: "zone-1", CloudflareToken: "cf-token"}
+	provider := NewCloudflareProvider(slog.New(slog.DiscardHandler), cfg, server.Client())
+	provider.endpoint = server.URL
+
+	records, err := provider.ListRecords(t.Context())
+	if err != nil {
+		t.Fatalf("ListRecords failed: %v", err)
+	}
+
+	if authorization != "Bearer cf-token" {
+		t.Errorf("unexpected Authorization header %q", authorization)
+	}
+
+	want := []DNSRecord{
+		{Name: "www.example.com", Type: "A", Address: "203.0.113.1", Zone: "cloudflare:zone-1"},
+		{Name: "v6.example.com", Type: "AAAA", Address: "2001:db8::1", Zone: "cloudflare:zone-1"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("ListRecords() = %+v, want %+v", records, want)
+	}
+}
+
+func TestCloudflareProvider_ListRecords_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"success": false, "errors": [{"message": "Authentication error"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &Config{CloudflareZones: "zone-1", CloudflareToken: "cf-token"}
+	provider := NewCloudflareProvider(slog.New(slog.DiscardHandler), cfg, server.Client())
+	provider.endpoint = server.URL
+
+	if _, err := provider.ListRecords(t.Context()); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
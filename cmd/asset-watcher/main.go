@@ -0,0 +1,9 @@
+// Command asset-watcher reports the IP addresses of a Google Cloud organization. It is configured
+// with ASSET_WATCHER_* environment variables; see the README for the options.
+package main
+
+import assetwatcher "github.com/andreygrechin/asset-watcher"
+
+func main() {
+	assetwatcher.Main()
+}
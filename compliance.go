@@ -0,0 +1,97 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// Compliance of the public addresses of an asset with the approved ranges.
+const (
+	complianceCompliant = "compliant"
+	complianceOutOfBand = "out-of-band"
+)
+
+var errInvalidApprovedRange = errors.New("invalid approved range")
+
+// LoadApprovedRanges reads a file of organization-approved public CIDR allocations, one per line.
+// Blank lines and lines starting with # are ignored, and so is anything after a # on a line.
+// An empty path results in no ranges.
+func LoadApprovedRanges(path string) ([]netip.Prefix, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open approved ranges file: %w", err)
+	}
+	defer f.Close()
+
+	ranges := []netip.Prefix{}
+	scanner := bufio.NewScanner(f)
+
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d of %s: %w", errInvalidApprovedRange, line, path, err)
+		}
+
+		ranges = append(ranges, prefix.Masked())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read approved ranges file: %w", err)
+	}
+
+	return ranges, nil
+}
+
+// annotateCompliance marks every asset with public addresses as compliant, if all of them are
+// within the approved ranges, or out-of-band, listing the addresses outside of them.
+// Assets without public addresses are left unmarked.
+func annotateCompliance(assets []ProcessedAsset, ranges []netip.Prefix) []ProcessedAsset {
+	for i, asset := range assets {
+		public := false
+
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || !isPublicAddress(asset, addr) {
+				continue
+			}
+
+			public = true
+
+			if !withinRanges(ranges, addr.Unmap()) {
+				assets[i].OutOfBandAddresses = append(assets[i].OutOfBandAddresses, address)
+			}
+		}
+
+		switch {
+		case len(assets[i].OutOfBandAddresses) > 0:
+			assets[i].Compliance = complianceOutOfBand
+		case public:
+			assets[i].Compliance = complianceCompliant
+		}
+	}
+
+	return assets
+}
+
+func withinRanges(ranges []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range ranges {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package assetwatcher
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadApprovedRanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approved-ranges.txt")
+	content := "# Approved allocations\n203.0.113.0/24\n\n198.51.100.7/32 # egress NAT\n2001:db8::/32\n"
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadApprovedRanges(path)
+	if err != nil {
+		t.Fatalf("LoadApprovedRanges failed: %v", err)
+	}
+
+	want := []netip.Prefix{
+		netip.MustParsePrefix("203.0.113.0/24"),
+		netip.MustParsePrefix("198.51.100.7/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadApprovedRanges() = %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("203.0.113.0/24\nnot-a-range\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadApprovedRanges(path); !errors.Is(err, errInvalidApprovedRange) {
+		t.Errorf("expected errInvalidApprovedRange, got %v", err)
+	}
+}
+
+func TestAnnotateCompliance(t *testing.T) {
+	ranges := []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")}
+	assets := []ProcessedAsset{
+		{Name: "approved", IPAddress: "203.0.113.1"},
+		{Name: "rogue", IPAddress: "198.51.100.1"},
+		{Name: "internal", IPAddress: "10.0.0.1", AddressType: addressTypeInternal},
+		{Name: "vm", Attributes: map[string]string{"externalIPs": "203.0.113.2,198.51.100.2"}},
+	}
+
+	got := annotateCompliance(assets, ranges)
+
+	tests := []struct {
+		compliance string
+		outOfBand  []string
+	}{
+		{compliance: complianceCompliant},
+		{compliance: complianceOutOfBand, outOfBand: []string{"198.51.100.1"}},
+		{compliance: ""},
+		{compliance: complianceOutOfBand, outOfBand: []string{"198.51.100.2"}},
+	}
+
+	for i, tt := range tests {
+		if got[i].Compliance != tt.compliance || !reflect.DeepEqual(got[i].OutOfBandAddresses, tt.outOfBand) {
+			t.Errorf("%s: got %q %v, want %q %v",
+				got[i].Name, got[i].Compliance, got[i].OutOfBandAddresses, tt.compliance, tt.outOfBand)
+		}
+	}
+}
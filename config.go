@@ -1,48 +1,833 @@
-package main
+package assetwatcher
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	env "github.com/caarlos0/env/v11"
 )
 
-// Config represents the configuration structure.
+// Config represents the configuration structure. Fields holding secrets, including URLs
+// embedding a secret, are tagged with secret:"true" and redacted when shown.
 type Config struct {
-	OrgID           string `env:"ASSET_WATCHER_ORG_ID,required,notEmpty"`
-	Debug           bool   `env:"ASSET_WATCHER_DEBUG"`
-	OutputFormat    string `env:"ASSET_WATCHER_OUTPUT_FORMAT"`
+	OrgID          string `env:"ASSET_WATCHER_ORG_ID,required,notEmpty"`
+	Debug          bool   `env:"ASSET_WATCHER_DEBUG"`
+	LogSeverities  string `env:"ASSET_WATCHER_LOG_SEVERITIES"`
+	LogBudget      int    `env:"ASSET_WATCHER_LOG_BUDGET"`
+	ConfigFile     string `env:"ASSET_WATCHER_CONFIG"`
+	EnvFile        string `env:"ASSET_WATCHER_ENV_FILE"`
+	Profile        string `env:"ASSET_WATCHER_PROFILE"`
+	UserAgent      string `env:"ASSET_WATCHER_USER_AGENT"`
+	ListenAddress  string `env:"ASSET_WATCHER_LISTEN_ADDRESS"`
+	Interval       string `env:"ASSET_WATCHER_INTERVAL"`
+	IntervalJitter string `env:"ASSET_WATCHER_INTERVAL_JITTER"`
+	RunTimeout     string `env:"ASSET_WATCHER_RUN_TIMEOUT"`
+	Timeout        string `env:"ASSET_WATCHER_TIMEOUT"`
+	PartialOutput  bool   `env:"ASSET_WATCHER_PARTIAL_OUTPUT"`
+	OutputFormat   string `env:"ASSET_WATCHER_OUTPUT_FORMAT"`
+	OutputTemplate string `env:"ASSET_WATCHER_OUTPUT_TEMPLATE"`
+	OutputPath     string `env:"ASSET_WATCHER_OUTPUT_PATH"`
+	HistoryDir     string `env:"ASSET_WATCHER_HISTORY_DIR"`
+	SQLitePath     string `env:"ASSET_WATCHER_SQLITE_PATH"`
+	MetricsFile    string `env:"ASSET_WATCHER_METRICS_FILE"`
+	SnapshotPath   string `env:"ASSET_WATCHER_SNAPSHOT_PATH"`
+	StateStore     string `env:"ASSET_WATCHER_STATE_STORE"`
+	AuditLog       string `env:"ASSET_WATCHER_AUDIT_LOG"`
+
+	ReleasedRetentionDays int  `env:"ASSET_WATCHER_RELEASED_RETENTION_DAYS"`
+	ShowReleased          bool `env:"ASSET_WATCHER_SHOW_RELEASED"`
+
+	CrashReportPath string `env:"ASSET_WATCHER_CRASH_REPORT_PATH"`
+	ResultPath      string `env:"ASSET_WATCHER_RESULT_PATH"`
+	SigningKey      string `env:"ASSET_WATCHER_SIGNING_KEY"`
+	AssetTypes      string `env:"ASSET_WATCHER_ASSET_TYPES"`
 	ExcludeReserved bool   `env:"ASSET_WATCHER_EXCLUDE_RESERVED"`
 	ExcludeProjects string `env:"ASSET_WATCHER_EXCLUDE_PROJECTS"`
 	IncludeProjects string `env:"ASSET_WATCHER_INCLUDE_PROJECTS"`
+	PerProject      bool   `env:"ASSET_WATCHER_PER_PROJECT"`
+	IncludeLabels   string `env:"ASSET_WATCHER_INCLUDE_LABELS"`
+	ExcludeLabels   string `env:"ASSET_WATCHER_EXCLUDE_LABELS"`
+
+	NameRegex            string `env:"ASSET_WATCHER_NAME_REGEX"`
+	ExcludeNameRegex     string `env:"ASSET_WATCHER_EXCLUDE_NAME_REGEX"`
+	ProjectRegex         string `env:"ASSET_WATCHER_PROJECT_REGEX"`
+	ExcludeProjectRegex  string `env:"ASSET_WATCHER_EXCLUDE_PROJECT_REGEX"`
+	LocationRegex        string `env:"ASSET_WATCHER_LOCATION_REGEX"`
+	ExcludeLocationRegex string `env:"ASSET_WATCHER_EXCLUDE_LOCATION_REGEX"`
+	FilterExpr           string `env:"ASSET_WATCHER_FILTER_EXPR"`
+	RulesFile            string `env:"ASSET_WATCHER_RULES_FILE"`
+	ClassificationRules  string `env:"ASSET_WATCHER_CLASSIFICATION_RULES"`
+	MinAge               string `env:"ASSET_WATCHER_MIN_AGE"`
+	MaxAge               string `env:"ASSET_WATCHER_MAX_AGE"`
+	ShowAge              bool   `env:"ASSET_WATCHER_SHOW_AGE"`
+	TimeFormat           string `env:"ASSET_WATCHER_TIME_FORMAT"`
+	TimeZone             string `env:"ASSET_WATCHER_TIME_ZONE"`
+
+	DebugLogSampling int `env:"ASSET_WATCHER_DEBUG_LOG_SAMPLING"`
+
+	GroupBy string `env:"ASSET_WATCHER_GROUP_BY"`
+
+	ShowCost               bool    `env:"ASSET_WATCHER_SHOW_COST"`
+	IdleAddressHourlyPrice float64 `env:"ASSET_WATCHER_IDLE_ADDRESS_HOURLY_PRICE"`
+
+	ShowRecommendations bool `env:"ASSET_WATCHER_SHOW_RECOMMENDATIONS"`
+	ShowDisposition     bool `env:"ASSET_WATCHER_SHOW_DISPOSITION"`
+
+	PartnerCIDRs         string `env:"ASSET_WATCHER_PARTNER_CIDRS"`
+	FlowLogsTable        string `env:"ASSET_WATCHER_FLOW_LOGS_TABLE"`
+	FlowLogsLookbackDays int    `env:"ASSET_WATCHER_FLOW_LOGS_LOOKBACK_DAYS"`
+	ShowLastTraffic      bool   `env:"ASSET_WATCHER_SHOW_LAST_TRAFFIC"`
+
+	GeoIPDatabase  string `env:"ASSET_WATCHER_GEOIP_DATABASE"`
+	GeofeedRegions string `env:"ASSET_WATCHER_GEOFEED_REGIONS"`
+
+	FirewallExposure bool   `env:"ASSET_WATCHER_FIREWALL_EXPOSURE"`
+	SensitivePorts   string `env:"ASSET_WATCHER_SENSITIVE_PORTS"`
+
+	DNSBLZones        string `env:"ASSET_WATCHER_DNSBL_ZONES"`
+	AbuseIPDBKey      string `env:"ASSET_WATCHER_ABUSEIPDB_KEY"       secret:"true"`
+	AbuseIPDBMinScore int    `env:"ASSET_WATCHER_ABUSEIPDB_MIN_SCORE"`
+
+	AuditLogRetentionDays int `env:"ASSET_WATCHER_AUDIT_LOG_RETENTION_DAYS"`
+
+	BYOIPRanges      string  `env:"ASSET_WATCHER_BYOIP_RANGES"`
+	BYOIPHourlyPrice float64 `env:"ASSET_WATCHER_BYOIP_HOURLY_PRICE"`
+
+	ApprovedRangesFile string `env:"ASSET_WATCHER_APPROVED_RANGES_FILE"`
+	BaselineFile       string `env:"ASSET_WATCHER_BASELINE_FILE"`
+	FailOnViolation    bool   `env:"ASSET_WATCHER_FAIL_ON_VIOLATION"`
+	FailOnChanges      bool   `env:"ASSET_WATCHER_FAIL_ON_CHANGES"`
+	FailThresholds     string `env:"ASSET_WATCHER_FAIL_THRESHOLDS"`
+	ExitCodes          string `env:"ASSET_WATCHER_EXIT_CODES"`
+
+	PrefixSource string `env:"ASSET_WATCHER_PREFIX_SOURCE"`
+
+	RDAPRanges   string `env:"ASSET_WATCHER_RDAP_RANGES"`
+	RDAPNetname  string `env:"ASSET_WATCHER_RDAP_NETNAME"`
+	RDAPContacts string `env:"ASSET_WATCHER_RDAP_CONTACTS"`
+
+	TerraformState string `env:"ASSET_WATCHER_TERRAFORM_STATE" secret:"true"`
+
+	DNSZones        string `env:"ASSET_WATCHER_DNS_ZONES"`
+	Route53Zones    string `env:"ASSET_WATCHER_ROUTE53_ZONES"`
+	CloudflareZones string `env:"ASSET_WATCHER_CLOUDFLARE_ZONES"`
+	CloudflareToken string `env:"ASSET_WATCHER_CLOUDFLARE_TOKEN" secret:"true"`
+
+	SCCSource string `env:"ASSET_WATCHER_SCC_SOURCE"`
+
+	BigQueryTable string `env:"ASSET_WATCHER_BIGQUERY_TABLE"`
+
+	NetBoxURL   string `env:"ASSET_WATCHER_NETBOX_URL"`
+	NetBoxToken string `env:"ASSET_WATCHER_NETBOX_TOKEN" secret:"true"`
+	NetBoxTag   string `env:"ASSET_WATCHER_NETBOX_TAG"`
+
+	GitHubRepo   string `env:"ASSET_WATCHER_GITHUB_REPO"`
+	GitHubToken  string `env:"ASSET_WATCHER_GITHUB_TOKEN" secret:"true"`
+	GitHubLabel  string `env:"ASSET_WATCHER_GITHUB_LABEL"`
+	GitHubAPIURL string `env:"ASSET_WATCHER_GITHUB_API_URL"`
+
+	ChronicleCustomerID string `env:"ASSET_WATCHER_CHRONICLE_CUSTOMER_ID"`
+	ChronicleRegion     string `env:"ASSET_WATCHER_CHRONICLE_REGION"`
+
+	DescribeFallback bool    `env:"ASSET_WATCHER_DESCRIBE_FALLBACK"`
+	DescribeRate     float64 `env:"ASSET_WATCHER_DESCRIBE_RATE"`
+
+	Tag       string `env:"ASSET_WATCHER_TAG"`
+	TagDryRun bool   `env:"ASSET_WATCHER_TAG_DRY_RUN"`
+
+	Credentials string `env:"ASSET_WATCHER_CREDENTIALS"`
+
+	SlackToken          string `env:"ASSET_WATCHER_SLACK_TOKEN"          secret:"true"`
+	SlackChannel        string `env:"ASSET_WATCHER_SLACK_CHANNEL"`
+	SlackThreads        bool   `env:"ASSET_WATCHER_SLACK_THREADS"`
+	SlackActions        bool   `env:"ASSET_WATCHER_SLACK_ACTIONS"`
+	SlackUploadFindings bool   `env:"ASSET_WATCHER_SLACK_UPLOAD_FINDINGS"`
+	SlackSigningSecret  string `env:"ASSET_WATCHER_SLACK_SIGNING_SECRET" secret:"true"`
+	TeamsWebhookURL     string `env:"ASSET_WATCHER_TEAMS_WEBHOOK_URL"    secret:"true"`
+	WebhookURL          string `env:"ASSET_WATCHER_WEBHOOK_URL"          secret:"true"`
+	ArtifactURL         string `env:"ASSET_WATCHER_ARTIFACT_URL"`
+
+	PagerDutyRoutingKey string `env:"ASSET_WATCHER_PAGERDUTY_ROUTING_KEY" secret:"true"`
+
+	SMTPHost     string `env:"ASSET_WATCHER_SMTP_HOST"`
+	SMTPPort     int    `env:"ASSET_WATCHER_SMTP_PORT"`
+	SMTPUsername string `env:"ASSET_WATCHER_SMTP_USERNAME"`
+	SMTPPassword string `env:"ASSET_WATCHER_SMTP_PASSWORD" secret:"true"`
+	EmailFrom    string `env:"ASSET_WATCHER_EMAIL_FROM"`
+	EmailTo      string `env:"ASSET_WATCHER_EMAIL_TO"`
+
+	SkipNotifierChecks bool   `env:"ASSET_WATCHER_SKIP_NOTIFIER_CHECKS"`
+	NotifyMode         string `env:"ASSET_WATCHER_NOTIFY_MODE"`
+	NotifyDigestWindow string `env:"ASSET_WATCHER_NOTIFY_DIGEST_WINDOW"`
+	NotifyMaxItems     int    `env:"ASSET_WATCHER_NOTIFY_MAX_ITEMS"`
+	NotifyDedupTTL     string `env:"ASSET_WATCHER_NOTIFY_DEDUP_TTL"`
+	NotifyRetries      int    `env:"ASSET_WATCHER_NOTIFY_RETRIES"`
+	NotifyRetryBackoff string `env:"ASSET_WATCHER_NOTIFY_RETRY_BACKOFF"`
+	NotifyDeadLetter   string `env:"ASSET_WATCHER_NOTIFY_DEAD_LETTER"`
+
+	NotifySubjectTemplate string `env:"ASSET_WATCHER_NOTIFY_SUBJECT_TEMPLATE"`
+	NotifyBodyTemplate    string `env:"ASSET_WATCHER_NOTIFY_BODY_TEMPLATE"`
+
+	CategoryRoutes   string `env:"ASSET_WATCHER_CATEGORY_ROUTES"`
+	NotifyRoutesFile string `env:"ASSET_WATCHER_NOTIFY_ROUTES_FILE"`
+
+	Advisories         bool   `env:"ASSET_WATCHER_ADVISORIES"`
+	AdvisoryTypes      string `env:"ASSET_WATCHER_ADVISORY_TYPES"`
+	AdvisorySeverities string `env:"ASSET_WATCHER_ADVISORY_SEVERITIES"`
+	AdvisoryRoutes     string `env:"ASSET_WATCHER_ADVISORY_ROUTES"`
 }
 
 // ConfigDefaults holds the actual configuration default values.
 var ConfigDefaults = Config{
-	OrgID:           "",
-	Debug:           false,
-	OutputFormat:    "table",
+	OrgID:          "",
+	Debug:          false,
+	LogSeverities:  "",
+	LogBudget:      0,
+	ConfigFile:     "",
+	EnvFile:        defaultEnvFile,
+	Profile:        "",
+	UserAgent:      "",
+	ListenAddress:  defaultListenAddress,
+	Interval:       "",
+	IntervalJitter: "",
+	RunTimeout:     "",
+	Timeout:        "",
+	PartialOutput:  false,
+	OutputFormat:   outputFormatTable,
+	OutputTemplate: "",
+	OutputPath:     "",
+	HistoryDir:     "",
+	SQLitePath:     "",
+	MetricsFile:    "",
+	SnapshotPath:   "",
+	StateStore:     "",
+	AuditLog:       "",
+
+	ReleasedRetentionDays: 0,
+	ShowReleased:          false,
+
+	CrashReportPath: "",
+	ResultPath:      "",
+	SigningKey:      "",
+	AssetTypes:      addressAssetType,
 	ExcludeReserved: false,
 	ExcludeProjects: "",
 	IncludeProjects: "",
+	PerProject:      false,
+	IncludeLabels:   "",
+	ExcludeLabels:   "",
+
+	NameRegex:            "",
+	ExcludeNameRegex:     "",
+	ProjectRegex:         "",
+	ExcludeProjectRegex:  "",
+	LocationRegex:        "",
+	ExcludeLocationRegex: "",
+	FilterExpr:           "",
+	RulesFile:            "",
+	ClassificationRules:  "",
+	MinAge:               "",
+	MaxAge:               "",
+	ShowAge:              false,
+	TimeFormat:           timeFormatDateTime,
+	TimeZone:             "UTC",
+
+	DebugLogSampling: 0,
+
+	GroupBy: "",
+
+	ShowCost:               false,
+	IdleAddressHourlyPrice: defaultIdleAddressHourlyPrice,
+
+	ShowRecommendations: false,
+	ShowDisposition:     false,
+
+	PartnerCIDRs:         "",
+	FlowLogsTable:        "",
+	FlowLogsLookbackDays: defaultFlowLogsLookbackDays,
+	ShowLastTraffic:      false,
+
+	GeoIPDatabase:  "",
+	GeofeedRegions: "",
+
+	FirewallExposure: false,
+	SensitivePorts:   defaultSensitivePorts,
+
+	DNSBLZones:        "",
+	AbuseIPDBKey:      "",
+	AbuseIPDBMinScore: defaultAbuseIPDBMinScore,
+
+	AuditLogRetentionDays: 0,
+
+	BYOIPRanges:      "",
+	BYOIPHourlyPrice: 0,
+
+	ApprovedRangesFile: "",
+	BaselineFile:       "",
+	FailOnViolation:    false,
+	FailOnChanges:      false,
+	FailThresholds:     "",
+	ExitCodes:          "",
+
+	PrefixSource: "",
+
+	RDAPRanges:   "",
+	RDAPNetname:  "",
+	RDAPContacts: "",
+
+	TerraformState: "",
+
+	DNSZones:        "",
+	Route53Zones:    "",
+	CloudflareZones: "",
+	CloudflareToken: "",
+
+	SCCSource: "",
+
+	BigQueryTable: "",
+
+	NetBoxURL:   "",
+	NetBoxToken: "",
+	NetBoxTag:   defaultNetBoxTag,
+
+	GitHubRepo:   "",
+	GitHubToken:  "",
+	GitHubLabel:  defaultGitHubLabel,
+	GitHubAPIURL: defaultGitHubAPIURL,
+
+	ChronicleCustomerID: "",
+	ChronicleRegion:     chronicleDefaultRegion,
+
+	DescribeFallback: false,
+	DescribeRate:     defaultDescribeRate,
+
+	Tag:       "",
+	TagDryRun: false,
+
+	Credentials: "",
+
+	SlackToken:          "",
+	SlackChannel:        "",
+	SlackThreads:        false,
+	SlackActions:        false,
+	SlackUploadFindings: false,
+	SlackSigningSecret:  "",
+	TeamsWebhookURL:     "",
+	WebhookURL:          "",
+	ArtifactURL:         "",
+
+	PagerDutyRoutingKey: "",
+
+	SMTPHost:     "",
+	SMTPPort:     defaultSMTPPort,
+	SMTPUsername: "",
+	SMTPPassword: "",
+	EmailFrom:    "",
+	EmailTo:      "",
+
+	SkipNotifierChecks: false,
+	NotifyMode:         notifyModeFindings,
+	NotifyDigestWindow: "",
+	NotifyMaxItems:     0,
+	NotifyDedupTTL:     "",
+	NotifyRetries:      defaultNotifyRetries,
+	NotifyRetryBackoff: defaultNotifyRetryBackoff,
+	NotifyDeadLetter:   "",
+
+	NotifySubjectTemplate: "",
+	NotifyBodyTemplate:    "",
+
+	CategoryRoutes:   "",
+	NotifyRoutesFile: "",
+
+	Advisories:         false,
+	AdvisoryTypes:      "",
+	AdvisorySeverities: "",
+	AdvisoryRoutes:     "",
+}
+
+// ErrInvalidConfig is returned by GetConfig for an invalid configuration, along with every
+// invalid option.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// configErrors collects the errors of the validation of the configuration, so that all of them
+// are reported at once.
+type configErrors []error
+
+func (e *configErrors) addf(format string, args ...any) {
+	*e = append(*e, fmt.Errorf(format, args...)) //nolint:err113 // Joined and wrapped in ErrInvalidConfig.
 }
 
-// GetConfig returns the configuration structure.
-func GetConfig() *Config {
+// GetConfig returns the configuration structure, read from the environment variables, completed
+// by the env file, and the configuration file of ASSET_WATCHER_CONFIG, with the profile of
+// ASSET_WATCHER_PROFILE. An invalid configuration returns ErrInvalidConfig with every invalid
+// option.
+func GetConfig() (*Config, error) {
+	if err := loadEnvFile(); err != nil {
+		return nil, fmt.Errorf("%w: invalid value for ASSET_WATCHER_ENV_FILE: %w", ErrInvalidConfig, err)
+	}
+
+	return getConfig(os.Getenv(configFileEnv), os.Getenv(profileEnv))
+}
+
+// getConfig returns the configuration structure, read from the configuration file at path, if
+// not empty, with the settings of the profile, and the environment variables, which take
+// precedence over the file. The profile also names the deployment.
+func getConfig(path, profile string) (*Config, error) {
 	cfg := ConfigDefaults
 
-	if err := env.Parse(&cfg); err != nil {
-		log.Fatalf("failed to parse environment variables: %v\n", err)
+	environment, err := configEnvironment(path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read the configuration file: %w", ErrInvalidConfig, err)
+	}
+
+	if err := env.ParseWithOptions(&cfg, env.Options{Environment: environment}); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse environment variables: %w", ErrInvalidConfig, err)
+	}
+
+	var errs configErrors
+
+	cfg.ConfigFile = path
+
+	if profile != "" {
+		cfg.Profile = profile
+	}
+
+	// The output formats are case-insensitive.
+	cfg.OutputFormat = strings.ToLower(cfg.OutputFormat)
+
+	if _, ok := outputWriters[cfg.OutputFormat]; !ok {
+		errs.addf("invalid value for ASSET_WATCHER_OUTPUT_FORMAT: %s. Allowed values are %s",
+			cfg.OutputFormat, strings.Join(outputFormats(), ", "))
+	}
+
+	if cfg.OutputTemplate != "" {
+		if _, err := loadOutputTemplate(cfg.OutputTemplate); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_OUTPUT_TEMPLATE: %v", err)
+		}
+
+		if cfg.OutputFormat == outputFormatNDJSON || cfg.OutputFormat == outputFormatXLSX {
+			errs.addf("ASSET_WATCHER_OUTPUT_TEMPLATE cannot be combined with the %s output format", cfg.OutputFormat)
+		}
+	}
+
+	if _, err := parseLogSeverities(cfg.LogSeverities); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_LOG_SEVERITIES: %v", err)
+	}
+
+	if cfg.LogBudget < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_LOG_BUDGET: %d. The budget must not be negative", cfg.LogBudget)
+	}
+
+	if cfg.DebugLogSampling < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_DEBUG_LOG_SAMPLING: %d. "+
+			"The sampling rate must not be negative", cfg.DebugLogSampling)
+	}
+
+	if _, err := parseLabels(cfg.IncludeLabels); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_INCLUDE_LABELS: %v", err)
+	}
+
+	if _, err := parseLabels(cfg.ExcludeLabels); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_EXCLUDE_LABELS: %v", err)
+	}
+
+	if _, err := newAssetRegexFilters(&cfg); err != nil {
+		errs.addf("invalid value for a regex filter: %v", err)
+	}
+
+	if _, err := newCELFilter(cfg.FilterExpr); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_FILTER_EXPR: %v", err)
+	}
+
+	if _, err := LoadRules(cfg.RulesFile); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_RULES_FILE: %v", err)
+	}
+
+	if _, err := LoadClassifier(cfg.ClassificationRules); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_CLASSIFICATION_RULES: %v", err)
+	}
+
+	if cfg.NotifyMode != notifyModeFindings && cfg.NotifyMode != notifyModeChanges {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_MODE: %q. Allowed values are '%s' and '%s'",
+			cfg.NotifyMode, notifyModeFindings, notifyModeChanges)
+	}
+
+	if cfg.NotifyMode == notifyModeChanges && cfg.SnapshotPath == "" && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_NOTIFY_MODE=changes requires ASSET_WATCHER_SNAPSHOT_PATH or " +
+			"ASSET_WATCHER_STATE_STORE to detect changes between runs")
+	}
+
+	if window, err := parseAge(cfg.NotifyDigestWindow); err != nil || (cfg.NotifyDigestWindow != "" && window == 0) {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_DIGEST_WINDOW: %q, expected a positive duration "+
+			"such as 1h or 1d", cfg.NotifyDigestWindow)
+	}
+
+	if cfg.NotifyDigestWindow != "" && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_NOTIFY_DIGEST_WINDOW requires ASSET_WATCHER_STATE_STORE to accumulate " +
+			"the findings between runs")
+	}
+
+	if ttl, err := parseAge(cfg.NotifyDedupTTL); err != nil || (cfg.NotifyDedupTTL != "" && ttl == 0) {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_DEDUP_TTL: %q, expected a positive duration "+
+			"such as 12h or 7d", cfg.NotifyDedupTTL)
+	}
+
+	if cfg.NotifyDedupTTL != "" && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_NOTIFY_DEDUP_TTL requires ASSET_WATCHER_STATE_STORE to remember " +
+			"the notified findings between runs")
+	}
+
+	if cfg.NotifyRetries < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_RETRIES: %d, expected a non-negative number",
+			cfg.NotifyRetries)
+	}
+
+	if backoff, err := time.ParseDuration(cfg.NotifyRetryBackoff); err != nil || backoff <= 0 {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_RETRY_BACKOFF: %q, expected a positive duration "+
+			"such as 2s", cfg.NotifyRetryBackoff)
+	}
+
+	if strings.HasPrefix(cfg.NotifyDeadLetter, pubSubScheme) {
+		if _, err := parsePubSubURL(cfg.NotifyDeadLetter); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_NOTIFY_DEAD_LETTER: %v", err)
+		}
+	}
+
+	if _, err := loadNotificationTemplates(cfg.NotifySubjectTemplate, cfg.NotifyBodyTemplate); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_SUBJECT_TEMPLATE or ASSET_WATCHER_NOTIFY_BODY_TEMPLATE: %v", err)
+	}
+
+	if cfg.Advisories && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_ADVISORIES requires ASSET_WATCHER_STATE_STORE to be set")
+	}
+
+	if _, err := parseAdvisoryPolicy(&cfg); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_ADVISORY_TYPES, ASSET_WATCHER_ADVISORY_SEVERITIES, or "+
+			"ASSET_WATCHER_ADVISORY_ROUTES: %v", err)
+	}
+
+	if cfg.NotifyMaxItems < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_MAX_ITEMS: %d, expected a non-negative number",
+			cfg.NotifyMaxItems)
+	}
+
+	if _, err := parseCategoryRoutes(cfg.CategoryRoutes); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_CATEGORY_ROUTES: %v", err)
+	}
+
+	if cfg.CategoryRoutes != "" && cfg.ClassificationRules == "" {
+		errs.addf("ASSET_WATCHER_CATEGORY_ROUTES requires ASSET_WATCHER_CLASSIFICATION_RULES")
+	}
+
+	notifyRoutes, err := LoadNotifyRoutes(cfg.NotifyRoutesFile)
+	if err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_NOTIFY_ROUTES_FILE: %v", err)
+	}
+
+	if notifyRoutes != nil {
+		if cfg.CategoryRoutes != "" {
+			errs.addf("ASSET_WATCHER_NOTIFY_ROUTES_FILE and ASSET_WATCHER_CATEGORY_ROUTES cannot be used together")
+		}
+
+		for _, target := range notifyRoutes.targets() {
+			if !notifierConfigured(&cfg, target) {
+				errs.addf("invalid value for ASSET_WATCHER_NOTIFY_ROUTES_FILE: the notifier of the target %q "+
+					"is not configured", target)
+			}
+		}
+	}
+
+	if _, err := newAgeFilter(cfg.MinAge, cfg.MaxAge); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_MIN_AGE or ASSET_WATCHER_MAX_AGE: %v", err)
+	}
+
+	if timeout, err := parseAge(cfg.Timeout); err != nil || (cfg.Timeout != "" && timeout == 0) {
+		errs.addf("invalid value for ASSET_WATCHER_TIMEOUT: %q. The timeout must be a positive duration "+
+			"such as 2h", cfg.Timeout)
+	}
+
+	if _, err := parseDaemonSchedule(&cfg); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_INTERVAL, ASSET_WATCHER_INTERVAL_JITTER, "+
+			"or ASSET_WATCHER_RUN_TIMEOUT: %v", err)
+	}
+
+	if _, err := newTimeFormatter(cfg.TimeFormat, cfg.TimeZone); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_TIME_FORMAT or ASSET_WATCHER_TIME_ZONE: %v", err)
+	}
+
+	if cfg.GroupBy != "" {
+		if _, err := parseGroupBy(cfg.GroupBy); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_GROUP_BY: %v", err)
+		}
+	}
+
+	if cfg.IdleAddressHourlyPrice < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_IDLE_ADDRESS_HOURLY_PRICE: %v. "+
+			"The price cannot be negative", cfg.IdleAddressHourlyPrice)
+	}
+
+	if _, err := parsePartnerRanges(cfg.PartnerCIDRs); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_PARTNER_CIDRS: %v", err)
+	}
+
+	if cfg.PartnerCIDRs != "" && cfg.FlowLogsTable == "" {
+		errs.addf("ASSET_WATCHER_PARTNER_CIDRS requires ASSET_WATCHER_FLOW_LOGS_TABLE to be set")
+	}
+
+	if cfg.ShowLastTraffic && cfg.FlowLogsTable == "" {
+		errs.addf("ASSET_WATCHER_SHOW_LAST_TRAFFIC requires ASSET_WATCHER_FLOW_LOGS_TABLE to be set")
+	}
+
+	if cfg.FlowLogsTable != "" {
+		if _, err := parseBigQueryTable(cfg.FlowLogsTable); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_FLOW_LOGS_TABLE: %v", err)
+		}
+	}
+
+	if cfg.FlowLogsLookbackDays <= 0 {
+		errs.addf("invalid value for ASSET_WATCHER_FLOW_LOGS_LOOKBACK_DAYS: %d. "+
+			"The lookback must be a positive number of days", cfg.FlowLogsLookbackDays)
+	}
+
+	if _, err := parseCloudDNSZones(cfg.DNSZones); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_DNS_ZONES: %v", err)
+	}
+
+	if cfg.NetBoxURL != "" {
+		if u, err := url.Parse(cfg.NetBoxURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs.addf("invalid value for ASSET_WATCHER_NETBOX_URL: %q. The URL must be an http(s) URL", cfg.NetBoxURL)
+		}
+
+		if cfg.NetBoxToken == "" {
+			errs.addf("ASSET_WATCHER_NETBOX_URL requires ASSET_WATCHER_NETBOX_TOKEN to be set")
+		}
+
+		if cfg.NetBoxTag == "" {
+			errs.addf("invalid value for ASSET_WATCHER_NETBOX_TAG: the tag of the managed addresses must not be empty")
+		}
+	}
+
+	if cfg.GitHubRepo != "" {
+		owner, repo, ok := strings.Cut(cfg.GitHubRepo, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			errs.addf("invalid value for ASSET_WATCHER_GITHUB_REPO: %q. The repository must be OWNER/REPO", cfg.GitHubRepo)
+		}
+
+		if u, err := url.Parse(cfg.GitHubAPIURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs.addf("invalid value for ASSET_WATCHER_GITHUB_API_URL: %q. The URL must be an http(s) URL", cfg.GitHubAPIURL)
+		}
+
+		if cfg.GitHubToken == "" {
+			errs.addf("ASSET_WATCHER_GITHUB_REPO requires ASSET_WATCHER_GITHUB_TOKEN to be set")
+		}
+
+		if cfg.GitHubLabel == "" {
+			errs.addf("invalid value for ASSET_WATCHER_GITHUB_LABEL: the label of the managed issues must not be empty")
+		}
+	}
+
+	if cfg.CloudflareZones != "" && cfg.CloudflareToken == "" {
+		errs.addf("ASSET_WATCHER_CLOUDFLARE_ZONES requires ASSET_WATCHER_CLOUDFLARE_TOKEN to be set")
+	}
+
+	if cfg.GeoIPDatabase != "" {
+		if db, err := OpenGeoIPDatabase(cfg.GeoIPDatabase); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_GEOIP_DATABASE: %v", err)
+		} else {
+			_ = db.Close()
+		}
+	}
+
+	if _, err := loadGeofeedRegions(cfg.GeofeedRegions); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_GEOFEED_REGIONS: %v", err)
+	}
+
+	if _, err := parseSensitivePorts(cfg.SensitivePorts); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_SENSITIVE_PORTS: %v", err)
+	}
+
+	if cfg.AbuseIPDBMinScore < 0 || cfg.AbuseIPDBMinScore > 100 {
+		errs.addf("invalid value for ASSET_WATCHER_ABUSEIPDB_MIN_SCORE: %d. "+
+			"The score must be between 0 and 100", cfg.AbuseIPDBMinScore)
+	}
+
+	if cfg.SigningKey != "" {
+		if _, err := loadSigningKey(cfg.SigningKey); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_SIGNING_KEY: %v", err)
+		}
+	}
+
+	if _, err := LoadApprovedRanges(cfg.ApprovedRangesFile); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_APPROVED_RANGES_FILE: %v", err)
+	}
+
+	if strings.HasPrefix(cfg.StateStore, firestoreScheme) {
+		if _, err := parseFirestoreURL(cfg.StateStore); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_STATE_STORE: %v", err)
+		}
+	}
+
+	if (cfg.SnapshotPath != "" || cfg.StateStore != "") && cfg.BaselineFile != "" {
+		errs.addf("ASSET_WATCHER_SNAPSHOT_PATH or ASSET_WATCHER_STATE_STORE and ASSET_WATCHER_BASELINE_FILE " +
+			"cannot be used together")
+	}
+
+	if _, err := LoadBaseline(cfg.BaselineFile); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_BASELINE_FILE: %v", err)
+	}
+
+	if err := validateExitPolicy(&cfg); err != nil {
+		errs.addf("invalid exit-code policy: %v", err)
+	}
+
+	if cfg.AuditLog != "" && cfg.SnapshotPath == "" && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_AUDIT_LOG requires ASSET_WATCHER_SNAPSHOT_PATH or ASSET_WATCHER_STATE_STORE " +
+			"to detect changes between runs")
+	}
+
+	if cfg.MetricsFile != "" && filepath.Ext(cfg.MetricsFile) != ".prom" {
+		errs.addf("invalid value for ASSET_WATCHER_METRICS_FILE: %q. "+
+			"The textfile collector of the node exporter only reads *.prom files", cfg.MetricsFile)
+	}
+
+	if cfg.ReleasedRetentionDays < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_RELEASED_RETENTION_DAYS: %d. "+
+			"The retention must be a positive number of days, or 0 to not track released assets", cfg.ReleasedRetentionDays)
+	}
+
+	if cfg.ReleasedRetentionDays > 0 && cfg.SnapshotPath == "" && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_RELEASED_RETENTION_DAYS requires ASSET_WATCHER_SNAPSHOT_PATH or ASSET_WATCHER_STATE_STORE " +
+			"to detect released assets between runs")
+	}
+
+	if cfg.ShowReleased && cfg.ReleasedRetentionDays == 0 {
+		errs.addf("ASSET_WATCHER_SHOW_RELEASED requires ASSET_WATCHER_RELEASED_RETENTION_DAYS to be set")
+	}
+
+	if strings.HasPrefix(cfg.AuditLog, gcsScheme) {
+		if _, _, err := parseAuditLogPath(cfg.AuditLog); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_AUDIT_LOG: %v", err)
+		}
+	}
+
+	if strings.HasPrefix(cfg.CrashReportPath, gcsScheme) {
+		if bucket, _, _ := strings.Cut(strings.TrimPrefix(cfg.CrashReportPath, gcsScheme), "/"); bucket == "" {
+			errs.addf("invalid value for ASSET_WATCHER_CRASH_REPORT_PATH: %s. "+
+				"Expected a directory or gs://BUCKET[/PREFIX]", cfg.CrashReportPath)
+		}
+	}
+
+	if strings.HasPrefix(cfg.OutputPath, gcsScheme) {
+		if bucket, object, _ := strings.Cut(strings.TrimPrefix(cfg.OutputPath, gcsScheme), "/"); bucket == "" || object == "" {
+			errs.addf("invalid value for ASSET_WATCHER_OUTPUT_PATH: %s. "+
+				"Expected a file or gs://BUCKET/OBJECT", cfg.OutputPath)
+		}
+	}
+
+	if strings.HasPrefix(cfg.ResultPath, gcsScheme) {
+		if bucket, object, _ := strings.Cut(strings.TrimPrefix(cfg.ResultPath, gcsScheme), "/"); bucket == "" || object == "" {
+			errs.addf("invalid value for ASSET_WATCHER_RESULT_PATH: %s. "+
+				"Expected a file or gs://BUCKET/OBJECT", cfg.ResultPath)
+		}
+	}
+
+	if cfg.AuditLogRetentionDays < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_AUDIT_LOG_RETENTION_DAYS: %d. "+
+			"The retention must not be negative", cfg.AuditLogRetentionDays)
+	}
+
+	if strings.HasPrefix(cfg.SnapshotPath, gcsScheme) {
+		if _, _, err := parseGCSPath(cfg.SnapshotPath); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_SNAPSHOT_PATH: %v", err)
+		}
+	}
+	if cfg.PrefixSource != "" && cfg.PrefixSource != prefixSourceRIPEstat {
+		if _, err := LoadPrefixTable(cfg.PrefixSource); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_PREFIX_SOURCE: %v", err)
+		}
+	}
+
+	if _, err := parseCIDRs(cfg.BYOIPRanges); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_BYOIP_RANGES: %v", err)
+	}
+
+	if cfg.BYOIPHourlyPrice < 0 {
+		errs.addf("invalid value for ASSET_WATCHER_BYOIP_HOURLY_PRICE: %v. The price must not be negative",
+			cfg.BYOIPHourlyPrice)
+	}
+
+	if _, err := parseCIDRs(cfg.RDAPRanges); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_RDAP_RANGES: %v", err)
+	}
+
+	if _, err := regexp.Compile(cfg.RDAPNetname); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_RDAP_NETNAME: %v", err)
+	}
+
+	if cfg.TerraformState != "" && !slices.Contains(splitString(cfg.AssetTypes, ","), addressAssetType) {
+		errs.addf("ASSET_WATCHER_TERRAFORM_STATE requires %s in ASSET_WATCHER_ASSET_TYPES", addressAssetType)
+	}
+
+	if err := validateNotifierConfig(&cfg); err != nil {
+		errs.addf("invalid notifier configuration:\n%v", err)
+	}
+
+	if cfg.SCCSource != "" {
+		if err := validateSCCSource(cfg.SCCSource); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_SCC_SOURCE: %v", err)
+		}
+	}
+
+	if cfg.BigQueryTable != "" {
+		if _, err := parseBigQueryTable(cfg.BigQueryTable); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_BIGQUERY_TABLE: %v", err)
+		}
+	}
+
+	if _, err := chronicleEndpoint(cfg.ChronicleRegion); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_CHRONICLE_REGION: %v", err)
+	}
+
+	if cfg.DescribeRate <= 0 {
+		errs.addf("invalid value for ASSET_WATCHER_DESCRIBE_RATE: %v. "+
+			"The rate must be a positive number of requests per second", cfg.DescribeRate)
+	}
+
+	if cfg.Tag != "" {
+		if _, err := parseTagValue(cfg.Tag, cfg.OrgID); err != nil {
+			errs.addf("invalid value for ASSET_WATCHER_TAG: %v", err)
+		}
+	}
+
+	if _, err := parseCredentials(cfg.Credentials); err != nil {
+		errs.addf("invalid value for ASSET_WATCHER_CREDENTIALS: %v", err)
+	}
+
+	if cfg.SlackToken != "" && cfg.SlackChannel == "" {
+		errs.addf("ASSET_WATCHER_SLACK_TOKEN requires ASSET_WATCHER_SLACK_CHANNEL to be set")
+	}
+
+	if cfg.SlackSigningSecret != "" && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_SLACK_SIGNING_SECRET requires ASSET_WATCHER_STATE_STORE to record " +
+			"the acknowledgments of the Slack buttons")
 	}
 
-	if cfg.ExcludeProjects != "" && cfg.IncludeProjects != "" {
-		log.Fatal("cannot set both ASSET_WATCHER_EXCLUDE_PROJECTS and ASSET_WATCHER_INCLUDE_PROJECTS at the same time\n")
+	if cfg.SlackThreads && cfg.StateStore == "" {
+		errs.addf("ASSET_WATCHER_SLACK_THREADS requires ASSET_WATCHER_STATE_STORE to keep the messages " +
+			"of the findings between runs")
 	}
 
-	if strings.ToLower(cfg.OutputFormat) != "table" && strings.ToLower(cfg.OutputFormat) != "json" {
-		log.Fatalf("invalid value for ASSET_WATCHER_OUTPUT_FORMAT: %s. "+
-			"Allowed values are 'table' or 'json'\n", cfg.OutputFormat)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w:\n%w", ErrInvalidConfig, errors.Join(errs...))
 	}
 
-	return &cfg
+	return &cfg, nil
 }
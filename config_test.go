@@ -1,10 +1,14 @@
-package main
+package assetwatcher
 
 import (
 	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -15,10 +19,136 @@ const (
 func cleanEnvVars() {
 	_ = os.Unsetenv("ASSET_WATCHER_ORG_ID")
 	_ = os.Unsetenv("ASSET_WATCHER_DEBUG")
+	_ = os.Unsetenv("ASSET_WATCHER_CONFIG")
+	_ = os.Unsetenv("ASSET_WATCHER_ENV_FILE")
+	_ = os.Unsetenv("ASSET_WATCHER_PROFILE")
+	_ = os.Unsetenv("ASSET_WATCHER_USER_AGENT")
 	_ = os.Unsetenv("ASSET_WATCHER_OUTPUT_FORMAT")
+	_ = os.Unsetenv("ASSET_WATCHER_OUTPUT_TEMPLATE")
+	_ = os.Unsetenv("ASSET_WATCHER_OUTPUT_PATH")
+	_ = os.Unsetenv("ASSET_WATCHER_RESULT_PATH")
+	_ = os.Unsetenv("ASSET_WATCHER_ASSET_TYPES")
 	_ = os.Unsetenv("ASSET_WATCHER_EXCLUDE_RESERVED")
 	_ = os.Unsetenv("ASSET_WATCHER_EXCLUDE_PROJECTS")
 	_ = os.Unsetenv("ASSET_WATCHER_INCLUDE_PROJECTS")
+	_ = os.Unsetenv("ASSET_WATCHER_PER_PROJECT")
+	_ = os.Unsetenv("ASSET_WATCHER_INCLUDE_LABELS")
+	_ = os.Unsetenv("ASSET_WATCHER_EXCLUDE_LABELS")
+	_ = os.Unsetenv("ASSET_WATCHER_NAME_REGEX")
+	_ = os.Unsetenv("ASSET_WATCHER_EXCLUDE_NAME_REGEX")
+	_ = os.Unsetenv("ASSET_WATCHER_PROJECT_REGEX")
+	_ = os.Unsetenv("ASSET_WATCHER_EXCLUDE_PROJECT_REGEX")
+	_ = os.Unsetenv("ASSET_WATCHER_LOCATION_REGEX")
+	_ = os.Unsetenv("ASSET_WATCHER_EXCLUDE_LOCATION_REGEX")
+	_ = os.Unsetenv("ASSET_WATCHER_FILTER_EXPR")
+	_ = os.Unsetenv("ASSET_WATCHER_RULES_FILE")
+	_ = os.Unsetenv("ASSET_WATCHER_MIN_AGE")
+	_ = os.Unsetenv("ASSET_WATCHER_MAX_AGE")
+	_ = os.Unsetenv("ASSET_WATCHER_SHOW_AGE")
+	_ = os.Unsetenv("ASSET_WATCHER_TIME_FORMAT")
+	_ = os.Unsetenv("ASSET_WATCHER_TIME_ZONE")
+	_ = os.Unsetenv("ASSET_WATCHER_INTERVAL")
+	_ = os.Unsetenv("ASSET_WATCHER_INTERVAL_JITTER")
+	_ = os.Unsetenv("ASSET_WATCHER_RUN_TIMEOUT")
+	_ = os.Unsetenv("ASSET_WATCHER_TIMEOUT")
+	_ = os.Unsetenv("ASSET_WATCHER_PARTIAL_OUTPUT")
+	_ = os.Unsetenv("ASSET_WATCHER_GROUP_BY")
+	_ = os.Unsetenv("ASSET_WATCHER_SHOW_COST")
+	_ = os.Unsetenv("ASSET_WATCHER_IDLE_ADDRESS_HOURLY_PRICE")
+	_ = os.Unsetenv("ASSET_WATCHER_SHOW_RECOMMENDATIONS")
+	_ = os.Unsetenv("ASSET_WATCHER_PARTNER_CIDRS")
+	_ = os.Unsetenv("ASSET_WATCHER_FLOW_LOGS_TABLE")
+	_ = os.Unsetenv("ASSET_WATCHER_FLOW_LOGS_LOOKBACK_DAYS")
+	_ = os.Unsetenv("ASSET_WATCHER_SHOW_LAST_TRAFFIC")
+	_ = os.Unsetenv("ASSET_WATCHER_GEOIP_DATABASE")
+	_ = os.Unsetenv("ASSET_WATCHER_GEOFEED_REGIONS")
+	_ = os.Unsetenv("ASSET_WATCHER_FIREWALL_EXPOSURE")
+	_ = os.Unsetenv("ASSET_WATCHER_SENSITIVE_PORTS")
+	_ = os.Unsetenv("ASSET_WATCHER_DNSBL_ZONES")
+	_ = os.Unsetenv("ASSET_WATCHER_ABUSEIPDB_KEY")
+	_ = os.Unsetenv("ASSET_WATCHER_ABUSEIPDB_MIN_SCORE")
+	_ = os.Unsetenv("ASSET_WATCHER_APPROVED_RANGES_FILE")
+	_ = os.Unsetenv("ASSET_WATCHER_BASELINE_FILE")
+	_ = os.Unsetenv("ASSET_WATCHER_PREFIX_SOURCE")
+	_ = os.Unsetenv("ASSET_WATCHER_RDAP_RANGES")
+	_ = os.Unsetenv("ASSET_WATCHER_RDAP_NETNAME")
+	_ = os.Unsetenv("ASSET_WATCHER_RDAP_CONTACTS")
+	_ = os.Unsetenv("ASSET_WATCHER_FAIL_ON_VIOLATION")
+	_ = os.Unsetenv("ASSET_WATCHER_FAIL_ON_CHANGES")
+	_ = os.Unsetenv("ASSET_WATCHER_FAIL_THRESHOLDS")
+	_ = os.Unsetenv("ASSET_WATCHER_EXIT_CODES")
+	_ = os.Unsetenv("ASSET_WATCHER_LISTEN_ADDRESS")
+	_ = os.Unsetenv("ASSET_WATCHER_HISTORY_DIR")
+	_ = os.Unsetenv("ASSET_WATCHER_SNAPSHOT_PATH")
+	_ = os.Unsetenv("ASSET_WATCHER_STATE_STORE")
+	_ = os.Unsetenv("ASSET_WATCHER_AUDIT_LOG")
+	_ = os.Unsetenv("ASSET_WATCHER_SKIP_NOTIFIER_CHECKS")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_MODE")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_DIGEST_WINDOW")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_MAX_ITEMS")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_SUBJECT_TEMPLATE")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_BODY_TEMPLATE")
+	_ = os.Unsetenv("ASSET_WATCHER_ADVISORIES")
+	_ = os.Unsetenv("ASSET_WATCHER_ADVISORY_TYPES")
+	_ = os.Unsetenv("ASSET_WATCHER_ADVISORY_SEVERITIES")
+	_ = os.Unsetenv("ASSET_WATCHER_ADVISORY_ROUTES")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_DEDUP_TTL")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_RETRIES")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_RETRY_BACKOFF")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_DEAD_LETTER")
+	_ = os.Unsetenv("ASSET_WATCHER_SLACK_THREADS")
+	_ = os.Unsetenv("ASSET_WATCHER_SLACK_ACTIONS")
+	_ = os.Unsetenv("ASSET_WATCHER_SLACK_UPLOAD_FINDINGS")
+	_ = os.Unsetenv("ASSET_WATCHER_SLACK_SIGNING_SECRET")
+	_ = os.Unsetenv("ASSET_WATCHER_PAGERDUTY_ROUTING_KEY")
+	_ = os.Unsetenv("ASSET_WATCHER_SMTP_HOST")
+	_ = os.Unsetenv("ASSET_WATCHER_SMTP_PORT")
+	_ = os.Unsetenv("ASSET_WATCHER_SMTP_USERNAME")
+	_ = os.Unsetenv("ASSET_WATCHER_SMTP_PASSWORD")
+	_ = os.Unsetenv("ASSET_WATCHER_EMAIL_FROM")
+	_ = os.Unsetenv("ASSET_WATCHER_EMAIL_TO")
+	_ = os.Unsetenv("ASSET_WATCHER_CRASH_REPORT_PATH")
+	_ = os.Unsetenv("ASSET_WATCHER_LOG_SEVERITIES")
+	_ = os.Unsetenv("ASSET_WATCHER_LOG_BUDGET")
+	_ = os.Unsetenv("ASSET_WATCHER_DEBUG_LOG_SAMPLING")
+	_ = os.Unsetenv("ASSET_WATCHER_TERRAFORM_STATE")
+	_ = os.Unsetenv("ASSET_WATCHER_BYOIP_RANGES")
+	_ = os.Unsetenv("ASSET_WATCHER_SHOW_DISPOSITION")
+	_ = os.Unsetenv("ASSET_WATCHER_CLASSIFICATION_RULES")
+	_ = os.Unsetenv("ASSET_WATCHER_CATEGORY_ROUTES")
+	_ = os.Unsetenv("ASSET_WATCHER_NOTIFY_ROUTES_FILE")
+	_ = os.Unsetenv("ASSET_WATCHER_BYOIP_HOURLY_PRICE")
+	_ = os.Unsetenv("ASSET_WATCHER_AUDIT_LOG_RETENTION_DAYS")
+	_ = os.Unsetenv("ASSET_WATCHER_SIGNING_KEY")
+	_ = os.Unsetenv("ASSET_WATCHER_DNS_ZONES")
+	_ = os.Unsetenv("ASSET_WATCHER_ROUTE53_ZONES")
+	_ = os.Unsetenv("ASSET_WATCHER_CLOUDFLARE_ZONES")
+	_ = os.Unsetenv("ASSET_WATCHER_CLOUDFLARE_TOKEN")
+	_ = os.Unsetenv("ASSET_WATCHER_SCC_SOURCE")
+	_ = os.Unsetenv("ASSET_WATCHER_BIGQUERY_TABLE")
+	_ = os.Unsetenv("ASSET_WATCHER_NETBOX_URL")
+	_ = os.Unsetenv("ASSET_WATCHER_NETBOX_TOKEN")
+	_ = os.Unsetenv("ASSET_WATCHER_NETBOX_TAG")
+	_ = os.Unsetenv("ASSET_WATCHER_GITHUB_REPO")
+	_ = os.Unsetenv("ASSET_WATCHER_GITHUB_TOKEN")
+	_ = os.Unsetenv("ASSET_WATCHER_GITHUB_LABEL")
+	_ = os.Unsetenv("ASSET_WATCHER_GITHUB_API_URL")
+	_ = os.Unsetenv("ASSET_WATCHER_SQLITE_PATH")
+	_ = os.Unsetenv("ASSET_WATCHER_METRICS_FILE")
+	_ = os.Unsetenv("ASSET_WATCHER_RELEASED_RETENTION_DAYS")
+	_ = os.Unsetenv("ASSET_WATCHER_SHOW_RELEASED")
+	_ = os.Unsetenv("ASSET_WATCHER_CHRONICLE_CUSTOMER_ID")
+	_ = os.Unsetenv("ASSET_WATCHER_CHRONICLE_REGION")
+	_ = os.Unsetenv("ASSET_WATCHER_DESCRIBE_FALLBACK")
+	_ = os.Unsetenv("ASSET_WATCHER_DESCRIBE_RATE")
+	_ = os.Unsetenv("ASSET_WATCHER_TAG")
+	_ = os.Unsetenv("ASSET_WATCHER_TAG_DRY_RUN")
+	_ = os.Unsetenv("ASSET_WATCHER_CREDENTIALS")
+	_ = os.Unsetenv("ASSET_WATCHER_SLACK_TOKEN")
+	_ = os.Unsetenv("ASSET_WATCHER_SLACK_CHANNEL")
+	_ = os.Unsetenv("ASSET_WATCHER_TEAMS_WEBHOOK_URL")
+	_ = os.Unsetenv("ASSET_WATCHER_WEBHOOK_URL")
+	_ = os.Unsetenv("ASSET_WATCHER_ARTIFACT_URL")
 }
 
 // TestGetConfig_Defaults tests the default values for non-required fields.
@@ -28,7 +158,10 @@ func TestGetConfig_Defaults(t *testing.T) {
 
 	t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-id-defaults")
 
-	cfg := GetConfig()
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
 
 	if cfg.OrgID != "test-org-id-defaults" {
 		t.Errorf("expected OrgID to be 'test-org-id-defaults', got '%s'", cfg.OrgID)
@@ -42,6 +175,10 @@ func TestGetConfig_Defaults(t *testing.T) {
 		t.Errorf("expected OutputFormat default to be '%s', got '%s'", ConfigDefaults.OutputFormat, cfg.OutputFormat)
 	}
 
+	if cfg.AssetTypes != addressAssetType {
+		t.Errorf("expected AssetTypes default to be '%s', got '%s'", addressAssetType, cfg.AssetTypes)
+	}
+
 	if cfg.ExcludeReserved != false {
 		t.Errorf("expected ExcludeReserved default to be %t, got %t", ConfigDefaults.ExcludeReserved, cfg.ExcludeReserved)
 	}
@@ -53,6 +190,50 @@ func TestGetConfig_Defaults(t *testing.T) {
 	if cfg.IncludeProjects != "" {
 		t.Errorf("expected IncludeProjects default to be '%s' string, got '%s'", ConfigDefaults.IncludeProjects, cfg.IncludeProjects)
 	}
+
+	if cfg.ShowCost != false {
+		t.Errorf("expected ShowCost default to be %t, got %t", ConfigDefaults.ShowCost, cfg.ShowCost)
+	}
+
+	if cfg.IdleAddressHourlyPrice != defaultIdleAddressHourlyPrice {
+		t.Errorf("expected IdleAddressHourlyPrice default to be %v, got %v", defaultIdleAddressHourlyPrice, cfg.IdleAddressHourlyPrice)
+	}
+
+	if cfg.ShowRecommendations != false {
+		t.Errorf("expected ShowRecommendations default to be %t, got %t", ConfigDefaults.ShowRecommendations, cfg.ShowRecommendations)
+	}
+}
+
+// TestConfigDefaults_ListsEveryField checks that every option has its documented default in
+// ConfigDefaults, even a zero one, so that the list does not drift from the options.
+func TestConfigDefaults_ListsEveryField(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "config.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse config.go: %v", err)
+	}
+
+	listed := map[string]bool{}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "ConfigDefaults" {
+			return true
+		}
+
+		for _, elt := range spec.Values[0].(*ast.CompositeLit).Elts {
+			listed[elt.(*ast.KeyValueExpr).Key.(*ast.Ident).Name] = true
+		}
+
+		return false
+	})
+
+	configType := reflect.TypeFor[Config]()
+
+	for i := range configType.NumField() {
+		if field := configType.Field(i); field.Tag.Get("env") != "" && !listed[field.Name] {
+			t.Errorf("ConfigDefaults does not list %s", field.Name)
+		}
+	}
 }
 
 // TestGetConfig_LoadFromEnv tests loading configuration from environment variables.
@@ -62,19 +243,124 @@ func TestGetConfig_LoadFromEnv(t *testing.T) {
 	expectedConfig := Config{
 		OrgID:           "env-org-id",
 		Debug:           true,
+		Profile:         "acme",
+		UserAgent:       "acme-scanner/1.0",
+		ListenAddress:   "127.0.0.1:9090",
 		OutputFormat:    "json",
+		HistoryDir:      "/var/lib/asset-watcher/runs",
+		SnapshotPath:    "gs://asset-watcher-state/snapshot.json",
+		StateStore:      "firestore://state-project/asset-watcher",
+		AssetTypes:      "compute.googleapis.com/Address,compute.googleapis.com/Instance",
 		ExcludeReserved: true,
 		ExcludeProjects: "proj1,proj2",
-		IncludeProjects: "", // Will be empty as ExcludeProjects is set
+		IncludeProjects: "",
+		PerProject:      true,
+		IncludeLabels:   "env=prod",
+		ExcludeLabels:   "asset-watcher-ignore=true",
+
+		NameRegex:           "^nat-",
+		ExcludeProjectRegex: "^sandbox-",
+
+		ShowCost:               true,
+		IdleAddressHourlyPrice: 0.005,
+
+		ShowRecommendations: true,
+
+		PartnerCIDRs:         "acme=203.0.113.0/24",
+		FlowLogsTable:        "proj.dataset.flows",
+		FlowLogsLookbackDays: 14,
+		ShowLastTraffic:      true,
+
+		FirewallExposure: true,
+		SensitivePorts:   "22,3389",
+
+		DNSBLZones:        "zen.spamhaus.org,bl.spamcop.net",
+		AbuseIPDBKey:      "abuseipdb-key",
+		AbuseIPDBMinScore: 75,
+		FailOnViolation:   true,
+
+		DNSZones:        "proj-dns/public-zone",
+		Route53Zones:    "Z0123456789ABC",
+		CloudflareZones: "023e105f4ecef8ad9ca31a8372d0c353",
+		CloudflareToken: "cf-token",
+
+		SCCSource: "organizations/123/sources/456",
+
+		NetBoxTag: defaultNetBoxTag,
+
+		GitHubLabel:  defaultGitHubLabel,
+		GitHubAPIURL: defaultGitHubAPIURL,
+
+		EnvFile: defaultEnvFile,
+
+		TimeFormat: timeFormatDateTime,
+		TimeZone:   "UTC",
+
+		NotifyMode:         notifyModeFindings,
+		NotifyRetries:      defaultNotifyRetries,
+		NotifyRetryBackoff: defaultNotifyRetryBackoff,
+
+		SMTPPort: defaultSMTPPort,
+
+		ChronicleCustomerID: "0123abcd-0000-0000-0000-000000000000",
+		ChronicleRegion:     "europe",
+
+		DescribeFallback: true,
+		DescribeRate:     0.5,
+
+		Tag:       "asset-watcher-reviewed=false",
+		TagDryRun: true,
+
+		Credentials: "scc=impersonate:scc-publisher@proj.iam.gserviceaccount.com",
 	}
 
 	t.Setenv("ASSET_WATCHER_ORG_ID", expectedConfig.OrgID)
 	t.Setenv("ASSET_WATCHER_DEBUG", "true")
+	t.Setenv("ASSET_WATCHER_PROFILE", expectedConfig.Profile)
+	t.Setenv("ASSET_WATCHER_USER_AGENT", expectedConfig.UserAgent)
+	t.Setenv("ASSET_WATCHER_LISTEN_ADDRESS", expectedConfig.ListenAddress)
 	t.Setenv("ASSET_WATCHER_OUTPUT_FORMAT", expectedConfig.OutputFormat)
+	t.Setenv("ASSET_WATCHER_HISTORY_DIR", expectedConfig.HistoryDir)
+	t.Setenv("ASSET_WATCHER_SNAPSHOT_PATH", expectedConfig.SnapshotPath)
+	t.Setenv("ASSET_WATCHER_STATE_STORE", expectedConfig.StateStore)
+	t.Setenv("ASSET_WATCHER_ASSET_TYPES", expectedConfig.AssetTypes)
 	t.Setenv("ASSET_WATCHER_EXCLUDE_RESERVED", "true")
 	t.Setenv("ASSET_WATCHER_EXCLUDE_PROJECTS", expectedConfig.ExcludeProjects)
-
-	cfg := GetConfig()
+	t.Setenv("ASSET_WATCHER_PER_PROJECT", "true")
+	t.Setenv("ASSET_WATCHER_INCLUDE_LABELS", expectedConfig.IncludeLabels)
+	t.Setenv("ASSET_WATCHER_EXCLUDE_LABELS", expectedConfig.ExcludeLabels)
+	t.Setenv("ASSET_WATCHER_NAME_REGEX", expectedConfig.NameRegex)
+	t.Setenv("ASSET_WATCHER_EXCLUDE_PROJECT_REGEX", expectedConfig.ExcludeProjectRegex)
+	t.Setenv("ASSET_WATCHER_SHOW_COST", "true")
+	t.Setenv("ASSET_WATCHER_IDLE_ADDRESS_HOURLY_PRICE", "0.005")
+	t.Setenv("ASSET_WATCHER_SHOW_RECOMMENDATIONS", "true")
+	t.Setenv("ASSET_WATCHER_PARTNER_CIDRS", expectedConfig.PartnerCIDRs)
+	t.Setenv("ASSET_WATCHER_FLOW_LOGS_TABLE", expectedConfig.FlowLogsTable)
+	t.Setenv("ASSET_WATCHER_FLOW_LOGS_LOOKBACK_DAYS", "14")
+	t.Setenv("ASSET_WATCHER_SHOW_LAST_TRAFFIC", "true")
+	t.Setenv("ASSET_WATCHER_FIREWALL_EXPOSURE", "true")
+	t.Setenv("ASSET_WATCHER_SENSITIVE_PORTS", expectedConfig.SensitivePorts)
+	t.Setenv("ASSET_WATCHER_DNSBL_ZONES", expectedConfig.DNSBLZones)
+	t.Setenv("ASSET_WATCHER_ABUSEIPDB_KEY", expectedConfig.AbuseIPDBKey)
+	t.Setenv("ASSET_WATCHER_ABUSEIPDB_MIN_SCORE", "75")
+	t.Setenv("ASSET_WATCHER_FAIL_ON_VIOLATION", "true")
+	t.Setenv("ASSET_WATCHER_DNS_ZONES", expectedConfig.DNSZones)
+	t.Setenv("ASSET_WATCHER_ROUTE53_ZONES", expectedConfig.Route53Zones)
+	t.Setenv("ASSET_WATCHER_CLOUDFLARE_ZONES", expectedConfig.CloudflareZones)
+	t.Setenv("ASSET_WATCHER_CLOUDFLARE_TOKEN", expectedConfig.CloudflareToken)
+	t.Setenv("ASSET_WATCHER_SCC_SOURCE", expectedConfig.SCCSource)
+	t.Setenv("ASSET_WATCHER_CHRONICLE_CUSTOMER_ID", expectedConfig.ChronicleCustomerID)
+	t.Setenv("ASSET_WATCHER_CHRONICLE_REGION", expectedConfig.ChronicleRegion)
+	t.Setenv("ASSET_WATCHER_DESCRIBE_FALLBACK", "true")
+	t.Setenv("ASSET_WATCHER_DESCRIBE_RATE", "0.5")
+	t.Setenv("ASSET_WATCHER_TAG", expectedConfig.Tag)
+	t.Setenv("ASSET_WATCHER_TAG_DRY_RUN", "true")
+	t.Setenv("ASSET_WATCHER_CREDENTIALS", expectedConfig.Credentials)
+
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
 
 	if !reflect.DeepEqual(*cfg, expectedConfig) {
 		t.Errorf("expected config %+v, got %+v", expectedConfig, *cfg)
@@ -88,9 +374,40 @@ func TestGetConfig_LoadFromEnv_Include(t *testing.T) {
 		OrgID:           "env-org-id-include",
 		Debug:           false,               // Testing explicit false
 		OutputFormat:    defaultOutputFormat, // Testing explicit table
-		ExcludeReserved: false,               // Testing explicit false
+		AssetTypes:      addressAssetType,
+		ExcludeReserved: false, // Testing explicit false
 		ExcludeProjects: "",
 		IncludeProjects: "proj3,proj4",
+		ListenAddress:   defaultListenAddress,
+
+		ShowCost:               false,
+		IdleAddressHourlyPrice: defaultIdleAddressHourlyPrice,
+
+		FlowLogsLookbackDays: defaultFlowLogsLookbackDays,
+
+		SensitivePorts: defaultSensitivePorts,
+
+		AbuseIPDBMinScore: defaultAbuseIPDBMinScore,
+
+		NetBoxTag: defaultNetBoxTag,
+
+		GitHubLabel:  defaultGitHubLabel,
+		GitHubAPIURL: defaultGitHubAPIURL,
+
+		EnvFile: defaultEnvFile,
+
+		TimeFormat: timeFormatDateTime,
+		TimeZone:   "UTC",
+
+		NotifyMode:         notifyModeFindings,
+		NotifyRetries:      defaultNotifyRetries,
+		NotifyRetryBackoff: defaultNotifyRetryBackoff,
+
+		SMTPPort: defaultSMTPPort,
+
+		ChronicleRegion: chronicleDefaultRegion,
+
+		DescribeRate: defaultDescribeRate,
 	}
 
 	t.Setenv("ASSET_WATCHER_ORG_ID", expectedConfig.OrgID)
@@ -99,74 +416,598 @@ func TestGetConfig_LoadFromEnv_Include(t *testing.T) {
 	t.Setenv("ASSET_WATCHER_EXCLUDE_RESERVED", "false")
 	t.Setenv("ASSET_WATCHER_INCLUDE_PROJECTS", expectedConfig.IncludeProjects)
 
-	cfg := GetConfig()
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
 
 	if !reflect.DeepEqual(*cfg, expectedConfig) {
 		t.Errorf("expected config %+v, got %+v", expectedConfig, *cfg)
 	}
 }
 
-func runTestExpectingFatal(t *testing.T, testName string, setupFunc func()) {
+// expectConfigError sets up the environment of an invalid configuration, and expects GetConfig
+// to return ErrInvalidConfig.
+func expectConfigError(t *testing.T, setupFunc func()) {
 	t.Helper()
-	// Check if we are in the subprocess
-	if os.Getenv("BE_FATAL_TESTER") == "1" {
-		setupFunc() // Setup env vars for the specific test case
-		GetConfig() // This should call log.Fatalf and exit
 
-		return // Should not be reached in subprocess
+	setupFunc()
+
+	if cfg, err := GetConfig(); !errors.Is(err, ErrInvalidConfig) || cfg != nil {
+		t.Errorf("expected ErrInvalidConfig, got %v, %v", cfg, err)
 	}
+}
+
+func TestGetConfig_MissingRequiredEnv(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+	})
+}
 
-	// Prepare to run the test in a subprocess
-	cmd := exec.Command(os.Args[0], "-test.run="+testName) //nolint:gosec // Will rerun only the current test function
-	cmd.Env = append(os.Environ(), "BE_FATAL_TESTER=1")    // Set marker for subprocess
+func TestGetConfig_ExcludeAndIncludeProjectsSet(t *testing.T) {
+	cleanEnvVars()
+	t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-exclude-include")
+	t.Setenv("ASSET_WATCHER_EXCLUDE_PROJECTS", "projA")
+	t.Setenv("ASSET_WATCHER_INCLUDE_PROJECTS", "projA,projB")
 
-	// We need to pass through necessary env vars for the test runner itself,
-	// but clear/set specific ASSET_WATCHER_* vars within setupFunc in the subprocess.
-	// The setupFunc will be responsible for setting the environment that causes GetConfig to fail.
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
 
-	err := cmd.Run()
-	// Check if the command exited with a non-zero status, indicating log.Fatalf was likely called.
-	exitErr := &exec.ExitError{}
-	if errors.As(err, &exitErr) {
-		// ExitError and non-zero exit status means the program exited as expected.
-		// We could also check e.Sys().(syscall.WaitStatus).ExitStatus() == 1 if needed for more specific exit codes.
-		return // Test passed
+	if cfg.IncludeProjects != "projA,projB" || cfg.ExcludeProjects != "projA" {
+		t.Errorf("expected both included and excluded projects, got %q and %q", cfg.IncludeProjects,
+			cfg.ExcludeProjects)
 	}
-	// If err is nil, or it's an ExitError but with success (exit code 0), then GetConfig did not call log.Fatalf.
-	var output []byte
+}
+
+func TestGetConfig_InvalidOutputFormat(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-format")
+		t.Setenv("ASSET_WATCHER_OUTPUT_FORMAT", "invalid-format")
+	})
+}
 
-	exitErr = &exec.ExitError{}
-	if errors.As(err, &exitErr) {
-		output = exitErr.Stderr // Stderr often contains the log.Fatalf message
-	} else if err != nil { // Other error during cmd.Run
-		t.Fatalf("%s: GetConfig did not call log.Fatalf as expected. Command execution failed: %v. Output: %s", testName, err, output)
+func TestGetConfig_OutputFormatCase(t *testing.T) {
+	cleanEnvVars()
+	t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-format-case")
+	t.Setenv("ASSET_WATCHER_OUTPUT_FORMAT", "JSON")
 
-		return
+	cfg, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
 	}
 
-	// If we reach here, cmd.Run() succeeded (exit code 0) or had an unexpected error.
-	t.Fatalf("%s: GetConfig did not call log.Fatalf as expected. Process exited cleanly or with an unexpected error. Output: %s", testName, output)
+	if cfg.OutputFormat != outputFormatJSON {
+		t.Errorf("expected OutputFormat %q, got %q", outputFormatJSON, cfg.OutputFormat)
+	}
 }
 
-func TestGetConfig_MissingRequiredEnv(t *testing.T) {
-	runTestExpectingFatal(t, "TestGetConfig_MissingRequiredEnv", func() {
+func TestGetConfig_NegativeIdleAddressPrice(t *testing.T) {
+	expectConfigError(t, func() {
 		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-negative-price")
+		t.Setenv("ASSET_WATCHER_IDLE_ADDRESS_HOURLY_PRICE", "-1")
 	})
 }
 
-func TestGetConfig_ExcludeAndIncludeProjectsSet(t *testing.T) {
-	runTestExpectingFatal(t, "TestGetConfig_ExcludeAndIncludeProjectsSet", func() {
+func TestGetConfig_InvalidIncludeLabels(t *testing.T) {
+	expectConfigError(t, func() {
 		cleanEnvVars()
-		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-exclude-include")
-		t.Setenv("ASSET_WATCHER_EXCLUDE_PROJECTS", "projA")
-		t.Setenv("ASSET_WATCHER_INCLUDE_PROJECTS", "projB")
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-labels")
+		t.Setenv("ASSET_WATCHER_INCLUDE_LABELS", "env")
 	})
 }
 
-func TestGetConfig_InvalidOutputFormat(t *testing.T) {
-	runTestExpectingFatal(t, "TestGetConfig_InvalidOutputFormat", func() {
+func TestGetConfig_PartnerCIDRsWithoutFlowLogs(t *testing.T) {
+	expectConfigError(t, func() {
 		cleanEnvVars()
-		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-format")
-		t.Setenv("ASSET_WATCHER_OUTPUT_FORMAT", "invalid-format")
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-partners")
+		t.Setenv("ASSET_WATCHER_PARTNER_CIDRS", "acme=203.0.113.0/24")
+	})
+}
+
+func TestGetConfig_InvalidFlowLogsTable(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-flow-logs")
+		t.Setenv("ASSET_WATCHER_FLOW_LOGS_TABLE", "dataset.flows")
+	})
+}
+
+func TestGetConfig_InvalidRegex(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-regex")
+		t.Setenv("ASSET_WATCHER_EXCLUDE_PROJECT_REGEX", "(sandbox")
+	})
+}
+
+func TestGetConfig_InvalidFilterExpr(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-expr")
+		t.Setenv("ASSET_WATCHER_FILTER_EXPR", "asset.status ==")
+	})
+}
+
+func TestGetConfig_LastTrafficWithoutFlowLogs(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-last-traffic")
+		t.Setenv("ASSET_WATCHER_SHOW_LAST_TRAFFIC", "true")
+	})
+}
+
+func TestGetConfig_MissingRulesFile(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-rules")
+		t.Setenv("ASSET_WATCHER_RULES_FILE", "/nonexistent/rules.yaml")
+	})
+}
+
+func TestGetConfig_InvalidMinAge(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-age")
+		t.Setenv("ASSET_WATCHER_MIN_AGE", "three months")
+	})
+}
+
+func TestGetConfig_InvalidTag(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-tag")
+		t.Setenv("ASSET_WATCHER_TAG", "reviewed")
+	})
+}
+
+func TestGetConfig_InvalidDescribeRate(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-describe")
+		t.Setenv("ASSET_WATCHER_DESCRIBE_RATE", "0")
+	})
+}
+
+func TestGetConfig_UnknownCredentialsComponent(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-credentials")
+		t.Setenv("ASSET_WATCHER_CREDENTIALS", "everything=/tmp/key.json")
+	})
+}
+
+func TestGetConfig_InvalidGroupBy(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-group-by")
+		t.Setenv("ASSET_WATCHER_GROUP_BY", "zone")
+	})
+}
+
+func TestGetConfig_SlackTokenWithoutChannel(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-slack")
+		t.Setenv("ASSET_WATCHER_SLACK_TOKEN", "xoxb-token")
+	})
+}
+
+func TestGetConfig_InvalidDNSZones(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-dns-zones")
+		t.Setenv("ASSET_WATCHER_DNS_ZONES", "public-zone")
+	})
+}
+
+func TestGetConfig_CloudflareZonesWithoutToken(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-cloudflare")
+		t.Setenv("ASSET_WATCHER_CLOUDFLARE_ZONES", "zone-1")
+	})
+}
+
+func TestGetConfig_MissingGeoIPDatabase(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-geoip")
+		t.Setenv("ASSET_WATCHER_GEOIP_DATABASE", "/nonexistent/GeoLite2-Country.mmdb")
+	})
+}
+
+func TestGetConfig_InvalidAbuseIPDBMinScore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-abuseipdb")
+		t.Setenv("ASSET_WATCHER_ABUSEIPDB_MIN_SCORE", "101")
+	})
+}
+
+func TestGetConfig_InvalidSensitivePorts(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-sensitive-ports")
+		t.Setenv("ASSET_WATCHER_SENSITIVE_PORTS", "22,ssh")
+	})
+}
+
+func TestGetConfig_MissingApprovedRangesFile(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-approved-ranges")
+		t.Setenv("ASSET_WATCHER_APPROVED_RANGES_FILE", "/nonexistent/approved-ranges.txt")
+	})
+}
+
+func TestGetConfig_MissingBaselineFile(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-baseline")
+		t.Setenv("ASSET_WATCHER_BASELINE_FILE", "/nonexistent/baseline.json")
+	})
+}
+
+func TestGetConfig_InvalidSigningKey(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-signing-key")
+		t.Setenv("ASSET_WATCHER_SIGNING_KEY", "/nonexistent/signing-key.pem")
+	})
+}
+
+func TestGetConfig_MissingPrefixTable(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-prefix-table")
+		t.Setenv("ASSET_WATCHER_PREFIX_SOURCE", "/nonexistent/prefixes.csv")
+	})
+}
+
+func TestGetConfig_InvalidSnapshotPath(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-snapshot")
+		t.Setenv("ASSET_WATCHER_SNAPSHOT_PATH", "gs://bucket-only")
+	})
+}
+
+func TestGetConfig_SnapshotWithBaseline(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-snapshot-baseline")
+		t.Setenv("ASSET_WATCHER_SNAPSHOT_PATH", "snapshot.json")
+		t.Setenv("ASSET_WATCHER_BASELINE_FILE", "baseline.json")
+	})
+}
+
+func TestGetConfig_MissingGeofeedRegions(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-geofeed")
+		t.Setenv("ASSET_WATCHER_GEOFEED_REGIONS", "/nonexistent/regions.csv")
+	})
+}
+
+func TestGetConfig_InvalidStateStore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-state-store")
+		t.Setenv("ASSET_WATCHER_STATE_STORE", "firestore://project-only")
+	})
+}
+
+func TestGetConfig_InvalidRDAPRanges(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-rdap")
+		t.Setenv("ASSET_WATCHER_RDAP_RANGES", "203.0.113.0/24,not-a-range")
+	})
+}
+
+func TestGetConfig_AuditLogWithoutSnapshot(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-audit-log")
+		t.Setenv("ASSET_WATCHER_AUDIT_LOG", "audit")
+	})
+}
+
+func TestGetConfig_NegativeAuditLogRetention(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-audit-log")
+		t.Setenv("ASSET_WATCHER_SNAPSHOT_PATH", "snapshot.json")
+		t.Setenv("ASSET_WATCHER_AUDIT_LOG", "audit")
+		t.Setenv("ASSET_WATCHER_AUDIT_LOG_RETENTION_DAYS", "-1")
+	})
+}
+
+func TestGetConfig_InvalidLogSeverities(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-log-severities")
+		t.Setenv("ASSET_WATCHER_LOG_SEVERITIES", "ERROR=SEVERE")
+	})
+}
+
+func TestGetConfig_TerraformStateWithoutAddresses(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-terraform-state")
+		t.Setenv("ASSET_WATCHER_ASSET_TYPES", "compute.googleapis.com/Instance")
+		t.Setenv("ASSET_WATCHER_TERRAFORM_STATE", "terraform.tfstate")
+	})
+}
+
+func TestGetConfig_NegativeLogBudget(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-log-budget")
+		t.Setenv("ASSET_WATCHER_LOG_BUDGET", "-1")
+	})
+}
+
+func TestGetConfig_InvalidNotifiers(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notifiers")
+		t.Setenv("ASSET_WATCHER_SLACK_TOKEN", "xoxb-token")
+		t.Setenv("ASSET_WATCHER_SLACK_CHANNEL", "alerts")
+	})
+}
+
+func TestGetConfig_InvalidBYOIPRanges(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-byoip")
+		t.Setenv("ASSET_WATCHER_BYOIP_RANGES", "203.0.113.0/33")
+	})
+}
+
+func TestGetConfig_CategoryRoutesWithoutClassification(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-category-routes")
+		t.Setenv("ASSET_WATCHER_CATEGORY_ROUTES", "nat=slack")
+	})
+}
+
+func TestGetConfig_InvalidOutputTemplate(t *testing.T) {
+	expectConfigError(t, func() {
+		path := filepath.Join(t.TempDir(), "invalid.tmpl")
+		if err := os.WriteFile(path, []byte("{{ range .Assets }}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-output-template")
+		t.Setenv("ASSET_WATCHER_OUTPUT_TEMPLATE", path)
+	})
+}
+
+func TestGetConfig_InvalidResultPath(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-result-path")
+		t.Setenv("ASSET_WATCHER_RESULT_PATH", "gs://bucket")
+	})
+}
+
+func TestGetConfig_InvalidBigQueryTable(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-bigquery-table")
+		t.Setenv("ASSET_WATCHER_BIGQUERY_TABLE", "dataset.assets")
+	})
+}
+
+func TestGetConfig_ReleasedRetentionWithoutSnapshot(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-released-retention")
+		t.Setenv("ASSET_WATCHER_RELEASED_RETENTION_DAYS", "90")
+	})
+}
+
+func TestGetConfig_InvalidMetricsFile(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-metrics-file")
+		t.Setenv("ASSET_WATCHER_METRICS_FILE", "/var/lib/node_exporter/asset_watcher.txt")
+	})
+}
+
+func TestGetConfig_NetBoxURLWithoutToken(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-netbox")
+		t.Setenv("ASSET_WATCHER_NETBOX_URL", "https://netbox.example.com")
+	})
+}
+
+func TestGetConfig_FailOnChangesWithoutChangeDetection(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-fail-on-changes")
+		t.Setenv("ASSET_WATCHER_FAIL_ON_CHANGES", "true")
+	})
+}
+
+func TestGetConfig_NotifyChangesWithoutSnapshot(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-mode")
+		t.Setenv("ASSET_WATCHER_NOTIFY_MODE", notifyModeChanges)
+	})
+}
+
+func TestGetConfig_NotifyRoutesUnconfiguredNotifier(t *testing.T) {
+	expectConfigError(t, func() {
+		path := filepath.Join(t.TempDir(), "routes.yaml")
+		_ = os.WriteFile(path, []byte("routes:\n  - notify: [pagerduty]\n"), 0o600)
+
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-routes")
+		t.Setenv("ASSET_WATCHER_NOTIFY_ROUTES_FILE", path)
+	})
+}
+
+func TestGetConfig_NotifyDigestWindowWithoutStateStore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-digest")
+		t.Setenv("ASSET_WATCHER_NOTIFY_DIGEST_WINDOW", "1d")
+	})
+}
+
+func TestGetConfig_InvalidNotifyDigestWindow(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-digest")
+		t.Setenv("ASSET_WATCHER_STATE_STORE", t.TempDir())
+		t.Setenv("ASSET_WATCHER_NOTIFY_DIGEST_WINDOW", "0s")
+	})
+}
+
+func TestGetConfig_NegativeNotifyMaxItems(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-max-items")
+		t.Setenv("ASSET_WATCHER_NOTIFY_MAX_ITEMS", "-1")
+	})
+}
+
+func TestGetConfig_NotifyDedupTTLWithoutStateStore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-dedup")
+		t.Setenv("ASSET_WATCHER_NOTIFY_DEDUP_TTL", "12h")
+	})
+}
+
+func TestGetConfig_InvalidNotifyRetryBackoff(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-retries")
+		t.Setenv("ASSET_WATCHER_NOTIFY_RETRY_BACKOFF", "soon")
+	})
+}
+
+func TestGetConfig_InvalidNotifyDeadLetterTopic(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-dead-letter")
+		t.Setenv("ASSET_WATCHER_NOTIFY_DEAD_LETTER", "pubsub://project-only")
+	})
+}
+
+func TestGetConfig_SlackThreadsWithoutStateStore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-slack-threads")
+		t.Setenv("ASSET_WATCHER_SLACK_THREADS", "true")
+	})
+}
+
+func TestGetConfig_SlackSigningSecretWithoutStateStore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-slack-actions")
+		t.Setenv("ASSET_WATCHER_SLACK_SIGNING_SECRET", "signing-secret")
+	})
+}
+
+func TestGetConfig_InvalidGitHubRepo(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-github-repo")
+		t.Setenv("ASSET_WATCHER_GITHUB_REPO", "example/security/findings")
+		t.Setenv("ASSET_WATCHER_GITHUB_TOKEN", "github-token")
+	})
+}
+
+func TestGetConfig_GitHubRepoWithoutToken(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-github-token")
+		t.Setenv("ASSET_WATCHER_GITHUB_REPO", "example/findings")
+	})
+}
+
+func TestGetConfig_InvalidNotifyBodyTemplate(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-notify-template")
+		t.Setenv("ASSET_WATCHER_NOTIFY_BODY_TEMPLATE", "/nonexistent/notification.tmpl")
+	})
+}
+
+func TestGetConfig_InvalidAdvisoryTypes(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-advisory-types")
+		t.Setenv("ASSET_WATCHER_ADVISORY_TYPES", "security-privacy,incident")
+	})
+}
+
+func TestGetConfig_AdvisoriesWithoutStateStore(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-advisories")
+		t.Setenv("ASSET_WATCHER_ADVISORIES", "true")
+	})
+}
+
+func TestGetConfig_UnknownConfigFileSetting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("org_id: \"123\"\nslack_chanel: C123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_CONFIG", path)
+	})
+}
+
+func TestGetConfig_AllErrors(t *testing.T) {
+	cleanEnvVars()
+	t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-all-errors")
+	t.Setenv("ASSET_WATCHER_OUTPUT_FORMAT", "yaml")
+	t.Setenv("ASSET_WATCHER_LOG_BUDGET", "-1")
+	t.Setenv("ASSET_WATCHER_NOTIFY_MODE", "always")
+
+	_, err := GetConfig()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	for _, option := range []string{
+		"ASSET_WATCHER_OUTPUT_FORMAT", "ASSET_WATCHER_LOG_BUDGET", "ASSET_WATCHER_NOTIFY_MODE",
+	} {
+		if !strings.Contains(err.Error(), "invalid value for "+option) {
+			t.Errorf("expected the error of %s in %q", option, err)
+		}
+	}
+}
+
+func TestGetConfig_InvalidInterval(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-interval")
+		t.Setenv("ASSET_WATCHER_INTERVAL", "hourly")
+	})
+}
+
+func TestGetConfig_InvalidTimeout(t *testing.T) {
+	expectConfigError(t, func() {
+		cleanEnvVars()
+		t.Setenv("ASSET_WATCHER_ORG_ID", "test-org-for-invalid-timeout")
+		t.Setenv("ASSET_WATCHER_TIMEOUT", "0s")
 	})
 }
@@ -0,0 +1,152 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+// configCommand validates the configuration, or shows the effective configuration.
+const configCommand = "config"
+
+var (
+	errUnknownConfigAction = errors.New("unknown config action")
+	errUnknownConfigFormat = errors.New("unknown config format")
+)
+
+// runConfigCommand runs `config validate`, which checks the configuration and the credentials
+// of the components, or `config show`, which writes the effective configuration merged from
+// the defaults, the configuration file, and the environment, with the secrets redacted. The
+// configuration is parsed and validated by GetConfig before the command runs, so an invalid
+// one fails with every invalid option.
+func runConfigCommand(ctx context.Context, cfg *Config, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: expected validate or show", errUnknownConfigAction)
+	}
+
+	switch args[0] {
+	case "validate":
+		flags := flag.NewFlagSet(configCommand+" validate", flag.ContinueOnError)
+		flags.SetOutput(stdout)
+
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("failed to parse arguments: %w", err)
+		}
+
+		return validateConfig(ctx, cfg, stdout)
+	case "show":
+		flags := flag.NewFlagSet(configCommand+" show", flag.ContinueOnError)
+		flags.SetOutput(stdout)
+		format := flags.String("format", "json", "output format: json or env")
+
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("failed to parse arguments: %w", err)
+		}
+
+		return writeEffectiveConfig(cfg, *format, stdout)
+	default:
+		return fmt.Errorf("%w: %s, expected validate or show", errUnknownConfigAction, strconv.Quote(args[0]))
+	}
+}
+
+// validateConfig checks the credentials of the components of the validated configuration:
+// the credentials files must be readable and of a supported type, and the Application Default
+// Credentials must be found if a component uses them or impersonates a service account. Every
+// check is written as PASS or FAIL.
+func validateConfig(ctx context.Context, cfg *Config, stdout io.Writer) error {
+	checks := &selftest{out: stdout}
+
+	source := "the environment"
+	if cfg.ConfigFile != "" {
+		source = cfg.ConfigFile + " and the environment"
+	}
+
+	checks.check("configuration", true, "parsed and validated from %s", source)
+
+	// The credentials are validated by GetConfig.
+	sources, _ := parseCredentials(cfg.Credentials)
+	needsADC := false
+
+	for _, component := range credentialComponents {
+		source, ok := sources[component]
+
+		switch {
+		case !ok:
+			needsADC = true
+		case source.serviceAccount != "":
+			needsADC = true
+
+			checks.check("credentials", true, "%s impersonates %s", component, source.serviceAccount)
+		default:
+			credType, err := readCredentialsType(source.file)
+			checks.check("credentials", err == nil, "%s uses %s", component, credentialsResult(credType, err))
+		}
+	}
+
+	for _, component := range awsCredentialComponents {
+		if source, ok := sources[component]; ok {
+			_, err := awsCredentialsFromFile(source.file)
+			checks.check("credentials", err == nil, "%s uses %s", component, errorOr(err, "an AWS credentials file"))
+		}
+	}
+
+	if needsADC {
+		creds, err := google.FindDefaultCredentials(ctx, cloudPlatformScope)
+		if err != nil {
+			checks.check("adc", false, "Application Default Credentials not found: %v", err)
+		} else {
+			checks.check("adc", true, "Application Default Credentials found for project %s",
+				strconv.Quote(creds.ProjectID))
+		}
+	}
+
+	if checks.failed > 0 {
+		return fmt.Errorf("%w: %d checks failed", ErrInvalidConfig, checks.failed)
+	}
+
+	return nil
+}
+
+// credentialsResult describes the type of a credentials file, or the error reading it.
+func credentialsResult(credType option.CredentialsType, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return "a " + string(credType) + " credentials file"
+}
+
+// writeEffectiveConfig writes the effective configuration keyed by environment variable, as a
+// JSON object or as NAME=value lines, with the secrets redacted.
+func writeEffectiveConfig(cfg *Config, format string, stdout io.Writer) error {
+	effective := effectiveConfig(cfg)
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(effective); err != nil {
+			return fmt.Errorf("failed to write configuration: %w", err)
+		}
+	case "env":
+		for _, name := range slices.Sorted(maps.Keys(effective)) {
+			if _, err := fmt.Fprintf(stdout, "%s=%v\n", name, effective[name]); err != nil {
+				return fmt.Errorf("failed to write configuration: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s, expected json or env", errUnknownConfigFormat, strconv.Quote(format))
+	}
+
+	return nil
+}
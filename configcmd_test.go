@@ -0,0 +1,103 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunConfigCommand_Show(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.SlackToken = "xoxb-secret"
+	cfg.SlackChannel = "#alerts"
+
+	var out bytes.Buffer
+	if err := runConfigCommand(t.Context(), &cfg, []string{"show"}, &out); err != nil {
+		t.Fatalf("runConfigCommand() error = %v", err)
+	}
+
+	var effective map[string]any
+	if err := json.Unmarshal(out.Bytes(), &effective); err != nil {
+		t.Fatalf("failed to decode configuration: %v", err)
+	}
+
+	if effective["ASSET_WATCHER_ORG_ID"] != "123" || effective["ASSET_WATCHER_SLACK_TOKEN"] != redactedValue {
+		t.Errorf("unexpected configuration %v", effective)
+	}
+
+	out.Reset()
+
+	if err := runConfigCommand(t.Context(), &cfg, []string{"show", "--format", "env"}, &out); err != nil {
+		t.Fatalf("runConfigCommand() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"ASSET_WATCHER_ORG_ID=123\n",
+		"ASSET_WATCHER_SLACK_TOKEN=" + redactedValue + "\n",
+		"ASSET_WATCHER_SLACK_THREADS=false\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in %q", want, out.String())
+		}
+	}
+
+	if err := runConfigCommand(t.Context(), &cfg, []string{"show", "--format", "yaml"}, &out); !errors.Is(err, errUnknownConfigFormat) {
+		t.Errorf("expected errUnknownConfigFormat, got %v", err)
+	}
+
+	for _, args := range [][]string{nil, {"dump"}} {
+		if err := runConfigCommand(t.Context(), &cfg, args, &out); !errors.Is(err, errUnknownConfigAction) {
+			t.Errorf("runConfigCommand(%q) expected errUnknownConfigAction, got %v", args, err)
+		}
+	}
+}
+
+func TestRunConfigCommand_Validate(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key.json")
+
+	key := `{"type": "service_account", "project_id": "adc-project", "client_email": "sa@adc-project.iam.gserviceaccount.com",
+"private_key": "unused"}`
+	if err := os.WriteFile(keyFile, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", keyFile)
+
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.ConfigFile = "asset-watcher.yaml"
+	cfg.Credentials = "scc=" + keyFile + ",chronicle=impersonate:chronicle@example.iam.gserviceaccount.com"
+
+	var out bytes.Buffer
+	if err := runConfigCommand(t.Context(), &cfg, []string{"validate"}, &out); err != nil {
+		t.Fatalf("runConfigCommand() error = %v\n%s", err, out.String())
+	}
+
+	for _, want := range []string{
+		"PASS  configuration  parsed and validated from asset-watcher.yaml and the environment\n",
+		"PASS  credentials    scc uses a service_account credentials file\n",
+		"PASS  credentials    chronicle impersonates chronicle@example.iam.gserviceaccount.com\n",
+		"PASS  adc            Application Default Credentials found for project \"adc-project\"\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in %q", want, out.String())
+		}
+	}
+
+	out.Reset()
+
+	cfg.Credentials = "scc=" + filepath.Join(dir, "missing.json")
+	if err := runConfigCommand(t.Context(), &cfg, []string{"validate"}, &out); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	if !strings.Contains(out.String(), "FAIL  credentials    scc uses failed to read credentials file") {
+		t.Errorf("expected the missing credentials file to fail, got %q", out.String())
+	}
+}
@@ -0,0 +1,301 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	env "github.com/caarlos0/env/v11"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFileEnv is the environment variable of the configuration file, which the --config
+	// flag overrides.
+	configFileEnv = "ASSET_WATCHER_CONFIG"
+
+	// profileEnv is the environment variable naming the deployment, which selects the profile
+	// of the configuration file, and which the --profile flag overrides.
+	profileEnv = "ASSET_WATCHER_PROFILE"
+
+	// profilesSetting is the table of the profiles in the configuration file.
+	profilesSetting = "profiles"
+
+	envPrefix = "ASSET_WATCHER_"
+)
+
+var (
+	errInvalidConfigFile = errors.New("invalid configuration file")
+	errInvalidGlobalFlag = errors.New("invalid flag")
+	errUnknownProfile    = errors.New("unknown profile")
+	errNoProfiles        = errors.New("no profiles in the configuration file")
+)
+
+// globalFlags are the flags accepted before or after any subcommand.
+type globalFlags struct {
+	// configFile is the path of the configuration file of --config.
+	configFile string
+
+	// profile is the profile of the configuration file selected with --profile.
+	profile string
+
+	// allProfiles runs the command for every profile of the configuration file.
+	allProfiles bool
+}
+
+// parseGlobalFlags returns the global flags of the arguments, and the arguments without them,
+// so that they can be set before any subcommand, which does not know them. The flags are
+// --config PATH, --profile NAME, also as --flag=value, and --all-profiles. The arguments
+// after -- are left as they are.
+func parseGlobalFlags(args []string) (globalFlags, []string, error) {
+	var flags globalFlags
+
+	values := map[string]*string{"config": &flags.configFile, "profile": &flags.profile}
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		target, ok := values[name]
+
+		switch {
+		case !strings.HasPrefix(arg, "-"):
+			rest = append(rest, arg)
+		case name == "all-profiles" && !hasValue:
+			flags.allProfiles = true
+		case !ok:
+			rest = append(rest, arg)
+		case hasValue:
+			*target = value
+		case i+1 == len(args):
+			return globalFlags{}, nil, fmt.Errorf("%w: missing value of --%s", errInvalidGlobalFlag, name)
+		default:
+			i++
+			*target = args[i]
+		}
+	}
+
+	if flags.allProfiles && flags.profile != "" {
+		return globalFlags{}, nil, fmt.Errorf("%w: --profile cannot be combined with --all-profiles",
+			errInvalidGlobalFlag)
+	}
+
+	return flags, rest, nil
+}
+
+// configEnvironment returns the environment the configuration is parsed from: the settings of
+// the configuration file at path, if not empty, with those of the profile, overridden by the
+// environment variables.
+func configEnvironment(path, profile string) (map[string]string, error) {
+	environment := map[string]string{}
+
+	if path != "" {
+		settings, err := loadConfigFile(path, profile)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(environment, settings)
+	}
+
+	maps.Copy(environment, env.ToMap(os.Environ()))
+
+	return environment, nil
+}
+
+// loadConfigFile reads a YAML, JSON, or TOML configuration file into the environment variables
+// of its settings. The settings are named after the environment variables without the
+// ASSET_WATCHER_ prefix, in lower case, such as slack_channel, and tables group the settings
+// sharing a prefix, so that slack: {channel: C123} sets ASSET_WATCHER_SLACK_CHANNEL. Lists are
+// joined with commas. The profiles table holds a table of settings per profile; the settings of
+// the profile, if not empty and the file has profiles, override the shared settings.
+func loadConfigFile(path, profile string) (map[string]string, error) {
+	settings, profiles, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	environment := map[string]string{}
+	if err := flattenConfigSettings(environment, "", settings); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errInvalidConfigFile, path, err)
+	}
+
+	if profile != "" && len(profiles) > 0 {
+		settings, ok := profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s, expected one of %s", errUnknownProfile, strconv.Quote(profile),
+				strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+		}
+
+		if err := flattenConfigSettings(environment, "", settings); err != nil {
+			return nil, fmt.Errorf("%w: %s: profile %s: %w", errInvalidConfigFile, path, profile, err)
+		}
+	}
+
+	known := configEnvNames()
+
+	for _, name := range slices.Sorted(maps.Keys(environment)) {
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("%w: %s: unknown setting %s", errInvalidConfigFile, path,
+				strings.ToLower(strings.TrimPrefix(name, envPrefix)))
+		}
+	}
+
+	return environment, nil
+}
+
+// configProfiles returns the names of the profiles of the configuration file, sorted.
+func configProfiles(path string) ([]string, error) {
+	_, profiles, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("%w: %s", errNoProfiles, path)
+	}
+
+	return slices.Sorted(maps.Keys(profiles)), nil
+}
+
+// readConfigFile parses the configuration file into its shared settings and the settings of
+// its profiles.
+func readConfigFile(path string) (map[string]any, map[string]map[string]any, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var settings map[string]any
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", ".json":
+		if err := yaml.Unmarshal(data, &settings); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %w", errInvalidConfigFile, path, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &settings); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %w", errInvalidConfigFile, path, err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w: %s: unknown extension %s, expected .yaml, .yml, .json, or .toml",
+			errInvalidConfigFile, path, strconv.Quote(ext))
+	}
+
+	value, ok := settings[profilesSetting]
+	if !ok {
+		return settings, nil, nil
+	}
+
+	delete(settings, profilesSetting)
+
+	table, ok := value.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s: %s must be a table of profiles", errInvalidConfigFile, path,
+			profilesSetting)
+	}
+
+	profiles := make(map[string]map[string]any, len(table))
+
+	for name, value := range table {
+		if profiles[name], ok = value.(map[string]any); !ok {
+			return nil, nil, fmt.Errorf("%w: %s: profile %s must be a table of settings", errInvalidConfigFile,
+				path, strconv.Quote(name))
+		}
+	}
+
+	return settings, profiles, nil
+}
+
+// flattenConfigSettings adds the settings of a table of the configuration file, whose settings
+// are prefixed with prefix, to the environment.
+func flattenConfigSettings(environment map[string]string, prefix string, settings map[string]any) error {
+	for key, value := range settings {
+		name := prefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+
+		switch v := value.(type) {
+		case map[string]any:
+			if err := flattenConfigSettings(environment, name+"_", v); err != nil {
+				return err
+			}
+
+			continue
+		case []any:
+			items := make([]string, 0, len(v))
+
+			for _, item := range v {
+				s, err := configSettingValue(item)
+				if err != nil {
+					return fmt.Errorf("setting %s: %w", strings.ToLower(name), err)
+				}
+
+				items = append(items, s)
+			}
+
+			environment[envPrefix+name] = strings.Join(items, ",")
+		default:
+			s, err := configSettingValue(v)
+			if err != nil {
+				return fmt.Errorf("setting %s: %w", strings.ToLower(name), err)
+			}
+
+			environment[envPrefix+name] = s
+		}
+	}
+
+	return nil
+}
+
+// configSettingValue returns the value of a scalar setting as an environment variable.
+func configSettingValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported value of type %T", errInvalidConfigFile, value)
+	}
+}
+
+// configEnvNames returns the environment variables of the configuration, except those of the
+// configuration file itself and of the env file, which is read before.
+func configEnvNames() []string {
+	names := []string{}
+	t := reflect.TypeFor[Config]()
+
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("env"), ",")
+		if name != "" && name != configFileEnv && name != envFileEnv {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,270 @@
+package assetwatcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantFlags globalFlags
+		wantArgs  []string
+	}{
+		{name: "none", args: []string{"diff", "--since", "7d"}, wantArgs: []string{"diff", "--since", "7d"}},
+		{
+			name:      "before command",
+			args:      []string{"--config", "a.yaml", "--profile", "acme", "diff"},
+			wantFlags: globalFlags{configFile: "a.yaml", profile: "acme"},
+			wantArgs:  []string{"diff"},
+		},
+		{
+			name:      "after command",
+			args:      []string{"check", "--config=a.toml", "--all-profiles"},
+			wantFlags: globalFlags{configFile: "a.toml", allProfiles: true},
+			wantArgs:  []string{"check"},
+		},
+		{
+			name:      "single dash",
+			args:      []string{"-config", "a.yaml", "-profile=acme"},
+			wantFlags: globalFlags{configFile: "a.yaml", profile: "acme"},
+			wantArgs:  []string{},
+		},
+		{
+			name:     "after terminator",
+			args:     []string{"notify", "--", "--config", "a.yaml"},
+			wantArgs: []string{"notify", "--", "--config", "a.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, args, err := parseGlobalFlags(tt.args)
+			if err != nil {
+				t.Fatalf("parseGlobalFlags() error = %v", err)
+			}
+
+			if flags != tt.wantFlags || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("parseGlobalFlags() = %+v, %q, want %+v, %q", flags, args, tt.wantFlags, tt.wantArgs)
+			}
+		})
+	}
+
+	for _, args := range [][]string{{"--config"}, {"--profile", "acme", "--all-profiles"}} {
+		if _, _, err := parseGlobalFlags(args); !errors.Is(err, errInvalidGlobalFlag) {
+			t.Errorf("parseGlobalFlags(%q) expected errInvalidGlobalFlag, got %v", args, err)
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	want := map[string]string{
+		"ASSET_WATCHER_ORG_ID":           "123",
+		"ASSET_WATCHER_INCLUDE_PROJECTS": "prod-a,prod-b",
+		"ASSET_WATCHER_SLACK_CHANNEL":    "C123",
+		"ASSET_WATCHER_SLACK_THREADS":    "true",
+		"ASSET_WATCHER_NOTIFY_RETRIES":   "5",
+		"ASSET_WATCHER_EXCLUDE_RESERVED": "",
+	}
+
+	files := map[string]string{
+		"config.yaml": `org_id: "123"
+include_projects: [prod-a, prod-b]
+slack:
+  channel: C123
+  threads: true
+notify-retries: 5
+exclude_reserved:
+`,
+		"config.json": `{"org_id": "123", "include_projects": ["prod-a", "prod-b"],
+"slack": {"channel": "C123", "threads": true}, "notify_retries": 5, "exclude_reserved": null}`,
+		"config.toml": `# asset-watcher
+org_id = "123"
+include_projects = [
+  "prod-a", # production
+  'prod-b',
+]
+notify_retries = 5
+exclude_reserved = ""
+
+[slack]
+channel = "C123"
+threads = true
+`,
+		"inline.toml": `org_id = """
+123"""
+include_projects = ["prod-a", "prod-b"]
+slack = { channel = "C123", threads = true }
+notify_retries = 5
+exclude_reserved = ''
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			got, err := loadConfigFile(writeConfigFile(t, name, content), "")
+			if err != nil {
+				t.Fatalf("loadConfigFile() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("loadConfigFile() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "unknown setting", file: "config.yaml", content: "slack_chanel: C123\n"},
+		{name: "configuration file", file: "config.yaml", content: "config: other.yaml\n"},
+		{name: "list of tables", file: "config.yaml", content: "include_projects:\n  - name: prod\n"},
+		{name: "invalid YAML", file: "config.yaml", content: "org_id: [\n"},
+		{name: "invalid TOML", file: "config.toml", content: "org_id = \n"},
+		{name: "TOML array of tables", file: "config.toml", content: "[[include_projects]]\nname = \"prod\"\n"},
+		{name: "unknown extension", file: "config.ini", content: "org_id=123\n"},
+		{name: "profiles list", file: "config.yaml", content: "profiles: [acme]\n"},
+		{name: "profile value", file: "config.yaml", content: "profiles:\n  acme: \"123\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadConfigFile(writeConfigFile(t, tt.file, tt.content), ""); !errors.Is(err, errInvalidConfigFile) {
+				t.Errorf("expected errInvalidConfigFile, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigEnvironment_Precedence(t *testing.T) {
+	cleanEnvVars()
+	t.Setenv("ASSET_WATCHER_OUTPUT_FORMAT", "json")
+
+	path := writeConfigFile(t, "config.yaml", "org_id: \"123\"\noutput_format: sarif\n")
+
+	cfg, err := getConfig(path, "")
+	if err != nil {
+		t.Fatalf("getConfig() error = %v", err)
+	}
+
+	if cfg.OrgID != "123" {
+		t.Errorf("expected the organization of the file, got %q", cfg.OrgID)
+	}
+
+	if cfg.OutputFormat != "json" {
+		t.Errorf("expected the environment to take precedence over the file, got %q", cfg.OutputFormat)
+	}
+
+	if cfg.ConfigFile != path {
+		t.Errorf("expected the configuration file %q, got %q", path, cfg.ConfigFile)
+	}
+
+	if cfg.NotifyRetries != defaultNotifyRetries {
+		t.Errorf("expected the default retries, got %d", cfg.NotifyRetries)
+	}
+}
+
+const profilesConfig = `org_id: "0"
+output_format: json
+github:
+  label: shared
+profiles:
+  acme:
+    org_id: "111"
+    github:
+      label: acme
+  globex:
+    org_id: "222"
+    include_projects: [globex-prod]
+`
+
+func TestLoadConfigFile_Profiles(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", profilesConfig)
+
+	tests := []struct {
+		profile string
+		want    map[string]string
+	}{
+		{
+			profile: "",
+			want: map[string]string{
+				"ASSET_WATCHER_ORG_ID": "0", "ASSET_WATCHER_OUTPUT_FORMAT": "json", "ASSET_WATCHER_GITHUB_LABEL": "shared",
+			},
+		},
+		{
+			profile: "acme",
+			want: map[string]string{
+				"ASSET_WATCHER_ORG_ID": "111", "ASSET_WATCHER_OUTPUT_FORMAT": "json", "ASSET_WATCHER_GITHUB_LABEL": "acme",
+			},
+		},
+		{
+			profile: "globex",
+			want: map[string]string{
+				"ASSET_WATCHER_ORG_ID": "222", "ASSET_WATCHER_OUTPUT_FORMAT": "json", "ASSET_WATCHER_GITHUB_LABEL": "shared",
+				"ASSET_WATCHER_INCLUDE_PROJECTS": "globex-prod",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := loadConfigFile(path, tt.profile)
+		if err != nil {
+			t.Fatalf("loadConfigFile(%q) error = %v", tt.profile, err)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("loadConfigFile(%q) = %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+
+	if _, err := loadConfigFile(path, "initech"); !errors.Is(err, errUnknownProfile) {
+		t.Errorf("expected errUnknownProfile, got %v", err)
+	}
+
+	// Without profiles in the file, the profile only names the deployment.
+	if _, err := loadConfigFile(writeConfigFile(t, "plain.yaml", "org_id: \"1\"\n"), "initech"); err != nil {
+		t.Errorf("loadConfigFile() error = %v", err)
+	}
+
+	profiles, err := configProfiles(path)
+	if err != nil || !reflect.DeepEqual(profiles, []string{"acme", "globex"}) {
+		t.Errorf("configProfiles() = %v, %v", profiles, err)
+	}
+
+	if _, err := configProfiles(writeConfigFile(t, "plain.yaml", "org_id: \"1\"\n")); !errors.Is(err, errNoProfiles) {
+		t.Errorf("expected errNoProfiles, got %v", err)
+	}
+}
+
+func TestGetConfig_Profile(t *testing.T) {
+	cleanEnvVars()
+
+	cfg, err := getConfig(writeConfigFile(t, "config.yaml", profilesConfig), "acme")
+	if err != nil {
+		t.Fatalf("getConfig() error = %v", err)
+	}
+
+	if cfg.OrgID != "111" || cfg.GitHubLabel != "acme" || cfg.OutputFormat != "json" || cfg.Profile != "acme" {
+		t.Errorf("unexpected configuration of the profile: %q, %q, %q, %q", cfg.OrgID, cfg.GitHubLabel,
+			cfg.OutputFormat, cfg.Profile)
+	}
+}
@@ -0,0 +1,80 @@
+package assetwatcher
+
+import (
+	"maps"
+	"slices"
+)
+
+const (
+	// defaultIdleAddressHourlyPrice is the list price in USD of a static external
+	// IPv4 address that is reserved but not attached to any resource.
+	// https://cloud.google.com/vpc/network-pricing#ipaddress
+	defaultIdleAddressHourlyPrice = 0.01
+
+	// hoursPerMonth is the average number of hours in a month used by Google Cloud billing.
+	hoursPerMonth = 730
+
+	addressStatusReserved = "RESERVED"
+	addressStatusInUse    = "IN_USE"
+	addressTypeInternal   = "INTERNAL"
+)
+
+// ProjectCost represents the estimated monthly cost of idle addresses in a project.
+type ProjectCost struct {
+	Project       string  `json:"project"`
+	IdleAddresses int     `json:"idleAddresses"`
+	MonthlyCost   float64 `json:"monthlyCost"`
+}
+
+// CostSummary represents the estimated monthly cost of idle addresses.
+type CostSummary struct {
+	Projects      []ProjectCost `json:"projects"`
+	IdleAddresses int           `json:"idleAddresses"`
+	MonthlyCost   float64       `json:"monthlyCost"`
+}
+
+// isIdleAddress reports whether the address is reserved without being used by any resource.
+// Internal addresses are free of charge and never considered idle for cost purposes.
+func isIdleAddress(asset ProcessedAsset) bool {
+	return asset.Status == addressStatusReserved && asset.AddressType != addressTypeInternal
+}
+
+// estimateMonthlyCost returns the estimated monthly cost of an address in USD.
+// Only idle addresses are taken into account, as the cost of in-use addresses
+// is usually attributed to the resources they are attached to.
+func estimateMonthlyCost(asset ProcessedAsset, hourlyPrice float64) float64 {
+	if !isIdleAddress(asset) {
+		return 0
+	}
+
+	return hourlyPrice * hoursPerMonth
+}
+
+// summarizeCosts aggregates the estimated costs of the assets per project.
+func summarizeCosts(assets []ProcessedAsset) CostSummary {
+	byProject := make(map[string]*ProjectCost)
+	summary := CostSummary{Projects: []ProjectCost{}}
+
+	for _, asset := range assets {
+		if asset.EstimatedMonthlyCost == 0 {
+			continue
+		}
+
+		projectCost, ok := byProject[asset.Project]
+		if !ok {
+			projectCost = &ProjectCost{Project: asset.Project}
+			byProject[asset.Project] = projectCost
+		}
+
+		projectCost.IdleAddresses++
+		projectCost.MonthlyCost += asset.EstimatedMonthlyCost
+		summary.IdleAddresses++
+		summary.MonthlyCost += asset.EstimatedMonthlyCost
+	}
+
+	for _, project := range slices.Sorted(maps.Keys(byProject)) {
+		summary.Projects = append(summary.Projects, *byProject[project])
+	}
+
+	return summary
+}
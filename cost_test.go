@@ -0,0 +1,75 @@
+package assetwatcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEstimateMonthlyCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		asset       ProcessedAsset
+		hourlyPrice float64
+		want        float64
+	}{
+		{name: "reserved external address", asset: ProcessedAsset{Status: "RESERVED", AddressType: "EXTERNAL"}, hourlyPrice: 0.01, want: 7.3},
+		{name: "reserved address without type", asset: ProcessedAsset{Status: "RESERVED"}, hourlyPrice: 0.01, want: 7.3},
+		{name: "reserved internal address", asset: ProcessedAsset{Status: "RESERVED", AddressType: "INTERNAL"}, hourlyPrice: 0.01, want: 0},
+		{name: "in use external address", asset: ProcessedAsset{Status: "IN_USE", AddressType: "EXTERNAL"}, hourlyPrice: 0.01, want: 0},
+		{name: "custom price", asset: ProcessedAsset{Status: "RESERVED"}, hourlyPrice: 0.02, want: 14.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateMonthlyCost(tt.asset, tt.hourlyPrice)
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("estimateMonthlyCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeCosts(t *testing.T) {
+	assets := []ProcessedAsset{
+		{Name: "a1", Project: "proj-B", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+		{Name: "a2", Project: "proj-A", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+		{Name: "a3", Project: "proj-B", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+		{Name: "a4", Project: "proj-C", Status: "IN_USE"},
+	}
+
+	want := CostSummary{
+		Projects: []ProjectCost{
+			{Project: "proj-A", IdleAddresses: 1, MonthlyCost: 7.3},
+			{Project: "proj-B", IdleAddresses: 2, MonthlyCost: 14.6},
+		},
+		IdleAddresses: 3,
+		MonthlyCost:   21.9,
+	}
+
+	got := summarizeCosts(assets)
+
+	if len(got.Projects) != len(want.Projects) {
+		t.Fatalf("summarizeCosts() returned %d projects, want %d", len(got.Projects), len(want.Projects))
+	}
+
+	for i := range want.Projects {
+		if got.Projects[i].Project != want.Projects[i].Project || got.Projects[i].IdleAddresses != want.Projects[i].IdleAddresses {
+			t.Errorf("project[%d] = %+v, want %+v", i, got.Projects[i], want.Projects[i])
+		}
+	}
+
+	if got.IdleAddresses != want.IdleAddresses {
+		t.Errorf("IdleAddresses = %d, want %d", got.IdleAddresses, want.IdleAddresses)
+	}
+
+	if diff := got.MonthlyCost - want.MonthlyCost; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("MonthlyCost = %v, want %v", got.MonthlyCost, want.MonthlyCost)
+	}
+}
+
+func TestSummarizeCosts_Empty(t *testing.T) {
+	want := CostSummary{Projects: []ProjectCost{}}
+	if got := summarizeCosts(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeCosts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// exitCodeCrash is the exit code of a run that panicked.
+const exitCodeCrash = 3
+
+// Stages of the pipeline recorded in crash reports.
+const (
+	stageStartup = "startup"
+	stageFetch   = "fetch"
+	stageEnrich  = "enrich"
+	stageOutput  = "output"
+	stagePublish = "publish"
+
+	stageAdvisories = "advisories"
+)
+
+// CrashReport describes a panic of a run, so that a crash of a scheduled job leaves a
+// forensic trail.
+type CrashReport struct {
+	RunID     string            `json:"runId"`
+	Time      time.Time         `json:"time"`
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	Stage     string            `json:"stage"`
+	Panic     string            `json:"panic"`
+	Stack     string            `json:"stack"`
+	LastAsset *AssetFingerprint `json:"lastAsset,omitempty"`
+	Config    map[string]any    `json:"config"`
+}
+
+// AssetFingerprint identifies the last asset read by a run, without including its data.
+type AssetFingerprint struct {
+	Name      string `json:"name"`
+	AssetType string `json:"assetType"`
+	SHA256    string `json:"sha256"`
+}
+
+// runProgress tracks the stage of a run and the last asset it read.
+type runProgress struct {
+	stage     atomic.Value
+	lastAsset atomic.Pointer[assetpb.ResourceSearchResult]
+}
+
+type progressContextKey struct{}
+
+// withRunProgress returns a context tracking the progress of the run.
+func withRunProgress(ctx context.Context) context.Context {
+	progress := &runProgress{}
+	progress.stage.Store(stageStartup)
+
+	return context.WithValue(ctx, progressContextKey{}, progress)
+}
+
+func progressFromContext(ctx context.Context) *runProgress {
+	progress, _ := ctx.Value(progressContextKey{}).(*runProgress)
+
+	return progress
+}
+
+// setStage records the stage of the run of the context.
+func setStage(ctx context.Context, stage string) {
+	if progress := progressFromContext(ctx); progress != nil {
+		progress.stage.Store(stage)
+	}
+}
+
+// stageFromContext returns the stage of the run of the context.
+func stageFromContext(ctx context.Context) string {
+	if progress := progressFromContext(ctx); progress != nil {
+		stage, _ := progress.stage.Load().(string)
+
+		return stage
+	}
+
+	return stageStartup
+}
+
+// recordAsset records the asset being processed by the run of the context. The fingerprint
+// is only computed if the run crashes.
+func recordAsset(ctx context.Context, asset *assetpb.ResourceSearchResult) {
+	if progress := progressFromContext(ctx); progress != nil {
+		progress.lastAsset.Store(asset)
+	}
+}
+
+// newCrashReport returns the crash report of the recovered panic of the run of the context.
+func newCrashReport(ctx context.Context, cfg *Config, recovered any, stack []byte) *CrashReport {
+	report := &CrashReport{
+		RunID:   runIDFromContext(ctx),
+		Time:    time.Now().UTC(),
+		Version: Version,
+		Commit:  Commit,
+		Panic:   fmt.Sprint(recovered),
+		Stack:   string(stack),
+		Config:  effectiveConfig(cfg),
+	}
+
+	progress := progressFromContext(ctx)
+	if progress == nil {
+		return report
+	}
+
+	report.Stage, _ = progress.stage.Load().(string)
+
+	if asset := progress.lastAsset.Load(); asset != nil {
+		fingerprint := &AssetFingerprint{Name: asset.GetName(), AssetType: asset.GetAssetType()}
+
+		if data, err := (proto.MarshalOptions{Deterministic: true}).Marshal(asset); err == nil {
+			sum := sha256.Sum256(data)
+			fingerprint.SHA256 = hex.EncodeToString(sum[:])
+		}
+
+		report.LastAsset = fingerprint
+	}
+
+	return report
+}
+
+// writeCrashReport writes the crash report as crash-RUN_ID.json to the directory or the
+// gs://BUCKET/PREFIX of ASSET_WATCHER_CRASH_REPORT_PATH, and returns its location.
+func writeCrashReport(ctx context.Context, logger *slog.Logger, cfg *Config, report *CrashReport) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode crash report: %w", err)
+	}
+
+	name := "crash-" + report.RunID + ".json"
+
+	if !strings.HasPrefix(cfg.CrashReportPath, gcsScheme) {
+		if err := os.MkdirAll(cfg.CrashReportPath, 0o750); err != nil {
+			return "", fmt.Errorf("failed to create crash report directory: %w", err)
+		}
+
+		location := filepath.Join(cfg.CrashReportPath, name)
+		if err := os.WriteFile(location, data, 0o600); err != nil {
+			return "", fmt.Errorf("failed to write crash report: %w", err)
+		}
+
+		return location, nil
+	}
+
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(cfg.CrashReportPath, gcsScheme), "/")
+	object := path.Join(prefix, name)
+
+	return gcsScheme + bucket + "/" + object, uploadJSON(ctx, bucket, object, data,
+		clientOptionsFor(ctx, logger, cfg, credentialsStorage)...)
+}
+
+// uploadJSON uploads the JSON document to the Cloud Storage object.
+func uploadJSON(ctx context.Context, bucket, object string, data []byte, opts ...option.ClientOption) error {
+	s, err := storage.NewService(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+
+	_, err = s.Objects.Insert(bucket, &storage.Object{Name: object, ContentType: "application/json"}).
+		Media(bytes.NewReader(data)).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to upload gs://%s/%s: %w", bucket, object, err)
+	}
+
+	return nil
+}
+
+// recoverCrash recovers a panic of the run, logs it along with the crash report, writes
+// the crash report if ASSET_WATCHER_CRASH_REPORT_PATH is set, and exits with exitCodeCrash.
+// It must be deferred by main.
+func recoverCrash(ctx context.Context, cfg *Config) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	// The exit of an embedded run unwinds to the run.
+	if exited, ok := recovered.(embeddedExit); ok {
+		panic(exited)
+	}
+
+	logger := newLogger(cfg, os.Stderr)
+	report := newCrashReport(ctx, cfg, recovered, debug.Stack())
+
+	logger.ErrorContext(ctx, "asset-watcher crashed",
+		slog.String("stage", report.Stage),
+		slog.String("panic", report.Panic),
+		slog.String("stack", report.Stack),
+		slog.Any("last_asset", report.LastAsset),
+	)
+
+	if cfg.CrashReportPath != "" {
+		location, err := writeCrashReport(ctx, logger, cfg, report)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to write the crash report", slog.Any("error", err))
+		} else {
+			logger.ErrorContext(ctx, "Wrote the crash report", slog.String("location", location))
+		}
+	}
+
+	exit(ctx, exitCodeCrash)
+}
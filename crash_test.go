@@ -0,0 +1,72 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+)
+
+func TestNewCrashReport(t *testing.T) {
+	ctx := withRunProgress(withRunID(t.Context(), "run1"))
+	setStage(ctx, stageFetch)
+	recordAsset(ctx, &assetpb.ResourceSearchResult{
+		Name:      "//compute.googleapis.com/projects/p1/regions/us-central1/addresses/a1",
+		AssetType: addressAssetType,
+	})
+
+	report := newCrashReport(ctx, &Config{OrgID: "123", SlackToken: "xoxb-secret"}, "boom", []byte("stack"))
+
+	if report.RunID != "run1" || report.Stage != stageFetch || report.Panic != "boom" || report.Stack != "stack" {
+		t.Errorf("unexpected crash report %+v", report)
+	}
+
+	if report.LastAsset == nil || !strings.HasSuffix(report.LastAsset.Name, "/addresses/a1") ||
+		len(report.LastAsset.SHA256) != 64 {
+		t.Errorf("unexpected last asset %+v", report.LastAsset)
+	}
+
+	if report.Config["ASSET_WATCHER_SLACK_TOKEN"] != redactedValue {
+		t.Errorf("expected the Slack token to be redacted, got %v", report.Config["ASSET_WATCHER_SLACK_TOKEN"])
+	}
+}
+
+func TestRecoverCrash(t *testing.T) {
+	dir := os.Getenv("CRASH_REPORT_TESTER_DIR")
+	if dir != "" {
+		ctx := withRunProgress(withRunID(t.Context(), "run1"))
+		defer recoverCrash(ctx, &Config{CrashReportPath: dir})
+
+		setStage(ctx, stageEnrich)
+		panic("malformed asset")
+	}
+
+	dir = t.TempDir()
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestRecoverCrash") //nolint:gosec // Reruns only the current test.
+	cmd.Env = append(os.Environ(), "CRASH_REPORT_TESTER_DIR="+dir)
+
+	exitErr := &exec.ExitError{}
+	if err := cmd.Run(); !errors.As(err, &exitErr) || exitErr.ExitCode() != exitCodeCrash {
+		t.Fatalf("expected exit code %d, got %v", exitCodeCrash, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "crash-run1.json"))
+	if err != nil {
+		t.Fatalf("failed to read the crash report: %v", err)
+	}
+
+	var report CrashReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to decode the crash report: %v", err)
+	}
+
+	if report.Stage != stageEnrich || report.Panic != "malformed asset" || !strings.Contains(report.Stack, "TestRecoverCrash") {
+		t.Errorf("unexpected crash report %+v", report)
+	}
+}
@@ -0,0 +1,176 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// Components that can use their own credentials.
+const (
+	credentialsAssets      = "assets"
+	credentialsRecommender = "recommender"
+	credentialsFlowLogs    = "flowlogs"
+	credentialsCompute     = "compute"
+	credentialsSCC         = "scc"
+	credentialsChronicle   = "chronicle"
+	credentialsBigQuery    = "bigquery"
+	credentialsTags        = "tags"
+	credentialsDNS         = "dns"
+	credentialsStorage     = "storage"
+	credentialsFirestore   = "firestore"
+	credentialsProjects    = "projects"
+	credentialsPubSub      = "pubsub"
+	credentialsAdvisories  = "advisories"
+
+	// credentialsRoute53 authenticates to AWS rather than Google Cloud, with a file of the AWS
+	// credential_process format.
+	credentialsRoute53 = "route53"
+)
+
+const (
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	impersonatePrefix  = "impersonate:"
+)
+
+var credentialComponents = []string{
+	credentialsAssets, credentialsRecommender, credentialsFlowLogs, credentialsCompute,
+	credentialsSCC, credentialsChronicle, credentialsTags, credentialsDNS, credentialsStorage,
+	credentialsFirestore, credentialsProjects, credentialsBigQuery, credentialsPubSub,
+	credentialsAdvisories,
+}
+
+// awsCredentialComponents are the components authenticating to AWS, whose source is always a file.
+var awsCredentialComponents = []string{credentialsRoute53}
+
+// Credential file types supported by the client libraries.
+var credentialFileTypes = map[string]option.CredentialsType{
+	"service_account":              option.ServiceAccount,
+	"authorized_user":              option.AuthorizedUser,
+	"impersonated_service_account": option.ImpersonatedServiceAccount,
+	"external_account":             option.ExternalAccount,
+}
+
+var (
+	errInvalidCredentials  = errors.New("invalid credentials")
+	errUnknownComponent    = errors.New("unknown component")
+	errUnsupportedCredType = errors.New("unsupported credentials type")
+)
+
+// credentialSource is either a credentials file or a service account to impersonate
+// with the Application Default Credentials.
+type credentialSource struct {
+	file           string
+	serviceAccount string
+}
+
+// parseCredentials parses a comma-separated list of component=source pairs, where the source
+// is a path to a credentials file or, for the Google Cloud components,
+// impersonate:SERVICE_ACCOUNT_EMAIL.
+func parseCredentials(s string) (map[string]credentialSource, error) {
+	sources := make(map[string]credentialSource)
+
+	for _, pair := range splitString(s, ",") {
+		component, source, ok := strings.Cut(pair, "=")
+		component = strings.TrimSpace(component)
+		source = strings.TrimSpace(source)
+
+		if !ok || component == "" || source == "" {
+			return nil, fmt.Errorf("%w: %q, expected component=source", errInvalidCredentials, pair)
+		}
+
+		isAWS := slices.Contains(awsCredentialComponents, component)
+		if !isAWS && !slices.Contains(credentialComponents, component) {
+			return nil, fmt.Errorf("%w: %q, expected one of %s", errUnknownComponent, component,
+				strings.Join(slices.Concat(credentialComponents, awsCredentialComponents), ", "))
+		}
+
+		if serviceAccount, ok := strings.CutPrefix(source, impersonatePrefix); ok {
+			if isAWS {
+				return nil, fmt.Errorf("%w: %s cannot impersonate a service account, expected an AWS credentials file",
+					errInvalidCredentials, component)
+			}
+
+			if !strings.Contains(serviceAccount, "@") {
+				return nil, fmt.Errorf("%w: %q, expected a service account email", errInvalidCredentials, serviceAccount)
+			}
+
+			sources[component] = credentialSource{serviceAccount: serviceAccount}
+
+			continue
+		}
+
+		sources[component] = credentialSource{file: source}
+	}
+
+	return sources, nil
+}
+
+// clientOptions returns the client options authenticating the component with its own
+// credentials. Components without configured credentials use the Application Default
+// Credentials, so no options are returned. The scopes are used for impersonation and
+// default to the cloud-platform scope.
+func clientOptions(ctx context.Context, cfg *Config, component string, scopes ...string) ([]option.ClientOption, error) {
+	sources, err := parseCredentials(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	source, ok := sources[component]
+	if !ok {
+		return nil, nil
+	}
+
+	if source.serviceAccount != "" {
+		if len(scopes) == 0 {
+			scopes = []string{cloudPlatformScope}
+		}
+
+		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: source.serviceAccount,
+			Scopes:          scopes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to impersonate %s for %s: %w", source.serviceAccount, component, err)
+		}
+
+		return []option.ClientOption{option.WithTokenSource(ts)}, nil
+	}
+
+	credType, err := readCredentialsType(source.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credentials for %s: %w", component, err)
+	}
+
+	return []option.ClientOption{option.WithAuthCredentialsFile(credType, source.file)}, nil
+}
+
+// readCredentialsType returns the type of the credentials file.
+func readCredentialsType(path string) (option.CredentialsType, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return "", fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var file struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(b, &file); err != nil {
+		return "", fmt.Errorf("failed to parse credentials file %s: %w", path, err)
+	}
+
+	credType, ok := credentialFileTypes[file.Type]
+	if !ok {
+		return "", fmt.Errorf("%w: %q in %s", errUnsupportedCredType, file.Type, path)
+	}
+
+	return credType, nil
+}
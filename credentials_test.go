@@ -0,0 +1,85 @@
+package assetwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestParseCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]credentialSource
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: map[string]credentialSource{}},
+		{
+			name:  "file and impersonation",
+			input: "assets=/keys/assets.json, scc=impersonate:scc@proj.iam.gserviceaccount.com",
+			want: map[string]credentialSource{
+				"assets": {file: "/keys/assets.json"},
+				"scc":    {serviceAccount: "scc@proj.iam.gserviceaccount.com"},
+			},
+		},
+		{
+			name:  "aws file",
+			input: "route53=/keys/aws.json",
+			want:  map[string]credentialSource{"route53": {file: "/keys/aws.json"}},
+		},
+		{name: "aws impersonation", input: "route53=impersonate:dns@proj.iam.gserviceaccount.com", wantErr: true},
+		{name: "unknown component", input: "everything=/keys/key.json", wantErr: true},
+		{name: "missing source", input: "assets=", wantErr: true},
+		{name: "invalid service account", input: "tags=impersonate:tags", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCredentials(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCredentials() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	keyFile := filepath.Join(dir, "key.json")
+	if err := os.WriteFile(keyFile, []byte(`{"type": "service_account"}`), 0o600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+
+	unsupportedFile := filepath.Join(dir, "unsupported.json")
+	if err := os.WriteFile(unsupportedFile, []byte(`{"type": "gdch_service_account"}`), 0o600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+
+	ctx := t.Context()
+	cfg := &Config{Credentials: "scc=" + keyFile + ",tags=" + unsupportedFile}
+
+	opts, err := clientOptions(ctx, cfg, credentialsSCC)
+	if err != nil {
+		t.Fatalf("clientOptions() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(opts, []option.ClientOption{option.WithAuthCredentialsFile(option.ServiceAccount, keyFile)}) {
+		t.Errorf("unexpected client options: %v", opts)
+	}
+
+	if opts, err := clientOptions(ctx, cfg, credentialsAssets); err != nil || opts != nil {
+		t.Errorf("expected no options for a component without credentials, got %v, %v", opts, err)
+	}
+
+	if _, err := clientOptions(ctx, cfg, credentialsTags); err == nil {
+		t.Error("expected an error for an unsupported credentials type")
+	}
+}
@@ -0,0 +1,157 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+var errInvalidSchedule = errors.New("invalid schedule")
+
+// daemonSchedule is the schedule of the scans of the daemon mode.
+type daemonSchedule struct {
+	// interval is the time between the starts of two scans.
+	interval time.Duration
+
+	// jitter is the maximum random delay added to every interval, so that the daemons of
+	// several organizations do not call the APIs at the same time.
+	jitter time.Duration
+
+	// timeout is the maximum duration of a scan.
+	timeout time.Duration
+}
+
+// parseDaemonSchedule parses ASSET_WATCHER_INTERVAL, ASSET_WATCHER_INTERVAL_JITTER, and
+// ASSET_WATCHER_RUN_TIMEOUT, durations such as 30m or 1d. The daemon mode is off without an
+// interval, and the timeout of a scan defaults to the interval, so scans never overlap.
+func parseDaemonSchedule(cfg *Config) (daemonSchedule, error) {
+	interval, err := parseAge(cfg.Interval)
+	if err != nil || (cfg.Interval != "" && interval == 0) {
+		return daemonSchedule{}, fmt.Errorf("%w: interval %q, expected a positive duration such as 1h",
+			errInvalidSchedule, cfg.Interval)
+	}
+
+	jitter, err := parseAge(cfg.IntervalJitter)
+	if err != nil {
+		return daemonSchedule{}, fmt.Errorf("%w: jitter %q, expected a duration such as 5m", errInvalidSchedule,
+			cfg.IntervalJitter)
+	}
+
+	timeout, err := parseAge(cfg.RunTimeout)
+	if err != nil || (cfg.RunTimeout != "" && timeout == 0) {
+		return daemonSchedule{}, fmt.Errorf("%w: run timeout %q, expected a positive duration such as 30m",
+			errInvalidSchedule, cfg.RunTimeout)
+	}
+
+	if interval == 0 && (jitter != 0 || timeout != 0) {
+		return daemonSchedule{}, fmt.Errorf("%w: the jitter and the run timeout require an interval", errInvalidSchedule)
+	}
+
+	if timeout == 0 {
+		timeout = interval
+	}
+
+	return daemonSchedule{interval: interval, jitter: jitter, timeout: timeout}, nil
+}
+
+// delay returns the time to wait before the next scan, which started at startedAt: the interval
+// plus a random jitter, less the time the scan took.
+func (s daemonSchedule) delay(startedAt, now time.Time) time.Duration {
+	delay := s.interval - now.Sub(startedAt)
+	if s.jitter > 0 {
+		delay += rand.N(s.jitter) //nolint:gosec // The jitter spreads the load, it is not a secret.
+	}
+
+	return max(delay, 0)
+}
+
+// runDaemon runs the scan of the configuration on the schedule of the configuration until
+// the context is canceled, by SIGINT or SIGTERM, so that asset-watcher can run as a
+// Kubernetes Deployment instead of a cron job. Every scan is a run of its own, with its run
+// ID, result file, and timeout; a failed scan is logged and the next one runs on schedule.
+// On cancellation, the running scan is canceled and the daemon returns.
+func runDaemon(ctx context.Context, logger *slog.Logger, cfg *Config) error {
+	schedule, err := parseDaemonSchedule(cfg)
+	if err != nil {
+		return err
+	}
+
+	// The scans write their own result file.
+	discardRunOutcome(ctx)
+
+	logger.InfoContext(ctx, "Running as a daemon",
+		slog.Duration("interval", schedule.interval),
+		slog.Duration("jitter", schedule.jitter),
+		slog.Duration("timeout", schedule.timeout),
+	)
+
+	daemonLoop(ctx, schedule, func(ctx context.Context) {
+		runDaemonScan(ctx, logger, cfg, schedule.timeout, func(ctx context.Context, startedAt time.Time) {
+			scanAndPublish(ctx, logger, cfg, startedAt)
+		})
+	})
+
+	logger.InfoContext(ctx, "Stopped the daemon", slog.String("reason", context.Cause(ctx).Error()))
+
+	return nil
+}
+
+// daemonLoop calls scan on the schedule until the context is done.
+func daemonLoop(ctx context.Context, schedule daemonSchedule, scan func(ctx context.Context)) {
+	for {
+		startedAt := time.Now()
+
+		scan(ctx)
+
+		timer := time.NewTimer(schedule.delay(startedAt, time.Now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// runDaemonScan runs a scheduled scan as an embedded run, whose failures, including crashes,
+// end the scan instead of the process, and returns its exit code.
+func runDaemonScan(ctx context.Context, logger *slog.Logger, cfg *Config, timeout time.Duration,
+	scan func(ctx context.Context, startedAt time.Time),
+) (code int) {
+	startedAt := time.Now()
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", errRunTimeout, timeout))
+	defer cancel()
+
+	ctx = withRunOutcome(withEmbeddedRun(withRunProgress(withRunID(ctx, newRunID()))), cfg, startedAt)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			exited, ok := recovered.(embeddedExit)
+			if !ok {
+				panic(recovered)
+			}
+
+			code = exited.code
+		}
+
+		writeRunResult(ctx, code)
+
+		logger.InfoContext(ctx, "Finished the scan",
+			slog.Int("exit_code", code),
+			slog.String("stage", stageFromContext(ctx)),
+			slog.Duration("duration", time.Since(startedAt)),
+		)
+	}()
+
+	defer recoverCrash(ctx, cfg)
+
+	scan(ctx, startedAt)
+
+	return 0
+}
@@ -0,0 +1,195 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestParseDaemonSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    daemonSchedule
+		wantErr bool
+	}{
+		{name: "off", cfg: Config{}},
+		{
+			name: "interval",
+			cfg:  Config{Interval: "1h"},
+			want: daemonSchedule{interval: time.Hour, timeout: time.Hour},
+		},
+		{
+			name: "jitter and timeout",
+			cfg:  Config{Interval: "1d", IntervalJitter: "5m", RunTimeout: "30m"},
+			want: daemonSchedule{interval: 24 * time.Hour, jitter: 5 * time.Minute, timeout: 30 * time.Minute},
+		},
+		{name: "zero interval", cfg: Config{Interval: "0s"}, wantErr: true},
+		{name: "invalid interval", cfg: Config{Interval: "hourly"}, wantErr: true},
+		{name: "invalid jitter", cfg: Config{Interval: "1h", IntervalJitter: "-5m"}, wantErr: true},
+		{name: "zero timeout", cfg: Config{Interval: "1h", RunTimeout: "0"}, wantErr: true},
+		{name: "jitter without interval", cfg: Config{IntervalJitter: "5m"}, wantErr: true},
+		{name: "timeout without interval", cfg: Config{RunTimeout: "5m"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDaemonSchedule(&tt.cfg)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidSchedule) {
+					t.Fatalf("parseDaemonSchedule() error = %v, want errInvalidSchedule", err)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("parseDaemonSchedule() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemonSchedule_Delay(t *testing.T) {
+	startedAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	schedule := daemonSchedule{interval: time.Hour}
+
+	if got := schedule.delay(startedAt, startedAt.Add(10*time.Minute)); got != 50*time.Minute {
+		t.Errorf("delay() = %v, want 50m", got)
+	}
+
+	if got := schedule.delay(startedAt, startedAt.Add(2*time.Hour)); got != 0 {
+		t.Errorf("delay() of an overrunning scan = %v, want 0", got)
+	}
+
+	schedule.jitter = 5 * time.Minute
+
+	for range 100 {
+		if got := schedule.delay(startedAt, startedAt); got < time.Hour || got >= time.Hour+schedule.jitter {
+			t.Fatalf("delay() with jitter = %v, want within [1h, 1h5m)", got)
+		}
+	}
+}
+
+func TestDaemonLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	scans := 0
+
+	daemonLoop(ctx, daemonSchedule{interval: time.Millisecond}, func(context.Context) {
+		if scans++; scans == 3 {
+			cancel()
+		}
+	})
+
+	if scans != 3 {
+		t.Errorf("daemonLoop() ran %d scans, want 3 before the cancellation", scans)
+	}
+}
+
+func TestRunDaemonScan(t *testing.T) {
+	ctx := withFakeAssetServer(t,
+		selftestAddress("prod", "web", addressStatusInUse, "203.0.113.10"),
+		selftestAddress("prod", "spare", addressStatusReserved, "203.0.113.11"),
+	)
+
+	dir := t.TempDir()
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.OutputPath = filepath.Join(dir, "report.json")
+	cfg.OutputFormat = outputFormatJSON
+	cfg.ResultPath = filepath.Join(dir, "result.json")
+	cfg.SkipNotifierChecks = true
+
+	logger := slog.New(slog.DiscardHandler)
+	scan := func(ctx context.Context, startedAt time.Time) { scanAndPublish(ctx, logger, &cfg, startedAt) }
+
+	if code := runDaemonScan(ctx, logger, &cfg, time.Minute, scan); code != 0 {
+		t.Fatalf("runDaemonScan() = %d, want 0", code)
+	}
+
+	result := readRunResult(t, cfg.ResultPath)
+	if result.Status != resultStatusSuccess || result.Counts == nil || result.Counts.Assets != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// A failed scan ends with its exit code instead of exiting the process.
+	cfg.FailOnViolation = true
+
+	if code := runDaemonScan(ctx, logger, &cfg, time.Minute, scan); code != exitCodeViolations {
+		t.Fatalf("runDaemonScan() = %d, want %d", code, exitCodeViolations)
+	}
+
+	if second := readRunResult(t, cfg.ResultPath); second.Status != resultStatusViolations ||
+		second.RunID == result.RunID {
+		t.Errorf("unexpected result %+v of the second scan", second)
+	}
+}
+
+// withFakeAssetServer serves the assets from a fake Cloud Asset API and returns a context
+// whose scans fetch them from it.
+func withFakeAssetServer(t *testing.T, assets ...*assetpb.ResourceSearchResult) context.Context {
+	t.Helper()
+
+	assetServer := &selftestAssetServer{assets: assets}
+
+	listener, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	grpcServer := grpc.NewServer()
+	assetpb.RegisterAssetServiceServer(grpcServer, assetServer)
+
+	go func() { _ = grpcServer.Serve(listener) }()
+
+	t.Cleanup(grpcServer.Stop)
+
+	return withSelftestServices(t.Context(), &selftestServices{
+		clientOptions: []option.ClientOption{
+			option.WithEndpoint(listener.Addr().String()),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		},
+	})
+}
+
+func readRunResult(t *testing.T, path string) RunResult {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the result: %v", err)
+	}
+
+	var result RunResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to decode the result: %v", err)
+	}
+
+	return result
+}
+
+func TestWriteRunResult_Discarded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	ctx := withRunOutcome(t.Context(), &Config{ResultPath: path}, time.Now())
+
+	discardRunOutcome(ctx)
+	writeRunResult(ctx, 0)
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no result file for a discarded outcome, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+)
+
+const (
+	pubSubScheme = "pubsub://"
+
+	defaultNotifyRetries      = 3
+	defaultNotifyRetryBackoff = "2s"
+)
+
+var errInvalidDeadLetter = errors.New("invalid dead-letter queue, expected a file or pubsub://PROJECT/TOPIC")
+
+// DeadLetter is a notification that could not be delivered after all retries.
+type DeadLetter struct {
+	Time        time.Time `json:"time"`
+	RunID       string    `json:"runId"`
+	Notifier    string    `json:"notifier"`
+	Attempts    int       `json:"attempts"`
+	Error       string    `json:"error"`
+	Title       string    `json:"title"`
+	Summary     string    `json:"summary"`
+	Items       []string  `json:"items"`
+	ArtifactURL string    `json:"artifactUrl,omitempty"`
+}
+
+// DeadLetterQueue is an interface for keeping the undelivered notifications, so that the
+// findings of a notifier outage are not lost.
+type DeadLetterQueue interface {
+	Put(ctx context.Context, letter DeadLetter) error
+}
+
+// newDeadLetter returns the dead letter of the notification undelivered by the notifier.
+func newDeadLetter(notifier string, notification Notification, attempts int, err error) DeadLetter {
+	return DeadLetter{
+		Time:        time.Now().UTC(),
+		RunID:       notification.RunID,
+		Notifier:    notifier,
+		Attempts:    attempts,
+		Error:       err.Error(),
+		Title:       notification.Title,
+		Summary:     notification.Summary,
+		Items:       notification.Items,
+		ArtifactURL: notification.ArtifactURL,
+	}
+}
+
+// FileDeadLetterQueue appends the dead letters to a JSON lines file.
+type FileDeadLetterQueue struct {
+	path string
+}
+
+// NewFileDeadLetterQueue creates a new dead-letter queue appending to the file.
+func NewFileDeadLetterQueue(path string) *FileDeadLetterQueue {
+	return &FileDeadLetterQueue{path: path}
+}
+
+// Put appends the dead letter to the file, creating its directory if needed.
+func (q *FileDeadLetterQueue) Put(_ context.Context, letter DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+
+	f, err := os.OpenFile(q.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// PubSubDeadLetterQueue publishes the dead letters to a Pub/Sub topic, with the notifier and
+// the run ID as attributes of the messages.
+type PubSubDeadLetterQueue struct {
+	topics *pubsub.ProjectsTopicsService
+	topic  string
+}
+
+// parsePubSubURL converts a pubsub://PROJECT/TOPIC URL into the name of the topic.
+func parsePubSubURL(s string) (string, error) {
+	project, topic, _ := strings.Cut(strings.TrimPrefix(s, pubSubScheme), "/")
+	if project == "" || topic == "" || strings.Contains(topic, "/") {
+		return "", fmt.Errorf("%w: %q", errInvalidDeadLetter, s)
+	}
+
+	return "projects/" + project + "/topics/" + topic, nil
+}
+
+// NewPubSubDeadLetterQueue creates a new dead-letter queue of the pubsub:// URL.
+func NewPubSubDeadLetterQueue(ctx context.Context, url string, opts ...option.ClientOption) (*PubSubDeadLetterQueue, error) {
+	topic, err := parsePubSubURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+
+	return &PubSubDeadLetterQueue{topics: s.Projects.Topics, topic: topic}, nil
+}
+
+// Put publishes the dead letter as a JSON message.
+func (q *PubSubDeadLetterQueue) Put(ctx context.Context, letter DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	_, err = q.topics.Publish(q.topic, &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{{
+			Data:       base64.StdEncoding.EncodeToString(data),
+			Attributes: map[string]string{"notifier": letter.Notifier, "runId": letter.RunID},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+
+	return nil
+}
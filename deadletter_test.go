@@ -0,0 +1,65 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePubSubURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "pubsub://my-project/dead-letters", want: "projects/my-project/topics/dead-letters"},
+		{url: "pubsub://my-project", wantErr: true},
+		{url: "pubsub:///dead-letters", wantErr: true},
+		{url: "pubsub://my-project/topics/dead-letters", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := parsePubSubURL(tt.url)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidDeadLetter) {
+					t.Errorf("expected errInvalidDeadLetter, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("parsePubSubURL() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileDeadLetterQueue_Put(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dead-letters.jsonl")
+	queue := NewFileDeadLetterQueue(path)
+
+	for _, notifier := range []string{"slack", "teams"} {
+		if err := queue.Put(t.Context(), DeadLetter{Notifier: notifier}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the dead letters: %v", err)
+	}
+	defer f.Close()
+
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		lines++
+	}
+
+	if lines != 2 {
+		t.Errorf("expected 2 dead letters, got %d", lines)
+	}
+}
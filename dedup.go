@@ -0,0 +1,129 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// stateKeyNotifiedFindings is the key of the findings already notified, in the state store.
+const stateKeyNotifiedFindings = "notified-findings"
+
+// notifyDedupSink suppresses the policy violations already notified within the TTL, keyed by
+// rule and asset, so that a known finding is notified once per TTL rather than every run until
+// it is fixed. Changes are notified every time.
+type notifyDedupSink struct {
+	sinks  []Sink
+	state  StateStore
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// newNotifyDedupSink creates a sink publishing the findings not notified within the TTL to the sinks.
+func newNotifyDedupSink(logger *slog.Logger, state StateStore, ttl time.Duration, sinks []Sink) *notifyDedupSink {
+	return &notifyDedupSink{
+		sinks:  sinks,
+		state:  state,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Name returns the name of the sink.
+func (s *notifyDedupSink) Name() string {
+	return "notify-dedup"
+}
+
+// Publish publishes the report without the violations notified within the TTL. The
+// violations are only recorded as notified if all sinks succeed, so that a failed
+// notification is retried by the next run.
+func (s *notifyDedupSink) Publish(ctx context.Context, report *Report) error {
+	notified, err := loadNotifiedFindings(ctx, s.state)
+	if err != nil {
+		return err
+	}
+
+	now := report.Metadata.StartedAt
+	maps.DeleteFunc(notified, func(_ string, at time.Time) bool {
+		return now.Sub(at) >= s.ttl
+	})
+
+	fresh := *report
+	fresh.Violations = slices.DeleteFunc(slices.Clone(report.Violations), func(v RuleViolation) bool {
+		_, ok := notified[notifiedFindingKey(v)]
+
+		return ok
+	})
+
+	if suppressed := len(report.Violations) - len(fresh.Violations); suppressed > 0 {
+		s.logger.InfoContext(ctx, "Suppressed policy violations already notified",
+			slog.Int("suppressed", suppressed),
+			slog.Duration("ttl", s.ttl),
+		)
+	}
+
+	errs := []error{}
+
+	for _, sink := range s.sinks {
+		if err := sink.Publish(ctx, &fresh); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, v := range fresh.Violations {
+		notified[notifiedFindingKey(v)] = now
+	}
+
+	return saveNotifiedFindings(ctx, s.state, notified)
+}
+
+// Close closes the notifier sinks.
+func (s *notifyDedupSink) Close() error {
+	errs := []error{}
+
+	for _, sink := range s.sinks {
+		errs = append(errs, sink.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifiedFindingKey returns the key of the violation, its rule and asset.
+func notifiedFindingKey(v RuleViolation) string {
+	return v.Rule + "/" + assetKey(v.Asset)
+}
+
+// loadNotifiedFindings reads the time every finding was last notified from the state store.
+func loadNotifiedFindings(ctx context.Context, state StateStore) (map[string]time.Time, error) {
+	notified := map[string]time.Time{}
+
+	value, err := state.Get(ctx, stateKeyNotifiedFindings)
+	if err != nil || value == nil {
+		return notified, err
+	}
+
+	if err := json.Unmarshal(value, &notified); err != nil {
+		return nil, fmt.Errorf("failed to decode notified findings: %w", err)
+	}
+
+	return notified, nil
+}
+
+// saveNotifiedFindings writes the notified findings to the state store.
+func saveNotifiedFindings(ctx context.Context, state StateStore, notified map[string]time.Time) error {
+	value, err := json.Marshal(notified)
+	if err != nil {
+		return fmt.Errorf("failed to encode notified findings: %w", err)
+	}
+
+	return state.Put(ctx, stateKeyNotifiedFindings, value)
+}
@@ -0,0 +1,87 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var errSinkUnavailable = errors.New("sink unavailable")
+
+// failingSink is a sink failing to publish.
+type failingSink struct{}
+
+// Name returns the name of the sink.
+func (failingSink) Name() string {
+	return "failing"
+}
+
+// Publish always fails.
+func (failingSink) Publish(_ context.Context, _ *Report) error {
+	return errSinkUnavailable
+}
+
+// Close is a no-op.
+func (failingSink) Close() error {
+	return nil
+}
+
+func TestNotifyDedupSink_Publish(t *testing.T) {
+	ctx := t.Context()
+	notifier := &fakeNotifier{}
+	state := &memoryStateStore{values: map[string][]byte{}}
+	sink := newNotifyDedupSink(slog.New(slog.DiscardHandler), state, 24*time.Hour, []Sink{notifierSink{notifier: notifier}})
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	unused := RuleViolation{Rule: "unused-address", Message: "unused", Asset: ProcessedAsset{ResourceName: "//compute/a1"}}
+	exposed := RuleViolation{Rule: "exposed", Message: "exposed", Asset: ProcessedAsset{ResourceName: "//compute/a1"}}
+
+	for i, tc := range []struct {
+		at         time.Duration
+		violations []RuleViolation
+		wantItems  int
+	}{
+		{at: 0, violations: []RuleViolation{unused}, wantItems: 1},
+		{at: time.Hour, violations: []RuleViolation{unused}, wantItems: 0},
+		{at: 2 * time.Hour, violations: []RuleViolation{unused, exposed}, wantItems: 1},
+		{at: 24 * time.Hour, violations: []RuleViolation{unused, exposed}, wantItems: 1},
+	} {
+		notifier.notifications = nil
+		report := &Report{Metadata: RunMetadata{StartedAt: start.Add(tc.at)}, Violations: tc.violations}
+
+		if err := sink.Publish(ctx, report); err != nil {
+			t.Fatalf("run %d: Publish failed: %v", i, err)
+		}
+
+		got := 0
+		if len(notifier.notifications) > 0 {
+			got = len(notifier.notifications[0].Items)
+		}
+
+		if got != tc.wantItems {
+			t.Errorf("run %d: expected %d notified items, got %d", i, tc.wantItems, got)
+		}
+	}
+}
+
+func TestNotifyDedupSink_PublishFailed(t *testing.T) {
+	ctx := t.Context()
+	state := &memoryStateStore{values: map[string][]byte{}}
+	sink := newNotifyDedupSink(slog.New(slog.DiscardHandler), state, time.Hour, []Sink{failingSink{}})
+
+	report := &Report{Violations: []RuleViolation{{Rule: "r", Asset: ProcessedAsset{ResourceName: "//compute/a1"}}}}
+	if err := sink.Publish(ctx, report); !errors.Is(err, errSinkUnavailable) {
+		t.Fatalf("expected errSinkUnavailable, got %v", err)
+	}
+
+	notified, err := loadNotifiedFindings(ctx, state)
+	if err != nil {
+		t.Fatalf("loadNotifiedFindings failed: %v", err)
+	}
+
+	if len(notified) != 0 {
+		t.Errorf("expected the failed notification not to be recorded, got %v", notified)
+	}
+}
@@ -0,0 +1,178 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/time/rate"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// defaultDescribeRate is the default number of compute.addresses.get requests per second.
+const defaultDescribeRate = 5
+
+// Attributes that are filled by the describe fallback when Cloud Asset Inventory omits them.
+var describedAttributes = []string{"purpose", "users"}
+
+var errInvalidAddressResourceName = errors.New("invalid address resource name")
+
+// AddressDetails contains the address fields fetched from the Compute Engine API.
+type AddressDetails struct {
+	Purpose string
+	Users   []string
+}
+
+// AddressDescriber is an interface for fetching an address directly from the Compute Engine API.
+type AddressDescriber interface {
+	DescribeAddress(ctx context.Context, resourceName string) (*AddressDetails, error)
+}
+
+// GoogleAddressDescriber is a Compute Engine API client.
+type GoogleAddressDescriber struct {
+	service *compute.Service
+	logger  *slog.Logger
+}
+
+// NewGoogleAddressDescriber creates a new Compute Engine API address describer.
+func NewGoogleAddressDescriber(
+	ctx context.Context,
+	logger *slog.Logger,
+	opts ...option.ClientOption,
+) (*GoogleAddressDescriber, error) {
+	s, err := compute.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client: %w", err)
+	}
+
+	return &GoogleAddressDescriber{
+		service: s,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// DescribeAddress fetches a regional or global address by its full resource name.
+func (d *GoogleAddressDescriber) DescribeAddress(ctx context.Context, resourceName string) (*AddressDetails, error) {
+	address, err := d.GetAddress(ctx, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddressDetails{Purpose: address.Purpose, Users: address.Users}, nil
+}
+
+// GetAddress fetches all the fields of a regional or global address by its full resource name.
+func (d *GoogleAddressDescriber) GetAddress(ctx context.Context, resourceName string) (*compute.Address, error) {
+	project, region, name, err := parseAddressResourceName(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var address *compute.Address
+	if region == "" {
+		address, err = d.service.GlobalAddresses.Get(project, name).Context(ctx).Do()
+	} else {
+		address, err = d.service.Addresses.Get(project, region, name).Context(ctx).Do()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address %s: %w", resourceName, err)
+	}
+
+	return address, nil
+}
+
+// AddressQuota returns the quota of static external addresses of a region of a project.
+func (d *GoogleAddressDescriber) AddressQuota(ctx context.Context, project, region string) (int, error) {
+	r, err := d.service.Regions.Get(project, region).Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get region %s of project %s: %w", region, project, err)
+	}
+
+	for _, quota := range r.Quotas {
+		if quota.Metric == staticAddressesQuota {
+			return int(quota.Limit), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s in region %s of project %s", errQuotaNotFound, staticAddressesQuota, region, project)
+}
+
+// parseAddressResourceName parses the full resource name of an address, such as
+// //compute.googleapis.com/projects/PROJECT/regions/REGION/addresses/NAME or
+// //compute.googleapis.com/projects/PROJECT/global/addresses/NAME. The region of
+// global addresses is empty.
+func parseAddressResourceName(resourceName string) (project, region, name string, err error) {
+	parts := strings.Split(strings.TrimPrefix(resourceName, "//compute.googleapis.com/"), "/")
+
+	switch {
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "regions" && parts[4] == "addresses":
+		return parts[1], parts[3], parts[5], nil
+	case len(parts) == 5 && parts[0] == "projects" && parts[2] == "global" && parts[3] == "addresses":
+		return parts[1], "", parts[4], nil
+	default:
+		return "", "", "", fmt.Errorf("%w: %q", errInvalidAddressResourceName, resourceName)
+	}
+}
+
+// missingAttributes reports whether Cloud Asset Inventory omitted any of the described attributes.
+func missingAttributes(asset ProcessedAsset) bool {
+	for _, key := range describedAttributes {
+		if _, ok := asset.Attributes[key]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describeMissingAttributes fetches addresses lacking attributes directly from the
+// Compute Engine API, waiting for the limiter before each request. Failures are
+// logged and leave the attributes of the asset unchanged.
+func describeMissingAttributes(
+	ctx context.Context,
+	logger *slog.Logger,
+	describer AddressDescriber,
+	limiter *rate.Limiter,
+	assets []ProcessedAsset,
+) []ProcessedAsset {
+	described := 0
+
+	for i, asset := range assets {
+		if asset.AssetType != addressAssetType || !missingAttributes(asset) {
+			continue
+		}
+
+		if err := limiter.Wait(ctx); err != nil {
+			logger.WarnContext(ctx, "stopped describing addresses", slog.Any("error", err))
+
+			break
+		}
+
+		details, err := describer.DescribeAddress(ctx, asset.ResourceName)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to describe address",
+				slog.String("name", asset.Name),
+				slog.Any("error", err),
+			)
+
+			continue
+		}
+
+		if assets[i].Attributes == nil {
+			assets[i].Attributes = make(map[string]string)
+		}
+
+		assets[i].Attributes["purpose"] = details.Purpose
+		assets[i].Attributes["users"] = joinLastPathSegments(details.Users)
+		assets[i].Attachments = attachmentKinds(details.Users)
+		described++
+	}
+
+	logger.DebugContext(ctx, "Described addresses with missing attributes", slog.Int("number_of_addresses", described))
+
+	return assets
+}
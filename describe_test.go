@@ -0,0 +1,129 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/api/option"
+)
+
+var errAddressNotFound = errors.New("address not found")
+
+// fakeAddressDescriber is a mock implementation of the AddressDescriber.
+type fakeAddressDescriber struct {
+	addresses map[string]*AddressDetails
+	calls     []string
+}
+
+// DescribeAddress returns the details stored for the resource name.
+func (f *fakeAddressDescriber) DescribeAddress(_ context.Context, resourceName string) (*AddressDetails, error) {
+	f.calls = append(f.calls, resourceName)
+
+	details, ok := f.addresses[resourceName]
+	if !ok {
+		return nil, errAddressNotFound
+	}
+
+	return details, nil
+}
+
+func TestParseAddressResourceName(t *testing.T) {
+	tests := []struct {
+		resourceName          string
+		project, region, name string
+		wantErr               bool
+	}{
+		{
+			resourceName: "//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/a1",
+			project:      "proj-A", region: "us-central1", name: "a1",
+		},
+		{
+			resourceName: "//compute.googleapis.com/projects/proj-A/global/addresses/g1",
+			project:      "proj-A", region: "", name: "g1",
+		},
+		{resourceName: "//compute.googleapis.com/projects/proj-A/zones/us-central1-a/instances/vm", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceName, func(t *testing.T) {
+			project, region, name, err := parseAddressResourceName(tt.resourceName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAddressResourceName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if project != tt.project || region != tt.region || name != tt.name {
+				t.Errorf("parseAddressResourceName() = %q, %q, %q", project, region, name)
+			}
+		})
+	}
+}
+
+func TestDescribeMissingAttributes(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	describer := &fakeAddressDescriber{addresses: map[string]*AddressDetails{
+		"missing": {Purpose: "NAT_AUTO", Users: []string{"https://www.googleapis.com/compute/v1/projects/p/regions/r/routers/nat"}},
+	}}
+
+	assets := []ProcessedAsset{
+		{Name: "complete", AssetType: addressAssetType, ResourceName: "complete", Attributes: map[string]string{"purpose": "", "users": ""}},
+		{Name: "missing", AssetType: addressAssetType, ResourceName: "missing", Attributes: map[string]string{"networkTier": "PREMIUM"}},
+		{Name: "failing", AssetType: addressAssetType, ResourceName: "failing"},
+		{Name: "instance", AssetType: instanceAssetType, ResourceName: "instance"},
+	}
+
+	got := describeMissingAttributes(ctx, logger, describer, rate.NewLimiter(rate.Inf, 1), assets)
+
+	if len(describer.calls) != 2 {
+		t.Errorf("expected 2 describe calls, got %v", describer.calls)
+	}
+
+	if got[1].Attributes["purpose"] != "NAT_AUTO" || got[1].Attributes["users"] != "nat" || got[1].Attributes["networkTier"] != "PREMIUM" {
+		t.Errorf("unexpected attributes of the described address: %v", got[1].Attributes)
+	}
+
+	if _, ok := got[2].Attributes["purpose"]; ok {
+		t.Errorf("expected attributes of a failed address to be unchanged, got %v", got[2].Attributes)
+	}
+}
+
+func TestGoogleAddressDescriber_DescribeAddress(t *testing.T) {
+	var requestedPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "a1", "purpose": "GCE_ENDPOINT", "users": ["projects/p/zones/z/instances/vm-1"]}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	describer, err := NewGoogleAddressDescriber(ctx, slog.New(slog.DiscardHandler),
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewGoogleAddressDescriber failed: %v", err)
+	}
+
+	details, err := describer.DescribeAddress(ctx, "//compute.googleapis.com/projects/p/regions/us-central1/addresses/a1")
+	if err != nil {
+		t.Fatalf("DescribeAddress failed: %v", err)
+	}
+
+	if requestedPath != "/projects/p/regions/us-central1/addresses/a1" {
+		t.Errorf("unexpected request path %s", requestedPath)
+	}
+
+	if details.Purpose != "GCE_ENDPOINT" || len(details.Users) != 1 {
+		t.Errorf("unexpected details: %+v", details)
+	}
+}
@@ -0,0 +1,242 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// diffCommand compares two snapshots.
+	diffCommand = "diff"
+
+	// diffArguments are the old and the new snapshot.
+	diffArguments = 2
+)
+
+var (
+	errDiffArguments     = errors.New("expected the old and the new snapshot")
+	errSnapshotNotFound  = errors.New("snapshot not found")
+	errUnknownDiffFormat = errors.New("unknown diff format")
+)
+
+// SnapshotDiff represents the changes between two snapshots.
+type SnapshotDiff struct {
+	Old   SnapshotInfo `json:"old"`
+	New   SnapshotInfo `json:"new"`
+	Diffs []AssetDiff  `json:"diffs"`
+}
+
+// SnapshotInfo describes a compared snapshot.
+type SnapshotInfo struct {
+	Source  string    `json:"source"`
+	RunID   string    `json:"runId,omitempty"`
+	TakenAt time.Time `json:"takenAt,omitzero"`
+	Assets  int       `json:"assets"`
+}
+
+// runDiffCommand compares two snapshots and writes the changes as a table or JSON. A snapshot is
+// either a file or gs:// object written by ASSET_WATCHER_SNAPSHOT_PATH, a JSON report, or a date
+// or time resolved from the history like --as-of, to answer questions like "what changed last quarter?".
+func runDiffCommand(ctx context.Context, logger *slog.Logger, cfg *Config, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(diffCommand, flag.ContinueOnError)
+	// The usage and the parse errors are kept out of the diff written to w.
+	flags.SetOutput(os.Stderr)
+	format := flags.String("format", outputFormatTable, "output format, table or json")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if flags.NArg() != diffArguments {
+		return errDiffArguments
+	}
+
+	*format = strings.ToLower(*format)
+	if *format != outputFormatTable && *format != outputFormatJSON {
+		return fmt.Errorf("%w: %s, expected table or json", errUnknownDiffFormat, strconv.Quote(*format))
+	}
+
+	old, err := loadSnapshotSource(ctx, logger, cfg, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	current, err := loadSnapshotSource(ctx, logger, cfg, flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	diff := SnapshotDiff{
+		Old:   SnapshotInfo{Source: flags.Arg(0), RunID: old.RunID, TakenAt: old.TakenAt, Assets: len(old.Assets)},
+		New:   SnapshotInfo{Source: flags.Arg(1), RunID: current.RunID, TakenAt: current.TakenAt, Assets: len(current.Assets)},
+		Diffs: diffAssets(old.Assets, current.Assets),
+	}
+
+	if *format == outputFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(diff); err != nil {
+			return fmt.Errorf("failed to encode diff: %w", err)
+		}
+
+		return nil
+	}
+
+	return writeDiffTable(w, diff.Diffs)
+}
+
+// loadSnapshotSource loads the snapshot of a diff argument.
+func loadSnapshotSource(ctx context.Context, logger *slog.Logger, cfg *Config, source string) (*Snapshot, error) {
+	if t, err := parseAsOf(source); err == nil {
+		if cfg.HistoryDir == "" {
+			return nil, errNoHistory
+		}
+
+		report, err := NewFileRunStore(cfg.HistoryDir).AsOf(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+
+		return newSnapshot(report), nil
+	}
+
+	var store SnapshotStore = NewFileSnapshotStore(source)
+
+	if strings.HasPrefix(source, gcsScheme) {
+		gcsStore, err := NewGCSSnapshotStore(ctx, source, clientOptionsFor(ctx, logger, cfg, credentialsStorage)...)
+		if err != nil {
+			return nil, err
+		}
+
+		store = gcsStore
+	}
+
+	snapshot, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot == nil {
+		return nil, fmt.Errorf("%w: %s", errSnapshotNotFound, source)
+	}
+
+	return snapshot, nil
+}
+
+func writeDiffTable(w io.Writer, diffs []AssetDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw, "Change\tDisplay Name\tIP Address\tProject ID\tChanges")
+	_, _ = fmt.Fprintln(tw, "------\t------------\t----------\t----------\t-------")
+
+	for _, diff := range diffs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", diff.Type, diff.Asset.Name, diff.Asset.IPAddress,
+			diff.Asset.Project, strings.Join(diff.Changes, "; "))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return nil
+}
+
+// diffAssets compares the assets of the previous and the current run. Assets are matched by
+// assetKey; added and changed assets are listed in the order of the current run, followed by
+// the removed assets in the order of the previous run.
+func diffAssets(previous, current []ProcessedAsset) []AssetDiff {
+	byKey := make(map[string]ProcessedAsset, len(previous))
+	for _, asset := range previous {
+		byKey[assetKey(asset)] = asset
+	}
+
+	diffs := []AssetDiff{}
+	found := make(map[string]bool, len(current))
+
+	for _, asset := range current {
+		key := assetKey(asset)
+		found[key] = true
+
+		before, ok := byKey[key]
+		if !ok {
+			diffs = append(diffs, AssetDiff{Type: DiffAdded, Asset: asset})
+
+			continue
+		}
+
+		if changes := assetChanges(before, asset); len(changes) > 0 {
+			diffs = append(diffs, AssetDiff{Type: DiffChanged, Asset: asset, Previous: &before, Changes: changes})
+		}
+	}
+
+	for _, asset := range previous {
+		if !found[assetKey(asset)] {
+			diffs = append(diffs, AssetDiff{Type: DiffRemoved, Asset: asset})
+		}
+	}
+
+	return diffs
+}
+
+// assetChanges describes the changes of the inventory attributes of an asset, such as
+// "status: RESERVED -> IN_USE". Enrichments that vary from run to run, such as costs and
+// traffic, are not compared.
+func assetChanges(before, after ProcessedAsset) []string {
+	changes := []string{}
+
+	compare := func(field, a, b string) {
+		if a != b {
+			changes = append(changes, field+": "+orNone(a)+" -> "+orNone(b))
+		}
+	}
+
+	compare("name", before.Name, after.Name)
+	compare("status", before.Status, after.Status)
+	compare("ipAddress", before.IPAddress, after.IPAddress)
+	compare("location", before.Location, after.Location)
+	compare("project", before.Project, after.Project)
+	compare("addressType", before.AddressType, after.AddressType)
+
+	for _, key := range unionKeys(before.Labels, after.Labels) {
+		compare("label "+key, before.Labels[key], after.Labels[key])
+	}
+
+	for _, key := range unionKeys(before.Attributes, after.Attributes) {
+		compare("attribute "+key, before.Attributes[key], after.Attributes[key])
+	}
+
+	return changes
+}
+
+func unionKeys(a, b map[string]string) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return noLabelGroup
+	}
+
+	return s
+}
@@ -0,0 +1,126 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffAssets(t *testing.T) {
+	kept := ProcessedAsset{Name: "kept", ResourceName: "//r/kept", Status: "IN_USE", IPAddress: "203.0.113.1"}
+	changed := ProcessedAsset{
+		Name: "changed", ResourceName: "//r/changed", Status: "RESERVED", IPAddress: "203.0.113.2",
+		Labels: map[string]string{"env": "dev"}, Attributes: map[string]string{"users": ""},
+	}
+	removed := ProcessedAsset{Name: "removed", ResourceName: "//r/removed", Status: "RESERVED"}
+	added := ProcessedAsset{Name: "added", ResourceName: "//r/added", Status: "IN_USE"}
+
+	after := changed
+	after.Status = "IN_USE"
+	after.Labels = map[string]string{"env": "prod", "team": "net"}
+	after.Attributes = map[string]string{"users": "vm-1"}
+	after.EstimatedMonthlyCost = 7.3
+
+	// Enrichments, such as the cost, are not compared.
+	keptAfter := kept
+	keptAfter.TrafficBytes = 100
+
+	got := diffAssets([]ProcessedAsset{kept, changed, removed}, []ProcessedAsset{keptAfter, after, added})
+
+	want := []AssetDiff{
+		{
+			Type: DiffChanged, Asset: after, Previous: &changed,
+			Changes: []string{"status: RESERVED -> IN_USE", "label env: dev -> prod", "label team: (none) -> net", "attribute users: (none) -> vm-1"},
+		},
+		{Type: DiffAdded, Asset: added},
+		{Type: DiffRemoved, Asset: removed},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffAssets() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunDiffCommand(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	// The diff is a table whatever the output format of the scans.
+	cfg := &Config{HistoryDir: filepath.Join(dir, "history"), OutputFormat: outputFormatXLSX}
+
+	snapshotPath := filepath.Join(dir, "snapshot.json")
+	if err := NewFileSnapshotStore(snapshotPath).Save(ctx, &Snapshot{
+		RunID:  "snap",
+		Assets: []ProcessedAsset{{Name: "a1", ResourceName: "//r/a1", IPAddress: "203.0.113.1", Status: "RESERVED"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run1", StartedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+		Assets: []ProcessedAsset{
+			{Name: "a1", ResourceName: "//r/a1", IPAddress: "203.0.113.1", Status: "IN_USE"},
+			{Name: "a2", ResourceName: "//r/a2", IPAddress: "203.0.113.2"},
+		},
+	}
+	if err := NewFileRunStore(cfg.HistoryDir).Save(ctx, report); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+
+	var out bytes.Buffer
+	if err := runDiffCommand(ctx, logger, cfg, []string{snapshotPath, "2024-06-30"}, &out); err != nil {
+		t.Fatalf("runDiffCommand failed: %v", err)
+	}
+
+	for _, want := range []string{"changed", "status: RESERVED -> IN_USE", "added", "a2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the table:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+
+	reportPath := filepath.Join(cfg.HistoryDir, "run1.json")
+	if err := runDiffCommand(ctx, logger, cfg, []string{"--format", "json", reportPath, snapshotPath}, &out); err != nil {
+		t.Fatalf("runDiffCommand failed: %v", err)
+	}
+
+	var diff SnapshotDiff
+	if err := json.Unmarshal(out.Bytes(), &diff); err != nil {
+		t.Fatalf("failed to decode the diff: %v", err)
+	}
+
+	if diff.Old.RunID != "run1" || diff.New.RunID != "snap" || len(diff.Diffs) != 2 || diff.Diffs[1].Type != DiffRemoved {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	if err := runDiffCommand(ctx, logger, cfg, []string{snapshotPath}, &out); !errors.Is(err, errDiffArguments) {
+		t.Errorf("expected errDiffArguments, got %v", err)
+	}
+
+	err := runDiffCommand(ctx, logger, cfg, []string{snapshotPath, filepath.Join(dir, "missing.json")}, &out)
+	if !errors.Is(err, errSnapshotNotFound) {
+		t.Errorf("expected errSnapshotNotFound, got %v", err)
+	}
+
+	out.Reset()
+
+	err = runDiffCommand(ctx, logger, cfg, []string{"--format", "csv", reportPath, snapshotPath}, &out)
+	if !errors.Is(err, errUnknownDiffFormat) {
+		t.Errorf("expected errUnknownDiffFormat, got %v", err)
+	}
+
+	if err := runDiffCommand(ctx, logger, cfg, []string{"--unknown", reportPath, snapshotPath}, &out); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected nothing written to the output on errors, got %q", out.String())
+	}
+}
@@ -0,0 +1,175 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// stateKeyNotifyDigest is the key of the findings accumulated for the next notification digest.
+const stateKeyNotifyDigest = "notify-digest"
+
+// notifyDigest is the state of a digest window: the policy violations and changes of the runs
+// since the window started.
+type notifyDigest struct {
+	Since      time.Time       `json:"since"`
+	Runs       int             `json:"runs"`
+	Violations []RuleViolation `json:"violations,omitempty"`
+	Diffs      []AssetDiff     `json:"diffs,omitempty"`
+}
+
+// add merges the findings of the report. A violation reported by several runs is kept once,
+// as last reported, while every change is kept.
+func (d *notifyDigest) add(report *Report) {
+	d.Runs++
+
+	for _, v := range report.Violations {
+		found := false
+
+		for i := range d.Violations {
+			if d.Violations[i].Rule == v.Rule && assetKey(d.Violations[i].Asset) == assetKey(v.Asset) {
+				d.Violations[i] = v
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			d.Violations = append(d.Violations, v)
+		}
+	}
+
+	d.Diffs = append(d.Diffs, report.Diffs...)
+}
+
+// summary returns the summary of a notification of the digest.
+func (d *notifyDigest) summary(report *Report) string {
+	return fmt.Sprintf("Digest of %d runs since %s, %d assets scanned in the last run %s", d.Runs,
+		d.Since.Format(time.RFC3339), report.Summary.TotalAssets, report.Metadata.RunID)
+}
+
+// notifyDigestSink accumulates the findings of the runs in the state store and publishes them
+// to the notifier sinks once per window, so that frequent runs send one summarized message
+// instead of a message every run.
+type notifyDigestSink struct {
+	sinks  []Sink
+	state  StateStore
+	window time.Duration
+	logger *slog.Logger
+}
+
+// newNotifyDigestSink creates a sink sending a digest of the findings to the sinks every window.
+func newNotifyDigestSink(logger *slog.Logger, state StateStore, window time.Duration, sinks []Sink) *notifyDigestSink {
+	return &notifyDigestSink{
+		sinks:  sinks,
+		state:  state,
+		window: window,
+		logger: logger,
+	}
+}
+
+// Name returns the name of the sink.
+func (s *notifyDigestSink) Name() string {
+	return "notify-digest"
+}
+
+// Publish adds the findings of the report to the digest and, once the window has elapsed,
+// notifies them. The digest is reset even if a notifier fails, so that the notifiers that
+// succeeded are not sent the same findings again.
+func (s *notifyDigestSink) Publish(ctx context.Context, report *Report) error {
+	digest, err := loadNotifyDigest(ctx, s.state)
+	if err != nil {
+		return err
+	}
+
+	if digest == nil {
+		digest = &notifyDigest{Since: report.Metadata.StartedAt}
+	}
+
+	digest.add(report)
+
+	due := digest.Since.Add(s.window)
+	if report.Metadata.StartedAt.Before(due) {
+		s.logger.InfoContext(ctx, "Added findings to the notification digest",
+			slog.Int("runs", digest.Runs),
+			slog.Int("violations", len(digest.Violations)),
+			slog.Int("changes", len(digest.Diffs)),
+			slog.Time("due", due),
+		)
+
+		return saveNotifyDigest(ctx, s.state, digest)
+	}
+
+	accumulated := *report
+	accumulated.Violations = digest.Violations
+	accumulated.Diffs = digest.Diffs
+
+	errs := []error{}
+	ctx = withNotifyDigest(ctx, digest)
+
+	for _, sink := range s.sinks {
+		if err := sink.Publish(ctx, &accumulated); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+
+	if err := saveNotifyDigest(ctx, s.state, &notifyDigest{Since: report.Metadata.StartedAt}); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes the notifier sinks.
+func (s *notifyDigestSink) Close() error {
+	errs := []error{}
+
+	for _, sink := range s.sinks {
+		errs = append(errs, sink.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// loadNotifyDigest reads the digest of the state store, or nil if no window has started.
+func loadNotifyDigest(ctx context.Context, state StateStore) (*notifyDigest, error) {
+	value, err := state.Get(ctx, stateKeyNotifyDigest)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	digest := &notifyDigest{}
+	if err := json.Unmarshal(value, digest); err != nil {
+		return nil, fmt.Errorf("failed to decode notification digest: %w", err)
+	}
+
+	return digest, nil
+}
+
+// saveNotifyDigest writes the digest to the state store.
+func saveNotifyDigest(ctx context.Context, state StateStore, digest *notifyDigest) error {
+	value, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification digest: %w", err)
+	}
+
+	return state.Put(ctx, stateKeyNotifyDigest, value)
+}
+
+type notifyDigestContextKey struct{}
+
+// withNotifyDigest returns a context carrying the digest being notified.
+func withNotifyDigest(ctx context.Context, digest *notifyDigest) context.Context {
+	return context.WithValue(ctx, notifyDigestContextKey{}, digest)
+}
+
+// notifyDigestFromContext returns the digest being notified, or nil for the findings of a run.
+func notifyDigestFromContext(ctx context.Context) *notifyDigest {
+	digest, _ := ctx.Value(notifyDigestContextKey{}).(*notifyDigest)
+
+	return digest
+}
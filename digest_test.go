@@ -0,0 +1,65 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifyDigestSink_Publish(t *testing.T) {
+	ctx := t.Context()
+	notifier := &fakeNotifier{}
+	state := &memoryStateStore{values: map[string][]byte{}}
+	sink := newNotifyDigestSink(slog.New(slog.DiscardHandler), state, 24*time.Hour,
+		[]Sink{notifierSink{notifier: notifier}})
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	violation := RuleViolation{Rule: "r", Severity: severityHigh, Message: "exposed", Asset: ProcessedAsset{
+		ResourceName: "//compute/a1", Project: "p",
+	}}
+	runs := []*Report{
+		{
+			Metadata:   RunMetadata{RunID: "run-1", StartedAt: start},
+			Violations: []RuleViolation{violation},
+		},
+		{
+			Metadata:   RunMetadata{RunID: "run-2", StartedAt: start.Add(12 * time.Hour)},
+			Violations: []RuleViolation{violation},
+			Diffs:      []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "a2", IPAddress: "203.0.113.2", Project: "p"}}},
+		},
+	}
+
+	for _, report := range runs {
+		if err := sink.Publish(ctx, report); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	if len(notifier.notifications) != 0 {
+		t.Fatalf("expected no notification within the window, got %d", len(notifier.notifications))
+	}
+
+	last := &Report{Metadata: RunMetadata{RunID: "run-3", StartedAt: start.Add(24 * time.Hour)}}
+	if err := sink.Publish(ctx, last); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected 1 digest notification, got %d", len(notifier.notifications))
+	}
+
+	got := notifier.notifications[0]
+	if len(got.Items) != 2 || !strings.HasPrefix(got.Summary, "Digest of 3 runs since 2025-01-01T00:00:00Z") {
+		t.Errorf("unexpected digest notification %+v", got)
+	}
+
+	digest, err := loadNotifyDigest(ctx, state)
+	if err != nil {
+		t.Fatalf("loadNotifyDigest failed: %v", err)
+	}
+
+	if digest.Runs != 0 || len(digest.Violations) != 0 || !digest.Since.Equal(last.Metadata.StartedAt) {
+		t.Errorf("expected the digest to be reset, got %+v", digest)
+	}
+}
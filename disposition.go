@@ -0,0 +1,138 @@
+package assetwatcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/option"
+)
+
+// Dispositions of an asset in the cleanup report.
+const (
+	dispositionKeep           = "keep"
+	dispositionReview         = "review"
+	dispositionWillAutoDelete = "will-auto-delete"
+)
+
+const (
+	// projectDeleteRequested is the state of a project pending deletion, whose resources
+	// are deleted with the project at the end of the grace period.
+	projectDeleteRequested = "DELETE_REQUESTED"
+
+	// projectDeletePermission is the restriction of the liens preventing project deletion.
+	projectDeletePermission = "resourcemanager.projects.delete"
+)
+
+// ProjectStatus is the lifecycle state of a project and the reasons of its deletion liens.
+type ProjectStatus struct {
+	State string
+	Liens []string
+}
+
+// ProjectStatusFetcher is an interface for fetching the lifecycle state of projects.
+type ProjectStatusFetcher interface {
+	FetchProjectStatus(ctx context.Context, project string) (ProjectStatus, error)
+}
+
+// GoogleProjectStatusFetcher is a Resource Manager API client.
+type GoogleProjectStatusFetcher struct {
+	service *cloudresourcemanager.Service
+	logger  *slog.Logger
+}
+
+// NewGoogleProjectStatusFetcher creates a new Resource Manager API fetcher.
+func NewGoogleProjectStatusFetcher(
+	ctx context.Context,
+	logger *slog.Logger,
+	opts ...option.ClientOption,
+) (*GoogleProjectStatusFetcher, error) {
+	s, err := cloudresourcemanager.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource manager client: %w", err)
+	}
+
+	return &GoogleProjectStatusFetcher{
+		service: s,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// FetchProjectStatus fetches the state of a project and its liens restricting deletion.
+func (f *GoogleProjectStatusFetcher) FetchProjectStatus(ctx context.Context, project string) (ProjectStatus, error) {
+	p, err := f.service.Projects.Get("projects/" + project).Context(ctx).Do()
+	if err != nil {
+		return ProjectStatus{}, fmt.Errorf("failed to get project %s: %w", project, err)
+	}
+
+	status := ProjectStatus{State: p.State, Liens: []string{}}
+
+	err = f.service.Liens.List().Parent(p.Name).
+		Pages(ctx, func(resp *cloudresourcemanager.ListLiensResponse) error {
+			for _, lien := range resp.Liens {
+				if slices.Contains(lien.Restrictions, projectDeletePermission) {
+					status.Liens = append(status.Liens, lien.Reason)
+				}
+			}
+
+			return nil
+		})
+	if err != nil {
+		return ProjectStatus{}, fmt.Errorf("failed to list liens of project %s: %w", project, err)
+	}
+
+	return status, nil
+}
+
+// annotateDispositions fetches the state of the project of every asset and sets the
+// disposition of the assets. Failures to fetch a project are logged and do not abort
+// the run, and the assets of the project are classified without its state.
+func annotateDispositions(
+	ctx context.Context,
+	logger *slog.Logger,
+	fetcher ProjectStatusFetcher,
+	assets []ProcessedAsset,
+) []ProcessedAsset {
+	statuses := make(map[string]ProjectStatus)
+
+	for i := range assets {
+		project := assets[i].Project
+
+		status, ok := statuses[project]
+		if !ok && project != "N/A" {
+			var err error
+
+			status, err = fetcher.FetchProjectStatus(ctx, project)
+			if err != nil {
+				logger.WarnContext(ctx, "failed to fetch project status",
+					slog.String("project", project),
+					slog.Any("error", err),
+				)
+			}
+
+			statuses[project] = status
+		}
+
+		assets[i].Disposition = disposition(assets[i], status)
+	}
+
+	return assets
+}
+
+// disposition classifies an asset for cleanup. Assets of projects pending deletion will be
+// deleted with their project, assets of projects protected by a lien are deliberately kept,
+// and idle addresses of other projects are to be reviewed.
+func disposition(asset ProcessedAsset, status ProjectStatus) string {
+	switch {
+	case status.State == projectDeleteRequested:
+		return dispositionWillAutoDelete
+	case len(status.Liens) > 0:
+		return dispositionKeep
+	case isIdleAddress(asset):
+		return dispositionReview
+	default:
+		return dispositionKeep
+	}
+}
@@ -0,0 +1,141 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+var errProjectNotFound = errors.New("project not found")
+
+// fakeProjectStatusFetcher is a mock implementation of the ProjectStatusFetcher.
+type fakeProjectStatusFetcher struct {
+	statuses map[string]ProjectStatus
+	calls    []string
+}
+
+// FetchProjectStatus returns the status stored for the project.
+func (f *fakeProjectStatusFetcher) FetchProjectStatus(_ context.Context, project string) (ProjectStatus, error) {
+	f.calls = append(f.calls, project)
+
+	status, ok := f.statuses[project]
+	if !ok {
+		return ProjectStatus{}, errProjectNotFound
+	}
+
+	return status, nil
+}
+
+func TestDisposition(t *testing.T) {
+	idle := ProcessedAsset{Status: addressStatusReserved, AddressType: "EXTERNAL"}
+	inUse := ProcessedAsset{Status: "IN_USE", AddressType: "EXTERNAL"}
+
+	tests := []struct {
+		name   string
+		asset  ProcessedAsset
+		status ProjectStatus
+		want   string
+	}{
+		{name: "idle address", asset: idle, status: ProjectStatus{State: "ACTIVE"}, want: dispositionReview},
+		{name: "in use address", asset: inUse, status: ProjectStatus{State: "ACTIVE"}, want: dispositionKeep},
+		{name: "project pending deletion", asset: idle, status: ProjectStatus{State: projectDeleteRequested}, want: dispositionWillAutoDelete},
+		{name: "project under lien", asset: idle, status: ProjectStatus{State: "ACTIVE", Liens: []string{"production"}}, want: dispositionKeep},
+		{name: "unknown project status", asset: idle, want: dispositionReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := disposition(tt.asset, tt.status); got != tt.want {
+				t.Errorf("disposition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateDispositions(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	fetcher := &fakeProjectStatusFetcher{
+		statuses: map[string]ProjectStatus{
+			"proj-A": {State: projectDeleteRequested},
+			"proj-B": {State: "ACTIVE"},
+		},
+	}
+
+	assets := []ProcessedAsset{
+		{Name: "a1", Project: "proj-A", Status: addressStatusReserved},
+		{Name: "a2", Project: "proj-A", Status: addressStatusReserved},
+		{Name: "b1", Project: "proj-B", Status: addressStatusReserved},
+		{Name: "c1", Project: "proj-C", Status: "IN_USE"},
+	}
+
+	got := annotateDispositions(ctx, logger, fetcher, assets)
+
+	if want := []string{"proj-A", "proj-B", "proj-C"}; !reflect.DeepEqual(fetcher.calls, want) {
+		t.Errorf("fetched projects %v, want %v", fetcher.calls, want)
+	}
+
+	dispositions := []string{}
+	for _, asset := range got {
+		dispositions = append(dispositions, asset.Disposition)
+	}
+
+	want := []string{dispositionWillAutoDelete, dispositionWillAutoDelete, dispositionReview, dispositionKeep}
+	if !reflect.DeepEqual(dispositions, want) {
+		t.Errorf("dispositions = %v, want %v", dispositions, want)
+	}
+}
+
+func TestFetchProjectStatus_WithFakeServer(t *testing.T) {
+	var lienParent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v3/projects/proj-A":
+			_, _ = w.Write([]byte(`{"name": "projects/1234", "projectId": "proj-A", "state": "ACTIVE"}`))
+		case "/v3/liens":
+			lienParent = r.URL.Query().Get("parent")
+			_, _ = w.Write([]byte(`{"liens": [
+				{"name": "liens/1", "reason": "Holds production addresses", "restrictions": ["resourcemanager.projects.delete"]},
+				{"name": "liens/2", "reason": "Unrelated", "restrictions": ["resourcemanager.projects.update"]}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	fetcher, err := NewGoogleProjectStatusFetcher(ctx, logger,
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewGoogleProjectStatusFetcher failed: %v", err)
+	}
+
+	status, err := fetcher.FetchProjectStatus(ctx, "proj-A")
+	if err != nil {
+		t.Fatalf("FetchProjectStatus failed: %v", err)
+	}
+
+	if lienParent != "projects/1234" {
+		t.Errorf("liens listed for %q, want projects/1234", lienParent)
+	}
+
+	want := ProjectStatus{State: "ACTIVE", Liens: []string{"Holds production addresses"}}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("FetchProjectStatus() = %+v, want %+v", status, want)
+	}
+}
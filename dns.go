@@ -0,0 +1,220 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"strings"
+
+	dns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/option"
+)
+
+var errInvalidDNSZone = errors.New("invalid DNS zone")
+
+// DNSRecord represents an address record of a DNS zone.
+type DNSRecord struct {
+	Name    string
+	Type    string
+	Address string
+	Zone    string
+}
+
+// DNSProvider is an interface for listing the address records of DNS zones.
+type DNSProvider interface {
+	Name() string
+	ListRecords(ctx context.Context) ([]DNSRecord, error)
+}
+
+// UnmappedAddress represents an external address that no DNS record points at.
+type UnmappedAddress struct {
+	Address string `json:"address"`
+	Asset   string `json:"asset"`
+	Project string `json:"project"`
+}
+
+// DanglingRecord represents a DNS record pointing at an address that is not owned
+// anymore, which can be taken over by whoever gets the address next.
+type DanglingRecord struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Address string `json:"address"`
+	Zone    string `json:"zone"`
+}
+
+// DNSReconciliation represents the result of cross-referencing the addresses with DNS records.
+type DNSReconciliation struct {
+	Records           int               `json:"records"`
+	UnmappedAddresses []UnmappedAddress `json:"unmappedAddresses"`
+	DanglingRecords   []DanglingRecord  `json:"danglingRecords"`
+}
+
+// cloudDNSZone is a managed zone of Cloud DNS.
+type cloudDNSZone struct {
+	project string
+	zone    string
+}
+
+// parseCloudDNSZones parses a comma-separated list of PROJECT/ZONE managed zones.
+func parseCloudDNSZones(s string) ([]cloudDNSZone, error) {
+	zones := []cloudDNSZone{}
+
+	for _, z := range splitString(s, ",") {
+		project, zone, ok := strings.Cut(z, "/")
+		if !ok || project == "" || zone == "" || strings.Contains(zone, "/") {
+			return nil, fmt.Errorf("%w: %q, expected PROJECT/ZONE", errInvalidDNSZone, z)
+		}
+
+		zones = append(zones, cloudDNSZone{project: project, zone: zone})
+	}
+
+	return zones, nil
+}
+
+// CloudDNSProvider lists the address records of Cloud DNS managed zones.
+type CloudDNSProvider struct {
+	service *dns.Service
+	zones   []cloudDNSZone
+	logger  *slog.Logger
+}
+
+// NewCloudDNSProvider creates a new Cloud DNS provider for the configured zones.
+func NewCloudDNSProvider(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg *Config,
+	opts ...option.ClientOption,
+) (*CloudDNSProvider, error) {
+	zones, err := parseCloudDNSZones(cfg.DNSZones)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := dns.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud DNS client: %w", err)
+	}
+
+	return &CloudDNSProvider{
+		service: s,
+		zones:   zones,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (p *CloudDNSProvider) Name() string {
+	return "clouddns"
+}
+
+// ListRecords lists the A and AAAA records of all zones.
+func (p *CloudDNSProvider) ListRecords(ctx context.Context) ([]DNSRecord, error) {
+	records := []DNSRecord{}
+
+	for _, z := range p.zones {
+		zoneName := z.project + "/" + z.zone
+
+		err := p.service.ResourceRecordSets.List(z.project, z.zone).
+			Pages(ctx, func(resp *dns.ResourceRecordSetsListResponse) error {
+				for _, rrset := range resp.Rrsets {
+					if rrset.Type != "A" && rrset.Type != "AAAA" {
+						continue
+					}
+
+					for _, address := range rrset.Rrdatas {
+						records = append(records, DNSRecord{
+							Name: rrset.Name, Type: rrset.Type, Address: address, Zone: zoneName,
+						})
+					}
+				}
+
+				return nil
+			})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list records of zone %s: %w", zoneName, err)
+		}
+	}
+
+	p.logger.DebugContext(ctx, "Listed DNS records", slog.Int("number_of_records", len(records)))
+
+	return records, nil
+}
+
+// fetchDNSRecords lists the address records of all providers.
+func fetchDNSRecords(ctx context.Context, providers []DNSProvider) ([]DNSRecord, error) {
+	records := []DNSRecord{}
+
+	for _, provider := range providers {
+		r, err := provider.ListRecords(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s records: %w", provider.Name(), err)
+		}
+
+		records = append(records, r...)
+	}
+
+	return records, nil
+}
+
+// reconcileDNS reports the external addresses without any DNS record and the DNS records
+// pointing at addresses that are not among the assets. Internal addresses are owned, but
+// are not expected to have DNS records.
+func reconcileDNS(assets []ProcessedAsset, records []DNSRecord) DNSReconciliation {
+	recorded := make(map[netip.Addr]bool)
+	for _, record := range records {
+		if addr, err := netip.ParseAddr(record.Address); err == nil {
+			recorded[addr.Unmap()] = true
+		}
+	}
+
+	owned := make(map[netip.Addr]bool)
+	reconciliation := DNSReconciliation{
+		Records:           len(records),
+		UnmappedAddresses: []UnmappedAddress{},
+		DanglingRecords:   []DanglingRecord{},
+	}
+
+	for _, asset := range assets {
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil {
+				continue
+			}
+
+			addr = addr.Unmap()
+			owned[addr] = true
+
+			if !recorded[addr] && isPublicAddress(asset, addr) {
+				reconciliation.UnmappedAddresses = append(reconciliation.UnmappedAddresses, UnmappedAddress{
+					Address: address, Asset: asset.Name, Project: asset.Project,
+				})
+			}
+		}
+	}
+
+	for _, record := range records {
+		addr, err := netip.ParseAddr(record.Address)
+		if err != nil || owned[addr.Unmap()] {
+			continue
+		}
+
+		reconciliation.DanglingRecords = append(reconciliation.DanglingRecords, DanglingRecord(record))
+	}
+
+	return reconciliation
+}
+
+// isPublicAddress reports whether the address of the asset is reachable from the internet.
+func isPublicAddress(asset ProcessedAsset, addr netip.Addr) bool {
+	return asset.AddressType != addressTypeInternal && !addr.IsPrivate()
+}
+
+// ownedAddresses returns the addresses of the asset, including the external
+// addresses of instances.
+func ownedAddresses(asset ProcessedAsset) []string {
+	addresses := []string{asset.IPAddress}
+
+	return append(addresses, splitString(asset.Attributes["externalIPs"], ",")...)
+}
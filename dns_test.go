@@ -0,0 +1,115 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestParseCloudDNSZones(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []cloudDNSZone
+		wantErr bool
+	}{
+		{input: "", want: []cloudDNSZone{}},
+		{input: "proj-a/zone-1, proj-b/zone-2", want: []cloudDNSZone{{"proj-a", "zone-1"}, {"proj-b", "zone-2"}}},
+		{input: "zone-1", wantErr: true},
+		{input: "proj-a/", wantErr: true},
+		{input: "proj-a/zones/zone-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseCloudDNSZones(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCloudDNSZones() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCloudDNSZones() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDNS(t *testing.T) {
+	assets := []ProcessedAsset{
+		{Name: "mapped", Project: "p", AddressType: "EXTERNAL", IPAddress: "203.0.113.1"},
+		{Name: "unmapped", Project: "p", AddressType: "EXTERNAL", IPAddress: "203.0.113.2"},
+		{Name: "internal", Project: "p", AddressType: "INTERNAL", IPAddress: "10.0.0.1"},
+		{Name: "vm", Project: "p", IPAddress: "N/A", Attributes: map[string]string{"externalIPs": "203.0.113.3,2001:db8::1"}},
+	}
+
+	records := []DNSRecord{
+		{Name: "www.example.com.", Type: "A", Address: "203.0.113.1", Zone: "p/example"},
+		{Name: "vm.example.com.", Type: "A", Address: "203.0.113.3", Zone: "p/example"},
+		{Name: "vm.example.com.", Type: "AAAA", Address: "2001:0db8:0000::1", Zone: "p/example"},
+		{Name: "intranet.example.com.", Type: "A", Address: "10.0.0.1", Zone: "p/example"},
+		{Name: "old.example.com.", Type: "A", Address: "198.51.100.7", Zone: "p/example"},
+	}
+
+	got := reconcileDNS(assets, records)
+
+	if got.Records != len(records) {
+		t.Errorf("expected %d records, got %d", len(records), got.Records)
+	}
+
+	wantUnmapped := []UnmappedAddress{{Address: "203.0.113.2", Asset: "unmapped", Project: "p"}}
+	if !reflect.DeepEqual(got.UnmappedAddresses, wantUnmapped) {
+		t.Errorf("unexpected unmapped addresses: %+v", got.UnmappedAddresses)
+	}
+
+	wantDangling := []DanglingRecord{{Name: "old.example.com.", Type: "A", Address: "198.51.100.7", Zone: "p/example"}}
+	if !reflect.DeepEqual(got.DanglingRecords, wantDangling) {
+		t.Errorf("unexpected dangling records: %+v", got.DanglingRecords)
+	}
+}
+
+func TestCloudDNSProvider_ListRecords(t *testing.T) {
+	var requestedPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"rrsets": [
+			{"name": "www.example.com.", "type": "A", "rrdatas": ["203.0.113.1", "203.0.113.2"]},
+			{"name": "example.com.", "type": "MX", "rrdatas": ["10 mail.example.com."]},
+			{"name": "v6.example.com.", "type": "AAAA", "rrdatas": ["2001:db8::1"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	provider, err := NewCloudDNSProvider(ctx, slog.New(slog.DiscardHandler), &Config{DNSZones: "proj-dns/example"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewCloudDNSProvider failed: %v", err)
+	}
+
+	records, err := provider.ListRecords(ctx)
+	if err != nil {
+		t.Fatalf("ListRecords failed: %v", err)
+	}
+
+	if requestedPath != "/dns/v1/projects/proj-dns/managedZones/example/rrsets" {
+		t.Errorf("unexpected request path %s", requestedPath)
+	}
+
+	want := []DNSRecord{
+		{Name: "www.example.com.", Type: "A", Address: "203.0.113.1", Zone: "proj-dns/example"},
+		{Name: "www.example.com.", Type: "A", Address: "203.0.113.2", Zone: "proj-dns/example"},
+		{Name: "v6.example.com.", Type: "AAAA", Address: "2001:db8::1", Zone: "proj-dns/example"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("ListRecords() = %+v, want %+v", records, want)
+	}
+}
@@ -0,0 +1,190 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/oauth2/google"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/serviceusage/v1"
+)
+
+// doctorCommand checks the credentials, the APIs, and the permissions of a scan before it runs.
+const doctorCommand = "doctor"
+
+var errDoctorFailed = errors.New("preflight checks failed")
+
+// doctorAPI is an API a scan calls, enabled in the quota project.
+type doctorAPI struct {
+	service string
+	enabled func(*Config) bool
+}
+
+// doctorAPIs are the APIs called by the scan with the configuration.
+var doctorAPIs = []doctorAPI{
+	{service: "cloudasset.googleapis.com", enabled: func(*Config) bool { return true }},
+	{service: "recommender.googleapis.com", enabled: func(cfg *Config) bool { return cfg.ShowRecommendations }},
+}
+
+// doctorPermission is a permission a scan needs on the organization, and the predefined role
+// granting it.
+type doctorPermission struct {
+	permission string
+	role       string
+	enabled    func(*Config) bool
+}
+
+// doctorPermissions are the permissions needed by the scan with the configuration.
+var doctorPermissions = []doctorPermission{
+	{
+		permission: "cloudasset.assets.searchAllResources",
+		role:       "roles/cloudasset.viewer",
+		enabled:    func(*Config) bool { return true },
+	},
+	{
+		permission: "resourcemanager.projects.get",
+		role:       "roles/browser",
+		enabled:    func(*Config) bool { return true },
+	},
+	{
+		permission: "resourcemanager.projects.list",
+		role:       "roles/browser",
+		enabled:    func(*Config) bool { return true },
+	},
+	{
+		permission: "recommender.computeAddressIdleResourceRecommendations.list",
+		role:       "roles/recommender.computeViewer",
+		enabled:    func(cfg *Config) bool { return cfg.ShowRecommendations },
+	},
+}
+
+// runDoctorCommand runs the preflight checks of a scan, so that a missing credential, API, or
+// permission is reported with a fix before a long scan fails halfway: the Application Default
+// Credentials must be found, the APIs of the scan must be enabled in the quota project, which
+// --project overrides, and the identity of the scan must hold the permissions it needs on the
+// organization, as reported by testIamPermissions. Every check is written as PASS or FAIL.
+func runDoctorCommand(ctx context.Context, logger *slog.Logger, cfg *Config, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet(doctorCommand, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	project := flags.String("project", "", "quota project where the APIs must be enabled, "+
+		"instead of the project of the Application Default Credentials")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	checks := &selftest{out: stdout}
+
+	creds, err := google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	if err != nil {
+		checks.check("credentials", false, "Application Default Credentials not found, "+
+			"run `gcloud auth application-default login` or set GOOGLE_APPLICATION_CREDENTIALS: %v", err)
+	} else {
+		checks.check("credentials", true, "Application Default Credentials found")
+	}
+
+	if *project == "" {
+		*project = os.Getenv("GOOGLE_CLOUD_QUOTA_PROJECT")
+	}
+
+	if *project == "" && creds != nil {
+		*project = creds.ProjectID
+	}
+
+	opts := clientOptionsFor(ctx, logger, cfg, credentialsAssets)
+
+	if *project == "" {
+		checks.check("apis", false, "no quota project found, run "+
+			"`gcloud auth application-default set-quota-project PROJECT_ID` or set --project")
+	} else if usage, err := serviceusage.NewService(ctx, opts...); err != nil {
+		checks.check("apis", false, "failed to create Service Usage client: %v", err)
+	} else {
+		checkDoctorAPIs(ctx, checks, usage, cfg, *project)
+	}
+
+	if crm, err := cloudresourcemanager.NewService(ctx, opts...); err != nil {
+		checks.check("permissions", false, "failed to create Resource Manager client: %v", err)
+	} else {
+		checkDoctorPermissions(ctx, checks, crm, cfg)
+	}
+
+	if checks.failed > 0 {
+		return fmt.Errorf("%w: %d checks failed", errDoctorFailed, checks.failed)
+	}
+
+	return nil
+}
+
+// checkDoctorAPIs checks that the APIs of the scan are enabled in the project.
+func checkDoctorAPIs(ctx context.Context, checks *selftest, usage *serviceusage.Service, cfg *Config, project string) {
+	for _, api := range doctorAPIs {
+		if !api.enabled(cfg) {
+			continue
+		}
+
+		service, err := usage.Services.Get("projects/" + project + "/services/" + api.service).Context(ctx).Do()
+
+		switch {
+		case err != nil:
+			checks.check("apis", false, "failed to get the state of %s in project %s, which requires "+
+				"serviceusage.services.get: %v", api.service, project, err)
+		case service.State != "ENABLED":
+			checks.check("apis", false, "%s is not enabled in project %s, run `gcloud services enable %s --project %s`",
+				api.service, project, api.service, project)
+		default:
+			checks.check("apis", true, "%s is enabled in project %s", api.service, project)
+		}
+	}
+}
+
+// checkDoctorPermissions checks that the identity of the scan holds the permissions of the scan
+// on the organization, and names the roles granting the missing ones.
+func checkDoctorPermissions(ctx context.Context, checks *selftest, crm *cloudresourcemanager.Service, cfg *Config) {
+	resource := "organizations/" + cfg.OrgID
+	permissions := []string{}
+
+	for _, p := range doctorPermissions {
+		if p.enabled(cfg) {
+			permissions = append(permissions, p.permission)
+		}
+	}
+
+	resp, err := crm.Organizations.TestIamPermissions(resource,
+		&cloudresourcemanager.TestIamPermissionsRequest{Permissions: permissions}).Context(ctx).Do()
+	if err != nil {
+		checks.check("permissions", false, "failed to test the permissions on %s: %v", resource, err)
+
+		return
+	}
+
+	missing := []string{}
+	roles := []string{}
+
+	for _, p := range doctorPermissions {
+		if !slices.Contains(permissions, p.permission) || slices.Contains(resp.Permissions, p.permission) {
+			continue
+		}
+
+		missing = append(missing, p.permission)
+
+		if !slices.Contains(roles, p.role) {
+			roles = append(roles, p.role)
+		}
+	}
+
+	if len(missing) > 0 {
+		checks.check("permissions", false, "missing %s on %s, grant %s", strings.Join(missing, ", "), resource,
+			strings.Join(roles, " and "))
+
+		return
+	}
+
+	checks.check("permissions", true, "%d permissions granted on %s", len(permissions), resource)
+}
@@ -0,0 +1,124 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+// newDoctorServer serves the Service Usage and Resource Manager APIs, with the services enabled
+// in the project and the permissions granted on the organization.
+func newDoctorServer(t *testing.T, enabled, granted []string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/projects/quota-project/services/"):
+			state := "DISABLED"
+			for _, service := range enabled {
+				if strings.HasSuffix(r.URL.Path, "/"+service) {
+					state = "ENABLED"
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]string{"state": state})
+		case r.URL.Path == "/v3/organizations/123:testIamPermissions":
+			var req struct {
+				Permissions []string `json:"permissions"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+
+			resp := map[string][]string{"permissions": {}}
+			for _, permission := range req.Permissions {
+				for _, g := range granted {
+					if permission == g {
+						resp["permissions"] = append(resp["permissions"], permission)
+					}
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestRunDoctorCommand(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(keyFile, []byte(`{"type": "service_account", "project_id": "quota-project",
+"client_email": "sa@quota-project.iam.gserviceaccount.com", "private_key": "unused"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", keyFile)
+	t.Setenv("GOOGLE_CLOUD_QUOTA_PROJECT", "")
+
+	logger := slog.New(slog.DiscardHandler)
+
+	srv := newDoctorServer(t, []string{"cloudasset.googleapis.com"}, []string{
+		"cloudasset.assets.searchAllResources", "resourcemanager.projects.get", "resourcemanager.projects.list",
+	})
+	ctx := withSelftestServices(t.Context(), &selftestServices{
+		clientOptions: []option.ClientOption{option.WithEndpoint(srv.URL + "/"), option.WithoutAuthentication()},
+	})
+
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+
+	var out bytes.Buffer
+	if err := runDoctorCommand(ctx, logger, &cfg, nil, &out); err != nil {
+		t.Fatalf("runDoctorCommand() error = %v\n%s", err, out.String())
+	}
+
+	want := "PASS  credentials    Application Default Credentials found\n" +
+		"PASS  apis           cloudasset.googleapis.com is enabled in project quota-project\n" +
+		"PASS  permissions    3 permissions granted on organizations/123\n"
+	if out.String() != want {
+		t.Errorf("runDoctorCommand() wrote %q, want %q", out.String(), want)
+	}
+
+	// The recommendations need another API and permission.
+	cfg.ShowRecommendations = true
+
+	out.Reset()
+
+	if err := runDoctorCommand(ctx, logger, &cfg, nil, &out); !errors.Is(err, errDoctorFailed) {
+		t.Errorf("expected errDoctorFailed, got %v", err)
+	}
+
+	for _, want := range []string{
+		"FAIL  apis           recommender.googleapis.com is not enabled in project quota-project, " +
+			"run `gcloud services enable recommender.googleapis.com --project quota-project`\n",
+		"FAIL  permissions    missing recommender.computeAddressIdleResourceRecommendations.list on " +
+			"organizations/123, grant roles/recommender.computeViewer\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in %q", want, out.String())
+		}
+	}
+
+	// --project overrides the quota project of the credentials.
+	out.Reset()
+	cfg.ShowRecommendations = false
+
+	if err := runDoctorCommand(ctx, logger, &cfg, []string{"--project", "other"}, &out); !errors.Is(err, errDoctorFailed) {
+		t.Errorf("expected errDoctorFailed, got %v", err)
+	}
+
+	if !strings.Contains(out.String(), "FAIL  apis           failed to get the state of cloudasset.googleapis.com in project other") {
+		t.Errorf("expected the other project to be checked, got %q", out.String())
+	}
+}
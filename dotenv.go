@@ -0,0 +1,154 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// envFileEnv is the environment variable of the env file, which disables it when empty.
+	envFileEnv     = "ASSET_WATCHER_ENV_FILE"
+	defaultEnvFile = ".env"
+)
+
+var (
+	errInvalidEnvFile = errors.New("invalid env file")
+	envNamePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// loadEnvFile sets the variables of the env file of ASSET_WATCHER_ENV_FILE, or of .env in the
+// working directory if it exists, which are not set in the environment yet, so that local and
+// docker-compose setups do not need to export every variable. The variables of the environment
+// take precedence over the file, as if it was sourced before.
+func loadEnvFile() error {
+	path, explicit := os.LookupEnv(envFileEnv)
+	if !explicit {
+		path = defaultEnvFile
+	}
+
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // The path is provided by the operator.
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	vars, err := parseEnvFile(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for name, value := range vars {
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseEnvFile parses the NAME=value lines of an env file. Lines may start with export, and
+// blank lines and comments starting with # are skipped. Values may be double-quoted, with the
+// \n, \t, \", and \\ escapes only, other backslashes being kept, or single-quoted, without
+// escapes; unquoted values end at a comment. Variables are not expanded.
+func parseEnvFile(data string) (map[string]string, error) {
+	vars := map[string]string{}
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		name = strings.TrimSpace(name)
+
+		if !ok || !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%w: line %d, expected NAME=value", errInvalidEnvFile, i+1)
+		}
+
+		value, ok = parseEnvValue(strings.TrimSpace(value))
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d, invalid quoted value of %s", errInvalidEnvFile, i+1, name)
+		}
+
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// envEscape returns the character of the \n, \t, \", and \\ escapes of double-quoted values.
+// Other backslashes are kept as they are, such as in "C:\Users".
+func envEscape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case '"', '\\':
+		return string(c)
+	default:
+		return "\\" + string(c)
+	}
+}
+
+// parseEnvValue returns the value of a line of an env file, unquoted and without its comment,
+// or false if a quoted value is not terminated or is followed by more than a comment.
+func parseEnvValue(value string) (string, bool) {
+	var rest string
+
+	switch {
+	case strings.HasPrefix(value, `"`):
+		var unquoted strings.Builder
+
+		end := 1
+		for ; end < len(value) && value[end] != '"'; end++ {
+			if value[end] == '\\' && end+1 < len(value) {
+				end++
+				unquoted.WriteString(envEscape(value[end]))
+
+				continue
+			}
+
+			unquoted.WriteByte(value[end])
+		}
+
+		if end >= len(value) {
+			return "", false
+		}
+
+		value, rest = unquoted.String(), value[end+1:]
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", false
+		}
+
+		value, rest = value[1:end+1], value[end+2:]
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+
+		return strings.TrimSpace(value), true
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	return value, rest == "" || strings.HasPrefix(rest, "#")
+}
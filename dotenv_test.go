@@ -0,0 +1,123 @@
+package assetwatcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	input := `# local development
+ASSET_WATCHER_ORG_ID=123
+export ASSET_WATCHER_DEBUG=true
+ASSET_WATCHER_INCLUDE_PROJECTS = prod-a,prod-b # production only
+ASSET_WATCHER_NAME_REGEX="^web-\\d+ #1\n"
+ASSET_WATCHER_USER_AGENT='acme "scanner" \n' # literal
+ASSET_WATCHER_TAG="C:\Users\x41 \"tab\"\t"
+ASSET_WATCHER_SLACK_CHANNEL=
+ASSET_WATCHER_WEBHOOK_URL=https://hooks.example.com/#fragment
+`
+
+	got, err := parseEnvFile(input)
+	if err != nil {
+		t.Fatalf("parseEnvFile() error = %v", err)
+	}
+
+	want := map[string]string{
+		"ASSET_WATCHER_ORG_ID":           "123",
+		"ASSET_WATCHER_DEBUG":            "true",
+		"ASSET_WATCHER_INCLUDE_PROJECTS": "prod-a,prod-b",
+		"ASSET_WATCHER_NAME_REGEX":       "^web-\\d+ #1\n",
+		"ASSET_WATCHER_USER_AGENT":       `acme "scanner" \n`,
+		"ASSET_WATCHER_TAG":              `C:\Users\x41 "tab"` + "\t",
+		"ASSET_WATCHER_SLACK_CHANNEL":    "",
+		"ASSET_WATCHER_WEBHOOK_URL":      "https://hooks.example.com/#fragment",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEnvFile() = %q, want %q", got, want)
+	}
+
+	for _, input := range []string{
+		"ASSET_WATCHER_ORG_ID\n",
+		"1ASSET=x\n",
+		"ASSET_WATCHER_ORG_ID=\"123\n",
+		"ASSET_WATCHER_ORG_ID='123\n",
+		"ASSET_WATCHER_ORG_ID=\"123\" 456\n",
+		"ASSET_WATCHER_ORG_ID=\"123\\\"\n",
+	} {
+		if _, err := parseEnvFile(input); !errors.Is(err, errInvalidEnvFile) {
+			t.Errorf("parseEnvFile(%q) expected errInvalidEnvFile, got %v", input, err)
+		}
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	cleanEnvVars()
+
+	// The variables set by the env file are restored by t.Setenv.
+	t.Setenv("ASSET_WATCHER_ORG_ID", "")
+	t.Setenv("ASSET_WATCHER_TAG", "")
+	_ = os.Unsetenv("ASSET_WATCHER_ORG_ID")
+	_ = os.Unsetenv("ASSET_WATCHER_TAG")
+
+	t.Setenv("ASSET_WATCHER_DEBUG", "false")
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	content := "ASSET_WATCHER_ORG_ID=from-env-file\nASSET_WATCHER_DEBUG=true\n"
+	if err := os.WriteFile(defaultEnvFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadEnvFile(); err != nil {
+		t.Fatalf("loadEnvFile() error = %v", err)
+	}
+
+	if got := os.Getenv("ASSET_WATCHER_ORG_ID"); got != "from-env-file" {
+		t.Errorf("expected the variable of the env file, got %q", got)
+	}
+
+	if got := os.Getenv("ASSET_WATCHER_DEBUG"); got != "false" {
+		t.Errorf("expected the environment to take precedence over the env file, got %q", got)
+	}
+
+	// ASSET_WATCHER_ENV_FILE replaces .env, and must exist.
+	other := filepath.Join(dir, "other.env")
+	if err := os.WriteFile(other, []byte("ASSET_WATCHER_TAG=owner=security\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(envFileEnv, other)
+
+	if err := loadEnvFile(); err != nil {
+		t.Fatalf("loadEnvFile() error = %v", err)
+	}
+
+	if got := os.Getenv("ASSET_WATCHER_TAG"); got != "owner=security" {
+		t.Errorf("expected the variable of ASSET_WATCHER_ENV_FILE, got %q", got)
+	}
+
+	t.Setenv(envFileEnv, filepath.Join(dir, "missing.env"))
+
+	if err := loadEnvFile(); err == nil {
+		t.Error("expected an error for a missing ASSET_WATCHER_ENV_FILE")
+	}
+
+	// An empty ASSET_WATCHER_ENV_FILE disables the env file, and a missing .env is ignored.
+	t.Setenv(envFileEnv, "")
+
+	if err := loadEnvFile(); err != nil {
+		t.Errorf("loadEnvFile() error = %v", err)
+	}
+
+	_ = os.Unsetenv(envFileEnv)
+	t.Chdir(t.TempDir())
+
+	if err := loadEnvFile(); err != nil {
+		t.Errorf("loadEnvFile() error = %v", err)
+	}
+}
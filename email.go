@@ -0,0 +1,421 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSMTPPort = 587
+	// smtpsPort is the port of SMTP over implicit TLS; other ports upgrade with STARTTLS when
+	// the server supports it.
+	smtpsPort = 465
+
+	// emailMaxRows is the maximum number of changes and of violations listed in the digest.
+	emailMaxRows = 500
+)
+
+// emailDigestTemplate renders the HTML digest of a report, with inline styles as most mail
+// clients ignore style sheets.
+var emailDigestTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; font-size: 14px; color: #1f2328;">
+<h2 style="font-size: 18px;">{{.Title}}</h2>
+<p>{{.Summary}}</p>
+{{- if .Stats}}
+<table style="border-collapse: collapse; margin-bottom: 16px;">
+{{- range .Stats}}
+<tr><td style="padding: 4px 16px 4px 0; color: #59636e;">{{.Name}}</td><td style="padding: 4px 0; font-weight: bold;">{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Changes}}
+<h3 style="font-size: 16px;">Changes</h3>
+<table style="border-collapse: collapse; width: 100%;">
+<tr style="background: #f6f8fa; text-align: left;"><th style="padding: 6px;">Change</th><th style="padding: 6px;">Asset</th><th style="padding: 6px;">Address</th><th style="padding: 6px;">Project</th><th style="padding: 6px;">Location</th><th style="padding: 6px;">Details</th></tr>
+{{- range .Changes}}
+<tr style="border-top: 1px solid #d1d9e0;"><td style="padding: 6px;">{{.Type}}</td><td style="padding: 6px;">{{.Asset.Name}}</td><td style="padding: 6px;">{{.Asset.IPAddress}}</td><td style="padding: 6px;">{{.Asset.Project}}</td><td style="padding: 6px;">{{.Asset.Location}}</td><td style="padding: 6px;">{{range $i, $c := .Changes}}{{if $i}}<br>{{end}}{{$c}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .OmittedChanges}}
+<p>{{.OmittedChanges}} more changes{{if .ArtifactURL}} in the <a href="{{.ArtifactURL}}">full report</a>{{end}}.</p>
+{{- end}}
+{{- end}}
+{{- if .Violations}}
+<h3 style="font-size: 16px;">Policy violations</h3>
+<table style="border-collapse: collapse; width: 100%;">
+<tr style="background: #f6f8fa; text-align: left;"><th style="padding: 6px;">Severity</th><th style="padding: 6px;">Rule</th><th style="padding: 6px;">Asset</th><th style="padding: 6px;">Address</th><th style="padding: 6px;">Project</th><th style="padding: 6px;">Message</th></tr>
+{{- range .Violations}}
+<tr style="border-top: 1px solid #d1d9e0;"><td style="padding: 6px;">{{.Severity}}</td><td style="padding: 6px;">{{.Rule}}</td><td style="padding: 6px;">{{.Asset.Name}}</td><td style="padding: 6px;">{{.Asset.IPAddress}}</td><td style="padding: 6px;">{{.Asset.Project}}</td><td style="padding: 6px;">{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- if .OmittedViolations}}
+<p>{{.OmittedViolations}} more violations{{if .ArtifactURL}} in the <a href="{{.ArtifactURL}}">full report</a>{{end}}.</p>
+{{- end}}
+{{- end}}
+{{- if .Items}}
+<ul>
+{{- range .Items}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- if .OmittedItems}}
+<p>{{.OmittedItems}} more items{{if .ArtifactURL}} in the <a href="{{.ArtifactURL}}">full report</a>{{end}}.</p>
+{{- end}}
+{{- end}}
+{{- if .ArtifactURL}}
+<p><a href="{{.ArtifactURL}}">View the full report</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// emailDigest is the data of the HTML digest.
+type emailDigest struct {
+	Title             string
+	Summary           string
+	Stats             []emailStat
+	Changes           []AssetDiff
+	OmittedChanges    int
+	Violations        []RuleViolation
+	OmittedViolations int
+	Items             []string
+	OmittedItems      int
+	ArtifactURL       string
+}
+
+type emailStat struct {
+	Name  string
+	Value string
+}
+
+// EmailNotifier sends notifications as HTML digests to a list of recipients over SMTP.
+type EmailNotifier struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	sender   string
+	to       []string
+	logger   *slog.Logger
+}
+
+// NewEmailNotifier creates a new email notifier for the configured SMTP server and recipients.
+func NewEmailNotifier(logger *slog.Logger, cfg *Config) *EmailNotifier {
+	// The addresses are validated by GetConfig.
+	to := []string{}
+
+	if recipients, err := mail.ParseAddressList(cfg.EmailTo); err == nil {
+		for _, recipient := range recipients {
+			to = append(to, recipient.Address)
+		}
+	}
+
+	// The sender of the envelope is the address without the display name of the From header.
+	sender := cfg.EmailFrom
+	if from, err := mail.ParseAddress(cfg.EmailFrom); err == nil {
+		sender = from.Address
+	}
+
+	return &EmailNotifier{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.EmailFrom,
+		sender:   sender,
+		to:       to,
+		logger:   logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the notifier.
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends the notification as an HTML digest with a plain text alternative.
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	message, err := n.newMessage(notification, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = notifyRetryFromContext(ctx).do(ctx, func() error {
+		client, err := n.dial(ctx)
+		if err != nil {
+			return smtpError(err)
+		}
+		defer client.Close()
+
+		return smtpError(n.send(client, message))
+	})
+	if err != nil {
+		return err
+	}
+
+	n.logger.DebugContext(ctx, "Sent email notification", slog.Int("recipients", len(n.to)))
+
+	return nil
+}
+
+// Check verifies that the SMTP server is reachable and accepts the credentials.
+func (n *EmailNotifier) Check(ctx context.Context) error {
+	client, err := n.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNotifierUnreachable, err)
+	}
+	defer client.Close()
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("%w: %w", errNotifierUnreachable, err)
+	}
+
+	return nil
+}
+
+// smtpError returns the failure of the notification, a transient error for network errors and
+// for the 4xx replies of the server, which reject the message temporarily.
+func smtpError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("%w: %w", errNotificationFailed, err)
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return &transientError{err: err}
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 400 && protoErr.Code < 500 {
+		return &transientError{err: err}
+	}
+
+	return err
+}
+
+// dial connects to the SMTP server, upgrading the connection to TLS, and authenticates if a
+// username is configured. The connection must be done within the notifier timeout.
+func (n *EmailNotifier) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(n.host, strconv.Itoa(n.port))
+	dialer := &net.Dialer{Timeout: notifierTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(notifierTimeout)); err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("failed to set the deadline of the connection: %w", err)
+	}
+
+	if n.port == smtpsPort {
+		conn = tls.Client(conn, &tls.Config{ServerName: n.host, MinVersion: tls.VersionTLS12})
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("failed to start the SMTP session: %w", err)
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok && n.port != smtpsPort {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host, MinVersion: tls.VersionTLS12}); err != nil {
+			_ = client.Close()
+
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	// PLAIN authentication is refused over unencrypted connections, except to localhost.
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			_ = client.Close()
+
+			return nil, fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func (n *EmailNotifier) send(client *smtp.Client, message []byte) error {
+	if err := client.Mail(n.sender); err != nil {
+		return fmt.Errorf("sender rejected: %w", err)
+	}
+
+	for _, recipient := range n.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start the message: %w", err)
+	}
+
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write the message: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("failed to end the SMTP session: %w", err)
+	}
+
+	return nil
+}
+
+// newMessage renders the notification as a multipart/alternative message with a plain text
+// and an HTML part. A body rendered by a template is sent as the plain text part only.
+func (n *EmailNotifier) newMessage(notification Notification, date time.Time) ([]byte, error) {
+	contents := []struct{ contentType, content string }{
+		{contentType: "text/plain; charset=utf-8", content: notification.Body + "\n"},
+	}
+
+	if notification.Body == "" {
+		var html bytes.Buffer
+		if err := emailDigestTemplate.Execute(&html, newEmailDigest(notification)); err != nil {
+			return nil, fmt.Errorf("failed to render the email digest: %w", err)
+		}
+
+		contents = []struct{ contentType, content string }{
+			{contentType: "text/plain; charset=utf-8", content: emailText(notification)},
+			{contentType: "text/html; charset=utf-8", content: html.String()},
+		}
+	}
+
+	var body bytes.Buffer
+
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range contents {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the message part: %w", err)
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		if _, err := io.WriteString(qp, part.content); err != nil {
+			return nil, fmt.Errorf("failed to write the message part: %w", err)
+		}
+
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write the message part: %w", err)
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write the message: %w", err)
+	}
+
+	var message bytes.Buffer
+
+	for _, header := range [][2]string{
+		{"From", n.from},
+		{"To", strings.Join(n.to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", notification.Title)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + parts.Boundary()},
+	} {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// newEmailDigest returns the data of the digest: the summary statistics and tables of the
+// changes and violations of the report, or the items of notifications without a report.
+func newEmailDigest(notification Notification) emailDigest {
+	digest := emailDigest{
+		Title:       notification.Title,
+		Summary:     notification.Summary,
+		ArtifactURL: notification.ArtifactURL,
+	}
+
+	report := notification.Report
+	if report == nil {
+		digest.Items = notification.Items[:min(len(notification.Items), notification.itemsLimit(emailMaxRows))]
+		digest.OmittedItems = len(notification.Items) - len(digest.Items)
+
+		return digest
+	}
+
+	digest.Stats = []emailStat{
+		{Name: "Assets scanned", Value: strconv.Itoa(report.Summary.TotalAssets)},
+		{Name: "Reserved addresses", Value: strconv.Itoa(report.Summary.ByStatus[addressStatusReserved])},
+		{Name: "Policy violations", Value: strconv.Itoa(len(report.Violations))},
+		{Name: "Changes", Value: strconv.Itoa(len(report.Diffs))},
+	}
+
+	if report.Summary.Cost != nil {
+		digest.Stats = append(digest.Stats, emailStat{
+			Name:  "Estimated monthly cost of idle addresses",
+			Value: fmt.Sprintf("$%.2f", report.Summary.Cost.MonthlyCost),
+		})
+	}
+
+	maxRows := notification.itemsLimit(emailMaxRows)
+
+	digest.Changes = report.Diffs[:min(len(report.Diffs), maxRows)]
+	digest.OmittedChanges = len(report.Diffs) - len(digest.Changes)
+	digest.Violations = report.Violations[:min(len(report.Violations), maxRows)]
+	digest.OmittedViolations = len(report.Violations) - len(digest.Violations)
+
+	return digest
+}
+
+// emailText renders the notification as the plain text alternative of the digest.
+func emailText(notification Notification) string {
+	var b strings.Builder
+
+	b.WriteString(notification.Title + "\n\n" + notification.Summary + "\n\n")
+
+	items := notification.Items[:min(len(notification.Items), notification.itemsLimit(emailMaxRows))]
+	for _, item := range items {
+		b.WriteString("- " + item + "\n")
+	}
+
+	if omitted := len(notification.Items) - len(items); omitted > 0 {
+		b.WriteString("\n" + moreItemsFooter(omitted, "") + "\n")
+	}
+
+	if notification.ArtifactURL != "" {
+		b.WriteString("\nFull report: " + notification.ArtifactURL + "\n")
+	}
+
+	return b.String()
+}
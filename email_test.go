@@ -0,0 +1,261 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts every message, recording the envelopes and the data.
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	commands   []string
+	messages   []string
+	rejectRcpt bool
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake ESMTP")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.TrimRight(line, "\r\n")
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		rejectRcpt := s.rejectRcpt
+		s.mu.Unlock()
+
+		switch verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0]); verb {
+		case "EHLO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			reply("235 authenticated")
+		case "RCPT":
+			if rejectRcpt {
+				reply("550 no such user")
+			} else {
+				reply("250 ok")
+			}
+		case "DATA":
+			reply("354 go ahead")
+
+			var data strings.Builder
+
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil || dataLine == ".\r\n" {
+					break
+				}
+
+				data.WriteString(dataLine)
+			}
+
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func newTestEmailNotifier(port int) *EmailNotifier {
+	return NewEmailNotifier(slog.New(slog.DiscardHandler), &Config{
+		SMTPHost:     "127.0.0.1",
+		SMTPPort:     port,
+		SMTPUsername: "asset-watcher",
+		SMTPPassword: "secret",
+		EmailFrom:    "Asset Watcher <asset-watcher@example.com>",
+		EmailTo:      "netops@example.com, Security <security@example.com>",
+	})
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := newTestEmailNotifier(server.port())
+
+	report := &Report{
+		Metadata: RunMetadata{OrgID: "123", RunID: "run-1"},
+		Summary:  Summary{TotalAssets: 3, ByStatus: map[string]int{addressStatusReserved: 2}},
+		Violations: []RuleViolation{{
+			Rule: ruleOrphanedExternalAddress, Severity: severityHigh, Message: "reserved <unused>",
+			Asset: ProcessedAsset{Name: "spare", IPAddress: "203.0.113.11", Project: "prod"},
+		}},
+		Diffs: []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "egress", IPAddress: "198.51.100.21", Project: "dev"}}},
+	}
+
+	if err := notifier.Notify(t.Context(), newNotification(report, "https://example.com/report.json")); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	commands := strings.Join(server.commands, "\n")
+	for _, want := range []string{
+		"AUTH PLAIN", "MAIL FROM:<asset-watcher@example.com>", "RCPT TO:<netops@example.com>", "RCPT TO:<security@example.com>",
+	} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("expected %q in the SMTP session:\n%s", want, commands)
+		}
+	}
+
+	if len(server.messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(server.messages))
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(server.messages[0]))
+	if err != nil {
+		t.Fatalf("failed to parse the message: %v", err)
+	}
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "asset-watcher: 1 violations and 1 changes in organization 123" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("failed to parse the content type: %v", err)
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("failed to read the message part: %v", err)
+		}
+
+		body, _ := io.ReadAll(part)
+		parts[strings.Split(part.Header.Get("Content-Type"), ";")[0]] = string(body)
+	}
+
+	for _, want := range []string{"Assets scanned", "egress", "198.51.100.21", "reserved &lt;unused&gt;", `href="https://example.com/report.json"`} {
+		if !strings.Contains(parts["text/html"], want) {
+			t.Errorf("expected %q in the HTML digest:\n%s", want, parts["text/html"])
+		}
+	}
+
+	if !strings.Contains(parts["text/plain"], "- added: egress 198.51.100.21 (dev)") {
+		t.Errorf("unexpected text part:\n%s", parts["text/plain"])
+	}
+}
+
+func TestEmailNotifier_NewMessageBody(t *testing.T) {
+	notification := Notification{Title: "custom subject", Items: []string{"item"}, Body: "custom body"}
+
+	message, err := newTestEmailNotifier(25).newMessage(notification, time.Now())
+	if err != nil {
+		t.Fatalf("newMessage failed: %v", err)
+	}
+
+	if !strings.Contains(string(message), "custom body") || strings.Contains(string(message), "text/html") {
+		t.Errorf("expected a plain text message of the body:\n%s", message)
+	}
+}
+
+func TestEmailNotifier_NotifyRejected(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectRcpt = true
+
+	err := newTestEmailNotifier(server.port()).Notify(t.Context(), Notification{Title: "title"})
+	if !errors.Is(err, errNotificationFailed) || !strings.Contains(err.Error(), "netops@example.com") {
+		t.Errorf("expected a rejected recipient, got %v", err)
+	}
+}
+
+func TestEmailNotifier_Check(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	if err := newTestEmailNotifier(server.port()).Check(t.Context()); err != nil {
+		t.Errorf("Check failed: %v", err)
+	}
+
+	// Nothing listens on the port of a closed listener.
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	if err := newTestEmailNotifier(port).Check(t.Context()); !errors.Is(err, errNotifierUnreachable) {
+		t.Errorf("expected %v, got %v", errNotifierUnreachable, err)
+	}
+}
+
+func TestNewEmailDigest_Limits(t *testing.T) {
+	report := &Report{Violations: make([]RuleViolation, emailMaxRows+3)}
+
+	digest := newEmailDigest(Notification{Report: report, Items: []string{"item"}, ArtifactURL: "https://example.com/r.json"})
+	if len(digest.Violations) != emailMaxRows || digest.OmittedViolations != 3 || digest.Items != nil {
+		t.Errorf("unexpected digest: %d violations, %d omitted, items %v",
+			len(digest.Violations), digest.OmittedViolations, digest.Items)
+	}
+
+	// Notifications of other sources than a report list their items.
+	digest = newEmailDigest(Notification{Items: []string{"item"}})
+	if len(digest.Items) != 1 || digest.Stats != nil {
+		t.Errorf("unexpected digest without a report: %+v", digest)
+	}
+}
@@ -0,0 +1,25 @@
+# Validates a planned address reservation against the existing allocations
+# with the Terraform external data source.
+data "external" "planned_address" {
+  program = ["asset-watcher", "terraform-data-source"]
+
+  query = {
+    ip_address = "203.0.113.10"
+    # Read the report of a scheduled run instead of scanning the organization.
+    report_url = "https://storage.googleapis.com/bucket/asset-watcher/report.json"
+  }
+}
+
+resource "google_compute_address" "planned" {
+  name         = "planned"
+  address      = "203.0.113.10"
+  address_type = "EXTERNAL"
+  region       = "us-central1"
+
+  lifecycle {
+    precondition {
+      condition     = data.external.planned_address.result.exists == "false"
+      error_message = "203.0.113.10 is already allocated to ${data.external.planned_address.result.name} in ${data.external.planned_address.result.project}."
+    }
+  }
+}
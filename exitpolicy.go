@@ -0,0 +1,182 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Conditions of the exit-code policy, checked in this order after the report is published.
+const (
+	exitConditionViolations = "violations"
+	exitConditionThreshold  = "threshold"
+	exitConditionChanges    = "changes"
+)
+
+const (
+	// exitCodeThreshold is the default exit code of a scan with a count above its threshold.
+	exitCodeThreshold = 4
+	// exitCodeChanges is the default exit code of a scan with changes when --fail-on-changes is set.
+	exitCodeChanges = 5
+)
+
+// defaultExitCodes are the exit codes of the conditions, overridden by ASSET_WATCHER_EXIT_CODES.
+var defaultExitCodes = map[string]int{
+	exitConditionViolations: exitCodeViolations,
+	exitConditionThreshold:  exitCodeThreshold,
+	exitConditionChanges:    exitCodeChanges,
+}
+
+// thresholdCounts are the counts of the report that ASSET_WATCHER_FAIL_THRESHOLDS can limit.
+var thresholdCounts = map[string]func(report *Report) int{
+	"assets":     func(report *Report) int { return len(report.Assets) },
+	"violations": func(report *Report) int { return len(report.Violations) },
+	"changes":    func(report *Report) int { return len(report.Diffs) },
+	"unused": func(report *Report) int {
+		unused := 0
+
+		for _, asset := range report.Assets {
+			if isIdleAddress(asset) {
+				unused++
+			}
+		}
+
+		return unused
+	},
+}
+
+var (
+	errInvalidExitCode      = errors.New("invalid exit code")
+	errInvalidFailThreshold = errors.New("invalid threshold")
+	errNoChangeDetection    = errors.New("failing on changes requires a snapshot, a state store, or a baseline")
+	errStreamedExitPolicy   = errors.New("the exit-code policy is not evaluated on the streamed ndjson output")
+)
+
+// exitDecision is the condition of the exit-code policy a report meets, and the exit code of
+// the run.
+type exitDecision struct {
+	condition string
+	code      int
+	reason    string
+}
+
+// parseExitCodes parses a comma-separated list of condition=code pairs, such as
+// "violations=10,changes=11", and returns the default exit codes with the overrides applied.
+// Codes 0, 1, and 3 are reserved for success, errors, and crashes.
+func parseExitCodes(s string) (map[string]int, error) {
+	codes := maps.Clone(defaultExitCodes)
+
+	for _, pair := range splitString(s, ",") {
+		condition, value, ok := strings.Cut(pair, "=")
+		condition = strings.TrimSpace(condition)
+
+		if _, known := codes[condition]; !ok || !known {
+			return nil, fmt.Errorf("%w: %s, expected condition=code with a condition of %s", errInvalidExitCode,
+				strconv.Quote(pair), strings.Join(slices.Sorted(maps.Keys(codes)), ", "))
+		}
+
+		code, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || code < 2 || code > 125 || code == exitCodeCrash {
+			return nil, fmt.Errorf("%w: %s, expected a code between 2 and 125 other than %d", errInvalidExitCode,
+				strconv.Quote(value), exitCodeCrash)
+		}
+
+		codes[condition] = code
+	}
+
+	return codes, nil
+}
+
+// parseFailThresholds parses a comma-separated list of count=max pairs, such as
+// "unused=5,changes=20", into the maximum of every count.
+func parseFailThresholds(s string) (map[string]int, error) {
+	thresholds := map[string]int{}
+
+	for _, pair := range splitString(s, ",") {
+		count, value, ok := strings.Cut(pair, "=")
+		count = strings.TrimSpace(count)
+
+		if _, known := thresholdCounts[count]; !ok || !known {
+			return nil, fmt.Errorf("%w: %s, expected count=max with a count of %s", errInvalidFailThreshold,
+				strconv.Quote(pair), strings.Join(slices.Sorted(maps.Keys(thresholdCounts)), ", "))
+		}
+
+		maximum, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || maximum < 0 {
+			return nil, fmt.Errorf("%w: %s, expected a non-negative maximum", errInvalidFailThreshold, strconv.Quote(value))
+		}
+
+		thresholds[count] = maximum
+	}
+
+	return thresholds, nil
+}
+
+// validateExitPolicy validates the exit-code policy of the configuration, which is also set by
+// the flags of a scan. The ndjson output streams the assets without a report to evaluate, so
+// its runs would always pass the policy.
+func validateExitPolicy(cfg *Config) error {
+	if _, err := parseExitCodes(cfg.ExitCodes); err != nil {
+		return err
+	}
+
+	if _, err := parseFailThresholds(cfg.FailThresholds); err != nil {
+		return err
+	}
+
+	if cfg.FailOnChanges && cfg.SnapshotPath == "" && cfg.StateStore == "" && cfg.BaselineFile == "" {
+		return errNoChangeDetection
+	}
+
+	if cfg.OutputFormat == outputFormatNDJSON && (cfg.FailOnViolation || cfg.FailOnChanges || cfg.FailThresholds != "") {
+		return fmt.Errorf("%w: --fail-on-violation, --fail-on-changes, and --fail-thresholds require another format",
+			errStreamedExitPolicy)
+	}
+
+	return nil
+}
+
+// evaluateExitPolicy returns the first condition of the exit-code policy the report meets:
+// policy violations with --fail-on-violation, a count above its threshold, or changes with
+// --fail-on-changes. The configuration must be valid.
+func evaluateExitPolicy(cfg *Config, report *Report) (exitDecision, bool) {
+	codes, _ := parseExitCodes(cfg.ExitCodes)
+	thresholds, _ := parseFailThresholds(cfg.FailThresholds)
+
+	if cfg.FailOnViolation && len(report.Violations) > 0 {
+		return exitDecision{
+			condition: exitConditionViolations,
+			code:      codes[exitConditionViolations],
+			reason:    fmt.Sprintf("%d policy violations found", len(report.Violations)),
+		}, true
+	}
+
+	exceeded := []string{}
+
+	for _, count := range slices.Sorted(maps.Keys(thresholds)) {
+		if n := thresholdCounts[count](report); n > thresholds[count] {
+			exceeded = append(exceeded, fmt.Sprintf("%s %d above %d", count, n, thresholds[count]))
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return exitDecision{
+			condition: exitConditionThreshold,
+			code:      codes[exitConditionThreshold],
+			reason:    "thresholds exceeded: " + strings.Join(exceeded, ", "),
+		}, true
+	}
+
+	if cfg.FailOnChanges && len(report.Diffs) > 0 {
+		return exitDecision{
+			condition: exitConditionChanges,
+			code:      codes[exitConditionChanges],
+			reason:    fmt.Sprintf("%d changes detected", len(report.Diffs)),
+		}, true
+	}
+
+	return exitDecision{}, false
+}
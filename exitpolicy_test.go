@@ -0,0 +1,133 @@
+package assetwatcher
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseExitCodes(t *testing.T) {
+	codes, err := parseExitCodes("changes=10, threshold = 11")
+	if err != nil {
+		t.Fatalf("parseExitCodes failed: %v", err)
+	}
+
+	if codes[exitConditionViolations] != exitCodeViolations || codes[exitConditionThreshold] != 11 ||
+		codes[exitConditionChanges] != 10 {
+		t.Errorf("unexpected exit codes: %v", codes)
+	}
+
+	for _, s := range []string{"drift=4", "changes", "changes=1", "changes=3", "changes=126", "changes=x"} {
+		if _, err := parseExitCodes(s); !errors.Is(err, errInvalidExitCode) {
+			t.Errorf("parseExitCodes(%q) error = %v, want %v", s, err, errInvalidExitCode)
+		}
+	}
+}
+
+func TestParseFailThresholds(t *testing.T) {
+	thresholds, err := parseFailThresholds("unused=0,assets=500")
+	if err != nil {
+		t.Fatalf("parseFailThresholds failed: %v", err)
+	}
+
+	if len(thresholds) != 2 || thresholds["unused"] != 0 || thresholds["assets"] != 500 {
+		t.Errorf("unexpected thresholds: %v", thresholds)
+	}
+
+	for _, s := range []string{"reserved=1", "unused", "unused=-1"} {
+		if _, err := parseFailThresholds(s); !errors.Is(err, errInvalidFailThreshold) {
+			t.Errorf("parseFailThresholds(%q) error = %v, want %v", s, err, errInvalidFailThreshold)
+		}
+	}
+}
+
+func TestValidateExitPolicy(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.FailOnChanges = true
+
+	if err := validateExitPolicy(&cfg); !errors.Is(err, errNoChangeDetection) {
+		t.Errorf("expected %v without change detection, got %v", errNoChangeDetection, err)
+	}
+
+	cfg.SnapshotPath = "snapshot.json"
+
+	if err := validateExitPolicy(&cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateExitPolicy_NDJSON(t *testing.T) {
+	for _, policy := range []func(cfg *Config){
+		func(cfg *Config) { cfg.FailOnViolation = true },
+		func(cfg *Config) { cfg.FailOnChanges, cfg.SnapshotPath = true, "snapshot.json" },
+		func(cfg *Config) { cfg.FailThresholds = "unused=5" },
+	} {
+		cfg := ConfigDefaults
+		cfg.OutputFormat = outputFormatNDJSON
+		policy(&cfg)
+
+		if err := validateExitPolicy(&cfg); !errors.Is(err, errStreamedExitPolicy) {
+			t.Errorf("expected %v with the ndjson output, got %v", errStreamedExitPolicy, err)
+		}
+	}
+
+	cfg := ConfigDefaults
+	cfg.OutputFormat = outputFormatNDJSON
+
+	if err := validateExitPolicy(&cfg); err != nil {
+		t.Errorf("unexpected error without an exit-code policy: %v", err)
+	}
+}
+
+func TestEvaluateExitPolicy(t *testing.T) {
+	report := &Report{
+		Assets: []ProcessedAsset{
+			{Name: "a1", Status: addressStatusReserved, AddressType: "EXTERNAL"},
+			{Name: "a2", Status: addressStatusReserved, AddressType: "EXTERNAL"},
+			{Name: "a3", Status: addressStatusInUse, AddressType: "EXTERNAL"},
+		},
+		Violations: []RuleViolation{{Rule: ruleOrphanedExternalAddress}},
+		Diffs:      []AssetDiff{{}},
+	}
+
+	tests := []struct {
+		name          string
+		configure     func(cfg *Config)
+		wantCondition string
+		wantCode      int
+	}{
+		{name: "none", configure: func(*Config) {}},
+		{
+			name: "violations first",
+			configure: func(cfg *Config) {
+				cfg.FailOnViolation, cfg.FailOnChanges, cfg.FailThresholds = true, true, "unused=1"
+			},
+			wantCondition: exitConditionViolations, wantCode: exitCodeViolations,
+		},
+		{
+			name:          "threshold",
+			configure:     func(cfg *Config) { cfg.FailThresholds, cfg.FailOnChanges = "unused=1", true },
+			wantCondition: exitConditionThreshold, wantCode: exitCodeThreshold,
+		},
+		{
+			name:      "threshold not exceeded",
+			configure: func(cfg *Config) { cfg.FailThresholds = "unused=2,assets=3" },
+		},
+		{
+			name:          "changes with a configured code",
+			configure:     func(cfg *Config) { cfg.FailOnChanges, cfg.ExitCodes = true, "changes=20" },
+			wantCondition: exitConditionChanges, wantCode: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ConfigDefaults
+			tt.configure(&cfg)
+
+			decision, ok := evaluateExitPolicy(&cfg, report)
+			if ok != (tt.wantCondition != "") || decision.condition != tt.wantCondition || decision.code != tt.wantCode {
+				t.Errorf("evaluateExitPolicy() = %+v, %v, want %s, %d", decision, ok, tt.wantCondition, tt.wantCode)
+			}
+		})
+	}
+}
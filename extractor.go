@@ -0,0 +1,164 @@
+package assetwatcher
+
+import (
+	"strings"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	addressAssetType        = "compute.googleapis.com/Address"
+	instanceAssetType       = "compute.googleapis.com/Instance"
+	forwardingRuleAssetType = "compute.googleapis.com/ForwardingRule"
+)
+
+// column is a column of the table output.
+type column struct {
+	header string
+	value  func(asset ProcessedAsset) string
+}
+
+// assetExtractor extracts type-specific attributes of an asset and defines
+// the columns used to render assets of that type.
+type assetExtractor struct {
+	columns []column
+	extract func(asset *assetpb.ResourceSearchResult) map[string]string
+}
+
+var (
+	nameColumn      = column{header: "Display Name", value: func(a ProcessedAsset) string { return a.Name }}
+	locationColumn  = column{header: "Location", value: func(a ProcessedAsset) string { return a.Location }}
+	projectColumn   = column{header: "Project ID", value: func(a ProcessedAsset) string { return a.Project }}
+	ipAddressColumn = column{header: "IP Address", value: func(a ProcessedAsset) string { return a.IPAddress }}
+	stateColumn     = column{header: "State", value: func(a ProcessedAsset) string { return a.Status }}
+	createdAtColumn = column{header: "Created At", value: func(a ProcessedAsset) string { return a.CreatedAt }}
+)
+
+// attributeColumn returns a column rendering a type-specific attribute of the asset.
+func attributeColumn(header, key string) column {
+	return column{header: header, value: func(a ProcessedAsset) string {
+		if v, ok := a.Attributes[key]; ok && v != "" {
+			return v
+		}
+
+		return "N/A"
+	}}
+}
+
+// extractors is the registry of the supported asset types.
+var extractors = map[string]assetExtractor{
+	addressAssetType: {
+		columns: []column{
+			nameColumn, locationColumn, projectColumn, ipAddressColumn,
+			attributeColumn("Network Tier", "networkTier"), attributeColumn("Purpose", "purpose"),
+			attributeColumn("Users", "users"), stateColumn, createdAtColumn,
+		},
+		extract: func(asset *assetpb.ResourceSearchResult) map[string]string {
+			attributes := map[string]string{
+				"networkTier": getStringAttribute(asset, "networkTier", ""),
+			}
+
+			// Purpose and users are set only if present, so that missing attributes
+			// can be fetched by the describe fallback.
+			if hasAttribute(asset, "purpose") {
+				attributes["purpose"] = getStringAttribute(asset, "purpose", "")
+			}
+
+			if hasAttribute(asset, "users") {
+				attributes["users"] = joinLastPathSegments(strings.Split(getListAttribute(asset, "users"), ","))
+			}
+
+			return attributes
+		},
+	},
+	instanceAssetType: {
+		columns: []column{
+			nameColumn, attributeColumn("Zone", "zone"), projectColumn, attributeColumn("Machine Type", "machineType"),
+			attributeColumn("Internal IPs", "internalIPs"), attributeColumn("External IPs", "externalIPs"),
+			stateColumn, createdAtColumn,
+		},
+		extract: func(asset *assetpb.ResourceSearchResult) map[string]string {
+			return map[string]string{
+				"zone":        asset.GetLocation(),
+				"machineType": lastPathSegment(getStringAttribute(asset, "machineType", "")),
+				"internalIPs": getListAttribute(asset, "internalIPs"),
+				"externalIPs": getListAttribute(asset, "externalIPs"),
+				"networkTags": strings.Join(asset.GetNetworkTags(), ","),
+			}
+		},
+	},
+	forwardingRuleAssetType: {
+		columns: []column{
+			nameColumn, locationColumn, projectColumn, ipAddressColumn,
+			attributeColumn("Load Balancing Scheme", "loadBalancingScheme"), stateColumn, createdAtColumn,
+		},
+		extract: func(asset *assetpb.ResourceSearchResult) map[string]string {
+			return map[string]string{
+				"loadBalancingScheme": getStringAttribute(asset, "loadBalancingScheme", ""),
+			}
+		},
+	},
+}
+
+// genericExtractor is used for asset types without a registered extractor.
+var genericExtractor = assetExtractor{
+	columns: []column{nameColumn, locationColumn, projectColumn, ipAddressColumn, stateColumn, createdAtColumn},
+	extract: func(_ *assetpb.ResourceSearchResult) map[string]string { return nil },
+}
+
+// extractorFor returns the extractor registered for the asset type.
+func extractorFor(assetType string) assetExtractor {
+	if extractor, ok := extractors[assetType]; ok {
+		return extractor
+	}
+
+	return genericExtractor
+}
+
+// getListAttribute returns a comma-separated string of the list attribute of the asset,
+// or an empty string if the attribute is absent or is not a list.
+func getListAttribute(asset *assetpb.ResourceSearchResult, key string) string {
+	field, ok := asset.GetAdditionalAttributes().GetFields()[key]
+	if !ok || field == nil {
+		return ""
+	}
+
+	lv, ok := field.GetKind().(*structpb.Value_ListValue)
+	if !ok {
+		return ""
+	}
+
+	values := make([]string, 0, len(lv.ListValue.GetValues()))
+	for _, v := range lv.ListValue.GetValues() {
+		if s := v.GetStringValue(); s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return strings.Join(values, ",")
+}
+
+// hasAttribute reports whether the asset has the additional attribute.
+func hasAttribute(asset *assetpb.ResourceSearchResult, key string) bool {
+	_, ok := asset.GetAdditionalAttributes().GetFields()[key]
+
+	return ok
+}
+
+// joinLastPathSegments returns a comma-separated string of the last path segments
+// of the resource URLs, such as the names of the resources using an address.
+func joinLastPathSegments(urls []string) string {
+	names := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if url != "" {
+			names = append(names, lastPathSegment(url))
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+func lastPathSegment(s string) string {
+	return s[strings.LastIndex(s, "/")+1:]
+}
@@ -0,0 +1,83 @@
+package assetwatcher
+
+import (
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestGetListAttribute(t *testing.T) {
+	list, _ := structpb.NewList([]any{"10.0.0.1", "", "10.0.0.2"})
+
+	tests := []struct {
+		name  string
+		asset *assetpb.ResourceSearchResult
+		want  string
+	}{
+		{name: "list attribute", asset: &assetpb.ResourceSearchResult{AdditionalAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{"internalIPs": structpb.NewListValue(list)}}}, want: "10.0.0.1,10.0.0.2"},
+		{name: "not a list", asset: &assetpb.ResourceSearchResult{AdditionalAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{"internalIPs": structpb.NewStringValue("10.0.0.1")}}}, want: ""},
+		{name: "absent attribute", asset: &assetpb.ResourceSearchResult{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getListAttribute(tt.asset, "internalIPs"); got != tt.want {
+				t.Errorf("getListAttribute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractorFor_Instance(t *testing.T) {
+	externalIPs, _ := structpb.NewList([]any{"34.1.2.3"})
+	asset := &assetpb.ResourceSearchResult{
+		AssetType:   instanceAssetType,
+		Location:    "us-central1-a",
+		NetworkTags: []string{"web", "ssh"},
+		AdditionalAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"machineType": structpb.NewStringValue("https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/machineTypes/e2-small"),
+			"externalIPs": structpb.NewListValue(externalIPs),
+		}},
+	}
+
+	want := map[string]string{
+		"zone":        "us-central1-a",
+		"machineType": "e2-small",
+		"internalIPs": "",
+		"externalIPs": "34.1.2.3",
+		"networkTags": "web,ssh",
+	}
+
+	if got := extractorFor(asset.GetAssetType()).extract(asset); !reflect.DeepEqual(got, want) {
+		t.Errorf("extract() = %v, want %v", got, want)
+	}
+}
+
+func TestExtractorFor_Address(t *testing.T) {
+	users, _ := structpb.NewList([]any{"https://www.googleapis.com/compute/v1/projects/p/regions/r/forwardingRules/fr-1"})
+	withUsers := &assetpb.ResourceSearchResult{AdditionalAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{
+		"networkTier": structpb.NewStringValue("PREMIUM"),
+		"purpose":     structpb.NewStringValue(""),
+		"users":       structpb.NewListValue(users),
+	}}}
+
+	want := map[string]string{"networkTier": "PREMIUM", "purpose": "", "users": "fr-1"}
+	if got := extractorFor(addressAssetType).extract(withUsers); !reflect.DeepEqual(got, want) {
+		t.Errorf("extract() = %v, want %v", got, want)
+	}
+
+	// Attributes omitted by Cloud Asset Inventory are left out for the describe fallback.
+	got := extractorFor(addressAssetType).extract(&assetpb.ResourceSearchResult{})
+	if _, ok := got["users"]; ok {
+		t.Errorf("expected no users attribute, got %v", got)
+	}
+}
+
+func TestExtractorFor_Unknown(t *testing.T) {
+	extractor := extractorFor("example.googleapis.com/Unknown")
+	if len(extractor.columns) != len(genericExtractor.columns) {
+		t.Errorf("expected generic columns for an unknown asset type, got %d columns", len(extractor.columns))
+	}
+}
@@ -1,18 +1,27 @@
-package main
+package assetwatcher
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 
 	asset "cloud.google.com/go/asset/apiv1"
 	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
+// assetPrefetch is the number of assets fetched ahead of the merge from every asset type,
+// which is the maximum page size of SearchAllResources.
+const assetPrefetch = 500
+
 // Fetcher is an interface for fetching assets.
 type Fetcher interface {
-	FetchAssets(ctx context.Context) *asset.ResourceSearchResultIterator
+	FetchAssets(ctx context.Context) AssetIterator
 	Close() error
 }
 
@@ -43,16 +52,196 @@ func NewGoogleAssetFetcher(
 }
 
 // FetchAssets fetches the assets from Google Cloud Asset API.
-func (f *GoogleAssetFetcher) FetchAssets(ctx context.Context) *asset.ResourceSearchResultIterator {
+func (f *GoogleAssetFetcher) FetchAssets(ctx context.Context) AssetIterator {
+	return f.fetch(ctx, "organizations/"+f.cfg.OrgID)
+}
+
+// fetch searches the configured asset types of the scope. Several asset types are searched
+// in parallel and merged in the order of a single search, by project and then name, so that
+// the processing and the output remain deterministic.
+func (f *GoogleAssetFetcher) fetch(ctx context.Context, scope string) AssetIterator {
+	assetTypes := f.assetTypes()
+	if len(assetTypes) == 1 {
+		return f.search(ctx, scope, assetTypes)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	sources := make([]AssetIterator, 0, len(assetTypes))
+	for _, assetType := range assetTypes {
+		sources = append(sources, f.search(ctx, scope, []string{assetType}))
+	}
+
+	return newMergedAssetIterator(ctx, cancel, sources)
+}
+
+// assetTypes returns the configured asset types, defaulting to addresses.
+func (f *GoogleAssetFetcher) assetTypes() []string {
+	assetTypes := splitString(f.cfg.AssetTypes, ",")
+	if len(assetTypes) == 0 {
+		assetTypes = []string{addressAssetType}
+	}
+
+	return assetTypes
+}
+
+func (f *GoogleAssetFetcher) search(
+	ctx context.Context,
+	scope string,
+	assetTypes []string,
+) *asset.ResourceSearchResultIterator {
 	req := &assetpb.SearchAllResourcesRequest{
-		Scope:      "organizations/" + f.cfg.OrgID,
+		Scope:      scope,
 		OrderBy:    "project,name",
-		AssetTypes: []string{"compute.googleapis.com/Address"},
+		AssetTypes: assetTypes,
+	}
+
+	return f.client.SearchAllResources(ctx, req)
+}
+
+// SearchAddresses returns the full resource names of the addresses of the organization
+// with the IP address. Cloud Asset Inventory matches the query as free text, so the
+// results are filtered on the exact address.
+func (f *GoogleAssetFetcher) SearchAddresses(ctx context.Context, ip string) ([]string, error) {
+	it := f.client.SearchAllResources(ctx, &assetpb.SearchAllResourcesRequest{
+		Scope:      "organizations/" + f.cfg.OrgID,
+		Query:      strconv.Quote(ip),
+		AssetTypes: []string{addressAssetType},
+	})
+
+	names := []string{}
+
+	for {
+		result, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return names, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to search addresses: %w", err)
+		}
+
+		if getIPAddress(result) == ip {
+			names = append(names, result.GetName())
+		}
+	}
+}
+
+// mergedAssetIterator merges ordered asset iterators into a single ordered stream with a
+// k-way merge. Every source is read ahead in its own goroutine, so that the searches run
+// in parallel.
+type mergedAssetIterator struct {
+	ctx     context.Context //nolint:containedctx // The context of the sources, to report their cancellation.
+	sources []<-chan assetResult
+	heads   []*assetpb.ResourceSearchResult
+	done    []bool
+	cancel  context.CancelFunc
+	err     error
+}
+
+type assetResult struct {
+	asset *assetpb.ResourceSearchResult
+	err   error
+}
+
+// newMergedAssetIterator starts reading the sources. The context must be canceled by cancel,
+// which is called once the iteration ends, to stop the sources left.
+func newMergedAssetIterator(ctx context.Context, cancel context.CancelFunc, sources []AssetIterator) *mergedAssetIterator {
+	it := &mergedAssetIterator{
+		ctx:     ctx,
+		sources: make([]<-chan assetResult, len(sources)),
+		heads:   make([]*assetpb.ResourceSearchResult, len(sources)),
+		done:    make([]bool, len(sources)),
+		cancel:  cancel,
 	}
 
-	assets := f.client.SearchAllResources(ctx, req)
+	for i, source := range sources {
+		ch := make(chan assetResult, assetPrefetch)
+		it.sources[i] = ch
+
+		go func() {
+			// A source canceled before sending its last result closes its channel instead.
+			defer close(ch)
+
+			for {
+				asset, err := source.Next()
+
+				select {
+				case ch <- assetResult{asset: asset, err: err}:
+				case <-ctx.Done():
+					return
+				}
+
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	return it
+}
+
+// Next returns the first of the next assets of the sources. Ties are broken by the order of
+// the sources. An error of any source, or the cancellation of the context, ends the iteration.
+func (it *mergedAssetIterator) Next() (*assetpb.ResourceSearchResult, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+
+	first := -1
+
+	for i, source := range it.sources {
+		if it.done[i] {
+			continue
+		}
+
+		if it.heads[i] == nil {
+			result, ok := <-source
+			if !ok {
+				return nil, it.stop(it.ctx.Err())
+			}
+
+			if errors.Is(result.err, iterator.Done) {
+				it.done[i] = true
+
+				continue
+			}
+
+			if result.err != nil {
+				return nil, it.stop(result.err)
+			}
+
+			it.heads[i] = result.asset
+		}
+
+		if first < 0 || compareAssetOrder(it.heads[i], it.heads[first]) < 0 {
+			first = i
+		}
+	}
+
+	if first < 0 {
+		return nil, it.stop(iterator.Done)
+	}
+
+	asset := it.heads[first]
+	it.heads[first] = nil
+
+	return asset, nil
+}
+
+// stop ends the iteration with the error and stops the sources.
+func (it *mergedAssetIterator) stop(err error) error {
+	it.err = err
+	it.cancel()
+
+	return err
+}
 
-	return assets
+// compareAssetOrder compares assets in the order of SearchAllResources with OrderBy
+// "project,name".
+func compareAssetOrder(a, b *assetpb.ResourceSearchResult) int {
+	return cmp.Or(strings.Compare(a.GetProject(), b.GetProject()), strings.Compare(a.GetName(), b.GetName()))
 }
 
 // Close closes the asset client.
@@ -1,4 +1,4 @@
-package main
+package assetwatcher
 
 import (
 	"context"
@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"net"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/asset/apiv1/assetpb"
 	"google.golang.org/api/iterator"
@@ -151,3 +153,134 @@ func TestFetchAssets_WithFakeServer(t *testing.T) {
 		t.Errorf("expected to find %d asset(s), found %d", len(expectedAssets), assetsFound)
 	}
 }
+
+func TestMergedAssetIterator(t *testing.T) {
+	asset := func(project, name string) *assetpb.ResourceSearchResult {
+		return &assetpb.ResourceSearchResult{Project: project, Name: name}
+	}
+
+	tests := []struct {
+		name    string
+		sources [][]*assetpb.ResourceSearchResult
+		want    []string
+	}{
+		{
+			name: "interleaved sources",
+			sources: [][]*assetpb.ResourceSearchResult{
+				{asset("projects/1", "a"), asset("projects/1", "d"), asset("projects/2", "b")},
+				{asset("projects/1", "b"), asset("projects/2", "a"), asset("projects/3", "a")},
+				{asset("projects/1", "c")},
+			},
+			want: []string{"projects/1/a", "projects/1/b", "projects/1/c", "projects/1/d", "projects/2/a", "projects/2/b", "projects/3/a"},
+		},
+		{
+			name: "ties in source order",
+			sources: [][]*assetpb.ResourceSearchResult{
+				{asset("projects/1", "a")},
+				{asset("projects/1", "a")},
+			},
+			want: []string{"projects/1/a", "projects/1/a"},
+		},
+		{
+			name:    "empty sources",
+			sources: [][]*assetpb.ResourceSearchResult{{}, {asset("projects/1", "a")}, {}},
+			want:    []string{"projects/1/a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(t.Context())
+
+			sources := make([]AssetIterator, 0, len(tt.sources))
+			for _, assets := range tt.sources {
+				sources = append(sources, &mockAssetIterator{assets: assets})
+			}
+
+			it := newMergedAssetIterator(ctx, cancel, sources)
+
+			var got []string
+
+			for {
+				asset, err := it.Next()
+				if errors.Is(err, iterator.Done) {
+					break
+				}
+
+				if err != nil {
+					t.Fatalf("Next() failed: %v", err)
+				}
+
+				got = append(got, asset.GetProject()+"/"+asset.GetName())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged assets = %v, want %v", got, tt.want)
+			}
+
+			if ctx.Err() == nil {
+				t.Error("context not canceled at the end of the iteration")
+			}
+		})
+	}
+}
+
+func TestMergedAssetIterator_Error(t *testing.T) {
+	errSearch := errors.New("search failed")
+	ctx, cancel := context.WithCancel(t.Context())
+
+	it := newMergedAssetIterator(ctx, cancel, []AssetIterator{
+		&mockAssetIterator{assets: []*assetpb.ResourceSearchResult{{Project: "projects/1", Name: "a"}}},
+		&mockAssetIterator{err: errSearch},
+	})
+
+	for range 2 {
+		if _, err := it.Next(); !errors.Is(err, errSearch) {
+			t.Fatalf("Next() error = %v, want %v", err, errSearch)
+		}
+	}
+
+	if ctx.Err() == nil {
+		t.Error("context not canceled after the error")
+	}
+}
+
+func TestMergedAssetIterator_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	it := newMergedAssetIterator(ctx, cancel, []AssetIterator{endlessAssetIterator{}, endlessAssetIterator{}})
+
+	if _, err := it.Next(); err != nil {
+		t.Fatalf("Next() failed: %v", err)
+	}
+
+	cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		for {
+			if _, err := it.Next(); err != nil {
+				done <- err
+
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Next() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next() blocked after the cancellation")
+	}
+}
+
+// endlessAssetIterator returns the same asset forever.
+type endlessAssetIterator struct{}
+
+func (endlessAssetIterator) Next() (*assetpb.ResourceSearchResult, error) {
+	return &assetpb.ResourceSearchResult{Project: "projects/1", Name: "a"}, nil
+}
@@ -0,0 +1,224 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+var errNonBooleanExpression = errors.New("filter expression must evaluate to a boolean")
+
+// regexFilter is a pair of optional include and exclude regular expressions.
+type regexFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// newRegexFilter compiles the include and exclude regular expressions.
+// Empty expressions are not applied.
+func newRegexFilter(include, exclude string) (regexFilter, error) {
+	var (
+		f   regexFilter
+		err error
+	)
+
+	if include != "" {
+		if f.include, err = regexp.Compile(include); err != nil {
+			return regexFilter{}, fmt.Errorf("failed to compile %q: %w", include, err)
+		}
+	}
+
+	if exclude != "" {
+		if f.exclude, err = regexp.Compile(exclude); err != nil {
+			return regexFilter{}, fmt.Errorf("failed to compile %q: %w", exclude, err)
+		}
+	}
+
+	return f, nil
+}
+
+// matches reports whether the value matches the include expression and does not match
+// the exclude expression.
+func (f regexFilter) matches(value string) bool {
+	if f.include != nil && !f.include.MatchString(value) {
+		return false
+	}
+
+	return f.exclude == nil || !f.exclude.MatchString(value)
+}
+
+// assetRegexFilters are the regular expression filters evaluated against processed assets.
+type assetRegexFilters struct {
+	name     regexFilter
+	project  regexFilter
+	location regexFilter
+}
+
+// newAssetRegexFilters compiles the regular expression filters of the configuration.
+func newAssetRegexFilters(cfg *Config) (assetRegexFilters, error) {
+	name, err := newRegexFilter(cfg.NameRegex, cfg.ExcludeNameRegex)
+	if err != nil {
+		return assetRegexFilters{}, fmt.Errorf("invalid name regex: %w", err)
+	}
+
+	project, err := newRegexFilter(cfg.ProjectRegex, cfg.ExcludeProjectRegex)
+	if err != nil {
+		return assetRegexFilters{}, fmt.Errorf("invalid project regex: %w", err)
+	}
+
+	location, err := newRegexFilter(cfg.LocationRegex, cfg.ExcludeLocationRegex)
+	if err != nil {
+		return assetRegexFilters{}, fmt.Errorf("invalid location regex: %w", err)
+	}
+
+	return assetRegexFilters{name: name, project: project, location: location}, nil
+}
+
+// matches reports whether the asset passes all regular expression filters.
+func (f assetRegexFilters) matches(asset ProcessedAsset) bool {
+	return f.name.matches(asset.Name) && f.project.matches(asset.Project) && f.location.matches(asset.Location)
+}
+
+// celFilter is a CEL expression evaluated against each processed asset.
+// https://github.com/google/cel-spec
+type celFilter struct {
+	program cel.Program
+}
+
+// newCELFilter compiles the CEL expression. The expression must evaluate to a boolean
+// and can refer to the processed asset fields through the asset variable, for example:
+// asset.status == 'RESERVED' && asset.location.startsWith('europe-').
+// An empty expression results in a nil filter that matches all assets.
+func newCELFilter(expr string) (*celFilter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil //nolint:nilnil // No filter is configured.
+	}
+
+	env, err := cel.NewEnv(cel.Variable("asset", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile %q: %w", expr, issues.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("%w: %q evaluates to %v", errNonBooleanExpression, expr, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL program for %q: %w", expr, err)
+	}
+
+	return &celFilter{program: program}, nil
+}
+
+// matches evaluates the expression against the asset.
+func (f *celFilter) matches(asset ProcessedAsset) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	out, _, err := f.program.Eval(map[string]any{"asset": celAsset(asset)})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate filter expression: %w", err)
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: got %v", errNonBooleanExpression, out.Type())
+	}
+
+	return result, nil
+}
+
+// celAsset converts the asset to the map exposed as the asset variable, keyed by the
+// JSON field names. Optional fields are always present to simplify expressions.
+func celAsset(asset ProcessedAsset) map[string]any {
+	labels := asset.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	attributes := asset.Attributes
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+
+	return map[string]any{
+		"name":                 asset.Name,
+		"location":             asset.Location,
+		"status":               asset.Status,
+		"ipAddress":            asset.IPAddress,
+		"project":              asset.Project,
+		"createdAt":            asset.CreatedAt,
+		"resourceName":         asset.ResourceName,
+		"assetType":            asset.AssetType,
+		"addressType":          asset.AddressType,
+		"estimatedMonthlyCost": asset.EstimatedMonthlyCost,
+		"labels":               labels,
+		"attributes":           attributes,
+	}
+}
+
+// ageFilter keeps assets created within an optional minimum and maximum age.
+type ageFilter struct {
+	min time.Duration
+	max time.Duration
+}
+
+// newAgeFilter parses the minimum and maximum ages. Empty ages are not applied.
+func newAgeFilter(minAge, maxAge string) (ageFilter, error) {
+	var (
+		f   ageFilter
+		err error
+	)
+
+	if f.min, err = parseAge(minAge); err != nil {
+		return ageFilter{}, fmt.Errorf("invalid minimum age: %w", err)
+	}
+
+	if f.max, err = parseAge(maxAge); err != nil {
+		return ageFilter{}, fmt.Errorf("invalid maximum age: %w", err)
+	}
+
+	if f.min > 0 && f.max > 0 && f.min > f.max {
+		return ageFilter{}, fmt.Errorf("%w: minimum age %s is greater than maximum age %s", errInvalidAge, minAge, maxAge)
+	}
+
+	return f, nil
+}
+
+// matches reports whether the asset age is within the limits. Assets without
+// a known creation time do not match if any limit is set.
+func (f ageFilter) matches(asset ProcessedAsset, now time.Time) bool {
+	if f.min == 0 && f.max == 0 {
+		return true
+	}
+
+	age, ok := assetAge(asset, now)
+	if !ok {
+		return false
+	}
+
+	return (f.min == 0 || age >= f.min) && (f.max == 0 || age <= f.max)
+}
+
+// formatAge formats the asset age as whole days, such as 93d, or as whole hours
+// for assets younger than a day.
+func formatAge(age time.Duration) string {
+	day := hoursPerDay * time.Hour
+	if age < day {
+		return strconv.Itoa(int(age/time.Hour)) + "h"
+	}
+
+	return strconv.Itoa(int(age/day)) + "d"
+}
@@ -0,0 +1,225 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+)
+
+func TestRegexFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		value   string
+		want    bool
+	}{
+		{name: "no expressions", value: "anything", want: true},
+		{name: "include matches", include: "^prod-", value: "prod-network", want: true},
+		{name: "include does not match", include: "^prod-", value: "dev-network", want: false},
+		{name: "exclude matches", exclude: "sandbox", value: "team-sandbox-1", want: false},
+		{name: "include and exclude", include: "^prod-", exclude: "-legacy$", value: "prod-network-legacy", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newRegexFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("newRegexFilter failed: %v", err)
+			}
+
+			if got := f.matches(tt.value); got != tt.want {
+				t.Errorf("matches(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAssetRegexFilters_Invalid(t *testing.T) {
+	for _, cfg := range []*Config{
+		{NameRegex: "("},
+		{ExcludeProjectRegex: "[a-"},
+		{LocationRegex: "*"},
+	} {
+		if _, err := newAssetRegexFilters(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestProcessAssets_RegexFilters(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+	baseTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	assets := []*assetpb.ResourceSearchResult{
+		createTestAsset("nat-1", "prod-network", "IN_USE", "1.1.1.1", baseTime),
+		createTestAsset("lb-1", "prod-web", "IN_USE", "2.2.2.2", baseTime),
+		createTestAsset("nat-2", "sandbox-alice", "RESERVED", "3.3.3.3", baseTime),
+	}
+	assets[1].Location = "europe-west1"
+
+	tests := []struct {
+		name  string
+		cfg   *Config
+		names []string
+	}{
+		{name: "name regex", cfg: &Config{NameRegex: "^nat-"}, names: []string{"nat-1", "nat-2"}},
+		{name: "exclude project regex", cfg: &Config{ExcludeProjectRegex: "^sandbox-"}, names: []string{"nat-1", "lb-1"}},
+		{name: "location regex", cfg: &Config{LocationRegex: "^europe-"}, names: []string{"lb-1"}},
+		{name: "combined", cfg: &Config{NameRegex: "^nat-", ProjectRegex: "^prod-"}, names: []string{"nat-1"}},
+		{name: "filter expression", cfg: &Config{FilterExpr: "asset.status == 'RESERVED' || asset.ipAddress == '2.2.2.2'"}, names: []string{"lb-1", "nat-2"}},
+		{name: "min age", cfg: &Config{MinAge: "1d"}, names: []string{"nat-1", "lb-1", "nat-2"}},
+		{name: "max age", cfg: &Config{MaxAge: "1d"}, names: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewAssetProcessor(ctx, logger, tt.cfg)
+
+			results, err := processor.ProcessAssets(ctx, &mockAssetIterator{assets: assets})
+			if err != nil {
+				t.Fatalf("ProcessAssets failed: %v", err)
+			}
+
+			names := make([]string, 0, len(results))
+			for _, result := range results {
+				names = append(names, result.Name)
+			}
+
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("ProcessAssets() returned %v, want %v", names, tt.names)
+			}
+		})
+	}
+}
+
+func TestCELFilter_Matches(t *testing.T) {
+	asset := ProcessedAsset{
+		Name:     "nat-1",
+		Status:   "RESERVED",
+		Location: "europe-west1",
+		Labels:   map[string]string{"env": "prod"},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "empty expression", expr: "", want: true},
+		{name: "status and location", expr: "asset.status == 'RESERVED' && asset.location.startsWith('europe-')", want: true},
+		{name: "label", expr: "asset.labels.env == 'prod'", want: true},
+		{name: "label presence", expr: "'team' in asset.labels", want: false},
+		{name: "missing attribute key", expr: "asset.attributes.zone == 'a'", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newCELFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("newCELFilter failed: %v", err)
+			}
+
+			got, err := f.matches(asset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matches() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCELFilter_Invalid(t *testing.T) {
+	for _, expr := range []string{"asset.status ==", "1 + 2", "unknown.field == 1"} {
+		if _, err := newCELFilter(expr); err == nil {
+			t.Errorf("expected an error for %q", expr)
+		}
+	}
+}
+
+func TestAgeFilter_Matches(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	old := ProcessedAsset{CreatedAt: now.Add(-93 * 24 * time.Hour).Format(createdAtLayout)}
+	recent := ProcessedAsset{CreatedAt: now.Add(-2 * time.Hour).Format(createdAtLayout)}
+	unknown := ProcessedAsset{CreatedAt: "N/A"}
+
+	tests := []struct {
+		name           string
+		minAge, maxAge string
+		asset          ProcessedAsset
+		want           bool
+	}{
+		{name: "no limits", asset: unknown, want: true},
+		{name: "older than min", minAge: "90d", asset: old, want: true},
+		{name: "younger than min", minAge: "90d", asset: recent, want: false},
+		{name: "younger than max", maxAge: "24h", asset: recent, want: true},
+		{name: "older than max", maxAge: "24h", asset: old, want: false},
+		{name: "unknown creation time", minAge: "1h", asset: unknown, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newAgeFilter(tt.minAge, tt.maxAge)
+			if err != nil {
+				t.Fatalf("newAgeFilter failed: %v", err)
+			}
+
+			if got := f.matches(tt.asset, now); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAgeFilter_Invalid(t *testing.T) {
+	for _, ages := range [][2]string{{"ninety days", ""}, {"", "-1h"}, {"90d", "30d"}} {
+		if _, err := newAgeFilter(ages[0], ages[1]); err == nil {
+			t.Errorf("expected an error for min %q and max %q", ages[0], ages[1])
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 93*24*time.Hour + 5*time.Hour, want: "93d"},
+		{age: 24 * time.Hour, want: "1d"},
+		{age: 5*time.Hour + 30*time.Minute, want: "5h"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestProcessAssets_ShowAge(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	assets := []*assetpb.ResourceSearchResult{
+		createTestAsset("old", "proj", "RESERVED", "1.1.1.1", time.Now().Add(-93*24*time.Hour-time.Hour)),
+	}
+
+	processor := NewAssetProcessor(ctx, logger, &Config{ShowAge: true})
+
+	results, err := processor.ProcessAssets(ctx, &mockAssetIterator{assets: assets})
+	if err != nil {
+		t.Fatalf("ProcessAssets failed: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Age != "93d" {
+		t.Errorf("expected age 93d, got %+v", results)
+	}
+}
@@ -0,0 +1,228 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const firewallAssetType = "compute.googleapis.com/Firewall"
+
+// defaultSensitivePorts are ports of remote administration, database, and cache services
+// that should never be reachable from the internet.
+const defaultSensitivePorts = "22,23,445,1433,2375,3306,3389,5432,5900,6379,9200,11211,27017"
+
+var errInvalidPort = errors.New("invalid port")
+
+// FirewallRule represents an ingress allow rule of a VPC firewall.
+type FirewallRule struct {
+	Name                  string
+	Project               string
+	SourceRanges          []string
+	TargetTags            []string
+	TargetServiceAccounts []string
+	Allowed               []FirewallAllowed
+}
+
+// FirewallAllowed represents a protocol and the ports allowed by a firewall rule.
+// No ports mean all ports of the protocol.
+type FirewallAllowed struct {
+	Protocol string
+	Ports    []string
+}
+
+// parseSensitivePorts parses a comma-separated list of ports.
+func parseSensitivePorts(s string) ([]int, error) {
+	ports := []int{}
+
+	for _, p := range splitString(s, ",") {
+		port, err := strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("%w: %q", errInvalidPort, p)
+		}
+
+		ports = append(ports, port)
+	}
+
+	return ports, nil
+}
+
+// FetchFirewallRules fetches the enabled ingress allow rules of the VPC firewalls of the organization.
+func (f *GoogleAssetFetcher) FetchFirewallRules(ctx context.Context) ([]FirewallRule, error) {
+	req := &assetpb.SearchAllResourcesRequest{
+		Scope:      "organizations/" + f.cfg.OrgID,
+		AssetTypes: []string{firewallAssetType},
+		ReadMask:   &fieldmaskpb.FieldMask{Paths: []string{"name", "parentFullResourceName", "parentAssetType", "versionedResources"}},
+	}
+
+	it := f.client.SearchAllResources(ctx, req)
+	rules := []FirewallRule{}
+
+	for {
+		result, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to search firewall rules: %w", err)
+		}
+
+		if rule, ok := parseFirewallRule(result); ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	f.logger.DebugContext(ctx, "Fetched firewall rules", slog.Int("number_of_rules", len(rules)))
+
+	return rules, nil
+}
+
+// parseFirewallRule parses the firewall resource of a search result. Only enabled ingress
+// allow rules are returned.
+func parseFirewallRule(result *assetpb.ResourceSearchResult) (FirewallRule, bool) {
+	if len(result.GetVersionedResources()) == 0 {
+		return FirewallRule{}, false
+	}
+
+	resource := result.GetVersionedResources()[0].GetResource().AsMap()
+
+	if disabled, _ := resource["disabled"].(bool); disabled {
+		return FirewallRule{}, false
+	}
+
+	if direction, _ := resource["direction"].(string); direction != "" && direction != "INGRESS" {
+		return FirewallRule{}, false
+	}
+
+	rule := FirewallRule{
+		Name:                  lastPathSegment(result.GetName()),
+		Project:               getProjectID(result),
+		SourceRanges:          stringSlice(resource["sourceRanges"]),
+		TargetTags:            stringSlice(resource["targetTags"]),
+		TargetServiceAccounts: stringSlice(resource["targetServiceAccounts"]),
+	}
+
+	allowed, _ := resource["allowed"].([]any)
+	for _, a := range allowed {
+		entry, _ := a.(map[string]any)
+		protocol, _ := entry["IPProtocol"].(string)
+		rule.Allowed = append(rule.Allowed, FirewallAllowed{Protocol: protocol, Ports: stringSlice(entry["ports"])})
+	}
+
+	return rule, len(rule.Allowed) > 0
+}
+
+func stringSlice(v any) []string {
+	items, _ := v.([]any)
+	s := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			s = append(s, str)
+		}
+	}
+
+	return s
+}
+
+// openToInternet reports whether the rule allows traffic from any address.
+func (r FirewallRule) openToInternet() bool {
+	return slices.Contains(r.SourceRanges, "0.0.0.0/0") || slices.Contains(r.SourceRanges, "::/0")
+}
+
+// appliesTo reports whether the rule applies to the asset. Rules targeting service accounts
+// are skipped, as the service accounts of the instances are unknown. Forwarding rules are
+// only matched by rules applying to all instances, as their backends are unknown.
+func (r FirewallRule) appliesTo(asset ProcessedAsset) bool {
+	if r.Project != asset.Project || len(r.TargetServiceAccounts) > 0 {
+		return false
+	}
+
+	if len(r.TargetTags) == 0 {
+		return true
+	}
+
+	tags := splitString(asset.Attributes["networkTags"], ",")
+
+	return slices.ContainsFunc(r.TargetTags, func(tag string) bool { return slices.Contains(tags, tag) })
+}
+
+// allows reports whether the rule allows TCP or UDP traffic to the port.
+func (r FirewallRule) allows(port int) (string, bool) {
+	for _, allowed := range r.Allowed {
+		protocol := strings.ToLower(allowed.Protocol)
+		if protocol != "tcp" && protocol != "udp" && protocol != "all" {
+			continue
+		}
+
+		if len(allowed.Ports) == 0 || slices.ContainsFunc(allowed.Ports, func(p string) bool { return portInRange(port, p) }) {
+			return protocol, true
+		}
+	}
+
+	return "", false
+}
+
+// portInRange reports whether the port is the port or within the range, such as 8000-8100.
+func portInRange(port int, portRange string) bool {
+	low, high, isRange := strings.Cut(portRange, "-")
+	if !isRange {
+		high = low
+	}
+
+	l, errLow := strconv.Atoi(low)
+	h, errHigh := strconv.Atoi(high)
+
+	return errLow == nil && errHigh == nil && l <= port && port <= h
+}
+
+// annotateExposures sets the sensitive ports of the instances and external forwarding rules
+// that are reachable from the internet according to the firewall rules, such as tcp:22 (allow-ssh).
+// Deny rules are not evaluated, so an exposure may still be blocked by a higher priority deny rule.
+func annotateExposures(assets []ProcessedAsset, rules []FirewallRule, sensitivePorts []int) []ProcessedAsset {
+	for i, asset := range assets {
+		if !exposable(asset) {
+			continue
+		}
+
+		for _, rule := range rules {
+			if !rule.openToInternet() || !rule.appliesTo(asset) {
+				continue
+			}
+
+			if asset.AssetType == forwardingRuleAssetType && len(rule.TargetTags) > 0 {
+				continue
+			}
+
+			for _, port := range sensitivePorts {
+				if protocol, ok := rule.allows(port); ok {
+					assets[i].ExposedPorts = append(assets[i].ExposedPorts,
+						fmt.Sprintf("%s:%d (%s)", protocol, port, rule.Name))
+				}
+			}
+		}
+	}
+
+	return assets
+}
+
+// exposable reports whether the asset has an external address that firewall rules apply to.
+func exposable(asset ProcessedAsset) bool {
+	switch asset.AssetType {
+	case instanceAssetType:
+		return asset.Attributes["externalIPs"] != ""
+	case forwardingRuleAssetType:
+		return strings.HasPrefix(asset.Attributes["loadBalancingScheme"], "EXTERNAL")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,136 @@
+package assetwatcher
+
+import (
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newFirewallSearchResult(t *testing.T, resource map[string]any) *assetpb.ResourceSearchResult {
+	t.Helper()
+
+	s, err := structpb.NewStruct(resource)
+	if err != nil {
+		t.Fatalf("failed to create the firewall resource: %v", err)
+	}
+
+	return &assetpb.ResourceSearchResult{
+		Name:                   "//compute.googleapis.com/projects/proj-A/global/firewalls/allow-ssh",
+		ParentAssetType:        "cloudresourcemanager.googleapis.com/Project",
+		ParentFullResourceName: "//cloudresourcemanager.googleapis.com/projects/proj-A",
+		VersionedResources:     []*assetpb.VersionedResource{{Resource: s}},
+	}
+}
+
+func TestParseFirewallRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource map[string]any
+		want     FirewallRule
+		wantOK   bool
+	}{
+		{
+			name: "ingress allow rule",
+			resource: map[string]any{
+				"direction":    "INGRESS",
+				"sourceRanges": []any{"0.0.0.0/0"},
+				"targetTags":   []any{"ssh"},
+				"allowed":      []any{map[string]any{"IPProtocol": "tcp", "ports": []any{"22"}}},
+			},
+			want: FirewallRule{
+				Name: "allow-ssh", Project: "proj-A", SourceRanges: []string{"0.0.0.0/0"}, TargetTags: []string{"ssh"},
+				TargetServiceAccounts: []string{}, Allowed: []FirewallAllowed{{Protocol: "tcp", Ports: []string{"22"}}},
+			},
+			wantOK: true,
+		},
+		{
+			name: "disabled rule",
+			resource: map[string]any{
+				"disabled": true, "allowed": []any{map[string]any{"IPProtocol": "all"}},
+			},
+		},
+		{
+			name: "egress rule",
+			resource: map[string]any{
+				"direction": "EGRESS", "allowed": []any{map[string]any{"IPProtocol": "all"}},
+			},
+		},
+		{
+			name:     "deny rule",
+			resource: map[string]any{"denied": []any{map[string]any{"IPProtocol": "all"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseFirewallRule(newFirewallSearchResult(t, tt.resource))
+			if ok != tt.wantOK {
+				t.Fatalf("parseFirewallRule() ok = %t, want %t", ok, tt.wantOK)
+			}
+
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFirewallRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateExposures(t *testing.T) {
+	rules := []FirewallRule{
+		{
+			Name: "allow-ssh", Project: "proj-A", SourceRanges: []string{"0.0.0.0/0"}, TargetTags: []string{"ssh"},
+			Allowed: []FirewallAllowed{{Protocol: "tcp", Ports: []string{"22"}}},
+		},
+		{
+			Name: "allow-db-range", Project: "proj-A", SourceRanges: []string{"::/0"},
+			Allowed: []FirewallAllowed{{Protocol: "tcp", Ports: []string{"5000-5500"}}, {Protocol: "icmp"}},
+		},
+		{
+			Name: "allow-all-internal", Project: "proj-A", SourceRanges: []string{"10.0.0.0/8"},
+			Allowed: []FirewallAllowed{{Protocol: "all"}},
+		},
+		{
+			Name: "allow-rdp-sa", Project: "proj-A", SourceRanges: []string{"0.0.0.0/0"},
+			TargetServiceAccounts: []string{"vm@proj-A.iam.gserviceaccount.com"},
+			Allowed:               []FirewallAllowed{{Protocol: "tcp", Ports: []string{"3389"}}},
+		},
+	}
+
+	assets := []ProcessedAsset{
+		{Name: "bastion", AssetType: instanceAssetType, Project: "proj-A", Attributes: map[string]string{"externalIPs": "203.0.113.1", "networkTags": "ssh"}},
+		{Name: "web", AssetType: instanceAssetType, Project: "proj-A", Attributes: map[string]string{"externalIPs": "203.0.113.2", "networkTags": "http"}},
+		{Name: "private", AssetType: instanceAssetType, Project: "proj-A", Attributes: map[string]string{"networkTags": "ssh"}},
+		{Name: "lb", AssetType: forwardingRuleAssetType, Project: "proj-A", Attributes: map[string]string{"loadBalancingScheme": "EXTERNAL"}},
+		{Name: "other", AssetType: instanceAssetType, Project: "proj-B", Attributes: map[string]string{"externalIPs": "203.0.113.3", "networkTags": "ssh"}},
+	}
+
+	got := annotateExposures(assets, rules, []int{22, 3389, 5432})
+
+	want := [][]string{
+		{"tcp:22 (allow-ssh)", "tcp:5432 (allow-db-range)"},
+		{"tcp:5432 (allow-db-range)"},
+		nil,
+		{"tcp:5432 (allow-db-range)"},
+		nil,
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(got[i].ExposedPorts, want[i]) {
+			t.Errorf("%s: expected exposed ports %v, got %v", got[i].Name, want[i], got[i].ExposedPorts)
+		}
+	}
+}
+
+func TestParseSensitivePorts(t *testing.T) {
+	if got, err := parseSensitivePorts("22, 3389"); err != nil || !reflect.DeepEqual(got, []int{22, 3389}) {
+		t.Errorf("parseSensitivePorts() = %v, %v", got, err)
+	}
+
+	for _, invalid := range []string{"ssh", "0", "65536"} {
+		if _, err := parseSensitivePorts(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
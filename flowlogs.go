@@ -0,0 +1,240 @@
+package assetwatcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+const (
+	defaultFlowLogsLookbackDays = 7
+	hoursPerDay                 = 24
+)
+
+// FlowPeer represents a remote IP address that an address communicated with.
+type FlowPeer struct {
+	Address string
+	Peer    string
+}
+
+// FlowTraffic represents the traffic observed for an address.
+type FlowTraffic struct {
+	Address  string
+	LastSeen time.Time
+	Bytes    int64
+}
+
+// FlowLogsClient is an interface for querying VPC Flow Logs.
+type FlowLogsClient interface {
+	QueryPeers(ctx context.Context, addresses []string, since time.Time) ([]FlowPeer, error)
+	QueryTraffic(ctx context.Context, addresses []string, since time.Time) ([]FlowTraffic, error)
+}
+
+// BigQueryFlowLogsClient queries VPC Flow Logs exported to a BigQuery table.
+type BigQueryFlowLogsClient struct {
+	service *bigquery.Service
+	table   bigQueryTable
+	logger  *slog.Logger
+}
+
+// NewBigQueryFlowLogsClient creates a new VPC Flow Logs client for the configured BigQuery table.
+func NewBigQueryFlowLogsClient(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg *Config,
+	opts ...option.ClientOption,
+) (*BigQueryFlowLogsClient, error) {
+	table, err := parseBigQueryTable(cfg.FlowLogsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := bigquery.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
+	}
+
+	return &BigQueryFlowLogsClient{
+		service: s,
+		table:   table,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// QueryPeers returns the distinct remote IP addresses that the addresses communicated
+// with since the given time, in either direction.
+func (c *BigQueryFlowLogsClient) QueryPeers(
+	ctx context.Context,
+	addresses []string,
+	since time.Time,
+) ([]FlowPeer, error) {
+	query := fmt.Sprintf(`
+WITH flows AS (
+  SELECT jsonPayload.connection.src_ip AS address, jsonPayload.connection.dest_ip AS peer
+  FROM %[1]s WHERE timestamp >= @since
+  UNION ALL
+  SELECT jsonPayload.connection.dest_ip AS address, jsonPayload.connection.src_ip AS peer
+  FROM %[1]s WHERE timestamp >= @since
+)
+SELECT DISTINCT address, peer FROM flows WHERE address IN UNNEST(@addresses)`, c.table)
+
+	c.logger.DebugContext(ctx, "Querying VPC Flow Logs peers", slog.Int("number_of_addresses", len(addresses)))
+
+	rows, err := runQuery(ctx, c.service, c.table.project, query,
+		timestampParameter("since", since),
+		stringArrayParameter("addresses", addresses),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query VPC Flow Logs peers: %w", err)
+	}
+
+	peers := make([]FlowPeer, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+
+		peers = append(peers, FlowPeer{Address: row[0], Peer: row[1]})
+	}
+
+	return peers, nil
+}
+
+// QueryTraffic returns the last time traffic was observed and the total number of bytes
+// sent and received by each of the addresses since the given time. Addresses without
+// any observed traffic are omitted.
+func (c *BigQueryFlowLogsClient) QueryTraffic(
+	ctx context.Context,
+	addresses []string,
+	since time.Time,
+) ([]FlowTraffic, error) {
+	query := fmt.Sprintf(`
+WITH flows AS (
+  SELECT jsonPayload.connection.src_ip AS address, timestamp, jsonPayload.bytes_sent AS bytes
+  FROM %[1]s WHERE timestamp >= @since
+  UNION ALL
+  SELECT jsonPayload.connection.dest_ip AS address, timestamp, jsonPayload.bytes_sent AS bytes
+  FROM %[1]s WHERE timestamp >= @since
+)
+SELECT address, UNIX_MICROS(MAX(timestamp)), SUM(SAFE_CAST(bytes AS INT64))
+FROM flows WHERE address IN UNNEST(@addresses) GROUP BY address`, c.table)
+
+	c.logger.DebugContext(ctx, "Querying VPC Flow Logs traffic", slog.Int("number_of_addresses", len(addresses)))
+
+	rows, err := runQuery(ctx, c.service, c.table.project, query,
+		timestampParameter("since", since),
+		stringArrayParameter("addresses", addresses),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query VPC Flow Logs traffic: %w", err)
+	}
+
+	traffic := make([]FlowTraffic, 0, len(rows))
+
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+
+		lastSeen, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last traffic time of %s: %w", row[0], err)
+		}
+
+		bytes, _ := strconv.ParseInt(row[2], 10, 64)
+
+		traffic = append(traffic, FlowTraffic{
+			Address:  row[0],
+			LastSeen: time.UnixMicro(lastSeen).UTC(),
+			Bytes:    bytes,
+		})
+	}
+
+	return traffic, nil
+}
+
+// annotateLastTraffic annotates each in-use address with the last time traffic was observed
+// and the number of bytes transferred within the lookback window.
+func annotateLastTraffic(
+	ctx context.Context,
+	logger *slog.Logger,
+	client FlowLogsClient,
+	cfg *Config,
+	assets []ProcessedAsset,
+	now time.Time,
+) ([]ProcessedAsset, error) {
+	inUse := []ProcessedAsset{}
+
+	for _, asset := range assets {
+		if asset.Status == addressStatusInUse {
+			inUse = append(inUse, asset)
+		}
+	}
+
+	addresses := assetAddresses(inUse)
+	if len(addresses) == 0 {
+		return assets, nil
+	}
+
+	traffic, err := client.QueryTraffic(ctx, addresses, flowLogsSince(now, cfg.FlowLogsLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := make(map[string]FlowTraffic, len(traffic))
+	for _, t := range traffic {
+		byAddress[t.Address] = t
+	}
+
+	withoutTraffic := 0
+
+	for i := range assets {
+		if assets[i].Status != addressStatusInUse {
+			continue
+		}
+
+		t, ok := byAddress[assets[i].IPAddress]
+		if !ok {
+			withoutTraffic++
+
+			continue
+		}
+
+		assets[i].LastTrafficSeen = t.LastSeen.Format(createdAtLayout)
+		assets[i].TrafficBytes = t.Bytes
+	}
+
+	logger.DebugContext(ctx, "Annotated last traffic",
+		slog.Int("number_of_addresses", len(addresses)),
+		slog.Int("number_without_traffic", withoutTraffic),
+	)
+
+	return assets, nil
+}
+
+// flowLogsSince returns the start of the VPC Flow Logs lookback window.
+func flowLogsSince(now time.Time, lookbackDays int) time.Time {
+	return now.Add(-time.Duration(lookbackDays) * hoursPerDay * time.Hour)
+}
+
+// assetAddresses returns the distinct known IP addresses of the assets.
+func assetAddresses(assets []ProcessedAsset) []string {
+	seen := make(map[string]bool)
+	addresses := []string{}
+
+	for _, asset := range assets {
+		if asset.IPAddress == "N/A" || asset.IPAddress == "" || seen[asset.IPAddress] {
+			continue
+		}
+
+		seen[asset.IPAddress] = true
+		addresses = append(addresses, asset.IPAddress)
+	}
+
+	return addresses
+}
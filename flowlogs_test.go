@@ -0,0 +1,181 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+// fakeFlowLogsClient is a mock implementation of the FlowLogsClient.
+type fakeFlowLogsClient struct {
+	peers     []FlowPeer
+	traffic   []FlowTraffic
+	addresses []string
+	since     time.Time
+}
+
+// QueryPeers returns the stored peers and records the query arguments.
+func (c *fakeFlowLogsClient) QueryPeers(_ context.Context, addresses []string, since time.Time) ([]FlowPeer, error) {
+	c.addresses = addresses
+	c.since = since
+
+	return c.peers, nil
+}
+
+// QueryTraffic returns the stored traffic and records the query arguments.
+func (c *fakeFlowLogsClient) QueryTraffic(_ context.Context, addresses []string, since time.Time) ([]FlowTraffic, error) {
+	c.addresses = addresses
+	c.since = since
+
+	return c.traffic, nil
+}
+
+func TestParseBigQueryTable(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{s: "proj.dataset.table", want: "`proj.dataset.table`"},
+		{s: "dataset.table", wantErr: true},
+		{s: "proj..table", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseBigQueryTable(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBigQueryTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("parseBigQueryTable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryPeers_WithFakeServer(t *testing.T) {
+	var request struct {
+		Query           string `json:"query"`
+		QueryParameters []struct {
+			Name string `json:"name"`
+		} `json:"queryParameters"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/queries") {
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			_, _ = w.Write([]byte(`{"jobComplete": true, "pageToken": "p2", "jobReference": {"jobId": "job-1", "location": "US"},
+				"rows": [{"f": [{"v": "34.1.1.1"}, {"v": "203.0.113.10"}]}]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"jobComplete": true, "rows": [{"f": [{"v": "34.2.2.2"}, {"v": null}]}]}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	client, err := NewBigQueryFlowLogsClient(ctx, logger, &Config{FlowLogsTable: "proj.dataset.flows"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewBigQueryFlowLogsClient failed: %v", err)
+	}
+
+	peers, err := client.QueryPeers(ctx, []string{"34.1.1.1", "34.2.2.2"}, time.Now())
+	if err != nil {
+		t.Fatalf("QueryPeers failed: %v", err)
+	}
+
+	want := []FlowPeer{{Address: "34.1.1.1", Peer: "203.0.113.10"}, {Address: "34.2.2.2", Peer: ""}}
+	if !reflect.DeepEqual(peers, want) {
+		t.Errorf("QueryPeers() = %v, want %v", peers, want)
+	}
+
+	if !strings.Contains(request.Query, "`proj.dataset.flows`") {
+		t.Errorf("query does not reference the flow logs table: %s", request.Query)
+	}
+
+	if len(request.QueryParameters) != 2 {
+		t.Errorf("expected 2 query parameters, got %d", len(request.QueryParameters))
+	}
+}
+
+func TestQueryTraffic_WithFakeServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jobComplete": true, "jobReference": {"jobId": "job-1"},
+			"rows": [{"f": [{"v": "34.1.1.1"}, {"v": "1704888000000000"}, {"v": "1024"}]}]}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	client, err := NewBigQueryFlowLogsClient(ctx, logger, &Config{FlowLogsTable: "proj.dataset.flows"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewBigQueryFlowLogsClient failed: %v", err)
+	}
+
+	traffic, err := client.QueryTraffic(ctx, []string{"34.1.1.1"}, time.Now())
+	if err != nil {
+		t.Fatalf("QueryTraffic failed: %v", err)
+	}
+
+	want := []FlowTraffic{{Address: "34.1.1.1", LastSeen: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), Bytes: 1024}}
+	if !reflect.DeepEqual(traffic, want) {
+		t.Errorf("QueryTraffic() = %v, want %v", traffic, want)
+	}
+}
+
+func TestAnnotateLastTraffic(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	client := &fakeFlowLogsClient{traffic: []FlowTraffic{
+		{Address: "34.1.1.1", LastSeen: time.Date(2024, 1, 9, 8, 30, 0, 0, time.UTC), Bytes: 2048},
+	}}
+
+	assets := []ProcessedAsset{
+		{Name: "busy", Status: "IN_USE", IPAddress: "34.1.1.1"},
+		{Name: "quiet", Status: "IN_USE", IPAddress: "34.2.2.2"},
+		{Name: "reserved", Status: "RESERVED", IPAddress: "34.3.3.3"},
+	}
+
+	got, err := annotateLastTraffic(ctx, logger, client, &Config{FlowLogsLookbackDays: 30}, assets, time.Now())
+	if err != nil {
+		t.Fatalf("annotateLastTraffic failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(client.addresses, []string{"34.1.1.1", "34.2.2.2"}) {
+		t.Errorf("expected only in-use addresses to be queried, got %v", client.addresses)
+	}
+
+	if got[0].LastTrafficSeen != "2024-01-09 08:30:00" || got[0].TrafficBytes != 2048 {
+		t.Errorf("unexpected traffic for busy: %+v", got[0])
+	}
+
+	for i, want := range []string{"2024-01-09 08:30:00", "none", "N/A"} {
+		if formatted := formatLastTraffic(got[i]); formatted != want {
+			t.Errorf("formatLastTraffic(%s) = %v, want %v", got[i].Name, formatted, want)
+		}
+	}
+}
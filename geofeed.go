@@ -0,0 +1,155 @@
+package assetwatcher
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+)
+
+// outputFormatGeofeed renders the inventory as an RFC 8805 geofeed.
+const outputFormatGeofeed = "geofeed"
+
+var (
+	errInvalidGeofeedRegions = errors.New("invalid geofeed region mapping")
+
+	// zoneSuffix matches the suffix of a zone, such as the -a of us-central1-a.
+	zoneSuffix = regexp.MustCompile(`-[a-z]$`)
+)
+
+// GeofeedLocation is the location of a Google Cloud region in a geofeed. The region is an
+// ISO 3166-2 subdivision code, such as US-IA.
+type GeofeedLocation struct {
+	Country string
+	Region  string
+	City    string
+}
+
+// gcpRegionLocations maps the Google Cloud regions to the locations of their data centers.
+// It can be extended and overridden with ASSET_WATCHER_GEOFEED_REGIONS.
+var gcpRegionLocations = map[string]GeofeedLocation{
+	"africa-south1":           {Country: "ZA", Region: "ZA-GP", City: "Johannesburg"},
+	"asia-east1":              {Country: "TW", Region: "TW-CHA", City: "Changhua County"},
+	"asia-east2":              {Country: "HK", City: "Hong Kong"},
+	"asia-northeast1":         {Country: "JP", Region: "JP-13", City: "Tokyo"},
+	"asia-northeast2":         {Country: "JP", Region: "JP-27", City: "Osaka"},
+	"asia-northeast3":         {Country: "KR", Region: "KR-11", City: "Seoul"},
+	"asia-south1":             {Country: "IN", Region: "IN-MH", City: "Mumbai"},
+	"asia-south2":             {Country: "IN", Region: "IN-DL", City: "Delhi"},
+	"asia-southeast1":         {Country: "SG", City: "Singapore"},
+	"asia-southeast2":         {Country: "ID", Region: "ID-JK", City: "Jakarta"},
+	"australia-southeast1":    {Country: "AU", Region: "AU-NSW", City: "Sydney"},
+	"australia-southeast2":    {Country: "AU", Region: "AU-VIC", City: "Melbourne"},
+	"europe-central2":         {Country: "PL", Region: "PL-MZ", City: "Warsaw"},
+	"europe-north1":           {Country: "FI", Region: "FI-09", City: "Hamina"},
+	"europe-southwest1":       {Country: "ES", Region: "ES-M", City: "Madrid"},
+	"europe-west1":            {Country: "BE", Region: "BE-WHT", City: "St. Ghislain"},
+	"europe-west2":            {Country: "GB", Region: "GB-LND", City: "London"},
+	"europe-west3":            {Country: "DE", Region: "DE-HE", City: "Frankfurt"},
+	"europe-west4":            {Country: "NL", Region: "NL-GR", City: "Eemshaven"},
+	"europe-west6":            {Country: "CH", Region: "CH-ZH", City: "Zurich"},
+	"europe-west8":            {Country: "IT", Region: "IT-25", City: "Milan"},
+	"europe-west9":            {Country: "FR", Region: "FR-IDF", City: "Paris"},
+	"europe-west10":           {Country: "DE", Region: "DE-BE", City: "Berlin"},
+	"europe-west12":           {Country: "IT", Region: "IT-21", City: "Turin"},
+	"me-central1":             {Country: "QA", City: "Doha"},
+	"me-central2":             {Country: "SA", City: "Dammam"},
+	"me-west1":                {Country: "IL", Region: "IL-TA", City: "Tel Aviv"},
+	"northamerica-northeast1": {Country: "CA", Region: "CA-QC", City: "Montreal"},
+	"northamerica-northeast2": {Country: "CA", Region: "CA-ON", City: "Toronto"},
+	"southamerica-east1":      {Country: "BR", Region: "BR-SP", City: "Osasco"},
+	"southamerica-west1":      {Country: "CL", Region: "CL-RM", City: "Santiago"},
+	"us-central1":             {Country: "US", Region: "US-IA", City: "Council Bluffs"},
+	"us-east1":                {Country: "US", Region: "US-SC", City: "Moncks Corner"},
+	"us-east4":                {Country: "US", Region: "US-VA", City: "Ashburn"},
+	"us-east5":                {Country: "US", Region: "US-OH", City: "Columbus"},
+	"us-south1":               {Country: "US", Region: "US-TX", City: "Dallas"},
+	"us-west1":                {Country: "US", Region: "US-OR", City: "The Dalles"},
+	"us-west2":                {Country: "US", Region: "US-CA", City: "Los Angeles"},
+	"us-west3":                {Country: "US", Region: "US-UT", City: "Salt Lake City"},
+	"us-west4":                {Country: "US", Region: "US-NV", City: "Las Vegas"},
+}
+
+// loadGeofeedRegions returns the built-in region mapping, extended and overridden by the
+// CSV file of region,country,subdivision,city lines, if set. Lines starting with # are ignored.
+func loadGeofeedRegions(path string) (map[string]GeofeedLocation, error) {
+	regions := maps.Clone(gcpRegionLocations)
+	if path == "" {
+		return regions, nil
+	}
+
+	f, err := os.Open(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geofeed region mapping: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidGeofeedRegions, err)
+	}
+
+	for _, record := range records {
+		regions[record[0]] = GeofeedLocation{Country: record[1], Region: record[2], City: record[3]}
+	}
+
+	return regions, nil
+}
+
+// writeGeofeed writes an RFC 8805 geofeed of the public addresses of the assets, located by
+// the region of the asset. Addresses of global assets and unmapped regions are omitted, as
+// their location is not known.
+func writeGeofeed(w io.Writer, assets []ProcessedAsset, regions map[string]GeofeedLocation) error {
+	type entry struct {
+		addr     netip.Addr
+		location GeofeedLocation
+	}
+
+	entries := map[netip.Addr]entry{}
+
+	for _, asset := range assets {
+		location, ok := regions[zoneSuffix.ReplaceAllString(asset.Location, "")]
+		if !ok {
+			continue
+		}
+
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || !isPublicAddress(asset, addr) {
+				continue
+			}
+
+			entries[addr.Unmap()] = entry{addr: addr.Unmap(), location: location}
+		}
+	}
+
+	sorted := slices.SortedFunc(maps.Values(entries), func(a, b entry) int { return a.addr.Compare(b.addr) })
+
+	if _, err := fmt.Fprintln(w, "# ip_prefix,alpha2code,region,city,postal_code"); err != nil {
+		return fmt.Errorf("failed to write geofeed: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	for _, e := range sorted {
+		prefix := netip.PrefixFrom(e.addr, e.addr.BitLen())
+		_ = writer.Write([]string{prefix.String(), e.location.Country, e.location.Region, e.location.City, ""})
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write geofeed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteGeofeed(t *testing.T) {
+	assets := []ProcessedAsset{
+		{Name: "nat", Location: "us-central1", IPAddress: "203.0.113.2"},
+		{Name: "vm", Location: "europe-west3-b", Attributes: map[string]string{"externalIPs": "2001:db8::1,203.0.113.1"}},
+		{Name: "lb", Location: "global", IPAddress: "198.51.100.1"},
+		{Name: "internal", Location: "us-central1", IPAddress: "10.0.0.1", AddressType: addressTypeInternal},
+		{Name: "dup", Location: "us-central1", IPAddress: "203.0.113.2"},
+	}
+
+	var out bytes.Buffer
+	if err := writeGeofeed(&out, assets, gcpRegionLocations); err != nil {
+		t.Fatalf("writeGeofeed failed: %v", err)
+	}
+
+	want := "# ip_prefix,alpha2code,region,city,postal_code\n" +
+		"203.0.113.1/32,DE,DE-HE,Frankfurt,\n" +
+		"203.0.113.2/32,US,US-IA,Council Bluffs,\n" +
+		"2001:db8::1/128,DE,DE-HE,Frankfurt,\n"
+	if got := out.String(); got != want {
+		t.Errorf("writeGeofeed() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLoadGeofeedRegions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.csv")
+	content := "# region,country,subdivision,city\nus-central1,US,US-IA,Des Moines\nonprem-1,NL,NL-NH,Amsterdam\n"
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	regions, err := loadGeofeedRegions(path)
+	if err != nil {
+		t.Fatalf("loadGeofeedRegions failed: %v", err)
+	}
+
+	if got := regions["us-central1"].City; got != "Des Moines" {
+		t.Errorf("expected the overridden city, got %q", got)
+	}
+
+	if got := regions["onprem-1"]; got != (GeofeedLocation{Country: "NL", Region: "NL-NH", City: "Amsterdam"}) {
+		t.Errorf("unexpected added region %+v", got)
+	}
+
+	if gcpRegionLocations["us-central1"].City != "Council Bluffs" {
+		t.Error("the built-in mapping must not be modified")
+	}
+
+	if err := os.WriteFile(path, []byte("us-central1,US\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadGeofeedRegions(path); err == nil {
+		t.Error("expected an error for an invalid mapping")
+	}
+}
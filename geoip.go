@@ -0,0 +1,104 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+// GeoLocation represents the location of an address according to a GeoIP database.
+type GeoLocation struct {
+	Country string
+	Region  string
+}
+
+// GeoIPDatabase looks up the location of addresses in a local MaxMind GeoLite2
+// or GeoIP2 Country or City database.
+type GeoIPDatabase struct {
+	reader *maxminddb.Reader
+}
+
+// geoIPRecord holds the fields of the Country and City records that are looked up.
+type geoIPRecord struct {
+	Country           geoIPPlace   `maxminddb:"country"`
+	RegisteredCountry geoIPPlace   `maxminddb:"registered_country"`
+	Subdivisions      []geoIPPlace `maxminddb:"subdivisions"`
+}
+
+type geoIPPlace struct {
+	ISOCode string `maxminddb:"iso_code"`
+}
+
+// OpenGeoIPDatabase opens a GeoIP database from an mmdb file.
+func OpenGeoIPDatabase(path string) (*GeoIPDatabase, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MaxMind DB: %w", err)
+	}
+
+	return &GeoIPDatabase{reader: reader}, nil
+}
+
+// Close closes the database file.
+func (db *GeoIPDatabase) Close() error {
+	if err := db.reader.Close(); err != nil {
+		return fmt.Errorf("failed to close MaxMind DB: %w", err)
+	}
+
+	return nil
+}
+
+// Lookup returns the location of the address. The country is the ISO 3166-1 code of the
+// country, or of the registered country if the address is not located in a country, and
+// the region is the ISO 3166-2 code of the first subdivision, such as US-CA, if known.
+// IPv6 addresses are not located by IPv4 databases.
+func (db *GeoIPDatabase) Lookup(ip netip.Addr) (GeoLocation, error) {
+	ip = ip.Unmap()
+	if ip.Is6() && db.reader.Metadata.IPVersion == 4 {
+		return GeoLocation{}, nil
+	}
+
+	var record geoIPRecord
+	if err := db.reader.Lookup(ip).Decode(&record); err != nil {
+		return GeoLocation{}, fmt.Errorf("failed to look up %s: %w", ip, err)
+	}
+
+	location := GeoLocation{Country: cmp.Or(record.Country.ISOCode, record.RegisteredCountry.ISOCode)}
+
+	if len(record.Subdivisions) > 0 && record.Subdivisions[0].ISOCode != "" && location.Country != "" {
+		location.Region = location.Country + "-" + record.Subdivisions[0].ISOCode
+	}
+
+	return location, nil
+}
+
+// annotateGeoLocation sets the country and region of the first public address of each asset.
+func annotateGeoLocation(ctx context.Context, logger *slog.Logger, db *GeoIPDatabase, assets []ProcessedAsset) []ProcessedAsset {
+	for i, asset := range assets {
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || !isPublicAddress(asset, addr) {
+				continue
+			}
+
+			location, err := db.Lookup(addr)
+			if err != nil {
+				logger.WarnContext(ctx, "failed to look up the location of an address",
+					slog.String("address", address), slog.Any("error", err))
+
+				break
+			}
+
+			assets[i].Country = location.Country
+			assets[i].Region = location.Region
+
+			break
+		}
+	}
+
+	return assets
+}
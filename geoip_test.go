@@ -0,0 +1,288 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// MaxMind DB data types of the test databases.
+// https://maxmind.github.io/MaxMind-DB/
+const (
+	mmdbString = 2
+	mmdbUint32 = 6
+	mmdbMap    = 7
+	mmdbArray  = 11
+	mmdbBool   = 14
+)
+
+const mmdbDataSectionSeparator = 16
+
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// encodeMMDBValue encodes a value of the data section of a MaxMind DB. Unsigned integers
+// are encoded as uint32, which is enough for the metadata and the test records.
+func encodeMMDBValue(t *testing.T, buf *bytes.Buffer, value any) {
+	t.Helper()
+
+	writeControl := func(typ, size int) {
+		switch {
+		case typ > mmdbMap:
+			buf.WriteByte(byte(min(size, 29)))
+			buf.WriteByte(byte(typ - 7))
+		default:
+			buf.WriteByte(byte(typ<<5 | min(size, 29)))
+		}
+
+		if size >= 29 {
+			buf.WriteByte(byte(size - 29))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		writeControl(mmdbString, len(v))
+		buf.WriteString(v)
+	case uint32:
+		writeControl(mmdbUint32, 4)
+		buf.Write([]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+
+		writeControl(mmdbBool, size)
+	case map[string]any:
+		writeControl(mmdbMap, len(v))
+
+		for key, item := range v {
+			encodeMMDBValue(t, buf, key)
+			encodeMMDBValue(t, buf, item)
+		}
+	case []any:
+		writeControl(mmdbArray, len(v))
+
+		for _, item := range v {
+			encodeMMDBValue(t, buf, item)
+		}
+	default:
+		t.Fatalf("unsupported MaxMind DB test value %T", value)
+	}
+}
+
+// buildTestMMDB builds a MaxMind DB with 24-bit records in which only the addresses
+// of the prefix have the record.
+func buildTestMMDB(t *testing.T, ipVersion int, prefix netip.Prefix, record map[string]any) []byte {
+	t.Helper()
+
+	data := &bytes.Buffer{}
+	encodeMMDBValue(t, data, record)
+
+	return buildTestMMDBData(t, ipVersion, prefix, data.Bytes())
+}
+
+// buildTestMMDBData builds a MaxMind DB with 24-bit records in which only the addresses
+// of the prefix have the data section, encoded as is.
+func buildTestMMDBData(t *testing.T, ipVersion int, prefix netip.Prefix, data []byte) []byte {
+	t.Helper()
+
+	address := prefix.Addr().AsSlice()
+	bitCount := prefix.Bits()
+
+	if ipVersion == 6 && prefix.Addr().Is4() {
+		// IPv4 addresses are stored as ::a.b.c.d in IPv6 databases.
+		address = append(make([]byte, 12), address...)
+		bitCount += 96
+	}
+
+	nodeCount := bitCount
+	tree := &bytes.Buffer{}
+
+	for i := range bitCount {
+		next := i + 1
+		if next == bitCount {
+			next = nodeCount + mmdbDataSectionSeparator // the record is at offset 0
+		}
+
+		records := [2]int{nodeCount, nodeCount}
+		records[address[i/8]>>(7-i%8)&1] = next
+
+		for _, r := range records {
+			tree.Write([]byte{byte(r >> 16), byte(r >> 8), byte(r)})
+		}
+	}
+
+	db := &bytes.Buffer{}
+	db.Write(tree.Bytes())
+	db.Write(make([]byte, mmdbDataSectionSeparator))
+	db.Write(data)
+	db.Write(mmdbMetadataMarker)
+	encodeMMDBValue(t, db, map[string]any{
+		"node_count":  uint32(nodeCount), //nolint:gosec // Small test value.
+		"record_size": uint32(24),
+		"ip_version":  uint32(ipVersion), //nolint:gosec // Small test value.
+	})
+
+	return db.Bytes()
+}
+
+func writeTestGeoIPDatabase(t *testing.T, prefix string, record map[string]any) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	if err := os.WriteFile(path, buildTestMMDB(t, 6, netip.MustParsePrefix(prefix), record), 0o600); err != nil {
+		t.Fatalf("failed to write the test database: %v", err)
+	}
+
+	return path
+}
+
+func TestGeoIPDatabase_Lookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		record map[string]any
+		want   GeoLocation
+	}{
+		{
+			name: "city database",
+			record: map[string]any{
+				"country":      map[string]any{"iso_code": "US"},
+				"subdivisions": []any{map[string]any{"iso_code": "CA"}, map[string]any{"iso_code": "XX"}},
+			},
+			want: GeoLocation{Country: "US", Region: "US-CA"},
+		},
+		{
+			name:   "country database",
+			record: map[string]any{"country": map[string]any{"iso_code": "DE"}},
+			want:   GeoLocation{Country: "DE"},
+		},
+		{
+			name:   "registered country only",
+			record: map[string]any{"registered_country": map[string]any{"iso_code": "NL"}},
+			want:   GeoLocation{Country: "NL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := OpenGeoIPDatabase(writeTestGeoIPDatabase(t, "203.0.113.0/24", tt.record))
+			if err != nil {
+				t.Fatalf("OpenGeoIPDatabase failed: %v", err)
+			}
+
+			got, err := db.Lookup(netip.MustParseAddr("203.0.113.5"))
+			if err != nil {
+				t.Fatalf("Lookup failed: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Lookup() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoIPDatabase_LookupIPVersions(t *testing.T) {
+	record := map[string]any{"country": map[string]any{"iso_code": "DE"}, "anycast": true}
+	tests := []struct {
+		name      string
+		ipVersion int
+		prefix    string
+		ip        string
+		want      string
+	}{
+		{name: "IPv4 database", ipVersion: 4, prefix: "203.0.113.0/24", ip: "203.0.113.7", want: "DE"},
+		{name: "IPv4 database, other address", ipVersion: 4, prefix: "203.0.113.0/24", ip: "198.51.100.1"},
+		{name: "IPv4 database, IPv6 address", ipVersion: 4, prefix: "203.0.113.0/24", ip: "2001:db8::1"},
+		{name: "IPv6 database, IPv4 address", ipVersion: 6, prefix: "203.0.113.0/24", ip: "203.0.113.7", want: "DE"},
+		{name: "IPv6 database, IPv6 address", ipVersion: 6, prefix: "2001:db8::/32", ip: "2001:db8::1", want: "DE"},
+		{name: "IPv6 database, other IPv6 address", ipVersion: 6, prefix: "2001:db8::/32", ip: "2001:db9::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb")
+			data := buildTestMMDB(t, tt.ipVersion, netip.MustParsePrefix(tt.prefix), record)
+
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatalf("failed to write the test database: %v", err)
+			}
+
+			db, err := OpenGeoIPDatabase(path)
+			if err != nil {
+				t.Fatalf("OpenGeoIPDatabase failed: %v", err)
+			}
+			defer db.Close()
+
+			got, err := db.Lookup(netip.MustParseAddr(tt.ip))
+			if err != nil || got.Country != tt.want {
+				t.Errorf("Lookup() = %+v, %v, want country %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoIPDatabase_LookupCorrupt(t *testing.T) {
+	// The record is a pointer to itself, which must fail instead of recursing forever.
+	path := filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb")
+	data := buildTestMMDBData(t, 6, netip.MustParsePrefix("203.0.113.0/24"), []byte{0x20, 0x00})
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write the test database: %v", err)
+	}
+
+	db, err := OpenGeoIPDatabase(path)
+	if err != nil {
+		t.Fatalf("OpenGeoIPDatabase failed: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Lookup(netip.MustParseAddr("203.0.113.5")); err == nil {
+		t.Error("expected an error for a record pointing to itself")
+	}
+}
+
+func TestOpenGeoIPDatabase_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb")
+	if err := os.WriteFile(path, []byte("not a MaxMind DB"), 0o600); err != nil {
+		t.Fatalf("failed to write the test database: %v", err)
+	}
+
+	if _, err := OpenGeoIPDatabase(path); err == nil {
+		t.Error("expected an error for a file without metadata")
+	}
+}
+
+func TestAnnotateGeoLocation(t *testing.T) {
+	record := map[string]any{"country": map[string]any{"iso_code": "SG"}}
+
+	db, err := OpenGeoIPDatabase(writeTestGeoIPDatabase(t, "203.0.113.0/24", record))
+	if err != nil {
+		t.Fatalf("OpenGeoIPDatabase failed: %v", err)
+	}
+
+	assets := []ProcessedAsset{
+		{Name: "lb", IPAddress: "203.0.113.5"},
+		{Name: "vm", IPAddress: "N/A", Attributes: map[string]string{"externalIPs": "203.0.113.9"}},
+		{Name: "internal", AddressType: "INTERNAL", IPAddress: "203.0.113.10"},
+		{Name: "unknown", IPAddress: "198.51.100.1"},
+	}
+
+	got := annotateGeoLocation(t.Context(), slog.New(slog.DiscardHandler), db, assets)
+
+	for i, want := range []string{"SG", "SG", "", ""} {
+		if got[i].Country != want {
+			t.Errorf("%s: expected country %q, got %q", got[i].Name, want, got[i].Country)
+		}
+	}
+}
+
+func TestOpenGeoIPDatabase_Missing(t *testing.T) {
+	if _, err := OpenGeoIPDatabase(filepath.Join(t.TempDir(), "missing.mmdb")); err == nil {
+		t.Error("expected an error for a missing database")
+	}
+}
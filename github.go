@@ -0,0 +1,309 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultGitHubLabel  = "asset-watcher"
+	defaultGitHubAPIURL = "https://api.github.com"
+	githubPageSize      = 100
+
+	// githubMaxIssueBody is the maximum length of the body of GitHub issues.
+	githubMaxIssueBody = 65536
+
+	// githubIssueMarker prefixes the hidden comment identifying the asset of an issue.
+	githubIssueMarker = "<!-- asset-watcher:"
+	githubMarkerEnd   = " -->"
+)
+
+var errGitHubRequestFailed = errors.New("request to GitHub failed")
+
+// githubIssue is an issue as read from and written to the GitHub REST API.
+// https://docs.github.com/en/rest/issues/issues
+type githubIssue struct {
+	Number      int      `json:"number,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Body        string   `json:"body,omitempty"`
+	State       string   `json:"state,omitempty"`
+	StateReason string   `json:"state_reason,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// githubIssueRecord is an issue as read from the GitHub REST API, where the labels are objects.
+type githubIssueRecord struct {
+	Number      int              `json:"number"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body"`
+	PullRequest *json.RawMessage `json:"pull_request"`
+}
+
+// GitHubIssuesSink tracks the policy violations as issues of a GitHub repository, one per
+// asset. It manages the open issues with its label only: an issue is opened for every asset
+// with violations, updated when its violations change, and closed once they are resolved.
+type GitHubIssuesSink struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	label    string
+	logger   *slog.Logger
+}
+
+// NewGitHubIssuesSink creates a new GitHub issues sink for the configured repository,
+// authenticated with a token with write permissions on issues.
+func NewGitHubIssuesSink(logger *slog.Logger, cfg *Config, client *http.Client) *GitHubIssuesSink {
+	return &GitHubIssuesSink{
+		client:   client,
+		endpoint: strings.TrimSuffix(cfg.GitHubAPIURL, "/") + "/repos/" + cfg.GitHubRepo,
+		token:    cfg.GitHubToken,
+		label:    cfg.GitHubLabel,
+		logger:   logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the sink.
+func (s *GitHubIssuesSink) Name() string {
+	return "github-issues"
+}
+
+// Publish synchronizes the issues of the repository with the violations of the report.
+func (s *GitHubIssuesSink) Publish(ctx context.Context, report *Report) error {
+	existing, err := s.listIssues(ctx)
+	if err != nil {
+		return err
+	}
+
+	creates, updates, closes := githubIssueChanges(report, existing)
+
+	for _, issue := range creates {
+		issue.Labels = []string{s.label}
+		if err := s.do(ctx, http.MethodPost, "/issues", issue, nil); err != nil {
+			return fmt.Errorf("failed to create GitHub issue %q: %w", issue.Title, err)
+		}
+	}
+
+	for _, issue := range updates {
+		path := "/issues/" + strconv.Itoa(issue.Number)
+		if err := s.do(ctx, http.MethodPatch, path, githubIssue{Title: issue.Title, Body: issue.Body}, nil); err != nil {
+			return fmt.Errorf("failed to update GitHub issue #%d: %w", issue.Number, err)
+		}
+	}
+
+	for _, number := range closes {
+		path := "/issues/" + strconv.Itoa(number)
+
+		comment := map[string]string{"body": "The policy violations were resolved in run " + report.Metadata.RunID + "."}
+		if err := s.do(ctx, http.MethodPost, path+"/comments", comment, nil); err != nil {
+			return fmt.Errorf("failed to comment on GitHub issue #%d: %w", number, err)
+		}
+
+		if err := s.do(ctx, http.MethodPatch, path, githubIssue{State: "closed", StateReason: "completed"}, nil); err != nil {
+			return fmt.Errorf("failed to close GitHub issue #%d: %w", number, err)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Synchronized GitHub issues",
+		slog.Int("created", len(creates)),
+		slog.Int("updated", len(updates)),
+		slog.Int("closed", len(closes)),
+	)
+
+	return nil
+}
+
+// Close is a no-op, as the HTTP client does not need to be closed.
+func (s *GitHubIssuesSink) Close() error {
+	return nil
+}
+
+// githubIssueChanges returns the issues to create for the assets with violations and no open
+// issue, the open issues whose title or body changed, and the numbers of the open issues of
+// the assets no longer in violation. Issues are matched to assets by the marker in their body.
+func githubIssueChanges(report *Report, existing []githubIssueRecord) ([]githubIssue, []githubIssue, []int) {
+	byAsset := map[string][]RuleViolation{}
+	order := []string{}
+
+	for _, v := range report.Violations {
+		key := assetKey(v.Asset)
+		if _, ok := byAsset[key]; !ok {
+			order = append(order, key)
+		}
+
+		byAsset[key] = append(byAsset[key], v)
+	}
+
+	open := make(map[string]githubIssueRecord, len(existing))
+
+	for _, issue := range existing {
+		if key, ok := githubIssueAsset(issue.Body); ok {
+			open[key] = issue
+		}
+	}
+
+	creates := []githubIssue{}
+	updates := []githubIssue{}
+	closes := []int{}
+
+	for _, key := range order {
+		want := githubIssue{Title: githubIssueTitle(byAsset[key][0].Asset), Body: githubIssueBody(key, byAsset[key])}
+
+		issue, ok := open[key]
+		if !ok {
+			creates = append(creates, want)
+
+			continue
+		}
+
+		if issue.Title != want.Title || issue.Body != want.Body {
+			want.Number = issue.Number
+			updates = append(updates, want)
+		}
+	}
+
+	for key, issue := range open {
+		if _, ok := byAsset[key]; !ok {
+			closes = append(closes, issue.Number)
+		}
+	}
+
+	slices.Sort(closes)
+
+	return creates, updates, closes
+}
+
+func githubIssueTitle(asset ProcessedAsset) string {
+	return "Policy violations of " + asset.Name + " (" + asset.Project + ")"
+}
+
+// githubIssueBody renders the violations of the asset as a Markdown table. The body does not
+// depend on the run, so that the issue is only updated when its violations change.
+func githubIssueBody(key string, violations []RuleViolation) string {
+	violations = slices.Clone(violations)
+	slices.SortFunc(violations, func(a, b RuleViolation) int {
+		return strings.Compare(a.Rule, b.Rule)
+	})
+
+	asset := violations[0].Asset
+	marker := githubIssueMarker + key + githubMarkerEnd
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "`%s` (`%s`) in project `%s`, location `%s`, violates %d policy rules.\n\n",
+		asset.Name, asset.IPAddress, asset.Project, asset.Location, len(violations))
+	b.WriteString("| Severity | Rule | Message |\n|---|---|---|\n")
+
+	for _, v := range violations {
+		fmt.Fprintf(&b, "| %s | `%s` | %s |\n", v.Severity, v.Rule, strings.ReplaceAll(v.Message, "|", "\\|"))
+	}
+
+	fmt.Fprintf(&b, "\nResource: `%s`\n\n", key)
+	b.WriteString("This issue is managed by asset-watcher and closed once the violations are resolved.\n")
+
+	return truncateString(b.String(), githubMaxIssueBody-len(marker)-1) + "\n" + marker
+}
+
+// githubIssueAsset returns the key of the asset of the issue body, if it has a marker.
+func githubIssueAsset(body string) (string, bool) {
+	_, rest, ok := strings.Cut(body, githubIssueMarker)
+	if !ok {
+		return "", false
+	}
+
+	key, _, ok := strings.Cut(rest, githubMarkerEnd)
+
+	return key, ok && key != ""
+}
+
+// listIssues lists the open issues managed by asset-watcher, without pull requests.
+func (s *GitHubIssuesSink) listIssues(ctx context.Context) ([]githubIssueRecord, error) {
+	issues := []githubIssueRecord{}
+
+	for page := 1; ; page++ {
+		query := url.Values{
+			"state":    {"open"},
+			"labels":   {s.label},
+			"per_page": {strconv.Itoa(githubPageSize)},
+			"page":     {strconv.Itoa(page)},
+		}
+
+		var records []githubIssueRecord
+		if err := s.do(ctx, http.MethodGet, "/issues?"+query.Encode(), nil, &records); err != nil {
+			return nil, fmt.Errorf("failed to list GitHub issues: %w", err)
+		}
+
+		for _, record := range records {
+			if record.PullRequest == nil {
+				issues = append(issues, record)
+			}
+		}
+
+		if len(records) < githubPageSize {
+			return issues, nil
+		}
+	}
+}
+
+// do sends a request with the JSON body to the path of the repository, and decodes the
+// response into out, unless it is nil.
+func (s *GitHubIssuesSink) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s: %s", errGitHubRequestFailed, resp.Status,
+			bytes.TrimSpace(respBody[:min(len(respBody), maxErrorBodyBytes)]))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+
+	return nil
+}
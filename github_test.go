@@ -0,0 +1,143 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGitHubIssueChanges(t *testing.T) {
+	web := ProcessedAsset{Name: "web", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.10"}
+	db := ProcessedAsset{Name: "db", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.11"}
+	api := ProcessedAsset{Name: "api", Project: "dev", Location: "global", IPAddress: "203.0.113.12"}
+
+	report := &Report{Violations: []RuleViolation{
+		{Rule: "no-public-ip", Severity: "HIGH", Message: "public address", Asset: web},
+		{Rule: "unused", Severity: "LOW", Message: "reserved", Asset: db},
+		{Rule: "no-public-ip", Severity: "HIGH", Message: "public address", Asset: db},
+	}}
+
+	dbBody := githubIssueBody(assetKey(db), report.Violations[1:])
+	existing := []githubIssueRecord{
+		{Number: 1, Title: githubIssueTitle(db), Body: dbBody},
+		{Number: 2, Title: githubIssueTitle(web), Body: "outdated\n" + githubIssueMarker + assetKey(web) + githubMarkerEnd},
+		{Number: 3, Title: githubIssueTitle(api), Body: githubIssueMarker + assetKey(api) + githubMarkerEnd},
+		{Number: 4, Title: "Unrelated", Body: "no marker"},
+	}
+
+	creates, updates, closes := githubIssueChanges(report, existing)
+
+	if len(creates) != 0 {
+		t.Errorf("unexpected creates: %+v", creates)
+	}
+
+	if len(updates) != 1 || updates[0].Number != 2 || !strings.Contains(updates[0].Body, "public address") {
+		t.Errorf("unexpected updates: %+v", updates)
+	}
+
+	if len(closes) != 1 || closes[0] != 3 {
+		t.Errorf("unexpected closes: %v", closes)
+	}
+}
+
+func TestGitHubIssueAsset(t *testing.T) {
+	body := githubIssueBody("projects/prod/addresses/web", []RuleViolation{
+		{Rule: "no-public-ip", Severity: "HIGH", Message: "a | b", Asset: ProcessedAsset{Name: "web"}},
+	})
+
+	if key, ok := githubIssueAsset(body); !ok || key != "projects/prod/addresses/web" {
+		t.Errorf("expected the key of the marker, got %q, %v", key, ok)
+	}
+
+	if !strings.Contains(body, `a \| b`) {
+		t.Errorf("expected the pipe of the message to be escaped:\n%s", body)
+	}
+
+	if _, ok := githubIssueAsset("no marker"); ok {
+		t.Error("expected no key without a marker")
+	}
+}
+
+func TestGitHubIssuesSink_Publish(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		created  githubIssue
+		closed   githubIssue
+	)
+
+	stale := githubIssueRecord{Number: 9, Title: "old", Body: githubIssueMarker + "gone" + githubMarkerEnd}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+
+			return
+		}
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/example/findings/issues":
+			if r.URL.Query().Get("labels") != defaultGitHubLabel || r.URL.Query().Get("state") != "open" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+
+			_ = json.NewEncoder(w).Encode([]any{stale, map[string]any{"number": 10, "pull_request": map[string]any{}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/example/findings/issues":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch:
+			_ = json.NewDecoder(r.Body).Decode(&closed)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	cfg := ConfigDefaults
+	cfg.GitHubAPIURL = server.URL + "/"
+	cfg.GitHubRepo = "example/findings"
+	cfg.GitHubToken = "secret"
+	sink := NewGitHubIssuesSink(slog.New(slog.DiscardHandler), &cfg, server.Client())
+
+	report := &Report{Violations: []RuleViolation{
+		{Rule: "no-public-ip", Severity: "HIGH", Message: "public address", Asset: ProcessedAsset{Name: "web"}},
+	}}
+
+	if err := sink.Publish(t.Context(), report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	want := []string{
+		"GET /repos/example/findings/issues",
+		"POST /repos/example/findings/issues",
+		"POST /repos/example/findings/issues/9/comments",
+		"PATCH /repos/example/findings/issues/9",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+
+	if len(created.Labels) != 1 || created.Labels[0] != defaultGitHubLabel || !strings.Contains(created.Body, "no-public-ip") {
+		t.Errorf("unexpected created issue: %+v", created)
+	}
+
+	if closed.State != "closed" || closed.StateReason != "completed" {
+		t.Errorf("unexpected closed issue: %+v", closed)
+	}
+
+	cfg.GitHubToken = "wrong"
+	if err := NewGitHubIssuesSink(slog.New(slog.DiscardHandler), &cfg, server.Client()).Publish(t.Context(), report); err == nil ||
+		!strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("expected the error of GitHub, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Dimensions the assets can be grouped by.
+const (
+	groupByProject     = "project"
+	groupByLocation    = "location"
+	groupByState       = "state"
+	groupByLabelPrefix = "label:"
+
+	// noLabelGroup is the group of assets without the label.
+	noLabelGroup = "(none)"
+)
+
+var errInvalidGroupBy = errors.New("invalid group by dimension")
+
+// GroupSummary represents the number of assets, and their estimated monthly cost
+// if enabled, aggregated by a dimension.
+type GroupSummary struct {
+	By     string  `json:"by"`
+	Groups []Group `json:"groups"`
+}
+
+// Group represents the aggregated assets sharing the same value of the dimension.
+type Group struct {
+	Key         string   `json:"key"`
+	Assets      int      `json:"assets"`
+	MonthlyCost *float64 `json:"monthlyCost,omitempty"`
+}
+
+// groupKeyFunc returns the value of the dimension for an asset.
+type groupKeyFunc func(asset ProcessedAsset) string
+
+// parseGroupBy returns the function extracting the dimension: project, location,
+// state, or label:KEY.
+func parseGroupBy(by string) (groupKeyFunc, error) {
+	switch by {
+	case groupByProject:
+		return func(a ProcessedAsset) string { return a.Project }, nil
+	case groupByLocation:
+		return func(a ProcessedAsset) string { return a.Location }, nil
+	case groupByState:
+		return func(a ProcessedAsset) string { return a.Status }, nil
+	}
+
+	if key, ok := strings.CutPrefix(by, groupByLabelPrefix); ok && key != "" {
+		return func(a ProcessedAsset) string {
+			if value, ok := a.Labels[key]; ok {
+				return value
+			}
+
+			return noLabelGroup
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q, expected project, location, state, or label:KEY", errInvalidGroupBy, by)
+}
+
+// groupByTitle returns the table header of the dimension.
+func groupByTitle(by string) string {
+	switch by {
+	case groupByProject:
+		return projectColumn.header
+	case groupByLocation:
+		return locationColumn.header
+	case groupByState:
+		return stateColumn.header
+	default:
+		return "Label " + strings.TrimPrefix(by, groupByLabelPrefix)
+	}
+}
+
+// summarizeGroups aggregates the assets by the dimension, sorted by the dimension value.
+// Costs are totaled only if withCost is set.
+func summarizeGroups(assets []ProcessedAsset, by string, withCost bool) (GroupSummary, error) {
+	keyFunc, err := parseGroupBy(by)
+	if err != nil {
+		return GroupSummary{}, err
+	}
+
+	byKey := make(map[string]*Group)
+
+	for _, asset := range assets {
+		key := keyFunc(asset)
+
+		group, ok := byKey[key]
+		if !ok {
+			group = &Group{Key: key}
+			if withCost {
+				group.MonthlyCost = new(float64)
+			}
+
+			byKey[key] = group
+		}
+
+		group.Assets++
+
+		if withCost {
+			*group.MonthlyCost += asset.EstimatedMonthlyCost
+		}
+	}
+
+	summary := GroupSummary{By: by, Groups: []Group{}}
+	for _, key := range slices.Sorted(maps.Keys(byKey)) {
+		summary.Groups = append(summary.Groups, *byKey[key])
+	}
+
+	return summary, nil
+}
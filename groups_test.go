@@ -0,0 +1,52 @@
+package assetwatcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeGroups(t *testing.T) {
+	assets := []ProcessedAsset{
+		{Project: "proj-B", Location: "us-central1", Status: "RESERVED", EstimatedMonthlyCost: 7.3, Labels: map[string]string{"env": "prod"}},
+		{Project: "proj-A", Location: "us-central1", Status: "IN_USE", Labels: map[string]string{"env": "dev"}},
+		{Project: "proj-B", Location: "europe-west1", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+	}
+
+	cost := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		by       string
+		withCost bool
+		want     []Group
+	}{
+		{name: "project", by: "project", want: []Group{{Key: "proj-A", Assets: 1}, {Key: "proj-B", Assets: 2}}},
+		{name: "location", by: "location", want: []Group{{Key: "europe-west1", Assets: 1}, {Key: "us-central1", Assets: 2}}},
+		{
+			name: "state with cost", by: "state", withCost: true,
+			want: []Group{{Key: "IN_USE", Assets: 1, MonthlyCost: cost(0)}, {Key: "RESERVED", Assets: 2, MonthlyCost: cost(14.6)}},
+		},
+		{name: "label", by: "label:env", want: []Group{{Key: noLabelGroup, Assets: 1}, {Key: "dev", Assets: 1}, {Key: "prod", Assets: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := summarizeGroups(assets, tt.by, tt.withCost)
+			if err != nil {
+				t.Fatalf("summarizeGroups() failed: %v", err)
+			}
+
+			if got.By != tt.by || !reflect.DeepEqual(got.Groups, tt.want) {
+				t.Errorf("summarizeGroups() = %+v, want %+v", got.Groups, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGroupBy_Invalid(t *testing.T) {
+	for _, by := range []string{"", "zone", "label:", "labels:env"} {
+		if _, err := parseGroupBy(by); err == nil {
+			t.Errorf("expected an error for %q", by)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+var (
+	errRunNotFound  = errors.New("run not found")
+	errInvalidRunID = errors.New("invalid run ID")
+	errInvalidAsOf  = errors.New("invalid date, expected YYYY-MM-DD or RFC 3339")
+	errNoHistory    = errors.New("ASSET_WATCHER_HISTORY_DIR must be set to read stored runs")
+
+	runIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// RunStore is an interface for storing the reports of past runs.
+type RunStore interface {
+	Save(ctx context.Context, report *Report) error
+	Load(ctx context.Context, runID string) (*Report, error)
+	// AsOf returns the report of the latest run started at or before the time.
+	AsOf(ctx context.Context, t time.Time) (*Report, error)
+	// List returns the reports of all stored runs, oldest first.
+	List(ctx context.Context) ([]*Report, error)
+}
+
+// FileRunStore stores the report of each run as a JSON file named after the run ID.
+type FileRunStore struct {
+	dir string
+}
+
+// NewFileRunStore creates a new run store in the directory.
+func NewFileRunStore(dir string) *FileRunStore {
+	return &FileRunStore{dir: dir}
+}
+
+// Save writes the report, creating the directory if needed.
+func (s *FileRunStore) Save(_ context.Context, report *Report) error {
+	path, err := s.path(report.Metadata.RunID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the report of the run.
+func (s *FileRunStore) Load(_ context.Context, runID string) (*Report, error) {
+	path, err := s.path(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := readReportFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", errRunNotFound, runID)
+	}
+
+	return report, err
+}
+
+func readReportFile(path string) (*Report, error) {
+	f, err := os.Open(path) //nolint:gosec // The path is within the history directory.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer f.Close()
+
+	report, err := ReadReport(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	return report, nil
+}
+
+// parseAsOf parses the time of an --as-of query. A date refers to the end of that day in UTC,
+// so that the runs of the day are included.
+func parseAsOf(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", errInvalidAsOf, s)
+	}
+
+	return t, nil
+}
+
+// AsOf returns the latest stored report started at or before the time.
+func (s *FileRunStore) AsOf(ctx context.Context, t time.Time) (*Report, error) {
+	reports, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(reports) - 1; i >= 0; i-- {
+		if !reports[i].Metadata.StartedAt.After(t) {
+			return reports[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w as of %s", errRunNotFound, t.Format(time.RFC3339))
+}
+
+// List reads every stored report, ordered by the start time of the run.
+func (s *FileRunStore) List(_ context.Context) ([]*Report, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list history directory: %w", err)
+	}
+
+	reports := []*Report{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		report, err := readReportFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, report)
+	}
+
+	slices.SortStableFunc(reports, func(a, b *Report) int {
+		return a.Metadata.StartedAt.Compare(b.Metadata.StartedAt)
+	})
+
+	return reports, nil
+}
+
+// path returns the path of the report of the run, rejecting run IDs that are not plain names.
+func (s *FileRunStore) path(runID string) (string, error) {
+	if !runIDPattern.MatchString(runID) {
+		return "", fmt.Errorf("%w: %q", errInvalidRunID, runID)
+	}
+
+	return filepath.Join(s.dir, runID+".json"), nil
+}
+
+// runStoreSink saves every report to the run store.
+type runStoreSink struct {
+	store RunStore
+}
+
+// Name returns the name of the sink.
+func (s runStoreSink) Name() string {
+	return "history"
+}
+
+// Publish saves the report.
+func (s runStoreSink) Publish(ctx context.Context, report *Report) error {
+	return s.store.Save(ctx, report)
+}
+
+// Close is a no-op, as the file store does not hold any resources.
+func (s runStoreSink) Close() error {
+	return nil
+}
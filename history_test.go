@@ -0,0 +1,90 @@
+package assetwatcher
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFileRunStore(t *testing.T) {
+	ctx := t.Context()
+	store := NewFileRunStore(t.TempDir() + "/runs")
+
+	report := &Report{
+		Metadata: RunMetadata{RunID: "abc123", StartedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Assets:   []ProcessedAsset{{Name: "a1", IPAddress: "203.0.113.1"}},
+	}
+
+	if err := (runStoreSink{store: store}).Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	got, err := store.Load(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !got.Metadata.StartedAt.Equal(report.Metadata.StartedAt) || len(got.Assets) != 1 || got.Assets[0].Name != "a1" {
+		t.Errorf("unexpected report %+v", got)
+	}
+
+	if _, err := store.Load(ctx, "missing"); !errors.Is(err, errRunNotFound) {
+		t.Errorf("expected errRunNotFound, got %v", err)
+	}
+
+	if _, err := store.Load(ctx, "../abc123"); !errors.Is(err, errInvalidRunID) {
+		t.Errorf("expected errInvalidRunID, got %v", err)
+	}
+}
+
+func TestFileRunStore_AsOf(t *testing.T) {
+	ctx := t.Context()
+	store := NewFileRunStore(t.TempDir())
+
+	if _, err := store.AsOf(ctx, time.Now()); !errors.Is(err, errRunNotFound) {
+		t.Errorf("expected errRunNotFound for an empty history, got %v", err)
+	}
+
+	for i, day := range []int{1, 3, 5} {
+		report := &Report{Metadata: RunMetadata{
+			RunID:     "run" + strconv.Itoa(i),
+			StartedAt: time.Date(2024, 6, day, 12, 0, 0, 0, time.UTC),
+		}}
+		if err := store.Save(ctx, report); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		asOf    string
+		want    string
+		wantErr error
+	}{
+		{asOf: "2024-05-31", wantErr: errRunNotFound},
+		{asOf: "2024-06-01", want: "run0"},
+		{asOf: "2024-06-04", want: "run1"},
+		{asOf: "2024-06-05T11:00:00Z", want: "run1"},
+		{asOf: "2024-06-05T12:00:00Z", want: "run2"},
+		{asOf: "2025-01-01", want: "run2"},
+		{asOf: "June 1st", wantErr: errInvalidAsOf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.asOf, func(t *testing.T) {
+			asOf, err := parseAsOf(tt.asOf)
+			if err == nil {
+				var report *Report
+
+				report, err = store.AsOf(ctx, asOf)
+				if err == nil && report.Metadata.RunID != tt.want {
+					t.Errorf("expected %s, got %s", tt.want, report.Metadata.RunID)
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
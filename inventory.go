@@ -0,0 +1,301 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// inventorySourceScan and inventorySourceHistory are the sources of the compared inventories
+	// of GET /v1/diff.
+	inventorySourceScan    = "scan"
+	inventorySourceHistory = "history"
+)
+
+var errInvalidQuery = errors.New("invalid query")
+
+// inventoryCache holds the reports of the latest two scans of the serve command, which the
+// inventory API answers from, so that its clients need no Google Cloud credentials.
+type inventoryCache struct {
+	mu       sync.RWMutex
+	previous *Report
+	latest   *Report
+}
+
+// store replaces the latest report, which becomes the previous one.
+func (c *inventoryCache) store(report *Report) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.previous, c.latest = c.latest, report
+}
+
+// reports returns the reports of the previous and the latest scans, nil until they finish.
+func (c *inventoryCache) reports() (*Report, *Report) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.previous, c.latest
+}
+
+// runInventoryScans scans the organization on the schedule of ASSET_WATCHER_INTERVAL until the
+// context is canceled, storing the report of every scan in the cache. The scans are neither
+// written to the output nor published to the sinks; a failed scan keeps the cached report.
+func runInventoryScans(ctx context.Context, logger *slog.Logger, cfg *Config, schedule daemonSchedule,
+	stores *runStores, cache *inventoryCache,
+) {
+	logger.InfoContext(ctx, "Scanning the inventory of the API",
+		slog.Duration("interval", schedule.interval),
+		slog.Duration("jitter", schedule.jitter),
+		slog.Duration("timeout", schedule.timeout),
+	)
+
+	daemonLoop(ctx, schedule, func(ctx context.Context) {
+		runDaemonScan(ctx, logger, cfg, schedule.timeout, func(ctx context.Context, startedAt time.Time) {
+			cache.store(runScan(ctx, logger, cfg, stores, startedAt))
+		})
+	})
+}
+
+// inventoryAssets is the response of GET /v1/assets.
+type inventoryAssets struct {
+	Metadata RunMetadata      `json:"metadata"`
+	Assets   []ProcessedAsset `json:"assets"`
+}
+
+// inventorySummary is the response of GET /v1/summary.
+type inventorySummary struct {
+	Metadata   RunMetadata `json:"metadata"`
+	Summary    Summary     `json:"summary"`
+	Violations int         `json:"violations"`
+}
+
+// registerInventoryAPI adds the endpoints of the inventory of the cache to the mux.
+func registerInventoryAPI(mux *http.ServeMux, logger *slog.Logger, cfg *Config, cache *inventoryCache) {
+	times, _ := newTimeFormatter(cfg.TimeFormat, cfg.TimeZone) // Validated by GetConfig.
+
+	mux.HandleFunc("GET /v1/assets", func(w http.ResponseWriter, r *http.Request) {
+		_, latest := cache.reports()
+		if latest == nil {
+			http.Error(w, "no scan has finished yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		query, err := newAssetQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		assets := query.filter(latest.Assets, time.Now())
+		if times != nil {
+			assets = times.assets(assets)
+		}
+
+		writeJSON(r.Context(), logger, w, inventoryAssets{Metadata: latest.Metadata, Assets: assets})
+	})
+
+	mux.HandleFunc("GET /v1/summary", func(w http.ResponseWriter, r *http.Request) {
+		_, latest := cache.reports()
+		if latest == nil {
+			http.Error(w, "no scan has finished yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		writeJSON(r.Context(), logger, w, inventorySummary{
+			Metadata:   latest.Metadata,
+			Summary:    latest.Summary,
+			Violations: len(latest.Violations),
+		})
+	})
+
+	mux.HandleFunc("GET /v1/diff", inventoryDiffHandler(logger, cfg, cache))
+}
+
+// inventoryDiffHandler compares the latest scan with the previous one or, with the since query
+// parameter, a date or time such as 2024-01-10, with the run stored in the history at the time.
+func inventoryDiffHandler(logger *slog.Logger, cfg *Config, cache *inventoryCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		previous, latest := cache.reports()
+		if latest == nil {
+			http.Error(w, "no scan has finished yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		source := inventorySourceScan
+
+		if since := r.URL.Query().Get("since"); since != "" {
+			report, err := reportAsOf(r.Context(), cfg, since)
+
+			switch {
+			case errors.Is(err, errNoHistory), errors.Is(err, errInvalidAsOf):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			case errors.Is(err, errRunNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+
+				return
+			case err != nil:
+				logger.ErrorContext(r.Context(), "failed to read the run from history", slog.Any("error", err))
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+				return
+			}
+
+			previous, source = report, inventorySourceHistory
+		}
+
+		if previous == nil {
+			http.Error(w, "no previous scan to compare with yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		writeJSON(r.Context(), logger, w, SnapshotDiff{
+			Old:   inventoryInfo(source, previous),
+			New:   inventoryInfo(inventorySourceScan, latest),
+			Diffs: diffAssets(previous.Assets, latest.Assets),
+		})
+	}
+}
+
+func inventoryInfo(source string, report *Report) SnapshotInfo {
+	return SnapshotInfo{
+		Source:  source,
+		RunID:   report.Metadata.RunID,
+		TakenAt: report.Metadata.FinishedAt,
+		Assets:  len(report.Assets),
+	}
+}
+
+// assetQueryParams are the query parameters of GET /v1/assets, the filters of the configuration
+// named after their environment variables without the prefix, such as include_projects.
+var assetQueryParams = map[string]func(cfg *Config) *string{
+	"include_projects":       func(cfg *Config) *string { return &cfg.IncludeProjects },
+	"exclude_projects":       func(cfg *Config) *string { return &cfg.ExcludeProjects },
+	"include_labels":         func(cfg *Config) *string { return &cfg.IncludeLabels },
+	"exclude_labels":         func(cfg *Config) *string { return &cfg.ExcludeLabels },
+	"name_regex":             func(cfg *Config) *string { return &cfg.NameRegex },
+	"exclude_name_regex":     func(cfg *Config) *string { return &cfg.ExcludeNameRegex },
+	"project_regex":          func(cfg *Config) *string { return &cfg.ProjectRegex },
+	"exclude_project_regex":  func(cfg *Config) *string { return &cfg.ExcludeProjectRegex },
+	"location_regex":         func(cfg *Config) *string { return &cfg.LocationRegex },
+	"exclude_location_regex": func(cfg *Config) *string { return &cfg.ExcludeLocationRegex },
+	"filter_expr":            func(cfg *Config) *string { return &cfg.FilterExpr },
+	"min_age":                func(cfg *Config) *string { return &cfg.MinAge },
+	"max_age":                func(cfg *Config) *string { return &cfg.MaxAge },
+}
+
+// excludeReservedParam is the boolean query parameter of ASSET_WATCHER_EXCLUDE_RESERVED.
+const excludeReservedParam = "exclude_reserved"
+
+// assetQuery selects the cached assets as the filters of the configuration select the scanned ones.
+type assetQuery struct {
+	includeProjects []string
+	excludeProjects []string
+	includeLabels   map[string]string
+	excludeLabels   map[string]string
+	excludeReserved bool
+	regexFilters    assetRegexFilters
+	exprFilter      *celFilter
+	ages            ageFilter
+}
+
+// newAssetQuery parses the query parameters of GET /v1/assets. Unknown parameters are rejected,
+// so that a misspelled filter does not return every asset.
+func newAssetQuery(values url.Values) (*assetQuery, error) {
+	var cfg Config
+
+	for name, value := range values {
+		if name == excludeReservedParam {
+			excludeReserved, err := strconv.ParseBool(value[len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be true or false", errInvalidQuery, name)
+			}
+
+			cfg.ExcludeReserved = excludeReserved
+
+			continue
+		}
+
+		field, ok := assetQueryParams[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown parameter %q", errInvalidQuery, name)
+		}
+
+		*field(&cfg) = value[len(value)-1]
+	}
+
+	query := &assetQuery{
+		includeProjects: splitString(cfg.IncludeProjects, ","),
+		excludeProjects: splitString(cfg.ExcludeProjects, ","),
+		excludeReserved: cfg.ExcludeReserved,
+	}
+
+	var err error
+
+	if query.includeLabels, err = parseLabels(cfg.IncludeLabels); err != nil {
+		return nil, fmt.Errorf("%w: include_labels: %w", errInvalidQuery, err)
+	}
+
+	if query.excludeLabels, err = parseLabels(cfg.ExcludeLabels); err != nil {
+		return nil, fmt.Errorf("%w: exclude_labels: %w", errInvalidQuery, err)
+	}
+
+	if query.regexFilters, err = newAssetRegexFilters(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidQuery, err)
+	}
+
+	if query.exprFilter, err = newCELFilter(cfg.FilterExpr); err != nil {
+		return nil, fmt.Errorf("%w: filter_expr: %w", errInvalidQuery, err)
+	}
+
+	if query.ages, err = newAgeFilter(cfg.MinAge, cfg.MaxAge); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidQuery, err)
+	}
+
+	return query, nil
+}
+
+// filter returns the assets matching the query. The assets the expression fails to evaluate
+// against are skipped, as in the scan.
+func (q *assetQuery) filter(assets []ProcessedAsset, now time.Time) []ProcessedAsset {
+	return slices.DeleteFunc(slices.Clone(assets), func(asset ProcessedAsset) bool {
+		return !q.matches(asset, now)
+	})
+}
+
+func (q *assetQuery) matches(asset ProcessedAsset, now time.Time) bool {
+	if q.excludeReserved && asset.Status == addressStatusReserved {
+		return false
+	}
+
+	if !selectsProject(q.includeProjects, q.excludeProjects, asset.Project) {
+		return false
+	}
+
+	if matchesAnyLabel(asset.Labels, q.excludeLabels) || !matchesAllLabels(asset.Labels, q.includeLabels) {
+		return false
+	}
+
+	if !q.regexFilters.matches(asset) || !q.ages.matches(asset, now) {
+		return false
+	}
+
+	match, _ := q.exprFilter.matches(asset)
+
+	return match
+}
@@ -0,0 +1,202 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAssetQuery(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	assets := []ProcessedAsset{
+		{Name: "web", Project: "prod", Status: addressStatusInUse, Location: "europe-west1",
+			Labels: map[string]string{"env": "prod"}, CreatedAt: "2024-01-08 12:00:00"},
+		{Name: "spare", Project: "prod", Status: addressStatusReserved, Location: "us-east1",
+			CreatedAt: "2024-01-10 11:00:00"},
+		{Name: "test", Project: "dev", Status: addressStatusReserved, Location: "europe-west1",
+			Labels: map[string]string{"env": "dev"}, CreatedAt: "2024-01-07 12:00:00"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "no filters", want: []string{"web", "spare", "test"}},
+		{name: "included projects", query: "include_projects=prod", want: []string{"web", "spare"}},
+		{name: "excluded projects", query: "exclude_projects=prod", want: []string{"test"}},
+		{name: "excluded reserved", query: "exclude_reserved=true", want: []string{"web"}},
+		{name: "labels", query: "include_labels=env=dev", want: []string{"test"}},
+		{name: "regex", query: "location_regex=^europe-&exclude_name_regex=^test$", want: []string{"web"}},
+		{name: "expression", query: "filter_expr=" + url.QueryEscape(`asset.status == "RESERVED"`),
+			want: []string{"spare", "test"}},
+		{name: "minimum age", query: "min_age=1d", want: []string{"web", "test"}},
+		{name: "unknown parameter", query: "projects=prod", wantErr: true},
+		{name: "invalid boolean", query: "exclude_reserved=maybe", wantErr: true},
+		{name: "invalid regex", query: "name_regex=(", wantErr: true},
+		{name: "invalid expression", query: "filter_expr=" + url.QueryEscape("asset.name =="), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("failed to parse the query: %v", err)
+			}
+
+			query, err := newAssetQuery(values)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidQuery) {
+					t.Fatalf("newAssetQuery() error = %v, want errInvalidQuery", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("newAssetQuery() error = %v", err)
+			}
+
+			var got []string
+			for _, asset := range query.filter(assets, now) {
+				got = append(got, asset.Name)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("filter() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("filter() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestInventoryAPI(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+
+	cache := &inventoryCache{}
+	mux := newServeMux(slog.New(slog.DiscardHandler), &cfg, cache, nil)
+
+	if rec := serveRequest(mux, "/v1/assets"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 before the first scan, got %d", rec.Code)
+	}
+
+	createTime := time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC)
+	web := ProcessedAsset{Name: "web", Project: "prod", Status: addressStatusInUse, IPAddress: "203.0.113.10",
+		CreatedAt: "2024-01-10 23:30:00", CreateTime: createTime}
+	spare := ProcessedAsset{Name: "spare", Project: "dev", Status: addressStatusReserved, IPAddress: "203.0.113.11",
+		CreatedAt: "2024-01-10 23:30:00", CreateTime: createTime}
+
+	cache.store(NewReport(t.Context(), &cfg, time.Now(), []ProcessedAsset{web}))
+
+	if rec := serveRequest(mux, "/v1/diff"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 without a previous scan, got %d", rec.Code)
+	}
+
+	cache.store(NewReport(t.Context(), &cfg, time.Now(), []ProcessedAsset{web, spare}))
+
+	var assets inventoryAssets
+
+	decodeResponse(t, serveRequest(mux, "/v1/assets?include_projects=dev"), &assets)
+
+	if assets.Metadata.OrgID != "123" || len(assets.Assets) != 1 || assets.Assets[0].Name != "spare" {
+		t.Errorf("unexpected assets %+v", assets)
+	}
+
+	if rec := serveRequest(mux, "/v1/assets?project=dev"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown parameter, got %d", rec.Code)
+	}
+
+	var summary inventorySummary
+
+	decodeResponse(t, serveRequest(mux, "/v1/summary"), &summary)
+
+	if summary.Summary.TotalAssets != 2 || summary.Summary.ByStatus[addressStatusReserved] != 1 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	var diff SnapshotDiff
+
+	decodeResponse(t, serveRequest(mux, "/v1/diff"), &diff)
+
+	if diff.Old.Assets != 1 || diff.New.Assets != 2 || len(diff.Diffs) != 1 ||
+		diff.Diffs[0].Type != DiffAdded || diff.Diffs[0].Asset.Name != "spare" {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	if rec := serveRequest(mux, "/v1/diff?since=2024-01-10"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a history, got %d", rec.Code)
+	}
+}
+
+func TestRunInventoryScans(t *testing.T) {
+	ctx, cancel := context.WithCancel(withFakeAssetServer(t,
+		selftestAddress("prod", "web", addressStatusInUse, "203.0.113.10"),
+	))
+	defer cancel()
+
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+
+	cache := &inventoryCache{}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		runInventoryScans(ctx, slog.New(slog.DiscardHandler), &cfg, daemonSchedule{interval: time.Hour, timeout: time.Minute},
+			&runStores{}, cache)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		if _, latest := cache.reports(); latest != nil {
+			if len(latest.Assets) != 1 || latest.Assets[0].Name != "web" {
+				t.Errorf("unexpected cached assets %+v", latest.Assets)
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the scan was not cached")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+func serveRequest(handler http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	return rec
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("failed to decode the response %q: %v", rec.Body.String(), err)
+	}
+}
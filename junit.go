@@ -0,0 +1,157 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// outputFormatJUnit renders the policy checks of every asset as JUnit XML test cases, so CI
+// pipelines such as Jenkins and GitLab fail on violations and display them natively.
+const outputFormatJUnit = "junit"
+
+// junitRules are the policy rules checked for every asset, each reported as a test suite.
+var junitRules = []string{
+	ruleOrphanedExternalAddress,
+	ruleInstanceExternalIP,
+	ruleInternetExposedPort,
+	ruleBlocklistedAddress,
+	ruleOutOfBandAddress,
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr,omitempty"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Skipped   *junitSkipped `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// newJUnitTestSuites returns a test suite per policy rule, with a test case per asset that
+// fails if the asset violates the rule. Acknowledged violations are skipped test cases.
+func newJUnitTestSuites(report *Report) junitTestSuites {
+	violations := map[string]RuleViolation{}
+	for _, v := range report.Violations {
+		violations[v.Rule+"\x00"+assetKey(v.Asset)] = v
+	}
+
+	acknowledged := map[string]RuleViolation{}
+	for _, v := range report.Acknowledged {
+		acknowledged[v.Rule+"\x00"+assetKey(v.Asset)] = v
+	}
+
+	suites := junitTestSuites{Name: "asset-watcher organization " + report.Metadata.OrgID}
+
+	if !report.Metadata.FinishedAt.IsZero() {
+		suites.Time = strconv.FormatFloat(report.Metadata.FinishedAt.Sub(report.Metadata.StartedAt).Seconds(), 'f', 3, 64)
+	}
+
+	for _, rule := range junitRules {
+		suite := junitTestSuite{Name: rule, Cases: make([]junitTestCase, 0, len(report.Assets))}
+		if !report.Metadata.StartedAt.IsZero() {
+			suite.Timestamp = report.Metadata.StartedAt.UTC().Format("2006-01-02T15:04:05")
+		}
+
+		for _, asset := range report.Assets {
+			key := rule + "\x00" + assetKey(asset)
+			testCase := junitTestCase{Name: asset.Name + " " + asset.IPAddress, ClassName: asset.Project + "." + asset.Location}
+
+			if v, ok := violations[key]; ok {
+				testCase.Failure = &junitFailure{Message: v.Message, Type: v.Severity, Text: junitFailureText(v)}
+				suite.Failures++
+			} else if v, ok := acknowledged[key]; ok {
+				testCase.Skipped = &junitSkipped{Message: "acknowledged"}
+				if v.Acknowledgment != nil && v.Acknowledgment.Reason != "" {
+					testCase.Skipped.Message += ": " + v.Acknowledgment.Reason
+				}
+
+				suite.Skipped++
+			}
+
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	return suites
+}
+
+// junitFailureText describes the asset of the violation in the body of the failure.
+func junitFailureText(v RuleViolation) string {
+	lines := []string{
+		"rule: " + v.Rule,
+		"severity: " + v.Severity,
+		"project: " + v.Asset.Project,
+		"address: " + v.Asset.IPAddress,
+	}
+
+	if v.Asset.ResourceName != "" {
+		lines = append(lines, "resource: "+v.Asset.ResourceName)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// writeJUnit writes the policy checks of the report as a JUnit XML document.
+func writeJUnit(w io.Writer, report *Report) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit XML: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(newJUnitTestSuites(report)); err != nil {
+		return fmt.Errorf("failed to encode JUnit XML: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit XML: %w", err)
+	}
+
+	return nil
+}
+
+func outputJUnit(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report) {
+	if err := writeJUnit(w, report); err != nil {
+		logger.ErrorContext(ctx, "failed to write JUnit XML", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
@@ -0,0 +1,63 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	spare := ProcessedAsset{Name: "spare", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.11", ResourceName: "//spare"}
+	nat := ProcessedAsset{Name: "nat", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.12", ResourceName: "//nat"}
+
+	report := &Report{
+		Metadata: RunMetadata{OrgID: "123"},
+		Assets:   []ProcessedAsset{spare, nat},
+		Violations: []RuleViolation{
+			{Rule: ruleOrphanedExternalAddress, Severity: severityMedium, Message: "reserved but not used", Asset: spare},
+		},
+		Acknowledged: []RuleViolation{
+			{Rule: ruleOutOfBandAddress, Severity: severityHigh, Asset: nat, Acknowledgment: &Acknowledgment{Reason: "migration"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJUnit(&buf, report); err != nil {
+		t.Fatalf("writeJUnit failed: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("expected an XML declaration, got %q", buf.String()[:min(buf.Len(), 40)])
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("failed to decode the JUnit XML: %v", err)
+	}
+
+	if suites.Tests != 2*len(junitRules) || suites.Failures != 1 || suites.Skipped != 1 || len(suites.Suites) != len(junitRules) {
+		t.Fatalf("unexpected totals: %d tests, %d failures, %d skipped, %d suites",
+			suites.Tests, suites.Failures, suites.Skipped, len(suites.Suites))
+	}
+
+	orphaned := suites.Suites[0]
+	if orphaned.Name != ruleOrphanedExternalAddress || orphaned.Failures != 1 {
+		t.Fatalf("unexpected suite %+v", orphaned)
+	}
+
+	failure := orphaned.Cases[0].Failure
+	if failure == nil || failure.Message != "reserved but not used" || failure.Type != severityMedium ||
+		!strings.Contains(failure.Text, "resource: //spare") || orphaned.Cases[1].Failure != nil {
+		t.Errorf("unexpected test cases %+v", orphaned.Cases)
+	}
+
+	if got := orphaned.Cases[0]; got.Name != "spare 203.0.113.11" || got.ClassName != "prod.us-central1" {
+		t.Errorf("unexpected test case name %q and class name %q", got.Name, got.ClassName)
+	}
+
+	skipped := suites.Suites[len(suites.Suites)-1].Cases[1].Skipped
+	if skipped == nil || skipped.Message != "acknowledged: migration" {
+		t.Errorf("expected the acknowledged violation to be skipped, got %+v", skipped)
+	}
+}
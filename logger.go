@@ -1,56 +1,219 @@
-package main
+package assetwatcher
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Custom levels matching the Cloud Logging severities that slog has no level for.
+const (
+	LevelNotice    = slog.Level(2)
+	LevelCritical  = slog.Level(12)
+	LevelAlert     = slog.Level(16)
+	LevelEmergency = slog.Level(20)
+)
+
+var (
+	errInvalidLogLevel    = errors.New("invalid log level")
+	errInvalidLogSeverity = errors.New("invalid Cloud Logging severity")
+)
+
+// logLevels maps the names of the levels to the levels.
+var logLevels = map[string]slog.Level{
+	"DEBUG":     slog.LevelDebug,
+	"INFO":      slog.LevelInfo,
+	"NOTICE":    LevelNotice,
+	"WARN":      slog.LevelWarn,
+	"ERROR":     slog.LevelError,
+	"CRITICAL":  LevelCritical,
+	"ALERT":     LevelAlert,
+	"EMERGENCY": LevelEmergency,
+}
+
+// defaultSeverities maps the levels to Cloud Logging LogSeverity values.
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogSeverity
+var defaultSeverities = map[slog.Level]string{
+	slog.LevelDebug: "DEBUG",
+	slog.LevelInfo:  "INFO",
+	LevelNotice:     "NOTICE",
+	slog.LevelWarn:  "WARNING",
+	slog.LevelError: "ERROR",
+	LevelCritical:   "CRITICAL",
+	LevelAlert:      "ALERT",
+	LevelEmergency:  "EMERGENCY",
+}
+
+// logSeverities are the LogSeverity values accepted by Cloud Logging.
+var logSeverities = []string{"DEFAULT", "DEBUG", "INFO", "NOTICE", "WARNING", "ERROR", "CRITICAL", "ALERT", "EMERGENCY"}
+
 func setupLogging(cfg *Config) *slog.Logger {
+	return newLogger(cfg, os.Stdout)
+}
+
+// newLogger returns a logger writing structured logs to w.
+func newLogger(cfg *Config, w io.Writer) *slog.Logger {
 	logLevel := slog.LevelInfo
 	if cfg.Debug {
 		logLevel = slog.LevelDebug
 	}
 
+	// The severities are validated by GetConfig.
+	severities, _ := parseLogSeverities(cfg.LogSeverities)
+
 	// Use json as our base logging format.
 	jsonHandler := slog.NewJSONHandler(
-		os.Stdout,
-		&slog.HandlerOptions{ReplaceAttr: convertSlogToCloudLogging, Level: logLevel},
+		w,
+		&slog.HandlerOptions{ReplaceAttr: newCloudLoggingReplacer(severities), Level: logLevel},
 	)
 	// Add span context attributes when Context is passed to logging calls.
 	instrumentedHandler := handlerWithSpanContext(jsonHandler)
 
-	logger := slog.New(instrumentedHandler)
+	// Sample repetitive debug records and limit the records of every run.
+	logger := slog.New(handlerWithSampling(instrumentedHandler, cfg.DebugLogSampling, cfg.LogBudget))
 
 	return logger
 }
 
+// Keys of the log attributes identifying the run and the serve mode request.
+const (
+	runIDLogKey     = "run_id"
+	requestIDLogKey = "request_id"
+)
+
+type logContextKey int
+
+const (
+	runIDContextKey logContextKey = iota
+	requestIDContextKey
+)
+
+// withRunID returns a context carrying the ID of the run, added to every log record
+// and outbound request made with the context.
+func withRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDContextKey, runID)
+}
+
+// runIDFromContext returns the ID of the run of the context, or an empty string.
+func runIDFromContext(ctx context.Context) string {
+	runID, _ := ctx.Value(runIDContextKey).(string)
+
+	return runID
+}
+
+// withRequestID returns a context carrying the ID of a serve mode request.
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// requestIDFromContext returns the ID of the request of the context, or an empty string.
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+
+	return requestID
+}
+
 // spanContextLogHandler is a slog.Handler which adds attributes from the
-// span context.
+// span context, such as the IDs of the run and the request.
 type spanContextLogHandler struct {
 	slog.Handler
 }
 
-func convertSlogToCloudLogging(_ []string, a slog.Attr) slog.Attr {
-	// Rename attribute keys to match Cloud Logging structured log format
-	switch a.Key {
-	case slog.LevelKey:
-		a.Key = "severity"
-		// Map slog.Level string values to Cloud Logging LogSeverity
-		// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogSeverity
-		if level, ok := a.Value.Any().(slog.Level); ok && level == slog.LevelWarn {
-			a.Value = slog.StringValue("WARNING")
+// Handle adds the IDs of the run and the request of the context to the record.
+func (h *spanContextLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if runID := runIDFromContext(ctx); runID != "" {
+		record.AddAttrs(slog.String(runIDLogKey, runID))
+	}
+
+	if requestID := requestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String(requestIDLogKey, requestID))
+	}
+
+	return h.Handler.Handle(ctx, record) //nolint:wrapcheck // The handler errors are returned as is.
+}
+
+// WithAttrs returns a handler that keeps adding the span context attributes.
+func (h *spanContextLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &spanContextLogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that keeps adding the span context attributes.
+func (h *spanContextLogHandler) WithGroup(name string) slog.Handler {
+	return &spanContextLogHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// parseLogSeverities parses a comma-separated list of LEVEL=SEVERITY overrides, such as
+// "WARN=NOTICE,ERROR=CRITICAL", and returns the default mapping with the overrides applied.
+func parseLogSeverities(s string) (map[slog.Level]string, error) {
+	severities := maps.Clone(defaultSeverities)
+
+	for _, override := range splitString(s, ",") {
+		name, severity, _ := strings.Cut(override, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		severity = strings.ToUpper(strings.TrimSpace(severity))
+
+		level, ok := logLevels[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errInvalidLogLevel, name)
 		}
-	case slog.TimeKey:
-		a.Key = "timestamp"
-		if t, ok := a.Value.Any().(time.Time); ok {
-			a.Value = slog.TimeValue(t.UTC())
+
+		if !slices.Contains(logSeverities, severity) {
+			return nil, fmt.Errorf("%w: %q", errInvalidLogSeverity, severity)
 		}
-	case slog.MessageKey:
-		a.Key = "message"
+
+		severities[level] = severity
 	}
 
-	return a
+	return severities, nil
+}
+
+// severityOf returns the severity of the highest mapped level not above the level,
+// so that levels between the named ones, such as INFO+1, keep the lower severity.
+func severityOf(severities map[slog.Level]string, level slog.Level) string {
+	best, found := slog.Level(0), false
+
+	for l := range severities {
+		if l <= level && (!found || l > best) {
+			best, found = l, true
+		}
+	}
+
+	if !found {
+		return "DEFAULT"
+	}
+
+	return severities[best]
+}
+
+// newCloudLoggingReplacer returns a slog.HandlerOptions.ReplaceAttr function converting
+// the attributes to the Cloud Logging structured log format, with the severities of the levels.
+func newCloudLoggingReplacer(severities map[slog.Level]string) func([]string, slog.Attr) slog.Attr {
+	return func(_ []string, a slog.Attr) slog.Attr {
+		// Rename attribute keys to match Cloud Logging structured log format
+		switch a.Key {
+		case slog.LevelKey:
+			a.Key = "severity"
+			if level, ok := a.Value.Any().(slog.Level); ok {
+				a.Value = slog.StringValue(severityOf(severities, level))
+			}
+		case slog.TimeKey:
+			a.Key = "timestamp"
+			if t, ok := a.Value.Any().(time.Time); ok {
+				a.Value = slog.TimeValue(t.UTC())
+			}
+		case slog.MessageKey:
+			a.Key = "message"
+		}
+
+		return a
+	}
 }
 
 // handlerWithSpanContext adds attributes from the span context.
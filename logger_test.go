@@ -0,0 +1,80 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNewLogger_Severities(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := newLogger(&Config{Debug: true, LogSeverities: "warn=notice, ERROR=CRITICAL"}, &buf)
+	ctx := t.Context()
+
+	logger.DebugContext(ctx, "debug")
+	logger.InfoContext(ctx, "info")
+	logger.Log(ctx, slog.LevelInfo+1, "info+1")
+	logger.WarnContext(ctx, "warn")
+	logger.ErrorContext(ctx, "error")
+	logger.Log(ctx, LevelAlert, "alert")
+
+	want := []string{"DEBUG", "INFO", "INFO", "NOTICE", "CRITICAL", "ALERT"}
+
+	decoder := json.NewDecoder(&buf)
+	for i, severity := range want {
+		var entry map[string]any
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("failed to decode log entry %d: %v", i, err)
+		}
+
+		if entry["severity"] != severity {
+			t.Errorf("entry %q has severity %v, want %s", entry["message"], entry["severity"], severity)
+		}
+	}
+}
+
+func TestSeverityOf_BelowDebug(t *testing.T) {
+	if severity := severityOf(defaultSeverities, slog.LevelDebug-1); severity != "DEFAULT" {
+		t.Errorf("severityOf() = %s, want DEFAULT", severity)
+	}
+}
+
+func TestParseLogSeverities_Invalid(t *testing.T) {
+	for _, s := range []string{"TRACE=DEBUG", "ERROR=SEVERE", "ERROR"} {
+		if _, err := parseLogSeverities(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestNewLogger_ContextIDs(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := newLogger(&Config{}, &buf).With(slog.String("component", "test"))
+	ctx := withRequestID(withRunID(t.Context(), "run1"), "req1")
+
+	logger.InfoContext(ctx, "with IDs")
+	logger.InfoContext(t.Context(), "without IDs")
+
+	decoder := json.NewDecoder(&buf)
+
+	var entry map[string]any
+	if err := decoder.Decode(&entry); err != nil {
+		t.Fatalf("failed to decode log entry: %v", err)
+	}
+
+	if entry[runIDLogKey] != "run1" || entry[requestIDLogKey] != "req1" || entry["component"] != "test" {
+		t.Errorf("unexpected log entry %v", entry)
+	}
+
+	entry = map[string]any{}
+	if err := decoder.Decode(&entry); err != nil {
+		t.Fatalf("failed to decode log entry: %v", err)
+	}
+
+	if _, ok := entry[runIDLogKey]; ok {
+		t.Errorf("unexpected run ID in %v", entry)
+	}
+}
@@ -0,0 +1,114 @@
+package assetwatcher
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// samplingLogHandler is a slog.Handler which limits the volume of the logs of a run, so
+// that debug logging of large organizations does not inflate the Cloud Logging bill.
+// Repetitive debug records, with the same message, are sampled, and the records below
+// WARNING are dropped once the budget of the run is exhausted. Warnings and errors are
+// always logged. The counters are reset when the run ID of the context changes.
+type samplingLogHandler struct {
+	slog.Handler
+
+	state *samplingState
+}
+
+// samplingState is shared by the handlers derived with WithAttrs and WithGroup.
+type samplingState struct {
+	mu sync.Mutex
+
+	sampleEvery int
+	budget      int
+
+	runID     string
+	messages  map[string]int
+	logged    int
+	exhausted bool
+}
+
+// handlerWithSampling returns a handler logging only the first and every sampleEvery-th
+// debug record of every message, and at most budget records below WARNING per run.
+// A value below 2 disables sampling, and a budget of 0 disables the budget.
+func handlerWithSampling(handler slog.Handler, sampleEvery, budget int) slog.Handler {
+	if sampleEvery < 2 && budget == 0 {
+		return handler
+	}
+
+	return &samplingLogHandler{
+		Handler: handler,
+		state:   &samplingState{sampleEvery: sampleEvery, budget: budget, messages: map[string]int{}},
+	}
+}
+
+// Handle logs the record unless it is sampled out or the budget is exhausted. The first
+// record dropped for the budget is replaced with a warning.
+func (h *samplingLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelWarn {
+		return h.Handler.Handle(ctx, record) //nolint:wrapcheck // The handler errors are returned as is.
+	}
+
+	keep, warn := h.state.admit(runIDFromContext(ctx), record)
+	if warn {
+		exhausted := slog.NewRecord(record.Time, slog.LevelWarn,
+			"Log budget of the run exhausted, dropping records below WARNING", record.PC)
+		exhausted.AddAttrs(slog.Int("log_budget", h.state.budget))
+
+		return h.Handler.Handle(ctx, exhausted) //nolint:wrapcheck // The handler errors are returned as is.
+	}
+
+	if !keep {
+		return nil
+	}
+
+	return h.Handler.Handle(ctx, record) //nolint:wrapcheck // The handler errors are returned as is.
+}
+
+// admit reports whether the record is logged, and whether the budget was exhausted by it.
+func (s *samplingState) admit(runID string, record slog.Record) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if runID != s.runID {
+		s.runID = runID
+		s.messages = map[string]int{}
+		s.logged = 0
+		s.exhausted = false
+	}
+
+	if s.exhausted {
+		return false, false
+	}
+
+	if record.Level < slog.LevelInfo && s.sampleEvery > 1 {
+		n := s.messages[record.Message]
+		s.messages[record.Message] = n + 1
+
+		if n%s.sampleEvery != 0 {
+			return false, false
+		}
+	}
+
+	if s.budget > 0 && s.logged >= s.budget {
+		s.exhausted = true
+
+		return false, true
+	}
+
+	s.logged++
+
+	return true, false
+}
+
+// WithAttrs returns a handler sharing the sampling state.
+func (h *samplingLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingLogHandler{Handler: h.Handler.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a handler sharing the sampling state.
+func (h *samplingLogHandler) WithGroup(name string) slog.Handler {
+	return &samplingLogHandler{Handler: h.Handler.WithGroup(name), state: h.state}
+}
@@ -0,0 +1,71 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func decodeLogMessages(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+
+	messages := []string{}
+
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var entry map[string]any
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("failed to decode log entry: %v", err)
+		}
+
+		messages = append(messages, entry["message"].(string))
+	}
+
+	return messages
+}
+
+func TestNewLogger_DebugSampling(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := newLogger(&Config{Debug: true, DebugLogSampling: 3}, &buf)
+	ctx := withRunID(t.Context(), "run1")
+
+	for range 7 {
+		logger.DebugContext(ctx, "Processing asset")
+	}
+
+	logger.DebugContext(ctx, "Processed asset:")
+	logger.InfoContext(ctx, "info")
+	logger.InfoContext(ctx, "info")
+
+	// The first, fourth, and seventh records of the repeated message are logged.
+	want := []string{"Processing asset", "Processing asset", "Processing asset", "Processed asset:", "info", "info"}
+	if got := decodeLogMessages(t, &buf); !slices.Equal(got, want) {
+		t.Errorf("messages = %v, want %v", got, want)
+	}
+}
+
+func TestNewLogger_Budget(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := newLogger(&Config{Debug: true, LogBudget: 2}, &buf).With(slog.String("component", "test"))
+	run1 := withRunID(t.Context(), "run1")
+
+	logger.DebugContext(run1, "one")
+	logger.InfoContext(run1, "two")
+	logger.InfoContext(run1, "three")
+	logger.InfoContext(run1, "four")
+	logger.ErrorContext(run1, "error")
+
+	// The budget is reset for the next run.
+	logger.InfoContext(withRunID(t.Context(), "run2"), "next run")
+
+	want := []string{
+		"one", "two", "Log budget of the run exhausted, dropping records below WARNING", "error", "next run",
+	}
+	if got := decodeLogMessages(t, &buf); !slices.Equal(got, want) {
+		t.Errorf("messages = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// lookupCommand fetches a single address fresh from the API, for incident-response automations
+// that need an authoritative point lookup.
+const lookupCommand = "lookup"
+
+var errLookupArguments = errors.New("usage: lookup --ip ADDRESS | --name projects/P/regions/R/addresses/N")
+
+// AddressSearcher finds the addresses of the organization with an IP address.
+type AddressSearcher interface {
+	SearchAddresses(ctx context.Context, ip string) ([]string, error)
+}
+
+// AddressGetter fetches an address from the Compute Engine API.
+type AddressGetter interface {
+	GetAddress(ctx context.Context, resourceName string) (*compute.Address, error)
+}
+
+// LookupResult is the result of a lookup. Found is false if no address matches.
+type LookupResult struct {
+	Query  string           `json:"query"`
+	Found  bool             `json:"found"`
+	Assets []ProcessedAsset `json:"assets"`
+}
+
+// runLookupCommand looks up an address by its IP address or resource name and writes the
+// result as JSON. The IP address is searched in Cloud Asset Inventory, and every match, like
+// a named address, is fetched from the Compute Engine API, so that the result is authoritative
+// rather than the state of the last snapshot.
+func runLookupCommand(ctx context.Context, logger *slog.Logger, cfg *Config, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(lookupCommand, flag.ContinueOnError)
+	flags.SetOutput(w)
+	ip := flags.String("ip", "", "IP address to look up, such as 34.1.2.3")
+	name := flags.String("name", "", "resource name of the address, such as projects/P/regions/R/addresses/N")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if flags.NArg() > 0 || (*ip == "") == (*name == "") {
+		return errLookupArguments
+	}
+
+	getter, err := NewGoogleAddressDescriber(ctx, logger, clientOptionsFor(ctx, logger, cfg, credentialsCompute)...)
+	if err != nil {
+		return err
+	}
+
+	var searcher AddressSearcher
+
+	if *ip != "" {
+		fetcher, err := NewGoogleAssetFetcher(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsAssets)...)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			if err := fetcher.Close(); err != nil {
+				logger.WarnContext(ctx, "failed to close asset client", slog.Any("error", err))
+			}
+		}()
+
+		searcher = fetcher
+	}
+
+	// The ranges are validated by GetConfig.
+	byoipRanges, _ := parseCIDRs(cfg.BYOIPRanges)
+
+	assets, err := lookupAddresses(ctx, searcher, getter, *ip, *name)
+	if err != nil {
+		return err
+	}
+
+	for i := range assets {
+		assets[i].BYOIP = isBYOIP(assets[i], byoipRanges)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	result := LookupResult{Query: cmp.Or(*ip, *name), Found: len(assets) > 0, Assets: assets}
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode lookup result: %w", err)
+	}
+
+	return nil
+}
+
+// lookupAddresses returns the address named by the resource name, or the addresses with the
+// IP address. Addresses that no longer exist are not returned.
+func lookupAddresses(
+	ctx context.Context,
+	searcher AddressSearcher,
+	getter AddressGetter,
+	ip, name string,
+) ([]ProcessedAsset, error) {
+	names := []string{addressResourceName(name)}
+
+	if ip != "" {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errLookupArguments, err)
+		}
+
+		names, err = searcher.SearchAddresses(ctx, addr.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	assets := []ProcessedAsset{}
+
+	for _, resourceName := range names {
+		address, err := getter.GetAddress(ctx, resourceName)
+
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, addressAsset(resourceName, address))
+	}
+
+	return assets, nil
+}
+
+// addressResourceName returns the full resource name of an address given by its relative
+// resource name, self link, or full resource name.
+func addressResourceName(name string) string {
+	if i := strings.Index(name, "projects/"); i >= 0 {
+		return "//compute.googleapis.com/" + name[i:]
+	}
+
+	return name
+}
+
+// addressAsset converts an address of the Compute Engine API into a processed asset, with
+// the fields and attributes of the assets of a scan.
+func addressAsset(resourceName string, address *compute.Address) ProcessedAsset {
+	// The resource name is validated by GetAddress.
+	project, region, _, _ := parseAddressResourceName(resourceName)
+
+	createdAt := address.CreationTimestamp
+	createTime, err := time.Parse(time.RFC3339, address.CreationTimestamp)
+
+	if err == nil {
+		createTime = createTime.UTC()
+		createdAt = createTime.Format(createdAtLayout)
+	}
+
+	return ProcessedAsset{
+		Name:         address.Name,
+		Location:     cmp.Or(region, "global"),
+		Status:       address.Status,
+		IPAddress:    address.Address,
+		Project:      project,
+		CreatedAt:    createdAt,
+		CreateTime:   createTime,
+		ResourceName: resourceName,
+		AssetType:    addressAssetType,
+		AddressType:  address.AddressType,
+		Labels:       address.Labels,
+		Attributes: map[string]string{
+			"networkTier": address.NetworkTier,
+			"purpose":     address.Purpose,
+			"users":       joinLastPathSegments(address.Users),
+		},
+		Attachments: attachmentKinds(address.Users),
+	}
+}
@@ -0,0 +1,120 @@
+package assetwatcher
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+type fakeAddressSearcher struct {
+	names []string
+	ip    string
+}
+
+func (s *fakeAddressSearcher) SearchAddresses(_ context.Context, ip string) ([]string, error) {
+	s.ip = ip
+
+	return s.names, nil
+}
+
+func newTestAddressGetter(t *testing.T) *GoogleAddressDescriber {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/projects/p/regions/us-central1/addresses/nat-1":
+			_, _ = w.Write([]byte(`{"name": "nat-1", "address": "34.1.2.3", "status": "IN_USE",
+				"region": "https://www.googleapis.com/compute/v1/projects/p/regions/us-central1",
+				"addressType": "EXTERNAL", "networkTier": "PREMIUM", "purpose": "NAT_AUTO",
+				"creationTimestamp": "2024-01-10T04:00:00.000-08:00", "labels": {"team": "net"},
+				"users": ["https://www.googleapis.com/compute/v1/projects/p/regions/us-central1/routers/router-1"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "not found"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	getter, err := NewGoogleAddressDescriber(t.Context(), slog.New(slog.DiscardHandler),
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewGoogleAddressDescriber failed: %v", err)
+	}
+
+	return getter
+}
+
+func TestLookupAddresses(t *testing.T) {
+	getter := newTestAddressGetter(t)
+
+	want := ProcessedAsset{
+		Name:         "nat-1",
+		Location:     "us-central1",
+		Status:       "IN_USE",
+		IPAddress:    "34.1.2.3",
+		Project:      "p",
+		CreatedAt:    "2024-01-10 12:00:00",
+		CreateTime:   time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+		ResourceName: "//compute.googleapis.com/projects/p/regions/us-central1/addresses/nat-1",
+		AssetType:    addressAssetType,
+		AddressType:  "EXTERNAL",
+		Labels:       map[string]string{"team": "net"},
+		Attributes:   map[string]string{"networkTier": "PREMIUM", "purpose": "NAT_AUTO", "users": "router-1"},
+		Attachments:  []string{"routers"},
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		for _, name := range []string{
+			"projects/p/regions/us-central1/addresses/nat-1",
+			"https://www.googleapis.com/compute/v1/projects/p/regions/us-central1/addresses/nat-1",
+		} {
+			got, err := lookupAddresses(t.Context(), nil, getter, "", name)
+			if err != nil {
+				t.Fatalf("lookupAddresses(%s) error = %v", name, err)
+			}
+
+			if !reflect.DeepEqual(got, []ProcessedAsset{want}) {
+				t.Errorf("lookupAddresses(%s) = %+v, want %+v", name, got, want)
+			}
+		}
+	})
+
+	t.Run("by ip skips deleted addresses", func(t *testing.T) {
+		searcher := &fakeAddressSearcher{names: []string{
+			"//compute.googleapis.com/projects/p/regions/us-central1/addresses/deleted",
+			want.ResourceName,
+		}}
+
+		got, err := lookupAddresses(t.Context(), searcher, getter, "34.1.2.3", "")
+		if err != nil {
+			t.Fatalf("lookupAddresses() error = %v", err)
+		}
+
+		if searcher.ip != "34.1.2.3" || !reflect.DeepEqual(got, []ProcessedAsset{want}) {
+			t.Errorf("lookupAddresses() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		got, err := lookupAddresses(t.Context(), nil, getter, "", "projects/p/global/addresses/missing")
+		if err != nil || len(got) != 0 {
+			t.Errorf("lookupAddresses() = %+v, %v, want no assets", got, err)
+		}
+	})
+
+	t.Run("invalid ip", func(t *testing.T) {
+		if _, err := lookupAddresses(t.Context(), &fakeAddressSearcher{}, getter, "34.1.2", ""); err == nil {
+			t.Error("lookupAddresses() error = nil, want an error for an invalid IP address")
+		}
+	})
+}
@@ -1,25 +1,406 @@
-// Project: asset-watcher
-package main
+// Package assetwatcher collects the IP addresses of a Google Cloud organization from Cloud Asset
+// Inventory, enriches and checks them, and publishes the report. The asset-watcher command is
+// configured with environment variables and run by Main; Go programs embed the pipeline with New.
+package assetwatcher
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/api/option"
 )
 
+// exitCodeViolations is the exit code of a scan with policy violations when --fail-on-violation is set,
+// and of a check finding drift from the baseline.
+const exitCodeViolations = 2
+
+// Build information, set with -ldflags at build time.
 var (
 	Version   = "unknown"
 	BuildTime = "unknown"
 	Commit    = "unknown"
 )
 
-func main() {
-	cfg := GetConfig()
+// Main runs the asset-watcher command with the arguments and the environment of the process.
+func Main() {
+	startedAt := time.Now()
+
+	// The configuration file and the profile can be set before or after the subcommand, which
+	// does not know their flags, so they are removed from the arguments.
+	global, args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to parse arguments: %v\n", err)
+	}
+
+	// The env file can set any variable, including those of the configuration file and the
+	// profile, and is inherited by the processes of the profiles.
+	if err := loadEnvFile(); err != nil {
+		log.Fatalf("invalid value for ASSET_WATCHER_ENV_FILE: %v\n", err)
+	}
+
+	configFile := cmp.Or(global.configFile, os.Getenv(configFileEnv))
+
+	// The version and the usage are shown without any configuration.
+	if len(args) > 0 && isVersionArg(args[0]) {
+		if err := runVersionCommand(args[1:], os.Stdout); err != nil {
+			log.Fatalf("failed to show the version: %v\n", err)
+		}
+
+		return
+	}
+
+	if len(args) > 0 && isHelpArg(args[0]) {
+		if err := writeUsage(os.Stdout); err != nil {
+			log.Fatalf("failed to show the usage: %v\n", err)
+		}
+
+		return
+	}
+
+	if global.allProfiles {
+		os.Exit(runAllProfiles(context.Background(), configFile, args, os.Stdout, os.Stderr))
+	}
 
-	ctx := context.Background()
+	cfg, err := getConfig(configFile, cmp.Or(global.profile, os.Getenv(profileEnv)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Every log record and outbound request of the run carries its ID.
+	ctx, cancel := withCancellation(context.Background(), cfg)
+	defer cancel()
+
+	ctx = withRunProgress(withRunID(ctx, newRunID()))
+	ctx = withRunOutcome(ctx, cfg, startedAt)
+
+	defer finishRun(ctx)
+	defer recoverCrash(ctx, cfg)
+
+	if len(args) > 0 {
+		switch args[0] {
+		case terraformDataSourceCommand:
+			// Terraform reads the result from stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runTerraformDataSource(ctx, logger, cfg, os.Stdin, os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to answer the Terraform query", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case attestCommand:
+			// The attestation is written to stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runAttestCommand(ctx, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to export the attestation", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case diffCommand:
+			logger := newLogger(cfg, os.Stderr)
+			if err := runDiffCommand(ctx, logger, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to compare the snapshots", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case checkCommand:
+			// The drift is written to stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runCheckCommand(ctx, logger, cfg, args[1:], os.Stdout); err != nil {
+				if errors.Is(err, errInventoryDrift) {
+					logger.ErrorContext(ctx, "inventory drift found", slog.Any("error", err))
+					exit(ctx, exitCodeViolations)
+				}
+
+				logger.ErrorContext(ctx, "failed to check the inventory", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case trendCommand:
+			// The trend report is written to stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runTrendCommand(ctx, logger, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to report the trend", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case lookupCommand:
+			// The result is written to stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runLookupCommand(ctx, logger, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to look up the address", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case notifyCommand:
+			logger := setupLogging(cfg)
+			if err := runNotifyCommand(ctx, logger, cfg, args[1:], os.Stdin, os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to replay notifications", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case ackCommand:
+			// The export is written to stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runAckCommand(ctx, logger, cfg, args[1:], os.Stdin, os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to run the ack command", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case configCommand:
+			// The configuration is written to stdout, so logs go to stderr.
+			logger := newLogger(cfg, os.Stderr)
+			if err := runConfigCommand(ctx, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to validate the configuration", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case doctorCommand:
+			logger := setupLogging(cfg)
+			if err := runDoctorCommand(ctx, logger, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to run the preflight checks", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case selftestCommand:
+			logger := setupLogging(cfg)
+			if err := runSelftestCommand(ctx, logger, cfg, os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to run the self-test", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case advisoriesCommand:
+			logger := setupLogging(cfg)
+			if err := runAdvisoriesCommand(ctx, logger, cfg, args[1:], os.Stdout); err != nil {
+				logger.ErrorContext(ctx, "failed to send the advisories", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		case serveCommand:
+			logger := setupLogging(cfg)
+			if err := serve(ctx, logger, cfg); err != nil {
+				logger.ErrorContext(ctx, "failed to run the server", slog.Any("error", err))
+				exit(ctx, 1)
+			}
+
+			return
+		}
+	}
 
 	logger := setupLogging(cfg)
+	if cfg.OutputPath == "" && (cfg.OutputFormat == outputFormatNDJSON || cfg.OutputFormat == outputFormatXLSX) {
+		// The assets are streamed, or the workbook is written, to stdout, so logs go to stderr.
+		logger = newLogger(cfg, os.Stderr)
+	}
+
+	flags, err := parseScanFlags(cfg, args)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to parse arguments", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	if flags.asOf != "" {
+		report, err := reportAsOf(ctx, cfg, flags.asOf)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read the inventory from history", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		writeOutput(ctx, logger, cfg, func(w io.Writer) { outputReport(ctx, logger, w, report, cfg) })
+
+		return
+	}
+
+	if cfg.Interval != "" {
+		if err := runDaemon(ctx, logger, cfg); err != nil {
+			logger.ErrorContext(ctx, "failed to run the daemon", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		return
+	}
+
+	scanAndPublish(ctx, logger, cfg, startedAt)
+}
+
+// scanAndPublish runs a scan: it checks the notifiers, scans the organization, writes the
+// output, publishes the report to the sinks, and exits as the exit-code policy decides.
+func scanAndPublish(ctx context.Context, logger *slog.Logger, cfg *Config, startedAt time.Time) {
+	// Surface notifier misconfigurations before the scan rather than on the first delivery.
+	if !cfg.SkipNotifierChecks {
+		degraded, err := checkNotifiers(ctx, logger, newNotifiers(logger, cfg))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check notifiers", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		ctx = withDegradedNotifiers(ctx, degraded)
+	}
+
+	if cfg.OutputFormat == outputFormatNDJSON {
+		writeOutput(ctx, logger, cfg, func(w io.Writer) { streamScan(ctx, logger, cfg, w) })
+
+		if ctx.Err() != nil {
+			exit(ctx, exitCodeCanceled)
+		}
+
+		return
+	}
+
+	stores := newRunStores(ctx, logger, cfg)
+	defer closeRunStores(ctx, logger, stores)
+
+	report := runScan(ctx, logger, cfg, stores, startedAt)
+
+	setStage(ctx, stageOutput)
+	writeOutput(ctx, logger, cfg, func(w io.Writer) { outputReport(ctx, logger, w, report, cfg) })
+
+	setStage(ctx, stagePublish)
 
+	sinks := newSinks(ctx, logger, cfg, stores)
+	defer closeSinks(ctx, logger, sinks)
+
+	if !publishToSinks(ctx, logger, sinks, report) {
+		exit(ctx, 1)
+	}
+
+	if cfg.Advisories {
+		setStage(ctx, stageAdvisories)
+
+		if err := runAdvisories(ctx, logger, cfg, stores.state, false, io.Discard); err != nil {
+			logger.ErrorContext(ctx, "failed to send the advisories", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+	}
+
+	if decision, ok := evaluateExitPolicy(cfg, report); ok {
+		logger.ErrorContext(ctx, decision.reason,
+			slog.String("condition", decision.condition),
+			slog.Int("exit_code", decision.code),
+		)
+		recordExitDecision(ctx, decision)
+
+		// The sinks and stores are closed by the deferred calls only if exit unwinds, in an
+		// embedded run.
+		if !isEmbeddedRun(ctx) {
+			closeSinks(ctx, logger, sinks)
+			closeRunStores(ctx, logger, stores)
+		}
+
+		exit(ctx, decision.code)
+	}
+}
+
+// scanFlags are the command-line flags of a scan that are not part of the configuration.
+type scanFlags struct {
+	asOf string
+}
+
+// parseScanFlags applies the command-line flags of a scan on top of the configuration.
+func parseScanFlags(cfg *Config, args []string) (scanFlags, error) {
+	var sf scanFlags
+
+	flags := newScanFlagSet(cfg, &sf)
+	if err := flags.Parse(args); err != nil {
+		return sf, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if err := validateExitPolicy(cfg); err != nil {
+		return sf, fmt.Errorf("invalid exit-code policy: %w", err)
+	}
+
+	if _, err := parseDaemonSchedule(cfg); err != nil {
+		return sf, err
+	}
+
+	if sf.asOf != "" && cfg.Interval != "" {
+		return sf, fmt.Errorf("%w: --as-of cannot be combined with --interval", errInvalidSchedule)
+	}
+
+	return sf, nil
+}
+
+// newScanFlagSet returns the flags of the scan, which override the configuration.
+func newScanFlagSet(cfg *Config, sf *scanFlags) *flag.FlagSet {
+	flags := flag.NewFlagSet("asset-watcher", flag.ContinueOnError)
+	flags.BoolVar(&cfg.FailOnViolation, "fail-on-violation", cfg.FailOnViolation,
+		"exit with code 2 if the report has any policy violations")
+	flags.BoolVar(&cfg.FailOnChanges, "fail-on-changes", cfg.FailOnChanges,
+		"exit with code 5 if any asset was added, removed, or changed since the snapshot or the baseline")
+	flags.StringVar(&cfg.FailThresholds, "fail-thresholds", cfg.FailThresholds,
+		"exit with code 4 if a count exceeds its maximum, as a list of count=max pairs such as unused=5,changes=20")
+	flags.StringVar(&sf.asOf, "as-of", "",
+		"show the inventory as of a date (YYYY-MM-DD) or time (RFC 3339) from the history instead of scanning")
+	flags.StringVar(&cfg.Interval, "interval", cfg.Interval,
+		"run as a daemon scanning at the interval, such as 1h, until SIGINT or SIGTERM")
+	flags.StringVar(&cfg.IntervalJitter, "jitter", cfg.IntervalJitter,
+		"maximum random delay added to the interval of the daemon, such as 5m")
+	flags.StringVar(&cfg.RunTimeout, "run-timeout", cfg.RunTimeout,
+		"maximum duration of a scan of the daemon, the interval by default")
+
+	return flags
+}
+
+// reportAsOf returns the report of the latest stored run at the time of the --as-of query.
+func reportAsOf(ctx context.Context, cfg *Config, asOf string) (*Report, error) {
+	if cfg.HistoryDir == "" {
+		return nil, errNoHistory
+	}
+
+	t, err := parseAsOf(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFileRunStore(cfg.HistoryDir).AsOf(ctx, t)
+}
+
+// openAssets creates the asset fetcher and the iterator of the assets to scan, which is
+// the per-project iterator if ASSET_WATCHER_PER_PROJECT is enabled.
+func openAssets(ctx context.Context, logger *slog.Logger, cfg *Config) (
+	*GoogleAssetFetcher, AssetIterator, *ProjectAssetIterator,
+) {
+	fetcher, err := NewGoogleAssetFetcher(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsAssets)...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create an asset fetcher", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	if !cfg.PerProject {
+		return fetcher, fetcher.FetchAssets(ctx), nil
+	}
+
+	projectIterator, err := fetcher.FetchAssetsPerProject(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to list projects", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	return fetcher, projectIterator, projectIterator
+}
+
+// runScan fetches, processes, and enriches the assets and returns the report of the run.
+func runScan(ctx context.Context, logger *slog.Logger, cfg *Config, stores *runStores, startedAt time.Time) *Report {
 	logger.DebugContext(
 		ctx, "version information",
 		slog.String("version", Version),
@@ -27,28 +408,497 @@ func main() {
 		slog.String("commit", Commit),
 	)
 
-	fetcher, err := NewGoogleAssetFetcher(ctx, logger, cfg)
-	if err != nil {
-		logger.ErrorContext(ctx, "failed to create an asset fetcher", slog.Any("error", err))
-		os.Exit(1)
-	}
+	fetcher, assets, projectIterator := openAssets(ctx, logger, cfg)
 
 	defer func() {
 		if err := fetcher.Close(); err != nil {
 			logger.ErrorContext(ctx, "failed to close asset client", slog.Any("error", err))
-			os.Exit(1)
+			exit(ctx, 1)
 		}
 	}()
 
-	assets := fetcher.FetchAssets(ctx)
+	setStage(ctx, stageFetch)
+
 	processor := NewAssetProcessor(ctx, logger, cfg)
 
 	processedAssets, err := processor.ProcessAssets(ctx, assets)
+	if ctx.Err() != nil {
+		cancelScan(ctx, logger, cfg, startedAt, processedAssets)
+	}
+
 	if err != nil {
 		logger.ErrorContext(ctx, "failed to process assets", slog.Any("error", err))
 	}
 
 	logger.DebugContext(ctx, "Processed asset:", slog.Int("number_of_asset", len(processedAssets)))
 
-	outputToStdOut(ctx, logger, processedAssets, cfg.OutputFormat)
+	setStage(ctx, stageEnrich)
+
+	if cfg.DescribeFallback {
+		describer, err := NewGoogleAddressDescriber(ctx, logger, clientOptionsFor(ctx, logger, cfg, credentialsCompute)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create an address describer", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		limiter := rate.NewLimiter(rate.Limit(cfg.DescribeRate), 1)
+		processedAssets = describeMissingAttributes(ctx, logger, describer, limiter, processedAssets)
+	}
+
+	if cfg.ShowRecommendations {
+		recommendationFetcher, err := NewGoogleRecommendationFetcher(ctx, logger, cfg,
+			clientOptionsFor(ctx, logger, cfg, credentialsRecommender)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a recommendation fetcher", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		processedAssets = mergeRecommendations(ctx, logger, recommendationFetcher, processedAssets)
+	}
+
+	if cfg.ShowDisposition {
+		projectFetcher, err := NewGoogleProjectStatusFetcher(ctx, logger,
+			clientOptionsFor(ctx, logger, cfg, credentialsProjects)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a project status fetcher", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		processedAssets = annotateDispositions(ctx, logger, projectFetcher, processedAssets)
+	}
+
+	if cfg.ClassificationRules != "" {
+		// The rules are validated by GetConfig.
+		classifier, _ := LoadClassifier(cfg.ClassificationRules)
+		processedAssets = classifyAssets(classifier, processedAssets)
+	}
+
+	if cfg.FlowLogsTable != "" {
+		processedAssets = enrichFromFlowLogs(ctx, logger, cfg, processedAssets)
+	}
+
+	if cfg.GeoIPDatabase != "" {
+		db, err := OpenGeoIPDatabase(cfg.GeoIPDatabase)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to open the GeoIP database", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		processedAssets = annotateGeoLocation(ctx, logger, db, processedAssets)
+
+		if err := db.Close(); err != nil {
+			logger.WarnContext(ctx, "failed to close the GeoIP database", slog.Any("error", err))
+		}
+	}
+
+	if cfg.FirewallExposure {
+		rules, err := fetcher.FetchFirewallRules(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to fetch firewall rules", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		// The ports are validated by GetConfig.
+		sensitivePorts, _ := parseSensitivePorts(cfg.SensitivePorts)
+		processedAssets = annotateExposures(processedAssets, rules, sensitivePorts)
+	}
+
+	if checkers := newBlocklistCheckers(cfg); len(checkers) > 0 {
+		processedAssets = annotateBlocklists(ctx, logger, checkers, processedAssets)
+	}
+
+	if cfg.ApprovedRangesFile != "" {
+		// The file is validated by GetConfig.
+		ranges, _ := LoadApprovedRanges(cfg.ApprovedRangesFile)
+		// BYOIP ranges are allocations of the organization, so their addresses are always approved.
+		byoipRanges, _ := parseCIDRs(cfg.BYOIPRanges)
+		processedAssets = annotateCompliance(processedAssets, append(ranges, byoipRanges...))
+	}
+
+	var (
+		baselineDiffs   []AssetDiff
+		baselineSummary BaselineSummary
+	)
+
+	if cfg.BaselineFile != "" {
+		// The baseline is validated by GetConfig.
+		baseline, _ := LoadBaseline(cfg.BaselineFile)
+		processedAssets, baselineDiffs, baselineSummary = applyBaseline(processedAssets, baseline)
+	}
+
+	report := NewReport(ctx, cfg, startedAt, processedAssets)
+	if cfg.BaselineFile != "" {
+		report.Diffs = baselineDiffs
+		report.Summary.Baseline = &baselineSummary
+	}
+
+	if stores.state != nil {
+		acks, err := loadAcknowledgments(ctx, stores.state)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to load acknowledgments", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		applyAcknowledgments(report, acks, time.Now())
+	}
+
+	if projectIterator != nil {
+		report.UnscannableProjects = projectIterator.Errors()
+		coverage := projectIterator.Coverage()
+		report.Summary.Coverage = &coverage
+	}
+
+	if stores.snapshot != nil {
+		previous, err := stores.snapshot.Load(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to load the previous snapshot", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		if previous != nil {
+			report.Diffs = diffAssets(previous.Assets, report.Assets)
+		}
+
+		if cfg.ReleasedRetentionDays > 0 {
+			retention := time.Duration(cfg.ReleasedRetentionDays) * hoursPerDay * time.Hour
+			report.Released = trackReleased(previous, report.Assets, startedAt, retention)
+		}
+	}
+
+	if cfg.PrefixSource != "" {
+		// The prefix table is validated by GetConfig.
+		resolver, _ := newPrefixResolver(cfg)
+		report.Prefixes = groupByAnnouncedPrefix(ctx, logger, resolver, processedAssets)
+	}
+
+	if cfg.RDAPRanges != "" {
+		report.RDAP = checkRDAP(ctx, logger, NewRDAPClient(newHTTPClient(cfg)), cfg, processedAssets)
+	}
+
+	if cfg.TerraformState != "" {
+		reader := terraformStateReader{
+			client:      newHTTPClient(cfg),
+			storageOpts: clientOptionsFor(ctx, logger, cfg, credentialsStorage),
+		}
+
+		declared, err := loadTerraformAddresses(ctx, reader, cfg.TerraformState)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read the Terraform state", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		drift := compareTerraformState(declared, processedAssets)
+		report.Terraform = &drift
+	}
+
+	if cfg.DNSZones != "" || cfg.Route53Zones != "" || cfg.CloudflareZones != "" {
+		report.DNS = reconcileDNSRecords(ctx, logger, cfg, processedAssets)
+	}
+
+	recordReport(ctx, report)
+
+	return report
+}
+
+// clientOptionsFor returns the client options authenticating the component with its own
+// credentials, if configured.
+func clientOptionsFor(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg *Config,
+	component string,
+	scopes ...string,
+) []option.ClientOption {
+	if services := selftestServicesFromContext(ctx); services != nil {
+		return services.clientOptions
+	}
+
+	opts, err := clientOptions(ctx, cfg, component, scopes...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load credentials", slog.String("component", component), slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	return append(opts, option.WithUserAgent(userAgent(cfg)))
+}
+
+func newSinks(ctx context.Context, logger *slog.Logger, cfg *Config, stores *runStores) []Sink {
+	sinks := []Sink{}
+
+	if cfg.HistoryDir != "" {
+		sinks = append(sinks, runStoreSink{store: NewFileRunStore(cfg.HistoryDir)})
+	}
+
+	if cfg.SQLitePath != "" {
+		sinks = append(sinks, NewSQLiteSink(logger, cfg.SQLitePath))
+	}
+
+	if cfg.MetricsFile != "" {
+		sinks = append(sinks, metricsTextfileSink{path: cfg.MetricsFile, logger: logger})
+	}
+
+	if stores.snapshot != nil {
+		sinks = append(sinks, snapshotSink{store: stores.snapshot, logger: logger})
+	}
+
+	if auditLog := newAuditLog(ctx, logger, cfg); auditLog != nil {
+		sinks = append(sinks, auditLogSink{log: auditLog, retentionDays: cfg.AuditLogRetentionDays, logger: logger})
+	}
+
+	if cfg.SCCSource != "" {
+		sccSink, err := NewSCCSink(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsSCC)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a Security Command Center sink", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		sinks = append(sinks, sccSink)
+	}
+
+	if cfg.BigQueryTable != "" {
+		bigQuerySink, err := NewBigQuerySink(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsBigQuery)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a BigQuery sink", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		sinks = append(sinks, bigQuerySink)
+	}
+
+	if cfg.NetBoxURL != "" {
+		sinks = append(sinks, NewNetBoxSink(logger, cfg, newHTTPClient(cfg)))
+	}
+
+	if cfg.GitHubRepo != "" {
+		sinks = append(sinks, NewGitHubIssuesSink(logger, cfg, newHTTPClient(cfg)))
+	}
+
+	if cfg.ChronicleCustomerID != "" {
+		chronicleSink, err := NewChronicleSink(ctx, logger, cfg,
+			clientOptionsFor(ctx, logger, cfg, credentialsChronicle, chronicleScope)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a Chronicle sink", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		sinks = append(sinks, chronicleSink)
+	}
+
+	if cfg.Tag != "" {
+		tagAction, err := NewTagAction(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsTags)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a tag binding action", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		sinks = append(sinks, tagAction)
+	}
+
+	return append(sinks, newStatefulNotifierSinks(ctx, logger, cfg, stores.state)...)
+}
+
+// newStatefulNotifierSinks creates the notifier sinks, suppressing the findings already
+// notified within ASSET_WATCHER_NOTIFY_DEDUP_TTL, and accumulating the findings into a digest
+// every ASSET_WATCHER_NOTIFY_DIGEST_WINDOW. The findings of the digest are deduplicated when
+// it is sent.
+func newStatefulNotifierSinks(ctx context.Context, logger *slog.Logger, cfg *Config, state StateStore) []Sink {
+	notifierSinks := newNotifierSinks(ctx, logger, cfg, state)
+
+	// The durations are validated by GetConfig.
+	ttl, _ := parseAge(cfg.NotifyDedupTTL)
+	window, _ := parseAge(cfg.NotifyDigestWindow)
+
+	if len(notifierSinks) == 0 || (ttl == 0 && window == 0) {
+		return notifierSinks
+	}
+
+	if ttl > 0 {
+		notifierSinks = []Sink{newNotifyDedupSink(logger, state, ttl, notifierSinks)}
+	}
+
+	if window > 0 {
+		notifierSinks = []Sink{newNotifyDigestSink(logger, state, window, notifierSinks)}
+	}
+
+	return notifierSinks
+}
+
+// runStores are the state store and the snapshot store of a run, created once and shared by
+// the scan, the sinks, and the notifiers, then closed with the sinks.
+type runStores struct {
+	state    StateStore
+	snapshot SnapshotStore
+}
+
+// newRunStores creates the stores of the run; a store that is not configured is nil.
+func newRunStores(ctx context.Context, logger *slog.Logger, cfg *Config) *runStores {
+	state := newStateStore(ctx, logger, cfg)
+
+	return &runStores{state: state, snapshot: newSnapshotStore(ctx, logger, cfg, state)}
+}
+
+// closeRunStores closes the clients of the stores of the run.
+func closeRunStores(ctx context.Context, logger *slog.Logger, stores *runStores) {
+	closeStore(ctx, logger, stores.state)
+	closeStore(ctx, logger, stores.snapshot)
+}
+
+// closeStore closes the client of a store, if it holds one.
+func closeStore(ctx context.Context, logger *slog.Logger, store any) {
+	closer, ok := store.(io.Closer)
+	if !ok {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		logger.ErrorContext(ctx, "failed to close store", slog.Any("error", err))
+	}
+}
+
+// newSnapshotStore creates the store of ASSET_WATCHER_SNAPSHOT_PATH, a Cloud Storage object
+// for gs:// paths and a local file otherwise, or keeps the snapshot in the state store.
+// It returns nil if snapshots are not enabled.
+func newSnapshotStore(ctx context.Context, logger *slog.Logger, cfg *Config, state StateStore) SnapshotStore {
+	switch {
+	case cfg.SnapshotPath == "" && state != nil:
+		return stateSnapshotStore{state: state}
+	case cfg.SnapshotPath == "":
+		return nil
+	case !strings.HasPrefix(cfg.SnapshotPath, gcsScheme):
+		return NewFileSnapshotStore(cfg.SnapshotPath)
+	}
+
+	store, err := NewGCSSnapshotStore(ctx, cfg.SnapshotPath, clientOptionsFor(ctx, logger, cfg, credentialsStorage)...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create a Cloud Storage snapshot store", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	return store
+}
+
+// newAuditLog creates the audit log of ASSET_WATCHER_AUDIT_LOG, objects under a Cloud
+// Storage prefix for gs:// paths and a local directory otherwise. It returns nil if the
+// audit log is not enabled.
+func newAuditLog(ctx context.Context, logger *slog.Logger, cfg *Config) AuditLog {
+	switch {
+	case cfg.AuditLog == "":
+		return nil
+	case !strings.HasPrefix(cfg.AuditLog, gcsScheme):
+		return NewFileAuditLog(cfg.AuditLog)
+	}
+
+	auditLog, err := NewGCSAuditLog(ctx, cfg.AuditLog, clientOptionsFor(ctx, logger, cfg, credentialsStorage)...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create a Cloud Storage audit log", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	return auditLog
+}
+
+// newDeadLetterQueue creates the dead-letter queue of ASSET_WATCHER_NOTIFY_DEAD_LETTER, a
+// Pub/Sub topic for pubsub:// URLs and a local file otherwise.
+func newDeadLetterQueue(ctx context.Context, logger *slog.Logger, cfg *Config) DeadLetterQueue {
+	if !strings.HasPrefix(cfg.NotifyDeadLetter, pubSubScheme) {
+		return NewFileDeadLetterQueue(cfg.NotifyDeadLetter)
+	}
+
+	queue, err := NewPubSubDeadLetterQueue(ctx, cfg.NotifyDeadLetter,
+		clientOptionsFor(ctx, logger, cfg, credentialsPubSub)...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create a Pub/Sub dead-letter queue", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	return queue
+}
+
+// newStateStore creates the store of ASSET_WATCHER_STATE_STORE, a Firestore collection for
+// firestore:// URLs and a local directory otherwise. It returns nil if the state store is not
+// configured.
+func newStateStore(ctx context.Context, logger *slog.Logger, cfg *Config) StateStore {
+	if services := selftestServicesFromContext(ctx); services != nil {
+		return services.stateStore
+	}
+
+	if cfg.StateStore == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(cfg.StateStore, firestoreScheme) {
+		return NewFileStateStore(cfg.StateStore)
+	}
+
+	store, err := NewFirestoreStateStore(ctx, cfg.StateStore, clientOptionsFor(ctx, logger, cfg, credentialsFirestore)...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create a Firestore state store", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	return store
+}
+
+func reconcileDNSRecords(ctx context.Context, logger *slog.Logger, cfg *Config, assets []ProcessedAsset) *DNSReconciliation {
+	providers := []DNSProvider{}
+
+	if cfg.DNSZones != "" {
+		cloudDNS, err := NewCloudDNSProvider(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsDNS)...)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a Cloud DNS provider", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		providers = append(providers, cloudDNS)
+	}
+
+	if cfg.Route53Zones != "" {
+		route53, err := NewRoute53Provider(logger, cfg, newHTTPClient(cfg))
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to create a Route 53 provider", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+
+		providers = append(providers, route53)
+	}
+
+	if cfg.CloudflareZones != "" {
+		providers = append(providers, NewCloudflareProvider(logger, cfg, newHTTPClient(cfg)))
+	}
+
+	records, err := fetchDNSRecords(ctx, providers)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to fetch DNS records", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	reconciliation := reconcileDNS(assets, records)
+
+	return &reconciliation
+}
+
+func enrichFromFlowLogs(ctx context.Context, logger *slog.Logger, cfg *Config, assets []ProcessedAsset) []ProcessedAsset {
+	flowLogsClient, err := NewBigQueryFlowLogsClient(ctx, logger, cfg, clientOptionsFor(ctx, logger, cfg, credentialsFlowLogs)...)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create a VPC Flow Logs client", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	if cfg.PartnerCIDRs != "" {
+		assets, err = annotatePartnerPeers(ctx, logger, flowLogsClient, cfg, assets, time.Now())
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to annotate partner peers", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+	}
+
+	if cfg.ShowLastTraffic {
+		assets, err = annotateLastTraffic(ctx, logger, flowLogsClient, cfg, assets, time.Now())
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to annotate last traffic", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+	}
+
+	return assets
 }
@@ -0,0 +1,232 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format.
+// https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricLabelEscaper escapes label values of the text exposition format.
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// gauge is a metric family of the text exposition format with its samples.
+type gauge struct {
+	name    string
+	help    string
+	label   string
+	samples []gaugeSample
+}
+
+type gaugeSample struct {
+	labelValue string
+	value      float64
+}
+
+// reportGauges returns the gauges of the inventory of the report. Samples are sorted by
+// label value, so the exposition is stable between runs.
+func reportGauges(report *Report) []gauge {
+	byProject := map[string]int{}
+	byStatus := map[string]int{}
+	unused := 0
+
+	for _, asset := range report.Assets {
+		byProject[asset.Project]++
+		byStatus[asset.Status]++
+
+		if isIdleAddress(asset) {
+			unused++
+		}
+	}
+
+	gauges := []gauge{
+		{
+			name:    "asset_watcher_assets",
+			help:    "Number of assets in the inventory.",
+			samples: []gaugeSample{{value: float64(len(report.Assets))}},
+		},
+		{
+			name: "asset_watcher_project_assets", help: "Number of assets per project.", label: "project",
+			samples: countSamples(byProject),
+		},
+		{
+			name: "asset_watcher_status_assets", help: "Number of assets per status.", label: "status",
+			samples: countSamples(byStatus),
+		},
+		{
+			name:    "asset_watcher_unused_addresses",
+			help:    "Number of external addresses reserved without being used by any resource.",
+			samples: []gaugeSample{{value: float64(unused)}},
+		},
+		{
+			name:    "asset_watcher_violations",
+			help:    "Number of policy violations.",
+			samples: []gaugeSample{{value: float64(len(report.Violations))}},
+		},
+		{
+			name:    "asset_watcher_last_run_timestamp_seconds",
+			help:    "Start time of the run the metrics are collected by, in seconds since the epoch.",
+			samples: []gaugeSample{{value: float64(report.Metadata.StartedAt.Unix())}},
+		},
+	}
+
+	// Costs are only estimated with ASSET_WATCHER_SHOW_COST.
+	if cost := report.Summary.Cost; cost != nil {
+		projectCosts := make([]gaugeSample, 0, len(cost.Projects))
+		for _, project := range cost.Projects {
+			projectCosts = append(projectCosts, gaugeSample{labelValue: project.Project, value: project.MonthlyCost})
+		}
+
+		slices.SortFunc(projectCosts, func(a, b gaugeSample) int { return cmp.Compare(a.labelValue, b.labelValue) })
+
+		gauges = append(gauges,
+			gauge{
+				name:    "asset_watcher_estimated_monthly_cost_usd",
+				help:    "Estimated monthly cost of the unused addresses in USD.",
+				samples: []gaugeSample{{value: cost.MonthlyCost}},
+			},
+			gauge{
+				name:    "asset_watcher_project_estimated_monthly_cost_usd",
+				help:    "Estimated monthly cost of the unused addresses per project in USD.",
+				label:   "project",
+				samples: projectCosts,
+			},
+		)
+	}
+
+	return gauges
+}
+
+func countSamples(counts map[string]int) []gaugeSample {
+	samples := make([]gaugeSample, 0, len(counts))
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		samples = append(samples, gaugeSample{labelValue: key, value: float64(counts[key])})
+	}
+
+	return samples
+}
+
+// writeMetrics writes the gauges of the report in the Prometheus text exposition format.
+func writeMetrics(w io.Writer, report *Report) error {
+	bw := bufio.NewWriter(w)
+
+	for _, g := range reportGauges(report) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+
+		for _, sample := range g.samples {
+			value := strconv.FormatFloat(sample.value, 'f', -1, 64)
+
+			if g.label == "" {
+				fmt.Fprintf(bw, "%s %s\n", g.name, value)
+
+				continue
+			}
+
+			fmt.Fprintf(bw, "%s{%s=\"%s\"} %s\n", g.name, g.label, metricLabelEscaper.Replace(sample.labelValue), value)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// metricsTextfileSink writes the metrics of every run to a file for the textfile collector of
+// the Prometheus node exporter.
+type metricsTextfileSink struct {
+	path   string
+	logger *slog.Logger
+}
+
+// Name returns the name of the sink.
+func (s metricsTextfileSink) Name() string {
+	return "metrics"
+}
+
+// Publish replaces the file atomically, as the node exporter may read it at any time.
+func (s metricsTextfileSink) Publish(ctx context.Context, report *Report) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	// The node exporter only reads *.prom files, so the temporary file is ignored.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeMetrics(tmp, report); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	// The textfile collector runs as another user than the scans.
+	//nolint:gosec // Metrics are not sensitive.
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace metrics file: %w", err)
+	}
+
+	s.logger.DebugContext(ctx, "Wrote metrics", slog.String("path", s.path))
+
+	return nil
+}
+
+// Close is a no-op, as the file is closed after every run.
+func (s metricsTextfileSink) Close() error {
+	return nil
+}
+
+// metricsHandler serves the metrics of the latest run stored in the history directory, so
+// Prometheus can scrape the inventory of the scheduled runs.
+func metricsHandler(logger *slog.Logger, cfg *Config) http.HandlerFunc {
+	store := NewFileRunStore(cfg.HistoryDir)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, err := store.AsOf(r.Context(), time.Now())
+		if errors.Is(err, errRunNotFound) {
+			http.Error(w, "no run is stored yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to read the latest run", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", metricsContentType)
+
+		if err := writeMetrics(w, report); err != nil {
+			logger.ErrorContext(r.Context(), "failed to write response", slog.Any("error", err))
+		}
+	}
+}
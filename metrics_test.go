@@ -0,0 +1,125 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMetricsReport() *Report {
+	return &Report{
+		Metadata: RunMetadata{RunID: "run-1", StartedAt: time.Unix(1741600800, 0)},
+		Assets: []ProcessedAsset{
+			{Name: "a1", Project: "prod", Status: addressStatusReserved, AddressType: "EXTERNAL"},
+			{Name: "a2", Project: "prod", Status: addressStatusInUse, AddressType: "EXTERNAL"},
+			{Name: "a3", Project: `dev "sandbox"`, Status: addressStatusReserved, AddressType: addressTypeInternal},
+		},
+		Summary: Summary{Cost: &CostSummary{
+			Projects:    []ProjectCost{{Project: "prod", IdleAddresses: 1, MonthlyCost: 7.3}},
+			MonthlyCost: 7.3,
+		}},
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var b strings.Builder
+	if err := writeMetrics(&b, newMetricsReport()); err != nil {
+		t.Fatalf("writeMetrics failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# TYPE asset_watcher_assets gauge\nasset_watcher_assets 3\n",
+		`asset_watcher_project_assets{project="dev \"sandbox\""} 1` + "\n" + `asset_watcher_project_assets{project="prod"} 2`,
+		`asset_watcher_status_assets{status="IN_USE"} 1` + "\n" + `asset_watcher_status_assets{status="RESERVED"} 2`,
+		"asset_watcher_unused_addresses 1\n",
+		"asset_watcher_violations 0\n",
+		"asset_watcher_last_run_timestamp_seconds 1741600800\n",
+		"asset_watcher_estimated_monthly_cost_usd 7.3\n",
+		`asset_watcher_project_estimated_monthly_cost_usd{project="prod"} 7.3`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %q in the metrics:\n%s", want, b.String())
+		}
+	}
+
+	// Without cost estimation, no cost is reported rather than a zero cost.
+	report := newMetricsReport()
+	report.Summary.Cost = nil
+
+	b.Reset()
+
+	if err := writeMetrics(&b, report); err != nil {
+		t.Fatalf("writeMetrics failed: %v", err)
+	}
+
+	if strings.Contains(b.String(), "cost") {
+		t.Errorf("expected no cost metrics:\n%s", b.String())
+	}
+}
+
+func TestMetricsTextfileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "textfile", "asset_watcher.prom")
+	sink := metricsTextfileSink{path: path, logger: slog.New(slog.DiscardHandler)}
+
+	if err := sink.Publish(t.Context(), newMetricsReport()); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the metrics file: %v", err)
+	}
+
+	if !strings.Contains(string(data), "asset_watcher_assets 3\n") {
+		t.Errorf("unexpected metrics file:\n%s", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the metrics file, got %v", entries)
+	}
+}
+
+func TestServeMux_Metrics(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.HistoryDir = t.TempDir()
+
+	mux := newServeMux(slog.New(slog.DiscardHandler), &cfg, nil, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 without stored runs, got %d", rec.Code)
+	}
+
+	if err := NewFileRunStore(cfg.HistoryDir).Save(t.Context(), newMetricsReport()); err != nil {
+		t.Fatalf("failed to store the run: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != metricsContentType {
+		t.Fatalf("expected status 200 with metrics, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	if !strings.Contains(rec.Body.String(), "asset_watcher_assets 3\n") {
+		t.Errorf("unexpected metrics:\n%s", rec.Body.String())
+	}
+
+	// Without a history directory, there is nothing to expose.
+	cfg.HistoryDir = ""
+	rec = httptest.NewRecorder()
+	newServeMux(slog.New(slog.DiscardHandler), &cfg, nil, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without a history directory, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// outputFormatNDJSON streams the processed assets as JSON Lines, one asset per line.
+const outputFormatNDJSON = "ndjson"
+
+// writeNDJSON writes every processed asset as a line of JSON as soon as it is processed,
+// so that the inventory is never held in memory.
+func writeNDJSON(ctx context.Context, processor *AssetProcessor, assets AssetIterator, w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	// The time format is validated by GetConfig.
+	times, err := newTimeFormatter(processor.cfg.TimeFormat, processor.cfg.TimeZone)
+	if err != nil {
+		return err
+	}
+
+	return processor.StreamAssets(ctx, assets, func(asset ProcessedAsset) error {
+		if err := enc.Encode(times.asset(asset)); err != nil {
+			return fmt.Errorf("failed to write asset: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// writeReportNDJSON writes the assets of a report as JSON Lines.
+func writeReportNDJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+
+	for _, asset := range report.Assets {
+		if err := enc.Encode(asset); err != nil {
+			return fmt.Errorf("failed to write asset: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func outputNDJSON(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report) {
+	if err := writeReportNDJSON(w, report); err != nil {
+		logger.ErrorContext(ctx, "failed to write JSON Lines", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+// streamScan fetches and processes the assets and streams them to the writer as JSON Lines.
+// The enrichments, the report, and the sinks need the whole inventory and are skipped.
+func streamScan(ctx context.Context, logger *slog.Logger, cfg *Config, w io.Writer) {
+	fetcher, assets, _ := openAssets(ctx, logger, cfg)
+
+	defer func() {
+		if err := fetcher.Close(); err != nil {
+			logger.ErrorContext(ctx, "failed to close asset client", slog.Any("error", err))
+			exit(ctx, 1)
+		}
+	}()
+
+	setStage(ctx, stageFetch)
+
+	if err := writeNDJSON(ctx, NewAssetProcessor(ctx, logger, cfg), assets, w); err != nil {
+		// The assets streamed before the cancellation are flushed as the output is closed.
+		if ctx.Err() != nil {
+			logger.WarnContext(ctx, "The streaming was canceled", slog.Any("cause", context.Cause(ctx)))
+
+			return
+		}
+
+		logger.ErrorContext(ctx, "failed to stream assets", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
@@ -0,0 +1,101 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+)
+
+// errWriter fails every write after the first n bytes.
+type errWriter struct {
+	n int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		return 0, errWriteFailed
+	}
+
+	w.n -= len(p)
+
+	return len(p), nil
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+	baseTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	assets := []*assetpb.ResourceSearchResult{
+		createTestAsset("nat-1", "prod-network", "IN_USE", "1.1.1.1", baseTime),
+		createTestAsset("lb-1", "prod-web", "IN_USE", "2.2.2.2", baseTime),
+		createTestAsset("nat-2", "sandbox-alice", "RESERVED", "3.3.3.3", baseTime),
+	}
+
+	processor := NewAssetProcessor(ctx, logger, &Config{ExcludeProjectRegex: "^sandbox-"})
+
+	var buf bytes.Buffer
+	if err := writeNDJSON(ctx, processor, &mockAssetIterator{assets: assets}, &buf); err != nil {
+		t.Fatalf("writeNDJSON() error = %v", err)
+	}
+
+	names := []string{}
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var asset ProcessedAsset
+		if err := json.Unmarshal(scanner.Bytes(), &asset); err != nil {
+			t.Fatalf("line %q is not a JSON object: %v", scanner.Text(), err)
+		}
+
+		names = append(names, asset.Name)
+	}
+
+	if want := []string{"nat-1", "lb-1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("writeNDJSON() wrote %v, want %v", names, want)
+	}
+}
+
+func TestWriteNDJSON_WriteError(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+	baseTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	iter := &mockAssetIterator{assets: []*assetpb.ResourceSearchResult{
+		createTestAsset("nat-1", "prod-network", "IN_USE", "1.1.1.1", baseTime),
+		createTestAsset("nat-2", "prod-network", "IN_USE", "2.2.2.2", baseTime),
+	}}
+
+	err := writeNDJSON(ctx, NewAssetProcessor(ctx, logger, &Config{}), iter, &errWriter{})
+	if !errors.Is(err, errWriteFailed) {
+		t.Fatalf("writeNDJSON() error = %v, want %v", err, errWriteFailed)
+	}
+
+	// The stream stops at the first failed write, without reading the remaining assets.
+	if iter.index != 1 {
+		t.Errorf("writeNDJSON() read %d assets, want 1", iter.index)
+	}
+}
+
+func TestWriteReportNDJSON(t *testing.T) {
+	report := &Report{Assets: []ProcessedAsset{{Name: "a1"}, {Name: "a2"}}}
+
+	var buf bytes.Buffer
+	if err := writeReportNDJSON(&buf, report); err != nil {
+		t.Fatalf("writeReportNDJSON() error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != len(report.Assets) {
+		t.Errorf("writeReportNDJSON() wrote %d lines, want %d", len(lines), len(report.Assets))
+	}
+}
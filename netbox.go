@@ -0,0 +1,295 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultNetBoxTag = "asset-watcher"
+	netBoxPageSize   = 1000
+
+	// netBoxMaxDescription is the maximum length of the description of NetBox objects.
+	netBoxMaxDescription = 200
+
+	// Statuses of NetBox IP addresses.
+	netBoxStatusActive     = "active"
+	netBoxStatusReserved   = "reserved"
+	netBoxStatusDeprecated = "deprecated"
+)
+
+var errNetBoxRequestFailed = errors.New("request to NetBox failed")
+
+// netBoxIPAddress is an IP address object as written to the NetBox REST API.
+// https://demo.netbox.dev/api/docs/#/ipam/ipam_ip_addresses_create
+type netBoxIPAddress struct {
+	ID          int         `json:"id,omitempty"`
+	Address     string      `json:"address,omitempty"`
+	Status      string      `json:"status"`
+	Description string      `json:"description"`
+	Tags        []netBoxTag `json:"tags,omitempty"`
+}
+
+type netBoxTag struct {
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug"`
+}
+
+// netBoxIPAddressRecord is an IP address object as read from the NetBox REST API, where the
+// status is a value and label pair.
+type netBoxIPAddressRecord struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"`
+	Status  struct {
+		Value string `json:"value"`
+	} `json:"status"`
+	Description string `json:"description"`
+}
+
+// netBoxPage is a page of a list response.
+type netBoxPage[T any] struct {
+	Next    string `json:"next"`
+	Results []T    `json:"results"`
+}
+
+// NetBoxSink keeps the IP addresses of a NetBox IPAM in sync with the inventory. It manages the
+// addresses with its tag only: every address of the inventory is created or updated, and the
+// managed addresses that are no longer found are deprecated rather than deleted.
+type NetBoxSink struct {
+	client   *http.Client
+	endpoint string
+	token    string
+	tag      string
+	logger   *slog.Logger
+}
+
+// NewNetBoxSink creates a new NetBox sink for the configured instance, authenticated with an
+// API token with write permissions on IP addresses and tags.
+func NewNetBoxSink(logger *slog.Logger, cfg *Config, client *http.Client) *NetBoxSink {
+	return &NetBoxSink{
+		client:   client,
+		endpoint: strings.TrimSuffix(cfg.NetBoxURL, "/") + "/api",
+		token:    cfg.NetBoxToken,
+		tag:      cfg.NetBoxTag,
+		logger:   logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the sink.
+func (s *NetBoxSink) Name() string {
+	return "netbox"
+}
+
+// Publish synchronizes the addresses of the report with NetBox.
+func (s *NetBoxSink) Publish(ctx context.Context, report *Report) error {
+	if err := s.ensureTag(ctx); err != nil {
+		return err
+	}
+
+	existing, err := s.listIPAddresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	creates, updates := netBoxChanges(report.Assets, existing, s.tag)
+
+	if len(creates) > 0 {
+		if err := s.do(ctx, http.MethodPost, "/ipam/ip-addresses/", creates, nil); err != nil {
+			return fmt.Errorf("failed to create NetBox IP addresses: %w", err)
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.do(ctx, http.MethodPatch, "/ipam/ip-addresses/", updates, nil); err != nil {
+			return fmt.Errorf("failed to update NetBox IP addresses: %w", err)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Synchronized NetBox IP addresses",
+		slog.Int("created", len(creates)),
+		slog.Int("updated", len(updates)),
+	)
+
+	return nil
+}
+
+// Close is a no-op, as the HTTP client does not need to be closed.
+func (s *NetBoxSink) Close() error {
+	return nil
+}
+
+// netBoxChanges returns the addresses to create and the changes of the existing managed
+// addresses: the updated ones, and the ones no longer found, which are deprecated. An address
+// used by several assets, such as an address and its forwarding rule, is synced once with the
+// first of them.
+func netBoxChanges(
+	assets []ProcessedAsset, existing []netBoxIPAddressRecord, tag string,
+) ([]netBoxIPAddress, []netBoxIPAddress) {
+	byAddress := make(map[netip.Addr]netBoxIPAddressRecord, len(existing))
+
+	for _, record := range existing {
+		if prefix, err := netip.ParsePrefix(record.Address); err == nil {
+			byAddress[prefix.Addr()] = record
+		}
+	}
+
+	creates := []netBoxIPAddress{}
+	updates := []netBoxIPAddress{}
+	seen := map[netip.Addr]bool{}
+
+	for _, asset := range assets {
+		addr, err := netip.ParseAddr(asset.IPAddress)
+		if err != nil || seen[addr] {
+			continue
+		}
+
+		seen[addr] = true
+		want := netBoxIPAddress{Status: netBoxStatus(asset), Description: netBoxDescription(asset)}
+
+		record, ok := byAddress[addr]
+		if !ok {
+			want.Address = netip.PrefixFrom(addr, addr.BitLen()).String()
+			want.Tags = []netBoxTag{{Slug: tag}}
+			creates = append(creates, want)
+
+			continue
+		}
+
+		if record.Status.Value != want.Status || record.Description != want.Description {
+			want.ID = record.ID
+			updates = append(updates, want)
+		}
+	}
+
+	for addr, record := range byAddress {
+		if seen[addr] || record.Status.Value == netBoxStatusDeprecated {
+			continue
+		}
+
+		updates = append(updates, netBoxIPAddress{
+			ID: record.ID, Status: netBoxStatusDeprecated, Description: record.Description,
+		})
+	}
+
+	return creates, updates
+}
+
+// netBoxStatus maps the status of the asset to the status of its NetBox address.
+func netBoxStatus(asset ProcessedAsset) string {
+	if asset.Status == addressStatusReserved {
+		return netBoxStatusReserved
+	}
+
+	return netBoxStatusActive
+}
+
+func netBoxDescription(asset ProcessedAsset) string {
+	return truncateString(asset.Name+" ("+asset.Project+", "+asset.Location+")", netBoxMaxDescription)
+}
+
+// ensureTag creates the tag of the managed addresses, if it does not exist.
+func (s *NetBoxSink) ensureTag(ctx context.Context) error {
+	var page netBoxPage[netBoxTag]
+	if err := s.do(ctx, http.MethodGet, "/extras/tags/?"+url.Values{"slug": {s.tag}}.Encode(), nil, &page); err != nil {
+		return fmt.Errorf("failed to get NetBox tag %s: %w", s.tag, err)
+	}
+
+	if len(page.Results) > 0 {
+		return nil
+	}
+
+	if err := s.do(ctx, http.MethodPost, "/extras/tags/", netBoxTag{Name: s.tag, Slug: s.tag}, nil); err != nil {
+		return fmt.Errorf("failed to create NetBox tag %s: %w", s.tag, err)
+	}
+
+	return nil
+}
+
+// listIPAddresses lists the addresses managed by asset-watcher.
+func (s *NetBoxSink) listIPAddresses(ctx context.Context) ([]netBoxIPAddressRecord, error) {
+	records := []netBoxIPAddressRecord{}
+	query := url.Values{"tag": {s.tag}, "limit": {strconv.Itoa(netBoxPageSize)}}
+	path := "/ipam/ip-addresses/?" + query.Encode()
+
+	for path != "" {
+		var page netBoxPage[netBoxIPAddressRecord]
+		if err := s.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list NetBox IP addresses: %w", err)
+		}
+
+		records = append(records, page.Results...)
+
+		// The next page is an absolute URL, which may differ from the configured one behind
+		// a proxy, so only its query is kept.
+		path = ""
+
+		if next, err := url.Parse(page.Next); err == nil && page.Next != "" {
+			path = "/ipam/ip-addresses/?" + next.RawQuery
+		}
+	}
+
+	return records, nil
+}
+
+// do sends a request with the JSON body to the path of the API, and decodes the response
+// into out, unless it is nil.
+func (s *NetBoxSink) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Token "+s.token)
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s: %s", errNetBoxRequestFailed, resp.Status,
+			bytes.TrimSpace(respBody[:min(len(respBody), maxErrorBodyBytes)]))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse NetBox response: %w", err)
+	}
+
+	return nil
+}
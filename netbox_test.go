@@ -0,0 +1,121 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNetBoxChanges(t *testing.T) {
+	assets := []ProcessedAsset{
+		{Name: "web", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.10", Status: addressStatusInUse},
+		{Name: "web-rule", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.10", Status: addressStatusInUse},
+		{Name: "spare", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.11", Status: addressStatusReserved},
+		{Name: "v6", Project: "dev", Location: "global", IPAddress: "2001:db8::1", Status: addressStatusInUse},
+		{Name: "unknown", Project: "dev", Location: "global", IPAddress: "N/A"},
+	}
+
+	existing := []netBoxIPAddressRecord{
+		{ID: 1, Address: "203.0.113.10/32", Description: "web (prod, us-central1)"},
+		{ID: 2, Address: "203.0.113.11/32", Description: "spare (prod, us-central1)"},
+		{ID: 3, Address: "198.51.100.20/32", Description: "gone (dev, us-east1)"},
+		{ID: 4, Address: "198.51.100.21/32", Description: "old (dev, us-east1)"},
+	}
+	existing[0].Status.Value = netBoxStatusActive
+	existing[1].Status.Value = netBoxStatusActive
+	existing[2].Status.Value = netBoxStatusActive
+	existing[3].Status.Value = netBoxStatusDeprecated
+
+	creates, updates := netBoxChanges(assets, existing, "asset-watcher")
+
+	if len(creates) != 1 || creates[0].Address != "2001:db8::1/128" || creates[0].Status != netBoxStatusActive ||
+		len(creates[0].Tags) != 1 || creates[0].Tags[0].Slug != "asset-watcher" {
+		t.Errorf("unexpected creates: %+v", creates)
+	}
+
+	got := map[int]string{}
+	for _, u := range updates {
+		got[u.ID] = u.Status
+	}
+
+	if len(got) != 2 || got[2] != netBoxStatusReserved || got[3] != netBoxStatusDeprecated {
+		t.Errorf("unexpected updates: %+v", updates)
+	}
+}
+
+func TestNetBoxSink_Publish(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		created  []netBoxIPAddress
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Token secret" {
+			http.Error(w, `{"detail":"Invalid token"}`, http.StatusForbidden)
+
+			return
+		}
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/extras/tags/":
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/ipam/ip-addresses/" && r.URL.Query().Get("offset") == "":
+			_, _ = w.Write([]byte(`{"next":"https://netbox.internal/api/ipam/ip-addresses/?offset=1&tag=asset-watcher",` +
+				`"results":[{"id":7,"address":"198.51.100.20/32","status":{"value":"active"},"description":"gone"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/ipam/ip-addresses/":
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/ipam/ip-addresses/":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	cfg := ConfigDefaults
+	cfg.NetBoxURL = server.URL + "/"
+	cfg.NetBoxToken = "secret"
+	sink := NewNetBoxSink(slog.New(slog.DiscardHandler), &cfg, server.Client())
+
+	report := &Report{Assets: []ProcessedAsset{
+		{Name: "web", Project: "prod", Location: "us-central1", IPAddress: "203.0.113.10", Status: addressStatusInUse},
+	}}
+
+	if err := sink.Publish(t.Context(), report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	want := []string{
+		"GET /api/extras/tags/",
+		"POST /api/extras/tags/",
+		"GET /api/ipam/ip-addresses/",
+		"GET /api/ipam/ip-addresses/",
+		"POST /api/ipam/ip-addresses/",
+		"PATCH /api/ipam/ip-addresses/",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+
+	if len(created) != 1 || created[0].Address != "203.0.113.10/32" {
+		t.Errorf("unexpected created addresses: %+v", created)
+	}
+
+	cfg.NetBoxToken = "wrong"
+	if err := NewNetBoxSink(slog.New(slog.DiscardHandler), &cfg, server.Client()).Publish(t.Context(), report); err == nil ||
+		!strings.Contains(err.Error(), "Invalid token") {
+		t.Errorf("expected the error of NetBox, got %v", err)
+	}
+}
@@ -0,0 +1,435 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	notifierTimeout = 30 * time.Second
+
+	// maxErrorBodyBytes is the maximum number of bytes of an error response kept in errors.
+	maxErrorBodyBytes = 1024
+)
+
+// Modes of the notifiers: by default, reports with policy violations or changes are notified;
+// in the changes mode, only the changes are, so identical inventories are not notified every run.
+const (
+	notifyModeFindings = "findings"
+	notifyModeChanges  = "changes"
+)
+
+var errNotificationFailed = errors.New("notification failed")
+
+// Notification is a message about a report, rendered by each notifier in its own format.
+type Notification struct {
+	RunID       string
+	Title       string
+	Summary     string
+	Items       []string
+	ArtifactURL string
+
+	// MaxItems is the maximum number of items of a message, as configured by
+	// ASSET_WATCHER_NOTIFY_MAX_ITEMS, or 0 for the limits of each notifier. Further items are
+	// counted in a footer linking the full report.
+	MaxItems int
+
+	// Body is the body rendered by ASSET_WATCHER_NOTIFY_BODY_TEMPLATE, which replaces the
+	// summary and the items in the messages of the notifiers, or empty for the default layout.
+	Body string
+
+	// Report is the report the notification is about, for notifiers rendering more than the
+	// items, such as the email digest. Notifications of routed categories or of changes only
+	// carry the matching part of the report.
+	Report *Report
+}
+
+// itemsLimit returns the limit of items of a message of the notifier, lowered to the
+// maximum of the notification.
+func (n Notification) itemsLimit(limit int) int {
+	if n.MaxItems > 0 {
+		return min(limit, n.MaxItems)
+	}
+
+	return limit
+}
+
+// Notifier is an interface for sending notifications to chat and incident systems.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// newNotification creates a notification listing the policy violations and changes of the report.
+func newNotification(report *Report, artifactURL string) Notification {
+	return Notification{
+		RunID: report.Metadata.RunID,
+		Title: fmt.Sprintf("asset-watcher: %d violations and %d changes in organization %s",
+			len(report.Violations), len(report.Diffs), report.Metadata.OrgID),
+		Summary:     fmt.Sprintf("%d assets scanned in run %s", report.Summary.TotalAssets, report.Metadata.RunID),
+		Items:       reportItems(report),
+		ArtifactURL: artifactURL,
+		Report:      report,
+	}
+}
+
+// reportItems returns the items of the policy violations and the changes of the report.
+func reportItems(report *Report) []string {
+	items := make([]string, 0, len(report.Violations)+len(report.Diffs))
+
+	for _, v := range report.Violations {
+		items = append(items, violationItem(v))
+	}
+
+	for _, d := range report.Diffs {
+		items = append(items, diffItem(d))
+	}
+
+	return items
+}
+
+// violationItem returns the item of a policy violation.
+func violationItem(v RuleViolation) string {
+	return fmt.Sprintf("[%s] %s (%s)", v.Severity, v.Message, v.Asset.Project)
+}
+
+// diffItem returns the item of a change.
+func diffItem(d AssetDiff) string {
+	return fmt.Sprintf("%s: %s %s (%s)", d.Type, d.Asset.Name, d.Asset.IPAddress, d.Asset.Project)
+}
+
+// notifierSink publishes reports with policy violations or changes through a notifier.
+// If categories are routed to the notifier, only the violations and changes of the assets
+// of these categories are sent. With a routing table, only the violations and changes routed to
+// the target are sent. With changesOnly, only the changes are sent. The requests of a notification
+// failing transiently are retried with an exponential backoff, and a notification still
+// undelivered is written to the dead-letter queue, if any.
+type notifierSink struct {
+	notifier    Notifier
+	artifactURL string
+	maxItems    int
+	categories  []string
+	routes      *NotifyRoutes
+	target      string
+	changesOnly bool
+	retries     int
+	backoff     time.Duration
+	deadLetter  DeadLetterQueue
+	templates   *notificationTemplates
+	logger      *slog.Logger
+}
+
+// Name returns the name of the notifier, or the target of the routes.
+func (s notifierSink) Name() string {
+	if s.target != "" {
+		return s.target
+	}
+
+	return s.notifier.Name()
+}
+
+// Publish notifies about the report, unless it has neither violations nor changes.
+func (s notifierSink) Publish(ctx context.Context, report *Report) error {
+	if len(s.categories) > 0 {
+		report = reportForCategories(report, s.categories)
+	}
+
+	if s.routes != nil {
+		report = s.routes.reportForTarget(report, s.target)
+	}
+
+	if s.changesOnly {
+		changes := *report
+		changes.Violations = nil
+		report = &changes
+	}
+
+	if len(report.Violations) == 0 && len(report.Diffs) == 0 {
+		return nil
+	}
+
+	notification := newNotification(report, s.artifactURL)
+	notification.MaxItems = s.maxItems
+
+	if digest := notifyDigestFromContext(ctx); digest != nil {
+		notification.Summary = digest.summary(report)
+	}
+
+	if s.templates != nil {
+		if err := s.templates.apply(&notification); err != nil {
+			return err
+		}
+	}
+
+	// A notifier found unable to deliver at startup logs the notification instead, so the
+	// findings are not lost while the notifier is being fixed.
+	if err := degradedNotifier(ctx, s.notifier.Name()); err != nil {
+		s.logger.WarnContext(ctx, "Notifier is degraded, logging the notification instead of sending it",
+			slog.String("notifier", s.notifier.Name()),
+			slog.Any("error", err),
+			slog.String("title", notification.Title),
+			slog.String("summary", notification.Summary),
+			slog.Any("items", notification.Items),
+		)
+
+		return nil
+	}
+
+	return s.notify(ctx, notification)
+}
+
+// notify sends the notification, retrying the requests failing transiently, and writes the
+// notification to the dead-letter queue if it is still undelivered.
+func (s notifierSink) notify(ctx context.Context, notification Notification) error {
+	retry := &notifyRetry{notifier: s.Name(), retries: s.retries, backoff: s.backoff, logger: s.logger}
+	err := s.notifier.Notify(withNotifyRetry(ctx, retry), notification)
+	attempts := max(retry.attempts, 1)
+
+	if err == nil || s.deadLetter == nil {
+		return err
+	}
+
+	if dlqErr := s.deadLetter.Put(ctx, newDeadLetter(s.Name(), notification, attempts, err)); dlqErr != nil {
+		return errors.Join(err, dlqErr)
+	}
+
+	s.logger.WarnContext(ctx, "Wrote the undelivered notification to the dead-letter queue",
+		slog.String("notifier", s.Name()),
+		slog.Int("attempts", attempts),
+	)
+
+	return err
+}
+
+// Close is a no-op, as notifiers do not hold any resources.
+func (s notifierSink) Close() error {
+	return nil
+}
+
+// limitItems returns the leading items that fit within both the number of items and
+// the total length in bytes, with the length of each item increased by overhead, and
+// the number of omitted items.
+func limitItems(items []string, maxItems, maxLength, overhead int) ([]string, int) {
+	length := 0
+
+	for i, item := range items {
+		length += len(item) + overhead
+		if i >= maxItems || length > maxLength {
+			return items[:i], len(items) - i
+		}
+	}
+
+	return items, 0
+}
+
+// moreItemsFooter returns the footer of a truncated notification.
+func moreItemsFooter(omitted int, artifactURL string) string {
+	if omitted == 0 {
+		return ""
+	}
+
+	footer := fmt.Sprintf("%d more items", omitted)
+	if artifactURL != "" {
+		footer += ", see the full report: " + artifactURL
+	}
+
+	return footer
+}
+
+// postJSON sends the payload to the URL, returning the response body of a successful request.
+// The URL is not included in errors, as webhook URLs contain secrets.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header, payload any) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var respBody []byte
+
+	err = notifyRetryFromContext(ctx).do(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		respBody, _, err = doRequest(client, req, header)
+
+		return err
+	})
+
+	return respBody, err
+}
+
+// doRequest sends the request with the header, returning the response body and header of a
+// successful request. Network errors, server errors, and rate limits are transient errors.
+func doRequest(client *http.Client, req *http.Request, header http.Header) ([]byte, http.Header, error) {
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		err = fmt.Errorf("failed to send request: %w", err)
+		if req.Context().Err() == nil {
+			err = &transientError{err: err}
+		}
+
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("%w: %s: %s", errNotificationFailed, resp.Status,
+			bytes.TrimSpace(respBody[:min(len(respBody), maxErrorBodyBytes)]))
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			err = &transientError{err: err, retryAfter: retryAfter(resp.Header, time.Now())}
+		}
+
+		return nil, nil, err
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// newNotifiers creates the configured notifiers.
+func newNotifiers(logger *slog.Logger, cfg *Config) []Notifier {
+	client := newHTTPClient(cfg)
+	notifiers := []Notifier{}
+
+	for _, name := range routableNotifiers {
+		if notifierConfigured(cfg, name) {
+			notifiers = append(notifiers, newNotifier(logger, cfg, client, name))
+		}
+	}
+
+	return notifiers
+}
+
+// newNotifier creates the notifier of the name, or of the target of a notification route,
+// where slack:CHANNEL posts to the channel instead of ASSET_WATCHER_SLACK_CHANNEL.
+func newNotifier(logger *slog.Logger, cfg *Config, client *http.Client, target string) Notifier {
+	name, channel, ok := strings.Cut(target, ":")
+
+	switch name {
+	case "slack":
+		if ok {
+			targetCfg := *cfg
+			targetCfg.SlackChannel = channel
+			cfg = &targetCfg
+		}
+
+		return NewSlackNotifier(logger, cfg, client)
+	case "teams":
+		return NewTeamsNotifier(logger, cfg, client)
+	case "webhook":
+		return NewWebhookNotifier(logger, cfg, client)
+	case "pagerduty":
+		return NewPagerDutyNotifier(logger, cfg, client)
+	default:
+		return NewEmailNotifier(logger, cfg)
+	}
+}
+
+// notifierConfigured reports whether the notifier of the name or route target is configured.
+func notifierConfigured(cfg *Config, target string) bool {
+	name, _, _ := strings.Cut(target, ":")
+
+	switch name {
+	case "slack":
+		return cfg.SlackToken != ""
+	case "teams":
+		return cfg.TeamsWebhookURL != ""
+	case "webhook":
+		return cfg.WebhookURL != ""
+	case "email":
+		return cfg.SMTPHost != ""
+	case "pagerduty":
+		return cfg.PagerDutyRoutingKey != ""
+	default:
+		return false
+	}
+}
+
+// newNotifierSinks creates sinks for the configured notifiers or, with a routing table, for
+// the targets of its routes. With Slack threads, the threads are kept in the state store.
+func newNotifierSinks(ctx context.Context, logger *slog.Logger, cfg *Config, state StateStore) []Sink {
+	sinks := []Sink{}
+
+	// The routes and the backoff are validated by GetConfig.
+	routes, _ := parseCategoryRoutes(cfg.CategoryRoutes)
+	notifyRoutes, _ := LoadNotifyRoutes(cfg.NotifyRoutesFile)
+	backoff, _ := time.ParseDuration(cfg.NotifyRetryBackoff)
+
+	var deadLetter DeadLetterQueue
+	if cfg.NotifyDeadLetter != "" && (notifyRoutes != nil || len(newNotifiers(logger, cfg)) > 0) {
+		deadLetter = newDeadLetterQueue(ctx, logger, cfg)
+	}
+
+	// The templates are validated by GetConfig.
+	templates, _ := loadNotificationTemplates(cfg.NotifySubjectTemplate, cfg.NotifyBodyTemplate)
+
+	var threads StateStore
+	if cfg.SlackThreads && cfg.SlackToken != "" {
+		threads = state
+	}
+
+	if notifyRoutes != nil {
+		client := newHTTPClient(cfg)
+
+		for _, target := range notifyRoutes.targets() {
+			sinks = append(sinks, notifierSink{
+				notifier:    threadSlackNotifications(newNotifier(logger, cfg, client, target), threads),
+				artifactURL: cfg.ArtifactURL,
+				maxItems:    cfg.NotifyMaxItems,
+				routes:      notifyRoutes,
+				target:      target,
+				changesOnly: cfg.NotifyMode == notifyModeChanges,
+				retries:     cfg.NotifyRetries,
+				backoff:     backoff,
+				deadLetter:  deadLetter,
+				templates:   templates,
+				logger:      logger,
+			})
+		}
+
+		return sinks
+	}
+
+	for _, notifier := range newNotifiers(logger, cfg) {
+		sinks = append(sinks, notifierSink{
+			notifier:    threadSlackNotifications(notifier, threads),
+			artifactURL: cfg.ArtifactURL,
+			maxItems:    cfg.NotifyMaxItems,
+			categories:  routes[notifier.Name()],
+			changesOnly: cfg.NotifyMode == notifyModeChanges,
+			retries:     cfg.NotifyRetries,
+			backoff:     backoff,
+			deadLetter:  deadLetter,
+			templates:   templates,
+			logger:      logger,
+		})
+	}
+
+	return sinks
+}
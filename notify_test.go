@@ -0,0 +1,316 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNotifier is a mock implementation of the Notifier.
+type fakeNotifier struct {
+	notifications []Notification
+}
+
+// Name returns the name of the notifier.
+func (f *fakeNotifier) Name() string {
+	return "fake"
+}
+
+// Notify records the notification.
+func (f *fakeNotifier) Notify(_ context.Context, notification Notification) error {
+	f.notifications = append(f.notifications, notification)
+
+	return nil
+}
+
+var errNotifierUnavailable = errors.New("notifier unavailable")
+
+// flakyNotifier is a notifier failing its first attempts.
+type flakyNotifier struct {
+	failures int
+	attempts int
+}
+
+// Name returns the name of the notifier.
+func (f *flakyNotifier) Name() string {
+	return "flaky"
+}
+
+// Notify fails transiently until the failures are exhausted.
+func (f *flakyNotifier) Notify(ctx context.Context, _ Notification) error {
+	return notifyRetryFromContext(ctx).do(ctx, func() error {
+		f.attempts++
+		if f.attempts <= f.failures {
+			return &transientError{err: errNotifierUnavailable}
+		}
+
+		return nil
+	})
+}
+
+func TestLimitItems(t *testing.T) {
+	items := []string{"aaaa", "bbbb", "cccc", "dddd"}
+
+	tests := []struct {
+		name               string
+		maxItems, maxBytes int
+		want               []string
+		omitted            int
+	}{
+		{name: "all fit", maxItems: 10, maxBytes: 100, want: items, omitted: 0},
+		{name: "limited by items", maxItems: 3, maxBytes: 100, want: items[:3], omitted: 1},
+		{name: "limited by length", maxItems: 10, maxBytes: 11, want: items[:2], omitted: 2},
+		{name: "nothing fits", maxItems: 10, maxBytes: 3, want: []string{}, omitted: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, omitted := limitItems(items, tt.maxItems, tt.maxBytes, 1)
+			if !reflect.DeepEqual(got, tt.want) || omitted != tt.omitted {
+				t.Errorf("limitItems() = %v, %d, want %v, %d", got, omitted, tt.want, tt.omitted)
+			}
+		})
+	}
+}
+
+func TestMoreItemsFooter(t *testing.T) {
+	if got := moreItemsFooter(0, "https://example.com"); got != "" {
+		t.Errorf("expected no footer, got %q", got)
+	}
+
+	got := moreItemsFooter(42, "https://example.com/report.json")
+	if !strings.Contains(got, "42 more items") || !strings.Contains(got, "https://example.com/report.json") {
+		t.Errorf("unexpected footer %q", got)
+	}
+}
+
+func TestNotifierSink_Publish(t *testing.T) {
+	ctx := t.Context()
+	notifier := &fakeNotifier{}
+	sink := notifierSink{notifier: notifier, artifactURL: "https://example.com/report.json"}
+
+	if err := sink.Publish(ctx, &Report{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 0 {
+		t.Errorf("expected no notification for a report without violations and changes")
+	}
+
+	report := &Report{
+		Metadata:   RunMetadata{OrgID: "123", RunID: "run-1"},
+		Violations: []RuleViolation{{Severity: severityHigh, Message: "exposed", Asset: ProcessedAsset{Project: "p"}}},
+		Diffs:      []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "a1", IPAddress: "203.0.113.1", Project: "p"}}},
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notifier.notifications))
+	}
+
+	want := []string{"[HIGH] exposed (p)", "added: a1 203.0.113.1 (p)"}
+	if got := notifier.notifications[0]; !reflect.DeepEqual(got.Items, want) || got.ArtifactURL != sink.artifactURL || got.RunID != "run-1" {
+		t.Errorf("unexpected notification %+v", got)
+	}
+}
+
+func TestNotifierSink_PublishChangesOnly(t *testing.T) {
+	ctx := t.Context()
+	notifier := &fakeNotifier{}
+	sink := notifierSink{notifier: notifier, changesOnly: true}
+
+	report := &Report{
+		Violations: []RuleViolation{{Severity: severityHigh, Message: "exposed", Asset: ProcessedAsset{Project: "p"}}},
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 0 {
+		t.Fatalf("expected no notification for a report without changes, got %d", len(notifier.notifications))
+	}
+
+	report.Diffs = []AssetDiff{{Type: DiffRemoved, Asset: ProcessedAsset{Name: "a1", IPAddress: "203.0.113.1", Project: "p"}}}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	want := []string{"removed: a1 203.0.113.1 (p)"}
+	if len(notifier.notifications) != 1 || !reflect.DeepEqual(notifier.notifications[0].Items, want) {
+		t.Errorf("expected a notification of the changes only, got %+v", notifier.notifications)
+	}
+
+	if len(report.Violations) != 1 {
+		t.Errorf("expected the report to be left unchanged, got %d violations", len(report.Violations))
+	}
+}
+
+func TestNotifierSink_PublishMaxItems(t *testing.T) {
+	notifier := &fakeNotifier{}
+	sink := notifierSink{notifier: notifier, maxItems: 5}
+
+	if err := sink.Publish(t.Context(), &Report{Diffs: make([]AssetDiff, 8)}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 || notifier.notifications[0].MaxItems != 5 {
+		t.Errorf("expected a notification limited to 5 items per message, got %+v", notifier.notifications)
+	}
+}
+
+func TestNotifierSink_PublishRetries(t *testing.T) {
+	report := &Report{Metadata: RunMetadata{RunID: "run-1"}, Diffs: []AssetDiff{{Type: DiffAdded}}}
+
+	notifier := &flakyNotifier{failures: 2}
+	sink := notifierSink{notifier: notifier, retries: 2, backoff: time.Millisecond, logger: slog.New(slog.DiscardHandler)}
+
+	if err := sink.Publish(t.Context(), report); err != nil || notifier.attempts != 3 {
+		t.Errorf("expected a success after 3 attempts, got %d attempts and %v", notifier.attempts, err)
+	}
+
+	deadLetters := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	notifier = &flakyNotifier{failures: 5}
+	sink.notifier = notifier
+	sink.deadLetter = NewFileDeadLetterQueue(deadLetters)
+
+	if err := sink.Publish(t.Context(), report); !errors.Is(err, errNotifierUnavailable) || notifier.attempts != 3 {
+		t.Fatalf("expected a failure after 3 attempts, got %d attempts and %v", notifier.attempts, err)
+	}
+
+	data, err := os.ReadFile(deadLetters)
+	if err != nil {
+		t.Fatalf("failed to read the dead letters: %v", err)
+	}
+
+	var letter DeadLetter
+	if err := json.Unmarshal(data, &letter); err != nil {
+		t.Fatalf("failed to decode the dead letter: %v", err)
+	}
+
+	if letter.Notifier != "flaky" || letter.RunID != "run-1" || letter.Attempts != 3 || len(letter.Items) != 1 {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+}
+
+func TestNotifierSink_PublishRetriesFailedMessage(t *testing.T) {
+	var (
+		texts    []string
+		requests int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var payload struct {
+			Channel string `json:"channel"`
+			Text    string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		switch {
+		case payload.Channel == "#missing":
+			_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+		case payload.Channel == "#forbidden":
+			http.Error(w, "forbidden", http.StatusForbidden)
+		case requests == 2:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+		default:
+			texts = append(texts, payload.Text)
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	notifier := NewSlackNotifier(logger, &Config{SlackToken: "xoxb-token", SlackChannel: "#alerts"}, server.Client())
+	notifier.endpoint = server.URL
+
+	sink := notifierSink{notifier: notifier, retries: 2, backoff: time.Millisecond, logger: logger}
+	items := make([]AssetDiff, 120)
+
+	if err := sink.Publish(t.Context(), &Report{Diffs: items}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	// The second message is retried alone, without posting the first one again.
+	if requests != 4 || len(texts) != 3 || strings.Contains(texts[0], "continued") ||
+		!strings.Contains(texts[1], "continued") || !strings.Contains(texts[2], "continued") {
+		t.Errorf("expected 3 messages in 4 requests, got %d requests and %d messages", requests, len(texts))
+	}
+
+	for _, channel := range []string{"#missing", "#forbidden"} {
+		requests = 0
+		notifier.channel = channel
+
+		if err := sink.Publish(t.Context(), &Report{Diffs: items}); !errors.Is(err, errNotificationFailed) {
+			t.Errorf("expected the failure of %s, got %v", channel, err)
+		}
+
+		if requests != 1 {
+			t.Errorf("expected the permanent failure of %s not to be retried, got %d requests", channel, requests)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "30", want: 30 * time.Second},
+		{value: "Wed, 10 Jan 2024 12:01:00 GMT", want: time.Minute},
+		{value: "Wed, 10 Jan 2024 11:00:00 GMT", want: 0},
+		{value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(http.Header{"Retry-After": []string{tt.value}}, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNotifierSink_PublishDegraded(t *testing.T) {
+	var logs bytes.Buffer
+
+	notifier := &fakeNotifier{}
+	sink := notifierSink{notifier: notifier, logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+	ctx := withDegradedNotifiers(t.Context(), map[string]error{"fake": errors.New("bot is not in the channel")})
+
+	report := &Report{
+		Violations: []RuleViolation{{Severity: severityHigh, Message: "exposed", Asset: ProcessedAsset{Project: "p"}}},
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(notifier.notifications) != 0 {
+		t.Errorf("expected no notification from a degraded notifier, got %d", len(notifier.notifications))
+	}
+
+	for _, want := range []string{"bot is not in the channel", "[HIGH] exposed (p)"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected %q in the logs %s", want, logs.String())
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// slackAuthTestURL verifies a Slack token without side effects, and returns the scopes
+	// of the token in the X-OAuth-Scopes header.
+	// https://api.slack.com/methods/auth.test
+	slackAuthTestURL = "https://slack.com/api/auth.test"
+	// slackConversationsInfoURL returns whether a channel is archived and the bot is a member.
+	// https://api.slack.com/methods/conversations.info
+	slackConversationsInfoURL = "https://slack.com/api/conversations.info"
+
+	slackScopesHeader    = "X-OAuth-Scopes"
+	slackChatWriteScope  = "chat:write"
+	slackChatWritePublic = "chat:write.public"
+	slackMissingScope    = "missing_scope"
+	slackChannelNotFound = "channel_not_found"
+)
+
+var (
+	errInvalidNotifierConfig = errors.New("invalid notifier configuration")
+	errNotifierUnreachable   = errors.New("notifier is unreachable")
+	// errNotifierDegraded is returned by the checks of notifiers that are reachable and
+	// authenticated but cannot deliver, such as a Slack bot missing a scope. Such notifiers
+	// log their notifications instead of failing the run.
+	errNotifierDegraded = errors.New("notifier cannot deliver notifications")
+
+	// slackChannelPattern matches Slack channel IDs, such as C0123456789, and #channel names.
+	slackChannelPattern = regexp.MustCompile(`^([CGD][A-Z0-9]{8,}|#[a-z0-9][a-z0-9._-]{0,79})$`)
+)
+
+// notifierChecker is implemented by notifiers that can verify their configuration and
+// endpoint without sending a notification.
+type notifierChecker interface {
+	Check(ctx context.Context) error
+}
+
+// validateNotifierConfig checks the format of the notifier settings and returns all the
+// problems found, so that they can be fixed at once.
+func validateNotifierConfig(cfg *Config) error {
+	errs := []error{}
+
+	if cfg.SlackChannel != "" && !slackChannelPattern.MatchString(cfg.SlackChannel) {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_SLACK_CHANNEL must be a channel ID, such as C0123456789, "+
+			"or a #channel name, got %q", errInvalidNotifierConfig, cfg.SlackChannel))
+	}
+
+	if cfg.SlackChannel != "" && cfg.SlackToken == "" {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_SLACK_CHANNEL requires ASSET_WATCHER_SLACK_TOKEN",
+			errInvalidNotifierConfig))
+	}
+
+	for _, webhook := range []struct{ name, value string }{
+		{name: "ASSET_WATCHER_TEAMS_WEBHOOK_URL", value: cfg.TeamsWebhookURL},
+		{name: "ASSET_WATCHER_WEBHOOK_URL", value: cfg.WebhookURL},
+	} {
+		name, value := webhook.name, webhook.value
+		if value == "" {
+			continue
+		}
+
+		// The URL is not included in errors, as webhook URLs contain secrets.
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%w: %s must be an http(s) URL", errInvalidNotifierConfig, name))
+		}
+	}
+
+	// The key is not included in errors, as it is a secret.
+	if cfg.PagerDutyRoutingKey != "" && !pagerDutyRoutingKeyPattern.MatchString(cfg.PagerDutyRoutingKey) {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_PAGERDUTY_ROUTING_KEY must be the 32 characters "+
+			"integration key of an Events API v2 integration", errInvalidNotifierConfig))
+	}
+
+	errs = append(errs, validateEmailConfig(cfg)...)
+
+	return errors.Join(errs...)
+}
+
+// validateEmailConfig checks the settings of the email notifier.
+func validateEmailConfig(cfg *Config) []error {
+	if cfg.SMTPHost == "" {
+		return nil
+	}
+
+	errs := []error{}
+
+	if cfg.SMTPPort < 1 || cfg.SMTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_SMTP_PORT must be a port number, got %d",
+			errInvalidNotifierConfig, cfg.SMTPPort))
+	}
+
+	if cfg.SMTPPassword != "" && cfg.SMTPUsername == "" {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_SMTP_PASSWORD requires ASSET_WATCHER_SMTP_USERNAME",
+			errInvalidNotifierConfig))
+	}
+
+	if _, err := mail.ParseAddress(cfg.EmailFrom); err != nil {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_SMTP_HOST requires ASSET_WATCHER_EMAIL_FROM to be "+
+			"an email address, got %q", errInvalidNotifierConfig, cfg.EmailFrom))
+	}
+
+	if _, err := mail.ParseAddressList(cfg.EmailTo); err != nil {
+		errs = append(errs, fmt.Errorf("%w: ASSET_WATCHER_SMTP_HOST requires ASSET_WATCHER_EMAIL_TO to be "+
+			"a comma-separated list of email addresses, got %q", errInvalidNotifierConfig, cfg.EmailTo))
+	}
+
+	return errs
+}
+
+// checkNotifiers verifies that every notifier that supports it is reachable and accepts
+// its credentials, and returns all the failures. Notifiers that cannot deliver are not
+// failures: they are logged with the remediation and returned as degraded, keyed by name.
+func checkNotifiers(ctx context.Context, logger *slog.Logger, notifiers []Notifier) (map[string]error, error) {
+	degraded := map[string]error{}
+	errs := []error{}
+
+	for _, notifier := range notifiers {
+		checker, ok := notifier.(notifierChecker)
+		if !ok {
+			continue
+		}
+
+		err := checker.Check(ctx)
+
+		switch {
+		case errors.Is(err, errNotifierDegraded):
+			logger.ErrorContext(ctx, "Notifier cannot deliver notifications, they will be logged instead",
+				slog.String("notifier", notifier.Name()),
+				slog.Any("error", err),
+			)
+
+			degraded[notifier.Name()] = err
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		default:
+			logger.DebugContext(ctx, "Checked notifier", slog.String("notifier", notifier.Name()))
+		}
+	}
+
+	return degraded, errors.Join(errs...)
+}
+
+type degradedNotifiersContextKey struct{}
+
+// withDegradedNotifiers returns a context carrying the degraded notifiers of the run.
+func withDegradedNotifiers(ctx context.Context, degraded map[string]error) context.Context {
+	if len(degraded) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, degradedNotifiersContextKey{}, degraded)
+}
+
+// degradedNotifier returns why the notifier cannot deliver, or nil if it is not degraded.
+func degradedNotifier(ctx context.Context, name string) error {
+	degraded, _ := ctx.Value(degradedNotifiersContextKey{}).(map[string]error)
+
+	return degraded[name]
+}
+
+// slackChannelInfo is the response of conversations.info.
+type slackChannelInfo struct {
+	slackResponse
+
+	Channel struct {
+		IsArchived bool `json:"is_archived"`
+		IsMember   bool `json:"is_member"`
+	} `json:"channel"`
+}
+
+// Check verifies the token with auth.test, and that the bot can post to the channel: the
+// token must have the chat:write scope, and the channel must exist, not be archived, and
+// have the bot as a member, unless the token has the chat:write.public scope.
+func (n *SlackNotifier) Check(ctx context.Context) error {
+	body, header, err := n.call(ctx, http.MethodPost, n.authEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to verify Slack token: %w", err)
+	}
+
+	var resp slackResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse Slack response: %w", err)
+	}
+
+	if !resp.OK {
+		return fmt.Errorf("%w: Slack error %s", errInvalidNotifierConfig, resp.Error)
+	}
+
+	// Tokens of all Slack apps return their scopes; a missing header is not conclusive.
+	scopes := strings.Split(strings.ReplaceAll(header.Get(slackScopesHeader), " ", ""), ",")
+	if header.Get(slackScopesHeader) != "" && !slices.Contains(scopes, slackChatWriteScope) {
+		return fmt.Errorf("%w: the Slack token lacks the %s scope, add it to the Slack app and reinstall the app",
+			errNotifierDegraded, slackChatWriteScope)
+	}
+
+	if n.uploads && header.Get(slackScopesHeader) != "" && !slices.Contains(scopes, slackFilesWriteScope) {
+		n.logger.WarnContext(ctx, "The Slack token lacks the files:write scope, truncated notifications "+
+			"will not be attached as files", slog.String("scope", slackFilesWriteScope))
+	}
+
+	// conversations.info only accepts channel IDs, so #channel names are not checked.
+	if n.channel == "" || strings.HasPrefix(n.channel, "#") {
+		return nil
+	}
+
+	return n.checkChannel(ctx, slices.Contains(scopes, slackChatWritePublic))
+}
+
+func (n *SlackNotifier) checkChannel(ctx context.Context, canWritePublic bool) error {
+	body, _, err := n.call(ctx, http.MethodGet, n.conversationsEndpoint, url.Values{"channel": {n.channel}})
+	if err != nil {
+		return fmt.Errorf("failed to check Slack channel: %w", err)
+	}
+
+	var info slackChannelInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return fmt.Errorf("failed to parse Slack response: %w", err)
+	}
+
+	switch {
+	case info.Error == slackMissingScope:
+		// Reading channels requires the channels:read or groups:read scope, which posting does not.
+		n.logger.WarnContext(ctx, "Cannot check the Slack channel without the channels:read and groups:read scopes",
+			slog.String("channel", n.channel))
+
+		return nil
+	case info.Error == slackChannelNotFound:
+		return fmt.Errorf("%w: Slack channel %s is not found, check ASSET_WATCHER_SLACK_CHANNEL, "+
+			"or invite the bot to the private channel", errNotifierDegraded, n.channel)
+	case !info.OK:
+		return fmt.Errorf("%w: Slack error %s", errInvalidNotifierConfig, info.Error)
+	case info.Channel.IsArchived:
+		return fmt.Errorf("%w: Slack channel %s is archived, unarchive it or change ASSET_WATCHER_SLACK_CHANNEL",
+			errNotifierDegraded, n.channel)
+	case !info.Channel.IsMember && !canWritePublic:
+		return fmt.Errorf("%w: the Slack bot is not in channel %s, invite it with /invite "+
+			"or add the %s scope to the Slack app", errNotifierDegraded, n.channel, slackChatWritePublic)
+	}
+
+	return nil
+}
+
+// call calls a Slack Web API method with the token, returning the response body and header.
+func (n *SlackNotifier) call(ctx context.Context, method, endpoint string, query url.Values) ([]byte, http.Header, error) {
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return doRequest(n.client, req, http.Header{"Authorization": []string{"Bearer " + n.token}})
+}
+
+// Check verifies that the webhook is reachable.
+func (n *TeamsNotifier) Check(ctx context.Context) error {
+	return checkWebhookURL(ctx, n.client, n.webhookURL)
+}
+
+// Check verifies that the webhook is reachable.
+func (n *WebhookNotifier) Check(ctx context.Context) error {
+	return checkWebhookURL(ctx, n.client, n.url)
+}
+
+// checkWebhookURL sends a HEAD request to the webhook. Webhook receivers commonly reject
+// other methods than POST, so any response but Not Found and Gone shows that the webhook
+// exists. The URL is not included in errors, as webhook URLs contain secrets.
+func checkWebhookURL(ctx context.Context, client *http.Client, webhookURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, webhookURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return fmt.Errorf("%w: %w", errNotifierUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("%w: %s", errNotifierUnreachable, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package assetwatcher
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateNotifierConfig(t *testing.T) {
+	valid := []*Config{
+		{},
+		{SlackToken: "xoxb-token", SlackChannel: "C0123456789"},
+		{SlackToken: "xoxb-token", SlackChannel: "#asset-alerts"},
+		{TeamsWebhookURL: "https://example.webhook.office.com/webhookb2/x", WebhookURL: "https://hooks.example.com/x"},
+		{PagerDutyRoutingKey: "0123456789abcdef0123456789ABCDEF"},
+		{SMTPHost: "smtp.example.com", SMTPPort: 587, EmailFrom: "Asset Watcher <aw@example.com>", EmailTo: "a@example.com, b@example.com"},
+	}
+
+	for _, cfg := range valid {
+		if err := validateNotifierConfig(cfg); err != nil {
+			t.Errorf("validateNotifierConfig(%+v) failed: %v", cfg, err)
+		}
+	}
+
+	err := validateNotifierConfig(&Config{
+		SlackChannel:    "alerts",
+		TeamsWebhookURL: "hooks.example.com/x",
+		WebhookURL:      "ftp://hooks.example.com/secret",
+		SMTPHost:        "smtp.example.com",
+		SMTPPassword:    "smtp-password",
+		EmailTo:         "netops",
+
+		PagerDutyRoutingKey: "not-a-key",
+	})
+	if !errors.Is(err, errInvalidNotifierConfig) {
+		t.Fatalf("expected an invalid notifier configuration, got %v", err)
+	}
+
+	// All the problems are reported at once.
+	for _, want := range []string{
+		"ASSET_WATCHER_SLACK_CHANNEL must be", "requires ASSET_WATCHER_SLACK_TOKEN",
+		"ASSET_WATCHER_TEAMS_WEBHOOK_URL", "ASSET_WATCHER_WEBHOOK_URL", "ASSET_WATCHER_SMTP_PORT",
+		"requires ASSET_WATCHER_SMTP_USERNAME", "ASSET_WATCHER_EMAIL_FROM", "ASSET_WATCHER_EMAIL_TO",
+		"ASSET_WATCHER_PAGERDUTY_ROUTING_KEY",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected the webhook URL to be omitted from %v", err)
+	}
+}
+
+func TestCheckNotifiers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth.test":
+			if r.Header.Get("Authorization") == "Bearer xoxb-valid" {
+				_, _ = w.Write([]byte(`{"ok": true}`))
+			} else {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+			}
+		case "/webhook":
+			if r.Method != http.MethodHead {
+				t.Errorf("unexpected method %s", r.Method)
+			}
+
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	newSlack := func(token string) *SlackNotifier {
+		notifier := NewSlackNotifier(logger, &Config{SlackToken: token}, server.Client())
+		notifier.authEndpoint = server.URL + "/auth.test"
+
+		return notifier
+	}
+
+	ok := []Notifier{
+		newSlack("xoxb-valid"),
+		NewWebhookNotifier(logger, &Config{WebhookURL: server.URL + "/webhook"}, server.Client()),
+	}
+	if degraded, err := checkNotifiers(t.Context(), logger, ok); err != nil || len(degraded) > 0 {
+		t.Errorf("checkNotifiers failed: %v, degraded %v", err, degraded)
+	}
+
+	failing := []Notifier{
+		newSlack("xoxb-revoked"),
+		NewTeamsNotifier(logger, &Config{TeamsWebhookURL: server.URL + "/deleted"}, server.Client()),
+	}
+
+	_, err := checkNotifiers(t.Context(), logger, failing)
+	if !errors.Is(err, errInvalidNotifierConfig) || !errors.Is(err, errNotifierUnreachable) {
+		t.Errorf("expected both notifiers to fail, got %v", err)
+	}
+}
+
+func TestSlackNotifierCheck_Degraded(t *testing.T) {
+	channels := map[string]string{
+		"C0000000001": `{"ok": true, "channel": {"is_archived": false, "is_member": true}}`,
+		"C0000000002": `{"ok": true, "channel": {"is_archived": true, "is_member": true}}`,
+		"C0000000003": `{"ok": true, "channel": {"is_archived": false, "is_member": false}}`,
+		"C0000000004": `{"ok": false, "error": "channel_not_found"}`,
+		"C0000000005": `{"ok": false, "error": "missing_scope", "needed": "channels:read"}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth.test":
+			w.Header().Set("X-OAuth-Scopes", strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		case "/conversations.info":
+			_, _ = w.Write([]byte(channels[r.URL.Query().Get("channel")]))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name         string
+		scopes       string
+		channel      string
+		wantDegraded string
+	}{
+		{name: "member", scopes: "chat:write,channels:read", channel: "C0000000001"},
+		{name: "channel name", scopes: "chat:write", channel: "#alerts"},
+		{name: "no chat:write", scopes: "channels:read", channel: "C0000000001", wantDegraded: "chat:write scope"},
+		{name: "archived", scopes: "chat:write", channel: "C0000000002", wantDegraded: "archived"},
+		{name: "not a member", scopes: "chat:write", channel: "C0000000003", wantDegraded: "/invite"},
+		{name: "public writer", scopes: "chat:write, chat:write.public", channel: "C0000000003"},
+		{name: "not found", scopes: "chat:write", channel: "C0000000004", wantDegraded: "not found"},
+		{name: "channel not readable", scopes: "chat:write", channel: "C0000000005"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake auth.test returns the token as the scopes.
+			notifier := NewSlackNotifier(logger, &Config{SlackToken: tt.scopes, SlackChannel: tt.channel}, server.Client())
+			notifier.authEndpoint = server.URL + "/auth.test"
+			notifier.conversationsEndpoint = server.URL + "/conversations.info"
+
+			degraded, err := checkNotifiers(t.Context(), logger, []Notifier{notifier})
+			if err != nil {
+				t.Fatalf("checkNotifiers failed: %v", err)
+			}
+
+			reason := degraded["slack"]
+
+			switch {
+			case tt.wantDegraded == "" && reason != nil:
+				t.Errorf("expected the notifier not to be degraded, got %v", reason)
+			case tt.wantDegraded != "" && (!errors.Is(reason, errNotifierDegraded) ||
+				!strings.Contains(reason.Error(), tt.wantDegraded)):
+				t.Errorf("expected the notifier to be degraded with %q, got %v", tt.wantDegraded, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// transientError is a failure of a notification request that may succeed when retried: a
+// network error, a server error, or a rate limit, with the delay of its Retry-After header.
+type transientError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// retryAfter returns the delay of the Retry-After header, in seconds or as an HTTP date, or
+// zero without a valid header.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
+
+type notifyRetryContextKey struct{}
+
+// notifyRetry retries the requests of a notification failing transiently. It is passed to the
+// notifiers in the context, so that a notification sent as several requests, such as the
+// messages of a long Slack notification, retries the failed request only instead of sending
+// the delivered ones again. Permanent failures, such as a rejected token, are not retried.
+type notifyRetry struct {
+	notifier string
+	retries  int
+	backoff  time.Duration
+	logger   *slog.Logger
+
+	// attempts is the largest number of attempts of a request, recorded in the dead letter.
+	attempts int
+}
+
+// withNotifyRetry returns the context retrying the requests of the notifiers with the policy.
+func withNotifyRetry(ctx context.Context, retry *notifyRetry) context.Context {
+	return context.WithValue(ctx, notifyRetryContextKey{}, retry)
+}
+
+// notifyRetryFromContext returns the retry policy of the notification, or nil to send every
+// request once.
+func notifyRetryFromContext(ctx context.Context) *notifyRetry {
+	retry, _ := ctx.Value(notifyRetryContextKey{}).(*notifyRetry)
+
+	return retry
+}
+
+// do sends a request, retrying its transient failures with an exponential backoff, or after the
+// delay requested by the server.
+func (r *notifyRetry) do(ctx context.Context, send func() error) error {
+	err := send()
+	if r == nil {
+		return err
+	}
+
+	attempts := 1
+	defer func() { r.attempts = max(r.attempts, attempts) }()
+
+	for backoff := r.backoff; attempts <= r.retries; backoff *= 2 {
+		var transient *transientError
+		if !errors.As(err, &transient) {
+			return err
+		}
+
+		delay := backoff
+		if transient.retryAfter > 0 {
+			delay = transient.retryAfter
+		}
+
+		r.logger.WarnContext(ctx, "Notification failed, retrying",
+			slog.String("notifier", r.notifier),
+			slog.Int("attempt", attempts),
+			slog.Duration("backoff", delay),
+			slog.Any("error", err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		err = send()
+		attempts++
+	}
+
+	return err
+}
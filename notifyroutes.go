@@ -0,0 +1,178 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var errInvalidNotifyRoute = errors.New("invalid notification route")
+
+// NotifyRoute sends the findings matching all its conditions to its targets. Empty conditions
+// match any finding. Changes have neither a rule nor a severity, so they only match routes
+// without rules and severities.
+type NotifyRoute struct {
+	Name       string   `yaml:"name"`
+	Rules      []string `yaml:"rules"`
+	Severities []string `yaml:"severities"`
+	Project    string   `yaml:"project"`
+	Notify     []string `yaml:"notify"`
+	Continue   bool     `yaml:"continue"`
+
+	project *regexp.Regexp
+}
+
+// NotifyRoutes is the routing table of the notifications. Every finding is sent to the
+// targets of the first route it matches, and of the following matching routes as long as the
+// matching routes continue. Findings matching no route are not notified.
+//
+// A target is the name of a configured notifier, such as pagerduty, or slack:CHANNEL to post
+// to another Slack channel than ASSET_WATCHER_SLACK_CHANNEL.
+type NotifyRoutes struct {
+	Routes []NotifyRoute `yaml:"routes"`
+}
+
+// LoadNotifyRoutes reads and validates a YAML routing table. An empty path results in nil
+// routes, with every notifier receiving every finding.
+func LoadNotifyRoutes(path string) (*NotifyRoutes, error) {
+	if path == "" {
+		return nil, nil //nolint:nilnil // No routing table is configured.
+	}
+
+	f, err := os.Open(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notification routes file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	routes := &NotifyRoutes{}
+	if err := decoder.Decode(routes); err != nil {
+		return nil, fmt.Errorf("failed to parse notification routes file %s: %w", path, err)
+	}
+
+	if err := routes.compile(); err != nil {
+		return nil, fmt.Errorf("invalid notification routes file %s: %w", path, err)
+	}
+
+	return routes, nil
+}
+
+func (r *NotifyRoutes) compile() error {
+	for i := range r.Routes {
+		route := &r.Routes[i]
+
+		name := route.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(i+1)
+		}
+
+		if len(route.Notify) == 0 {
+			return fmt.Errorf("%w: route %s has no notify targets", errInvalidNotifyRoute, name)
+		}
+
+		for _, target := range route.Notify {
+			notifier, channel, hasChannel := strings.Cut(target, ":")
+
+			if !slices.Contains(routableNotifiers, notifier) {
+				return fmt.Errorf("%w: route %s: unknown notifier %s, expected one of %s", errInvalidNotifyRoute,
+					name, strconv.Quote(notifier), strings.Join(routableNotifiers, ", "))
+			}
+
+			if hasChannel && (notifier != "slack" || !slackChannelPattern.MatchString(channel)) {
+				return fmt.Errorf("%w: route %s: invalid target %s, only slack targets take a channel ID "+
+					"or #channel name", errInvalidNotifyRoute, name, strconv.Quote(target))
+			}
+		}
+
+		for j, severity := range route.Severities {
+			route.Severities[j] = strings.ToUpper(severity)
+
+			if route.Severities[j] != severityHigh && route.Severities[j] != severityMedium {
+				return fmt.Errorf("%w: route %s: unknown severity %s, expected %s or %s", errInvalidNotifyRoute,
+					name, strconv.Quote(severity), severityHigh, severityMedium)
+			}
+		}
+
+		if route.Project != "" {
+			re, err := regexp.Compile(route.Project)
+			if err != nil {
+				return fmt.Errorf("%w: route %s: invalid project pattern: %w", errInvalidNotifyRoute, name, err)
+			}
+
+			route.project = re
+		}
+	}
+
+	return nil
+}
+
+// targets returns the distinct targets of all routes, in the order of their first appearance.
+func (r *NotifyRoutes) targets() []string {
+	targets := []string{}
+
+	for _, route := range r.Routes {
+		for _, target := range route.Notify {
+			if !slices.Contains(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	return targets
+}
+
+// routeTargets returns the targets of a finding of the project, with the rule and the severity
+// of a violation, or empty ones for a change.
+func (r *NotifyRoutes) routeTargets(project, rule, severity string) []string {
+	targets := []string{}
+
+	for _, route := range r.Routes {
+		if !route.matches(project, rule, severity) {
+			continue
+		}
+
+		targets = append(targets, route.Notify...)
+
+		if !route.Continue {
+			break
+		}
+	}
+
+	return targets
+}
+
+func (route *NotifyRoute) matches(project, rule, severity string) bool {
+	if len(route.Rules) > 0 && !slices.Contains(route.Rules, rule) {
+		return false
+	}
+
+	if len(route.Severities) > 0 && !slices.Contains(route.Severities, severity) {
+		return false
+	}
+
+	return route.project == nil || route.project.MatchString(project)
+}
+
+// reportForTarget returns a copy of the report with the violations and changes routed to the
+// target only.
+func (r *NotifyRoutes) reportForTarget(report *Report, target string) *Report {
+	routed := *report
+
+	routed.Violations = slices.DeleteFunc(slices.Clone(report.Violations), func(v RuleViolation) bool {
+		return !slices.Contains(r.routeTargets(v.Asset.Project, v.Rule, v.Severity), target)
+	})
+	routed.Diffs = slices.DeleteFunc(slices.Clone(report.Diffs), func(d AssetDiff) bool {
+		return !slices.Contains(r.routeTargets(d.Asset.Project, "", ""), target)
+	})
+
+	return &routed
+}
@@ -0,0 +1,139 @@
+package assetwatcher
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testNotifyRoutes = `
+routes:
+  - name: prod-exposure
+    project: ^prod-
+    rules: [internet-exposed-port]
+    notify: ["slack:#secops"]
+    continue: true
+  - name: prod-critical
+    project: ^prod-
+    severities: [high]
+    notify: [pagerduty, "slack:#secops"]
+  - name: everything-else
+    notify: ["slack:#cloud-inventory"]
+`
+
+func writeNotifyRoutes(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "routes.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write the routes file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadNotifyRoutes(t *testing.T) {
+	routes, err := LoadNotifyRoutes(writeNotifyRoutes(t, testNotifyRoutes))
+	if err != nil {
+		t.Fatalf("LoadNotifyRoutes failed: %v", err)
+	}
+
+	want := []string{"slack:#secops", "pagerduty", "slack:#cloud-inventory"}
+	if got := routes.targets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() = %v, want %v", got, want)
+	}
+
+	if routes, err := LoadNotifyRoutes(""); routes != nil || err != nil {
+		t.Errorf("expected no routes without a file, got %v, %v", routes, err)
+	}
+
+	for name, content := range map[string]string{
+		"no targets":       "routes:\n  - project: prod\n",
+		"unknown notifier": "routes:\n  - notify: [opsgenie]\n",
+		"channel":          "routes:\n  - notify: [\"teams:#secops\"]\n",
+		"invalid channel":  "routes:\n  - notify: [\"slack:secops\"]\n",
+		"severity":         "routes:\n  - severities: [low]\n    notify: [slack]\n",
+		"project":          "routes:\n  - project: \"(\"\n    notify: [slack]\n",
+	} {
+		if _, err := LoadNotifyRoutes(writeNotifyRoutes(t, content)); !errors.Is(err, errInvalidNotifyRoute) {
+			t.Errorf("%s: expected %v, got %v", name, errInvalidNotifyRoute, err)
+		}
+	}
+}
+
+func TestNotifyRoutes_ReportForTarget(t *testing.T) {
+	routes, err := LoadNotifyRoutes(writeNotifyRoutes(t, testNotifyRoutes))
+	if err != nil {
+		t.Fatalf("LoadNotifyRoutes failed: %v", err)
+	}
+
+	report := &Report{
+		Violations: []RuleViolation{
+			{Rule: ruleOutOfBandAddress, Severity: severityHigh, Asset: ProcessedAsset{Name: "prod-high", Project: "prod-web"}},
+			{Rule: ruleInternetExposedPort, Severity: severityMedium, Asset: ProcessedAsset{Name: "prod-exposed", Project: "prod-web"}},
+			{Rule: ruleOrphanedExternalAddress, Severity: severityMedium, Asset: ProcessedAsset{Name: "prod-medium", Project: "prod-web"}},
+			{Rule: ruleOutOfBandAddress, Severity: severityHigh, Asset: ProcessedAsset{Name: "dev-high", Project: "dev"}},
+		},
+		Diffs: []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "prod-added", Project: "prod-web"}}},
+	}
+
+	names := func(r *Report) []string {
+		names := []string{}
+		for _, v := range r.Violations {
+			names = append(names, v.Asset.Name)
+		}
+
+		for _, d := range r.Diffs {
+			names = append(names, d.Asset.Name)
+		}
+
+		return names
+	}
+
+	for target, want := range map[string][]string{
+		// The exposure route continues, so the exposed asset also reaches the last route.
+		"pagerduty":              {"prod-high"},
+		"slack:#secops":          {"prod-high", "prod-exposed"},
+		"slack:#cloud-inventory": {"prod-exposed", "prod-medium", "dev-high", "prod-added"},
+	} {
+		if got := names(routes.reportForTarget(report, target)); !reflect.DeepEqual(got, want) {
+			t.Errorf("reportForTarget(%s) = %v, want %v", target, got, want)
+		}
+	}
+
+	if len(report.Violations) != 4 {
+		t.Errorf("expected the report to be left unchanged, got %d violations", len(report.Violations))
+	}
+}
+
+func TestNewNotifierSinks_Routes(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.SlackToken = "xoxb-token"
+	cfg.SlackChannel = "#general"
+	cfg.PagerDutyRoutingKey = "0123456789abcdef0123456789abcdef"
+	cfg.NotifyRoutesFile = writeNotifyRoutes(t, testNotifyRoutes)
+
+	sinks := newNotifierSinks(t.Context(), slog.New(slog.DiscardHandler), &cfg, nil)
+
+	names := []string{}
+	channels := []string{}
+
+	for _, sink := range sinks {
+		names = append(names, sink.Name())
+
+		if slack, ok := sink.(notifierSink).notifier.(*SlackNotifier); ok {
+			channels = append(channels, slack.channel)
+		}
+	}
+
+	if want := []string{"slack:#secops", "pagerduty", "slack:#cloud-inventory"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sinks = %v, want %v", names, want)
+	}
+
+	if want := []string{"#secops", "#cloud-inventory"}; !reflect.DeepEqual(channels, want) {
+		t.Errorf("Slack channels = %v, want %v", channels, want)
+	}
+}
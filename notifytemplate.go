@@ -0,0 +1,108 @@
+package assetwatcher
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// notificationTemplates render the subject and the body of the notifications from template
+// files, replacing the title and the list of items of the notifiers.
+type notificationTemplates struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// loadNotificationTemplates parses the template files of ASSET_WATCHER_NOTIFY_SUBJECT_TEMPLATE
+// and ASSET_WATCHER_NOTIFY_BODY_TEMPLATE, or returns nil if neither is set.
+func loadNotificationTemplates(subjectPath, bodyPath string) (*notificationTemplates, error) {
+	if subjectPath == "" && bodyPath == "" {
+		return nil, nil //nolint:nilnil // No templates are configured.
+	}
+
+	templates := &notificationTemplates{}
+
+	for _, t := range []struct {
+		path string
+		tmpl **template.Template
+	}{
+		{path: subjectPath, tmpl: &templates.subject},
+		{path: bodyPath, tmpl: &templates.body},
+	} {
+		if t.path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(t.path) //nolint:gosec // The path is provided by the operator.
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notification template: %w", err)
+		}
+
+		tmpl, err := template.New(filepath.Base(t.path)).Funcs(templateFuncs).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notification template: %w", err)
+		}
+
+		*t.tmpl = tmpl
+	}
+
+	return templates, nil
+}
+
+// apply renders the templates with the notification, so that .Title, .Summary, .Items, and
+// .Report are available. The subject replaces the title, on a single line, and is available
+// to the body as .Title.
+func (t *notificationTemplates) apply(notification *Notification) error {
+	if t.subject != nil {
+		var b strings.Builder
+		if err := t.subject.Execute(&b, notification); err != nil {
+			return fmt.Errorf("failed to render notification subject: %w", err)
+		}
+
+		notification.Title = strings.Join(strings.Fields(b.String()), " ")
+	}
+
+	if t.body != nil {
+		var b strings.Builder
+		if err := t.body.Execute(&b, notification); err != nil {
+			return fmt.Errorf("failed to render notification body: %w", err)
+		}
+
+		notification.Body = strings.TrimSpace(b.String())
+	}
+
+	return nil
+}
+
+var (
+	htmlLinkPattern      = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlBoldPattern      = regexp.MustCompile(`(?is)<(?:b|strong)>(.*?)</(?:b|strong)>`)
+	htmlItalicPattern    = regexp.MustCompile(`(?is)<(?:i|em)>(.*?)</(?:i|em)>`)
+	htmlCodePattern      = regexp.MustCompile(`(?is)<code>(.*?)</code>`)
+	htmlItemPattern      = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlBreakPattern     = regexp.MustCompile(`(?i)<br\s*/?>|</li>`)
+	htmlParagraphPattern = regexp.MustCompile(`(?i)</p>|</h[1-6]>|</[uo]l>`)
+	htmlTagPattern       = regexp.MustCompile(`<[^>]*>`)
+	blankLinesPattern    = regexp.MustCompile(`\n{3,}`)
+)
+
+// convertHTMLToMarkdown converts the basic formatting of an HTML fragment, such as the
+// descriptions of findings, into Markdown: links, bold, italic, code, list items, and line
+// breaks. Other tags are removed, and entities are unescaped.
+func convertHTMLToMarkdown(s string) string {
+	s = htmlLinkPattern.ReplaceAllString(s, "[$2]($1)")
+	s = htmlBoldPattern.ReplaceAllString(s, "**$1**")
+	s = htmlItalicPattern.ReplaceAllString(s, "_${1}_")
+	s = htmlCodePattern.ReplaceAllString(s, "`$1`")
+	s = htmlItemPattern.ReplaceAllString(s, "- ")
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlParagraphPattern.ReplaceAllString(s, "\n\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = blankLinesPattern.ReplaceAllString(s, "\n\n")
+
+	return strings.TrimSpace(html.UnescapeString(s))
+}
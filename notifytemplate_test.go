@@ -0,0 +1,84 @@
+package assetwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotificationTemplates_Apply(t *testing.T) {
+	dir := t.TempDir()
+	subject := filepath.Join(dir, "subject.tmpl")
+	body := filepath.Join(dir, "body.tmpl")
+
+	if err := os.WriteFile(subject, []byte("[{{ .Report.Metadata.OrgID }}]\n{{ len .Report.Violations }} violations\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := `{{ .Title }}
+{{ range .Report.Violations }}- {{ upper .Severity }} {{ .Asset.Name }}: {{ markdown .Message }}
+{{ end }}`
+	if err := os.WriteFile(body, []byte(tmpl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := loadNotificationTemplates(subject, body)
+	if err != nil {
+		t.Fatalf("loadNotificationTemplates() error = %v", err)
+	}
+
+	report := &Report{
+		Metadata: RunMetadata{OrgID: "123"},
+		Violations: []RuleViolation{
+			{Severity: "high", Message: "<b>public</b> address", Asset: ProcessedAsset{Name: "web"}},
+		},
+	}
+
+	notification := newNotification(report, "")
+	if err := templates.apply(&notification); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	if notification.Title != "[123] 1 violations" {
+		t.Errorf("unexpected title %q", notification.Title)
+	}
+
+	if notification.Body != "[123] 1 violations\n- HIGH web: **public** address" {
+		t.Errorf("unexpected body %q", notification.Body)
+	}
+}
+
+func TestLoadNotificationTemplates(t *testing.T) {
+	if templates, err := loadNotificationTemplates("", ""); templates != nil || err != nil {
+		t.Errorf("expected no templates, got %v, %v", templates, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "body.tmpl")
+	if err := os.WriteFile(path, []byte("{{ .Title"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadNotificationTemplates("", path); err == nil || !strings.Contains(err.Error(), "parse") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+}
+
+func TestConvertHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{html: "plain &amp; simple", want: "plain & simple"},
+		{html: `See <a href="https://example.com/x">the <b>docs</b></a>.`, want: "See [the **docs**](https://example.com/x)."},
+		{html: "<p>Use <code>gcloud</code> or <em>the console</em>.</p><p>Next</p>", want: "Use `gcloud` or _the console_.\n\nNext"},
+		{html: "<ul><li>one</li><li>two</li></ul>", want: "- one\n- two"},
+		{html: "line<br/>break <span class=\"x\">kept</span>", want: "line\nbreak kept"},
+	}
+
+	for _, tt := range tests {
+		if got := convertHTMLToMarkdown(tt.html); got != tt.want {
+			t.Errorf("convertHTMLToMarkdown(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
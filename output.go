@@ -1,61 +1,716 @@
-package main
+package assetwatcher
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
 )
 
 const tabWriterPadding = 3
 
-func outputToStdOut(ctx context.Context, logger *slog.Logger, processedAssets []ProcessedAsset, outputFormat string) {
-	switch outputFormat {
-	case "table":
-		outputToStdOutTable(ctx, logger, processedAssets)
-	case "json":
-		outputToStdOutJSON(ctx, logger, processedAssets)
-	default:
-		fmt.Fprintf(os.Stderr, "unknown output format: %s\n", outputFormat)
-		outputToStdOutTable(ctx, logger, processedAssets)
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+)
+
+// outputWriter writes the report in an output format.
+type outputWriter func(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, cfg *Config)
+
+// outputWriters are the writers of the output formats of ASSET_WATCHER_OUTPUT_FORMAT, which
+// GetConfig only accepts if they have a writer.
+var outputWriters = map[string]outputWriter{
+	outputFormatTable:     outputTable,
+	outputFormatJSON:      withoutConfig(outputJSON),
+	outputFormatGeofeed:   outputGeofeed,
+	outputFormatTerraform: withoutConfig(outputTerraform),
+	outputFormatNDJSON:    withoutConfig(outputNDJSON),
+	outputFormatXLSX:      outputXLSX,
+	outputFormatSARIF:     withoutConfig(outputSARIF),
+	outputFormatJUnit:     withoutConfig(outputJUnit),
+}
+
+// withoutConfig returns the output writer of a format that does not depend on the configuration.
+func withoutConfig(write func(context.Context, *slog.Logger, io.Writer, *Report)) outputWriter {
+	return func(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, _ *Config) {
+		write(ctx, logger, w, report)
 	}
 }
 
-func outputToStdOutTable(ctx context.Context, logger *slog.Logger, processedAssets []ProcessedAsset) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
-	_, _ = fmt.Fprintln(w, "Display Name\tLocation\tProject ID\tIP Address\tState\tCreated At")
-	_, _ = fmt.Fprintln(w, "------------\t--------\t----------\t----------\t-----\t----------")
+// outputFormats returns the output formats, sorted.
+func outputFormats() []string {
+	return slices.Sorted(maps.Keys(outputWriters))
+}
 
-	for _, asset := range processedAssets {
-		resource := asset
+func outputReport(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, cfg *Config) {
+	// Released assets are tracked in the snapshot and the history, but only shown on request.
+	if !cfg.ShowReleased && report.Released != nil {
+		shown := *report
+		shown.Released = nil
+		report = &shown
+	}
+
+	// The time format is validated by GetConfig.
+	if times, err := newTimeFormatter(cfg.TimeFormat, cfg.TimeZone); err == nil {
+		shown := *report
+		shown.Assets = times.assets(report.Assets)
+		shown.Released = times.assets(report.Released)
+		report = &shown
+	}
+
+	// A custom template replaces the output format.
+	if cfg.OutputTemplate != "" {
+		outputTemplate(ctx, logger, w, report, cfg)
+
+		return
+	}
+
+	// The output format is validated by GetConfig.
+	write, ok := outputWriters[cfg.OutputFormat]
+	if !ok {
+		logger.ErrorContext(ctx, "unknown output format", slog.String("format", cfg.OutputFormat))
+		exit(ctx, 1)
+
+		return
+	}
 
-		_, _ = fmt.Fprintf(
-			w,
-			"%s\t%s\t%s\t%s\t%s\t%s\n",
-			resource.Name,
-			resource.Location,
-			resource.Project,
-			resource.IPAddress,
-			resource.Status,
-			resource.CreatedAt,
+	write(ctx, logger, w, report, cfg)
+}
+
+func outputTable(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, cfg *Config) {
+	groups := groupByAssetType(report.Assets)
+
+	for i, group := range groups {
+		if len(groups) > 1 {
+			if i > 0 {
+				_, _ = fmt.Fprintln(w)
+			}
+
+			_, _ = fmt.Fprintln(w, group.assetType)
+		}
+
+		outputAssetTable(ctx, logger, w, group.assets, tableColumns(group.assetType, cfg))
+	}
+
+	if report.Summary.Cost != nil {
+		outputCostSummaryTable(ctx, logger, w, *report.Summary.Cost)
+	}
+
+	if report.Summary.Recommendations != nil {
+		outputRecommendationSummaryTable(ctx, logger, w, *report.Summary.Recommendations)
+	}
+
+	if report.Summary.Groups != nil {
+		outputGroupSummaryTable(ctx, logger, w, *report.Summary.Groups)
+	}
+
+	if report.Summary.Categories != nil {
+		outputCategorySummaryTable(ctx, logger, w, report.Summary.Categories)
+	}
+
+	if report.Summary.Baseline != nil {
+		outputBaselineTable(ctx, logger, w, *report.Summary.Baseline, report.Diffs)
+	}
+
+	if report.Summary.Coverage != nil {
+		outputCoverageSummaryTable(ctx, logger, w, *report.Summary.Coverage)
+	}
+
+	if len(report.UnscannableProjects) > 0 {
+		outputUnscannableProjectsTable(ctx, logger, w, report.UnscannableProjects)
+	}
+
+	if report.DNS != nil {
+		outputDNSReconciliationTable(ctx, logger, w, *report.DNS)
+	}
+
+	if report.Prefixes != nil {
+		outputPrefixGroupsTable(ctx, logger, w, report.Prefixes)
+	}
+
+	if report.RDAP != nil {
+		outputRDAPFindingsTable(ctx, logger, w, report.RDAP)
+	}
+
+	if report.Terraform != nil {
+		outputTerraformDriftTable(ctx, logger, w, report.Terraform)
+	}
+
+	if len(report.Released) > 0 {
+		outputReleasedTable(ctx, logger, w, report.Released)
+	}
+}
+
+// assetGroup is a list of assets of the same type.
+type assetGroup struct {
+	assetType string
+	assets    []ProcessedAsset
+}
+
+// groupByAssetType splits the assets by type, keeping the order in which the types first appear.
+// An empty list of assets results in a single empty group, so that the table header is still printed.
+func groupByAssetType(assets []ProcessedAsset) []assetGroup {
+	if len(assets) == 0 {
+		return []assetGroup{{}}
+	}
+
+	groups := []assetGroup{}
+	index := make(map[string]int)
+
+	for _, asset := range assets {
+		i, ok := index[asset.AssetType]
+		if !ok {
+			i = len(groups)
+			index[asset.AssetType] = i
+			groups = append(groups, assetGroup{assetType: asset.AssetType})
+		}
+
+		groups[i].assets = append(groups[i].assets, asset)
+	}
+
+	return groups
+}
+
+// tableColumns returns the columns of the asset type profile, followed by the optional columns.
+func tableColumns(assetType string, cfg *Config) []column {
+	columns := slices.Clone(extractorFor(assetType).columns)
+
+	if cfg.ShowAge {
+		columns = append(columns, column{header: "Age", value: func(a ProcessedAsset) string { return orNotAvailable(a.Age) }})
+	}
+
+	if cfg.ShowCost {
+		columns = append(columns, column{header: "Monthly Cost", value: func(a ProcessedAsset) string {
+			return formatCost(a.EstimatedMonthlyCost)
+		}})
+	}
+
+	if cfg.ShowRecommendations {
+		columns = append(columns,
+			column{header: "Recommendation", value: func(a ProcessedAsset) string { return a.Recommendation }},
+			column{header: "Recommended Savings", value: func(a ProcessedAsset) string {
+				return formatCost(a.RecommendedMonthlySavings)
+			}},
+			column{header: "Idle According To", value: func(a ProcessedAsset) string { return a.IdleAgreement }},
 		)
 	}
 
-	err := w.Flush()
+	if cfg.ClassificationRules != "" {
+		columns = append(columns, column{header: "Category", value: func(a ProcessedAsset) string {
+			return orNotAvailable(a.Category)
+		}})
+	}
+
+	if cfg.ShowDisposition {
+		columns = append(columns, column{header: "Disposition", value: func(a ProcessedAsset) string {
+			return orNotAvailable(a.Disposition)
+		}})
+	}
+
+	if cfg.PartnerCIDRs != "" {
+		columns = append(columns, column{header: "Partners", value: func(a ProcessedAsset) string {
+			return strings.Join(a.PartnerPeers, ",")
+		}})
+	}
+
+	if cfg.ShowLastTraffic {
+		columns = append(columns,
+			column{header: "Last Traffic", value: formatLastTraffic},
+			column{header: "Traffic Bytes", value: func(a ProcessedAsset) string {
+				return strconv.FormatInt(a.TrafficBytes, 10)
+			}},
+		)
+	}
+
+	if cfg.GeoIPDatabase != "" {
+		columns = append(columns,
+			column{header: "Country", value: func(a ProcessedAsset) string { return orNotAvailable(a.Country) }},
+			column{header: "Region", value: func(a ProcessedAsset) string { return orNotAvailable(a.Region) }},
+		)
+	}
+
+	if cfg.FirewallExposure {
+		columns = append(columns, column{header: "Exposed Ports", value: func(a ProcessedAsset) string {
+			return strings.Join(a.ExposedPorts, ",")
+		}})
+	}
+
+	if cfg.DNSBLZones != "" || cfg.AbuseIPDBKey != "" {
+		columns = append(columns, column{header: "Blocklists", value: func(a ProcessedAsset) string {
+			return strings.Join(a.Blocklists, ",")
+		}})
+	}
+
+	if cfg.BYOIPRanges != "" {
+		columns = append(columns, column{header: "BYOIP", value: func(a ProcessedAsset) string {
+			return strconv.FormatBool(a.BYOIP)
+		}})
+	}
+
+	if cfg.ApprovedRangesFile != "" {
+		columns = append(columns, column{header: "Compliance", value: func(a ProcessedAsset) string {
+			return orNotAvailable(a.Compliance)
+		}})
+	}
+
+	return columns
+}
+
+func orNotAvailable(s string) string {
+	if s == "" {
+		return "N/A"
+	}
+
+	return s
+}
+
+// formatLastTraffic returns the last time traffic was observed for in-use addresses,
+// or "none" if the address is in use but no traffic was observed within the lookback window.
+func formatLastTraffic(asset ProcessedAsset) string {
+	switch {
+	case asset.LastTrafficSeen != "":
+		return asset.LastTrafficSeen
+	case asset.Status == addressStatusInUse:
+		return "none"
+	default:
+		return "N/A"
+	}
+}
+
+func outputAssetTable(ctx context.Context, logger *slog.Logger, w io.Writer, assets []ProcessedAsset, columns []column) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+
+	headers := make([]string, 0, len(columns))
+	separators := make([]string, 0, len(columns))
+
+	for _, c := range columns {
+		headers = append(headers, c.header)
+		separators = append(separators, strings.Repeat("-", len(c.header)))
+	}
+
+	_, _ = fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	_, _ = fmt.Fprintln(tw, strings.Join(separators, "\t"))
+
+	for _, asset := range assets {
+		values := make([]string, 0, len(columns))
+		for _, c := range columns {
+			values = append(values, c.value(asset))
+		}
+
+		_, _ = fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputCostSummaryTable(ctx context.Context, logger *slog.Logger, w io.Writer, summary CostSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Project ID\tIdle Addresses\tMonthly Cost")
+	_, _ = fmt.Fprintln(tw, "----------\t--------------\t------------")
+
+	for _, project := range summary.Projects {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", project.Project, project.IdleAddresses, formatCost(project.MonthlyCost))
+	}
+
+	_, _ = fmt.Fprintf(tw, "Total\t%d\t%s\n", summary.IdleAddresses, formatCost(summary.MonthlyCost))
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputRecommendationSummaryTable(ctx context.Context, logger *slog.Logger, w io.Writer, summary RecommendationSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Idle According To\tAddresses")
+	_, _ = fmt.Fprintln(tw, "-----------------\t---------")
+	_, _ = fmt.Fprintf(tw, "%s\t%d\n", agreementBoth, summary.Both)
+	_, _ = fmt.Fprintf(tw, "%s\t%d\n", agreementRecommender, summary.RecommenderOnly)
+	_, _ = fmt.Fprintf(tw, "%s\t%d\n", agreementAssetWatcher, summary.AssetWatcherOnly)
+	_, _ = fmt.Fprintf(tw, "Recommended savings\t%s\n", formatCost(summary.RecommendedMonthlySavings))
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputCategorySummaryTable(ctx context.Context, logger *slog.Logger, w io.Writer, categories map[string]int) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Category\tAssets")
+	_, _ = fmt.Fprintln(tw, "--------\t------")
+
+	for _, category := range slices.Sorted(maps.Keys(categories)) {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\n", category, categories[category])
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputGroupSummaryTable(ctx context.Context, logger *slog.Logger, w io.Writer, summary GroupSummary) {
+	withCost := len(summary.Groups) > 0 && summary.Groups[0].MonthlyCost != nil
+
+	header := []string{groupByTitle(summary.By), "Assets"}
+	if withCost {
+		header = append(header, "Monthly Cost")
+	}
+
+	separator := make([]string, len(header))
+	for i, h := range header {
+		separator[i] = strings.Repeat("-", len(h))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+	_, _ = fmt.Fprintln(tw, strings.Join(separator, "\t"))
+
+	total := 0
+	totalCost := 0.0
+
+	for _, group := range summary.Groups {
+		total += group.Assets
+		row := []string{group.Key, strconv.Itoa(group.Assets)}
+
+		if withCost {
+			totalCost += *group.MonthlyCost
+			row = append(row, formatCost(*group.MonthlyCost))
+		}
+
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	row := []string{"Total", strconv.Itoa(total)}
+	if withCost {
+		row = append(row, formatCost(totalCost))
+	}
+
+	_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+
+	err := tw.Flush()
 	if err != nil {
 		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
-		os.Exit(1)
+		exit(ctx, 1)
 	}
 }
 
-func outputToStdOutJSON(ctx context.Context, logger *slog.Logger, processedAssets []ProcessedAsset) {
-	jsonData, err := json.MarshalIndent(processedAssets, "", "  ")
+func outputCoverageSummaryTable(ctx context.Context, logger *slog.Logger, w io.Writer, coverage CoverageSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Scanned Projects\tTotal Projects\tCoverage")
+	_, _ = fmt.Fprintln(tw, "----------------\t--------------\t--------")
+	_, _ = fmt.Fprintf(tw, "%d\t%d\t%.1f%%\n", coverage.ScannedProjects, coverage.TotalProjects, coverage.Percent)
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputBaselineTable(ctx context.Context, logger *slog.Logger, w io.Writer, baseline BaselineSummary, diffs []AssetDiff) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Baseline Assets\tNew Assets\tDisappeared Assets")
+	_, _ = fmt.Fprintln(tw, "---------------\t----------\t------------------")
+	_, _ = fmt.Fprintf(tw, "%d\t%d\t%d\n", baseline.BaselineAssets, baseline.NewAssets, baseline.DisappearedAssets)
+
+	if baseline.DisappearedAssets > 0 {
+		_, _ = fmt.Fprintln(tw)
+		_, _ = fmt.Fprintln(tw, "Disappeared Asset\tIP Address\tProject ID")
+		_, _ = fmt.Fprintln(tw, "-----------------\t----------\t----------")
+
+		for _, diff := range diffs {
+			if diff.Type == DiffRemoved {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", diff.Asset.Name, diff.Asset.IPAddress, diff.Asset.Project)
+			}
+		}
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputUnscannableProjectsTable(ctx context.Context, logger *slog.Logger, w io.Writer, projects []ProjectError) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Unscannable Project ID\tReason\tError")
+	_, _ = fmt.Fprintln(tw, "----------------------\t------\t-----")
+
+	for _, project := range projects {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", project.Project, project.Reason, project.Error)
+	}
+
+	err := tw.Flush()
 	if err != nil {
-		logger.ErrorContext(ctx, "failed to marshal JSON: %v", slog.Any("error", err))
-		os.Exit(1)
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputDNSReconciliationTable(ctx context.Context, logger *slog.Logger, w io.Writer, reconciliation DNSReconciliation) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Dangling DNS Record\tType\tIP Address\tZone")
+	_, _ = fmt.Fprintln(tw, "-------------------\t----\t----------\t----")
+
+	for _, record := range reconciliation.DanglingRecords {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", record.Name, record.Type, record.Address, record.Zone)
+	}
+
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "IP Address Without DNS Record\tDisplay Name\tProject ID")
+	_, _ = fmt.Fprintln(tw, "-----------------------------\t------------\t----------")
+
+	for _, address := range reconciliation.UnmappedAddresses {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", address.Address, address.Asset, address.Project)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputPrefixGroupsTable(ctx context.Context, logger *slog.Logger, w io.Writer, groups []PrefixGroup) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Announced Prefix\tASN\tHolder\tAddresses\tIP Addresses")
+	_, _ = fmt.Fprintln(tw, "----------------\t---\t------\t---------\t------------")
+
+	for _, group := range groups {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", orNotAvailable(group.Prefix), orNotAvailable(group.ASN),
+			orNotAvailable(group.Holder), len(group.Addresses), strings.Join(group.Addresses, ","))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputRDAPFindingsTable(ctx context.Context, logger *slog.Logger, w io.Writer, findings []RDAPFinding) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Registered Range\tHandle\tName\tAllocated Addresses\tIssues")
+	_, _ = fmt.Fprintln(tw, "----------------\t------\t----\t-------------------\t------")
+
+	for _, finding := range findings {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", finding.Range, orNotAvailable(finding.Handle),
+			orNotAvailable(finding.Name), finding.AllocatedAddresses, strings.Join(finding.Issues, "; "))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputTerraformDriftTable(ctx context.Context, logger *slog.Logger, w io.Writer, drift *TerraformDrift) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Terraform Drift\tName\tProject\tLocation\tIP Address\tTerraform Address")
+	_, _ = fmt.Fprintln(tw, "---------------\t----\t-------\t--------\t----------\t-----------------")
+
+	for _, asset := range drift.Unmanaged {
+		_, _ = fmt.Fprintf(tw, "unmanaged\t%s\t%s\t%s\t%s\tN/A\n", asset.Name, asset.Project, asset.Location,
+			orNotAvailable(asset.IPAddress))
 	}
 
-	fmt.Println(string(jsonData))
+	for _, address := range drift.Missing {
+		_, _ = fmt.Fprintf(tw, "missing\t%s\t%s\t%s\t%s\t%s\n", address.Name, address.Project, address.Location,
+			orNotAvailable(address.IPAddress), address.Address)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func formatCost(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+func outputGeofeed(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, cfg *Config) {
+	// The region mapping is validated by GetConfig.
+	regions, _ := loadGeofeedRegions(cfg.GeofeedRegions)
+
+	if err := writeGeofeed(w, report.Assets, regions); err != nil {
+		logger.ErrorContext(ctx, "failed to write geofeed", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputTerraform(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report) {
+	if err := writeTerraformImports(w, terraformImportAddresses(report)); err != nil {
+		logger.ErrorContext(ctx, "failed to write Terraform configuration", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputXLSX(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, cfg *Config) {
+	if err := writeXLSX(w, report, cfg); err != nil {
+		logger.ErrorContext(ctx, "failed to write workbook", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+func outputJSON(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report) {
+	if err := report.WriteJSON(w); err != nil {
+		logger.ErrorContext(ctx, "failed to marshal JSON", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+// openOutput returns the destination of the output: stdout, or the local file or the
+// gs://BUCKET/OBJECT of ASSET_WATCHER_OUTPUT_PATH. The output is complete once the writer
+// is closed.
+func openOutput(ctx context.Context, logger *slog.Logger, cfg *Config) (io.WriteCloser, error) {
+	if cfg.OutputPath == "" {
+		return nopWriteCloser{Writer: os.Stdout}, nil
+	}
+
+	if strings.HasPrefix(cfg.OutputPath, gcsScheme) {
+		bucket, object, _ := strings.Cut(strings.TrimPrefix(cfg.OutputPath, gcsScheme), "/")
+
+		return newGCSObjectWriter(ctx, bucket, object, outputContentType(cfg),
+			clientOptionsFor(ctx, logger, cfg, credentialsStorage)...)
+	}
+
+	f, err := os.Create(cfg.OutputPath) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	return f, nil
+}
+
+// writeOutput writes the output to its destination, exiting if it cannot be written.
+func writeOutput(ctx context.Context, logger *slog.Logger, cfg *Config, write func(w io.Writer)) {
+	// The output of a canceled run is still completed, so that its partial output is flushed.
+	out, err := openOutput(context.WithoutCancel(ctx), logger, cfg)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to open the output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	write(out)
+
+	if err := out.Close(); err != nil {
+		logger.ErrorContext(ctx, "failed to write the output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+// outputContentType returns the media type of the output format.
+func outputContentType(cfg *Config) string {
+	if cfg.OutputTemplate != "" {
+		return "text/plain"
+	}
+
+	switch cfg.OutputFormat {
+	case outputFormatJSON:
+		return "application/json"
+	case outputFormatSARIF:
+		return "application/sarif+json"
+	case outputFormatJUnit:
+		return "application/xml"
+	case outputFormatNDJSON:
+		return "application/x-ndjson"
+	case outputFormatGeofeed:
+		return "text/csv"
+	case outputFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/plain"
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// gcsObjectWriter streams the writes to a Cloud Storage object, so that streamed outputs are
+// not held in memory. The object is created once the writer is closed.
+type gcsObjectWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newGCSObjectWriter(
+	ctx context.Context,
+	bucket, object, contentType string,
+	opts ...option.ClientOption,
+) (*gcsObjectWriter, error) {
+	s, err := storage.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	w := &gcsObjectWriter{pw: pw, done: make(chan error, 1)}
+
+	go func() {
+		_, err := s.Objects.Insert(bucket, &storage.Object{Name: object, ContentType: contentType}).
+			Media(pr, googleapi.ContentType(contentType)).Context(ctx).Do()
+		if err != nil {
+			err = fmt.Errorf("failed to upload gs://%s/%s: %w", bucket, object, err)
+		}
+
+		// Unblock the writes if the upload failed before reading all of them.
+		pr.CloseWithError(cmp.Or(err, io.ErrClosedPipe))
+		w.done <- err
+	}()
+
+	return w, nil
+}
+
+func (w *gcsObjectWriter) Write(p []byte) (int, error) {
+	n, err := w.pw.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return n, nil
+}
+
+// Close completes the upload and returns its error.
+func (w *gcsObjectWriter) Close() error {
+	_ = w.pw.Close()
+
+	return <-w.done
 }
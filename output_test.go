@@ -1,13 +1,20 @@
-package main
+package assetwatcher
 
 import (
 	"bytes"
 	"encoding/json"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"google.golang.org/api/option"
 )
 
 // captureStdout is a helper function to capture standard output.
@@ -45,8 +52,8 @@ func captureStdout(t *testing.T, fn func()) string {
 	return buf.String()
 }
 
-// TestOutputToStdOutTable tests the outputToStdOutTable function.
-func TestOutputToStdOutTable(t *testing.T) {
+// TestOutputTable tests the outputTable function.
+func TestOutputTable(t *testing.T) {
 	logger := slog.New(slog.DiscardHandler)
 	ctx := t.Context()
 
@@ -60,7 +67,7 @@ func TestOutputToStdOutTable(t *testing.T) {
 
 	t.Run("No assets", func(t *testing.T) {
 		output := captureStdout(t, func() {
-			outputToStdOutTable(ctx, logger, []ProcessedAsset{})
+			outputTable(ctx, logger, os.Stdout, &Report{Assets: []ProcessedAsset{}}, &Config{})
 		})
 
 		// Check for header keywords
@@ -84,7 +91,7 @@ func TestOutputToStdOutTable(t *testing.T) {
 
 	t.Run("With assets", func(t *testing.T) {
 		output := captureStdout(t, func() {
-			outputToStdOutTable(ctx, logger, sampleAssets)
+			outputTable(ctx, logger, os.Stdout, &Report{Assets: sampleAssets}, &Config{})
 		})
 
 		// Check for header keywords
@@ -123,8 +130,71 @@ func TestOutputToStdOutTable(t *testing.T) {
 	})
 }
 
-// TestOutputToStdOutJSON tests the outputToStdOutJSON function.
-func TestOutputToStdOutJSON(t *testing.T) {
+// TestOutputTable_WithCost tests the cost column and the cost summary of the table output.
+func TestOutputTable_WithCost(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	ctx := t.Context()
+
+	sampleAssets := []ProcessedAsset{
+		{Name: "Asset1", Location: "loc1", Project: "proj1", IPAddress: "1.1.1.1", Status: "IN_USE", CreatedAt: "2023-01-01"},
+		{Name: "Asset2", Location: "loc2", Project: "proj2", IPAddress: "2.2.2.2", Status: "RESERVED", CreatedAt: "2023-01-02", EstimatedMonthlyCost: 7.3},
+	}
+
+	output := captureStdout(t, func() {
+		cfg := &Config{ShowCost: true}
+		outputTable(ctx, logger, os.Stdout, NewReport(t.Context(), cfg, time.Now(), sampleAssets), cfg)
+	})
+
+	for _, keyword := range []string{"Monthly Cost", "Idle Addresses", "$7.30", "$0.00", "Total"} {
+		if !strings.Contains(output, keyword) {
+			t.Errorf("keyword '%s' not found in table output with cost. Output:\n%s", keyword, output)
+		}
+	}
+}
+
+// TestOutputTable_WithGroups tests the group summary of the table output.
+func TestOutputTable_WithGroups(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	ctx := t.Context()
+
+	sampleAssets := []ProcessedAsset{
+		{Name: "Asset1", Location: "loc1", Project: "proj1", Status: "IN_USE"},
+		{Name: "Asset2", Location: "loc1", Project: "proj2", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+	}
+
+	output := captureStdout(t, func() {
+		cfg := &Config{GroupBy: groupByLocation, ShowCost: true}
+		outputTable(ctx, logger, os.Stdout, NewReport(t.Context(), cfg, time.Now(), sampleAssets), cfg)
+	})
+
+	if !regexp.MustCompile(`loc1\s+\|\s*2\s+\|\s*\$7\.30`).MatchString(output) {
+		t.Errorf("group row not found in table output. Output:\n%s", output)
+	}
+}
+
+// TestOutputTable_MultipleAssetTypes tests that each asset type is rendered with its own columns.
+func TestOutputTable_MultipleAssetTypes(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	ctx := t.Context()
+
+	sampleAssets := []ProcessedAsset{
+		{Name: "Address1", AssetType: addressAssetType, IPAddress: "1.1.1.1", Attributes: map[string]string{"networkTier": "PREMIUM"}},
+		{Name: "Instance1", AssetType: instanceAssetType, Attributes: map[string]string{"machineType": "e2-small", "zone": "us-central1-a"}},
+	}
+
+	output := captureStdout(t, func() {
+		outputTable(ctx, logger, os.Stdout, &Report{Assets: sampleAssets}, &Config{})
+	})
+
+	for _, keyword := range []string{addressAssetType, instanceAssetType, "Network Tier", "PREMIUM", "Machine Type", "e2-small", "Zone"} {
+		if !strings.Contains(output, keyword) {
+			t.Errorf("keyword '%s' not found in table output with multiple asset types. Output:\n%s", keyword, output)
+		}
+	}
+}
+
+// TestOutputJSON tests the outputJSON function.
+func TestOutputJSON(t *testing.T) {
 	logger := slog.New(slog.DiscardHandler)
 	ctx := t.Context()
 
@@ -135,33 +205,35 @@ func TestOutputToStdOutJSON(t *testing.T) {
 
 	t.Run("No assets", func(t *testing.T) {
 		output := captureStdout(t, func() {
-			outputToStdOutJSON(ctx, logger, []ProcessedAsset{})
+			outputJSON(ctx, logger, os.Stdout, NewReport(t.Context(), &Config{}, time.Now(), nil))
 		})
 
-		var unmarshalledOutput []ProcessedAsset
+		var unmarshalledOutput Report
 
 		err := json.Unmarshal([]byte(output), &unmarshalledOutput)
 		if err != nil {
 			t.Fatalf("output with no assets is not valid JSON: %v\nOutput was: %s", err, output)
 		}
 
-		if len(unmarshalledOutput) != 0 {
-			t.Errorf("expected empty JSON array, got %d elements", len(unmarshalledOutput))
+		if unmarshalledOutput.Assets == nil || len(unmarshalledOutput.Assets) != 0 {
+			t.Errorf("expected empty JSON array of assets, got %v", unmarshalledOutput.Assets)
 		}
 	})
 
 	t.Run("With assets", func(t *testing.T) {
 		output := captureStdout(t, func() {
-			outputToStdOutJSON(ctx, logger, sampleAssets)
+			outputJSON(ctx, logger, os.Stdout, NewReport(t.Context(), &Config{}, time.Now(), sampleAssets))
 		})
 
-		var processedOutput []ProcessedAsset
+		var report Report
 
-		err := json.Unmarshal([]byte(output), &processedOutput)
+		err := json.Unmarshal([]byte(output), &report)
 		if err != nil {
 			t.Fatalf("output with assets is not valid JSON: %v\nOutput was: %s", err, output)
 		}
 
+		processedOutput := report.Assets
+
 		if len(processedOutput) != len(sampleAssets) {
 			t.Errorf("expected %d assets in JSON output, got %d", len(sampleAssets), len(processedOutput))
 		}
@@ -197,3 +269,116 @@ func TestOutputToStdOutJSON(t *testing.T) {
 		}
 	})
 }
+
+func TestOpenOutput_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	cfg := &Config{OutputFormat: "json", OutputPath: path}
+
+	out, err := openOutput(t.Context(), slog.New(slog.DiscardHandler), cfg)
+	if err != nil {
+		t.Fatalf("openOutput() error = %v", err)
+	}
+
+	outputReport(t.Context(), slog.New(slog.DiscardHandler), out, &Report{Assets: []ProcessedAsset{{Name: "nat-1"}}}, cfg)
+
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+
+	report, err := ReadReport(bytes.NewReader(data))
+	if err != nil || len(report.Assets) != 1 || report.Assets[0].Name != "nat-1" {
+		t.Errorf("output = %s, want the JSON report", data)
+	}
+}
+
+func TestGCSObjectWriter(t *testing.T) {
+	var (
+		uploaded    string
+		contentType string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/upload/storage/v1/b/reports/o" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		uploaded = string(body)
+		contentType = r.Header.Get("Content-Type")
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "runs/assets.ndjson"}`))
+	}))
+	defer server.Close()
+
+	out, err := newGCSObjectWriter(t.Context(), "reports", "runs/assets.ndjson", "application/x-ndjson",
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("newGCSObjectWriter() error = %v", err)
+	}
+
+	for _, line := range []string{`{"name":"nat-1"}` + "\n", `{"name":"nat-2"}` + "\n"} {
+		if _, err := io.WriteString(out, line); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if !strings.Contains(uploaded, `{"name":"nat-1"}`+"\n"+`{"name":"nat-2"}`) || !strings.Contains(uploaded, "runs/assets.ndjson") {
+		t.Errorf("uploaded = %q, want the object metadata and the written lines", uploaded)
+	}
+
+	if !strings.HasPrefix(contentType, "multipart/related") {
+		t.Errorf("Content-Type = %q, want a multipart upload", contentType)
+	}
+}
+
+func TestGCSObjectWriter_UploadFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"code": 403, "message": "forbidden"}}`))
+	}))
+	defer server.Close()
+
+	out, err := newGCSObjectWriter(t.Context(), "reports", "report.json", "application/json",
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("newGCSObjectWriter() error = %v", err)
+	}
+
+	_, _ = io.WriteString(out, "{}")
+
+	if err := out.Close(); err == nil {
+		t.Error("Close() error = nil, want the upload error")
+	}
+}
+
+func TestOutputReport_UnknownFormat(t *testing.T) {
+	ctx := withEmbeddedRun(withRunProgress(t.Context()))
+
+	var buf bytes.Buffer
+
+	defer func() {
+		if exited, ok := recover().(embeddedExit); !ok || exited.code != 1 {
+			t.Errorf("recover() = %v, want embeddedExit with code 1", exited)
+		}
+
+		if buf.Len() > 0 {
+			t.Errorf("unexpected output %q for an unknown format", buf.String())
+		}
+	}()
+
+	outputReport(ctx, slog.New(slog.DiscardHandler), &buf, &Report{}, &Config{OutputFormat: "yaml"})
+	t.Fatal("outputReport() returned for an unknown format")
+}
@@ -0,0 +1,153 @@
+package assetwatcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+)
+
+const (
+	// pagerDutyEventsURL is the endpoint of the PagerDuty Events API v2.
+	// https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// Limits of the summary and the deduplication key of PagerDuty events.
+	pagerDutyMaxSummary  = 1024
+	pagerDutyMaxDedupKey = 255
+)
+
+// pagerDutyRoutingKeyPattern matches the 32 characters integration keys of PagerDuty services.
+var pagerDutyRoutingKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]{32}$`)
+
+var errPagerDutyFailed = errors.New("failed to trigger PagerDuty events")
+
+// pagerDutyEvent is a trigger event of the Events API v2.
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+	Links       []pagerDutyLink  `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// PagerDutyNotifier triggers a PagerDuty incident for every high-severity policy violation.
+// Events are deduplicated by rule and asset, so a violation reported by every run updates its
+// open incident instead of paging again.
+type PagerDutyNotifier struct {
+	client     *http.Client
+	endpoint   string
+	routingKey string
+	logger     *slog.Logger
+}
+
+// NewPagerDutyNotifier creates a new PagerDuty notifier for the service of the configured
+// integration key.
+func NewPagerDutyNotifier(logger *slog.Logger, cfg *Config, client *http.Client) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		client:     client,
+		endpoint:   pagerDutyEventsURL,
+		routingKey: cfg.PagerDutyRoutingKey,
+		logger:     logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the notifier.
+func (n *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+// Notify triggers an event for every high-severity violation of the report of the
+// notification. Notifications without a report or without such violations page no one.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Report == nil {
+		return nil
+	}
+
+	errs := []error{}
+	triggered := 0
+
+	for _, v := range notification.Report.Violations {
+		if v.Severity != severityHigh {
+			continue
+		}
+
+		event := newPagerDutyEvent(n.routingKey, notification, v)
+		if _, err := postJSON(ctx, n.client, n.endpoint, nil, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", event.DedupKey, err))
+
+			continue
+		}
+
+		triggered++
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", errPagerDutyFailed, errors.Join(errs...))
+	}
+
+	n.logger.DebugContext(ctx, "Triggered PagerDuty events", slog.Int("events", triggered))
+
+	return nil
+}
+
+// newPagerDutyEvent returns the trigger event of the violation.
+func newPagerDutyEvent(routingKey string, notification Notification, v RuleViolation) pagerDutyEvent {
+	event := pagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		DedupKey:    pagerDutyDedupKey(v),
+		Payload: pagerDutyPayload{
+			Summary:   truncateString(fmt.Sprintf("%s: %s (%s)", v.Rule, v.Message, v.Asset.Project), pagerDutyMaxSummary),
+			Source:    v.Asset.IPAddress,
+			Severity:  "critical",
+			Component: v.Asset.Name,
+			Group:     v.Asset.Project,
+			Class:     v.Rule,
+			CustomDetails: map[string]string{
+				"resource": v.Asset.ResourceName,
+				"location": v.Asset.Location,
+				"status":   v.Asset.Status,
+				"runId":    notification.RunID,
+			},
+		},
+	}
+
+	if notification.ArtifactURL != "" {
+		event.Links = []pagerDutyLink{{Href: notification.ArtifactURL, Text: "Full report"}}
+	}
+
+	return event
+}
+
+// pagerDutyDedupKey returns the deduplication key of the violation, the rule and the asset,
+// hashed if it does not fit in the limit of PagerDuty.
+func pagerDutyDedupKey(v RuleViolation) string {
+	key := "asset-watcher/" + v.Rule + "/" + assetKey(v.Asset)
+	if len(key) <= pagerDutyMaxDedupKey {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return "asset-watcher/" + v.Rule + "/" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPagerDutyNotifier_Notify(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []pagerDutyEvent
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, event)
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success","message":"Event processed","dedup_key":"` + event.DedupKey + `"}`))
+	}))
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier(slog.New(slog.DiscardHandler),
+		&Config{PagerDutyRoutingKey: "0123456789abcdef0123456789abcdef"}, server.Client())
+	notifier.endpoint = server.URL
+
+	report := &Report{Violations: []RuleViolation{
+		{
+			Rule: ruleOutOfBandAddress, Severity: severityHigh, Message: "address outside of the approved ranges",
+			Asset: ProcessedAsset{Name: "nat", IPAddress: "203.0.113.10", Project: "prod", ResourceName: "//compute/nat"},
+		},
+		{Rule: ruleOrphanedExternalAddress, Severity: severityMedium, Asset: ProcessedAsset{Name: "spare"}},
+	}}
+
+	notification := newNotification(report, "https://example.com/report.json")
+	if err := notifier.Notify(t.Context(), notification); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	// A repeated violation is sent with the same deduplication key.
+	if err := notifier.Notify(t.Context(), notification); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected an event per run for the high-severity violation only, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.DedupKey != "asset-watcher/"+ruleOutOfBandAddress+"///compute/nat" || events[1].DedupKey != event.DedupKey {
+		t.Errorf("unexpected deduplication keys %q and %q", event.DedupKey, events[1].DedupKey)
+	}
+
+	if event.EventAction != "trigger" || event.Payload.Severity != "critical" || event.Payload.Group != "prod" ||
+		!strings.Contains(event.Payload.Summary, "approved ranges") || len(event.Links) != 1 {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// Notifications without a report page no one.
+	if err := notifier.Notify(t.Context(), Notification{Title: "title"}); err != nil || len(events) != 2 {
+		t.Errorf("expected no event without a report, got %v and %d events", err, len(events))
+	}
+}
+
+func TestPagerDutyNotifier_NotifyFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"status":"invalid event","message":"Event object is invalid"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier(slog.New(slog.DiscardHandler), &Config{}, server.Client())
+	notifier.endpoint = server.URL
+
+	report := &Report{Violations: []RuleViolation{{Rule: ruleInstanceExternalIP, Severity: severityHigh}}}
+
+	err := notifier.Notify(t.Context(), newNotification(report, ""))
+	if !errors.Is(err, errPagerDutyFailed) || !strings.Contains(err.Error(), "Event object is invalid") {
+		t.Errorf("expected a failed event, got %v", err)
+	}
+}
+
+func TestPagerDutyDedupKey(t *testing.T) {
+	key := pagerDutyDedupKey(RuleViolation{Rule: ruleInstanceExternalIP, Asset: ProcessedAsset{ResourceName: strings.Repeat("x", 300)}})
+	if len(key) > pagerDutyMaxDedupKey || !strings.HasPrefix(key, "asset-watcher/"+ruleInstanceExternalIP+"/") {
+		t.Errorf("unexpected deduplication key %q", key)
+	}
+}
@@ -0,0 +1,108 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+var errInvalidPartnerRange = errors.New("invalid partner range")
+
+// partnerRange is a CIDR range owned by an egress partner.
+type partnerRange struct {
+	partner string
+	prefix  netip.Prefix
+}
+
+// parsePartnerRanges parses a comma-separated list of partner=CIDR pairs.
+// A partner may own several ranges, in which case it is listed once per range.
+func parsePartnerRanges(s string) ([]partnerRange, error) {
+	ranges := []partnerRange{}
+
+	for _, pair := range splitString(s, ",") {
+		partner, cidr, ok := strings.Cut(pair, "=")
+		partner = strings.TrimSpace(partner)
+
+		if !ok || partner == "" {
+			return nil, fmt.Errorf("%w: %q, expected partner=CIDR", errInvalidPartnerRange, pair)
+		}
+
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", errInvalidPartnerRange, pair, err)
+		}
+
+		ranges = append(ranges, partnerRange{partner: partner, prefix: prefix.Masked()})
+	}
+
+	return ranges, nil
+}
+
+// matchPartner returns the partner owning the IP address, if any.
+func matchPartner(ranges []partnerRange, ip string) (string, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", false
+	}
+
+	for _, r := range ranges {
+		if r.prefix.Contains(addr.Unmap()) {
+			return r.partner, true
+		}
+	}
+
+	return "", false
+}
+
+// annotatePartnerPeers annotates each asset with the partners its address communicated
+// with according to VPC Flow Logs.
+func annotatePartnerPeers(
+	ctx context.Context,
+	logger *slog.Logger,
+	client FlowLogsClient,
+	cfg *Config,
+	assets []ProcessedAsset,
+	now time.Time,
+) ([]ProcessedAsset, error) {
+	ranges, err := parsePartnerRanges(cfg.PartnerCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := assetAddresses(assets)
+	if len(ranges) == 0 || len(addresses) == 0 {
+		return assets, nil
+	}
+
+	peers, err := client.QueryPeers(ctx, addresses, flowLogsSince(now, cfg.FlowLogsLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+
+	partnersByAddress := make(map[string][]string)
+
+	for _, peer := range peers {
+		partner, ok := matchPartner(ranges, peer.Peer)
+		if !ok || slices.Contains(partnersByAddress[peer.Address], partner) {
+			continue
+		}
+
+		partnersByAddress[peer.Address] = append(partnersByAddress[peer.Address], partner)
+	}
+
+	for i := range assets {
+		if partners, ok := partnersByAddress[assets[i].IPAddress]; ok {
+			slices.Sort(partners)
+			assets[i].PartnerPeers = partners
+		}
+	}
+
+	logger.DebugContext(ctx, "Annotated partner peers", slog.Int("number_of_addresses", len(partnersByAddress)))
+
+	return assets, nil
+}
@@ -0,0 +1,105 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePartnerRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty string", s: "", want: 0},
+		{name: "multiple ranges of a partner", s: "acme=203.0.113.0/24, acme=2001:db8::/32, globex=198.51.100.7/24", want: 3},
+		{name: "missing partner", s: "203.0.113.0/24", wantErr: true},
+		{name: "invalid CIDR", s: "acme=203.0.113.0/33", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePartnerRanges(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePartnerRanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got) != tt.want {
+				t.Errorf("parsePartnerRanges() returned %d ranges, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPartner(t *testing.T) {
+	ranges, err := parsePartnerRanges("acme=203.0.113.0/24,globex=2001:db8::/32")
+	if err != nil {
+		t.Fatalf("parsePartnerRanges failed: %v", err)
+	}
+
+	tests := []struct {
+		ip      string
+		partner string
+		ok      bool
+	}{
+		{ip: "203.0.113.10", partner: "acme", ok: true},
+		{ip: "::ffff:203.0.113.10", partner: "acme", ok: true},
+		{ip: "2001:db8::1", partner: "globex", ok: true},
+		{ip: "198.51.100.1", ok: false},
+		{ip: "not-an-ip", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			partner, ok := matchPartner(ranges, tt.ip)
+			if partner != tt.partner || ok != tt.ok {
+				t.Errorf("matchPartner() = (%v, %v), want (%v, %v)", partner, ok, tt.partner, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAnnotatePartnerPeers(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	client := &fakeFlowLogsClient{peers: []FlowPeer{
+		{Address: "34.1.1.1", Peer: "203.0.113.10"},
+		{Address: "34.1.1.1", Peer: "203.0.113.11"},
+		{Address: "34.1.1.1", Peer: "198.51.100.1"},
+		{Address: "34.1.1.1", Peer: "8.8.8.8"},
+		{Address: "34.2.2.2", Peer: "8.8.8.8"},
+	}}
+
+	cfg := &Config{PartnerCIDRs: "globex=198.51.100.0/24,acme=203.0.113.0/24", FlowLogsLookbackDays: 7}
+	assets := []ProcessedAsset{
+		{Name: "a1", IPAddress: "34.1.1.1"},
+		{Name: "a2", IPAddress: "34.2.2.2"},
+		{Name: "a3", IPAddress: "N/A"},
+	}
+
+	got, err := annotatePartnerPeers(ctx, logger, client, cfg, assets, now)
+	if err != nil {
+		t.Fatalf("annotatePartnerPeers failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(client.addresses, []string{"34.1.1.1", "34.2.2.2"}) {
+		t.Errorf("queried addresses = %v", client.addresses)
+	}
+
+	if !client.since.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("queried since = %v, want %v", client.since, now.AddDate(0, 0, -7))
+	}
+
+	if !reflect.DeepEqual(got[0].PartnerPeers, []string{"acme", "globex"}) {
+		t.Errorf("a1 PartnerPeers = %v, want [acme globex]", got[0].PartnerPeers)
+	}
+
+	if got[1].PartnerPeers != nil || got[2].PartnerPeers != nil {
+		t.Errorf("expected no partners for a2 and a3, got %v and %v", got[1].PartnerPeers, got[2].PartnerPeers)
+	}
+}
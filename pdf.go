@@ -0,0 +1,114 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Layout of the PDF documents: A4 pages of 10 pt Courier text.
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 10
+	pdfLineHeight   = 13
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+	pdfLineLength   = 82
+)
+
+// writeTextPDF writes a minimal PDF document of plain text lines. Long lines are wrapped,
+// and the text flows over as many pages as needed.
+func writeTextPDF(w io.Writer, lines []string) error {
+	wrapped := []string{}
+	for _, line := range lines {
+		for len(line) > pdfLineLength {
+			wrapped = append(wrapped, line[:pdfLineLength])
+			line = line[pdfLineLength:]
+		}
+
+		wrapped = append(wrapped, line)
+	}
+
+	pages := [][]string{}
+	for len(wrapped) > pdfLinesPerPage {
+		pages = append(pages, wrapped[:pdfLinesPerPage])
+		wrapped = wrapped[pdfLinesPerPage:]
+	}
+
+	pages = append(pages, wrapped)
+
+	// Objects 1 to 3 are the catalog, the page tree, and the font, followed by a page
+	// and a content stream object for every page.
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>"}
+	kids := make([]string, 0, len(pages))
+
+	for i, page := range pages {
+		pageObject, contentObject := 4+2*i, 5+2*i
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+
+		var content strings.Builder
+
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escapePDFString(line))
+		}
+
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> "+
+				"/Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, contentObject),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+
+	return nil
+}
+
+// escapePDFString escapes the text for a PDF literal string, replacing characters
+// outside of printable ASCII, which the standard fonts cannot render.
+func escapePDFString(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,269 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	// prefixSourceRIPEstat resolves the announced prefixes with the RIPEstat Data API.
+	prefixSourceRIPEstat = "ripestat"
+
+	ripestatEndpoint = "https://stat.ripe.net/data"
+)
+
+var (
+	errInvalidPrefixTable = errors.New("invalid prefix table")
+	errRIPEstatFailed     = errors.New("RIPEstat query failed")
+)
+
+// AnnouncedPrefix is the BGP prefix announcing an address and its origin AS.
+type AnnouncedPrefix struct {
+	Prefix string `json:"prefix"`
+	ASN    string `json:"asn"`
+	Holder string `json:"holder,omitempty"`
+}
+
+// PrefixGroup represents the external addresses announced in the same prefix.
+type PrefixGroup struct {
+	AnnouncedPrefix
+
+	Addresses []string `json:"addresses"`
+}
+
+// PrefixResolver is an interface for resolving the announced prefix of an address.
+type PrefixResolver interface {
+	Resolve(ctx context.Context, addr netip.Addr) (AnnouncedPrefix, bool, error)
+}
+
+// PrefixTable resolves the announced prefixes from a lookup table.
+type PrefixTable struct {
+	prefixes []tablePrefix
+}
+
+type tablePrefix struct {
+	prefix netip.Prefix
+	AnnouncedPrefix
+}
+
+// LoadPrefixTable reads a CSV lookup table of prefix,asn,holder lines, where the holder is
+// optional and lines starting with # are ignored.
+func LoadPrefixTable(path string) (*PrefixTable, error) {
+	f, err := os.Open(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prefix table: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidPrefixTable, err)
+	}
+
+	table := &PrefixTable{}
+
+	for _, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("%w: %q, expected prefix,asn[,holder]", errInvalidPrefixTable, strings.Join(record, ","))
+		}
+
+		prefix, err := netip.ParsePrefix(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidPrefixTable, err)
+		}
+
+		entry := tablePrefix{prefix: prefix.Masked()}
+		entry.Prefix = prefix.Masked().String()
+		entry.ASN = strings.TrimPrefix(strings.ToUpper(record[1]), "AS")
+
+		if len(record) == 3 {
+			entry.Holder = record[2]
+		}
+
+		table.prefixes = append(table.prefixes, entry)
+	}
+
+	// The longest prefix comes first, so that the first match is the most specific one.
+	slices.SortStableFunc(table.prefixes, func(a, b tablePrefix) int {
+		return cmp.Compare(b.prefix.Bits(), a.prefix.Bits())
+	})
+
+	return table, nil
+}
+
+// Resolve returns the most specific prefix of the table containing the address.
+func (t *PrefixTable) Resolve(_ context.Context, addr netip.Addr) (AnnouncedPrefix, bool, error) {
+	for _, entry := range t.prefixes {
+		if entry.prefix.Contains(addr) {
+			return entry.AnnouncedPrefix, true, nil
+		}
+	}
+
+	return AnnouncedPrefix{}, false, nil
+}
+
+// RIPEstatResolver resolves the announced prefixes with the RIPEstat Data API.
+type RIPEstatResolver struct {
+	client   *http.Client
+	endpoint string
+	holders  map[string]string
+}
+
+// NewRIPEstatResolver creates a new RIPEstat resolver.
+func NewRIPEstatResolver(client *http.Client) *RIPEstatResolver {
+	return &RIPEstatResolver{client: client, endpoint: ripestatEndpoint, holders: map[string]string{}}
+}
+
+type ripestatNetworkInfo struct {
+	Data struct {
+		ASNs   []string `json:"asns"`
+		Prefix string   `json:"prefix"`
+	} `json:"data"`
+}
+
+type ripestatASOverview struct {
+	Data struct {
+		Holder string `json:"holder"`
+	} `json:"data"`
+}
+
+// Resolve returns the announced prefix of the address and the holder of its origin AS.
+// Addresses that are not announced are not resolved.
+func (r *RIPEstatResolver) Resolve(ctx context.Context, addr netip.Addr) (AnnouncedPrefix, bool, error) {
+	var info ripestatNetworkInfo
+	if err := r.get(ctx, "network-info", addr.String(), &info); err != nil {
+		return AnnouncedPrefix{}, false, err
+	}
+
+	if info.Data.Prefix == "" || len(info.Data.ASNs) == 0 {
+		return AnnouncedPrefix{}, false, nil
+	}
+
+	asn := info.Data.ASNs[0]
+
+	holder, ok := r.holders[asn]
+	if !ok {
+		var overview ripestatASOverview
+		if err := r.get(ctx, "as-overview", "AS"+asn, &overview); err != nil {
+			return AnnouncedPrefix{}, false, err
+		}
+
+		holder = overview.Data.Holder
+		r.holders[asn] = holder
+	}
+
+	return AnnouncedPrefix{Prefix: info.Data.Prefix, ASN: asn, Holder: holder}, true, nil
+}
+
+func (r *RIPEstatResolver) get(ctx context.Context, call, resource string, v any) error {
+	endpoint := r.endpoint + "/" + call + "/data.json?" + url.Values{"resource": {resource}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s: %s", errRIPEstatFailed, call, resp.Status)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// newPrefixResolver creates the resolver of the configured prefix source: ripestat,
+// or the path to a lookup table.
+func newPrefixResolver(cfg *Config) (PrefixResolver, error) {
+	if cfg.PrefixSource == prefixSourceRIPEstat {
+		return NewRIPEstatResolver(newHTTPClient(cfg)), nil
+	}
+
+	return LoadPrefixTable(cfg.PrefixSource)
+}
+
+// groupByAnnouncedPrefix groups the public addresses of the assets by their announced prefix,
+// sorted by the ASN and the prefix. Addresses that could not be resolved are grouped under an
+// empty prefix at the end, and failed lookups are logged.
+func groupByAnnouncedPrefix(
+	ctx context.Context,
+	logger *slog.Logger,
+	resolver PrefixResolver,
+	assets []ProcessedAsset,
+) []PrefixGroup {
+	byPrefix := map[AnnouncedPrefix]*PrefixGroup{}
+	seen := map[netip.Addr]bool{}
+
+	for _, asset := range assets {
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || !isPublicAddress(asset, addr) || seen[addr.Unmap()] {
+				continue
+			}
+
+			addr = addr.Unmap()
+			seen[addr] = true
+
+			announced, _, err := resolver.Resolve(ctx, addr)
+			if err != nil {
+				logger.WarnContext(ctx, "failed to resolve the announced prefix",
+					slog.String("address", address), slog.Any("error", err))
+			}
+
+			group, ok := byPrefix[announced]
+			if !ok {
+				group = &PrefixGroup{AnnouncedPrefix: announced, Addresses: []string{}}
+				byPrefix[announced] = group
+			}
+
+			group.Addresses = append(group.Addresses, addr.String())
+		}
+	}
+
+	groups := make([]PrefixGroup, 0, len(byPrefix))
+	for _, group := range byPrefix {
+		slices.Sort(group.Addresses)
+		groups = append(groups, *group)
+	}
+
+	slices.SortFunc(groups, func(a, b PrefixGroup) int {
+		if (a.Prefix == "") != (b.Prefix == "") {
+			return cmp.Compare(b.Prefix, a.Prefix)
+		}
+
+		// ASNs are compared by length first to sort them numerically.
+		return cmp.Or(cmp.Compare(len(a.ASN), len(b.ASN)), cmp.Compare(a.ASN, b.ASN), cmp.Compare(a.Prefix, b.Prefix))
+	})
+
+	return groups
+}
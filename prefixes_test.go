@@ -0,0 +1,125 @@
+package assetwatcher
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadPrefixTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefixes.csv")
+	content := "# prefix,asn,holder\n203.0.113.0/24,AS64500,Example Net\n203.0.113.128/25,64501\n"
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := LoadPrefixTable(path)
+	if err != nil {
+		t.Fatalf("LoadPrefixTable failed: %v", err)
+	}
+
+	tests := []struct {
+		addr  string
+		want  AnnouncedPrefix
+		found bool
+	}{
+		{addr: "203.0.113.1", want: AnnouncedPrefix{Prefix: "203.0.113.0/24", ASN: "64500", Holder: "Example Net"}, found: true},
+		{addr: "203.0.113.200", want: AnnouncedPrefix{Prefix: "203.0.113.128/25", ASN: "64501"}, found: true},
+		{addr: "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		got, found, err := table.Resolve(t.Context(), netip.MustParseAddr(tt.addr))
+		if err != nil || found != tt.found || got != tt.want {
+			t.Errorf("Resolve(%s) = %+v, %v, %v, want %+v, %v", tt.addr, got, found, err, tt.want, tt.found)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("203.0.113.0/24\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadPrefixTable(path); !errors.Is(err, errInvalidPrefixTable) {
+		t.Errorf("expected errInvalidPrefixTable, got %v", err)
+	}
+}
+
+func TestRIPEstatResolver_Resolve(t *testing.T) {
+	holderQueries := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/network-info/data.json":
+			if r.URL.Query().Get("resource") == "198.51.100.1" {
+				_, _ = w.Write([]byte(`{"data": {"asns": [], "prefix": ""}}`))
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"data": {"asns": ["64500"], "prefix": "203.0.113.0/24"}}`))
+		case "/as-overview/data.json":
+			holderQueries++
+
+			if r.URL.Query().Get("resource") != "AS64500" {
+				t.Errorf("unexpected resource %q", r.URL.Query().Get("resource"))
+			}
+
+			_, _ = w.Write([]byte(`{"data": {"holder": "EXAMPLE-NET"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewRIPEstatResolver(server.Client())
+	resolver.endpoint = server.URL
+
+	want := AnnouncedPrefix{Prefix: "203.0.113.0/24", ASN: "64500", Holder: "EXAMPLE-NET"}
+
+	for _, addr := range []string{"203.0.113.1", "203.0.113.2"} {
+		got, found, err := resolver.Resolve(t.Context(), netip.MustParseAddr(addr))
+		if err != nil || !found || got != want {
+			t.Errorf("Resolve(%s) = %+v, %v, %v, want %+v", addr, got, found, err, want)
+		}
+	}
+
+	if holderQueries != 1 {
+		t.Errorf("expected the holder to be queried once, got %d", holderQueries)
+	}
+
+	if _, found, err := resolver.Resolve(t.Context(), netip.MustParseAddr("198.51.100.1")); err != nil || found {
+		t.Errorf("expected an unannounced address, got %v, %v", found, err)
+	}
+}
+
+func TestGroupByAnnouncedPrefix(t *testing.T) {
+	table := &PrefixTable{prefixes: []tablePrefix{
+		{prefix: netip.MustParsePrefix("203.0.113.0/24"), AnnouncedPrefix: AnnouncedPrefix{Prefix: "203.0.113.0/24", ASN: "64500"}},
+		{prefix: netip.MustParsePrefix("198.51.100.0/24"), AnnouncedPrefix: AnnouncedPrefix{Prefix: "198.51.100.0/24", ASN: "9"}},
+	}}
+	assets := []ProcessedAsset{
+		{Name: "a1", IPAddress: "203.0.113.2"},
+		{Name: "a2", IPAddress: "198.51.100.1"},
+		{Name: "vm", Attributes: map[string]string{"externalIPs": "203.0.113.1,192.0.2.1"}},
+		{Name: "dup", IPAddress: "203.0.113.1"},
+		{Name: "internal", IPAddress: "10.0.0.1", AddressType: addressTypeInternal},
+	}
+
+	got := groupByAnnouncedPrefix(t.Context(), slog.New(slog.DiscardHandler), table, assets)
+
+	want := []PrefixGroup{
+		{AnnouncedPrefix: AnnouncedPrefix{Prefix: "198.51.100.0/24", ASN: "9"}, Addresses: []string{"198.51.100.1"}},
+		{AnnouncedPrefix: AnnouncedPrefix{Prefix: "203.0.113.0/24", ASN: "64500"}, Addresses: []string{"203.0.113.1", "203.0.113.2"}},
+		{Addresses: []string{"192.0.2.1"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByAnnouncedPrefix() = %+v, want %+v", got, want)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package assetwatcher
 
 import (
 	"context"
@@ -7,12 +7,15 @@ import (
 	"log/slog"
 	"slices"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/asset/apiv1/assetpb"
 	"google.golang.org/api/iterator"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+var errInvalidLabel = errors.New("invalid label")
+
 // AssetIterator is an interface for iterating over assets.
 type AssetIterator interface {
 	Next() (*assetpb.ResourceSearchResult, error)
@@ -26,6 +29,48 @@ type ProcessedAsset struct {
 	IPAddress string `json:"ipAddress"`
 	Project   string `json:"project"`
 	CreatedAt string `json:"createdAt"`
+	Age       string `json:"age,omitempty"`
+
+	// CreateTime is the raw creation time, in UTC, whatever the format of CreatedAt.
+	CreateTime time.Time `json:"createTime,omitzero"`
+
+	ResourceName         string  `json:"resourceName,omitempty"`
+	AssetType            string  `json:"assetType,omitempty"`
+	AddressType          string  `json:"addressType,omitempty"`
+	EstimatedMonthlyCost float64 `json:"estimatedMonthlyCost,omitempty"`
+
+	Labels     map[string]string `json:"labels,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	Recommendation            string  `json:"recommendation,omitempty"`
+	RecommendedMonthlySavings float64 `json:"recommendedMonthlySavings,omitempty"`
+	IdleAgreement             string  `json:"idleAgreement,omitempty"`
+
+	Disposition string `json:"disposition,omitempty"`
+
+	PartnerPeers []string `json:"partnerPeers,omitempty"`
+
+	LastTrafficSeen string `json:"lastTrafficSeen,omitempty"`
+	TrafficBytes    int64  `json:"trafficBytes,omitempty"`
+
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+
+	Blocklists []string `json:"blocklists,omitempty"`
+
+	ExposedPorts []string `json:"exposedPorts,omitempty"`
+
+	BYOIP bool `json:"byoip,omitempty"`
+
+	// Attachments are the kinds of the resources using an address, such as forwardingRules.
+	Attachments []string `json:"attachments,omitempty"`
+	Category    string   `json:"category,omitempty"`
+
+	Compliance         string   `json:"compliance,omitempty"`
+	OutOfBandAddresses []string `json:"outOfBandAddresses,omitempty"`
+
+	// ReleasedAt is the time of the first run the asset was missing from, for released assets.
+	ReleasedAt string `json:"releasedAt,omitempty"`
 }
 
 // AssetProcessor is a client for processing assets.
@@ -60,18 +105,124 @@ func splitString(s string, separator string) []string {
 	return result
 }
 
-// ProcessAssets processes the assets and filters them based on the configuration.
+// parseLabels parses a comma-separated list of key=value pairs.
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, pair := range splitString(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q, expected key=value", errInvalidLabel, pair)
+		}
+
+		labels[key] = strings.TrimSpace(value)
+	}
+
+	return labels, nil
+}
+
+// matchesAllLabels reports whether the asset has every label of the selector.
+func matchesAllLabels(assetLabels, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := assetLabels[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesAnyLabel reports whether the asset has at least one label of the selector.
+func matchesAnyLabel(assetLabels, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := assetLabels[key]; ok && v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectsProject reports whether the project is selected by the included and excluded projects.
+// The included projects, if any, narrow the projects first, and the excluded projects are then
+// removed from them, so that both can be combined, such as the projects of a team but a sandbox.
+func selectsProject(includeProjects, excludeProjects []string, projectID string) bool {
+	if len(includeProjects) > 0 && !slices.Contains(includeProjects, projectID) {
+		return false
+	}
+
+	return !slices.Contains(excludeProjects, projectID)
+}
+
+// ProcessAssets processes the assets and filters them based on the configuration. On an error,
+// such as the cancellation of the context, the assets processed so far are returned with it.
 func (p *AssetProcessor) ProcessAssets(ctx context.Context,
 	assets AssetIterator,
 ) ([]ProcessedAsset, error) {
+	processedResults := []ProcessedAsset{}
+
+	err := p.StreamAssets(ctx, assets, func(asset ProcessedAsset) error {
+		processedResults = append(processedResults, asset)
+
+		return nil
+	})
+
+	return processedResults, err
+}
+
+// StreamAssets processes the assets and filters them based on the configuration, and
+// passes every processed asset to emit as soon as it is read, without holding the
+// inventory in memory. It stops at the first error of emit.
+func (p *AssetProcessor) StreamAssets(ctx context.Context,
+	assets AssetIterator,
+	emit func(ProcessedAsset) error,
+) error {
 	totalAssets := 0
+	totalProcessed := 0
 
 	includeProjects := splitString(p.cfg.IncludeProjects, ",")
 	excludeProjects := splitString(p.cfg.ExcludeProjects, ",")
 
-	p.logger.DebugContext(ctx, "Processing assets...")
+	includeLabels, err := parseLabels(p.cfg.IncludeLabels)
+	if err != nil {
+		return fmt.Errorf("failed to parse included labels: %w", err)
+	}
+
+	excludeLabels, err := parseLabels(p.cfg.ExcludeLabels)
+	if err != nil {
+		return fmt.Errorf("failed to parse excluded labels: %w", err)
+	}
+
+	regexFilters, err := newAssetRegexFilters(p.cfg)
+	if err != nil {
+		return err
+	}
+
+	exprFilter, err := newCELFilter(p.cfg.FilterExpr)
+	if err != nil {
+		return fmt.Errorf("invalid filter expression: %w", err)
+	}
+
+	rules, err := LoadRules(p.cfg.RulesFile)
+	if err != nil {
+		return err
+	}
+
+	ages, err := newAgeFilter(p.cfg.MinAge, p.cfg.MaxAge)
+	if err != nil {
+		return err
+	}
+
+	byoipRanges, err := parseCIDRs(p.cfg.BYOIPRanges)
+	if err != nil {
+		return fmt.Errorf("invalid BYOIP ranges: %w", err)
+	}
 
-	processedResults := make([]ProcessedAsset, 0, totalAssets)
+	now := time.Now()
+
+	p.logger.DebugContext(ctx, "Processing assets...")
 
 	for {
 		asset, err := assets.Next()
@@ -80,10 +231,18 @@ func (p *AssetProcessor) ProcessAssets(ctx context.Context,
 		}
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to create asset client: %w", err)
+			return fmt.Errorf("failed to create asset client: %w", err)
 		}
 
 		totalAssets++
+
+		recordAsset(ctx, asset)
+
+		p.logger.DebugContext(ctx, "Processing asset",
+			slog.String("name", asset.GetName()),
+			slog.String("asset_type", asset.GetAssetType()),
+		)
+
 		projectID := getProjectID(asset)
 		ipAddress := getIPAddress(asset)
 
@@ -91,54 +250,102 @@ func (p *AssetProcessor) ProcessAssets(ctx context.Context,
 			continue
 		}
 
-		if slices.Contains(excludeProjects, projectID) {
+		if !selectsProject(includeProjects, excludeProjects, projectID) {
 			continue
 		}
 
-		var include bool
-		if len(includeProjects) > 0 {
-			include = slices.Contains(includeProjects, projectID)
-		} else {
-			include = true
+		if matchesAnyLabel(asset.GetLabels(), excludeLabels) || !matchesAllLabels(asset.GetLabels(), includeLabels) {
+			continue
 		}
 
-		if include {
-			processedResults = append(processedResults, ProcessedAsset{
-				Name:      asset.GetDisplayName(),
-				Location:  asset.GetLocation(),
-				Project:   projectID,
-				IPAddress: ipAddress,
-				Status:    asset.GetState(),
-				CreatedAt: asset.GetCreateTime().AsTime().Format("2006-01-02 15:04:05"),
-			})
+		processedAsset := ProcessedAsset{
+			Name:         asset.GetDisplayName(),
+			Location:     asset.GetLocation(),
+			Project:      projectID,
+			IPAddress:    ipAddress,
+			Status:       asset.GetState(),
+			CreatedAt:    asset.GetCreateTime().AsTime().Format(createdAtLayout),
+			CreateTime:   asset.GetCreateTime().AsTime().UTC(),
+			ResourceName: asset.GetName(),
+			AssetType:    asset.GetAssetType(),
+			AddressType:  getStringAttribute(asset, "addressType", ""),
+			Labels:       asset.GetLabels(),
+			Attributes:   extractorFor(asset.GetAssetType()).extract(asset),
+			Attachments:  attachmentKinds(splitString(getListAttribute(asset, "users"), ",")),
 		}
+
+		if !regexFilters.matches(processedAsset) {
+			continue
+		}
+
+		if match, err := exprFilter.matches(processedAsset); !match {
+			if err != nil {
+				p.logger.WarnContext(ctx, "failed to evaluate the filter expression, skipping asset",
+					slog.String("name", processedAsset.Name),
+					slog.Any("error", err),
+				)
+			}
+
+			continue
+		}
+
+		if !rules.allows(processedAsset, now) || !ages.matches(processedAsset, now) {
+			continue
+		}
+
+		if p.cfg.ShowAge {
+			if age, ok := assetAge(processedAsset, now); ok {
+				processedAsset.Age = formatAge(age)
+			}
+		}
+
+		processedAsset.BYOIP = isBYOIP(processedAsset, byoipRanges)
+
+		if p.cfg.ShowCost {
+			hourlyPrice := p.cfg.IdleAddressHourlyPrice
+			if processedAsset.BYOIP {
+				hourlyPrice = p.cfg.BYOIPHourlyPrice
+			}
+
+			processedAsset.EstimatedMonthlyCost = estimateMonthlyCost(processedAsset, hourlyPrice)
+		}
+
+		if err := emit(processedAsset); err != nil {
+			return err
+		}
+
+		totalProcessed++
 	}
 
 	p.logger.DebugContext(ctx, "Finished processing assets",
 		slog.Int("total_assets", totalAssets),
-		slog.Int("total_filtered", totalAssets-len(processedResults)),
+		slog.Int("total_filtered", totalAssets-totalProcessed),
 	)
 
-	return processedResults, nil
+	return nil
 }
 
 func getIPAddress(asset *assetpb.ResourceSearchResult) string {
-	ipAddress := "N/A"
+	return getStringAttribute(asset, "address", "N/A")
+}
 
+// getStringAttribute returns a string value of the additional attribute of the asset,
+// or the fallback value if the attribute is absent or is not a string.
+func getStringAttribute(asset *assetpb.ResourceSearchResult, key, fallback string) string {
 	isFieldsExists := asset.GetAdditionalAttributes() != nil && asset.GetAdditionalAttributes().GetFields() != nil
 	if !isFieldsExists {
-		return ipAddress
+		return fallback
 	}
 
-	if addressField, ok := asset.GetAdditionalAttributes().GetFields()["address"]; ok {
-		if addressField != nil {
-			if sv, ok := addressField.GetKind().(*structpb.Value_StringValue); ok {
-				ipAddress = sv.StringValue
+	if field, ok := asset.GetAdditionalAttributes().GetFields()[key]; ok {
+		if field != nil {
+			if sv, ok := field.GetKind().(*structpb.Value_StringValue); ok {
+				return sv.StringValue
 			}
 		}
 	}
 
-	return ipAddress
+	return fallback
 }
 
 func getProjectID(asset *assetpb.ResourceSearchResult) string {
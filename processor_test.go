@@ -1,4 +1,4 @@
-package main
+package assetwatcher
 
 import (
 	"errors"
@@ -42,6 +42,87 @@ func TestSplitString(t *testing.T) {
 	}
 }
 
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty string", s: "", want: map[string]string{}},
+		{name: "single pair", s: "env=prod", want: map[string]string{"env": "prod"}},
+		{name: "multiple pairs with spaces", s: " env = prod , team=net ", want: map[string]string{"env": "prod", "team": "net"}},
+		{name: "empty value", s: "env=", want: map[string]string{"env": ""}},
+		{name: "missing separator", s: "env", wantErr: true},
+		{name: "missing key", s: "=prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLabels(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessAssets_Labels(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+	baseTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	newLabeledAsset := func(name string, labels map[string]string) *assetpb.ResourceSearchResult {
+		asset := createTestAsset(name, "proj-A", "RESERVED", "1.2.3.4", baseTime)
+		asset.Labels = labels
+
+		return asset
+	}
+
+	assets := []*assetpb.ResourceSearchResult{
+		newLabeledAsset("prod", map[string]string{"env": "prod"}),
+		newLabeledAsset("prod-ignored", map[string]string{"env": "prod", "asset-watcher-ignore": "true"}),
+		newLabeledAsset("dev", map[string]string{"env": "dev"}),
+		newLabeledAsset("unlabeled", nil),
+	}
+
+	tests := []struct {
+		name  string
+		cfg   *Config
+		names []string
+	}{
+		{name: "no label filters", cfg: &Config{}, names: []string{"prod", "prod-ignored", "dev", "unlabeled"}},
+		{name: "include labels", cfg: &Config{IncludeLabels: "env=prod"}, names: []string{"prod", "prod-ignored"}},
+		{name: "exclude labels", cfg: &Config{ExcludeLabels: "asset-watcher-ignore=true"}, names: []string{"prod", "dev", "unlabeled"}},
+		{name: "include and exclude labels", cfg: &Config{IncludeLabels: "env=prod", ExcludeLabels: "asset-watcher-ignore=true"}, names: []string{"prod"}},
+		{name: "include requires all labels", cfg: &Config{IncludeLabels: "env=prod,asset-watcher-ignore=true"}, names: []string{"prod-ignored"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewAssetProcessor(ctx, logger, tt.cfg)
+
+			results, err := processor.ProcessAssets(ctx, &mockAssetIterator{assets: assets})
+			if err != nil {
+				t.Fatalf("ProcessAssets failed: %v", err)
+			}
+
+			names := make([]string, 0, len(results))
+			for _, result := range results {
+				names = append(names, result.Name)
+			}
+
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("ProcessAssets() returned %v, want %v", names, tt.names)
+			}
+		})
+	}
+}
+
 func TestGetIPAddress(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -249,6 +330,31 @@ func TestProcessAssets(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "include specific projects then exclude some of them",
+			config: &Config{
+				OrgID:           "test-org",
+				ExcludeReserved: false,
+				ExcludeProjects: "proj-B,proj-C",
+				IncludeProjects: "proj-A,proj-B",
+			},
+			assets: []*assetpb.ResourceSearchResult{
+				createTestAsset("asset1", "proj-A", "ACTIVE", "1.2.3.4", baseTime),
+				createTestAsset("asset2", "proj-B", "ACTIVE", "5.6.7.8", baseTime),
+				createTestAsset("asset3", "proj-C", "ACTIVE", "9.10.11.12", baseTime),
+			},
+			expectedCount: 1,
+			expectedAssets: []ProcessedAsset{
+				{
+					Name:      "asset1",
+					Location:  "us-central1",
+					Project:   "proj-A",
+					IPAddress: "1.2.3.4",
+					Status:    "ACTIVE",
+					CreatedAt: "2024-01-10 12:00:00",
+				},
+			},
+		},
 		{
 			name: "include specific projects only",
 			config: &Config{
@@ -0,0 +1,72 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// runAllProfiles runs asset-watcher with the arguments for every profile of the configuration
+// file in turn, so that one deployment scans the organizations of several customers. Every
+// profile runs in its own process, which exits on its own and writes its own result, and a
+// failing profile does not stop the others. It returns the first non-zero exit code, in the
+// order of the profile names.
+func runAllProfiles(ctx context.Context, configFile string, args []string, stdout, stderr io.Writer) int {
+	executable, err := os.Executable()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to find the asset-watcher executable: %v\n", err)
+
+		return 1
+	}
+
+	return runProfiles(ctx, executable, configFile, args, stdout, stderr)
+}
+
+// runProfiles runs the executable with the arguments for every profile of the configuration file.
+func runProfiles(ctx context.Context, executable, configFile string, args []string, stdout, stderr io.Writer) int {
+	if configFile == "" {
+		_, _ = fmt.Fprintf(stderr, "--all-profiles requires --config or %s\n", configFileEnv)
+
+		return 1
+	}
+
+	profiles, err := configProfiles(configFile)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to read the profiles: %v\n", err)
+
+		return 1
+	}
+
+	code := 0
+
+	for _, profile := range profiles {
+		//nolint:gosec // The arguments are those of the operator, for the executable itself.
+		cmd := exec.CommandContext(ctx, executable,
+			append([]string{"--config", configFile, "--profile", profile}, args...)...)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		err := cmd.Run()
+		if err == nil {
+			continue
+		}
+
+		profileCode := 1
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			profileCode = exitErr.ExitCode()
+		}
+
+		_, _ = fmt.Fprintf(stderr, "profile %s failed with exit code %d: %v\n", profile, profileCode, err)
+
+		if code == 0 {
+			code = profileCode
+		}
+	}
+
+	return code
+}
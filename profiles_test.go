@@ -0,0 +1,36 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunProfiles(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", profilesConfig)
+
+	var stdout, stderr bytes.Buffer
+	if code := runProfiles(t.Context(), "/bin/echo", path, []string{"diff", "--since", "7d"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runProfiles() = %d, stderr %q", code, stderr.String())
+	}
+
+	want := "--config " + path + " --profile acme diff --since 7d\n" +
+		"--config " + path + " --profile globex diff --since 7d\n"
+	if stdout.String() != want {
+		t.Errorf("runProfiles() ran %q, want %q", stdout.String(), want)
+	}
+
+	stderr.Reset()
+
+	if code := runProfiles(t.Context(), "/bin/false", path, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+
+	if !strings.Contains(stderr.String(), "profile acme failed") || !strings.Contains(stderr.String(), "profile globex failed") {
+		t.Errorf("expected every profile to run, got %q", stderr.String())
+	}
+
+	if code := runProfiles(t.Context(), "/bin/echo", "", nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 without a configuration file, got %d", code)
+	}
+}
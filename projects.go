@@ -0,0 +1,175 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+)
+
+const projectAssetType = "cloudresourcemanager.googleapis.com/Project"
+
+// Reasons a project could not be scanned.
+const (
+	unscannablePermissionDenied = "permission-denied"
+	unscannableAPIDisabled      = "api-disabled"
+	unscannableError            = "error"
+)
+
+// ProjectError represents a project that could not be scanned.
+type ProjectError struct {
+	Project string `json:"project"`
+	Reason  string `json:"reason"`
+	Error   string `json:"error"`
+}
+
+// CoverageSummary represents the share of the projects in scope that were scanned successfully.
+type CoverageSummary struct {
+	TotalProjects   int     `json:"totalProjects"`
+	ScannedProjects int     `json:"scannedProjects"`
+	Percent         float64 `json:"percent"`
+}
+
+// newCoverageSummary computes the coverage of a scan. A scan without any project
+// in scope did not miss anything and is fully covered.
+func newCoverageSummary(totalProjects int, unscannable []ProjectError) CoverageSummary {
+	scanned := totalProjects - len(unscannable)
+
+	percent := 100.0
+	if totalProjects > 0 {
+		percent = float64(scanned) * 100 / float64(totalProjects)
+	}
+
+	return CoverageSummary{TotalProjects: totalProjects, ScannedProjects: scanned, Percent: percent}
+}
+
+// newProjectError classifies the error returned when scanning a project.
+func newProjectError(project string, err error) ProjectError {
+	reason := unscannableError
+
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Reason() == "SERVICE_DISABLED" || apiErr.Reason() == "API_DISABLED":
+			reason = unscannableAPIDisabled
+		case apiErr.GRPCStatus().Code() == codes.PermissionDenied || apiErr.HTTPCode() == 403:
+			reason = unscannablePermissionDenied
+		}
+	}
+
+	return ProjectError{Project: project, Reason: reason, Error: err.Error()}
+}
+
+// ProjectAssetIterator iterates over the assets of several projects, one project at a time.
+// Errors of a project are collected and the iteration continues with the next project.
+type ProjectAssetIterator struct {
+	projects []string
+	total    int
+	search   func(scope string) AssetIterator
+	current  AssetIterator
+	project  string
+	errors   []ProjectError
+	logger   *slog.Logger
+}
+
+// Next returns the next asset of the current or following projects.
+func (it *ProjectAssetIterator) Next() (*assetpb.ResourceSearchResult, error) {
+	for {
+		if it.current == nil {
+			if len(it.projects) == 0 {
+				return nil, iterator.Done
+			}
+
+			it.project, it.projects = it.projects[0], it.projects[1:]
+			it.current = it.search("projects/" + it.project)
+		}
+
+		asset, err := it.current.Next()
+		if errors.Is(err, iterator.Done) {
+			it.current = nil
+
+			continue
+		}
+
+		if err != nil {
+			projectErr := newProjectError(it.project, err)
+			it.logger.Warn("failed to scan project",
+				slog.String("project", it.project),
+				slog.String("reason", projectErr.Reason),
+				slog.Any("error", err),
+			)
+
+			it.errors = append(it.errors, projectErr)
+			it.current = nil
+
+			continue
+		}
+
+		return asset, nil
+	}
+}
+
+// Errors returns the projects that could not be scanned so far.
+func (it *ProjectAssetIterator) Errors() []ProjectError {
+	return it.errors
+}
+
+// Coverage returns the coverage of the projects scanned so far.
+func (it *ProjectAssetIterator) Coverage() CoverageSummary {
+	return newCoverageSummary(it.total, it.errors)
+}
+
+// FetchAssetsPerProject lists the active projects of the organization and fetches the assets
+// of each project separately, so that a project that cannot be scanned does not fail the run.
+func (f *GoogleAssetFetcher) FetchAssetsPerProject(ctx context.Context) (*ProjectAssetIterator, error) {
+	projects, err := listProjects(f.search(ctx, "organizations/"+f.cfg.OrgID, []string{projectAssetType}), f.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f.logger.DebugContext(ctx, "Fetching assets per project", slog.Int("number_of_projects", len(projects)))
+
+	return &ProjectAssetIterator{
+		projects: projects,
+		total:    len(projects),
+		search:   func(scope string) AssetIterator { return f.fetch(ctx, scope) },
+		logger:   f.logger,
+	}, nil
+}
+
+// listProjects returns the IDs of the active projects, honoring the included and excluded projects.
+func listProjects(projects AssetIterator, cfg *Config) ([]string, error) {
+	includeProjects := splitString(cfg.IncludeProjects, ",")
+	excludeProjects := splitString(cfg.ExcludeProjects, ",")
+	ids := []string{}
+
+	for {
+		project, err := projects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		if project.GetState() != "ACTIVE" {
+			continue
+		}
+
+		id := getStringAttribute(project, "projectId", lastPathSegment(project.GetName()))
+
+		if !selectsProject(includeProjects, excludeProjects, id) {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,157 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newTestAPIError(t *testing.T, code codes.Code, reason string) error {
+	t.Helper()
+
+	st := status.New(code, "request failed")
+	if reason != "" {
+		var err error
+		if st, err = st.WithDetails(&errdetails.ErrorInfo{Reason: reason}); err != nil {
+			t.Fatalf("failed to add error details: %v", err)
+		}
+	}
+
+	apiErr, ok := apierror.FromError(st.Err())
+	if !ok {
+		t.Fatal("failed to convert the status to an API error")
+	}
+
+	return apiErr
+}
+
+func TestNewProjectError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "permission denied", err: newTestAPIError(t, codes.PermissionDenied, ""), want: unscannablePermissionDenied},
+		{name: "API disabled", err: newTestAPIError(t, codes.PermissionDenied, "SERVICE_DISABLED"), want: unscannableAPIDisabled},
+		{name: "other", err: errSimulatedAPI, want: unscannableError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newProjectError("proj", tt.err); got.Reason != tt.want {
+				t.Errorf("newProjectError() reason = %q, want %q", got.Reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectAssetIterator(t *testing.T) {
+	deniedErr := newTestAPIError(t, codes.PermissionDenied, "")
+	iterators := map[string]AssetIterator{
+		"projects/proj-A": &mockAssetIterator{assets: []*assetpb.ResourceSearchResult{{DisplayName: "a1"}, {DisplayName: "a2"}}},
+		"projects/proj-B": &mockAssetIterator{err: deniedErr},
+		"projects/proj-C": &mockAssetIterator{assets: []*assetpb.ResourceSearchResult{{DisplayName: "c1"}}},
+	}
+
+	it := &ProjectAssetIterator{
+		projects: []string{"proj-A", "proj-B", "proj-C"},
+		total:    3,
+		search:   func(scope string) AssetIterator { return iterators[scope] },
+		logger:   slog.New(slog.DiscardHandler),
+	}
+
+	processor := NewAssetProcessor(t.Context(), slog.New(slog.DiscardHandler), &Config{})
+
+	results, err := processor.ProcessAssets(t.Context(), it)
+	if err != nil {
+		t.Fatalf("ProcessAssets failed: %v", err)
+	}
+
+	names := []string{}
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"a1", "a2", "c1"}) {
+		t.Errorf("expected assets of the scannable projects, got %v", names)
+	}
+
+	want := []ProjectError{{Project: "proj-B", Reason: unscannablePermissionDenied, Error: deniedErr.Error()}}
+	if !reflect.DeepEqual(it.Errors(), want) {
+		t.Errorf("Errors() = %v, want %v", it.Errors(), want)
+	}
+
+	if coverage := it.Coverage(); coverage.ScannedProjects != 2 || coverage.TotalProjects != 3 {
+		t.Errorf("unexpected coverage %+v", coverage)
+	}
+}
+
+func TestNewCoverageSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		unscannable []ProjectError
+		want        CoverageSummary
+	}{
+		{name: "fully scanned", total: 4, want: CoverageSummary{TotalProjects: 4, ScannedProjects: 4, Percent: 100}},
+		{
+			name: "one unscannable project", total: 4, unscannable: []ProjectError{{Project: "p"}},
+			want: CoverageSummary{TotalProjects: 4, ScannedProjects: 3, Percent: 75},
+		},
+		{name: "no projects in scope", total: 0, want: CoverageSummary{Percent: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newCoverageSummary(tt.total, tt.unscannable); got != tt.want {
+				t.Errorf("newCoverageSummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	project := func(id, state string) *assetpb.ResourceSearchResult {
+		return &assetpb.ResourceSearchResult{
+			Name:  "//cloudresourcemanager.googleapis.com/projects/123",
+			State: state,
+			AdditionalAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{
+				"projectId": structpb.NewStringValue(id),
+			}},
+		}
+	}
+
+	projects := []*assetpb.ResourceSearchResult{
+		project("proj-A", "ACTIVE"), project("proj-B", "DELETE_REQUESTED"), project("proj-C", "ACTIVE"),
+	}
+
+	got, err := listProjects(&mockAssetIterator{assets: projects}, &Config{ExcludeProjects: "proj-C"})
+	if err != nil {
+		t.Fatalf("listProjects failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, []string{"proj-A"}) {
+		t.Errorf("listProjects() = %v, want [proj-A]", got)
+	}
+
+	got, err = listProjects(&mockAssetIterator{assets: projects},
+		&Config{IncludeProjects: "proj-A,proj-C", ExcludeProjects: "proj-A"})
+	if err != nil {
+		t.Fatalf("listProjects failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, []string{"proj-C"}) {
+		t.Errorf("listProjects() = %v, want [proj-C]", got)
+	}
+
+	if _, err := listProjects(&mockAssetIterator{err: errSimulatedAPI}, &Config{}); err == nil {
+		t.Error("expected an error when projects cannot be listed")
+	}
+}
@@ -0,0 +1,214 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	rdapEndpoint = "https://rdap.org/ip/"
+
+	// A jCard is ["vcard", [[name, params, type, value], ...]].
+	jCardLength         = 2
+	jCardPropertyLength = 4
+)
+
+var (
+	errRDAPFailed  = errors.New("RDAP query failed")
+	errInvalidCIDR = errors.New("invalid CIDR range")
+)
+
+// RDAPNetwork is the registration data of an IP network published in RDAP.
+type RDAPNetwork struct {
+	Handle   string
+	Name     string
+	Contacts []string
+}
+
+// RDAPFinding represents the consistency of the registration data of a range with the allocation.
+type RDAPFinding struct {
+	Range              string   `json:"range"`
+	Handle             string   `json:"handle,omitempty"`
+	Name               string   `json:"name,omitempty"`
+	Contacts           []string `json:"contacts,omitempty"`
+	AllocatedAddresses int      `json:"allocatedAddresses"`
+	Issues             []string `json:"issues,omitempty"`
+}
+
+// RDAPClient looks up the registration data of IP networks, following the redirects of
+// the rdap.org bootstrap service to the authoritative registry.
+type RDAPClient struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewRDAPClient creates a new RDAP client.
+func NewRDAPClient(client *http.Client) *RDAPClient {
+	return &RDAPClient{client: client, endpoint: rdapEndpoint}
+}
+
+type rdapEntity struct {
+	VCardArray []json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity      `json:"entities"`
+}
+
+type rdapIPNetwork struct {
+	Handle   string       `json:"handle"`
+	Name     string       `json:"name"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+// LookupNetwork returns the registration data of the network containing the range.
+func (c *RDAPClient) LookupNetwork(ctx context.Context, prefix netip.Prefix) (RDAPNetwork, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+prefix.String(), nil)
+	if err != nil {
+		return RDAPNetwork{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return RDAPNetwork{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return RDAPNetwork{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return RDAPNetwork{}, fmt.Errorf("%w: %s", errRDAPFailed, resp.Status)
+	}
+
+	network := rdapIPNetwork{}
+	if err := json.Unmarshal(body, &network); err != nil {
+		return RDAPNetwork{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	contacts := []string{}
+	for _, entity := range network.Entities {
+		contacts = appendEntityEmails(contacts, entity)
+	}
+
+	slices.Sort(contacts)
+
+	return RDAPNetwork{Handle: network.Handle, Name: network.Name, Contacts: slices.Compact(contacts)}, nil
+}
+
+// appendEntityEmails appends the email addresses of the jCard of the entity and its nested entities.
+func appendEntityEmails(emails []string, entity rdapEntity) []string {
+	if len(entity.VCardArray) == jCardLength {
+		var properties [][]any
+		if err := json.Unmarshal(entity.VCardArray[1], &properties); err == nil {
+			for _, property := range properties {
+				if len(property) == jCardPropertyLength && property[0] == "email" {
+					if email, ok := property[3].(string); ok {
+						emails = append(emails, strings.ToLower(email))
+					}
+				}
+			}
+		}
+	}
+
+	for _, nested := range entity.Entities {
+		emails = appendEntityEmails(emails, nested)
+	}
+
+	return emails
+}
+
+// checkRDAP cross-checks the published registration data of the ranges against the allocation:
+// the name must match the expected pattern, if set, every contact must be an expected contact,
+// if set, and the range must have allocated addresses. Failed lookups are logged and reported
+// as an issue of the range.
+func checkRDAP(
+	ctx context.Context,
+	logger *slog.Logger,
+	client *RDAPClient,
+	cfg *Config,
+	assets []ProcessedAsset,
+) []RDAPFinding {
+	// The ranges and the pattern are validated by GetConfig.
+	ranges, _ := parseCIDRs(cfg.RDAPRanges)
+	namePattern, _ := regexp.Compile(cfg.RDAPNetname)
+	expectedContacts := splitString(strings.ToLower(cfg.RDAPContacts), ",")
+
+	findings := make([]RDAPFinding, 0, len(ranges))
+
+	for _, prefix := range ranges {
+		finding := RDAPFinding{Range: prefix.String(), AllocatedAddresses: countAllocated(assets, prefix), Issues: []string{}}
+
+		if finding.AllocatedAddresses == 0 {
+			finding.Issues = append(finding.Issues, "no allocated addresses in the range")
+		}
+
+		network, err := client.LookupNetwork(ctx, prefix)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to look up RDAP data", slog.String("range", prefix.String()), slog.Any("error", err))
+			finding.Issues = append(finding.Issues, "RDAP lookup failed")
+			findings = append(findings, finding)
+
+			continue
+		}
+
+		finding.Handle, finding.Name, finding.Contacts = network.Handle, network.Name, network.Contacts
+
+		if cfg.RDAPNetname != "" && !namePattern.MatchString(network.Name) {
+			finding.Issues = append(finding.Issues, fmt.Sprintf("name %q does not match %q", network.Name, cfg.RDAPNetname))
+		}
+
+		if len(expectedContacts) > 0 {
+			for _, contact := range network.Contacts {
+				if !slices.Contains(expectedContacts, contact) {
+					finding.Issues = append(finding.Issues, "unexpected contact "+contact)
+				}
+			}
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+// parseCIDRs parses a comma-separated list of CIDR ranges.
+func parseCIDRs(s string) ([]netip.Prefix, error) {
+	prefixes := []netip.Prefix{}
+
+	for _, cidr := range splitString(s, ",") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", errInvalidCIDR, cidr, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// countAllocated returns the number of distinct public addresses of the assets within the range.
+func countAllocated(assets []ProcessedAsset, prefix netip.Prefix) int {
+	allocated := map[netip.Addr]bool{}
+
+	for _, asset := range assets {
+		for _, address := range ownedAddresses(asset) {
+			if addr, err := netip.ParseAddr(address); err == nil && prefix.Contains(addr.Unmap()) {
+				allocated[addr.Unmap()] = true
+			}
+		}
+	}
+
+	return len(allocated)
+}
@@ -0,0 +1,68 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCheckRDAP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdap+json")
+
+		switch r.URL.Path {
+		case "/203.0.113.0/24":
+			_, _ = w.Write([]byte(`{
+				"handle": "NET-203-0-113-0-1", "name": "ACME-NET",
+				"entities": [
+					{"roles": ["abuse"], "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["email", {}, "text", "Abuse@acme.example"]]]},
+					{"roles": ["registrant"], "entities": [
+						{"roles": ["technical"], "vcardArray": ["vcard", [["email", {}, "text", "old-noc@acme.example"]]]}
+					]}
+				]
+			}`))
+		case "/198.51.100.0/24":
+			_, _ = w.Write([]byte(`{"handle": "NET-198-51-100-0-1", "name": "LEGACY-NET", "entities": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewRDAPClient(server.Client())
+	client.endpoint = server.URL + "/"
+
+	cfg := &Config{
+		RDAPRanges:   "203.0.113.0/24,198.51.100.0/24,192.0.2.0/24",
+		RDAPNetname:  "^ACME-",
+		RDAPContacts: "abuse@acme.example,noc@acme.example",
+	}
+	assets := []ProcessedAsset{
+		{Name: "nat", IPAddress: "203.0.113.1"},
+		{Name: "vm", Attributes: map[string]string{"externalIPs": "203.0.113.2"}},
+	}
+
+	got := checkRDAP(t.Context(), slog.New(slog.DiscardHandler), client, cfg, assets)
+
+	want := []RDAPFinding{
+		{
+			Range: "203.0.113.0/24", Handle: "NET-203-0-113-0-1", Name: "ACME-NET",
+			Contacts:           []string{"abuse@acme.example", "old-noc@acme.example"},
+			AllocatedAddresses: 2,
+			Issues:             []string{"unexpected contact old-noc@acme.example"},
+		},
+		{
+			Range: "198.51.100.0/24", Handle: "NET-198-51-100-0-1", Name: "LEGACY-NET", Contacts: []string{},
+			Issues: []string{"no allocated addresses in the range", `name "LEGACY-NET" does not match "^ACME-"`},
+		},
+		{
+			Range:  "192.0.2.0/24",
+			Issues: []string{"no allocated addresses in the range", "RDAP lookup failed"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkRDAP() = %+v, want %+v", got, want)
+	}
+}
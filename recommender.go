@@ -0,0 +1,227 @@
+package assetwatcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"google.golang.org/api/option"
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+// idleAddressRecommenderID is the Recommender API recommender that detects idle addresses.
+// https://cloud.google.com/recommender/docs/recommenders#idle_ip_addresses
+const idleAddressRecommenderID = "google.compute.address.IdleResourceRecommender"
+
+// Agreement between the local idle address detection and the Recommender API.
+const (
+	agreementBoth         = "both"
+	agreementRecommender  = "recommender-only"
+	agreementAssetWatcher = "asset-watcher-only"
+)
+
+// RecommendationSummary compares the local idle address detection with the Recommender API.
+type RecommendationSummary struct {
+	Both                      int     `json:"both"`
+	RecommenderOnly           int     `json:"recommenderOnly"`
+	AssetWatcherOnly          int     `json:"assetWatcherOnly"`
+	RecommendedMonthlySavings float64 `json:"recommendedMonthlySavings"`
+}
+
+// Recommendation represents an idle address recommendation issued by the Recommender API.
+type Recommendation struct {
+	Name           string
+	Description    string
+	Priority       string
+	State          string
+	MonthlySavings float64
+	Resources      []string
+}
+
+// RecommendationFetcher is an interface for fetching idle address recommendations.
+type RecommendationFetcher interface {
+	FetchRecommendations(ctx context.Context, project, location string) ([]Recommendation, error)
+}
+
+// GoogleRecommendationFetcher is a Recommender API client and its configurations.
+type GoogleRecommendationFetcher struct {
+	service *recommender.Service
+	logger  *slog.Logger
+	cfg     *Config
+}
+
+// NewGoogleRecommendationFetcher creates a new Recommender API fetcher.
+func NewGoogleRecommendationFetcher(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg *Config,
+	opts ...option.ClientOption,
+) (*GoogleRecommendationFetcher, error) {
+	s, err := recommender.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recommender client: %w", err)
+	}
+
+	return &GoogleRecommendationFetcher{
+		service: s,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+		cfg:     cfg,
+	}, nil
+}
+
+// FetchRecommendations fetches the active idle address recommendations of a project in a location.
+func (f *GoogleRecommendationFetcher) FetchRecommendations(
+	ctx context.Context,
+	project, location string,
+) ([]Recommendation, error) {
+	parent := fmt.Sprintf("projects/%s/locations/%s/recommenders/%s", project, location, idleAddressRecommenderID)
+	recommendations := []Recommendation{}
+
+	err := f.service.Projects.Locations.Recommenders.Recommendations.List(parent).
+		Filter("stateInfo.state=ACTIVE").
+		Pages(ctx, func(resp *recommender.GoogleCloudRecommenderV1ListRecommendationsResponse) error {
+			for _, r := range resp.Recommendations {
+				recommendations = append(recommendations, convertRecommendation(r))
+			}
+
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recommendations for %s: %w", parent, err)
+	}
+
+	return recommendations, nil
+}
+
+func convertRecommendation(r *recommender.GoogleCloudRecommenderV1Recommendation) Recommendation {
+	recommendation := Recommendation{
+		Name:        r.Name,
+		Description: r.Description,
+		Priority:    r.Priority,
+		Resources:   r.TargetResources,
+	}
+
+	if r.StateInfo != nil {
+		recommendation.State = r.StateInfo.State
+	}
+
+	if r.PrimaryImpact != nil && r.PrimaryImpact.CostProjection != nil {
+		recommendation.MonthlySavings = monthlySavings(r.PrimaryImpact.CostProjection)
+	}
+
+	return recommendation
+}
+
+// monthlySavings converts a cost projection to the estimated monthly savings in USD.
+// The Recommender API reports savings as a negative cost over the projection duration.
+func monthlySavings(projection *recommender.GoogleCloudRecommenderV1CostProjection) float64 {
+	if projection.Cost == nil {
+		return 0
+	}
+
+	cost := float64(projection.Cost.Units) + float64(projection.Cost.Nanos)/1e9
+
+	duration, err := time.ParseDuration(projection.Duration)
+	if err != nil || duration <= 0 {
+		return math.Abs(cost)
+	}
+
+	return math.Abs(cost) * (hoursPerMonth * time.Hour).Hours() / duration.Hours()
+}
+
+// mergeRecommendations queries idle address recommendations for every project and
+// location of the assets and attaches them to the matching assets. Failures to
+// fetch recommendations for a project are logged and do not abort the run, as the
+// Recommender API is commonly disabled in some projects.
+func mergeRecommendations(
+	ctx context.Context,
+	logger *slog.Logger,
+	fetcher RecommendationFetcher,
+	assets []ProcessedAsset,
+) []ProcessedAsset {
+	type scope struct{ project, location string }
+
+	byResource := make(map[string]Recommendation)
+	seen := make(map[scope]bool)
+
+	for _, asset := range assets {
+		s := scope{project: asset.Project, location: asset.Location}
+		if seen[s] || asset.Project == "N/A" {
+			continue
+		}
+
+		seen[s] = true
+
+		recommendations, err := fetcher.FetchRecommendations(ctx, s.project, s.location)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to fetch recommendations",
+				slog.String("project", s.project),
+				slog.String("location", s.location),
+				slog.Any("error", err),
+			)
+
+			continue
+		}
+
+		for _, recommendation := range recommendations {
+			for _, resource := range recommendation.Resources {
+				byResource[resource] = recommendation
+			}
+		}
+	}
+
+	for i := range assets {
+		if recommendation, ok := byResource[assets[i].ResourceName]; ok {
+			assets[i].Recommendation = recommendation.Description
+			assets[i].RecommendedMonthlySavings = recommendation.MonthlySavings
+		}
+
+		assets[i].IdleAgreement = idleAgreement(assets[i])
+	}
+
+	logger.DebugContext(ctx, "Merged recommendations", slog.Int("number_of_recommendations", len(byResource)))
+
+	return assets
+}
+
+// idleAgreement reports whether an address is considered idle by asset-watcher,
+// by the Recommender API, or by both. It returns an empty string if neither
+// considers the address idle.
+func idleAgreement(asset ProcessedAsset) string {
+	recommended := asset.Recommendation != ""
+	idle := isIdleAddress(asset)
+
+	switch {
+	case recommended && idle:
+		return agreementBoth
+	case recommended:
+		return agreementRecommender
+	case idle:
+		return agreementAssetWatcher
+	default:
+		return ""
+	}
+}
+
+// summarizeRecommendations counts the agreement between the local idle address detection
+// and the Recommender API, and totals the savings estimated by the Recommender API.
+func summarizeRecommendations(assets []ProcessedAsset) RecommendationSummary {
+	summary := RecommendationSummary{}
+
+	for _, asset := range assets {
+		switch asset.IdleAgreement {
+		case agreementBoth:
+			summary.Both++
+		case agreementRecommender:
+			summary.RecommenderOnly++
+		case agreementAssetWatcher:
+			summary.AssetWatcherOnly++
+		}
+
+		summary.RecommendedMonthlySavings += asset.RecommendedMonthlySavings
+	}
+
+	return summary
+}
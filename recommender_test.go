@@ -0,0 +1,197 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	recommender "google.golang.org/api/recommender/v1"
+)
+
+var errRecommenderDisabled = errors.New("recommender API disabled")
+
+// fakeRecommendationFetcher is a mock implementation of the RecommendationFetcher.
+type fakeRecommendationFetcher struct {
+	recommendations map[string][]Recommendation
+	calls           []string
+}
+
+// FetchRecommendations returns the recommendations stored for the project.
+func (f *fakeRecommendationFetcher) FetchRecommendations(_ context.Context, project, location string) ([]Recommendation, error) {
+	f.calls = append(f.calls, project+"/"+location)
+
+	recommendations, ok := f.recommendations[project]
+	if !ok {
+		return nil, errRecommenderDisabled
+	}
+
+	return recommendations, nil
+}
+
+func TestMonthlySavings(t *testing.T) {
+	tests := []struct {
+		name       string
+		projection *recommender.GoogleCloudRecommenderV1CostProjection
+		want       float64
+	}{
+		{
+			name:       "30 days projection",
+			projection: &recommender.GoogleCloudRecommenderV1CostProjection{Cost: &recommender.GoogleTypeMoney{Units: -7, Nanos: -200000000}, Duration: "2592000s"},
+			want:       7.3,
+		},
+		{
+			name:       "unparsable duration",
+			projection: &recommender.GoogleCloudRecommenderV1CostProjection{Cost: &recommender.GoogleTypeMoney{Units: -5}, Duration: "month"},
+			want:       5,
+		},
+		{
+			name:       "no cost",
+			projection: &recommender.GoogleCloudRecommenderV1CostProjection{Duration: "2592000s"},
+			want:       0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := monthlySavings(tt.projection)
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("monthlySavings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRecommendations(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	fetcher := &fakeRecommendationFetcher{
+		recommendations: map[string][]Recommendation{
+			"proj-A": {
+				{
+					Description:    "Save cost by deleting idle address 'a1'.",
+					MonthlySavings: 7.3,
+					Resources:      []string{"//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/a1"},
+				},
+			},
+		},
+	}
+
+	assets := []ProcessedAsset{
+		{Name: "a1", Project: "proj-A", Location: "us-central1", ResourceName: "//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/a1"},
+		{Name: "a2", Project: "proj-A", Location: "us-central1", ResourceName: "//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/a2"},
+		{Name: "b1", Project: "proj-B", Location: "global", ResourceName: "//compute.googleapis.com/projects/proj-B/global/addresses/b1"},
+	}
+
+	got := mergeRecommendations(ctx, logger, fetcher, assets)
+
+	if len(fetcher.calls) != 2 {
+		t.Errorf("expected 2 recommendation queries, got %d: %v", len(fetcher.calls), fetcher.calls)
+	}
+
+	if got[0].Recommendation == "" || got[0].RecommendedMonthlySavings != 7.3 {
+		t.Errorf("expected recommendation to be merged into a1, got %+v", got[0])
+	}
+
+	if got[1].Recommendation != "" || got[2].Recommendation != "" {
+		t.Errorf("expected no recommendations for a2 and b1, got %+v and %+v", got[1], got[2])
+	}
+}
+
+func TestIdleAgreement(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset ProcessedAsset
+		want  string
+	}{
+		{name: "idle and recommended", asset: ProcessedAsset{Status: "RESERVED", Recommendation: "release"}, want: agreementBoth},
+		{name: "recommended only", asset: ProcessedAsset{Status: "IN_USE", Recommendation: "release"}, want: agreementRecommender},
+		{name: "idle only", asset: ProcessedAsset{Status: "RESERVED"}, want: agreementAssetWatcher},
+		{name: "internal reserved address", asset: ProcessedAsset{Status: "RESERVED", AddressType: "INTERNAL"}, want: ""},
+		{name: "neither", asset: ProcessedAsset{Status: "IN_USE"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idleAgreement(tt.asset); got != tt.want {
+				t.Errorf("idleAgreement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeRecommendations(t *testing.T) {
+	assets := []ProcessedAsset{
+		{IdleAgreement: agreementBoth, RecommendedMonthlySavings: 7.3},
+		{IdleAgreement: agreementBoth, RecommendedMonthlySavings: 7.3},
+		{IdleAgreement: agreementRecommender, RecommendedMonthlySavings: 7.3},
+		{IdleAgreement: agreementAssetWatcher},
+		{},
+	}
+
+	got := summarizeRecommendations(assets)
+
+	if got.Both != 2 || got.RecommenderOnly != 1 || got.AssetWatcherOnly != 1 {
+		t.Errorf("summarizeRecommendations() = %+v", got)
+	}
+
+	if diff := got.RecommendedMonthlySavings - 21.9; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("RecommendedMonthlySavings = %v, want 21.9", got.RecommendedMonthlySavings)
+	}
+}
+
+func TestFetchRecommendations_WithFakeServer(t *testing.T) {
+	var requestedPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"recommendations": [{
+			"name": "projects/1/locations/us-central1/recommenders/google.compute.address.IdleResourceRecommender/recommendations/r1",
+			"description": "Save cost by deleting idle address 'a1'.",
+			"priority": "P4",
+			"stateInfo": {"state": "ACTIVE"},
+			"targetResources": ["//compute.googleapis.com/projects/proj-A/regions/us-central1/addresses/a1"],
+			"primaryImpact": {"category": "COST", "costProjection": {"cost": {"currencyCode": "USD", "units": "-7", "nanos": -200000000}, "duration": "2592000s"}}
+		}]}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	fetcher, err := NewGoogleRecommendationFetcher(ctx, logger, &Config{},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewGoogleRecommendationFetcher failed: %v", err)
+	}
+
+	recommendations, err := fetcher.FetchRecommendations(ctx, "proj-A", "us-central1")
+	if err != nil {
+		t.Fatalf("FetchRecommendations failed: %v", err)
+	}
+
+	if !strings.Contains(requestedPath, "projects/proj-A/locations/us-central1/recommenders/"+idleAddressRecommenderID) {
+		t.Errorf("unexpected request path: %s", requestedPath)
+	}
+
+	if len(recommendations) != 1 {
+		t.Fatalf("expected 1 recommendation, got %d", len(recommendations))
+	}
+
+	if recommendations[0].State != "ACTIVE" || recommendations[0].Priority != "P4" {
+		t.Errorf("unexpected recommendation: %+v", recommendations[0])
+	}
+
+	if diff := recommendations[0].MonthlySavings - 7.3; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("MonthlySavings = %v, want 7.3", recommendations[0].MonthlySavings)
+	}
+}
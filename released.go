@@ -0,0 +1,82 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// releasedStatus is the status of an asset that disappeared between runs.
+const releasedStatus = "RELEASED"
+
+// trackReleased returns the assets released since the previous snapshot, along with the
+// assets it already tracked as released, marked RELEASED with the time they were first
+// missed. Released assets are kept for the retention, unless they reappear, and ordered
+// by project and name.
+func trackReleased(previous *Snapshot, current []ProcessedAsset, now time.Time, retention time.Duration) []ProcessedAsset {
+	if previous == nil {
+		return nil
+	}
+
+	found := make(map[string]bool, len(current))
+	for _, asset := range current {
+		found[assetKey(asset)] = true
+	}
+
+	released := []ProcessedAsset{}
+	tracked := make(map[string]bool, len(previous.Released))
+	cutoff := now.Add(-retention).UTC().Format(createdAtLayout)
+
+	for _, asset := range previous.Released {
+		key := assetKey(asset)
+		if found[key] || asset.ReleasedAt < cutoff {
+			continue
+		}
+
+		tracked[key] = true
+		released = append(released, asset)
+	}
+
+	releasedAt := now.UTC().Format(createdAtLayout)
+
+	for _, asset := range previous.Assets {
+		key := assetKey(asset)
+		if found[key] || tracked[key] {
+			continue
+		}
+
+		asset.Status = releasedStatus
+		asset.ReleasedAt = releasedAt
+		released = append(released, asset)
+	}
+
+	slices.SortStableFunc(released, func(a, b ProcessedAsset) int {
+		return cmp.Or(strings.Compare(a.Project, b.Project), strings.Compare(a.Name, b.Name))
+	})
+
+	return released
+}
+
+func outputReleasedTable(ctx context.Context, logger *slog.Logger, w io.Writer, released []ProcessedAsset) {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Released Address\tIP Address\tProject ID\tLocation\tReleased At")
+	_, _ = fmt.Fprintln(tw, "----------------\t----------\t----------\t--------\t-----------")
+
+	for _, asset := range released {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", asset.Name, asset.IPAddress, asset.Project, asset.Location,
+			asset.ReleasedAt)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to flush output", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
@@ -0,0 +1,69 @@
+package assetwatcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrackReleased(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	address := func(project, name string) ProcessedAsset {
+		return ProcessedAsset{
+			Name:         name,
+			Project:      project,
+			Status:       "RESERVED",
+			ResourceName: "//compute.googleapis.com/projects/" + project + "/regions/us-central1/addresses/" + name,
+		}
+	}
+
+	released := func(asset ProcessedAsset, releasedAt string) ProcessedAsset {
+		asset.Status = releasedStatus
+		asset.ReleasedAt = releasedAt
+
+		return asset
+	}
+
+	previous := &Snapshot{
+		Assets: []ProcessedAsset{address("proj-b", "kept"), address("proj-b", "gone"), address("proj-a", "gone")},
+		Released: []ProcessedAsset{
+			released(address("proj-a", "recent"), "2025-03-01 00:00:00"),
+			released(address("proj-a", "expired"), "2025-01-01 00:00:00"),
+			released(address("proj-c", "reserved-again"), "2025-03-01 00:00:00"),
+		},
+	}
+	current := []ProcessedAsset{address("proj-b", "kept"), address("proj-c", "reserved-again")}
+
+	got := trackReleased(previous, current, now, retention)
+	want := []ProcessedAsset{
+		released(address("proj-a", "gone"), "2025-03-10 12:00:00"),
+		released(address("proj-a", "recent"), "2025-03-01 00:00:00"),
+		released(address("proj-b", "gone"), "2025-03-10 12:00:00"),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("trackReleased() = %+v, want %+v", got, want)
+	}
+
+	if previous.Assets[1].Status != "RESERVED" {
+		t.Error("trackReleased() modified the previous snapshot")
+	}
+}
+
+func TestTrackReleased_KeepsFirstReleaseTime(t *testing.T) {
+	asset := ProcessedAsset{Name: "nat-1", Project: "proj-a", Status: releasedStatus, ReleasedAt: "2025-03-01 00:00:00"}
+	previous := &Snapshot{Released: []ProcessedAsset{asset}}
+
+	got := trackReleased(previous, nil, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), 30*24*time.Hour)
+	if len(got) != 1 || got[0].ReleasedAt != "2025-03-01 00:00:00" {
+		t.Errorf("trackReleased() = %+v, want the asset released at 2025-03-01", got)
+	}
+}
+
+func TestTrackReleased_NoPreviousSnapshot(t *testing.T) {
+	if got := trackReleased(nil, []ProcessedAsset{{Name: "nat-1"}}, time.Now(), time.Hour); got != nil {
+		t.Errorf("trackReleased() = %+v, want nil", got)
+	}
+}
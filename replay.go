@@ -0,0 +1,91 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// notifyCommand re-sends the notifications of a stored run.
+const notifyCommand = "notify"
+
+var (
+	errMissingRunID     = errors.New("--from-run is required")
+	errNoNotifiers      = errors.New("no notifiers are configured")
+	errReplayIncomplete = errors.New("some notifications could not be sent")
+)
+
+// runNotifyCommand re-renders and re-sends the notifications of a stored run with the notifiers
+// of the current configuration, e.g. after Slack was down or findings went to the wrong channel.
+// Unless --yes is set, the replay must be confirmed on stdin.
+func runNotifyCommand(
+	ctx context.Context,
+	logger *slog.Logger,
+	cfg *Config,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+) error {
+	flags := flag.NewFlagSet(notifyCommand, flag.ContinueOnError)
+	// The usage and the parse errors are kept out of the replay written to stdout.
+	flags.SetOutput(os.Stderr)
+	runID := flags.String("from-run", "", "ID of the stored run to re-send the notifications of")
+	yes := flags.Bool("yes", false, "re-send without asking for confirmation")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if *runID == "" {
+		return errMissingRunID
+	}
+
+	if cfg.HistoryDir == "" {
+		return errNoHistory
+	}
+
+	report, err := NewFileRunStore(cfg.HistoryDir).Load(ctx, *runID)
+	if err != nil {
+		return err
+	}
+
+	state := newStateStore(ctx, logger, cfg)
+	defer closeStore(ctx, logger, state)
+
+	sinks := newNotifierSinks(ctx, logger, cfg, state)
+	if len(sinks) == 0 {
+		return errNoNotifiers
+	}
+
+	names := make([]string, 0, len(sinks))
+	for _, sink := range sinks {
+		names = append(names, sink.Name())
+	}
+
+	if !*yes {
+		_, _ = fmt.Fprintf(stdout, "Re-send notifications of run %s from %s (%d violations, %d changes) to %s? [y/N] ",
+			report.Metadata.RunID, report.Metadata.StartedAt.Format("2006-01-02 15:04:05 MST"),
+			len(report.Violations), len(report.Diffs), strings.Join(names, ", "))
+
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			_, _ = fmt.Fprintln(stdout, "Cancelled")
+
+			return nil
+		}
+	}
+
+	if !publishToSinks(ctx, logger, sinks, report) {
+		return errReplayIncomplete
+	}
+
+	logger.InfoContext(ctx, "Re-sent notifications", slog.String("run_id", report.Metadata.RunID))
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunNotifyCommand(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := &Config{HistoryDir: t.TempDir(), WebhookURL: server.URL}
+	report := &Report{
+		Metadata:   RunMetadata{RunID: "run1"},
+		Violations: []RuleViolation{{Severity: severityHigh, Message: "exposed"}},
+	}
+
+	if err := NewFileRunStore(cfg.HistoryDir).Save(t.Context(), report); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantErr  error
+		requests int32
+	}{
+		{name: "missing run ID", args: nil, wantErr: errMissingRunID},
+		{name: "unknown run", args: []string{"--from-run", "run2", "--yes"}, wantErr: errRunNotFound},
+		{name: "declined", args: []string{"--from-run", "run1"}, stdin: "n\n"},
+		{name: "no answer", args: []string{"--from-run", "run1"}},
+		{name: "confirmed", args: []string{"--from-run", "run1"}, stdin: "y\n", requests: 1},
+		{name: "confirmed by flag", args: []string{"--from-run", "run1", "--yes"}, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+
+			var stdout bytes.Buffer
+
+			err := runNotifyCommand(t.Context(), logger, cfg, tt.args, strings.NewReader(tt.stdin), &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("expected %d notifications, got %d", tt.requests, got)
+			}
+		})
+	}
+}
+
+func TestRunNotifyCommand_NoNotifiers(t *testing.T) {
+	cfg := &Config{HistoryDir: t.TempDir()}
+	if err := NewFileRunStore(cfg.HistoryDir).Save(t.Context(), &Report{Metadata: RunMetadata{RunID: "run1"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err := runNotifyCommand(t.Context(), slog.New(slog.DiscardHandler), cfg,
+		[]string{"--from-run", "run1", "--yes"}, strings.NewReader(""), &bytes.Buffer{})
+	if !errors.Is(err, errNoNotifiers) {
+		t.Errorf("expected errNoNotifiers, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const runIDBytes = 8
+
+// DiffType is the kind of change detected for an asset between two runs.
+type DiffType string
+
+// Kinds of changes detected between two runs.
+const (
+	DiffAdded   DiffType = "added"
+	DiffRemoved DiffType = "removed"
+	DiffChanged DiffType = "changed"
+)
+
+// Report represents the result of a single run. It is produced by the pipeline
+// and consumed by all outputs, sinks, and notifiers.
+type Report struct {
+	Metadata   RunMetadata      `json:"metadata"`
+	Assets     []ProcessedAsset `json:"assets"`
+	Summary    Summary          `json:"summary"`
+	Diffs      []AssetDiff      `json:"diffs,omitempty"`
+	Violations []RuleViolation  `json:"violations,omitempty"`
+
+	Acknowledged []RuleViolation `json:"acknowledged,omitempty"`
+
+	UnscannableProjects []ProjectError     `json:"unscannableProjects,omitempty"`
+	DNS                 *DNSReconciliation `json:"dns,omitempty"`
+	Prefixes            []PrefixGroup      `json:"prefixes,omitempty"`
+	RDAP                []RDAPFinding      `json:"rdap,omitempty"`
+	Terraform           *TerraformDrift    `json:"terraform,omitempty"`
+
+	// Released are the assets that disappeared within ASSET_WATCHER_RELEASED_RETENTION_DAYS.
+	Released []ProcessedAsset `json:"released,omitempty"`
+}
+
+// RunMetadata describes the run that produced a report.
+type RunMetadata struct {
+	RunID      string    `json:"runId"`
+	OrgID      string    `json:"orgId"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit"`
+
+	// Partial is set on the report of a canceled scan, which only has the assets fetched
+	// before the cancellation.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Summary represents aggregated statistics of the reported assets.
+type Summary struct {
+	TotalAssets int            `json:"totalAssets"`
+	ByStatus    map[string]int `json:"byStatus"`
+	Cost        *CostSummary   `json:"cost,omitempty"`
+
+	Recommendations *RecommendationSummary `json:"recommendations,omitempty"`
+	Groups          *GroupSummary          `json:"groups,omitempty"`
+	Coverage        *CoverageSummary       `json:"coverage,omitempty"`
+	Baseline        *BaselineSummary       `json:"baseline,omitempty"`
+
+	Suppressed int `json:"suppressed,omitempty"`
+
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// AssetDiff represents a change of an asset between two runs.
+type AssetDiff struct {
+	Type     DiffType        `json:"type"`
+	Asset    ProcessedAsset  `json:"asset"`
+	Previous *ProcessedAsset `json:"previous,omitempty"`
+	Changes  []string        `json:"changes,omitempty"`
+}
+
+// RuleViolation represents an asset that violates a policy rule.
+type RuleViolation struct {
+	Rule     string         `json:"rule"`
+	Severity string         `json:"severity"`
+	Message  string         `json:"message"`
+	Asset    ProcessedAsset `json:"asset"`
+
+	Acknowledgment *Acknowledgment `json:"acknowledgment,omitempty"`
+}
+
+// NewReport creates a new report of the processed assets, identified by the run ID of the
+// context, or a new one if the context has none.
+func NewReport(ctx context.Context, cfg *Config, startedAt time.Time, assets []ProcessedAsset) *Report {
+	if assets == nil {
+		assets = []ProcessedAsset{}
+	}
+
+	report := &Report{
+		Metadata: RunMetadata{
+			RunID:      cmp.Or(runIDFromContext(ctx), newRunID()),
+			OrgID:      cfg.OrgID,
+			StartedAt:  startedAt.UTC(),
+			FinishedAt: time.Now().UTC(),
+			Version:    Version,
+			Commit:     Commit,
+		},
+		Assets:     assets,
+		Summary:    summarize(assets),
+		Violations: detectViolations(assets),
+	}
+
+	if cfg.ShowCost {
+		costSummary := summarizeCosts(assets)
+		report.Summary.Cost = &costSummary
+	}
+
+	if cfg.ShowRecommendations {
+		recommendationSummary := summarizeRecommendations(assets)
+		report.Summary.Recommendations = &recommendationSummary
+	}
+
+	if cfg.ClassificationRules != "" {
+		report.Summary.Categories = summarizeCategories(assets)
+	}
+
+	if cfg.GroupBy != "" {
+		// The dimension is validated by GetConfig.
+		if groupSummary, err := summarizeGroups(assets, cfg.GroupBy, cfg.ShowCost); err == nil {
+			report.Summary.Groups = &groupSummary
+		}
+	}
+
+	return report
+}
+
+// summarize aggregates the number of assets per status.
+func summarize(assets []ProcessedAsset) Summary {
+	byStatus := make(map[string]int)
+	for _, asset := range assets {
+		byStatus[asset.Status]++
+	}
+
+	return Summary{TotalAssets: len(assets), ByStatus: byStatus}
+}
+
+// WriteJSON serializes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	return nil
+}
+
+// ReadReport deserializes a report previously written by WriteJSON.
+func ReadReport(r io.Reader) (*Report, error) {
+	report := &Report{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	return report, nil
+}
+
+func newRunID() string {
+	b := make([]byte, runIDBytes)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,71 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewReport(t *testing.T) {
+	startedAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	assets := []ProcessedAsset{
+		{Name: "a1", Project: "proj-A", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+		{Name: "a2", Project: "proj-A", Status: "IN_USE"},
+		{Name: "a3", Project: "proj-B", Status: "IN_USE"},
+	}
+
+	t.Run("without cost", func(t *testing.T) {
+		report := NewReport(t.Context(), &Config{OrgID: "test-org"}, startedAt, assets)
+
+		if report.Metadata.OrgID != "test-org" || !report.Metadata.StartedAt.Equal(startedAt) {
+			t.Errorf("unexpected metadata: %+v", report.Metadata)
+		}
+
+		if report.Metadata.RunID == "" {
+			t.Error("expected a run ID to be generated")
+		}
+
+		want := Summary{TotalAssets: 3, ByStatus: map[string]int{"RESERVED": 1, "IN_USE": 2}}
+		if !reflect.DeepEqual(report.Summary, want) {
+			t.Errorf("Summary = %+v, want %+v", report.Summary, want)
+		}
+	})
+
+	t.Run("with cost", func(t *testing.T) {
+		report := NewReport(t.Context(), &Config{ShowCost: true}, startedAt, assets)
+
+		if report.Summary.Cost == nil || report.Summary.Cost.IdleAddresses != 1 {
+			t.Errorf("expected cost summary with 1 idle address, got %+v", report.Summary.Cost)
+		}
+	})
+
+	t.Run("nil assets", func(t *testing.T) {
+		report := NewReport(t.Context(), &Config{}, startedAt, nil)
+
+		if report.Assets == nil {
+			t.Error("expected assets to be an empty slice, got nil")
+		}
+	})
+}
+
+func TestReport_JSONRoundTrip(t *testing.T) {
+	report := NewReport(t.Context(), &Config{OrgID: "test-org", ShowCost: true}, time.Now(), []ProcessedAsset{
+		{Name: "a1", Project: "proj-A", Status: "RESERVED", EstimatedMonthlyCost: 7.3},
+	})
+	report.Diffs = []AssetDiff{{Type: DiffAdded, Asset: report.Assets[0]}}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	got, err := ReadReport(&buf)
+	if err != nil {
+		t.Fatalf("ReadReport failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, report) {
+		t.Errorf("ReadReport() = %+v, want %+v", got, report)
+	}
+}
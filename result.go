@@ -0,0 +1,209 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a run in the result file.
+const (
+	resultStatusSuccess    = "success"
+	resultStatusViolations = "violations"
+	resultStatusThreshold  = "threshold"
+	resultStatusChanges    = "changes"
+	resultStatusError      = "error"
+	resultStatusCrash      = "crash"
+	resultStatusCanceled   = "canceled"
+)
+
+// exitConditionStatuses are the statuses and error classes of the runs exiting on a condition
+// of the exit-code policy.
+var exitConditionStatuses = map[string]struct{ status, errorClass string }{
+	exitConditionViolations: {status: resultStatusViolations, errorClass: "policy_violation"},
+	exitConditionThreshold:  {status: resultStatusThreshold, errorClass: "threshold_exceeded"},
+	exitConditionChanges:    {status: resultStatusChanges, errorClass: "changes_detected"},
+}
+
+// RunResult summarizes the outcome of a run, so that orchestrators such as Airflow or Cloud
+// Workflows can branch on a structured result instead of parsing logs.
+type RunResult struct {
+	RunID           string        `json:"runId"`
+	Status          string        `json:"status"`
+	ExitCode        int           `json:"exitCode"`
+	ErrorClass      string        `json:"errorClass,omitempty"`
+	StartedAt       time.Time     `json:"startedAt"`
+	FinishedAt      time.Time     `json:"finishedAt"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	Version         string        `json:"version"`
+	Counts          *ResultCounts `json:"counts,omitempty"`
+}
+
+// ResultCounts are the counts of the report of a run.
+type ResultCounts struct {
+	Assets              int `json:"assets"`
+	Violations          int `json:"violations"`
+	Acknowledged        int `json:"acknowledged"`
+	Suppressed          int `json:"suppressed"`
+	Changes             int `json:"changes"`
+	UnscannableProjects int `json:"unscannableProjects"`
+}
+
+// runOutcome tracks what the result file of a run reports.
+type runOutcome struct {
+	cfg       *Config
+	startedAt time.Time
+	report    atomic.Pointer[Report]
+	decision  atomic.Pointer[exitDecision]
+
+	// discarded is set when the result file is written by other runs, such as the scans of
+	// the daemon.
+	discarded atomic.Bool
+}
+
+type outcomeContextKey struct{}
+
+// withRunOutcome returns a context tracking the outcome of the run, written to
+// ASSET_WATCHER_RESULT_PATH when the run exits.
+func withRunOutcome(ctx context.Context, cfg *Config, startedAt time.Time) context.Context {
+	return context.WithValue(ctx, outcomeContextKey{}, &runOutcome{cfg: cfg, startedAt: startedAt})
+}
+
+func outcomeFromContext(ctx context.Context) *runOutcome {
+	outcome, _ := ctx.Value(outcomeContextKey{}).(*runOutcome)
+
+	return outcome
+}
+
+// discardRunOutcome stops the run of the context from writing the result file when it exits.
+func discardRunOutcome(ctx context.Context) {
+	if outcome := outcomeFromContext(ctx); outcome != nil {
+		outcome.discarded.Store(true)
+	}
+}
+
+// recordReport records the report of the run of the context, whose counts are written to
+// the result file.
+func recordReport(ctx context.Context, report *Report) {
+	if outcome := outcomeFromContext(ctx); outcome != nil {
+		outcome.report.Store(report)
+	}
+}
+
+// recordExitDecision records the condition of the exit-code policy the run of the context
+// exits on, whose status is written to the result file, as the exit codes are configurable.
+func recordExitDecision(ctx context.Context, decision exitDecision) {
+	if outcome := outcomeFromContext(ctx); outcome != nil {
+		outcome.decision.Store(&decision)
+	}
+}
+
+// exit writes the result file of the run of the context, if ASSET_WATCHER_RESULT_PATH is
+// set, and exits with the code. It replaces os.Exit in the run, as deferred functions do
+// not run on os.Exit. A run embedded by Watcher.Run unwinds to it instead.
+func exit(ctx context.Context, code int) {
+	// A run failing as it was canceled, by a signal or the timeout, exits as canceled.
+	if code == 1 && ctx.Err() != nil {
+		code = exitCodeCanceled
+	}
+
+	if isEmbeddedRun(ctx) {
+		panic(embeddedExit{code: code})
+	}
+
+	writeRunResult(ctx, code)
+	os.Exit(code)
+}
+
+// finishRun writes the result file of a run that returns without exiting. It must be
+// deferred by main.
+func finishRun(ctx context.Context) {
+	writeRunResult(ctx, 0)
+}
+
+// writeRunResult writes the result file of the run, logging the failures, as the run exits
+// anyway.
+func writeRunResult(ctx context.Context, code int) {
+	outcome := outcomeFromContext(ctx)
+	if outcome == nil || outcome.cfg.ResultPath == "" || outcome.discarded.Load() {
+		return
+	}
+
+	logger := newLogger(outcome.cfg, os.Stderr)
+	result := newRunResult(ctx, outcome, code, time.Now())
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to encode the run result", slog.Any("error", err))
+
+		return
+	}
+
+	path := outcome.cfg.ResultPath
+
+	if strings.HasPrefix(path, gcsScheme) {
+		bucket, object, _ := strings.Cut(strings.TrimPrefix(path, gcsScheme), "/")
+		err = uploadJSON(ctx, bucket, object, data, clientOptionsFor(ctx, logger, outcome.cfg, credentialsStorage)...)
+	} else if err = os.WriteFile(path, data, 0o600); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to write the run result", slog.Any("error", err))
+	}
+}
+
+// newRunResult returns the result of a run exiting with the code. The error class of a
+// failed run is the stage of the pipeline it failed in, such as fetch_error.
+func newRunResult(ctx context.Context, outcome *runOutcome, code int, finishedAt time.Time) RunResult {
+	result := RunResult{
+		RunID:           runIDFromContext(ctx),
+		ExitCode:        code,
+		StartedAt:       outcome.startedAt.UTC(),
+		FinishedAt:      finishedAt.UTC(),
+		DurationSeconds: finishedAt.Sub(outcome.startedAt).Seconds(),
+		Version:         Version,
+	}
+
+	stage := stageFromContext(ctx)
+
+	decision := outcome.decision.Load()
+
+	switch {
+	case code == 0:
+		result.Status = resultStatusSuccess
+	case decision != nil && decision.code == code:
+		result.Status = exitConditionStatuses[decision.condition].status
+		result.ErrorClass = exitConditionStatuses[decision.condition].errorClass
+	case code == exitCodeViolations:
+		result.Status = resultStatusViolations
+		result.ErrorClass = "policy_violation"
+	case code == exitCodeCrash:
+		result.Status = resultStatusCrash
+		result.ErrorClass = stage + "_crash"
+	case code == exitCodeCanceled:
+		result.Status = resultStatusCanceled
+		result.ErrorClass = stage + "_canceled"
+	default:
+		result.Status = resultStatusError
+		result.ErrorClass = stage + "_error"
+	}
+
+	if report := outcome.report.Load(); report != nil {
+		result.Counts = &ResultCounts{
+			Assets:              len(report.Assets),
+			Violations:          len(report.Violations),
+			Acknowledged:        len(report.Acknowledged),
+			Suppressed:          report.Summary.Suppressed,
+			Changes:             len(report.Diffs),
+			UnscannableProjects: len(report.UnscannableProjects),
+		}
+	}
+
+	return result
+}
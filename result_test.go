@@ -0,0 +1,91 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewRunResult(t *testing.T) {
+	startedAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(90 * time.Second)
+
+	tests := []struct {
+		name       string
+		code       int
+		stage      string
+		wantStatus string
+		wantClass  string
+		decision   *exitDecision
+	}{
+		{name: "success", code: 0, stage: stagePublish, wantStatus: resultStatusSuccess},
+		{name: "violations", code: exitCodeViolations, stage: stagePublish, wantStatus: resultStatusViolations, wantClass: "policy_violation"},
+		{
+			name: "threshold", code: 11, stage: stagePublish, wantStatus: resultStatusThreshold, wantClass: "threshold_exceeded",
+			decision: &exitDecision{condition: exitConditionThreshold, code: 11},
+		},
+		{name: "error", code: 1, stage: stageFetch, wantStatus: resultStatusError, wantClass: "fetch_error"},
+		{name: "crash", code: exitCodeCrash, stage: stageEnrich, wantStatus: resultStatusCrash, wantClass: "enrich_crash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withRunProgress(withRunID(t.Context(), "run-1"))
+			ctx = withRunOutcome(ctx, &Config{}, startedAt)
+			setStage(ctx, tt.stage)
+
+			if tt.decision != nil {
+				recordExitDecision(ctx, *tt.decision)
+			}
+
+			result := newRunResult(ctx, outcomeFromContext(ctx), tt.code, finishedAt)
+
+			if result.Status != tt.wantStatus || result.ErrorClass != tt.wantClass || result.ExitCode != tt.code {
+				t.Errorf("newRunResult() = %s, %s, %d, want %s, %s, %d",
+					result.Status, result.ErrorClass, result.ExitCode, tt.wantStatus, tt.wantClass, tt.code)
+			}
+
+			if result.RunID != "run-1" || result.DurationSeconds != 90 {
+				t.Errorf("newRunResult() run = %s, %v, want run-1, 90", result.RunID, result.DurationSeconds)
+			}
+
+			if result.Counts != nil {
+				t.Errorf("newRunResult() counts = %+v, want none without a report", result.Counts)
+			}
+		})
+	}
+}
+
+func TestWriteRunResult(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+
+	ctx := withRunProgress(withRunID(t.Context(), "run-1"))
+	ctx = withRunOutcome(ctx, &Config{ResultPath: path}, time.Now())
+
+	recordReport(ctx, &Report{
+		Assets:       []ProcessedAsset{{Name: "nat-1"}, {Name: "nat-2"}},
+		Violations:   []RuleViolation{{Rule: "orphaned-address"}},
+		Acknowledged: []RuleViolation{{Rule: "external-ip"}},
+		Diffs:        []AssetDiff{{Type: DiffAdded}},
+		Summary:      Summary{Suppressed: 3},
+	})
+
+	finishRun(ctx)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+
+	var result RunResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+
+	want := ResultCounts{Assets: 2, Violations: 1, Acknowledged: 1, Suppressed: 3, Changes: 1}
+	if result.Status != resultStatusSuccess || result.Counts == nil || *result.Counts != want {
+		t.Errorf("result = %s, %+v, want %s, %+v", result.Status, result.Counts, resultStatusSuccess, want)
+	}
+}
@@ -0,0 +1,289 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Route 53 is a global service signed in us-east-1.
+// https://docs.aws.amazon.com/general/latest/gr/r53.html
+const (
+	route53Endpoint = "https://route53.amazonaws.com"
+	route53Region   = "us-east-1"
+	route53Service  = "route53"
+
+	awsAmzDateLayout = "20060102T150405Z"
+)
+
+var (
+	errMissingAWSCredentials = errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	errRoute53RequestFailed  = errors.New("request to Route 53 failed")
+)
+
+// awsCredentials are the static credentials used to sign AWS requests.
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// awsCredentialsFor returns the AWS credentials of the route53 component of
+// ASSET_WATCHER_CREDENTIALS, or those of the standard environment variables.
+func awsCredentialsFor(cfg *Config) (awsCredentials, error) {
+	sources, err := parseCredentials(cfg.Credentials)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	if source, ok := sources[credentialsRoute53]; ok {
+		return awsCredentialsFromFile(source.file)
+	}
+
+	return awsCredentialsFromEnv()
+}
+
+// awsCredentialsFromFile reads the AWS credentials from a JSON file of the credential_process
+// format, such as the output of aws configure export-credentials --format process.
+// https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sourcing-external.html
+func awsCredentialsFromFile(path string) (awsCredentials, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read AWS credentials file: %w", err)
+	}
+
+	var file struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		SessionToken    string `json:"SessionToken"`
+	}
+
+	if err := json.Unmarshal(b, &file); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse AWS credentials file %s: %w", path, err)
+	}
+
+	if file.AccessKeyID == "" || file.SecretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("%w: %s has no AccessKeyId or SecretAccessKey", errInvalidCredentials, path)
+	}
+
+	return awsCredentials{
+		accessKeyID:     file.AccessKeyID,
+		secretAccessKey: file.SecretAccessKey,
+		sessionToken:    file.SessionToken,
+	}, nil
+}
+
+// awsCredentialsFromEnv reads the AWS credentials from the standard environment variables.
+func awsCredentialsFromEnv() (awsCredentials, error) {
+	creds := awsCredentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	if creds.accessKeyID == "" || creds.secretAccessKey == "" {
+		return awsCredentials{}, errMissingAWSCredentials
+	}
+
+	return creds, nil
+}
+
+// signAWSRequest signs a request without a body with AWS Signature Version 4.
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func signAWSRequest(req *http.Request, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format(awsAmzDateLayout)
+	scope := amzDate[:8] + "/" + region + "/" + service + "/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		if name := strings.ToLower(key); name == "x-amz-date" || name == "x-amz-security-token" {
+			headers[name] = strings.Join(values, ",")
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+	emptyPayloadHash := sha256.Sum256(nil)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(emptyPayloadHash[:]),
+	}, "\n")
+
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	key := []byte("AWS4" + creds.secretAccessKey)
+	for _, part := range []string{amzDate[:8], region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+hex.EncodeToString(hmacSHA256(key, stringToSign)))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// route53RecordSets is the response of ListResourceRecordSets.
+// https://docs.aws.amazon.com/Route53/latest/APIReference/API_ListResourceRecordSets.html
+type route53RecordSets struct {
+	RecordSets []struct {
+		Name    string   `xml:"Name"`
+		Type    string   `xml:"Type"`
+		Records []string `xml:"ResourceRecords>ResourceRecord>Value"`
+	} `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated          bool   `xml:"IsTruncated"`
+	NextRecordName       string `xml:"NextRecordName"`
+	NextRecordType       string `xml:"NextRecordType"`
+	NextRecordIdentifier string `xml:"NextRecordIdentifier"`
+}
+
+// Route53Provider lists the address records of Route 53 hosted zones.
+type Route53Provider struct {
+	client   *http.Client
+	endpoint string
+	creds    awsCredentials
+	zones    []string
+	logger   *slog.Logger
+}
+
+// NewRoute53Provider creates a new Route 53 provider for the configured hosted zones,
+// authenticated with the AWS credentials of ASSET_WATCHER_CREDENTIALS or the environment.
+func NewRoute53Provider(logger *slog.Logger, cfg *Config, client *http.Client) (*Route53Provider, error) {
+	creds, err := awsCredentialsFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Route53Provider{
+		client:   client,
+		endpoint: route53Endpoint,
+		creds:    creds,
+		zones:    splitString(cfg.Route53Zones, ","),
+		logger:   logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (p *Route53Provider) Name() string {
+	return "route53"
+}
+
+// ListRecords lists the A and AAAA records of all hosted zones. Alias records point at
+// AWS resources rather than addresses and are skipped.
+func (p *Route53Provider) ListRecords(ctx context.Context) ([]DNSRecord, error) {
+	records := []DNSRecord{}
+
+	for _, zone := range p.zones {
+		query := url.Values{}
+
+		for {
+			page, err := p.listRecordSets(ctx, zone, query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list records of hosted zone %s: %w", zone, err)
+			}
+
+			for _, rrset := range page.RecordSets {
+				if rrset.Type != "A" && rrset.Type != "AAAA" {
+					continue
+				}
+
+				for _, address := range rrset.Records {
+					records = append(records, DNSRecord{
+						Name: rrset.Name, Type: rrset.Type, Address: address, Zone: p.Name() + ":" + zone,
+					})
+				}
+			}
+
+			if !page.IsTruncated {
+				break
+			}
+
+			query = url.Values{"name": {page.NextRecordName}, "type": {page.NextRecordType}}
+			if page.NextRecordIdentifier != "" {
+				query.Set("identifier", page.NextRecordIdentifier)
+			}
+		}
+	}
+
+	p.logger.DebugContext(ctx, "Listed Route 53 records", slog.Int("number_of_records", len(records)))
+
+	return records, nil
+}
+
+func (p *Route53Provider) listRecordSets(ctx context.Context, zone string, query url.Values) (*route53RecordSets, error) {
+	endpoint := p.endpoint + "/2013-04-01/hostedzone/" + url.PathEscape(strings.TrimPrefix(zone, "/hostedzone/")) + "/rrset"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	signAWSRequest(req, p.creds, route53Region, route53Service, time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: %s", errRoute53RequestFailed, resp.Status,
+			bytes.TrimSpace(body[:min(len(body), maxErrorBodyBytes)]))
+	}
+
+	page := &route53RecordSets{}
+	if err := xml.Unmarshal(body, page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,133 @@
+package assetwatcher
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSignAWSRequest(t *testing.T) {
+	// The get-vanilla case of the AWS Signature Version 4 test suite.
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	creds := awsCredentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signAWSRequest(req, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s, want %s", got, want)
+	}
+}
+
+func TestRoute53Provider_ListRecords(t *testing.T) {
+	pages := map[string]string{
+		"": `<ListResourceRecordSetsResponse><ResourceRecordSets>
+			<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type>
+				<ResourceRecords><ResourceRecord><Value>203.0.113.1</Value></ResourceRecord></ResourceRecords>
+			</ResourceRecordSet>
+			<ResourceRecordSet><Name>example.com.</Name><Type>TXT</Type>
+				<ResourceRecords><ResourceRecord><Value>"v=spf1 -all"</Value></ResourceRecord></ResourceRecords>
+			</ResourceRecordSet>
+			<ResourceRecordSet><Name>lb.example.com.</Name><Type>A</Type>
+				<AliasTarget><DNSName>lb.elb.amazonaws.com.</DNSName></AliasTarget>
+			</ResourceRecordSet>
+		</ResourceRecordSets><IsTruncated>true</IsTruncated>
+		<NextRecordName>v6.example.com.</NextRecordName><NextRecordType>AAAA</NextRecordType>
+		</ListResourceRecordSetsResponse>`,
+		"v6.example.com.": `<ListResourceRecordSetsResponse><ResourceRecordSets>
+			<ResourceRecordSet><Name>v6.example.com.</Name><Type>AAAA</Type>
+				<ResourceRecords><ResourceRecord><Value>2001:db8::1</Value></ResourceRecord></ResourceRecords>
+			</ResourceRecordSet>
+		</ResourceRecordSets><IsTruncated>false</IsTruncated></ListResourceRecordSetsResponse>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2013-04-01/hostedzone/Z123/rrset" || r.Header.Get("Authorization") == "" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("name")]))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	provider, err := NewRoute53Provider(slog.New(slog.DiscardHandler), &Config{Route53Zones: "Z123"}, server.Client())
+	if err != nil {
+		t.Fatalf("NewRoute53Provider failed: %v", err)
+	}
+
+	provider.endpoint = server.URL
+
+	records, err := provider.ListRecords(t.Context())
+	if err != nil {
+		t.Fatalf("ListRecords failed: %v", err)
+	}
+
+	want := []DNSRecord{
+		{Name: "www.example.com.", Type: "A", Address: "203.0.113.1", Zone: "route53:Z123"},
+		{Name: "v6.example.com.", Type: "AAAA", Address: "2001:db8::1", Zone: "route53:Z123"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("ListRecords() = %+v, want %+v", records, want)
+	}
+}
+
+func TestNewRoute53Provider_CredentialsFile(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+
+	dir := t.TempDir()
+
+	keyFile := filepath.Join(dir, "aws.json")
+	if err := os.WriteFile(keyFile, []byte(`{"Version": 1, "AccessKeyId": "AKIDFILE", "SecretAccessKey": "file-secret",
+		"SessionToken": "token"}`), 0o600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+
+	cfg := &Config{Route53Zones: "Z123", Credentials: "route53=" + keyFile}
+
+	provider, err := NewRoute53Provider(slog.New(slog.DiscardHandler), cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewRoute53Provider failed: %v", err)
+	}
+
+	want := awsCredentials{accessKeyID: "AKIDFILE", secretAccessKey: "file-secret", sessionToken: "token"}
+	if provider.creds != want {
+		t.Errorf("credentials = %+v, want those of the file %+v", provider.creds, want)
+	}
+
+	incomplete := filepath.Join(dir, "incomplete.json")
+	if err := os.WriteFile(incomplete, []byte(`{"AccessKeyId": "AKIDFILE"}`), 0o600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+
+	cfg.Credentials = "route53=" + incomplete
+
+	if _, err := NewRoute53Provider(slog.New(slog.DiscardHandler), cfg, http.DefaultClient); !errors.Is(err, errInvalidCredentials) {
+		t.Errorf("expected %v for an incomplete file, got %v", errInvalidCredentials, err)
+	}
+}
+
+func TestNewRoute53Provider_MissingCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	if _, err := NewRoute53Provider(slog.New(slog.DiscardHandler), &Config{Route53Zones: "Z123"}, http.DefaultClient); err == nil {
+		t.Error("expected an error without AWS credentials")
+	}
+}
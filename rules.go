@@ -0,0 +1,242 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule actions.
+const (
+	ruleActionAllow = "allow"
+	ruleActionDeny  = "deny"
+)
+
+const createdAtLayout = "2006-01-02 15:04:05"
+
+var (
+	errInvalidRuleAction = errors.New("invalid rule action")
+	errInvalidAge        = errors.New("invalid age")
+)
+
+// Rule is a filtering rule of the rules file. All conditions of a rule must match
+// for the rule to apply. Empty conditions match any asset.
+type Rule struct {
+	Name    string            `yaml:"name"`
+	Action  string            `yaml:"action"`
+	Project string            `yaml:"project"`
+	Labels  map[string]string `yaml:"labels"`
+	CIDRs   []string          `yaml:"cidrs"`
+	States  []string          `yaml:"states"`
+	MinAge  string            `yaml:"minAge"`
+	MaxAge  string            `yaml:"maxAge"`
+}
+
+// RuleSet is an ordered list of allow and deny rules. The first matching rule
+// decides whether an asset is kept; assets matching no rule get the default action.
+type RuleSet struct {
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+
+	compiled []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+
+	project *regexp.Regexp
+	cidrs   []netip.Prefix
+	minAge  time.Duration
+	maxAge  time.Duration
+}
+
+// LoadRules reads and validates a YAML rules file. An empty path results in
+// a nil rule set that allows all assets.
+func LoadRules(path string) (*RuleSet, error) {
+	if path == "" {
+		return nil, nil //nolint:nilnil // No rules file is configured.
+	}
+
+	f, err := os.Open(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rules file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	ruleSet := &RuleSet{}
+	if err := decoder.Decode(ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+
+	if err := ruleSet.compile(); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	return ruleSet, nil
+}
+
+func (rs *RuleSet) compile() error {
+	if rs.Default == "" {
+		rs.Default = ruleActionAllow
+	}
+
+	if rs.Default != ruleActionAllow && rs.Default != ruleActionDeny {
+		return fmt.Errorf("%w: default %q, expected allow or deny", errInvalidRuleAction, rs.Default)
+	}
+
+	rs.compiled = make([]compiledRule, 0, len(rs.Rules))
+
+	for i, rule := range rs.Rules {
+		name := rule.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(i+1)
+		}
+
+		c, err := compileRule(rule)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+
+		rs.compiled = append(rs.compiled, c)
+	}
+
+	return nil
+}
+
+func compileRule(rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+
+	if rule.Action != ruleActionAllow && rule.Action != ruleActionDeny {
+		return c, fmt.Errorf("%w: %q, expected allow or deny", errInvalidRuleAction, rule.Action)
+	}
+
+	var err error
+
+	if rule.Project != "" {
+		if c.project, err = regexp.Compile(rule.Project); err != nil {
+			return c, fmt.Errorf("invalid project regex: %w", err)
+		}
+	}
+
+	for _, cidr := range rule.CIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return c, fmt.Errorf("invalid CIDR: %w", err)
+		}
+
+		c.cidrs = append(c.cidrs, prefix.Masked())
+	}
+
+	if c.minAge, err = parseAge(rule.MinAge); err != nil {
+		return c, fmt.Errorf("invalid minAge: %w", err)
+	}
+
+	if c.maxAge, err = parseAge(rule.MaxAge); err != nil {
+		return c, fmt.Errorf("invalid maxAge: %w", err)
+	}
+
+	return c, nil
+}
+
+// allows reports whether the asset is kept by the rule set.
+func (rs *RuleSet) allows(asset ProcessedAsset, now time.Time) bool {
+	if rs == nil {
+		return true
+	}
+
+	for _, rule := range rs.compiled {
+		if rule.matches(asset, now) {
+			return rule.Action == ruleActionAllow
+		}
+	}
+
+	return rs.Default == ruleActionAllow
+}
+
+func (r compiledRule) matches(asset ProcessedAsset, now time.Time) bool {
+	if r.project != nil && !r.project.MatchString(asset.Project) {
+		return false
+	}
+
+	if !matchesAllLabels(asset.Labels, r.Labels) {
+		return false
+	}
+
+	if len(r.States) > 0 && !slices.Contains(r.States, asset.Status) {
+		return false
+	}
+
+	if len(r.cidrs) > 0 && !containsAddress(r.cidrs, asset.IPAddress) {
+		return false
+	}
+
+	if r.minAge > 0 || r.maxAge > 0 {
+		age, ok := assetAge(asset, now)
+		if !ok || (r.minAge > 0 && age < r.minAge) || (r.maxAge > 0 && age > r.maxAge) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsAddress(prefixes []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseAge parses a duration that, in addition to the time.ParseDuration units,
+// accepts a number of days such as 90d. An empty string results in a zero duration.
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%w: %q", errInvalidAge, s)
+		}
+
+		return time.Duration(n) * hoursPerDay * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %q", errInvalidAge, s)
+	}
+
+	return d, nil
+}
+
+// assetAge returns the time elapsed since the asset was created.
+func assetAge(asset ProcessedAsset, now time.Time) (time.Duration, bool) {
+	createdAt, err := time.Parse(createdAtLayout, asset.CreatedAt)
+	if err != nil {
+		return 0, false
+	}
+
+	return now.Sub(createdAt), true
+}
@@ -0,0 +1,131 @@
+package assetwatcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRulesFile writes the rules to a temporary file and returns its path.
+func writeRulesFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rules file: %v", err)
+	}
+
+	return path
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{s: "", want: 0},
+		{s: "90d", want: 90 * 24 * time.Hour},
+		{s: "36h", want: 36 * time.Hour},
+		{s: "-1d", wantErr: true},
+		{s: "ninety days", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseAge(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: "rules:\n  - action: deny\n    zone: a\n"},
+		{name: "invalid action", content: "rules:\n  - action: drop\n"},
+		{name: "invalid default", content: "default: maybe\n"},
+		{name: "invalid CIDR", content: "rules:\n  - action: deny\n    cidrs: [10.0.0.0/33]\n"},
+		{name: "invalid age", content: "rules:\n  - action: deny\n    minAge: old\n"},
+		{name: "invalid project regex", content: "rules:\n  - action: deny\n    project: \"(\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadRules(writeRulesFile(t, tt.content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := LoadRules(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestRuleSet_Allows(t *testing.T) {
+	rules, err := LoadRules(writeRulesFile(t, `
+default: deny
+rules:
+  - name: ignore-sandboxes
+    action: deny
+    project: "^sandbox-"
+  - name: ignore-reviewed
+    action: deny
+    labels:
+      asset-watcher-ignore: "true"
+  - name: old-reserved-addresses
+    action: allow
+    states: [RESERVED]
+    minAge: 90d
+  - name: corporate-ranges
+    action: allow
+    cidrs: [203.0.113.0/24]
+`))
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		asset ProcessedAsset
+		want  bool
+	}{
+		{name: "sandbox project", asset: ProcessedAsset{Project: "sandbox-alice", Status: "RESERVED", CreatedAt: "2023-01-01 00:00:00"}, want: false},
+		{name: "ignored label", asset: ProcessedAsset{Project: "prod", Status: "RESERVED", CreatedAt: "2023-01-01 00:00:00", Labels: map[string]string{"asset-watcher-ignore": "true"}}, want: false},
+		{name: "old reserved address", asset: ProcessedAsset{Project: "prod", Status: "RESERVED", CreatedAt: "2023-01-01 00:00:00"}, want: true},
+		{name: "recent reserved address", asset: ProcessedAsset{Project: "prod", Status: "RESERVED", CreatedAt: "2024-05-01 00:00:00"}, want: false},
+		{name: "corporate range", asset: ProcessedAsset{Project: "prod", Status: "IN_USE", IPAddress: "203.0.113.5"}, want: true},
+		{name: "default action", asset: ProcessedAsset{Project: "prod", Status: "IN_USE", IPAddress: "198.51.100.5"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.allows(tt.asset, now); got != tt.want {
+				t.Errorf("allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSet_NilAllowsAll(t *testing.T) {
+	rules, err := LoadRules("")
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	if !rules.allows(ProcessedAsset{}, time.Now()) {
+		t.Error("expected a nil rule set to allow all assets")
+	}
+}
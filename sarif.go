@@ -0,0 +1,240 @@
+package assetwatcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// outputFormatSARIF renders the policy violations and the dangling DNS records as a SARIF
+// 2.1.0 log, for GitHub code scanning and other static analysis result consumers.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const outputFormatSARIF = "sarif"
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// sarifFingerprint is the key of the partial fingerprint identifying a finding across runs,
+	// so code scanning tracks it as the same alert.
+	sarifFingerprint = "assetWatcherFinding/v1"
+
+	// ruleDanglingDNSRecord reports DNS records pointing to addresses no longer allocated,
+	// which can be taken over by whoever gets the address next.
+	ruleDanglingDNSRecord = "dangling-dns-record"
+)
+
+// sarifRules describes the rules of the findings. Violations of other rules are described by
+// their ID only.
+var sarifRules = map[string]string{
+	ruleOrphanedExternalAddress: "External address reserved but not used by any resource",
+	ruleInstanceExternalIP:      "Instance directly exposed on an external IP address",
+	ruleBlocklistedAddress:      "Address listed on a blocklist",
+	ruleInternetExposedPort:     "Asset reachable from the internet",
+	ruleOutOfBandAddress:        "Address outside of the approved ranges",
+	ruleDanglingDNSRecord:       "DNS record pointing to an address that is not allocated",
+}
+
+// sarifSeverities maps the severities to the SARIF levels and to the security severity
+// scores GitHub ranks code scanning alerts by.
+var sarifSeverities = map[string]struct{ level, score string }{
+	severityHigh:   {level: "error", score: "8.0"},
+	severityMedium: {level: "warning", score: "5.0"},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool              `json:"tool"`
+	AutomationDetails sarifAutomationDetails `json:"automationDetails"`
+	Results           []sarifResult          `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	SecuritySeverity string   `json:"security-severity"`
+	Tags             []string `json:"tags"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifFinding is a finding of the report with the resource it is located at.
+type sarifFinding struct {
+	rule     string
+	severity string
+	message  string
+	resource string
+}
+
+// sarifFindings returns the policy violations and the dangling DNS records of the report.
+// Acknowledged violations are not reported.
+func sarifFindings(report *Report) []sarifFinding {
+	findings := make([]sarifFinding, 0, len(report.Violations))
+
+	for _, v := range report.Violations {
+		resource := v.Asset.ResourceName
+		if resource == "" {
+			resource = v.Asset.Project + "/" + v.Asset.Location + "/" + v.Asset.Name
+		}
+
+		findings = append(findings, sarifFinding{rule: v.Rule, severity: v.Severity, message: v.Message, resource: resource})
+	}
+
+	if report.DNS != nil {
+		for _, record := range report.DNS.DanglingRecords {
+			findings = append(findings, sarifFinding{
+				rule:     ruleDanglingDNSRecord,
+				severity: severityHigh,
+				message: fmt.Sprintf("%s record %s points to %s, which is not allocated to any asset",
+					record.Type, record.Name, record.Address),
+				resource: "dns/" + record.Zone + "/" + record.Name + "/" + record.Type,
+			})
+		}
+	}
+
+	return findings
+}
+
+// newSARIFLog converts the findings of the report to a SARIF log with a single run. The rules
+// are listed in the order of their first finding.
+func newSARIFLog(report *Report) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "asset-watcher",
+			Version:        Version,
+			InformationURI: "https://github.com/andreygrechin/asset-watcher",
+			Rules:          []sarifRule{},
+		}},
+		// Code scanning keeps the alerts of every organization apart.
+		AutomationDetails: sarifAutomationDetails{ID: "asset-watcher/" + report.Metadata.OrgID + "/"},
+		Results:           []sarifResult{},
+	}
+
+	ruleIndexes := map[string]int{}
+
+	for _, finding := range sarifFindings(report) {
+		severity, ok := sarifSeverities[finding.severity]
+		if !ok {
+			severity = sarifSeverities[severityMedium]
+		}
+
+		index, ok := ruleIndexes[finding.rule]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[finding.rule] = index
+
+			description := sarifRules[finding.rule]
+			if description == "" {
+				description = finding.rule
+			}
+
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:                   finding.rule,
+				ShortDescription:     sarifMessage{Text: description},
+				DefaultConfiguration: sarifConfiguration{Level: severity.level},
+				Properties:           sarifRuleProperties{SecuritySeverity: severity.score, Tags: []string{"security"}},
+			})
+		}
+
+		fingerprint := sha256.Sum256([]byte(finding.rule + ":" + finding.resource))
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    finding.rule,
+			RuleIndex: index,
+			Level:     severity.level,
+			Message:   sarifMessage{Text: finding.message},
+			Locations: []sarifLocation{{
+				// Code scanning requires a physical location; the resource name stands in for a path.
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: strings.TrimPrefix(finding.resource, "//")},
+				},
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: finding.resource, Kind: "resource"}},
+			}},
+			PartialFingerprints: map[string]string{sarifFingerprint: hex.EncodeToString(fingerprint[:])},
+		})
+	}
+
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+// writeSARIF writes the findings of the report as a SARIF log.
+func writeSARIF(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(newSARIFLog(report)); err != nil {
+		return fmt.Errorf("failed to encode SARIF log: %w", err)
+	}
+
+	return nil
+}
+
+func outputSARIF(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report) {
+	if err := writeSARIF(w, report); err != nil {
+		logger.ErrorContext(ctx, "failed to write SARIF", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
@@ -0,0 +1,87 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	asset := ProcessedAsset{
+		Name:         "spare",
+		Project:      "prod",
+		IPAddress:    "203.0.113.11",
+		ResourceName: "//compute.googleapis.com/projects/prod/regions/us-central1/addresses/spare",
+	}
+
+	report := &Report{
+		Metadata: RunMetadata{OrgID: "123"},
+		Violations: []RuleViolation{
+			{Rule: ruleOrphanedExternalAddress, Severity: severityMedium, Message: "reserved but not used", Asset: asset},
+			{Rule: ruleOutOfBandAddress, Severity: severityHigh, Message: "outside of the approved ranges", Asset: asset},
+		},
+		DNS: &DNSReconciliation{DanglingRecords: []DanglingRecord{
+			{Name: "old.example.com.", Type: "A", Address: "198.51.100.7", Zone: "example-com"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, report); err != nil {
+		t.Fatalf("writeSARIF failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("failed to decode the SARIF log: %v", err)
+	}
+
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log %+v", log)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 || len(run.Results) != 3 {
+		t.Fatalf("expected 3 rules and 3 results, got %d and %d", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+
+	tests := []struct {
+		ruleID string
+		level  string
+		uri    string
+	}{
+		{ruleID: ruleOrphanedExternalAddress, level: "warning", uri: "compute.googleapis.com/projects/prod/regions/us-central1/addresses/spare"},
+		{ruleID: ruleOutOfBandAddress, level: "error", uri: "compute.googleapis.com/projects/prod/regions/us-central1/addresses/spare"},
+		{ruleID: ruleDanglingDNSRecord, level: "error", uri: "dns/example-com/old.example.com./A"},
+	}
+
+	for i, tt := range tests {
+		result := run.Results[i]
+
+		if result.RuleID != tt.ruleID || result.Level != tt.level ||
+			run.Tool.Driver.Rules[result.RuleIndex].ID != tt.ruleID ||
+			result.Locations[0].PhysicalLocation.ArtifactLocation.URI != tt.uri {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+	}
+
+	// Findings are identified across runs by their rule and resource.
+	if run.Results[0].PartialFingerprints[sarifFingerprint] == run.Results[1].PartialFingerprints[sarifFingerprint] {
+		t.Errorf("expected distinct fingerprints for distinct rules")
+	}
+
+	if run.AutomationDetails.ID != "asset-watcher/123/" {
+		t.Errorf("unexpected automation ID %q", run.AutomationDetails.ID)
+	}
+}
+
+func TestWriteSARIF_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, &Report{}); err != nil {
+		t.Fatalf("writeSARIF failed: %v", err)
+	}
+
+	// Code scanning closes the alerts of a run without results, so the results must be an array.
+	if !bytes.Contains(buf.Bytes(), []byte(`"results": []`)) {
+		t.Errorf("expected empty results, got %s", buf.String())
+	}
+}
@@ -0,0 +1,120 @@
+package assetwatcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"google.golang.org/api/option"
+	securitycenter "google.golang.org/api/securitycenter/v1"
+)
+
+// sccFindingIDLength is the maximum length of a Security Command Center finding ID.
+const sccFindingIDLength = 32
+
+var (
+	sccSourcePattern    = regexp.MustCompile(`^organizations/[0-9]+/sources/[0-9]+$`)
+	errInvalidSCCSource = errors.New("invalid Security Command Center source")
+)
+
+// validateSCCSource checks that the source is in the organizations/ORG_ID/sources/SOURCE_ID format.
+func validateSCCSource(source string) error {
+	if !sccSourcePattern.MatchString(source) {
+		return fmt.Errorf("%w: %q, expected organizations/ORG_ID/sources/SOURCE_ID", errInvalidSCCSource, source)
+	}
+
+	return nil
+}
+
+// SCCSink publishes policy violations as Security Command Center findings.
+type SCCSink struct {
+	service *securitycenter.Service
+	source  string
+	logger  *slog.Logger
+}
+
+// NewSCCSink creates a new Security Command Center sink for the configured source.
+func NewSCCSink(ctx context.Context, logger *slog.Logger, cfg *Config, opts ...option.ClientOption) (*SCCSink, error) {
+	if err := validateSCCSource(cfg.SCCSource); err != nil {
+		return nil, err
+	}
+
+	s, err := securitycenter.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Security Command Center client: %w", err)
+	}
+
+	return &SCCSink{
+		service: s,
+		source:  cfg.SCCSource,
+		logger:  logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// Name returns the name of the sink.
+func (s *SCCSink) Name() string {
+	return "scc"
+}
+
+// Publish creates or updates a finding for every policy violation of the report.
+// Findings are keyed by rule and resource, so repeated runs update existing findings.
+func (s *SCCSink) Publish(ctx context.Context, report *Report) error {
+	for _, violation := range report.Violations {
+		finding, err := newSCCFinding(violation, report.Metadata)
+		if err != nil {
+			return err
+		}
+
+		name := s.source + "/findings/" + sccFindingID(violation)
+
+		if _, err := s.service.Organizations.Sources.Findings.Patch(name, finding).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to publish finding %s: %w", name, err)
+		}
+	}
+
+	s.logger.DebugContext(ctx, "Published findings", slog.Int("number_of_findings", len(report.Violations)))
+
+	return nil
+}
+
+// Close is a no-op, as the REST client does not hold any resources.
+func (s *SCCSink) Close() error {
+	return nil
+}
+
+func newSCCFinding(violation RuleViolation, metadata RunMetadata) (*securitycenter.Finding, error) {
+	properties, err := json.Marshal(map[string]string{
+		"message":   violation.Message,
+		"project":   violation.Asset.Project,
+		"location":  violation.Asset.Location,
+		"ipAddress": violation.Asset.IPAddress,
+		"status":    violation.Asset.Status,
+		"runId":     metadata.RunID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal finding properties: %w", err)
+	}
+
+	return &securitycenter.Finding{
+		State:            "ACTIVE",
+		Category:         violation.Rule,
+		Severity:         violation.Severity,
+		FindingClass:     "MISCONFIGURATION",
+		ResourceName:     violation.Asset.ResourceName,
+		Description:      violation.Message,
+		EventTime:        metadata.FinishedAt.Format("2006-01-02T15:04:05Z07:00"),
+		SourceProperties: properties,
+	}, nil
+}
+
+// sccFindingID returns a stable finding ID for the violation.
+func sccFindingID(violation RuleViolation) string {
+	sum := sha256.Sum256([]byte(violation.Rule + "/" + violation.Asset.ResourceName))
+
+	return hex.EncodeToString(sum[:])[:sccFindingIDLength]
+}
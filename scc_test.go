@@ -0,0 +1,131 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestValidateSCCSource(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr bool
+	}{
+		{source: "organizations/123/sources/456", wantErr: false},
+		{source: "projects/123/sources/456", wantErr: true},
+		{source: "organizations/123", wantErr: true},
+		{source: "organizations/abc/sources/456", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			if err := validateSCCSource(tt.source); (err != nil) != tt.wantErr {
+				t.Errorf("validateSCCSource(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSCCFindingID(t *testing.T) {
+	v1 := RuleViolation{Rule: ruleOrphanedExternalAddress, Asset: ProcessedAsset{ResourceName: "//compute/a1"}}
+	v2 := RuleViolation{Rule: ruleOrphanedExternalAddress, Asset: ProcessedAsset{ResourceName: "//compute/a2"}}
+
+	id := sccFindingID(v1)
+	if len(id) != sccFindingIDLength {
+		t.Errorf("expected finding ID of length %d, got %q", sccFindingIDLength, id)
+	}
+
+	if id != sccFindingID(v1) {
+		t.Error("expected finding ID to be stable")
+	}
+
+	if id == sccFindingID(v2) {
+		t.Error("expected different finding IDs for different resources")
+	}
+}
+
+func TestSCCSink_Publish(t *testing.T) {
+	type request struct {
+		method, path string
+		body         map[string]any
+	}
+
+	var requests []request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		req := request{method: r.Method, path: r.URL.Path}
+		_ = json.Unmarshal(body, &req.body)
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	sink, err := NewSCCSink(ctx, logger, &Config{SCCSource: "organizations/123/sources/456"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewSCCSink failed: %v", err)
+	}
+
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run-1", FinishedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Violations: []RuleViolation{
+			{
+				Rule:     ruleOrphanedExternalAddress,
+				Severity: severityMedium,
+				Message:  "External address 203.0.113.1 is reserved but not used by any resource",
+				Asset:    ProcessedAsset{ResourceName: "//compute.googleapis.com/projects/p/regions/r/addresses/a1", IPAddress: "203.0.113.1"},
+			},
+		},
+	}
+
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	got := requests[0]
+	if got.method != http.MethodPatch {
+		t.Errorf("expected PATCH request, got %s", got.method)
+	}
+
+	wantPath := "/v1/organizations/123/sources/456/findings/" + sccFindingID(report.Violations[0])
+	if got.path != wantPath {
+		t.Errorf("expected path %s, got %s", wantPath, got.path)
+	}
+
+	if got.body["category"] != ruleOrphanedExternalAddress || got.body["severity"] != severityMedium ||
+		got.body["state"] != "ACTIVE" || got.body["eventTime"] != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected finding: %v", got.body)
+	}
+
+	properties, _ := got.body["sourceProperties"].(map[string]any)
+	if properties["runId"] != "run-1" || properties["ipAddress"] != "203.0.113.1" {
+		t.Errorf("unexpected source properties: %v", properties)
+	}
+}
+
+func TestNewSCCSink_InvalidSource(t *testing.T) {
+	_, err := NewSCCSink(t.Context(), slog.New(slog.DiscardHandler), &Config{SCCSource: "sources/1"})
+	if err == nil || !strings.Contains(err.Error(), "invalid Security Command Center source") {
+		t.Errorf("expected invalid source error, got %v", err)
+	}
+}
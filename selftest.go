@@ -0,0 +1,279 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// selftestCommand runs the pipeline against embedded fakes to validate an installation.
+const selftestCommand = "selftest"
+
+const selftestOrgID = "000000000000"
+
+var errSelftestFailed = errors.New("self-test failed")
+
+// selftestServices replaces the Google Cloud clients and the state store of a run, so that the
+// self-test runs the pipeline against the embedded fakes only.
+type selftestServices struct {
+	clientOptions []option.ClientOption
+	stateStore    StateStore
+}
+
+type selftestServicesContextKey struct{}
+
+func withSelftestServices(ctx context.Context, services *selftestServices) context.Context {
+	return context.WithValue(ctx, selftestServicesContextKey{}, services)
+}
+
+func selftestServicesFromContext(ctx context.Context) *selftestServices {
+	services, _ := ctx.Value(selftestServicesContextKey{}).(*selftestServices)
+
+	return services
+}
+
+// selftestAssetServer is the embedded Cloud Asset API serving the fixture assets.
+type selftestAssetServer struct {
+	assetpb.UnimplementedAssetServiceServer
+
+	mu     sync.Mutex
+	assets []*assetpb.ResourceSearchResult
+}
+
+// SearchAllResources returns the fixture assets of the requested asset type.
+func (s *selftestAssetServer) SearchAllResources(
+	_ context.Context, req *assetpb.SearchAllResourcesRequest,
+) (*assetpb.SearchAllResourcesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &assetpb.SearchAllResourcesResponse{}
+
+	for _, asset := range s.assets {
+		if len(req.GetAssetTypes()) == 0 || slices.Contains(req.GetAssetTypes(), asset.GetAssetType()) {
+			resp.Results = append(resp.Results, asset)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *selftestAssetServer) setAssets(assets []*assetpb.ResourceSearchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.assets = assets
+}
+
+// selftestAddress returns a fixture address of the embedded asset server.
+func selftestAddress(project, name, status, ip string) *assetpb.ResourceSearchResult {
+	return &assetpb.ResourceSearchResult{
+		Name:                   "//compute.googleapis.com/projects/" + project + "/regions/us-central1/addresses/" + name,
+		AssetType:              "compute.googleapis.com/Address",
+		DisplayName:            name,
+		Location:               "us-central1",
+		State:                  status,
+		CreateTime:             timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		ParentAssetType:        "cloudresourcemanager.googleapis.com/Project",
+		ParentFullResourceName: "//cloudresourcemanager.googleapis.com/projects/" + project,
+		AdditionalAttributes: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"address":     structpb.NewStringValue(ip),
+			"addressType": structpb.NewStringValue("EXTERNAL"),
+		}},
+	}
+}
+
+// memoryStateStore keeps the state in memory, for the duration of the self-test.
+type memoryStateStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+// Get returns the value of the key, or nil if it is not set.
+func (s *memoryStateStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key], nil
+}
+
+// Put sets the value of the key.
+func (s *memoryStateStore) Put(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+
+	return nil
+}
+
+// webhookReceiver records the notifications posted to the embedded webhook.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	payloads []webhookPayload
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var payload webhookPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.payloads = append(r.payloads, payload)
+}
+
+func (r *webhookReceiver) received() []webhookPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.payloads)
+}
+
+// selftest runs the checks and writes their results.
+type selftest struct {
+	out    io.Writer
+	failed int
+}
+
+func (s *selftest) check(name string, ok bool, format string, args ...any) {
+	result := "PASS"
+	if !ok {
+		result = "FAIL"
+		s.failed++
+	}
+
+	_, _ = fmt.Fprintf(s.out, "%s  %-14s %s\n", result, name, fmt.Sprintf(format, args...))
+}
+
+// runSelftestCommand spins up an embedded Cloud Asset API, webhook receiver, and in-memory
+// state store, runs two full pipeline cycles against them, and verifies the reports, the
+// outputs, the change detection, and the notifications. It neither reads the configuration
+// of the installation nor touches any production API, so it can validate a deployment.
+func runSelftestCommand(ctx context.Context, logger *slog.Logger, cfg *Config, out io.Writer) error {
+	assetServer := &selftestAssetServer{assets: []*assetpb.ResourceSearchResult{
+		selftestAddress("selftest-prod", "web", addressStatusInUse, "203.0.113.10"),
+		selftestAddress("selftest-prod", "spare", addressStatusReserved, "203.0.113.11"),
+		selftestAddress("selftest-dev", "nat", addressStatusInUse, "198.51.100.20"),
+	}}
+
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", "localhost:0")
+	if err != nil {
+		return fmt.Errorf("failed to start the embedded asset server: %w", err)
+	}
+
+	grpcServer := grpc.NewServer()
+	assetpb.RegisterAssetServiceServer(grpcServer, assetServer)
+
+	go func() { _ = grpcServer.Serve(listener) }()
+	defer grpcServer.Stop()
+
+	receiver := &webhookReceiver{}
+	webhook := httptest.NewServer(receiver)
+	defer webhook.Close()
+
+	ctx = withSelftestServices(ctx, &selftestServices{
+		clientOptions: []option.ClientOption{
+			option.WithEndpoint(listener.Addr().String()),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		},
+		stateStore: &memoryStateStore{values: map[string][]byte{}},
+	})
+
+	// Only the settings of the self-test are used; the state store is replaced by the
+	// in-memory one.
+	selftestCfg := ConfigDefaults
+	selftestCfg.OrgID = selftestOrgID
+	selftestCfg.Profile = cfg.Profile
+	selftestCfg.UserAgent = cfg.UserAgent
+	selftestCfg.StateStore = "memory"
+	selftestCfg.WebhookURL = webhook.URL
+	watcher := &Watcher{cfg: selftestCfg, logger: logger}
+
+	s := &selftest{out: out}
+
+	first, err := watcher.Run(ctx)
+	s.check("first run", err == nil, "%v", errorOr(err, "completed"))
+
+	if first != nil {
+		s.check("fetch", len(first.Assets) == len(assetServer.assets),
+			"%d of %d assets fetched from the embedded asset server", len(first.Assets), len(assetServer.assets))
+		s.check("snapshot", len(first.Diffs) == 0, "%d changes on the first run, which only creates the snapshot",
+			len(first.Diffs))
+
+		var buf bytes.Buffer
+
+		err := first.WriteJSON(&buf)
+		if err == nil {
+			_, err = ReadReport(&buf)
+		}
+
+		s.check("output", err == nil, "%v", errorOr(err, "the JSON report round-trips"))
+	}
+
+	notified := len(receiver.received())
+
+	// The second run sees a released and a new address.
+	assetServer.setAssets([]*assetpb.ResourceSearchResult{
+		selftestAddress("selftest-prod", "web", addressStatusInUse, "203.0.113.10"),
+		selftestAddress("selftest-dev", "nat", addressStatusInUse, "198.51.100.20"),
+		selftestAddress("selftest-dev", "egress", addressStatusInUse, "198.51.100.21"),
+	})
+
+	second, err := watcher.Run(ctx)
+	s.check("second run", err == nil, "%v", errorOr(err, "completed"))
+
+	if second != nil {
+		s.check("changes", len(second.Diffs) == 2, "%d changes detected against the in-memory snapshot, want 2",
+			len(second.Diffs))
+	}
+
+	payloads := receiver.received()[notified:]
+	items := []string{}
+
+	for _, payload := range payloads {
+		items = append(items, payload.Items...)
+	}
+
+	joined := strings.Join(items, "\n")
+	s.check("notification", len(payloads) == 1 && strings.Contains(joined, "egress") && strings.Contains(joined, "spare"),
+		"%d notifications received by the embedded webhook with %d items", len(payloads), len(items))
+
+	if s.failed > 0 {
+		return fmt.Errorf("%w: %d checks failed", errSelftestFailed, s.failed)
+	}
+
+	return nil
+}
+
+// errorOr returns the error message, or the text if there is no error.
+func errorOr(err error, text string) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return text
+}
@@ -0,0 +1,20 @@
+package assetwatcher
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRunSelftestCommand(t *testing.T) {
+	var out strings.Builder
+
+	cfg := ConfigDefaults
+	if err := runSelftestCommand(t.Context(), slog.New(slog.DiscardHandler), &cfg, &out); err != nil {
+		t.Fatalf("runSelftestCommand failed: %v\n%s", err, out.String())
+	}
+
+	if strings.Contains(out.String(), "FAIL") || strings.Count(out.String(), "PASS") != 7 {
+		t.Errorf("expected 7 passed checks, got:\n%s", out.String())
+	}
+}
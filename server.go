@@ -0,0 +1,180 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serveCommand runs asset-watcher as an HTTP server.
+const serveCommand = "serve"
+
+const (
+	defaultListenAddress = ":8080"
+	readHeaderTimeout    = 10 * time.Second
+	shutdownTimeout      = 10 * time.Second
+	redactedValue        = "REDACTED"
+)
+
+// requestIDPattern matches the request IDs accepted from clients.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// serve runs the HTTP server until it fails, or until the context is canceled, when it shuts
+// down gracefully. With ASSET_WATCHER_INTERVAL, it scans the organization on the schedule of
+// the daemon mode and serves the inventory of the latest scan.
+func serve(ctx context.Context, logger *slog.Logger, cfg *Config) error {
+	schedule, err := parseDaemonSchedule(cfg)
+	if err != nil {
+		return err
+	}
+
+	var (
+		cache *inventoryCache
+		scans sync.WaitGroup
+	)
+
+	// The stores are shared by the scans and the Slack actions, and closed once both stop.
+	stores := newRunStores(ctx, logger, cfg)
+	defer closeRunStores(ctx, logger, stores)
+
+	if schedule.interval > 0 {
+		// The scans write their own result file.
+		discardRunOutcome(ctx)
+
+		cache = &inventoryCache{}
+
+		scansCtx, cancelScans := context.WithCancel(ctx)
+
+		scans.Add(1)
+
+		go func() {
+			defer scans.Done()
+
+			runInventoryScans(scansCtx, logger, cfg, schedule, stores, cache)
+		}()
+
+		// The running scan is canceled whenever the server stops, including when it fails to
+		// listen, before waiting for it.
+		defer scans.Wait()
+		defer cancelScans()
+	}
+
+	server := &http.Server{
+		Addr:              cfg.ListenAddress,
+		Handler:           withRequestIDs(logger, newServeMux(logger, cfg, cache, stores.state)),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	shutdown := make(chan error, 1)
+	stop := context.AfterFunc(ctx, func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		shutdown <- server.Shutdown(ctx)
+	})
+
+	defer stop()
+
+	logger.InfoContext(ctx, "Listening", slog.String("address", cfg.ListenAddress))
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	if err := <-shutdown; err != nil {
+		return fmt.Errorf("failed to shut down the server: %w", err)
+	}
+
+	logger.InfoContext(ctx, "Shut down the server", slog.Any("cause", context.Cause(ctx)))
+
+	return nil
+}
+
+// newServeMux returns the handler of the read-only API, including the inventory of the cache if
+// any, and, with a Slack signing secret, of the action buttons of the Slack notifications, which
+// acknowledge the violations in the state store.
+func newServeMux(logger *slog.Logger, cfg *Config, cache *inventoryCache, state StateStore) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v1/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(r.Context(), logger, w, effectiveConfig(cfg))
+	})
+
+	if cache != nil {
+		registerInventoryAPI(mux, logger, cfg, cache)
+	}
+
+	if cfg.HistoryDir != "" {
+		mux.HandleFunc("GET /metrics", metricsHandler(logger, cfg))
+	}
+
+	if cfg.SlackSigningSecret != "" {
+		mux.HandleFunc("POST /v1/slack/actions",
+			slackActionsHandler(logger, cfg.SlackSigningSecret, state, newHTTPClient(cfg)))
+	}
+
+	return mux
+}
+
+// withRequestIDs identifies every request by the ID of its X-Request-Id header, if valid,
+// or a new one, which is added to the logs and outbound requests of the request and
+// returned in the X-Request-Id response header.
+func withRequestIDs(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRunID()
+		}
+
+		ctx := withRequestID(r.Context(), requestID)
+		w.Header().Set(requestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		logger.DebugContext(ctx, "Served request", slog.String("method", r.Method), slog.String("path", r.URL.Path))
+	})
+}
+
+// effectiveConfig returns the configuration keyed by environment variable, with the values
+// of the fields tagged as secret redacted, so it can be shown to operators.
+func effectiveConfig(cfg *Config) map[string]any {
+	effective := make(map[string]any)
+	v := reflect.ValueOf(*cfg)
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		if name == "" {
+			continue
+		}
+
+		value := v.Field(i).Interface()
+		if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+			value = redactedValue
+		}
+
+		effective[name] = value
+	}
+
+	return effective
+}
+
+func writeJSON(ctx context.Context, logger *slog.Logger, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		logger.ErrorContext(ctx, "failed to write response", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,121 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.SlackToken = "xoxb-secret"
+	cfg.SlackChannel = "#alerts"
+
+	effective := effectiveConfig(&cfg)
+
+	tests := map[string]any{
+		"ASSET_WATCHER_ORG_ID":                  "123",
+		"ASSET_WATCHER_SLACK_TOKEN":             redactedValue,
+		"ASSET_WATCHER_SLACK_CHANNEL":           "#alerts",
+		"ASSET_WATCHER_WEBHOOK_URL":             "",
+		"ASSET_WATCHER_SHOW_COST":               false,
+		"ASSET_WATCHER_FLOW_LOGS_LOOKBACK_DAYS": defaultFlowLogsLookbackDays,
+	}
+
+	for name, want := range tests {
+		if got := effective[name]; got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestServeMux_Config(t *testing.T) {
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.WebhookURL = "https://hooks.example.com/secret-path"
+
+	mux := newServeMux(slog.New(slog.DiscardHandler), &cfg, nil, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/config", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	effective := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &effective); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+
+	if effective["ASSET_WATCHER_WEBHOOK_URL"] != redactedValue || effective["ASSET_WATCHER_ORG_ID"] != "123" {
+		t.Errorf("unexpected configuration %v", effective)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/config", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for a POST request, got %d", rec.Code)
+	}
+}
+
+func TestWithRequestIDs(t *testing.T) {
+	var seen []string
+
+	handler := withRequestIDs(slog.New(slog.DiscardHandler), http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = append(seen, requestIDFromContext(r.Context()))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/config", nil)
+	req.Header.Set(requestIDHeader, "client-id.1")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen[0] != "client-id.1" || rec.Header().Get(requestIDHeader) != "client-id.1" {
+		t.Errorf("expected the client request ID, got %q and header %q", seen[0], rec.Header().Get(requestIDHeader))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/config", nil)
+	req.Header.Set(requestIDHeader, "invalid id\n")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen[1] == "" || seen[1] == "invalid id\n" || rec.Header().Get(requestIDHeader) != seen[1] {
+		t.Errorf("expected a new request ID, got %q and header %q", seen[1], rec.Header().Get(requestIDHeader))
+	}
+}
+
+func TestServe_ListenFailureWithInterval(t *testing.T) {
+	listener, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	cfg := ConfigDefaults
+	cfg.OrgID = "123"
+	cfg.ListenAddress = listener.Addr().String()
+	cfg.Interval = "1h"
+
+	ctx := withRunOutcome(withFakeAssetServer(t), &cfg, time.Now())
+	done := make(chan error, 1)
+
+	go func() { done <- serve(ctx, slog.New(slog.DiscardHandler), &cfg) }()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("serve() should fail when the listen address is taken")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("serve() did not return after failing to listen")
+	}
+}
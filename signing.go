@@ -0,0 +1,38 @@
+package assetwatcher
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var errInvalidSigningKey = errors.New("invalid signing key, expected a PEM encoded PKCS #8 Ed25519 private key")
+
+// loadSigningKey reads the report-signing key, such as one generated by
+// `openssl genpkey -algorithm ed25519`.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errInvalidSigningKey
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidSigningKey, err)
+	}
+
+	ed25519Key, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errInvalidSigningKey
+	}
+
+	return ed25519Key, nil
+}
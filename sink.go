@@ -0,0 +1,45 @@
+package assetwatcher
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Sink is an interface for publishing reports to external systems.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, report *Report) error
+	Close() error
+}
+
+// publishToSinks publishes the report to every sink. A failing sink does not prevent
+// the report from being published to the others. It reports whether all sinks succeeded.
+func publishToSinks(ctx context.Context, logger *slog.Logger, sinks []Sink, report *Report) bool {
+	ok := true
+
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, report); err != nil {
+			logger.ErrorContext(ctx, "failed to publish report",
+				slog.String("sink", sink.Name()),
+				slog.Any("error", err),
+			)
+
+			ok = false
+
+			continue
+		}
+
+		logger.DebugContext(ctx, "Published report", slog.String("sink", sink.Name()))
+	}
+
+	return ok
+}
+
+// closeSinks closes every sink, logging failures.
+func closeSinks(ctx context.Context, logger *slog.Logger, sinks []Sink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			logger.ErrorContext(ctx, "failed to close sink", slog.String("sink", sink.Name()), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Slack limits. Messages longer than 40,000 characters are rejected with msg_too_long,
+// and Slack recommends keeping the text under 4,000 characters.
+// https://api.slack.com/methods/chat.postMessage#truncating
+const (
+	slackPostMessageURL     = "https://slack.com/api/chat.postMessage"
+	slackMaxMessageLength   = 3500
+	slackMaxItemsPerMessage = 50
+	slackMaxMessages        = 5
+
+	// slackFooterLength is the length reserved for the footer of a truncated notification.
+	slackFooterLength = 512
+
+	bulletPrefix = "• "
+)
+
+// slackResponse is the common response of the Slack Web API.
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// slackPostMessageResponse is the response of chat.postMessage, with the ID of the channel and
+// the timestamp identifying the posted message.
+type slackPostMessageResponse struct {
+	slackResponse
+
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// SlackNotifier posts notifications to a Slack channel with a bot token.
+type SlackNotifier struct {
+	client                *http.Client
+	endpoint              string
+	authEndpoint          string
+	conversationsEndpoint string
+	token                 string
+	channel               string
+	logger                *slog.Logger
+
+	// threads keeps the messages of the findings, to thread the findings of the next runs
+	// under them, or nil to post every notification as new messages.
+	threads StateStore
+
+	// actions adds the acknowledgment buttons under the policy violations.
+	actions bool
+
+	// uploads attaches the findings of truncated notifications as a file.
+	uploads                bool
+	uploadURLEndpoint      string
+	completeUploadEndpoint string
+}
+
+// slackMessage is a message of mrkdwn text or, with action buttons, of Block Kit blocks with
+// the text as the fallback of notifications.
+type slackMessage struct {
+	Text   string
+	Blocks []map[string]any
+}
+
+// NewSlackNotifier creates a new Slack notifier for the configured channel.
+func NewSlackNotifier(logger *slog.Logger, cfg *Config, client *http.Client) *SlackNotifier {
+	return &SlackNotifier{
+		client:                client,
+		endpoint:              slackPostMessageURL,
+		authEndpoint:          slackAuthTestURL,
+		conversationsEndpoint: slackConversationsInfoURL,
+		token:                 cfg.SlackToken,
+		channel:               cfg.SlackChannel,
+		logger:                logger.With(slog.String("component", "asset-watcher")),
+		actions:               cfg.SlackActions,
+
+		uploads:                cfg.SlackUploadFindings,
+		uploadURLEndpoint:      slackGetUploadURLExternalURL,
+		completeUploadEndpoint: slackCompleteUploadExternalURL,
+	}
+}
+
+// Name returns the name of the notifier.
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// Notify posts the notification, split into several messages if it is too long.
+// Items that do not fit into the maximum number of messages are replaced with a footer and,
+// with uploads, attached as a file.
+// With threads, the findings already posted are replied in the threads of their messages.
+func (n *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	if n.threads != nil && notification.Report != nil {
+		return n.notifyThreaded(ctx, notification)
+	}
+
+	_, err := n.post(ctx, notification, "")
+
+	return err
+}
+
+// render returns the messages of the notification, with the action buttons if enabled and
+// the notification has policy violations, and the number of items that did not fit. A body
+// rendered by a template is posted as a single message, cut to the length of a message.
+func (n *SlackNotifier) render(notification Notification) ([]slackMessage, int) {
+	if notification.Body != "" {
+		return []slackMessage{{Text: truncateString(notification.Body, slackMaxMessageLength)}}, 0
+	}
+
+	if n.actions && notification.Report != nil && len(notification.Report.Violations) > 0 {
+		return slackActionMessages(notification)
+	}
+
+	texts, omitted := slackMessages(notification)
+	messages := make([]slackMessage, 0, len(texts))
+
+	for _, text := range texts {
+		messages = append(messages, slackMessage{Text: text})
+	}
+
+	return messages, omitted
+}
+
+// post posts the messages of the notification to the channel, or as replies in the thread of
+// the message with the timestamp threadTS, and returns the timestamp of the first message.
+// With uploads, the findings of a notification that does not fit into the messages are
+// uploaded as a CSV file in the thread of the first message.
+func (n *SlackNotifier) post(ctx context.Context, notification Notification, threadTS string) (string, error) {
+	messages, omitted := n.render(notification)
+	first := slackPostMessageResponse{}
+
+	for i, message := range messages {
+		resp, err := n.postMessage(ctx, message, threadTS)
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 {
+			first = resp
+		}
+	}
+
+	if omitted > 0 && n.uploads && notification.Report != nil {
+		if threadTS == "" {
+			threadTS = first.TS
+		}
+
+		// The messages are posted, so a failed upload is not retried, which would post them again.
+		if err := n.uploadFindings(ctx, notification, first.Channel, threadTS); err != nil {
+			n.logger.WarnContext(ctx, "failed to upload the findings to Slack", slog.Any("error", err))
+		}
+	}
+
+	n.logger.DebugContext(ctx, "Sent Slack notification", slog.Int("number_of_messages", len(messages)))
+
+	return first.TS, nil
+}
+
+// postMessage posts the message to the channel, or as a reply in the thread of the message
+// with the timestamp threadTS, and returns the channel ID and the timestamp of the message.
+func (n *SlackNotifier) postMessage(
+	ctx context.Context, message slackMessage, threadTS string,
+) (slackPostMessageResponse, error) {
+	header := http.Header{"Authorization": []string{"Bearer " + n.token}}
+	payload := map[string]any{"channel": n.channel, "text": message.Text, "unfurl_links": false}
+
+	if len(message.Blocks) > 0 {
+		payload["blocks"] = message.Blocks
+	}
+
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+
+	body, err := postJSON(ctx, n.client, n.endpoint, header, payload)
+	if err != nil {
+		return slackPostMessageResponse{}, fmt.Errorf("failed to post Slack message: %w", err)
+	}
+
+	var resp slackPostMessageResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return slackPostMessageResponse{}, fmt.Errorf("failed to parse Slack response: %w", err)
+	}
+
+	if !resp.OK {
+		return slackPostMessageResponse{}, fmt.Errorf("%w: Slack error %s", errNotificationFailed, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// slackMessages renders the notification as up to slackMaxMessages messages in Slack mrkdwn,
+// and returns the number of items that did not fit.
+func slackMessages(notification Notification) ([]string, int) {
+	header := "*" + notification.Title + "*\n" + notification.Summary
+	messages := []string{}
+	items := notification.Items
+
+	for len(items) > 0 && len(messages) < slackMaxMessages {
+		budget := slackMaxMessageLength - len(header) - slackFooterLength
+
+		page, _ := limitItems(items, notification.itemsLimit(slackMaxItemsPerMessage), budget, len(bulletPrefix)+1)
+		if len(page) == 0 {
+			// An item longer than a message is cut.
+			page = []string{truncateString(items[0], budget-len(bulletPrefix)-1)}
+		}
+
+		items = items[len(page):]
+		messages = append(messages, header+"\n"+bulletList(page))
+		header = "_" + notification.Title + " (continued)_"
+	}
+
+	if len(messages) == 0 {
+		return []string{header}, 0
+	}
+
+	if footer := moreItemsFooter(len(items), notification.ArtifactURL); footer != "" {
+		messages[len(messages)-1] += "_" + footer + "_"
+	}
+
+	return messages, len(items)
+}
+
+func bulletList(items []string) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(bulletPrefix + item + "\n")
+	}
+
+	return b.String()
+}
+
+// truncateString cuts the string to at most n bytes, marking it with an ellipsis.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	const ellipsis = "…"
+
+	return strings.ToValidUTF8(s[:max(n-len(ellipsis), 0)], "") + ellipsis
+}
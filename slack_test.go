@@ -0,0 +1,182 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func manyItems(n, length int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = strconv.Itoa(i) + strings.Repeat("x", length)
+	}
+
+	return items
+}
+
+func TestSlackMessages(t *testing.T) {
+	t.Run("short notification", func(t *testing.T) {
+		got, _ := slackMessages(Notification{Title: "title", Summary: "summary", Items: []string{"one", "two"}})
+		if len(got) != 1 || !strings.Contains(got[0], "• one\n• two") {
+			t.Errorf("unexpected messages %q", got)
+		}
+	})
+
+	t.Run("split", func(t *testing.T) {
+		got, _ := slackMessages(Notification{Title: "title", Items: manyItems(120, 10)})
+		if len(got) != 3 {
+			t.Fatalf("expected 3 messages, got %d", len(got))
+		}
+
+		for _, message := range got {
+			if len(message) > slackMaxMessageLength {
+				t.Errorf("message of %d bytes exceeds the limit", len(message))
+			}
+		}
+
+		if strings.Contains(got[2], "more items") {
+			t.Errorf("expected no footer when all items fit, got %q", got[2])
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		got, _ := slackMessages(Notification{Title: "title", Items: manyItems(1000, 100), ArtifactURL: "https://example.com/r.json"})
+		if len(got) != slackMaxMessages {
+			t.Fatalf("expected %d messages, got %d", slackMaxMessages, len(got))
+		}
+
+		last := got[len(got)-1]
+		if !strings.Contains(last, "more items") || !strings.Contains(last, "https://example.com/r.json") {
+			t.Errorf("expected a footer with the artifact link, got %q", last[max(len(last)-200, 0):])
+		}
+	})
+
+	t.Run("oversized item", func(t *testing.T) {
+		got, _ := slackMessages(Notification{Title: "title", Items: []string{strings.Repeat("x", 10000)}})
+		if len(got) != 1 || len(got[0]) > slackMaxMessageLength {
+			t.Errorf("expected a single truncated message, got %d messages", len(got))
+		}
+	})
+}
+
+func TestSlackNotifier_RenderBody(t *testing.T) {
+	notifier := &SlackNotifier{actions: true}
+	notification := Notification{
+		Title:  "title",
+		Items:  manyItems(120, 10),
+		Body:   strings.Repeat("x", 2*slackMaxMessageLength),
+		Report: &Report{Violations: []RuleViolation{{Rule: "rule"}}},
+	}
+
+	got, omitted := notifier.render(notification)
+	if len(got) != 1 || omitted != 0 || len(got[0].Text) > slackMaxMessageLength || len(got[0].Blocks) != 0 {
+		t.Errorf("expected a single truncated message of the body, got %d messages", len(got))
+	}
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var texts []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xoxb-token" {
+			t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+
+		var payload struct {
+			Channel string `json:"channel"`
+			Text    string `json:"text"`
+		}
+		_ = json.Unmarshal(body, &payload)
+		texts = append(texts, payload.Text)
+
+		if payload.Channel == "#missing" {
+			_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	logger := slog.New(slog.DiscardHandler)
+
+	notifier := NewSlackNotifier(logger, &Config{SlackToken: "xoxb-token", SlackChannel: "#alerts"}, server.Client())
+	notifier.endpoint = server.URL
+
+	if err := notifier.Notify(ctx, Notification{Title: "title", Items: manyItems(60, 10)}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(texts) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(texts))
+	}
+
+	notifier.channel = "#missing"
+
+	err := notifier.Notify(ctx, Notification{Title: "title"})
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("expected a Slack error, got %v", err)
+	}
+}
+
+func TestSlackNotifier_NotifyThreaded(t *testing.T) {
+	type post struct {
+		Text     string `json:"text"`
+		ThreadTS string `json:"thread_ts"`
+	}
+
+	var posts []post
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p post
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		posts = append(posts, p)
+
+		_, _ = fmt.Fprintf(w, `{"ok": true, "channel": "C0123456789", "ts": "1700000000.%06d"}`, len(posts))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+	notifier := NewSlackNotifier(slog.New(slog.DiscardHandler), &Config{SlackToken: "xoxb-token", SlackChannel: "#alerts"},
+		server.Client())
+	notifier.endpoint = server.URL
+	notifier.threads = &memoryStateStore{values: map[string][]byte{}}
+
+	unused := RuleViolation{Rule: "unused-address", Severity: severityMedium, Message: "unused",
+		Asset: ProcessedAsset{ResourceName: "//compute/a1", Project: "p"}}
+	exposed := RuleViolation{Rule: "exposed", Severity: severityHigh, Message: "exposed",
+		Asset: ProcessedAsset{ResourceName: "//compute/a2", Project: "p"}}
+
+	first := &Report{Metadata: RunMetadata{RunID: "run-1"}, Violations: []RuleViolation{unused}}
+	if err := notifier.Notify(ctx, newNotification(first, "")); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	second := &Report{Metadata: RunMetadata{RunID: "run-2"}, Violations: []RuleViolation{unused, exposed}}
+	if err := notifier.Notify(ctx, newNotification(second, "")); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(posts) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(posts))
+	}
+
+	if posts[1].ThreadTS != "" || !strings.Contains(posts[1].Text, "exposed") || strings.Contains(posts[1].Text, "unused (p)") {
+		t.Errorf("expected a new message of the new violation only, got %+v", posts[1])
+	}
+
+	if posts[2].ThreadTS != "1700000000.000001" || !strings.Contains(posts[2].Text, "unused (p)") {
+		t.Errorf("expected a reply of the persisting violation in the first thread, got %+v", posts[2])
+	}
+}
@@ -0,0 +1,285 @@
+package assetwatcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Block Kit limits. Every violation takes a section and an actions block, and a message has
+// at most 50 blocks.
+// https://api.slack.com/reference/block-kit/blocks
+const (
+	slackActionsPerMessage = 15
+	slackMaxSectionText    = 3000
+	slackMaxActionValue    = 2000
+
+	// slackSignatureMaxAge is the maximum age of the signed requests of Slack, which protects
+	// against replayed requests.
+	slackSignatureMaxAge = 5 * time.Minute
+
+	// slackMaxRequestBytes is the maximum size of the interaction requests of Slack.
+	slackMaxRequestBytes = 1 << 20
+)
+
+// slackAction is a button under the policy violations, recording an acknowledgment of the
+// status for the TTL, or without expiry for a zero TTL.
+type slackAction struct {
+	id     string
+	label  string
+	status string
+	ttl    time.Duration
+}
+
+var slackActions = []slackAction{
+	{id: "acknowledge", label: "Acknowledge", status: ackStatusAcknowledged},
+	{id: "snooze", label: "Snooze 7d", status: ackStatusAcknowledged, ttl: 7 * hoursPerDay * time.Hour},
+	{id: "exempt", label: "Create exemption", status: ackStatusSuppressed},
+}
+
+var errInvalidSlackSignature = errors.New("invalid Slack request signature")
+
+// slackActionValue identifies the violation of a button.
+type slackActionValue struct {
+	Rule     string `json:"rule"`
+	Resource string `json:"resource"`
+}
+
+// slackInteraction is the payload of a block_actions interaction.
+// https://api.slack.com/reference/interaction-payloads/block-actions
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+}
+
+// slackActionMessages renders the notification as up to slackMaxMessages messages of Block Kit
+// blocks, with the action buttons under every policy violation. Items that do not fit into the
+// maximum number of messages are replaced with a footer, and their number is returned.
+func slackActionMessages(notification Notification) ([]slackMessage, int) {
+	type entry struct {
+		text      string
+		violation *RuleViolation
+	}
+
+	report := notification.Report
+	entries := make([]entry, 0, len(report.Violations)+len(report.Diffs))
+
+	for i := range report.Violations {
+		entries = append(entries, entry{text: violationItem(report.Violations[i]), violation: &report.Violations[i]})
+	}
+
+	for _, d := range report.Diffs {
+		entries = append(entries, entry{text: diffItem(d)})
+	}
+
+	header := "*" + notification.Title + "*\n" + notification.Summary
+	messages := []slackMessage{}
+	perMessage := notification.itemsLimit(slackActionsPerMessage)
+
+	for len(entries) > 0 && len(messages) < slackMaxMessages {
+		page := entries[:min(len(entries), perMessage)]
+		entries = entries[len(page):]
+
+		message := slackMessage{Text: header, Blocks: []map[string]any{slackSection(header)}}
+		items := make([]string, 0, len(page))
+
+		for _, e := range page {
+			message.Blocks = append(message.Blocks, slackSection(truncateString(bulletPrefix+e.text, slackMaxSectionText)))
+			items = append(items, e.text)
+
+			if e.violation != nil {
+				if actions := slackActionsBlock(*e.violation); actions != nil {
+					message.Blocks = append(message.Blocks, actions)
+				}
+			}
+		}
+
+		message.Text = truncateString(header+"\n"+bulletList(items), slackMaxMessageLength)
+		messages = append(messages, message)
+		header = "_" + notification.Title + " (continued)_"
+	}
+
+	if footer := moreItemsFooter(len(entries), notification.ArtifactURL); footer != "" {
+		last := &messages[len(messages)-1]
+		last.Blocks = append(last.Blocks, slackSection("_"+footer+"_"))
+		last.Text += "_" + footer + "_"
+	}
+
+	return messages, len(entries)
+}
+
+func slackSection(text string) map[string]any {
+	return map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": text}}
+}
+
+// slackActionsBlock returns the buttons of the violation, or nil if its identifier does not
+// fit into the value of a button.
+func slackActionsBlock(v RuleViolation) map[string]any {
+	value, err := json.Marshal(slackActionValue{Rule: v.Rule, Resource: assetKey(v.Asset)})
+	if err != nil || len(value) > slackMaxActionValue {
+		return nil
+	}
+
+	buttons := make([]map[string]any, 0, len(slackActions))
+
+	for _, action := range slackActions {
+		buttons = append(buttons, map[string]any{
+			"type":      "button",
+			"action_id": action.id,
+			"text":      map[string]any{"type": "plain_text", "text": action.label},
+			"value":     string(value),
+		})
+	}
+
+	return map[string]any{"type": "actions", "elements": buttons}
+}
+
+// slackActionsHandler records the buttons clicked in Slack as acknowledgments in the state
+// store, so that the acknowledged violations are no longer notified, and confirms them in the
+// channel. Requests are authenticated with the signing secret of the Slack app.
+func slackActionsHandler(
+	logger *slog.Logger, signingSecret string, state StateStore, client *http.Client,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxRequestBytes))
+		if err != nil {
+			http.Error(w, "failed to read the request", http.StatusBadRequest)
+
+			return
+		}
+
+		if err := verifySlackSignature(signingSecret, r.Header, body, time.Now()); err != nil {
+			logger.WarnContext(ctx, "Rejected a Slack interaction", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			http.Error(w, "invalid form", http.StatusBadRequest)
+
+			return
+		}
+
+		var interaction slackInteraction
+		if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+
+			return
+		}
+
+		acks, confirmations := slackInteractionAcknowledgments(interaction, time.Now().UTC())
+		if len(acks) == 0 {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		if _, _, _, err := updateAcknowledgments(ctx, state, acks); err != nil {
+			logger.ErrorContext(ctx, "failed to record the Slack acknowledgments", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		logger.InfoContext(ctx, "Recorded Slack acknowledgments",
+			slog.String("user", acks[0].Owner),
+			slog.Int("acknowledgments", len(acks)),
+		)
+
+		if interaction.ResponseURL != "" {
+			payload := map[string]any{"response_type": "in_channel", "replace_original": false, "text": confirmations}
+			if _, err := postJSON(ctx, client, interaction.ResponseURL, nil, payload); err != nil {
+				logger.WarnContext(ctx, "failed to confirm the Slack acknowledgments", slog.Any("error", err))
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// slackInteractionAcknowledgments returns the acknowledgments of the buttons of the
+// interaction, and their confirmation message.
+func slackInteractionAcknowledgments(interaction slackInteraction, now time.Time) ([]Acknowledgment, string) {
+	owner := interaction.User.Username
+	if owner == "" {
+		owner = interaction.User.ID
+	}
+
+	acks := []Acknowledgment{}
+	confirmations := ""
+
+	for _, a := range interaction.Actions {
+		var value slackActionValue
+		if err := json.Unmarshal([]byte(a.Value), &value); err != nil || value.Rule == "" || value.Resource == "" {
+			continue
+		}
+
+		for _, action := range slackActions {
+			if action.id != a.ActionID {
+				continue
+			}
+
+			ack := Acknowledgment{
+				Rule:       value.Rule,
+				Resource:   value.Resource,
+				Status:     action.status,
+				Reason:     action.label + " in Slack",
+				Owner:      owner,
+				ImportedAt: now,
+			}
+
+			if action.ttl > 0 {
+				ack.Expires = now.Add(action.ttl)
+			}
+
+			acks = append(acks, ack)
+			confirmations += fmt.Sprintf("<@%s> clicked %s on %s of %s\n", interaction.User.ID, action.label,
+				value.Rule, value.Resource)
+		}
+	}
+
+	return acks, confirmations
+}
+
+// verifySlackSignature verifies the signature of a request of Slack with the signing secret.
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || now.Sub(time.Unix(seconds, 0)).Abs() > slackSignatureMaxAge {
+		return fmt.Errorf("%w: missing or expired timestamp", errInvalidSlackSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + timestamp + ":"))
+	_, _ = mac.Write(body)
+
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature"))) {
+		return errInvalidSlackSignature
+	}
+
+	return nil
+}
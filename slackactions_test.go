@@ -0,0 +1,201 @@
+package assetwatcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlackActionMessages(t *testing.T) {
+	report := &Report{Diffs: []AssetDiff{{Type: DiffAdded, Asset: ProcessedAsset{Name: "a0"}}}}
+	for i := range 20 {
+		report.Violations = append(report.Violations, RuleViolation{
+			Rule: "unused-address", Severity: severityMedium, Message: "unused",
+			Asset: ProcessedAsset{ResourceName: "//compute/a" + strconv.Itoa(i), Project: "p"},
+		})
+	}
+
+	messages, _ := slackActionMessages(newNotification(report, ""))
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	// The header, and a section and actions block of every violation.
+	if got := len(messages[0].Blocks); got != 1+2*slackActionsPerMessage {
+		t.Errorf("expected %d blocks in the first message, got %d", 1+2*slackActionsPerMessage, got)
+	}
+
+	actions := messages[0].Blocks[2]
+	buttons, _ := actions["elements"].([]map[string]any)
+
+	if actions["type"] != "actions" || len(buttons) != len(slackActions) {
+		t.Fatalf("expected the action buttons under the first violation, got %v", actions)
+	}
+
+	var value slackActionValue
+	if err := json.Unmarshal([]byte(buttons[0]["value"].(string)), &value); err != nil || value.Resource != "//compute/a0" {
+		t.Errorf("unexpected button value %v (%v)", buttons[0]["value"], err)
+	}
+
+	if !strings.Contains(messages[1].Text, "added: a0") {
+		t.Errorf("expected the change in the text of the last message, got %q", messages[1].Text)
+	}
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("payload=%7B%7D")
+
+	sign := func(timestamp int64) http.Header {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = mac.Write([]byte("v0:" + strconv.FormatInt(timestamp, 10) + ":"))
+		_, _ = mac.Write(body)
+
+		return http.Header{
+			"X-Slack-Request-Timestamp": {strconv.FormatInt(timestamp, 10)},
+			"X-Slack-Signature":         {"v0=" + hex.EncodeToString(mac.Sum(nil))},
+		}
+	}
+
+	if err := verifySlackSignature("secret", sign(now.Unix()), body, now); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+
+	if err := verifySlackSignature("other", sign(now.Unix()), body, now); !errors.Is(err, errInvalidSlackSignature) {
+		t.Errorf("expected errInvalidSlackSignature for another secret, got %v", err)
+	}
+
+	if err := verifySlackSignature("secret", sign(now.Unix()-600), body, now); !errors.Is(err, errInvalidSlackSignature) {
+		t.Errorf("expected errInvalidSlackSignature for an expired timestamp, got %v", err)
+	}
+}
+
+func TestSlackActionsHandler(t *testing.T) {
+	var confirmation string
+
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		confirmation = payload.Text
+	}))
+	defer slack.Close()
+
+	state := &memoryStateStore{values: map[string][]byte{}}
+	handler := slackActionsHandler(slog.New(slog.DiscardHandler), "secret", state, slack.Client())
+
+	interaction, _ := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]any{"id": "U123", "username": "alice"},
+		"actions":      []map[string]any{{"action_id": "snooze", "value": `{"rule":"unused-address","resource":"//compute/a1"}`}},
+		"response_url": slack.URL,
+	})
+	body := url.Values{"payload": {string(interaction)}}.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/slack/actions", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	acks, err := loadAcknowledgments(t.Context(), state)
+	if err != nil {
+		t.Fatalf("loadAcknowledgments failed: %v", err)
+	}
+
+	if len(acks) != 1 || acks[0].Status != ackStatusAcknowledged || acks[0].Owner != "alice" || acks[0].Expires.IsZero() {
+		t.Errorf("expected a snoozed acknowledgment, got %+v", acks)
+	}
+
+	if !strings.Contains(confirmation, "<@U123> clicked Snooze 7d") {
+		t.Errorf("unexpected confirmation %q", confirmation)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/slack/actions", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for an unsigned request, got %d", rec.Code)
+	}
+}
+
+func TestSlackActionsHandler_ConcurrentClicks(t *testing.T) {
+	state := NewFileStateStore(t.TempDir())
+	handler := slackActionsHandler(slog.New(slog.DiscardHandler), "secret", state, http.DefaultClient)
+
+	const clicks = 10
+
+	var wg sync.WaitGroup
+
+	for i := range clicks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, signedSlackAction(t, "secret", fmt.Sprintf("//compute/a%d", i)))
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	acks, err := loadAcknowledgments(t.Context(), state)
+	if err != nil {
+		t.Fatalf("loadAcknowledgments failed: %v", err)
+	}
+
+	if len(acks) != clicks {
+		t.Errorf("expected %d acknowledgments, got %d", clicks, len(acks))
+	}
+}
+
+// signedSlackAction returns a signed request of a click on the Acknowledge button of the resource.
+func signedSlackAction(t *testing.T, secret, resource string) *http.Request {
+	t.Helper()
+
+	value, _ := json.Marshal(map[string]string{"rule": "unused-address", "resource": resource})
+	interaction, _ := json.Marshal(map[string]any{
+		"type":    "block_actions",
+		"user":    map[string]any{"id": "U123", "username": "alice"},
+		"actions": []map[string]any{{"action_id": "acknowledge", "value": string(value)}},
+	})
+	body := url.Values{"payload": {string(interaction)}}.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/slack/actions", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
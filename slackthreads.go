@@ -0,0 +1,151 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+const (
+	// stateKeySlackThreads is the key of the Slack messages of the findings in the state store.
+	stateKeySlackThreads = "slack-threads"
+
+	// slackThreadRetention is how long the message of a finding no longer found is kept, so that
+	// a finding found again shortly after being fixed is replied in its thread.
+	slackThreadRetention = 30 * hoursPerDay * time.Hour
+)
+
+// slackThread is the message that first reported a finding.
+type slackThread struct {
+	TS       string    `json:"ts"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// slackThreads are the messages of the findings, by channel and by rule and asset.
+type slackThreads map[string]map[string]slackThread
+
+// threadSlackNotifications makes a Slack notifier reply the findings persisting across runs
+// in the threads of their messages, kept in the state store. Other notifiers are unchanged.
+func threadSlackNotifications(notifier Notifier, state StateStore) Notifier {
+	if slack, ok := notifier.(*SlackNotifier); ok && state != nil {
+		slack.threads = state
+	}
+
+	return notifier
+}
+
+// notifyThreaded posts the new policy violations and the changes as new messages, and replies
+// the violations persisting across runs in the threads of the messages that first reported them.
+func (n *SlackNotifier) notifyThreaded(ctx context.Context, notification Notification) error {
+	threads, err := loadSlackThreads(ctx, n.threads)
+	if err != nil {
+		return err
+	}
+
+	channel := threads[n.channel]
+	if channel == nil {
+		channel = map[string]slackThread{}
+	}
+
+	report := notification.Report
+	now := report.Metadata.StartedAt
+
+	freshReport := *report
+	freshReport.Violations = nil
+	newKeys := []string{}
+
+	// The persisting violations, by the message they are replied to.
+	replies := map[string][]RuleViolation{}
+	order := []string{}
+
+	for _, v := range report.Violations {
+		key := notifiedFindingKey(v)
+
+		thread, ok := channel[key]
+		if !ok {
+			freshReport.Violations = append(freshReport.Violations, v)
+			newKeys = append(newKeys, key)
+
+			continue
+		}
+
+		if _, ok := replies[thread.TS]; !ok {
+			order = append(order, thread.TS)
+		}
+
+		replies[thread.TS] = append(replies[thread.TS], v)
+		thread.LastSeen = now
+		channel[key] = thread
+	}
+
+	if len(freshReport.Violations) > 0 || len(freshReport.Diffs) > 0 {
+		fresh := notification
+		fresh.Items = reportItems(&freshReport)
+		fresh.Report = &freshReport
+
+		if known := len(report.Violations) - len(newKeys); known > 0 {
+			fresh.Summary += fmt.Sprintf(", %d known findings replied in their threads", known)
+		}
+
+		ts, err := n.post(ctx, fresh, "")
+		if err != nil {
+			return err
+		}
+
+		for _, key := range newKeys {
+			channel[key] = slackThread{TS: ts, LastSeen: now}
+		}
+	}
+
+	for _, ts := range order {
+		persisting := &Report{Metadata: report.Metadata, Violations: replies[ts]}
+		reply := Notification{
+			RunID:       notification.RunID,
+			Title:       "Still found by run " + notification.RunID,
+			Summary:     fmt.Sprintf("%d findings of this thread persist", len(replies[ts])),
+			Items:       reportItems(persisting),
+			ArtifactURL: notification.ArtifactURL,
+			MaxItems:    notification.MaxItems,
+			Report:      persisting,
+		}
+
+		if _, err := n.post(ctx, reply, ts); err != nil {
+			return err
+		}
+	}
+
+	maps.DeleteFunc(channel, func(_ string, thread slackThread) bool {
+		return now.Sub(thread.LastSeen) > slackThreadRetention
+	})
+	threads[n.channel] = channel
+
+	return saveSlackThreads(ctx, n.threads, threads)
+}
+
+// loadSlackThreads reads the messages of the findings from the state store.
+func loadSlackThreads(ctx context.Context, state StateStore) (slackThreads, error) {
+	threads := slackThreads{}
+
+	value, err := state.Get(ctx, stateKeySlackThreads)
+	if err != nil || value == nil {
+		return threads, err
+	}
+
+	if err := json.Unmarshal(value, &threads); err != nil {
+		return nil, fmt.Errorf("failed to decode Slack threads: %w", err)
+	}
+
+	return threads, nil
+}
+
+// saveSlackThreads writes the messages of the findings to the state store.
+func saveSlackThreads(ctx context.Context, state StateStore, threads slackThreads) error {
+	value, err := json.Marshal(threads)
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack threads: %w", err)
+	}
+
+	return state.Put(ctx, stateKeySlackThreads, value)
+}
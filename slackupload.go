@@ -0,0 +1,131 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Files are uploaded to a URL returned by files.getUploadURLExternal, then shared to the
+	// channel by files.completeUploadExternal.
+	// https://api.slack.com/messaging/files#uploading_files
+	slackGetUploadURLExternalURL   = "https://slack.com/api/files.getUploadURLExternal"
+	slackCompleteUploadExternalURL = "https://slack.com/api/files.completeUploadExternal"
+
+	slackFilesWriteScope = "files:write"
+)
+
+// findingsCSVHeader is the header of the CSV of the findings uploaded to Slack.
+var findingsCSVHeader = []string{
+	"finding", "type", "severity", "name", "ip_address", "project", "location", "resource", "message",
+}
+
+// slackUploadURLResponse is the response of files.getUploadURLExternal.
+type slackUploadURLResponse struct {
+	slackResponse
+
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+// uploadFindings uploads the policy violations and the changes of the notification as a CSV
+// file, shared in the thread of the message with the timestamp threadTS. The channel must be
+// the ID returned when posting the message, as files cannot be shared to #channel names.
+func (n *SlackNotifier) uploadFindings(ctx context.Context, notification Notification, channel, threadTS string) error {
+	content, err := findingsCSV(notification.Report)
+	if err != nil {
+		return err
+	}
+
+	filename := "asset-watcher-" + notification.RunID + ".csv"
+
+	body, _, err := n.call(ctx, http.MethodPost, n.uploadURLEndpoint, url.Values{
+		"filename": {filename},
+		"length":   {strconv.Itoa(len(content))},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get a Slack upload URL: %w", err)
+	}
+
+	var upload slackUploadURLResponse
+	if err := json.Unmarshal(body, &upload); err != nil {
+		return fmt.Errorf("failed to parse Slack response: %w", err)
+	}
+
+	if !upload.OK {
+		return fmt.Errorf("%w: Slack error %s", errNotificationFailed, upload.Error)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upload.UploadURL, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/csv")
+
+	if _, _, err := doRequest(n.client, req, nil); err != nil {
+		return fmt.Errorf("failed to upload the Slack file: %w", err)
+	}
+
+	header := http.Header{"Authorization": []string{"Bearer " + n.token}}
+	payload := map[string]any{
+		"files":      []map[string]string{{"id": upload.FileID, "title": notification.Title}},
+		"channel_id": channel,
+		"thread_ts":  threadTS,
+		"initial_comment": fmt.Sprintf("All %d policy violations and %d changes of run %s",
+			len(notification.Report.Violations), len(notification.Report.Diffs), notification.RunID),
+	}
+
+	body, err = postJSON(ctx, n.client, n.completeUploadEndpoint, header, payload)
+	if err != nil {
+		return fmt.Errorf("failed to share the Slack file: %w", err)
+	}
+
+	var resp slackResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse Slack response: %w", err)
+	}
+
+	if !resp.OK {
+		return fmt.Errorf("%w: Slack error %s", errNotificationFailed, resp.Error)
+	}
+
+	return nil
+}
+
+// findingsCSV returns the policy violations and the changes of the report as CSV.
+func findingsCSV(report *Report) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	_ = w.Write(findingsCSVHeader)
+
+	for _, v := range report.Violations {
+		_ = w.Write([]string{
+			"violation", v.Rule, v.Severity, v.Asset.Name, v.Asset.IPAddress, v.Asset.Project,
+			v.Asset.Location, assetKey(v.Asset), v.Message,
+		})
+	}
+
+	for _, d := range report.Diffs {
+		_ = w.Write([]string{
+			"change", string(d.Type), "", d.Asset.Name, d.Asset.IPAddress, d.Asset.Project,
+			d.Asset.Location, assetKey(d.Asset), strings.Join(d.Changes, "; "),
+		})
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write the findings: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,88 @@
+package assetwatcher
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFindingsCSV(t *testing.T) {
+	report := &Report{
+		Violations: []RuleViolation{{Rule: "r", Severity: severityHigh, Message: "exposed, really", Asset: ProcessedAsset{
+			Name: "a1", ResourceName: "//compute/a1",
+		}}},
+		Diffs: []AssetDiff{{Type: DiffChanged, Asset: ProcessedAsset{Name: "a2"}, Changes: []string{"status", "labels"}}},
+	}
+
+	content, err := findingsCSV(report)
+	if err != nil {
+		t.Fatalf("findingsCSV failed: %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse the CSV: %v", err)
+	}
+
+	if len(records) != 3 || records[1][8] != "exposed, really" || records[2][1] != string(DiffChanged) ||
+		records[2][8] != "status; labels" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+func TestSlackNotifier_NotifyUploadsFindings(t *testing.T) {
+	var uploaded []byte
+
+	var shared map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "C0123456789", "ts": "1700000000.000001"}`))
+	})
+	mux.HandleFunc("/files.getUploadURLExternal", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("length") == "" {
+			t.Errorf("expected the length of the file, got %q", r.URL.RawQuery)
+		}
+
+		_, _ = w.Write([]byte(`{"ok": true, "upload_url": "http://` + r.Host + `/upload", "file_id": "F123"}`))
+	})
+	mux.HandleFunc("/upload", func(_ http.ResponseWriter, r *http.Request) {
+		uploaded, _ = io.ReadAll(r.Body)
+	})
+	mux.HandleFunc("/files.completeUploadExternal", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&shared)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &Config{SlackToken: "xoxb-token", SlackChannel: "#alerts", SlackUploadFindings: true}
+	notifier := NewSlackNotifier(slog.New(slog.DiscardHandler), cfg, server.Client())
+	notifier.endpoint = server.URL + "/chat.postMessage"
+	notifier.uploadURLEndpoint = server.URL + "/files.getUploadURLExternal"
+	notifier.completeUploadEndpoint = server.URL + "/files.completeUploadExternal"
+
+	report := &Report{Metadata: RunMetadata{RunID: "run-1"}}
+	for i := range slackMaxItemsPerMessage*slackMaxMessages + 10 {
+		report.Diffs = append(report.Diffs, AssetDiff{Type: DiffAdded, Asset: ProcessedAsset{Name: "a" + strconv.Itoa(i)}})
+	}
+
+	if err := notifier.Notify(t.Context(), newNotification(report, "")); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if got := strings.Count(string(uploaded), "\n"); got != len(report.Diffs)+1 {
+		t.Errorf("expected the header and %d changes in the uploaded file, got %d lines", len(report.Diffs), got)
+	}
+
+	if shared["channel_id"] != "C0123456789" || shared["thread_ts"] != "1700000000.000001" {
+		t.Errorf("expected the file to be shared in the thread of the first message, got %v", shared)
+	}
+}
@@ -0,0 +1,222 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+	htransport "google.golang.org/api/transport/http"
+)
+
+const gcsScheme = "gs://"
+
+var errInvalidSnapshotPath = errors.New("invalid snapshot path, expected a file path or gs://BUCKET/OBJECT")
+
+// Snapshot is the list of assets found by a run, compared with the next run to detect changes.
+type Snapshot struct {
+	RunID   string           `json:"runId"`
+	TakenAt time.Time        `json:"takenAt"`
+	Assets  []ProcessedAsset `json:"assets"`
+
+	// Released are the assets tracked as released, kept for the retention.
+	Released []ProcessedAsset `json:"released,omitempty"`
+}
+
+// SnapshotStore is an interface for persisting the snapshot of the previous run.
+type SnapshotStore interface {
+	// Load returns the stored snapshot, or nil if there is none yet.
+	Load(ctx context.Context) (*Snapshot, error)
+	Save(ctx context.Context, snapshot *Snapshot) error
+}
+
+// newSnapshot returns the snapshot of the assets of the report.
+func newSnapshot(report *Report) *Snapshot {
+	return &Snapshot{
+		RunID:    report.Metadata.RunID,
+		TakenAt:  report.Metadata.StartedAt,
+		Assets:   report.Assets,
+		Released: report.Released,
+	}
+}
+
+// parseGCSPath splits a gs://BUCKET/OBJECT path into the bucket and the object name.
+func parseGCSPath(path string) (string, string, error) {
+	bucket, object, ok := strings.Cut(strings.TrimPrefix(path, gcsScheme), "/")
+	if !ok || bucket == "" || object == "" {
+		return "", "", fmt.Errorf("%w: %q", errInvalidSnapshotPath, path)
+	}
+
+	return bucket, object, nil
+}
+
+// FileSnapshotStore stores the snapshot in a local JSON file.
+type FileSnapshotStore struct {
+	path string
+}
+
+// NewFileSnapshotStore creates a new snapshot store of the file.
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{path: path}
+}
+
+// Load reads the snapshot, if the file exists.
+func (s *FileSnapshotStore) Load(_ context.Context) (*Snapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil // There is no previous run yet.
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	return decodeSnapshot(bytes.NewReader(data))
+}
+
+// Save writes the snapshot, creating the directory if needed.
+func (s *FileSnapshotStore) Save(_ context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// GCSSnapshotStore stores the snapshot in a Cloud Storage object.
+type GCSSnapshotStore struct {
+	client  *http.Client
+	service *storage.Service
+	bucket  string
+	object  string
+}
+
+// NewGCSSnapshotStore creates a new snapshot store of the gs://BUCKET/OBJECT path.
+func NewGCSSnapshotStore(ctx context.Context, path string, opts ...option.ClientOption) (*GCSSnapshotStore, error) {
+	bucket, object, err := parseGCSPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]option.ClientOption{option.WithScopes(storage.DevstorageReadWriteScope)}, opts...)
+
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+
+	s, err := storage.NewService(ctx, append(opts, option.WithHTTPClient(client))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+
+	return &GCSSnapshotStore{client: client, service: s, bucket: bucket, object: object}, nil
+}
+
+// Close closes the connections of the client.
+func (s *GCSSnapshotStore) Close() error {
+	s.client.CloseIdleConnections()
+
+	return nil
+}
+
+// Load downloads the snapshot, if the object exists.
+func (s *GCSSnapshotStore) Load(ctx context.Context) (*Snapshot, error) {
+	resp, err := s.service.Objects.Get(s.bucket, s.object).Context(ctx).Download()
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil, nil //nolint:nilnil // There is no previous run yet.
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeSnapshot(resp.Body)
+}
+
+// Save uploads the snapshot, replacing the previous one.
+func (s *GCSSnapshotStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	_, err = s.service.Objects.Insert(s.bucket, &storage.Object{Name: s.object, ContentType: "application/json"}).
+		Media(bytes.NewReader(data)).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// decodeSnapshot decodes a snapshot, or a JSON report, whose run metadata is used instead.
+func decodeSnapshot(r io.Reader) (*Snapshot, error) {
+	var decoded struct {
+		Snapshot
+
+		Metadata *RunMetadata `json:"metadata"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	snapshot := decoded.Snapshot
+	if decoded.Metadata != nil && snapshot.RunID == "" {
+		snapshot.RunID = decoded.Metadata.RunID
+		snapshot.TakenAt = decoded.Metadata.StartedAt
+	}
+
+	return &snapshot, nil
+}
+
+// snapshotSink saves the assets of every report as the snapshot the next run is compared with.
+type snapshotSink struct {
+	store  SnapshotStore
+	logger *slog.Logger
+}
+
+// Name returns the name of the sink.
+func (s snapshotSink) Name() string {
+	return "snapshot"
+}
+
+// Publish saves the snapshot of the report.
+func (s snapshotSink) Publish(ctx context.Context, report *Report) error {
+	if err := s.store.Save(ctx, newSnapshot(report)); err != nil {
+		return err
+	}
+
+	s.logger.DebugContext(ctx, "Saved snapshot", slog.Int("number_of_asset", len(report.Assets)))
+
+	return nil
+}
+
+// Close is a no-op, as the snapshot stores do not hold any resources.
+func (s snapshotSink) Close() error {
+	return nil
+}
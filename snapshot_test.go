@@ -0,0 +1,119 @@
+package assetwatcher
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestFileSnapshotStore(t *testing.T) {
+	ctx := t.Context()
+	store := NewFileSnapshotStore(filepath.Join(t.TempDir(), "state", "snapshot.json"))
+
+	snapshot, err := store.Load(ctx)
+	if err != nil || snapshot != nil {
+		t.Fatalf("expected no snapshot, got %+v, %v", snapshot, err)
+	}
+
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run1", StartedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		Assets:   []ProcessedAsset{{Name: "a1", IPAddress: "203.0.113.1"}},
+	}
+
+	sink := snapshotSink{store: store, logger: slog.New(slog.DiscardHandler)}
+	if err := sink.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	snapshot, err = store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if snapshot.RunID != "run1" || !snapshot.TakenAt.Equal(report.Metadata.StartedAt) || len(snapshot.Assets) != 1 {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+}
+
+func TestGCSSnapshotStore(t *testing.T) {
+	var stored string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/state/o/runs/snapshot.json":
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "No such object"}}`))
+
+				return
+			}
+
+			_, _ = w.Write([]byte(stored))
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/state/o":
+			body, _ := io.ReadAll(r.Body)
+			// The multipart upload contains the object metadata followed by the media.
+			parts := strings.Split(string(body), "\r\n")
+			for _, part := range parts {
+				if strings.HasPrefix(part, `{"runId"`) {
+					stored = part
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name": "runs/snapshot.json"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	store, err := NewGCSSnapshotStore(ctx, "gs://state/runs/snapshot.json",
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewGCSSnapshotStore failed: %v", err)
+	}
+
+	snapshot, err := store.Load(ctx)
+	if err != nil || snapshot != nil {
+		t.Fatalf("expected no snapshot, got %+v, %v", snapshot, err)
+	}
+
+	if err := store.Save(ctx, &Snapshot{RunID: "run1", Assets: []ProcessedAsset{{Name: "a1"}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	snapshot, err = store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if snapshot.RunID != "run1" || len(snapshot.Assets) != 1 || snapshot.Assets[0].Name != "a1" {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+	if err := store.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestParseGCSPath(t *testing.T) {
+	bucket, object, err := parseGCSPath("gs://state/runs/snapshot.json")
+	if err != nil || bucket != "state" || object != "runs/snapshot.json" {
+		t.Errorf("parseGCSPath() = %q, %q, %v", bucket, object, err)
+	}
+
+	for _, path := range []string{"gs://state", "gs:///snapshot.json", "gs://state/"} {
+		if _, _, err := parseGCSPath(path); err == nil {
+			t.Errorf("expected an error for %q", path)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package assetwatcher
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// The pure-Go SQLite driver, so that neither cgo nor the sqlite3 shell is needed.
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriver is the database/sql driver name of modernc.org/sqlite.
+const sqliteDriver = "sqlite"
+
+var errSQLite = errors.New("failed to write to the SQLite database")
+
+// sqliteSchema creates the tables of ASSET_WATCHER_SQLITE_PATH: a row per run in runs, and a
+// row per asset of every run in assets. Labels and attributes are JSON objects, queried with
+// json_extract. The latest_assets view is the inventory of the latest run.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS runs (
+  run_id TEXT PRIMARY KEY,
+  org_id TEXT,
+  started_at TEXT NOT NULL,
+  finished_at TEXT,
+  version TEXT,
+  git_commit TEXT,
+  total_assets INTEGER,
+  violations INTEGER,
+  changes INTEGER
+);
+CREATE TABLE IF NOT EXISTS assets (
+  run_id TEXT NOT NULL REFERENCES runs (run_id),
+  scan_time TEXT NOT NULL,
+  name TEXT,
+  project TEXT,
+  location TEXT,
+  status TEXT,
+  ip_address TEXT,
+  created_at TEXT,
+  resource_name TEXT,
+  asset_type TEXT,
+  address_type TEXT,
+  estimated_monthly_cost REAL,
+  labels TEXT,
+  attributes TEXT,
+  disposition TEXT,
+  category TEXT,
+  compliance TEXT,
+  country TEXT,
+  region TEXT,
+  byoip INTEGER
+);
+CREATE INDEX IF NOT EXISTS assets_run_id ON assets (run_id);
+CREATE INDEX IF NOT EXISTS assets_ip_address ON assets (ip_address);
+CREATE INDEX IF NOT EXISTS assets_resource_name ON assets (resource_name);
+CREATE VIEW IF NOT EXISTS latest_assets AS
+  SELECT * FROM assets WHERE run_id = (SELECT run_id FROM runs ORDER BY started_at DESC LIMIT 1);
+`
+
+// sqliteInsertRun and sqliteInsertAsset are the statements appending a run and its assets.
+var (
+	sqliteInsertRun   = "INSERT OR REPLACE INTO runs VALUES (" + sqlPlaceholders(9) + ")"
+	sqliteInsertAsset = "INSERT INTO assets VALUES (" + sqlPlaceholders(20) + ")"
+)
+
+// SQLiteSink appends the run and its assets to a local SQLite database, for ad-hoc historical
+// queries without any cloud infrastructure.
+type SQLiteSink struct {
+	path   string
+	logger *slog.Logger
+}
+
+// NewSQLiteSink creates a new SQLite sink of the database file.
+func NewSQLiteSink(logger *slog.Logger, path string) *SQLiteSink {
+	return &SQLiteSink{
+		path:   path,
+		logger: logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the sink.
+func (s *SQLiteSink) Name() string {
+	return "sqlite"
+}
+
+// Publish creates the database and its tables if needed, and appends the run in a single
+// transaction. A run stored before, such as a replayed run, is replaced.
+func (s *SQLiteSink) Publish(ctx context.Context, report *Report) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create SQLite database directory: %w", err)
+	}
+
+	db, err := sql.Open(sqliteDriver, s.path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errSQLite, err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return fmt.Errorf("%w: failed to create the schema: %w", errSQLite, err)
+	}
+
+	if err := appendSQLiteRun(ctx, db, report); err != nil {
+		return fmt.Errorf("%w: %w", errSQLite, err)
+	}
+
+	s.logger.DebugContext(ctx, "Appended the run to the SQLite database",
+		slog.String("path", s.path),
+		slog.Int("number_of_assets", len(report.Assets)),
+	)
+
+	return nil
+}
+
+// Close is a no-op, as every run opens the database on its own.
+func (s *SQLiteSink) Close() error {
+	return nil
+}
+
+// appendSQLiteRun replaces the run and its assets in a transaction.
+func appendSQLiteRun(ctx context.Context, db *sql.DB, report *Report) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin the transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Fails once committed.
+
+	metadata := report.Metadata
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM assets WHERE run_id = ?", metadata.RunID); err != nil {
+		return fmt.Errorf("failed to delete the assets of the run: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, sqliteInsertRun,
+		metadata.RunID, sqlString(metadata.OrgID), sqlTime(metadata.StartedAt), sqlTime(metadata.FinishedAt),
+		sqlString(metadata.Version), sqlString(metadata.Commit),
+		len(report.Assets), len(report.Violations), len(report.Diffs))
+	if err != nil {
+		return fmt.Errorf("failed to insert the run: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, sqliteInsertAsset)
+	if err != nil {
+		return fmt.Errorf("failed to prepare the insert of the assets: %w", err)
+	}
+	defer stmt.Close()
+
+	scanTime := sqlTime(metadata.StartedAt)
+
+	for _, asset := range report.Assets {
+		labels, err := sqlJSON(asset.Labels)
+		if err != nil {
+			return err
+		}
+
+		attributes, err := sqlJSON(asset.Attributes)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.ExecContext(ctx,
+			metadata.RunID, scanTime, sqlString(asset.Name), sqlString(asset.Project), sqlString(asset.Location),
+			sqlString(asset.Status), sqlString(asset.IPAddress), sqlString(asset.CreatedAt),
+			sqlString(asset.ResourceName), sqlString(asset.AssetType), sqlString(asset.AddressType),
+			asset.EstimatedMonthlyCost, labels, attributes,
+			sqlString(asset.Disposition), sqlString(asset.Category), sqlString(asset.Compliance),
+			sqlString(asset.Country), sqlString(asset.Region), asset.BYOIP,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert asset %s: %w", asset.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+
+	return nil
+}
+
+// sqlPlaceholders returns the placeholders of n parameters.
+func sqlPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// sqlString returns the parameter of the string, NULL if it is empty.
+func sqlString(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// sqlTime returns the parameter of the time, RFC 3339 in UTC, which sorts chronologically, or
+// NULL if it is zero.
+func sqlTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// sqlJSON returns the parameter of the map as a JSON object, or NULL if it is empty.
+func sqlJSON(m map[string]string) (any, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON column: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,82 @@
+package assetwatcher
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteSink_Publish(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "db", "inventory.db")
+	sink := NewSQLiteSink(slog.New(slog.DiscardHandler), path)
+
+	first := &Report{
+		Metadata: RunMetadata{RunID: "run-1", StartedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Assets: []ProcessedAsset{
+			{Name: "nat-1", IPAddress: "203.0.113.1"},
+			{Name: "o'brien'); DROP TABLE runs; --", IPAddress: "203.0.113.2", BYOIP: true},
+		},
+	}
+	second := &Report{
+		Metadata: RunMetadata{RunID: "run-2", StartedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		Assets:   []ProcessedAsset{{Name: "nat-1", IPAddress: "203.0.113.1", Labels: map[string]string{"team": "net"}}},
+	}
+
+	// The second run is published twice, as a replayed run, which replaces it.
+	for _, report := range []*Report{first, second, second} {
+		if err := sink.Publish(ctx, report); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		runs, assets int
+		teams        string
+	)
+
+	err = db.QueryRowContext(ctx, "SELECT (SELECT count(*) FROM runs), (SELECT count(*) FROM assets), "+
+		"(SELECT group_concat(json_extract(labels, '$.team')) FROM latest_assets)").Scan(&runs, &assets, &teams)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	if runs != 2 || assets != 3 || teams != "net" {
+		t.Errorf("query returned %d runs, %d assets and teams %q, want 2, 3 and %q", runs, assets, teams, "net")
+	}
+
+	var (
+		name        string
+		byoip       bool
+		addressType sql.NullString
+	)
+
+	err = db.QueryRowContext(ctx, "SELECT name, byoip, address_type FROM assets WHERE ip_address = ?", "203.0.113.2").
+		Scan(&name, &byoip, &addressType)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	if name != first.Assets[1].Name || !byoip || addressType.Valid {
+		t.Errorf("query returned %q, %t, %v, want the name stored verbatim, BYOIP, and a NULL address type",
+			name, byoip, addressType)
+	}
+}
+
+func TestSQLiteSink_PublishFailure(t *testing.T) {
+	// The database path is a directory, which SQLite cannot open.
+	sink := NewSQLiteSink(slog.New(slog.DiscardHandler), t.TempDir())
+
+	if err := sink.Publish(t.Context(), &Report{}); !errors.Is(err, errSQLite) {
+		t.Errorf("Publish() error = %v, want errSQLite", err)
+	}
+}
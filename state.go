@@ -0,0 +1,354 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+const (
+	firestoreScheme = "firestore://"
+
+	// firestoreValueField is the field of the state documents holding the gzip compressed value,
+	// which keeps large snapshots within the size limit of Firestore documents.
+	firestoreValueField = "value"
+
+	// stateKeySnapshot is the key of the snapshot of the previous run.
+	stateKeySnapshot = "snapshot"
+
+	// firestoreTransactionAttempts is the number of attempts of a transaction aborted by
+	// concurrent updates of its document.
+	firestoreTransactionAttempts = 5
+)
+
+var errInvalidStateStore = errors.New("invalid state store, expected a directory or firestore://PROJECT[/DATABASE]/COLLECTION")
+
+// StateStore is an interface for the state kept between runs, such as the snapshot of the
+// previous run, so that ephemeral executions do not need to manage files or buckets.
+type StateStore interface {
+	// Get returns the value of the key, or nil if it is not set.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+}
+
+// stateUpdater is implemented by the state stores updating a key atomically, so that
+// concurrent updates of the key, such as the Slack actions handled by several instances of the
+// server, do not overwrite each other.
+type stateUpdater interface {
+	// Update replaces the value of the key, or nil if it is not set, with the value returned by
+	// update, which may be called again if the key is updated concurrently.
+	Update(ctx context.Context, key string, update func(value []byte) ([]byte, error)) error
+}
+
+// updateState replaces the value of the key with its update, atomically if the store supports
+// it, and with a read followed by a write otherwise.
+func updateState(ctx context.Context, state StateStore, key string, update func([]byte) ([]byte, error)) error {
+	if updater, ok := state.(stateUpdater); ok {
+		return updater.Update(ctx, key, update)
+	}
+
+	value, err := state.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if value, err = update(value); err != nil {
+		return err
+	}
+
+	return state.Put(ctx, key, value)
+}
+
+// FileStateStore keeps every key in a file of the directory.
+type FileStateStore struct {
+	dir string
+
+	// mu serializes the updates of the keys. The directory is local to the host, so the
+	// updates of other processes sharing it are not serialized.
+	mu sync.Mutex
+}
+
+// NewFileStateStore creates a new state store in the directory.
+func NewFileStateStore(dir string) *FileStateStore {
+	return &FileStateStore{dir: dir}
+}
+
+// Get reads the file of the key.
+func (s *FileStateStore) Get(_ context.Context, key string) ([]byte, error) {
+	value, err := os.ReadFile(filepath.Join(s.dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Put writes the file of the key, creating the directory if needed.
+func (s *FileStateStore) Put(_ context.Context, key string, value []byte) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.dir, key), value, 0o600); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Update reads and writes the file of the key, serialized with the other updates of the store.
+func (s *FileStateStore) Update(ctx context.Context, key string, update func([]byte) ([]byte, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if value, err = update(value); err != nil {
+		return err
+	}
+
+	return s.Put(ctx, key, value)
+}
+
+// FirestoreStateStore keeps every key in a document of a Firestore collection.
+type FirestoreStateStore struct {
+	client     *http.Client
+	documents  *firestore.ProjectsDatabasesDocumentsService
+	database   string
+	collection string
+}
+
+// parseFirestoreURL returns the resource name of the collection of a
+// firestore://PROJECT[/DATABASE]/COLLECTION URL, using the (default) database if not set.
+func parseFirestoreURL(s string) (string, error) {
+	project, collection, _ := strings.Cut(strings.TrimPrefix(s, firestoreScheme), "/")
+	database := "(default)"
+
+	if d, c, ok := strings.Cut(collection, "/"); ok {
+		database, collection = d, c
+	}
+
+	if project == "" || database == "" || collection == "" || strings.Contains(collection, "/") {
+		return "", fmt.Errorf("%w: %q", errInvalidStateStore, s)
+	}
+
+	return "projects/" + project + "/databases/" + database + "/documents/" + collection, nil
+}
+
+// NewFirestoreStateStore creates a new state store of the firestore:// URL.
+func NewFirestoreStateStore(ctx context.Context, url string, opts ...option.ClientOption) (*FirestoreStateStore, error) {
+	collection, err := parseFirestoreURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]option.ClientOption{option.WithScopes(firestore.DatastoreScope)}, opts...)
+
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Firestore client: %w", err)
+	}
+
+	s, err := firestore.NewService(ctx, append(opts, option.WithHTTPClient(client))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Firestore client: %w", err)
+	}
+
+	database, _, _ := strings.Cut(collection, "/documents/")
+
+	return &FirestoreStateStore{
+		client:     client,
+		documents:  s.Projects.Databases.Documents,
+		database:   database,
+		collection: collection,
+	}, nil
+}
+
+// Close closes the connections of the client.
+func (s *FirestoreStateStore) Close() error {
+	s.client.CloseIdleConnections()
+
+	return nil
+}
+
+// Get reads the document of the key.
+func (s *FirestoreStateStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.get(ctx, key, "")
+}
+
+// get reads the document of the key, within the transaction if any.
+func (s *FirestoreStateStore) get(ctx context.Context, key, transaction string) ([]byte, error) {
+	call := s.documents.Get(s.collection + "/" + key).Context(ctx)
+	if transaction != "" {
+		call = call.Transaction(transaction)
+	}
+
+	doc, err := call.Do()
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state %s: %w", key, err)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(doc.Fields[firestoreValueField].BytesValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode state %s: %w", key, err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress state %s: %w", key, err)
+	}
+
+	value, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress state %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Put creates or replaces the document of the key.
+func (s *FirestoreStateStore) Put(ctx context.Context, key string, value []byte) error {
+	doc, err := firestoreStateDocument(key, value)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.documents.Patch(s.collection+"/"+key, doc).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to put state %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Update reads and replaces the document of the key in a transaction, which Firestore aborts
+// if the document is updated concurrently, such as by another instance of the server, and which
+// is then retried with the updated document.
+func (s *FirestoreStateStore) Update(ctx context.Context, key string, update func([]byte) ([]byte, error)) error {
+	for attempt := 1; ; attempt++ {
+		err := s.update(ctx, key, update)
+
+		var apiErr *googleapi.Error
+		if attempt < firestoreTransactionAttempts && errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+			continue
+		}
+
+		return err
+	}
+}
+
+// update runs a single transaction of Update.
+func (s *FirestoreStateStore) update(ctx context.Context, key string, update func([]byte) ([]byte, error)) error {
+	tx, err := s.documents.BeginTransaction(s.database, &firestore.BeginTransactionRequest{}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to begin the transaction of state %s: %w", key, err)
+	}
+
+	doc, err := s.updatedDocument(ctx, key, tx.Transaction, update)
+	if err != nil {
+		rollback := &firestore.RollbackRequest{Transaction: tx.Transaction}
+		_, _ = s.documents.Rollback(s.database, rollback).Context(context.WithoutCancel(ctx)).Do()
+
+		return err
+	}
+
+	commit := &firestore.CommitRequest{Transaction: tx.Transaction, Writes: []*firestore.Write{{Update: doc}}}
+	if _, err := s.documents.Commit(s.database, commit).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to put state %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// updatedDocument returns the document of the update of the key read in the transaction.
+func (s *FirestoreStateStore) updatedDocument(
+	ctx context.Context, key, transaction string, update func([]byte) ([]byte, error),
+) (*firestore.Document, error) {
+	value, err := s.get(ctx, key, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err = update(value); err != nil {
+		return nil, err
+	}
+
+	doc, err := firestoreStateDocument(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Name = s.collection + "/" + key
+
+	return doc, nil
+}
+
+// firestoreStateDocument returns the document holding the gzip compressed value of the key.
+func firestoreStateDocument(key string, value []byte) (*firestore.Document, error) {
+	var compressed bytes.Buffer
+
+	w := gzip.NewWriter(&compressed)
+	if _, err := w.Write(value); err != nil {
+		return nil, fmt.Errorf("failed to compress state %s: %w", key, err)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress state %s: %w", key, err)
+	}
+
+	return &firestore.Document{Fields: map[string]firestore.Value{
+		firestoreValueField: {BytesValue: base64.StdEncoding.EncodeToString(compressed.Bytes())},
+	}}, nil
+}
+
+// stateSnapshotStore keeps the snapshot in a state store.
+type stateSnapshotStore struct {
+	state StateStore
+}
+
+// Load reads the snapshot, if it is set.
+func (s stateSnapshotStore) Load(ctx context.Context) (*Snapshot, error) {
+	value, err := s.state.Get(ctx, stateKeySnapshot)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	return decodeSnapshot(bytes.NewReader(value))
+}
+
+// Save writes the snapshot.
+func (s stateSnapshotStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	return s.state.Put(ctx, stateKeySnapshot, value)
+}
@@ -0,0 +1,242 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/api/firestore/v1"
+	"google.golang.org/api/option"
+)
+
+func TestParseFirestoreURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "firestore://p/state", want: "projects/p/databases/(default)/documents/state"},
+		{url: "firestore://p/db/state", want: "projects/p/databases/db/documents/state"},
+		{url: "firestore://p"},
+		{url: "firestore:///state"},
+		{url: "firestore://p/db/state/extra"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFirestoreURL(tt.url)
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("parseFirestoreURL(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+}
+
+func TestStateSnapshotStore_File(t *testing.T) {
+	ctx := t.Context()
+	store := stateSnapshotStore{state: NewFileStateStore(t.TempDir())}
+
+	snapshot, err := store.Load(ctx)
+	if err != nil || snapshot != nil {
+		t.Fatalf("expected no snapshot, got %+v, %v", snapshot, err)
+	}
+
+	if err := store.Save(ctx, &Snapshot{RunID: "run1", Assets: []ProcessedAsset{{Name: "a1"}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	snapshot, err = store.Load(ctx)
+	if err != nil || snapshot.RunID != "run1" || len(snapshot.Assets) != 1 {
+		t.Errorf("unexpected snapshot %+v, %v", snapshot, err)
+	}
+}
+
+func TestFirestoreStateStore(t *testing.T) {
+	const documentPath = "/v1/projects/p/databases/(default)/documents/state/snapshot"
+
+	var stored *firestore.Document
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != documentPath {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "not found"}}`))
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(stored)
+		case http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			stored = &firestore.Document{}
+			_ = json.Unmarshal(body, stored)
+			_, _ = w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	state, err := NewFirestoreStateStore(ctx, "firestore://p/state",
+		option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewFirestoreStateStore failed: %v", err)
+	}
+
+	store := stateSnapshotStore{state: state}
+
+	snapshot, err := store.Load(ctx)
+	if err != nil || snapshot != nil {
+		t.Fatalf("expected no snapshot, got %+v, %v", snapshot, err)
+	}
+
+	if err := store.Save(ctx, &Snapshot{RunID: "run1", Assets: []ProcessedAsset{{Name: "a1"}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if stored.Fields[firestoreValueField].BytesValue == "" {
+		t.Fatalf("expected the value field to be set, got %+v", stored.Fields)
+	}
+
+	snapshot, err = store.Load(ctx)
+	if err != nil || snapshot.RunID != "run1" || len(snapshot.Assets) != 1 {
+		t.Errorf("unexpected snapshot %+v, %v", snapshot, err)
+	}
+
+	if err := state.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestFirestoreStateStore_Update(t *testing.T) {
+	const databasePath = "/v1/projects/p/databases/(default)/documents"
+
+	var (
+		stored  *firestore.Document
+		commits int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == databasePath+":beginTransaction":
+			_, _ = w.Write([]byte(`{"transaction": "dHg="}`))
+		case r.URL.Path == databasePath+"/state/acknowledgments" && r.Method == http.MethodGet:
+			if commits < 2 && r.URL.Query().Get("transaction") != "dHg=" {
+				t.Errorf("expected the document to be read in the transaction, got %s", r.URL.RawQuery)
+			}
+
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "not found"}}`))
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(stored)
+		case r.URL.Path == databasePath+":commit":
+			var commit firestore.CommitRequest
+			_ = json.NewDecoder(r.Body).Decode(&commit)
+
+			commits++
+
+			// The first commit is aborted by a concurrent update of the document.
+			if commits == 1 {
+				stored, _ = firestoreStateDocument("acknowledgments", []byte("concurrent"))
+
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error": {"code": 409, "status": "ABORTED", "message": "aborted"}}`))
+
+				return
+			}
+
+			stored = commit.Writes[0].Update
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	state, err := NewFirestoreStateStore(ctx, "firestore://p/state",
+		option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("NewFirestoreStateStore failed: %v", err)
+	}
+	defer state.Close()
+
+	read := []string{}
+
+	err = state.Update(ctx, "acknowledgments", func(value []byte) ([]byte, error) {
+		read = append(read, string(value))
+
+		return append(value, "+update"...), nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if len(read) != 2 || read[0] != "" || read[1] != "concurrent" {
+		t.Errorf("expected the update to be retried with the concurrent value, read %q", read)
+	}
+
+	value, err := state.Get(ctx, "acknowledgments")
+	if err != nil || string(value) != "concurrent+update" {
+		t.Errorf("Get() = %q, %v, want %q", value, err, "concurrent+update")
+	}
+}
+
+// closingStateStore is a state store recording how many times it is closed.
+type closingStateStore struct {
+	memoryStateStore
+
+	closed int
+}
+
+func (s *closingStateStore) Close() error {
+	s.closed++
+
+	return nil
+}
+
+func TestNewRunStores(t *testing.T) {
+	state := &closingStateStore{memoryStateStore: memoryStateStore{values: map[string][]byte{}}}
+	ctx := withSelftestServices(t.Context(), &selftestServices{stateStore: state})
+	logger := slog.New(slog.DiscardHandler)
+
+	cfg := ConfigDefaults
+	cfg.StateStore = "memory"
+
+	stores := newRunStores(ctx, logger, &cfg)
+
+	snapshot, ok := stores.snapshot.(stateSnapshotStore)
+	if stores.state != state || !ok || snapshot.state != state {
+		t.Fatalf("expected the snapshot in the state store of the run, got %+v", stores)
+	}
+
+	closeRunStores(ctx, logger, stores)
+
+	if state.closed != 1 {
+		t.Errorf("expected the state store to be closed once, got %d", state.closed)
+	}
+
+	cfg.StateStore = ""
+
+	if stores := newRunStores(t.Context(), logger, &cfg); stores.state != nil || stores.snapshot != nil {
+		t.Errorf("expected no stores without configuration, got %+v", stores)
+	}
+}
@@ -0,0 +1,171 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// namespacedTagParts is the number of parts of a namespaced tag value, ORG_ID/KEY/VALUE.
+const namespacedTagParts = 3
+
+var errInvalidTag = errors.New("invalid tag")
+
+// parseTagValue converts a key=value tag to the ORG_ID/KEY/VALUE namespaced name of the tag
+// value in the organization. Namespaced names are returned as is.
+// https://cloud.google.com/resource-manager/docs/tags/tags-overview
+func parseTagValue(tag, orgID string) (string, error) {
+	tag = strings.TrimSpace(tag)
+
+	if strings.Contains(tag, "/") {
+		parts := strings.Split(tag, "/")
+		if len(parts) != namespacedTagParts || slices.Contains(parts, "") {
+			return "", fmt.Errorf("%w: %q, expected key=value or ORG_ID/KEY/VALUE", errInvalidTag, tag)
+		}
+
+		return tag, nil
+	}
+
+	key, value, ok := strings.Cut(tag, "=")
+	if !ok || key == "" || value == "" {
+		return "", fmt.Errorf("%w: %q, expected key=value or ORG_ID/KEY/VALUE", errInvalidTag, tag)
+	}
+
+	return orgID + "/" + key + "/" + value, nil
+}
+
+// TagAction binds a Resource Manager tag value to the assets flagged by policy violations,
+// so organization policies and IAM conditions can act on them.
+type TagAction struct {
+	tagValue string
+	dryRun   bool
+	opts     []option.ClientOption
+	services map[string]*cloudresourcemanager.Service
+	logger   *slog.Logger
+}
+
+// NewTagAction creates a new tag binding action for the configured tag value.
+func NewTagAction(_ context.Context, logger *slog.Logger, cfg *Config, opts ...option.ClientOption) (*TagAction, error) {
+	tagValue, err := parseTagValue(cfg.Tag, cfg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TagAction{
+		tagValue: tagValue,
+		dryRun:   cfg.TagDryRun,
+		opts:     opts,
+		services: make(map[string]*cloudresourcemanager.Service),
+		logger:   logger.With(slog.String("component", "asset-watcher")),
+	}, nil
+}
+
+// Name returns the name of the sink.
+func (a *TagAction) Name() string {
+	return "tags"
+}
+
+// Publish binds the tag value to every resource flagged by a policy violation.
+// Every binding, including the ones skipped in dry-run mode, is logged for auditing.
+func (a *TagAction) Publish(ctx context.Context, report *Report) error {
+	seen := make(map[string]bool)
+
+	for _, violation := range report.Violations {
+		asset := violation.Asset
+		if asset.ResourceName == "" || seen[asset.ResourceName] {
+			continue
+		}
+
+		seen[asset.ResourceName] = true
+
+		audit := a.logger.With(
+			slog.String("action", "bind_tag"),
+			slog.String("resource", asset.ResourceName),
+			slog.String("tag_value", a.tagValue),
+			slog.String("rule", violation.Rule),
+			slog.Bool("dry_run", a.dryRun),
+		)
+
+		if a.dryRun {
+			audit.InfoContext(ctx, "Would bind tag")
+
+			continue
+		}
+
+		if err := a.bind(ctx, asset); err != nil {
+			audit.ErrorContext(ctx, "failed to bind tag", slog.Any("error", err))
+
+			return err
+		}
+
+		audit.InfoContext(ctx, "Bound tag")
+	}
+
+	return nil
+}
+
+// Close is a no-op, as the REST clients do not hold any resources.
+func (a *TagAction) Close() error {
+	return nil
+}
+
+func (a *TagAction) bind(ctx context.Context, asset ProcessedAsset) error {
+	service, err := a.service(ctx, asset.Location)
+	if err != nil {
+		return err
+	}
+
+	binding := &cloudresourcemanager.TagBinding{
+		Parent:                 asset.ResourceName,
+		TagValueNamespacedName: a.tagValue,
+	}
+
+	_, err = service.TagBindings.Create(binding).Context(ctx).Do()
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+		return nil // The tag value is already bound to the resource.
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to bind tag to %s: %w", asset.ResourceName, err)
+	}
+
+	return nil
+}
+
+// service returns a Resource Manager client for the location of the resource,
+// as tags of regional and zonal resources are managed through location-specific endpoints.
+// https://cloud.google.com/resource-manager/docs/tags/tags-creating-and-managing#attaching
+func (a *TagAction) service(ctx context.Context, location string) (*cloudresourcemanager.Service, error) {
+	if location == "" {
+		location = "global"
+	}
+
+	if s, ok := a.services[location]; ok {
+		return s, nil
+	}
+
+	opts := a.opts
+	if location != "global" {
+		endpoint := "https://" + location + "-cloudresourcemanager.googleapis.com/"
+		opts = append([]option.ClientOption{option.WithEndpoint(endpoint)}, a.opts...)
+	}
+
+	s, err := cloudresourcemanager.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource manager client: %w", err)
+	}
+
+	a.services[location] = s
+
+	return s, nil
+}
@@ -0,0 +1,129 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/api/option"
+)
+
+func TestParseTagValue(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "asset-watcher-reviewed=false", want: "123/asset-watcher-reviewed/false"},
+		{tag: "456/asset-watcher-reviewed/false", want: "456/asset-watcher-reviewed/false"},
+		{tag: "reviewed", wantErr: true},
+		{tag: "reviewed=", wantErr: true},
+		{tag: "asset-watcher/reviewed=false", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := parseTagValue(tt.tag, "123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTagValue(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseTagValue(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestTagReport() *Report {
+	address := ProcessedAsset{
+		Name:         "a1",
+		Location:     "us-central1",
+		ResourceName: "//compute.googleapis.com/projects/p/regions/us-central1/addresses/a1",
+	}
+
+	return &Report{Violations: []RuleViolation{
+		{Rule: ruleOrphanedExternalAddress, Asset: address},
+		{Rule: "another-rule", Asset: address},
+	}}
+}
+
+func TestTagAction_Publish(t *testing.T) {
+	var bindings []map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/tagBindings" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+
+		binding := map[string]string{}
+		_ = json.Unmarshal(body, &binding)
+		bindings = append(bindings, binding)
+
+		if len(bindings) > 1 {
+			http.Error(w, `{"error": {"code": 409, "message": "already exists"}}`, http.StatusConflict)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "operations/1"}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	action, err := NewTagAction(ctx, slog.New(slog.DiscardHandler), &Config{OrgID: "123", Tag: "reviewed=false"},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewTagAction failed: %v", err)
+	}
+
+	report := newTestTagReport()
+
+	if err := action.Publish(ctx, report); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(bindings) != 1 {
+		t.Fatalf("expected 1 binding for a resource with 2 violations, got %d", len(bindings))
+	}
+
+	want := map[string]string{"parent": report.Violations[0].Asset.ResourceName, "tagValueNamespacedName": "123/reviewed/false"}
+	if bindings[0]["parent"] != want["parent"] || bindings[0]["tagValueNamespacedName"] != want["tagValueNamespacedName"] {
+		t.Errorf("unexpected binding %v, want %v", bindings[0], want)
+	}
+
+	// An existing binding is not an error.
+	if err := action.Publish(ctx, report); err != nil {
+		t.Errorf("expected an existing binding to be ignored, got %v", err)
+	}
+}
+
+func TestTagAction_DryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request in dry-run mode: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	action, err := NewTagAction(ctx, slog.New(slog.DiscardHandler), &Config{OrgID: "123", Tag: "reviewed=false", TagDryRun: true},
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewTagAction failed: %v", err)
+	}
+
+	if err := action.Publish(ctx, newTestTagReport()); err != nil {
+		t.Errorf("Publish failed: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package assetwatcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Microsoft Teams rejects incoming webhook messages larger than 28 KB.
+// https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using#send-messages-using-curl-and-powershell
+const (
+	teamsMaxPayloadBytes = 28 * 1024
+	teamsMaxItems        = 200
+
+	// teamsPayloadOverhead is the size reserved for the card structure, the title, the summary,
+	// the footer, and the JSON escaping of the items.
+	teamsPayloadOverhead = 4 * 1024
+)
+
+// TeamsNotifier posts notifications to a Microsoft Teams channel through an incoming webhook.
+type TeamsNotifier struct {
+	client     *http.Client
+	webhookURL string
+	logger     *slog.Logger
+}
+
+// NewTeamsNotifier creates a new Microsoft Teams notifier for the configured webhook.
+func NewTeamsNotifier(logger *slog.Logger, cfg *Config, client *http.Client) *TeamsNotifier {
+	return &TeamsNotifier{
+		client:     client,
+		webhookURL: cfg.TeamsWebhookURL,
+		logger:     logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the notifier.
+func (n *TeamsNotifier) Name() string {
+	return "teams"
+}
+
+// Notify posts the notification as a single message card. Items that do not fit into
+// the payload limit are replaced with a footer.
+func (n *TeamsNotifier) Notify(ctx context.Context, notification Notification) error {
+	if _, err := postJSON(ctx, n.client, n.webhookURL, nil, teamsCard(notification)); err != nil {
+		return fmt.Errorf("failed to post Teams message: %w", err)
+	}
+
+	n.logger.DebugContext(ctx, "Sent Teams notification")
+
+	return nil
+}
+
+// teamsCard renders the notification as a legacy actionable message card, with the body
+// rendered by a template, if any, as its text.
+// https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+func teamsCard(notification Notification) map[string]any {
+	budget := teamsMaxPayloadBytes - teamsPayloadOverhead - len(notification.Title) - len(notification.Summary)
+
+	if notification.Body != "" {
+		return map[string]any{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  notification.Title,
+			"title":    notification.Title,
+			"text":     truncateString(notification.Body, budget+len(notification.Summary)),
+		}
+	}
+
+	items, omitted := limitItems(notification.Items, notification.itemsLimit(teamsMaxItems), budget, len("- \n\n"))
+	if len(items) == 0 && omitted > 0 {
+		items = []string{truncateString(notification.Items[0], budget)}
+		omitted--
+	}
+
+	var text strings.Builder
+
+	text.WriteString(notification.Summary + "\n\n")
+
+	for _, item := range items {
+		text.WriteString("- " + item + "\n\n")
+	}
+
+	if footer := moreItemsFooter(omitted, notification.ArtifactURL); footer != "" {
+		text.WriteString("_" + footer + "_")
+	}
+
+	return map[string]any{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  notification.Title,
+		"title":    notification.Title,
+		"text":     text.String(),
+	}
+}
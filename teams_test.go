@@ -0,0 +1,59 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTeamsCard(t *testing.T) {
+	card := teamsCard(Notification{Title: "title", Summary: "summary", Items: manyItems(1000, 100), ArtifactURL: "https://example.com/r.json"})
+
+	payload, err := json.Marshal(card)
+	if err != nil {
+		t.Fatalf("failed to marshal card: %v", err)
+	}
+
+	if len(payload) > teamsMaxPayloadBytes {
+		t.Errorf("payload of %d bytes exceeds the limit", len(payload))
+	}
+
+	text, _ := card["text"].(string)
+	if !strings.Contains(text, "800 more items") || !strings.Contains(text, "https://example.com/r.json") {
+		t.Errorf("expected a footer with the artifact link, got %q", text[max(len(text)-200, 0):])
+	}
+}
+
+func TestTeamsCard_MaxItems(t *testing.T) {
+	card := teamsCard(Notification{Title: "title", Summary: "summary", Items: manyItems(30, 10), MaxItems: 10})
+
+	text, _ := card["text"].(string)
+	if got := strings.Count(text, "- "); got != 10 || !strings.Contains(text, "20 more items") {
+		t.Errorf("expected 10 items and a footer of 20 more items, got %d items in %q", got, text)
+	}
+}
+
+func TestTeamsCard_Body(t *testing.T) {
+	card := teamsCard(Notification{Title: "title", Summary: "summary", Items: manyItems(30, 10), Body: "custom body"})
+
+	if text, _ := card["text"].(string); text != "custom body" {
+		t.Errorf("expected the body as text, got %q", text)
+	}
+}
+
+func TestTeamsNotifier_Notify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "Webhook message delivery failed", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	notifier := NewTeamsNotifier(slog.New(slog.DiscardHandler), &Config{TeamsWebhookURL: server.URL}, server.Client())
+
+	err := notifier.Notify(t.Context(), Notification{Title: "title"})
+	if err == nil || strings.Contains(err.Error(), server.URL) {
+		t.Errorf("expected an error without the webhook URL, got %v", err)
+	}
+}
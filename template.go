@@ -0,0 +1,121 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are the helper functions available to output templates, in addition to the
+// builtin functions of text/template.
+var templateFuncs = template.FuncMap{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"pad":        func(width int, s string) string { return fmt.Sprintf("%-*s", width, s) },
+	"default":    templateDefault,
+	"csv":        templateCSV,
+	"json":       templateJSON,
+	"keyValues":  formatKeyValues,
+	"cost":       func(cost float64) string { return fmt.Sprintf("%.2f", cost) },
+	"timeFormat": func(layout string, t time.Time) string { return t.Format(layout) },
+	"now":        func() time.Time { return time.Now().UTC() },
+	"markdown":   convertHTMLToMarkdown,
+}
+
+// loadOutputTemplate parses the template file of ASSET_WATCHER_OUTPUT_TEMPLATE.
+func loadOutputTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // The path is provided by the operator.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// writeTemplate renders the report through the template. The template is executed with
+// the report, so that .Assets, .Summary, and .Metadata are available.
+func writeTemplate(w io.Writer, tmpl *template.Template, report *Report) error {
+	bw := bufio.NewWriter(w)
+
+	if err := tmpl.Execute(bw, report); err != nil {
+		return fmt.Errorf("failed to render output template: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+func outputTemplate(ctx context.Context, logger *slog.Logger, w io.Writer, report *Report, cfg *Config) {
+	// The template is validated by GetConfig.
+	tmpl, err := loadOutputTemplate(cfg.OutputTemplate)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load output template", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+
+	if err := writeTemplate(w, tmpl, report); err != nil {
+		logger.ErrorContext(ctx, "failed to render output template", slog.Any("error", err))
+		exit(ctx, 1)
+	}
+}
+
+// templateDefault returns the value, or the fallback if the value is empty, as in
+// {{ .Age | default "n/a" }}.
+func templateDefault(fallback, value string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+// templateCSV formats the values as a CSV record, quoting them as needed, without the
+// trailing newline.
+func templateCSV(values ...string) (string, error) {
+	var b strings.Builder
+
+	cw := csv.NewWriter(&b)
+	if err := cw.Write(values); err != nil {
+		return "", fmt.Errorf("failed to format CSV record: %w", err)
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return "", fmt.Errorf("failed to format CSV record: %w", err)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// templateJSON formats the value as compact JSON.
+func templateJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to format JSON: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,88 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	report := &Report{
+		Metadata: RunMetadata{OrgID: "123", StartedAt: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		Assets: []ProcessedAsset{
+			{Name: "nat-1", Project: "prod-network", IPAddress: "1.1.1.1", Labels: map[string]string{"team": "net"}},
+			{Name: "lb, \"primary\"", Project: "prod-web"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "csv",
+			template: `{{ range .Assets }}{{ csv .Name .Project (.IPAddress | default "none") }}` + "\n" + `{{ end }}`,
+			want:     "nat-1,prod-network,1.1.1.1\n\"lb, \"\"primary\"\"\",prod-web,none\n",
+		},
+		{
+			name:     "helpers",
+			template: `{{ upper .Metadata.OrgID }} {{ timeFormat "2006-01-02" .Metadata.StartedAt }} {{ with index .Assets 0 }}{{ keyValues .Labels }} {{ json .Labels }}{{ end }}`,
+			want:     `123 2024-01-10 team=net {"team":"net"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name+".tmpl")
+			if err := os.WriteFile(path, []byte(tt.template), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			tmpl, err := loadOutputTemplate(path)
+			if err != nil {
+				t.Fatalf("loadOutputTemplate() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := writeTemplate(&buf, tmpl, report); err != nil {
+				t.Fatalf("writeTemplate() error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadOutputTemplate_Example(t *testing.T) {
+	tmpl, err := loadOutputTemplate(filepath.Join("examples", "confluence.tmpl"))
+	if err != nil {
+		t.Fatalf("loadOutputTemplate() error = %v", err)
+	}
+
+	report := &Report{Assets: []ProcessedAsset{{Name: "nat-1"}}, Summary: Summary{TotalAssets: 1}}
+
+	var buf bytes.Buffer
+	if err := writeTemplate(&buf, tmpl, report); err != nil {
+		t.Fatalf("writeTemplate() error = %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("|nat-1|")) {
+		t.Errorf("writeTemplate() = %q, want a row of the asset", buf.String())
+	}
+}
+
+func TestLoadOutputTemplate_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.tmpl")
+	if err := os.WriteFile(path, []byte("{{ unknownFunc .Assets }}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadOutputTemplate(path); err == nil {
+		t.Error("loadOutputTemplate() error = nil, want an error for an undefined function")
+	}
+}
@@ -0,0 +1,164 @@
+package assetwatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// terraformDataSourceCommand runs asset-watcher as a program of the Terraform external data source.
+const terraformDataSourceCommand = "terraform-data-source"
+
+// Query keys supported by the Terraform data source.
+const (
+	terraformQueryIPAddress = "ip_address"
+	terraformQueryCIDR      = "cidr"
+	terraformQueryProject   = "project"
+	terraformQueryReportURL = "report_url"
+)
+
+var (
+	errInvalidTerraformQuery = errors.New("invalid Terraform query")
+	errReportUnavailable     = errors.New("report is unavailable")
+)
+
+// runTerraformDataSource implements the protocol of the Terraform external data source: it reads
+// a JSON object of strings from r and writes a JSON object of strings describing the matching
+// addresses to w. The addresses are read from the report published at the report_url query key,
+// such as the artifact of a scheduled run, or fetched by a scan if it is not set.
+func runTerraformDataSource(ctx context.Context, logger *slog.Logger, cfg *Config, r io.Reader, w io.Writer) error {
+	query := map[string]string{}
+	if err := json.NewDecoder(r).Decode(&query); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidTerraformQuery, err)
+	}
+
+	var (
+		report *Report
+		err    error
+	)
+
+	if reportURL := query[terraformQueryReportURL]; reportURL != "" {
+		report, err = fetchReport(ctx, newHTTPClient(cfg), reportURL)
+		if err != nil {
+			return err
+		}
+	} else {
+		stores := newRunStores(ctx, logger, cfg)
+		defer closeRunStores(ctx, logger, stores)
+
+		report = runScan(ctx, logger, cfg, stores, time.Now())
+	}
+
+	result, err := answerTerraformQuery(report, query)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		return fmt.Errorf("failed to encode the Terraform result: %w", err)
+	}
+
+	return nil
+}
+
+// fetchReport reads a report published as JSON at the URL.
+func fetchReport(ctx context.Context, client *http.Client, reportURL string) (*Report, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reportURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", errReportUnavailable, resp.StatusCode)
+	}
+
+	return ReadReport(resp.Body)
+}
+
+// answerTerraformQuery returns the addresses of the report matching the query. Terraform requires
+// all values to be strings, so lists are comma-separated. An ip_address query also reports whether
+// the address is allocated and the asset it is allocated to, e.g. to validate planned reservations.
+func answerTerraformQuery(report *Report, query map[string]string) (map[string]string, error) {
+	var (
+		ip     netip.Addr
+		prefix netip.Prefix
+		err    error
+	)
+
+	for key := range query {
+		switch key {
+		case terraformQueryIPAddress, terraformQueryCIDR, terraformQueryProject, terraformQueryReportURL:
+		default:
+			return nil, fmt.Errorf("%w: unknown key %q", errInvalidTerraformQuery, key)
+		}
+	}
+
+	if s := query[terraformQueryIPAddress]; s != "" {
+		if ip, err = netip.ParseAddr(s); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidTerraformQuery, err)
+		}
+	}
+
+	if s := query[terraformQueryCIDR]; s != "" {
+		if prefix, err = netip.ParsePrefix(s); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidTerraformQuery, err)
+		}
+	}
+
+	project := query[terraformQueryProject]
+	addresses := []string{}
+	names := []string{}
+	result := map[string]string{}
+
+	for _, asset := range report.Assets {
+		if project != "" && asset.Project != project {
+			continue
+		}
+
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || (ip.IsValid() && addr != ip) || (prefix.IsValid() && !prefix.Contains(addr)) {
+				continue
+			}
+
+			if _, ok := result["name"]; !ok && ip.IsValid() {
+				result["name"] = asset.Name
+				result["project"] = asset.Project
+				result["status"] = asset.Status
+			}
+
+			addresses = append(addresses, address)
+			names = append(names, asset.Name)
+		}
+	}
+
+	if ip.IsValid() {
+		result["exists"] = strconv.FormatBool(len(addresses) > 0)
+	}
+
+	slices.Sort(addresses)
+	addresses = slices.Compact(addresses)
+	slices.Sort(names)
+
+	result["addresses"] = strings.Join(addresses, ",")
+	result["names"] = strings.Join(slices.Compact(names), ",")
+	result["count"] = strconv.Itoa(len(addresses))
+	result["run_id"] = report.Metadata.RunID
+
+	return result, nil
+}
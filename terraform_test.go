@@ -0,0 +1,109 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTerraformTestReport() *Report {
+	return &Report{
+		Metadata: RunMetadata{RunID: "run-1"},
+		Assets: []ProcessedAsset{
+			{Name: "nat-1", Project: "proj-A", Status: "IN_USE", IPAddress: "203.0.113.1"},
+			{Name: "nat-2", Project: "proj-B", Status: "RESERVED", IPAddress: "203.0.113.2"},
+			{Name: "vm", Project: "proj-A", Status: "RUNNING", IPAddress: "N/A", Attributes: map[string]string{"externalIPs": "198.51.100.9"}},
+		},
+	}
+}
+
+func TestAnswerTerraformQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "allocated address",
+			query: map[string]string{"ip_address": "203.0.113.2"},
+			want: map[string]string{
+				"exists": "true", "name": "nat-2", "project": "proj-B", "status": "RESERVED",
+				"addresses": "203.0.113.2", "names": "nat-2", "count": "1", "run_id": "run-1",
+			},
+		},
+		{
+			name:  "free address",
+			query: map[string]string{"ip_address": "203.0.113.3"},
+			want:  map[string]string{"exists": "false", "addresses": "", "names": "", "count": "0", "run_id": "run-1"},
+		},
+		{
+			name:  "cidr and project",
+			query: map[string]string{"cidr": "203.0.113.0/24", "project": "proj-A"},
+			want:  map[string]string{"addresses": "203.0.113.1", "names": "nat-1", "count": "1", "run_id": "run-1"},
+		},
+		{
+			name:  "instance external IP",
+			query: map[string]string{"cidr": "198.51.100.0/24"},
+			want:  map[string]string{"addresses": "198.51.100.9", "names": "vm", "count": "1", "run_id": "run-1"},
+		},
+		{name: "invalid address", query: map[string]string{"ip_address": "not-an-ip"}, wantErr: true},
+		{name: "invalid cidr", query: map[string]string{"cidr": "203.0.113.0/33"}, wantErr: true},
+		{name: "unknown key", query: map[string]string{"zone": "us-central1-a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := answerTerraformQuery(newTerraformTestReport(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("answerTerraformQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("answerTerraformQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTerraformDataSource_ReportURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = newTerraformTestReport().WriteJSON(w)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+
+	query := `{"report_url": "` + server.URL + `", "ip_address": "203.0.113.1"}`
+
+	err := runTerraformDataSource(t.Context(), slog.New(slog.DiscardHandler), &Config{}, strings.NewReader(query), &out)
+	if err != nil {
+		t.Fatalf("runTerraformDataSource failed: %v", err)
+	}
+
+	result := map[string]string{}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode the result: %v", err)
+	}
+
+	if result["exists"] != "true" || result["name"] != "nat-1" {
+		t.Errorf("unexpected result %v", result)
+	}
+}
+
+func TestRunTerraformDataSource_ReportUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	query := `{"report_url": "` + server.URL + `"}`
+
+	err := runTerraformDataSource(t.Context(), slog.New(slog.DiscardHandler), &Config{}, strings.NewReader(query), &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected an error for an unavailable report")
+	}
+}
@@ -0,0 +1,152 @@
+package assetwatcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// outputFormatTerraform renders the unmanaged addresses as Terraform import blocks and resources.
+const outputFormatTerraform = "terraform"
+
+// terraformImportAddresses returns the addresses to adopt into Terraform: the unmanaged
+// addresses of the Terraform drift, if the states were compared, or all the addresses.
+func terraformImportAddresses(report *Report) []ProcessedAsset {
+	if report.Terraform != nil {
+		return report.Terraform.Unmanaged
+	}
+
+	addresses := []ProcessedAsset{}
+
+	for _, asset := range report.Assets {
+		if asset.AssetType == "" || asset.AssetType == addressAssetType {
+			addresses = append(addresses, asset)
+		}
+	}
+
+	return addresses
+}
+
+// writeTerraformImports writes an import block and a skeleton resource for every address,
+// so that they can be adopted into Terraform with terraform plan and apply. The resource
+// arguments that cannot be derived from the inventory, such as the subnetwork of internal
+// addresses, are left as comments to complete.
+func writeTerraformImports(w io.Writer, addresses []ProcessedAsset) error {
+	bw := bufio.NewWriter(w)
+	used := map[string]bool{}
+
+	for i, asset := range addresses {
+		resourceType := terraformAddressType
+		if asset.Location == "global" {
+			resourceType = terraformGlobalAddressType
+		}
+
+		name := terraformResourceName(asset, used)
+
+		id := assetResource(asset)
+		if id == "" {
+			id = terraformAddressResource("", TerraformAddress{Name: asset.Name, Project: asset.Project, Location: asset.Location})
+		}
+
+		if i > 0 {
+			_, _ = fmt.Fprintln(bw)
+		}
+
+		_, _ = fmt.Fprintf(bw, "import {\n  to = %s.%s\n  id = %s\n}\n\n", resourceType, name, hclString(id))
+		_, _ = fmt.Fprintf(bw, "resource %q %q {\n", resourceType, name)
+
+		writeHCLAttribute(bw, "project", asset.Project)
+		writeHCLAttribute(bw, "name", asset.Name)
+
+		if resourceType == terraformAddressType {
+			writeHCLAttribute(bw, "region", asset.Location)
+		}
+
+		writeHCLAttribute(bw, "address", asset.IPAddress)
+		writeHCLAttribute(bw, "address_type", asset.AddressType)
+		writeHCLAttribute(bw, "network_tier", asset.Attributes["networkTier"])
+		writeHCLAttribute(bw, "purpose", asset.Attributes["purpose"])
+
+		if asset.AddressType == "INTERNAL" {
+			_, _ = fmt.Fprintln(bw, "  # subnetwork = \"\"")
+		}
+
+		if len(asset.Labels) > 0 {
+			_, _ = fmt.Fprintln(bw, "\n  labels = {")
+
+			for _, key := range slices.Sorted(maps.Keys(asset.Labels)) {
+				_, _ = fmt.Fprintf(bw, "    %s = %s\n", hclString(key), hclString(asset.Labels[key]))
+			}
+
+			_, _ = fmt.Fprintln(bw, "  }")
+		}
+
+		_, _ = fmt.Fprintln(bw, "}")
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write Terraform configuration: %w", err)
+	}
+
+	return nil
+}
+
+// writeHCLAttribute writes a string argument of a resource, unless the value is empty.
+func writeHCLAttribute(w io.Writer, name, value string) {
+	if value == "" {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "  %-12s = %s\n", name, hclString(value))
+}
+
+// hclString quotes a string literal, escaping the interpolation and directive sequences of HCL.
+func hclString(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
+
+// terraformResourceName returns a unique Terraform resource name of the asset, derived from
+// its name, or from its project and name if the name is already used.
+func terraformResourceName(asset ProcessedAsset, used map[string]bool) string {
+	name := terraformIdentifier(asset.Name)
+	if used[name] {
+		name = terraformIdentifier(asset.Project + "_" + asset.Name)
+	}
+
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + "_" + strconv.Itoa(n)
+	}
+
+	used[unique] = true
+
+	return unique
+}
+
+// terraformIdentifier converts a name into a Terraform identifier, replacing the characters
+// other than letters, digits, and underscores with underscores.
+func terraformIdentifier(s string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+
+	identifier := b.String()
+	if identifier == "" || identifier[0] < 'a' || identifier[0] > 'z' {
+		identifier = "address_" + identifier
+	}
+
+	return identifier
+}
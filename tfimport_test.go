@@ -0,0 +1,92 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTerraformImports(t *testing.T) {
+	report := &Report{
+		Assets: []ProcessedAsset{
+			{
+				Name: "nat-ip", Project: "p1", Location: "us-central1", IPAddress: "203.0.113.1",
+				AssetType: addressAssetType, AddressType: "EXTERNAL",
+				ResourceName: "//compute.googleapis.com/projects/p1/regions/us-central1/addresses/nat-ip",
+				Attributes:   map[string]string{"networkTier": "PREMIUM"},
+				Labels:       map[string]string{"team": "net", "env": "${prod}"},
+			},
+			{
+				Name: "nat-ip", Project: "p2", Location: "global", IPAddress: "198.51.100.1",
+				AssetType: addressAssetType,
+			},
+			{Name: "vm", AssetType: instanceAssetType},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTerraformImports(&buf, terraformImportAddresses(report)); err != nil {
+		t.Fatalf("writeTerraformImports failed: %v", err)
+	}
+
+	want := `import {
+  to = google_compute_address.nat_ip
+  id = "projects/p1/regions/us-central1/addresses/nat-ip"
+}
+
+resource "google_compute_address" "nat_ip" {
+  project      = "p1"
+  name         = "nat-ip"
+  region       = "us-central1"
+  address      = "203.0.113.1"
+  address_type = "EXTERNAL"
+  network_tier = "PREMIUM"
+
+  labels = {
+    "env" = "$${prod}"
+    "team" = "net"
+  }
+}
+
+import {
+  to = google_compute_global_address.p2_nat_ip
+  id = "projects/p2/global/addresses/nat-ip"
+}
+
+resource "google_compute_global_address" "p2_nat_ip" {
+  project      = "p2"
+  name         = "nat-ip"
+  address      = "198.51.100.1"
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected Terraform configuration:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTerraformImportAddresses_Unmanaged(t *testing.T) {
+	report := &Report{
+		Assets:    []ProcessedAsset{{Name: "managed"}, {Name: "manual"}},
+		Terraform: &TerraformDrift{Managed: 1, Unmanaged: []ProcessedAsset{{Name: "manual"}}},
+	}
+
+	addresses := terraformImportAddresses(report)
+	if len(addresses) != 1 || addresses[0].Name != "manual" {
+		t.Errorf("expected only the unmanaged address, got %+v", addresses)
+	}
+}
+
+func TestTerraformIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"nat-ip":   "nat_ip",
+		"My IP.1":  "my_ip_1",
+		"1st":      "address_1st",
+		"":         "address_",
+		"_private": "address__private",
+	}
+
+	for name, want := range tests {
+		if got := terraformIdentifier(name); got != want {
+			t.Errorf("terraformIdentifier(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
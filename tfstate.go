@@ -0,0 +1,289 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// Terraform resource types of the addresses compared with the inventory.
+const (
+	terraformAddressType       = "google_compute_address"
+	terraformGlobalAddressType = "google_compute_global_address"
+	terraformManagedMode       = "managed"
+
+	// terraformStateVersion is the state format of Terraform 0.12 and later.
+	terraformStateVersion = 4
+)
+
+var (
+	errTerraformStateUnavailable = errors.New("state is unavailable")
+	errUnsupportedTerraformState = errors.New("unsupported Terraform state version")
+)
+
+// TerraformAddress is an address declared in a Terraform state.
+type TerraformAddress struct {
+	Address   string `json:"address"`
+	State     string `json:"state"`
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Project   string `json:"project"`
+	Location  string `json:"location"`
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// TerraformDrift lists the differences between the addresses declared in the Terraform
+// states and the addresses found in the inventory.
+type TerraformDrift struct {
+	Managed   int                `json:"managed"`
+	Unmanaged []ProcessedAsset   `json:"unmanaged"`
+	Missing   []TerraformAddress `json:"missing"`
+}
+
+// terraformState is the subset of the Terraform state format, version 4, read by asset-watcher.
+type terraformState struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// terraformStateReader reads Terraform states from local files, the gs:// objects of the
+// gcs backend, and the http(s):// addresses of the http backend.
+type terraformStateReader struct {
+	client      *http.Client
+	storageOpts []option.ClientOption
+}
+
+// read returns the raw state of the source.
+func (r terraformStateReader) read(ctx context.Context, source string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(source, gcsScheme):
+		return r.readGCS(ctx, source)
+	case strings.HasPrefix(source, "https://"), strings.HasPrefix(source, "http://"):
+		return r.readHTTP(ctx, source)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Terraform state: %w", err)
+	}
+
+	return data, nil
+}
+
+func (r terraformStateReader) readGCS(ctx context.Context, source string) ([]byte, error) {
+	bucket, object, ok := strings.Cut(strings.TrimPrefix(source, gcsScheme), "/")
+	if !ok || bucket == "" || object == "" {
+		return nil, fmt.Errorf("%w: %q, expected gs://BUCKET/OBJECT", errTerraformStateUnavailable, source)
+	}
+
+	s, err := storage.NewService(ctx, r.storageOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage client: %w", err)
+	}
+
+	resp, err := s.Objects.Get(bucket, object).Context(ctx).Download()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download Terraform state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download Terraform state: %w", err)
+	}
+
+	return data, nil
+}
+
+func (r terraformStateReader) readHTTP(ctx context.Context, source string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Terraform state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", errTerraformStateUnavailable, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Terraform state: %w", err)
+	}
+
+	return data, nil
+}
+
+// parseTerraformAddresses returns the managed google_compute_address and
+// google_compute_global_address resources of a state.
+func parseTerraformAddresses(source string, data []byte) ([]TerraformAddress, error) {
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode Terraform state %s: %w", source, err)
+	}
+
+	if state.Version != terraformStateVersion {
+		return nil, fmt.Errorf("%w: %d in %s", errUnsupportedTerraformState, state.Version, source)
+	}
+
+	addresses := []TerraformAddress{}
+
+	for _, resource := range state.Resources {
+		if resource.Mode != terraformManagedMode ||
+			(resource.Type != terraformAddressType && resource.Type != terraformGlobalAddressType) {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			attribute := func(key string) string {
+				value, _ := instance.Attributes[key].(string)
+
+				return value
+			}
+
+			location := cmp.Or(attribute("region"), "global")
+
+			address := TerraformAddress{
+				Address:   terraformResourceAddress(resource.Module, resource.Type, resource.Name, instance.IndexKey),
+				State:     source,
+				Name:      attribute("name"),
+				Project:   attribute("project"),
+				Location:  location,
+				IPAddress: attribute("address"),
+			}
+			address.Resource = terraformAddressResource(attribute("self_link"), address)
+
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// terraformResourceAddress returns the address of a resource instance as shown by Terraform,
+// such as module.network.google_compute_address.nat["a"].
+func terraformResourceAddress(module, resourceType, name string, indexKey any) string {
+	address := resourceType + "." + name
+	if module != "" {
+		address = module + "." + address
+	}
+
+	switch key := indexKey.(type) {
+	case string:
+		address += fmt.Sprintf("[%q]", key)
+	case float64:
+		address += fmt.Sprintf("[%d]", int(key))
+	}
+
+	return address
+}
+
+// terraformAddressResource returns the relative resource name of the address, such as
+// projects/P/regions/R/addresses/N, from its self link or its attributes.
+func terraformAddressResource(selfLink string, address TerraformAddress) string {
+	if i := strings.Index(selfLink, "/projects/"); i >= 0 {
+		return selfLink[i+1:]
+	}
+
+	if address.Location == "global" {
+		return "projects/" + address.Project + "/global/addresses/" + address.Name
+	}
+
+	return "projects/" + address.Project + "/regions/" + address.Location + "/addresses/" + address.Name
+}
+
+// assetResource returns the relative resource name of an asset, such as
+// projects/P/regions/R/addresses/N, matching terraformAddressResource.
+func assetResource(asset ProcessedAsset) string {
+	if i := strings.Index(asset.ResourceName, "/projects/"); i >= 0 {
+		return asset.ResourceName[i+1:]
+	}
+
+	return asset.ResourceName
+}
+
+// loadTerraformAddresses reads the addresses declared in the states of the comma-separated sources.
+func loadTerraformAddresses(ctx context.Context, reader terraformStateReader, sources string) ([]TerraformAddress, error) {
+	addresses := []TerraformAddress{}
+
+	for _, source := range splitString(sources, ",") {
+		data, err := reader.read(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+
+		stateAddresses, err := parseTerraformAddresses(source, data)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, stateAddresses...)
+	}
+
+	return addresses, nil
+}
+
+// compareTerraformState compares the addresses declared in Terraform with the address assets:
+// addresses that are not declared are unmanaged, and declared addresses that are not found
+// are missing. Other asset types are not managed by the compared resources and are ignored.
+func compareTerraformState(declared []TerraformAddress, assets []ProcessedAsset) TerraformDrift {
+	drift := TerraformDrift{Unmanaged: []ProcessedAsset{}, Missing: []TerraformAddress{}}
+
+	byResource := make(map[string]bool, len(declared))
+	for _, address := range declared {
+		byResource[address.Resource] = true
+	}
+
+	found := make(map[string]bool, len(assets))
+
+	for _, asset := range assets {
+		if asset.AssetType != "" && asset.AssetType != addressAssetType {
+			continue
+		}
+
+		resource := assetResource(asset)
+		found[resource] = true
+
+		if byResource[resource] {
+			drift.Managed++
+		} else {
+			drift.Unmanaged = append(drift.Unmanaged, asset)
+		}
+	}
+
+	for _, address := range declared {
+		if !found[address.Resource] {
+			drift.Missing = append(drift.Missing, address)
+		}
+	}
+
+	slices.SortFunc(drift.Missing, func(a, b TerraformAddress) int {
+		return cmp.Compare(a.Resource, b.Resource)
+	})
+
+	return drift
+}
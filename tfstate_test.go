@@ -0,0 +1,135 @@
+package assetwatcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testTerraformState = `{
+	"version": 4,
+	"resources": [
+		{
+			"mode": "managed", "type": "google_compute_address", "name": "nat",
+			"instances": [
+				{"index_key": 0, "attributes": {"name": "nat-0", "project": "p1", "region": "us-central1", "address": "203.0.113.1",
+					"self_link": "https://www.googleapis.com/compute/v1/projects/p1/regions/us-central1/addresses/nat-0"}},
+				{"index_key": 1, "attributes": {"name": "nat-1", "project": "p1", "region": "us-central1", "address": "203.0.113.2"}}
+			]
+		},
+		{
+			"module": "module.lb", "mode": "managed", "type": "google_compute_global_address", "name": "lb",
+			"instances": [{"attributes": {"name": "lb", "project": "p2", "address": "198.51.100.1"}}]
+		},
+		{
+			"mode": "data", "type": "google_compute_address", "name": "existing",
+			"instances": [{"attributes": {"name": "existing", "project": "p1", "region": "us-central1"}}]
+		},
+		{
+			"mode": "managed", "type": "google_compute_instance", "name": "vm",
+			"instances": [{"attributes": {"name": "vm", "project": "p1"}}]
+		}
+	]
+}`
+
+func TestCompareTerraformState(t *testing.T) {
+	declared, err := parseTerraformAddresses("terraform.tfstate", []byte(testTerraformState))
+	if err != nil {
+		t.Fatalf("parseTerraformAddresses failed: %v", err)
+	}
+
+	wantAddresses := []string{
+		"google_compute_address.nat[0]",
+		"google_compute_address.nat[1]",
+		"module.lb.google_compute_global_address.lb",
+	}
+	if len(declared) != len(wantAddresses) {
+		t.Fatalf("expected %d addresses, got %+v", len(wantAddresses), declared)
+	}
+
+	for i, want := range wantAddresses {
+		if declared[i].Address != want {
+			t.Errorf("address %d = %q, want %q", i, declared[i].Address, want)
+		}
+	}
+
+	assets := []ProcessedAsset{
+		{
+			Name: "nat-0", AssetType: addressAssetType,
+			ResourceName: "//compute.googleapis.com/projects/p1/regions/us-central1/addresses/nat-0",
+		},
+		{
+			Name: "manual", AssetType: addressAssetType,
+			ResourceName: "//compute.googleapis.com/projects/p1/regions/us-central1/addresses/manual",
+		},
+		{
+			Name: "lb", AssetType: addressAssetType,
+			ResourceName: "//compute.googleapis.com/projects/p2/global/addresses/lb",
+		},
+		{
+			Name: "vm", AssetType: "compute.googleapis.com/Instance",
+			ResourceName: "//compute.googleapis.com/projects/p1/zones/us-central1-a/instances/vm",
+		},
+	}
+
+	drift := compareTerraformState(declared, assets)
+
+	if drift.Managed != 2 {
+		t.Errorf("expected 2 managed addresses, got %d", drift.Managed)
+	}
+
+	if len(drift.Unmanaged) != 1 || drift.Unmanaged[0].Name != "manual" {
+		t.Errorf("unexpected unmanaged addresses %+v", drift.Unmanaged)
+	}
+
+	want := []TerraformAddress{{
+		Address: "google_compute_address.nat[1]", State: "terraform.tfstate",
+		Resource: "projects/p1/regions/us-central1/addresses/nat-1",
+		Name:     "nat-1", Project: "p1", Location: "us-central1", IPAddress: "203.0.113.2",
+	}}
+	if !reflect.DeepEqual(drift.Missing, want) {
+		t.Errorf("missing = %+v, want %+v", drift.Missing, want)
+	}
+}
+
+func TestParseTerraformAddresses_UnsupportedVersion(t *testing.T) {
+	if _, err := parseTerraformAddresses("old.tfstate", []byte(`{"version": 3}`)); err == nil {
+		t.Error("expected an error for a version 3 state")
+	}
+}
+
+func TestLoadTerraformAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/state/network" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(testTerraformState))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	if err := os.WriteFile(path, []byte(testTerraformState), 0o600); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	reader := terraformStateReader{client: server.Client()}
+
+	addresses, err := loadTerraformAddresses(t.Context(), reader, path+","+server.URL+"/state/network")
+	if err != nil {
+		t.Fatalf("loadTerraformAddresses failed: %v", err)
+	}
+
+	if len(addresses) != 6 || addresses[0].State != path || addresses[3].State != server.URL+"/state/network" {
+		t.Errorf("unexpected addresses %+v", addresses)
+	}
+
+	if _, err := loadTerraformAddresses(t.Context(), reader, server.URL+"/state/missing"); err == nil {
+		t.Error("expected an error for a missing state")
+	}
+}
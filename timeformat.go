@@ -0,0 +1,101 @@
+package assetwatcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The formats of the creation times of the output.
+const (
+	timeFormatDateTime = "datetime"
+	timeFormatRFC3339  = "rfc3339"
+	timeFormatDate     = "date"
+	timeFormatUnix     = "unix"
+)
+
+var errInvalidTimeFormat = errors.New("invalid time format")
+
+// timeFormatter formats the creation times of the assets of the output in a format and a time
+// zone. The creation times of the pipeline, such as those of the snapshots and the rules, are
+// always in the datetime format in UTC.
+type timeFormatter struct {
+	format   string
+	location *time.Location
+}
+
+// newTimeFormatter returns the formatter of ASSET_WATCHER_TIME_FORMAT, one of datetime,
+// rfc3339, date, or unix, and ASSET_WATCHER_TIME_ZONE, an IANA time zone such as
+// Europe/Berlin, or Local. They default to datetime and UTC.
+func newTimeFormatter(format, zone string) (*timeFormatter, error) {
+	switch format {
+	case "":
+		format = timeFormatDateTime
+	case timeFormatDateTime, timeFormatRFC3339, timeFormatDate, timeFormatUnix:
+	default:
+		return nil, fmt.Errorf("%w: %q, expected %s, %s, %s, or %s", errInvalidTimeFormat, format,
+			timeFormatDateTime, timeFormatRFC3339, timeFormatDate, timeFormatUnix)
+	}
+
+	location := time.UTC
+
+	if zone != "" {
+		var err error
+		if location, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q: %w", errInvalidTimeFormat, zone, err)
+		}
+	}
+
+	return &timeFormatter{format: format, location: location}, nil
+}
+
+// formatTime formats the time in the format and time zone of the formatter.
+func (f *timeFormatter) formatTime(t time.Time) string {
+	t = t.In(f.location)
+
+	switch f.format {
+	case timeFormatRFC3339:
+		return t.Format(time.RFC3339)
+	case timeFormatDate:
+		return t.Format(time.DateOnly)
+	case timeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	default:
+		return t.Format(createdAtLayout)
+	}
+}
+
+// asset returns the asset with its creation time formatted. The raw creation time is kept in
+// CreateTime, so that it stays machine-readable whatever the format.
+func (f *timeFormatter) asset(asset ProcessedAsset) ProcessedAsset {
+	createTime := asset.CreateTime
+	if createTime.IsZero() {
+		// The assets of older snapshots have no raw creation time.
+		t, err := time.Parse(createdAtLayout, asset.CreatedAt)
+		if err != nil {
+			return asset
+		}
+
+		createTime = t
+	}
+
+	asset.CreateTime = createTime.UTC()
+	asset.CreatedAt = f.formatTime(createTime)
+
+	return asset
+}
+
+// assets returns copies of the assets with their creation times formatted.
+func (f *timeFormatter) assets(assets []ProcessedAsset) []ProcessedAsset {
+	if assets == nil {
+		return nil
+	}
+
+	formatted := make([]ProcessedAsset, len(assets))
+	for i, asset := range assets {
+		formatted[i] = f.asset(asset)
+	}
+
+	return formatted
+}
@@ -0,0 +1,104 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeFormatter(t *testing.T) {
+	createTime := time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		format  string
+		zone    string
+		want    string
+		wantErr bool
+	}{
+		{name: "defaults", want: "2024-01-10 23:30:00"},
+		{name: "datetime", format: timeFormatDateTime, zone: "UTC", want: "2024-01-10 23:30:00"},
+		{name: "rfc3339", format: timeFormatRFC3339, zone: "UTC", want: "2024-01-10T23:30:00Z"},
+		{name: "rfc3339 in zone", format: timeFormatRFC3339, zone: "Europe/Berlin", want: "2024-01-11T00:30:00+01:00"},
+		{name: "date in zone", format: timeFormatDate, zone: "Europe/Berlin", want: "2024-01-11"},
+		{name: "unix", format: timeFormatUnix, zone: "Europe/Berlin", want: "1704929400"},
+		{name: "unknown format", format: "iso", wantErr: true},
+		{name: "unknown zone", zone: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := newTimeFormatter(tt.format, tt.zone)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidTimeFormat) {
+					t.Fatalf("newTimeFormatter() error = %v, want errInvalidTimeFormat", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("newTimeFormatter() error = %v", err)
+			}
+
+			got := times.asset(ProcessedAsset{CreatedAt: "2024-01-10 23:30:00", CreateTime: createTime})
+			if got.CreatedAt != tt.want || !got.CreateTime.Equal(createTime) {
+				t.Errorf("asset() = %q, %v, want %q, %v", got.CreatedAt, got.CreateTime, tt.want, createTime)
+			}
+		})
+	}
+}
+
+func TestTimeFormatter_SnapshotAssets(t *testing.T) {
+	times, err := newTimeFormatter(timeFormatRFC3339, "UTC")
+	if err != nil {
+		t.Fatalf("newTimeFormatter() error = %v", err)
+	}
+
+	// The assets of older snapshots only have the creation time in the datetime format.
+	got := times.assets([]ProcessedAsset{{CreatedAt: "2024-01-10 23:30:00"}, {CreatedAt: "unknown"}})
+
+	if got[0].CreatedAt != "2024-01-10T23:30:00Z" || !got[0].CreateTime.Equal(time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC)) {
+		t.Errorf("assets()[0] = %q, %v", got[0].CreatedAt, got[0].CreateTime)
+	}
+
+	if got[1].CreatedAt != "unknown" || !got[1].CreateTime.IsZero() {
+		t.Errorf("assets()[1] = %q, %v, want the asset unchanged", got[1].CreatedAt, got[1].CreateTime)
+	}
+
+	if times.assets(nil) != nil {
+		t.Error("assets(nil) should be nil")
+	}
+}
+
+func TestOutputReport_TimeFormat(t *testing.T) {
+	createTime := time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC)
+	report := &Report{Assets: []ProcessedAsset{{Name: "nat-1", CreatedAt: "2024-01-10 23:30:00", CreateTime: createTime}}}
+	cfg := &Config{OutputFormat: "json", TimeFormat: timeFormatUnix, TimeZone: "UTC"}
+
+	var buf bytes.Buffer
+
+	outputReport(t.Context(), nil, &buf, report, cfg)
+
+	var got struct {
+		Assets []struct {
+			CreatedAt  string `json:"createdAt"`
+			CreateTime string `json:"createTime"`
+		} `json:"assets"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode the report %q: %v", buf.String(), err)
+	}
+
+	if len(got.Assets) != 1 || got.Assets[0].CreatedAt != "1704929400" ||
+		got.Assets[0].CreateTime != "2024-01-10T23:30:00Z" {
+		t.Errorf("unexpected assets %+v", got.Assets)
+	}
+
+	if report.Assets[0].CreatedAt != "2024-01-10 23:30:00" {
+		t.Error("outputReport should not change the assets of the report")
+	}
+}
@@ -0,0 +1,382 @@
+package assetwatcher
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// trendCommand reports the trend of the address usage from the history, with a forecast of
+	// when the external address quotas and the BYOIP ranges will be exhausted.
+	trendCommand = "trend"
+
+	// staticAddressesQuota is the Compute Engine quota of the regional static external addresses.
+	staticAddressesQuota = "STATIC_ADDRESSES"
+
+	// Kinds of trend series.
+	trendKindQuota = "quota"
+	trendKindBYOIP = "byoip"
+
+	defaultTrendLookbackDays = 90
+	defaultTrendHorizonDays  = 365
+
+	// externalAddressType is the address type of external addresses.
+	externalAddressType = "EXTERNAL"
+
+	ipv4Bits = 32
+
+	// trendDaysPerMonth is the period of the change of the usage in the table.
+	trendDaysPerMonth = 30
+)
+
+var (
+	errTrendArguments = errors.New("usage: trend [--format table|json] [--lookback DAYS] [--horizon DAYS]")
+	errQuotaNotFound  = errors.New("quota not found")
+
+	errUnknownTrendFormat = errors.New("unknown trend format")
+)
+
+// QuotaGetter returns the quota of static external addresses of a region of a project.
+type QuotaGetter interface {
+	AddressQuota(ctx context.Context, project, region string) (int, error)
+}
+
+// TrendReport is the trend of the address usage over the runs of the history.
+type TrendReport struct {
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Runs   int           `json:"runs"`
+	Series []TrendSeries `json:"series"`
+	// Forecast are the series forecast to be exhausted within the horizon, soonest first.
+	Forecast []TrendSeries `json:"forecast"`
+}
+
+// TrendSeries is the usage of an address quota of a region of a project, or of a BYOIP range,
+// with a linear trend fitted over the runs.
+type TrendSeries struct {
+	Kind    string       `json:"kind"`
+	Scope   string       `json:"scope"`
+	Points  []TrendPoint `json:"points"`
+	Current int          `json:"current"`
+	// Limit is the quota, or the size of the range. It is 0 if unknown.
+	Limit int `json:"limit,omitempty"`
+	// SlopePerDay is the fitted change of the usage per day.
+	SlopePerDay float64 `json:"slopePerDay"`
+	// ExhaustedAt is the forecast time of exhaustion, if the usage grows towards a known limit.
+	ExhaustedAt *time.Time `json:"exhaustedAt,omitempty"`
+	// DaysLeft is the number of days until ExhaustedAt, 0 if already exhausted.
+	DaysLeft *float64 `json:"daysLeft,omitempty"`
+}
+
+// TrendPoint is the usage of a series in a run.
+type TrendPoint struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+// runTrendCommand builds the trend report from the runs of ASSET_WATCHER_HISTORY_DIR and writes
+// it as a table or JSON. Every forecast is also logged with its days left, as a metric for
+// log-based alerts.
+func runTrendCommand(ctx context.Context, logger *slog.Logger, cfg *Config, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(trendCommand, flag.ContinueOnError)
+	// The usage and the parse errors are kept out of the report written to w.
+	flags.SetOutput(os.Stderr)
+	format := flags.String("format", outputFormatTable, "output format, table or json")
+	lookback := flags.Int("lookback", defaultTrendLookbackDays, "number of days of history to fit the trend over")
+	horizon := flags.Int("horizon", defaultTrendHorizonDays, "number of days to forecast exhaustion within")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	if flags.NArg() > 0 || *lookback <= 0 || *horizon <= 0 {
+		return errTrendArguments
+	}
+
+	*format = strings.ToLower(*format)
+	if *format != outputFormatTable && *format != outputFormatJSON {
+		return fmt.Errorf("%w: %s, expected table or json", errUnknownTrendFormat, strconv.Quote(*format))
+	}
+
+	if cfg.HistoryDir == "" {
+		return errNoHistory
+	}
+
+	reports, err := NewFileRunStore(cfg.HistoryDir).List(ctx)
+	if err != nil {
+		return err
+	}
+
+	quotas, err := NewGoogleAddressDescriber(ctx, logger, clientOptionsFor(ctx, logger, cfg, credentialsCompute)...)
+	if err != nil {
+		return err
+	}
+
+	// The ranges are validated by GetConfig.
+	byoipRanges, _ := parseCIDRs(cfg.BYOIPRanges)
+
+	now := time.Now()
+	since := now.Add(-time.Duration(*lookback) * hoursPerDay * time.Hour)
+	trend := buildTrendReport(ctx, logger, quotas, reports, byoipRanges, since)
+	trend.Forecast = forecastExhaustion(trend.Series, now, time.Duration(*horizon)*hoursPerDay*time.Hour)
+
+	for _, series := range trend.Forecast {
+		logger.WarnContext(ctx, "Address exhaustion forecast",
+			slog.String("kind", series.Kind),
+			slog.String("scope", series.Scope),
+			slog.Int("current", series.Current),
+			slog.Int("limit", series.Limit),
+			slog.Time("exhausted_at", *series.ExhaustedAt),
+			slog.Float64("days_to_exhaustion", *series.DaysLeft),
+		)
+	}
+
+	if *format == outputFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(trend); err != nil {
+			return fmt.Errorf("failed to encode trend report: %w", err)
+		}
+
+		return nil
+	}
+
+	return writeTrendTable(w, trend)
+}
+
+// buildTrendReport counts the external addresses of every region of every project, and the
+// addresses within every IPv4 BYOIP range, in the reports started since the time, and fits
+// a linear trend to every series. The quotas are fetched for the regions in use in the
+// latest run; a failure leaves the limit unknown.
+func buildTrendReport(
+	ctx context.Context,
+	logger *slog.Logger,
+	quotas QuotaGetter,
+	reports []*Report,
+	byoipRanges []netip.Prefix,
+	since time.Time,
+) TrendReport {
+	reports = slices.DeleteFunc(slices.Clone(reports), func(report *Report) bool {
+		return report.Metadata.StartedAt.Before(since)
+	})
+
+	trend := TrendReport{Runs: len(reports), Series: []TrendSeries{}, Forecast: []TrendSeries{}}
+	if len(reports) == 0 {
+		return trend
+	}
+
+	trend.From = reports[0].Metadata.StartedAt
+	trend.To = reports[len(reports)-1].Metadata.StartedAt
+
+	counts := make([]map[string]int, 0, len(reports))
+	series := map[string]*TrendSeries{}
+
+	for _, report := range reports {
+		usage := countAddressUsage(report.Assets, byoipRanges)
+		counts = append(counts, usage)
+
+		for key := range usage {
+			if _, ok := series[key]; !ok {
+				kind, scope, _ := strings.Cut(key, " ")
+				series[key] = &TrendSeries{Kind: kind, Scope: scope}
+			}
+		}
+	}
+
+	// A region or range without addresses in a run has a count of 0 there.
+	for i, report := range reports {
+		for key, s := range series {
+			s.Points = append(s.Points, TrendPoint{Time: report.Metadata.StartedAt, Count: counts[i][key]})
+		}
+	}
+
+	for _, prefix := range byoipRanges {
+		if s, ok := series[trendKindBYOIP+" "+prefix.String()]; ok {
+			s.Limit = 1 << (ipv4Bits - prefix.Bits())
+		}
+	}
+
+	for _, s := range series {
+		last := s.Points[len(s.Points)-1]
+		s.Current = last.Count
+		s.SlopePerDay = fitSlopePerDay(s.Points)
+
+		if s.Kind != trendKindQuota || s.Current == 0 {
+			continue
+		}
+
+		project, region, _ := strings.Cut(s.Scope, "/")
+
+		limit, err := quotas.AddressQuota(ctx, project, region)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to get the address quota",
+				slog.String("project", project),
+				slog.String("region", region),
+				slog.Any("error", err),
+			)
+
+			continue
+		}
+
+		s.Limit = limit
+	}
+
+	for _, s := range series {
+		trend.Series = append(trend.Series, *s)
+	}
+
+	slices.SortFunc(trend.Series, func(a, b TrendSeries) int {
+		return cmp.Or(strings.Compare(a.Kind, b.Kind), strings.Compare(a.Scope, b.Scope))
+	})
+
+	return trend
+}
+
+// countAddressUsage counts the regional external addresses of every project and region, keyed
+// by "quota PROJECT/REGION", and the addresses within every IPv4 BYOIP range, keyed by
+// "byoip RANGE".
+func countAddressUsage(assets []ProcessedAsset, byoipRanges []netip.Prefix) map[string]int {
+	counts := map[string]int{}
+
+	for _, asset := range assets {
+		if asset.AssetType == addressAssetType && asset.AddressType == externalAddressType {
+			if project, region, _, err := parseAddressResourceName(asset.ResourceName); err == nil && region != "" {
+				counts[trendKindQuota+" "+project+"/"+region]++
+			}
+		}
+
+		for _, address := range ownedAddresses(asset) {
+			addr, err := netip.ParseAddr(address)
+			if err != nil || !addr.Unmap().Is4() {
+				continue
+			}
+
+			for _, prefix := range byoipRanges {
+				if prefix.Addr().Is4() && prefix.Contains(addr.Unmap()) {
+					counts[trendKindBYOIP+" "+prefix.String()]++
+				}
+			}
+		}
+	}
+
+	return counts
+}
+
+// fitSlopePerDay fits a line to the points with least squares and returns its slope per day.
+// The slope is 0 for fewer than two distinct times.
+func fitSlopePerDay(points []TrendPoint) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+
+	origin := points[0].Time
+	n := float64(len(points))
+
+	var sumX, sumY, sumXY, sumXX float64
+
+	for _, point := range points {
+		x := point.Time.Sub(origin).Hours() / hoursPerDay
+		y := float64(point.Count)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// forecastExhaustion returns the series with a known limit that are exhausted, or whose usage
+// grows to the limit within the horizon from now, soonest first, with ExhaustedAt and DaysLeft set.
+func forecastExhaustion(series []TrendSeries, now time.Time, horizon time.Duration) []TrendSeries {
+	forecast := []TrendSeries{}
+
+	for _, s := range series {
+		if s.Limit <= 0 {
+			continue
+		}
+
+		daysLeft := 0.0
+
+		if s.Current < s.Limit {
+			if s.SlopePerDay <= 0 {
+				continue
+			}
+
+			last := s.Points[len(s.Points)-1].Time
+			daysLeft = float64(s.Limit-s.Current)/s.SlopePerDay - now.Sub(last).Hours()/hoursPerDay
+			daysLeft = math.Max(daysLeft, 0)
+		}
+
+		exhaustedAt := now.Add(time.Duration(daysLeft * hoursPerDay * float64(time.Hour))).UTC()
+		if exhaustedAt.Sub(now) > horizon {
+			continue
+		}
+
+		s.ExhaustedAt = &exhaustedAt
+		s.DaysLeft = &daysLeft
+		forecast = append(forecast, s)
+	}
+
+	slices.SortStableFunc(forecast, func(a, b TrendSeries) int {
+		return cmp.Compare(*a.DaysLeft, *b.DaysLeft)
+	})
+
+	return forecast
+}
+
+func writeTrendTable(w io.Writer, trend TrendReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tabWriterPadding, ' ', tabwriter.Debug)
+	_, _ = fmt.Fprintf(tw, "Trend of %d runs from %s to %s\n\n", trend.Runs,
+		trend.From.UTC().Format(time.DateOnly), trend.To.UTC().Format(time.DateOnly))
+	_, _ = fmt.Fprintln(tw, "Kind\tScope\tCurrent\tLimit\tChange per 30 Days")
+	_, _ = fmt.Fprintln(tw, "----\t-----\t-------\t-----\t------------------")
+
+	for _, s := range trend.Series {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%+.1f\n", s.Kind, s.Scope, s.Current, formatLimit(s.Limit),
+			s.SlopePerDay*trendDaysPerMonth)
+	}
+
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Forecast Exhaustion\tScope\tCurrent\tLimit\tDays Left")
+	_, _ = fmt.Fprintln(tw, "-------------------\t-----\t-------\t-----\t---------")
+
+	for _, s := range trend.Forecast {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.0f\n", s.ExhaustedAt.Format(time.DateOnly), s.Scope, s.Current,
+			s.Limit, *s.DaysLeft)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return nil
+}
+
+func formatLimit(limit int) string {
+	if limit <= 0 {
+		return "unknown"
+	}
+
+	return fmt.Sprint(limit)
+}
@@ -0,0 +1,247 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+type fakeQuotaGetter map[string]int
+
+func (f fakeQuotaGetter) AddressQuota(_ context.Context, project, region string) (int, error) {
+	limit, ok := f[project+"/"+region]
+	if !ok {
+		return 0, errQuotaNotFound
+	}
+
+	return limit, nil
+}
+
+func trendAddress(project, region, name, ip string) ProcessedAsset {
+	return ProcessedAsset{
+		Name:         name,
+		Project:      project,
+		IPAddress:    ip,
+		AssetType:    addressAssetType,
+		AddressType:  externalAddressType,
+		ResourceName: "//compute.googleapis.com/projects/" + project + "/regions/" + region + "/addresses/" + name,
+	}
+}
+
+func TestFitSlopePerDay(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		points []TrendPoint
+		want   float64
+	}{
+		{name: "no points", want: 0},
+		{name: "single point", points: []TrendPoint{{Time: start, Count: 3}}, want: 0},
+		{
+			name: "two per day",
+			points: []TrendPoint{
+				{Time: start, Count: 1},
+				{Time: start.AddDate(0, 0, 1), Count: 3},
+				{Time: start.AddDate(0, 0, 2), Count: 5},
+			},
+			want: 2,
+		},
+		{
+			name:   "same time",
+			points: []TrendPoint{{Time: start, Count: 1}, {Time: start, Count: 5}},
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitSlopePerDay(tt.points); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("fitSlopePerDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountAddressUsage(t *testing.T) {
+	ranges := []netip.Prefix{netip.MustParsePrefix("198.51.100.0/30"), netip.MustParsePrefix("2001:db8::/32")}
+	internal := trendAddress("proj-a", "us-central1", "internal", "10.0.0.1")
+	internal.AddressType = "INTERNAL"
+
+	counts := countAddressUsage([]ProcessedAsset{
+		trendAddress("proj-a", "us-central1", "a", "198.51.100.1"),
+		trendAddress("proj-a", "us-central1", "b", "203.0.113.1"),
+		trendAddress("proj-b", "europe-west1", "c", "198.51.100.2"),
+		internal,
+		{Name: "vm", AssetType: "compute.googleapis.com/Instance", Attributes: map[string]string{"externalIPs": "198.51.100.3"}},
+	}, ranges)
+
+	want := map[string]int{
+		"quota proj-a/us-central1":  2,
+		"quota proj-b/europe-west1": 1,
+		"byoip 198.51.100.0/30":     3,
+	}
+
+	if len(counts) != len(want) {
+		t.Errorf("countAddressUsage() = %v, want %v", counts, want)
+	}
+
+	for key, count := range want {
+		if counts[key] != count {
+			t.Errorf("countAddressUsage()[%q] = %d, want %d", key, counts[key], count)
+		}
+	}
+}
+
+func TestBuildTrendReport(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ranges := []netip.Prefix{netip.MustParsePrefix("198.51.100.0/30")}
+
+	reports := []*Report{
+		{Metadata: RunMetadata{StartedAt: start.AddDate(0, 0, -30)}, Assets: []ProcessedAsset{
+			trendAddress("proj-a", "us-central1", "old", "203.0.113.9"),
+		}},
+		{Metadata: RunMetadata{StartedAt: start}, Assets: []ProcessedAsset{
+			trendAddress("proj-a", "us-central1", "a", "203.0.113.1"),
+		}},
+		{Metadata: RunMetadata{StartedAt: start.AddDate(0, 0, 1)}, Assets: []ProcessedAsset{
+			trendAddress("proj-a", "us-central1", "a", "203.0.113.1"),
+			trendAddress("proj-a", "us-central1", "b", "198.51.100.1"),
+		}},
+		{Metadata: RunMetadata{StartedAt: start.AddDate(0, 0, 2)}, Assets: []ProcessedAsset{
+			trendAddress("proj-a", "us-central1", "a", "203.0.113.1"),
+			trendAddress("proj-a", "us-central1", "b", "198.51.100.1"),
+			trendAddress("proj-a", "us-central1", "c", "198.51.100.2"),
+			trendAddress("proj-b", "europe-west1", "d", "203.0.113.4"),
+		}},
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	quotas := fakeQuotaGetter{"proj-a/us-central1": 8}
+
+	trend := buildTrendReport(t.Context(), logger, quotas, reports, ranges, start)
+	if trend.Runs != 3 || !trend.From.Equal(start) || !trend.To.Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("unexpected runs: %d from %s to %s", trend.Runs, trend.From, trend.To)
+	}
+
+	if len(trend.Series) != 3 {
+		t.Fatalf("expected 3 series, got %+v", trend.Series)
+	}
+
+	byoip, quotaA, quotaB := trend.Series[0], trend.Series[1], trend.Series[2]
+
+	if byoip.Kind != trendKindBYOIP || byoip.Limit != 4 || byoip.Current != 2 || len(byoip.Points) != 3 || byoip.Points[0].Count != 0 {
+		t.Errorf("unexpected BYOIP series: %+v", byoip)
+	}
+
+	if quotaA.Scope != "proj-a/us-central1" || quotaA.Limit != 8 || quotaA.Current != 3 || math.Abs(quotaA.SlopePerDay-1) > 1e-9 {
+		t.Errorf("unexpected quota series: %+v", quotaA)
+	}
+
+	if quotaB.Scope != "proj-b/europe-west1" || quotaB.Limit != 0 {
+		t.Errorf("expected an unknown limit, got %+v", quotaB)
+	}
+
+	now := start.AddDate(0, 0, 2)
+	forecast := forecastExhaustion(trend.Series, now, 30*24*time.Hour)
+
+	if len(forecast) != 2 || forecast[0].Kind != trendKindBYOIP || forecast[1].Scope != "proj-a/us-central1" {
+		t.Fatalf("unexpected forecast: %+v", forecast)
+	}
+
+	if *forecast[0].DaysLeft != 2 || !forecast[0].ExhaustedAt.Equal(now.AddDate(0, 0, 2)) {
+		t.Errorf("expected the BYOIP range to be exhausted in 2 days, got %v", *forecast[0].DaysLeft)
+	}
+
+	if *forecast[1].DaysLeft != 5 {
+		t.Errorf("expected the quota to be exhausted in 5 days, got %v", *forecast[1].DaysLeft)
+	}
+
+	if got := forecastExhaustion(trend.Series, now, 3*24*time.Hour); len(got) != 1 {
+		t.Errorf("expected only the BYOIP range within the horizon, got %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTrendTable(&buf, TrendReport{Runs: trend.Runs, From: trend.From, To: trend.To, Series: trend.Series, Forecast: forecast}); err != nil {
+		t.Fatalf("writeTrendTable failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "2025-01-05") || !strings.Contains(buf.String(), "unknown") {
+		t.Errorf("unexpected trend table:\n%s", buf.String())
+	}
+}
+
+func TestForecastExhaustion_Exhausted(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := []TrendSeries{{Kind: trendKindQuota, Scope: "p/r", Current: 8, Limit: 8, Points: []TrendPoint{{Time: now, Count: 8}}}}
+
+	forecast := forecastExhaustion(series, now, time.Hour)
+	if len(forecast) != 1 || *forecast[0].DaysLeft != 0 {
+		t.Errorf("expected an exhausted quota, got %+v", forecast)
+	}
+}
+
+func TestGoogleAddressDescriber_AddressQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !strings.HasSuffix(r.URL.Path, "/projects/proj-a/regions/us-central1") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "not found"}}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"name": "us-central1", "quotas": [
+			{"metric": "CPUS", "limit": 24, "usage": 2},
+			{"metric": "STATIC_ADDRESSES", "limit": 8, "usage": 3}
+		]}`))
+	}))
+	defer server.Close()
+
+	ctx := t.Context()
+
+	describer, err := NewGoogleAddressDescriber(ctx, slog.New(slog.DiscardHandler),
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatalf("NewGoogleAddressDescriber failed: %v", err)
+	}
+
+	limit, err := describer.AddressQuota(ctx, "proj-a", "us-central1")
+	if err != nil || limit != 8 {
+		t.Errorf("AddressQuota() = %d, %v, want 8", limit, err)
+	}
+
+	if _, err := describer.AddressQuota(ctx, "proj-b", "us-central1"); err == nil || errors.Is(err, errQuotaNotFound) {
+		t.Errorf("expected an API error, got %v", err)
+	}
+}
+
+func TestRunTrendCommand_Format(t *testing.T) {
+	var out bytes.Buffer
+
+	err := runTrendCommand(t.Context(), slog.New(slog.DiscardHandler), &Config{}, []string{"--format", "csv"}, &out)
+	if !errors.Is(err, errUnknownTrendFormat) {
+		t.Errorf("expected errUnknownTrendFormat, got %v", err)
+	}
+
+	if err := runTrendCommand(t.Context(), slog.New(slog.DiscardHandler), &Config{}, []string{"--unknown"}, &out); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected nothing written to the output on errors, got %q", out.String())
+	}
+}
@@ -0,0 +1,136 @@
+package assetwatcher
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// helpCommand prints the usage of asset-watcher.
+const helpCommand = "help"
+
+// commandDescriptions describes the subcommands in the usage.
+var commandDescriptions = []struct {
+	name        string
+	description string
+}{
+	{ackCommand, "export the violations to CSV and import their acknowledgments"},
+	{advisoriesCommand, "send the new Advisory Notifications of the organization"},
+	{attestCommand, "export a signed attestation of the ownership of an IP address"},
+	{checkCommand, "compare the inventory with a committed baseline"},
+	{configCommand, "validate the configuration, or show the effective configuration"},
+	{diffCommand, "compare two snapshots"},
+	{doctorCommand, "check the credentials, APIs, and permissions of a scan"},
+	{helpCommand, "show this help"},
+	{lookupCommand, "fetch a single address fresh from the API"},
+	{notifyCommand, "re-send the notifications of a stored run"},
+	{selftestCommand, "validate the installation against embedded fakes"},
+	{serveCommand, "run the HTTP server"},
+	{terraformDataSourceCommand, "answer the queries of the Terraform external data source"},
+	{trendCommand, "report the trend of the address usage and forecast exhaustion"},
+	{versionCommand, "show the version"},
+}
+
+// isHelpArg reports whether the argument asks for the usage.
+func isHelpArg(arg string) bool {
+	return arg == helpCommand || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// isVersionArg reports whether the argument asks for the version.
+func isVersionArg(arg string) bool {
+	return arg == versionCommand || arg == "-v" || arg == "-version" || arg == "--version"
+}
+
+// writeUsage writes the usage of asset-watcher: the subcommands, the flags of the scan, and the
+// configuration options with their type and default value.
+func writeUsage(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprint(tw, `Usage: asset-watcher [--config PATH] [--profile NAME | --all-profiles] [COMMAND] [FLAGS]
+
+Without a command, asset-watcher scans the organization and publishes the report.
+Run asset-watcher COMMAND --help for the flags of a command.
+
+Commands:
+`)
+
+	for _, c := range commandDescriptions {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.description)
+	}
+
+	_, _ = fmt.Fprint(tw, `
+Flags:
+  --config PATH	read the settings from a YAML, JSON, or TOML configuration file
+  --profile NAME	apply the settings of a profile of the configuration file
+  --all-profiles	run the command for every profile of the configuration file
+  -h, --help	show this help
+  -v, --version	show the version
+`)
+
+	cfg := ConfigDefaults
+	flags := newScanFlagSet(&cfg, &scanFlags{})
+	flags.SetOutput(tw)
+
+	_, _ = fmt.Fprint(tw, "\nScan flags:\n")
+	flags.PrintDefaults()
+
+	_, _ = fmt.Fprint(tw, "\nConfiguration, as environment variables or, in lower case without the "+
+		"ASSET_WATCHER_ prefix,\nsettings of the configuration file. "+
+		"Flags override the environment, which overrides the file.\n")
+
+	for _, option := range configOptions() {
+		if option.details == "" {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\n", option.name, option.kind)
+		} else {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", option.name, option.kind, option.details)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write usage: %w", err)
+	}
+
+	return nil
+}
+
+// configOption is a configuration option shown in the usage.
+type configOption struct {
+	name    string
+	kind    string
+	details string
+}
+
+// configOptions returns the configuration options in the order of the configuration structure,
+// with whether they are required or secret, and their default value.
+func configOptions() []configOption {
+	options := []configOption{}
+	v := reflect.ValueOf(ConfigDefaults)
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		name, params, _ := strings.Cut(field.Tag.Get("env"), ",")
+		if name == "" {
+			continue
+		}
+
+		details := []string{}
+		if strings.Contains(params, "required") {
+			details = append(details, "required")
+		}
+
+		if field.Tag.Get("secret") == "true" {
+			details = append(details, "secret")
+		}
+
+		if !v.Field(i).IsZero() {
+			details = append(details, fmt.Sprintf("default %v", v.Field(i).Interface()))
+		}
+
+		options = append(options, configOption{name: name, kind: field.Type.String(), details: strings.Join(details, ", ")})
+	}
+
+	return options
+}
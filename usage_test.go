@@ -0,0 +1,74 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteUsage(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeUsage(&out); err != nil {
+		t.Fatalf("writeUsage() error = %v", err)
+	}
+
+	usage := out.String()
+
+	for _, want := range []string{
+		"Usage: asset-watcher [--config PATH] [--profile NAME | --all-profiles] [COMMAND] [FLAGS]",
+		"  advisories ",
+		"  -fail-on-violation\n",
+		"  ASSET_WATCHER_ORG_ID ",
+		"  ASSET_WATCHER_NOTIFY_RETRIES ",
+	} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected the usage to contain %q", want)
+		}
+	}
+
+	for _, line := range strings.Split(usage, "\n") {
+		if strings.HasSuffix(line, " ") {
+			t.Errorf("unexpected trailing spaces in %q", line)
+		}
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	options := map[string]configOption{}
+	for _, option := range configOptions() {
+		options[option.name] = option
+	}
+
+	tests := []struct {
+		name string
+		want configOption
+	}{
+		{
+			name: "ASSET_WATCHER_ORG_ID",
+			want: configOption{name: "ASSET_WATCHER_ORG_ID", kind: "string", details: "required"},
+		},
+		{
+			name: "ASSET_WATCHER_SLACK_TOKEN",
+			want: configOption{name: "ASSET_WATCHER_SLACK_TOKEN", kind: "string", details: "secret"},
+		},
+		{
+			name: "ASSET_WATCHER_GITHUB_LABEL",
+			want: configOption{name: "ASSET_WATCHER_GITHUB_LABEL", kind: "string", details: "default " + defaultGitHubLabel},
+		},
+		{
+			name: "ASSET_WATCHER_DEBUG",
+			want: configOption{name: "ASSET_WATCHER_DEBUG", kind: "bool"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := options[tt.name]; got != tt.want {
+			t.Errorf("configOptions()[%s] = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// The configuration and env files are not settings of the configuration file.
+	if len(options) != len(configEnvNames())+2 {
+		t.Errorf("expected every option, got %d", len(options))
+	}
+}
@@ -0,0 +1,59 @@
+package assetwatcher
+
+import (
+	"net/http"
+)
+
+const projectURL = "https://github.com/andreygrechin/asset-watcher"
+
+// userAgent returns the user agent of all outbound requests, so that platform owners can
+// attribute API traffic and quota usage to asset-watcher in their audit logs.
+func userAgent(cfg *Config) string {
+	if cfg.UserAgent != "" {
+		return cfg.UserAgent
+	}
+
+	ua := "asset-watcher/" + Version + " (+" + projectURL
+	if cfg.Profile != "" {
+		ua += "; profile=" + cfg.Profile
+	}
+
+	return ua + ")"
+}
+
+// Headers identifying the run and the serve mode request in outbound requests.
+const (
+	runIDHeader     = "X-Asset-Watcher-Run-Id"
+	requestIDHeader = "X-Request-Id"
+)
+
+// userAgentTransport sets the user agent of the requests sent through the base transport,
+// along with the IDs of the run and the request of their context.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip sets the user agent on a copy of the request and sends it.
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	if runID := runIDFromContext(req.Context()); runID != "" {
+		req.Header.Set(runIDHeader, runID)
+	}
+
+	if requestID := requestIDFromContext(req.Context()); requestID != "" {
+		req.Header.Set(requestIDHeader, requestID)
+	}
+
+	return t.base.RoundTrip(req) //nolint:wrapcheck // The transport errors are returned as is.
+}
+
+// newHTTPClient returns an HTTP client setting the user agent of all requests.
+func newHTTPClient(cfg *Config) *http.Client {
+	return &http.Client{
+		Timeout:   notifierTimeout,
+		Transport: userAgentTransport{base: http.DefaultTransport, userAgent: userAgent(cfg)},
+	}
+}
@@ -0,0 +1,79 @@
+package assetwatcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{name: "default", cfg: &Config{}, want: "asset-watcher/" + Version + " (+" + projectURL + ")"},
+		{name: "profile", cfg: &Config{Profile: "acme"}, want: "asset-watcher/" + Version + " (+" + projectURL + "; profile=acme)"},
+		{name: "override", cfg: &Config{Profile: "acme", UserAgent: "custom/1.0"}, want: "custom/1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userAgent(tt.cfg); got != tt.want {
+				t.Errorf("userAgent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient_SetsUserAgent(t *testing.T) {
+	var got string
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	resp, err := newHTTPClient(&Config{UserAgent: "custom/1.0"}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if got != "custom/1.0" {
+		t.Errorf("expected user agent custom/1.0, got %q", got)
+	}
+}
+
+func TestNewHTTPClient_SetsRunID(t *testing.T) {
+	var runID, requestID string
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		runID, requestID = r.Header.Get(runIDHeader), r.Header.Get(requestIDHeader)
+	}))
+	defer server.Close()
+
+	ctx := withRequestID(withRunID(t.Context(), "run1"), "req1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	resp, err := newHTTPClient(&Config{}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if runID != "run1" || requestID != "req1" {
+		t.Errorf("expected run ID run1 and request ID req1, got %q and %q", runID, requestID)
+	}
+}
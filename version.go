@@ -0,0 +1,69 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+)
+
+// versionCommand prints the build information of the binary.
+const versionCommand = "version"
+
+var errUnknownVersionFormat = errors.New("unknown version format")
+
+// buildInfo is the build information of the binary.
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+func currentBuildInfo() buildInfo {
+	return buildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// runVersionCommand writes the build information to stdout, as text or, with --format json,
+// as a JSON object. It does not need any configuration.
+func runVersionCommand(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet(versionCommand, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	format := flags.String("format", "text", "output format: text or json")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	info := currentBuildInfo()
+
+	switch *format {
+	case "text":
+		_, err := fmt.Fprintf(stdout, "asset-watcher %s\ncommit: %s\nbuilt: %s\ngo: %s\nplatform: %s\n",
+			info.Version, info.Commit, info.BuildTime, info.GoVersion, info.Platform)
+		if err != nil {
+			return fmt.Errorf("failed to write version: %w", err)
+		}
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(info); err != nil {
+			return fmt.Errorf("failed to write version: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: %s, expected text or json", errUnknownVersionFormat, strconv.Quote(*format))
+	}
+
+	return nil
+}
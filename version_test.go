@@ -0,0 +1,39 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunVersionCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := runVersionCommand(nil, &out); err != nil {
+		t.Fatalf("runVersionCommand() error = %v", err)
+	}
+
+	if !strings.HasPrefix(out.String(), "asset-watcher "+Version+"\ncommit: "+Commit+"\nbuilt: "+BuildTime+"\n") {
+		t.Errorf("unexpected version %q", out.String())
+	}
+
+	out.Reset()
+
+	if err := runVersionCommand([]string{"--format", "json"}, &out); err != nil {
+		t.Fatalf("runVersionCommand() error = %v", err)
+	}
+
+	var info buildInfo
+	if err := json.Unmarshal(out.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode version: %v", err)
+	}
+
+	if info != currentBuildInfo() {
+		t.Errorf("unexpected version %+v", info)
+	}
+
+	if err := runVersionCommand([]string{"--format", "xml"}, &out); !errors.Is(err, errUnknownVersionFormat) {
+		t.Errorf("expected errUnknownVersionFormat, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package assetwatcher
+
+import "strings"
+
+// Policy rules evaluated for every processed asset.
+const (
+	ruleOrphanedExternalAddress = "orphaned-external-address"
+	ruleInstanceExternalIP      = "instance-external-ip"
+	ruleBlocklistedAddress      = "blocklisted-address"
+	ruleInternetExposedPort     = "internet-exposed-port"
+	ruleOutOfBandAddress        = "out-of-band-address"
+)
+
+// Severities of the policy violations, matching the Security Command Center severities.
+const (
+	severityHigh   = "HIGH"
+	severityMedium = "MEDIUM"
+)
+
+// detectViolations evaluates the policy rules against the assets.
+func detectViolations(assets []ProcessedAsset) []RuleViolation {
+	violations := []RuleViolation{}
+
+	for _, asset := range assets {
+		if isIdleAddress(asset) && asset.AssetType != instanceAssetType {
+			violations = append(violations, RuleViolation{
+				Rule:     ruleOrphanedExternalAddress,
+				Severity: severityMedium,
+				Message:  "External address " + asset.IPAddress + " is reserved but not used by any resource",
+				Asset:    asset,
+			})
+		}
+
+		if asset.AssetType == instanceAssetType && asset.Attributes["externalIPs"] != "" {
+			violations = append(violations, RuleViolation{
+				Rule:     ruleInstanceExternalIP,
+				Severity: severityHigh,
+				Message:  "Instance " + asset.Name + " is directly exposed on " + asset.Attributes["externalIPs"],
+				Asset:    asset,
+			})
+		}
+
+		if len(asset.ExposedPorts) > 0 {
+			violations = append(violations, RuleViolation{
+				Rule:     ruleInternetExposedPort,
+				Severity: severityHigh,
+				Message:  asset.Name + " is reachable from the internet on " + strings.Join(asset.ExposedPorts, ", "),
+				Asset:    asset,
+			})
+		}
+
+		if len(asset.Blocklists) > 0 {
+			violations = append(violations, RuleViolation{
+				Rule:     ruleBlocklistedAddress,
+				Severity: severityHigh,
+				Message:  "Address of " + asset.Name + " is listed on " + strings.Join(asset.Blocklists, ", "),
+				Asset:    asset,
+			})
+		}
+
+		if asset.Compliance == complianceOutOfBand {
+			violations = append(violations, RuleViolation{
+				Rule:     ruleOutOfBandAddress,
+				Severity: severityHigh,
+				Message: asset.Name + " uses " + strings.Join(asset.OutOfBandAddresses, ", ") +
+					" outside of the approved ranges",
+				Asset: asset,
+			})
+		}
+	}
+
+	return violations
+}
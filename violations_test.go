@@ -0,0 +1,40 @@
+package assetwatcher
+
+import "testing"
+
+func TestDetectViolations(t *testing.T) {
+	assets := []ProcessedAsset{
+		{Name: "idle", AssetType: addressAssetType, Status: "RESERVED", IPAddress: "203.0.113.1"},
+		{Name: "internal", AssetType: addressAssetType, Status: "RESERVED", AddressType: "INTERNAL", IPAddress: "10.0.0.1"},
+		{Name: "used", AssetType: addressAssetType, Status: "IN_USE", IPAddress: "203.0.113.2"},
+		{Name: "vm-1", AssetType: instanceAssetType, Status: "RUNNING", Attributes: map[string]string{"externalIPs": "203.0.113.3"}},
+		{Name: "vm-2", AssetType: instanceAssetType, Status: "RUNNING", Attributes: map[string]string{"internalIPs": "10.0.0.2"}},
+		{Name: "nat", AssetType: addressAssetType, Status: "IN_USE", IPAddress: "203.0.113.4", Blocklists: []string{"zen.spamhaus.org"}},
+		{
+			Name: "rogue", AssetType: addressAssetType, Status: "IN_USE", IPAddress: "198.51.100.1",
+			Compliance: complianceOutOfBand, OutOfBandAddresses: []string{"198.51.100.1"},
+		},
+	}
+
+	got := detectViolations(assets)
+
+	if len(got) != 4 {
+		t.Fatalf("expected 4 violations, got %d: %+v", len(got), got)
+	}
+
+	if got[0].Rule != ruleOrphanedExternalAddress || got[0].Severity != severityMedium || got[0].Asset.Name != "idle" {
+		t.Errorf("unexpected first violation: %+v", got[0])
+	}
+
+	if got[1].Rule != ruleInstanceExternalIP || got[1].Severity != severityHigh || got[1].Asset.Name != "vm-1" {
+		t.Errorf("unexpected second violation: %+v", got[1])
+	}
+
+	if got[2].Rule != ruleBlocklistedAddress || got[2].Severity != severityHigh || got[2].Asset.Name != "nat" {
+		t.Errorf("unexpected third violation: %+v", got[2])
+	}
+
+	if got[3].Rule != ruleOutOfBandAddress || got[3].Severity != severityHigh || got[3].Asset.Name != "rogue" {
+		t.Errorf("unexpected fourth violation: %+v", got[3])
+	}
+}
@@ -0,0 +1,212 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const organizationsPrefix = "organizations/"
+
+var (
+	// ErrNoScope is returned by New without WithScope.
+	ErrNoScope = errors.New("no scope given, use WithScope")
+	// ErrInvalidScope is returned for a scope that is not an organization.
+	ErrInvalidScope = errors.New("invalid scope, expected organizations/ORG_ID")
+	// ErrInvalidFilters is returned for filters that cannot be applied.
+	ErrInvalidFilters = errors.New("invalid filters")
+	// ErrRunFailed is returned by Run when a stage of the pipeline fails. The cause is logged.
+	ErrRunFailed = errors.New("asset-watcher run failed")
+	// ErrPublishFailed is returned by Run when a sink or a notifier fails. The report is
+	// returned along with it.
+	ErrPublishFailed = errors.New("failed to publish the report")
+)
+
+// Watcher runs the asset-watcher pipeline from Go programs, configured with options instead of
+// the environment variables of the command: it fetches the assets of the scope, filters them,
+// builds the report, and publishes it to the sinks and notifiers.
+type Watcher struct {
+	cfg       Config
+	logger    *slog.Logger
+	sinks     []Sink
+	notifiers []Notifier
+}
+
+// Option configures a Watcher.
+type Option func(*Watcher) error
+
+// Filters select the assets of the report. Empty fields do not filter.
+type Filters struct {
+	// AssetTypes are the asset types to collect, such as compute.googleapis.com/Address,
+	// which is the default.
+	AssetTypes []string
+	// IncludeProjects are the project IDs to collect, and ExcludeProjects the project IDs to
+	// skip among them.
+	IncludeProjects []string
+	ExcludeProjects []string
+	// IncludeLabels are the labels every collected asset must have, and ExcludeLabels the
+	// labels of the assets to skip.
+	IncludeLabels map[string]string
+	ExcludeLabels map[string]string
+	// ExcludeReserved skips the addresses that are reserved but not in use.
+	ExcludeReserved bool
+	// Expression is a CEL expression the collected assets must match, such as
+	// asset.status == "RESERVED" && asset.project.startsWith("prod-").
+	Expression string
+}
+
+// New returns a Watcher configured with the options. WithScope is required.
+func New(opts ...Option) (*Watcher, error) {
+	w := &Watcher{cfg: ConfigDefaults, logger: slog.New(slog.DiscardHandler)}
+
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, err
+		}
+	}
+
+	if w.cfg.OrgID == "" {
+		return nil, ErrNoScope
+	}
+
+	return w, nil
+}
+
+// WithScope sets the organization whose assets are collected, as organizations/ORG_ID or ORG_ID.
+func WithScope(scope string) Option {
+	return func(w *Watcher) error {
+		orgID := strings.TrimPrefix(strings.TrimSpace(scope), organizationsPrefix)
+		if orgID == "" || strings.Contains(orgID, "/") {
+			return fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+
+		w.cfg.OrgID = orgID
+
+		return nil
+	}
+}
+
+// WithFilters sets the filters of the collected assets.
+func WithFilters(filters Filters) Option {
+	return func(w *Watcher) error {
+		if _, err := newCELFilter(filters.Expression); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidFilters, err)
+		}
+
+		w.cfg.AssetTypes = strings.Join(filters.AssetTypes, ",")
+		w.cfg.IncludeProjects = strings.Join(filters.IncludeProjects, ",")
+		w.cfg.ExcludeProjects = strings.Join(filters.ExcludeProjects, ",")
+		w.cfg.IncludeLabels = joinLabels(filters.IncludeLabels)
+		w.cfg.ExcludeLabels = joinLabels(filters.ExcludeLabels)
+		w.cfg.ExcludeReserved = filters.ExcludeReserved
+		w.cfg.FilterExpr = filters.Expression
+
+		return nil
+	}
+}
+
+// WithSinks adds sinks the report of every run is published to.
+func WithSinks(sinks ...Sink) Option {
+	return func(w *Watcher) error {
+		w.sinks = append(w.sinks, sinks...)
+
+		return nil
+	}
+}
+
+// WithNotifiers adds notifiers that are notified about the violations and changes of every run.
+func WithNotifiers(notifiers ...Notifier) Option {
+	return func(w *Watcher) error {
+		w.notifiers = append(w.notifiers, notifiers...)
+
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the pipeline. By default, nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Watcher) error {
+		w.logger = logger
+
+		return nil
+	}
+}
+
+// Run runs the pipeline once and returns the report. A failing stage returns ErrRunFailed; a
+// failing sink or notifier returns the report along with ErrPublishFailed.
+func (w *Watcher) Run(ctx context.Context) (report *Report, err error) {
+	startedAt := time.Now()
+	cfg := w.cfg
+
+	ctx = withEmbeddedRun(withRunProgress(withRunID(ctx, newRunID())))
+
+	// The stages of the pipeline exit the process on failure in the command; in an embedded run,
+	// exit unwinds to here instead.
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		exited, ok := recovered.(embeddedExit)
+		if !ok {
+			panic(recovered)
+		}
+
+		report = nil
+		err = fmt.Errorf("%w in the %s stage with exit code %d", ErrRunFailed, stageFromContext(ctx), exited.code)
+	}()
+
+	stores := newRunStores(ctx, w.logger, &cfg)
+	defer closeRunStores(ctx, w.logger, stores)
+
+	report = runScan(ctx, w.logger, &cfg, stores, startedAt)
+
+	setStage(ctx, stagePublish)
+
+	sinks := append(newSinks(ctx, w.logger, &cfg, stores), w.sinks...)
+	for _, notifier := range w.notifiers {
+		sinks = append(sinks, notifierSink{notifier: notifier, logger: w.logger})
+	}
+
+	defer closeSinks(ctx, w.logger, sinks)
+
+	if !publishToSinks(ctx, w.logger, sinks, report) {
+		return report, ErrPublishFailed
+	}
+
+	return report, nil
+}
+
+// embeddedExit is the panic exit raises in an embedded run.
+type embeddedExit struct {
+	code int
+}
+
+type embeddedContextKey struct{}
+
+// withEmbeddedRun returns a context of a run embedded by Watcher.Run, which must not exit the process.
+func withEmbeddedRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, embeddedContextKey{}, true)
+}
+
+func isEmbeddedRun(ctx context.Context) bool {
+	embedded, _ := ctx.Value(embeddedContextKey{}).(bool)
+
+	return embedded
+}
+
+// joinLabels formats the labels as the comma-separated key=value pairs of the configuration.
+func joinLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,97 @@
+package assetwatcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+		wantOrg string
+	}{
+		{name: "no scope", wantErr: ErrNoScope},
+		{name: "organization", opts: []Option{WithScope("organizations/123")}, wantOrg: "123"},
+		{name: "organization ID", opts: []Option{WithScope("123")}, wantOrg: "123"},
+		{name: "folder", opts: []Option{WithScope("folders/123")}, wantErr: ErrInvalidScope},
+		{name: "empty organization", opts: []Option{WithScope("organizations/")}, wantErr: ErrInvalidScope},
+		{
+			name:    "invalid expression",
+			opts:    []Option{WithScope("123"), WithFilters(Filters{Expression: "asset.status =="})},
+			wantErr: ErrInvalidFilters,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := New(tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && w.cfg.OrgID != tt.wantOrg {
+				t.Errorf("OrgID = %q, want %q", w.cfg.OrgID, tt.wantOrg)
+			}
+		})
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	sink := NewSQLiteSink(logger, "assets.db")
+
+	w, err := New(
+		WithScope("organizations/123"),
+		WithFilters(Filters{
+			AssetTypes:      []string{"compute.googleapis.com/Address", "compute.googleapis.com/GlobalAddress"},
+			IncludeProjects: []string{"prod-a", "prod-b"},
+			IncludeLabels:   map[string]string{"team": "net", "env": "prod"},
+			ExcludeReserved: true,
+			Expression:      `asset.status == "IN_USE"`,
+		}),
+		WithSinks(sink),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got, want := w.cfg.AssetTypes, "compute.googleapis.com/Address,compute.googleapis.com/GlobalAddress"; got != want {
+		t.Errorf("AssetTypes = %q, want %q", got, want)
+	}
+
+	if got, want := w.cfg.IncludeProjects, "prod-a,prod-b"; got != want {
+		t.Errorf("IncludeProjects = %q, want %q", got, want)
+	}
+
+	if got, want := w.cfg.IncludeLabels, "env=prod,team=net"; got != want {
+		t.Errorf("IncludeLabels = %q, want %q", got, want)
+	}
+
+	if !w.cfg.ExcludeReserved || w.cfg.FilterExpr == "" {
+		t.Errorf("ExcludeReserved = %v, FilterExpr = %q, want both set", w.cfg.ExcludeReserved, w.cfg.FilterExpr)
+	}
+
+	if len(w.sinks) != 1 || w.sinks[0] != Sink(sink) || w.logger != logger {
+		t.Errorf("sinks = %v, logger = %v, want the given sink and logger", w.sinks, w.logger)
+	}
+}
+
+func TestExit_EmbeddedRun(t *testing.T) {
+	ctx := withEmbeddedRun(withRunProgress(context.Background()))
+	setStage(ctx, stageFetch)
+
+	defer func() {
+		exited, ok := recover().(embeddedExit)
+		if !ok || exited.code != 2 {
+			t.Errorf("recover() = %v, want embeddedExit with code 2", exited)
+		}
+	}()
+
+	exit(ctx, 2)
+	t.Fatal("exit() returned in an embedded run")
+}
@@ -0,0 +1,77 @@
+package assetwatcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Limits of the generic webhook payload, chosen to stay below the request size
+// limits of common webhook receivers.
+const (
+	webhookMaxPayloadBytes = 256 * 1024
+	webhookMaxItems        = 1000
+	webhookPayloadOverhead = 4 * 1024
+)
+
+// webhookPayload is the JSON document posted by the generic webhook notifier.
+type webhookPayload struct {
+	RunID        string   `json:"runId,omitempty"`
+	Title        string   `json:"title"`
+	Summary      string   `json:"summary"`
+	Body         string   `json:"body,omitempty"`
+	Items        []string `json:"items"`
+	OmittedItems int      `json:"omittedItems"`
+	ArtifactURL  string   `json:"artifactUrl,omitempty"`
+}
+
+// WebhookNotifier posts notifications as JSON documents to an HTTP endpoint.
+type WebhookNotifier struct {
+	client *http.Client
+	url    string
+	logger *slog.Logger
+}
+
+// NewWebhookNotifier creates a new webhook notifier for the configured URL.
+func NewWebhookNotifier(logger *slog.Logger, cfg *Config, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{
+		client: client,
+		url:    cfg.WebhookURL,
+		logger: logger.With(slog.String("component", "asset-watcher")),
+	}
+}
+
+// Name returns the name of the notifier.
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the notification, with the body rendered by a template, if any, in the body
+// field. Items that do not fit into the payload limit are omitted, and their number is
+// reported in the omittedItems field.
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	if _, err := postJSON(ctx, n.client, n.url, nil, newWebhookPayload(notification)); err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+
+	n.logger.DebugContext(ctx, "Sent webhook notification")
+
+	return nil
+}
+
+func newWebhookPayload(notification Notification) webhookPayload {
+	budget := webhookMaxPayloadBytes - webhookPayloadOverhead - len(notification.Title) - len(notification.Summary) -
+		len(notification.Body)
+	items, omitted := limitItems(notification.Items, notification.itemsLimit(webhookMaxItems), budget, len(`"",`))
+
+	return webhookPayload{
+		RunID:        notification.RunID,
+		Title:        notification.Title,
+		Summary:      notification.Summary,
+		Body:         notification.Body,
+		Items:        items,
+		OmittedItems: omitted,
+		ArtifactURL:  notification.ArtifactURL,
+	}
+}
@@ -0,0 +1,37 @@
+package assetwatcher
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var payload webhookPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > webhookMaxPayloadBytes {
+			t.Errorf("payload of %d bytes exceeds the limit", len(body))
+		}
+
+		_ = json.Unmarshal(body, &payload)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(slog.New(slog.DiscardHandler), &Config{WebhookURL: server.URL}, server.Client())
+
+	notification := Notification{Title: "title", Items: manyItems(2000, 10), ArtifactURL: "https://example.com/r.json"}
+	if err := notifier.Notify(t.Context(), notification); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(payload.Items) != webhookMaxItems || payload.OmittedItems != 1000 || payload.ArtifactURL != notification.ArtifactURL {
+		t.Errorf("unexpected payload: %d items, %d omitted, artifact %q", len(payload.Items), payload.OmittedItems, payload.ArtifactURL)
+	}
+}
@@ -0,0 +1,201 @@
+package assetwatcher
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// outputFormatXLSX renders the report as an Excel workbook with a sheet of assets and a
+// summary sheet.
+const outputFormatXLSX = "xlsx"
+
+// xlsxCell is a cell of a worksheet, either a string or a number.
+type xlsxCell struct {
+	text   string
+	number float64
+	bold   bool
+
+	isNumber bool
+}
+
+func xlsxText(s string) xlsxCell    { return xlsxCell{text: s} }
+func xlsxHeader(s string) xlsxCell  { return xlsxCell{text: s, bold: true} }
+func xlsxNumber(n float64) xlsxCell { return xlsxCell{number: n, isNumber: true} }
+func xlsxInt(n int) xlsxCell        { return xlsxNumber(float64(n)) }
+
+// xlsxSheet is a named worksheet of rows of cells.
+type xlsxSheet struct {
+	name string
+	rows [][]xlsxCell
+}
+
+// writeXLSX writes the report as an Excel workbook.
+func writeXLSX(w io.Writer, report *Report, cfg *Config) error {
+	return writeXLSXSheets(w, []xlsxSheet{xlsxAssetsSheet(report, cfg), xlsxSummarySheet(report)})
+}
+
+// xlsxAssetsSheet lists the assets with the columns of the table output, along with the
+// asset type, the address type, and the labels, so that all asset types fit in one sheet.
+func xlsxAssetsSheet(report *Report, cfg *Config) xlsxSheet {
+	// Asset types without a registered extractor get the common columns and the optional ones.
+	columns := slices.Concat(
+		[]column{{header: "Asset Type", value: func(a ProcessedAsset) string { return a.AssetType }}},
+		tableColumns("", cfg),
+		[]column{
+			{header: "Address Type", value: func(a ProcessedAsset) string { return a.AddressType }},
+			{header: "Labels", value: func(a ProcessedAsset) string { return formatKeyValues(a.Labels) }},
+			{header: "Attributes", value: func(a ProcessedAsset) string { return formatKeyValues(a.Attributes) }},
+		},
+	)
+
+	header := make([]xlsxCell, 0, len(columns))
+	for _, c := range columns {
+		header = append(header, xlsxHeader(c.header))
+	}
+
+	sheet := xlsxSheet{name: "Assets", rows: [][]xlsxCell{header}}
+
+	for _, asset := range report.Assets {
+		row := make([]xlsxCell, 0, len(columns))
+		for _, c := range columns {
+			row = append(row, xlsxText(c.value(asset)))
+		}
+
+		sheet.rows = append(sheet.rows, row)
+	}
+
+	return sheet
+}
+
+// xlsxSummarySheet lists the metadata of the run and the summary of the report.
+func xlsxSummarySheet(report *Report) xlsxSheet {
+	summary := report.Summary
+	rows := [][]xlsxCell{
+		{xlsxHeader("Run"), xlsxHeader("")},
+		{xlsxText("Run ID"), xlsxText(report.Metadata.RunID)},
+		{xlsxText("Organization"), xlsxText(report.Metadata.OrgID)},
+		{xlsxText("Started At"), xlsxText(report.Metadata.StartedAt.Format(time.RFC3339))},
+		{xlsxText("Finished At"), xlsxText(report.Metadata.FinishedAt.Format(time.RFC3339))},
+		{xlsxText("Version"), xlsxText(report.Metadata.Version)},
+		{},
+		{xlsxHeader("Summary"), xlsxHeader("")},
+		{xlsxText("Total Assets"), xlsxInt(summary.TotalAssets)},
+		{xlsxText("Violations"), xlsxInt(len(report.Violations))},
+		{xlsxText("Changes"), xlsxInt(len(report.Diffs))},
+	}
+
+	if summary.Cost != nil {
+		rows = append(rows,
+			[]xlsxCell{xlsxText("Idle Addresses"), xlsxInt(summary.Cost.IdleAddresses)},
+			[]xlsxCell{xlsxText("Monthly Cost (USD)"), xlsxNumber(summary.Cost.MonthlyCost)},
+		)
+	}
+
+	sections := []struct {
+		title  string
+		counts map[string]int
+	}{
+		{title: "State", counts: summary.ByStatus},
+		{title: "Category", counts: summary.Categories},
+	}
+
+	for _, section := range sections {
+		if len(section.counts) == 0 {
+			continue
+		}
+
+		rows = append(rows, nil, []xlsxCell{xlsxHeader(section.title), xlsxHeader("Assets")})
+
+		for _, key := range slices.Sorted(maps.Keys(section.counts)) {
+			rows = append(rows, []xlsxCell{xlsxText(key), xlsxInt(section.counts[key])})
+		}
+	}
+
+	return xlsxSheet{name: "Summary", rows: rows}
+}
+
+// formatKeyValues formats a map as sorted key=value pairs.
+func formatKeyValues(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		pairs = append(pairs, key+"="+m[key])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// writeXLSXSheets writes a workbook of the sheets. The rows are streamed into the sheets, so
+// that large inventories are not held as cells in memory.
+func writeXLSXSheets(w io.Writer, sheets []xlsxSheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	defaultSheet := f.GetSheetName(0)
+
+	for _, sheet := range sheets {
+		if _, err := f.NewSheet(sheet.name); err != nil {
+			return fmt.Errorf("failed to write workbook: %w", err)
+		}
+
+		if err := writeXLSXRows(f, sheet, bold); err != nil {
+			return fmt.Errorf("failed to write sheet %s: %w", sheet.name, err)
+		}
+	}
+
+	if err := f.DeleteSheet(defaultSheet); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	return nil
+}
+
+// writeXLSXRows streams the rows of the sheet, with the bold cells in the bold style.
+func writeXLSXRows(f *excelize.File, sheet xlsxSheet, bold int) error {
+	sw, err := f.NewStreamWriter(sheet.name)
+	if err != nil {
+		return err //nolint:wrapcheck // Wrapped by writeXLSXSheets.
+	}
+
+	for i, row := range sheet.rows {
+		values := make([]any, 0, len(row))
+
+		for _, cell := range row {
+			value := excelize.Cell{Value: cell.text}
+			if cell.isNumber {
+				value.Value = cell.number
+			}
+
+			if cell.bold {
+				value.StyleID = bold
+			}
+
+			values = append(values, value)
+		}
+
+		ref, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err //nolint:wrapcheck // Wrapped by writeXLSXSheets.
+		}
+
+		if err := sw.SetRow(ref, values); err != nil {
+			return err //nolint:wrapcheck // Wrapped by writeXLSXSheets.
+		}
+	}
+
+	return sw.Flush() //nolint:wrapcheck // Wrapped by writeXLSXSheets.
+}
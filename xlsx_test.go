@@ -0,0 +1,100 @@
+package assetwatcher
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// readXLSXSheet returns the values of the cells of a sheet of the workbook, by row.
+func readXLSXSheet(t *testing.T, workbook []byte, name string) [][]string {
+	t.Helper()
+
+	f, err := excelize.OpenReader(bytes.NewReader(workbook))
+	if err != nil {
+		t.Fatalf("failed to open workbook: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(name)
+	if err != nil {
+		t.Fatalf("failed to read sheet %s: %v", name, err)
+	}
+
+	return rows
+}
+
+func TestWriteXLSX(t *testing.T) {
+	report := &Report{
+		Metadata: RunMetadata{RunID: "run-1", StartedAt: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		Assets: []ProcessedAsset{{
+			Name:        "nat-1",
+			AssetType:   addressAssetType,
+			Project:     "prod-network",
+			Location:    "us-central1",
+			Status:      "IN_USE",
+			IPAddress:   "1.1.1.1",
+			AddressType: "EXTERNAL",
+			Labels:      map[string]string{"team": "net", "env": "prod & test"},
+		}},
+		Summary: Summary{TotalAssets: 1, ByStatus: map[string]int{"IN_USE": 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, report, &Config{}); err != nil {
+		t.Fatalf("writeXLSX() error = %v", err)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to open workbook: %v", err)
+	}
+	defer f.Close()
+
+	if got := f.GetSheetList(); len(got) != 2 || got[0] != "Assets" || got[1] != "Summary" {
+		t.Errorf("sheets = %v, want Assets and Summary", got)
+	}
+
+	if style, err := f.GetCellStyle("Assets", "A1"); err != nil || style == 0 {
+		t.Errorf("header style = %d, %v, want the bold style", style, err)
+	}
+
+	assets := readXLSXSheet(t, buf.Bytes(), "Assets")
+	if len(assets) != 2 {
+		t.Fatalf("Assets sheet has %d rows, want 2", len(assets))
+	}
+
+	header, row := assets[0], assets[1]
+	if header[0] != "Asset Type" || row[0] != addressAssetType {
+		t.Errorf("first column = %q, %q, want Asset Type, %s", header[0], row[0], addressAssetType)
+	}
+
+	// The trailing empty cells of a row are not read.
+	values := map[string]string{}
+	for i, h := range header[:len(row)] {
+		values[h] = row[i]
+	}
+
+	if values["Display Name"] != "nat-1" || values["IP Address"] != "1.1.1.1" {
+		t.Errorf("asset row = %v, want the name and address of the asset", values)
+	}
+
+	if want := "env=prod & test, team=net"; values["Labels"] != want {
+		t.Errorf("Labels = %q, want %q", values["Labels"], want)
+	}
+
+	summary := readXLSXSheet(t, buf.Bytes(), "Summary")
+	found := map[string]string{}
+
+	for _, cells := range summary {
+		if len(cells) == 2 {
+			found[cells[0]] = cells[1]
+		}
+	}
+
+	if found["Run ID"] != "run-1" || found["Total Assets"] != "1" || found["IN_USE"] != "1" {
+		t.Errorf("Summary sheet = %v, want the run ID, the total, and the state counts", found)
+	}
+}